@@ -68,12 +68,17 @@ func (src *IntegrationTestScenario) ConvertTo(dstRaw conversion.Hub) error {
 			},
 		},
 	}
-	return nil
+	return dst.RestoreConversionData()
 }
 
 func (dst *IntegrationTestScenario) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta2.IntegrationTestScenario)
 	dst.ObjectMeta = src.ObjectMeta
+	annotations, err := src.MarshalConversionData()
+	if err != nil {
+		return err
+	}
+	dst.Annotations = annotations
 	dst.Spec.Application = src.Spec.Application
 	if src.Spec.Params != nil {
 		for _, par := range src.Spec.Params {
@@ -86,6 +91,10 @@ func (dst *IntegrationTestScenario) ConvertFrom(srcRaw conversion.Hub) error {
 		}
 	}
 
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = append(dst.Status.Conditions, src.Status.Conditions...)
+	}
+
 	if src.Spec.ResolverRef.Resolver == "bundles" {
 		for _, par := range src.Spec.ResolverRef.Params {
 			if par.Name == "bundle" {
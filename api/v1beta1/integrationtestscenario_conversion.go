@@ -65,12 +65,17 @@ func (src *IntegrationTestScenario) ConvertTo(dstRaw conversion.Hub) error {
 		}
 	}
 
-	return nil
+	return dst.RestoreConversionData()
 }
 
 func (dst *IntegrationTestScenario) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta2.IntegrationTestScenario)
 	dst.ObjectMeta = src.ObjectMeta
+	annotations, err := src.MarshalConversionData()
+	if err != nil {
+		return err
+	}
+	dst.Annotations = annotations
 	dst.Spec.Application = src.Spec.Application
 
 	if src.Spec.Params != nil {
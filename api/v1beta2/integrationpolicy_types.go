@@ -0,0 +1,149 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IntegrationPolicySpec defines the gating policy of an application
+type IntegrationPolicySpec struct {
+	// Application is the name of the application the policy applies to
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	// +required
+	Application string `json:"application"`
+	// RequiredScenarios are the names of the IntegrationTestScenarios required for the Snapshots of the application
+	// to pass, all of the other scenarios are optional when it's set regardless of their optional label
+	// +optional
+	RequiredScenarios []string `json:"requiredScenarios,omitempty"`
+	// OptionalScenarios are the names of the IntegrationTestScenarios which aren't required for the Snapshots of the
+	// application to pass, in addition to the scenarios labeled as optional
+	// +optional
+	OptionalScenarios []string `json:"optionalScenarios,omitempty"`
+	// BlockOnWarnings fails the Snapshots whose required integration tests passed with warnings
+	// +optional
+	BlockOnWarnings bool `json:"blockOnWarnings,omitempty"`
+	// MaxAllowedFailures is the failure tolerance threshold of the IntegrationTestScenarios of the application
+	// which don't define their own spec.maxAllowedFailures
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAllowedFailures *int `json:"maxAllowedFailures,omitempty"`
+	// AutoRelease enables the Releases to be created automatically for the auto-release ReleasePlans of the
+	// application once its Snapshots pass, it's enabled when not set
+	// +optional
+	AutoRelease *bool `json:"autoRelease,omitempty"`
+	// Quorums are groups of IntegrationTestScenarios of which only some have to pass for the Snapshots of the
+	// application to pass, the scenarios of a quorum aren't required on their own
+	// +optional
+	Quorums []ScenarioQuorum `json:"quorums,omitempty"`
+	// ReleaseWindows are the recurring periods of time the passed Snapshots of the application can be auto-released
+	// in, the Snapshots passing outside of them are auto-released once the next window opens
+	// +optional
+	ReleaseWindows []ReleaseWindow `json:"releaseWindows,omitempty"`
+	// TestingDeadline is how long the Snapshots of the application may await the results of their integration tests
+	// before they're failed as timed out, it takes precedence over the deadline set for all applications and 0
+	// disables it
+	// +optional
+	TestingDeadline *metav1.Duration `json:"testingDeadline,omitempty"`
+}
+
+// ReleaseWindow is a recurring period of time the Snapshots of an application can be auto-released in
+type ReleaseWindow struct {
+	// Schedule is the cron expression of the times the window opens at
+	// +required
+	Schedule string `json:"schedule"`
+	// Duration is how long the window stays open once it opened
+	// +required
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the IANA name of the time zone the schedule is evaluated in, UTC when not set
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ScenarioQuorum is a group of IntegrationTestScenarios which passes when enough of its scenarios passed
+type ScenarioQuorum struct {
+	// Name identifies the quorum in the gating decisions of the Snapshots
+	// +required
+	Name string `json:"name"`
+	// Scenarios are the names of the IntegrationTestScenarios of the quorum
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	Scenarios []string `json:"scenarios"`
+	// MinPassed is the number of the scenarios of the quorum which have to pass, it's capped to the number of its
+	// scenarios applicable to the Snapshot
+	// +kubebuilder:validation:Minimum=1
+	// +required
+	MinPassed int `json:"minPassed"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Application",type=string,JSONPath=`.spec.application`
+// +kubebuilder:printcolumn:name="Block on warnings",type=boolean,JSONPath=`.spec.blockOnWarnings`
+// +kubebuilder:printcolumn:name="Auto-release",type=boolean,JSONPath=`.spec.autoRelease`
+
+// IntegrationPolicy is the Schema for the integrationpolicies API, defining how the Snapshots of an application are gated
+type IntegrationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IntegrationPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IntegrationPolicyList contains a list of IntegrationPolicy
+type IntegrationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IntegrationPolicy `json:"items"`
+}
+
+// RequiresScenario returns true if the IntegrationTestScenario with the given name is required by the policy,
+// labeledOptional tells if the scenario is labeled as optional.
+func (p *IntegrationPolicy) RequiresScenario(scenarioName string, labeledOptional bool) bool {
+	// the scenarios of a quorum take part in the gating together with the other scenarios of the quorum
+	if p.IsQuorumScenario(scenarioName) {
+		return true
+	}
+	if len(p.Spec.RequiredScenarios) > 0 {
+		return slices.Contains(p.Spec.RequiredScenarios, scenarioName)
+	}
+
+	return !labeledOptional && !slices.Contains(p.Spec.OptionalScenarios, scenarioName)
+}
+
+// IsQuorumScenario returns true if the IntegrationTestScenario with the given name belongs to any quorum of the policy.
+func (p *IntegrationPolicy) IsQuorumScenario(scenarioName string) bool {
+	for _, quorum := range p.Spec.Quorums {
+		if slices.Contains(quorum.Scenarios, scenarioName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAutoReleaseEnabled returns true if the Snapshots of the application can be auto-released.
+func (p *IntegrationPolicy) IsAutoReleaseEnabled() bool {
+	return p.Spec.AutoRelease == nil || *p.Spec.AutoRelease
+}
+
+func init() {
+	SchemeBuilder.Register(&IntegrationPolicy{}, &IntegrationPolicyList{})
+}
@@ -16,5 +16,96 @@ limitations under the License.
 
 package v1beta2
 
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ConversionDataAnnotation is the annotation of the IntegrationTestScenarios converted to the v1beta1 and v1alpha1
+// API versions which stores the fields of the v1beta2 IntegrationTestScenario these versions don't have, so the
+// fields aren't lost when the scenario is updated through an older API version.
+const ConversionDataAnnotation = "test.appstudio.openshift.io/conversion-data"
+
 // Hub marks this type as a conversion hub.
 func (*IntegrationTestScenario) Hub() {}
+
+// conversionData contains the fields of the v1beta2 IntegrationTestScenario which the older API versions don't have.
+type conversionData struct {
+	Spec   IntegrationTestScenarioSpec   `json:"spec"`
+	Status IntegrationTestScenarioStatus `json:"status"`
+}
+
+// MarshalConversionData returns a copy of the annotations of the IntegrationTestScenario with the fields the older
+// API versions don't have stored in the ConversionDataAnnotation, to be used as the annotations of the scenario
+// converted to an older API version.
+func (r *IntegrationTestScenario) MarshalConversionData() (map[string]string, error) {
+	annotations := make(map[string]string, len(r.Annotations)+1)
+	for key, value := range r.Annotations {
+		annotations[key] = value
+	}
+	delete(annotations, ConversionDataAnnotation)
+
+	data := conversionData{Spec: *r.Spec.DeepCopy(), Status: *r.Status.DeepCopy()}
+	// the fields the older API versions have are converted by them
+	data.Spec.Application = ""
+	data.Spec.ResolverRef = ResolverRef{}
+	data.Spec.Params = nil
+	data.Spec.Contexts = nil
+	data.Status.Conditions = nil
+	if reflect.DeepEqual(data, conversionData{}) {
+		if len(annotations) == 0 {
+			return nil, nil
+		}
+		return annotations, nil
+	}
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the conversion data of the IntegrationTestScenario %s: %w", r.Name, err)
+	}
+	annotations[ConversionDataAnnotation] = string(value)
+	return annotations, nil
+}
+
+// RestoreConversionData restores the fields the older API versions don't have from the ConversionDataAnnotation of
+// the IntegrationTestScenario converted from an older API version and removes the annotation. The annotations are
+// copied first, as they are shared with the converted scenario.
+func (r *IntegrationTestScenario) RestoreConversionData() error {
+	value, ok := r.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	annotations := make(map[string]string, len(r.Annotations))
+	for key, value := range r.Annotations {
+		if key != ConversionDataAnnotation {
+			annotations[key] = value
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	r.Annotations = annotations
+
+	var data conversionData
+	if err := json.Unmarshal([]byte(value), &data); err != nil {
+		return fmt.Errorf("failed to unmarshal the conversion data of the IntegrationTestScenario %s: %w", r.Name, err)
+	}
+	r.Spec.ComponentSelector = data.Spec.ComponentSelector
+	r.Spec.MaxAllowedFailures = data.Spec.MaxAllowedFailures
+	r.Spec.Architecture = data.Spec.Architecture
+	r.Spec.Matrix = data.Spec.Matrix
+	r.Spec.ServiceAccountName = data.Spec.ServiceAccountName
+	r.Spec.Workspaces = data.Spec.Workspaces
+	r.Spec.PodTemplate = data.Spec.PodTemplate
+	r.Spec.TaskRunSpecs = data.Spec.TaskRunSpecs
+	r.Spec.Secrets = data.Spec.Secrets
+	r.Spec.SnapshotWorkspace = data.Spec.SnapshotWorkspace
+	r.Spec.Suspended = data.Spec.Suspended
+	r.Spec.ObserveOnlyRuns = data.Spec.ObserveOnlyRuns
+	r.Spec.QuarantineFlaky = data.Spec.QuarantineFlaky
+	r.Status.ObservedRuns = data.Status.ObservedRuns
+	r.Status.RunHistory = data.Status.RunHistory
+	r.Status.Trend = data.Status.Trend
+	return nil
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/api/v1alpha1"
+	"github.com/konflux-ci/integration-service/api/v1beta1"
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+)
+
+var _ = Describe("IntegrationTestScenario conversion", func() {
+
+	var validCondition metav1.Condition
+
+	BeforeEach(func() {
+		validCondition = metav1.Condition{
+			Type:               "IntegrationTestScenarioValid",
+			Status:             metav1.ConditionTrue,
+			Reason:             "Valid",
+			LastTransitionTime: metav1.Now(),
+		}
+	})
+
+	It("converts the v1alpha1 bundle and pipeline into a bundles resolverRef and back", func() {
+		scenario := &v1alpha1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{Name: "scenario", Namespace: "default"},
+			Spec: v1alpha1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+				Bundle:      "quay.io/redhat-appstudio/example-tekton-bundle:component-pipeline-pass",
+				Pipeline:    "component-pipeline-pass",
+				Params:      []v1alpha1.PipelineParameter{{Name: "param", Value: "value"}},
+				Contexts:    []v1alpha1.TestContext{{Name: "application", Description: "Application testing"}},
+			},
+			Status: v1alpha1.IntegrationTestScenarioStatus{Conditions: []metav1.Condition{validCondition}},
+		}
+
+		hub := &v1beta2.IntegrationTestScenario{}
+		Expect(scenario.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Name).To(Equal("scenario"))
+		Expect(hub.Spec.Application).To(Equal("application-sample"))
+		Expect(hub.Spec.ResolverRef).To(Equal(v1beta2.ResolverRef{
+			Resolver: "bundles",
+			Params: []v1beta2.ResolverParameter{
+				{Name: "bundle", Value: "quay.io/redhat-appstudio/example-tekton-bundle:component-pipeline-pass"},
+				{Name: "name", Value: "component-pipeline-pass"},
+				{Name: "kind", Value: "pipeline"},
+			},
+		}))
+		Expect(hub.Spec.Params).To(Equal([]v1beta2.PipelineParameter{{Name: "param", Value: "value"}}))
+		Expect(hub.Spec.Contexts).To(Equal([]v1beta2.TestContext{{Name: "application", Description: "Application testing"}}))
+		Expect(meta.IsStatusConditionTrue(hub.Status.Conditions, validCondition.Type)).To(BeTrue())
+
+		converted := &v1alpha1.IntegrationTestScenario{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec).To(Equal(scenario.Spec))
+		Expect(meta.IsStatusConditionTrue(converted.Status.Conditions, validCondition.Type)).To(BeTrue())
+	})
+
+	It("converts the v1beta1 resolverRef, params and contexts and back", func() {
+		scenario := &v1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{Name: "scenario", Namespace: "default"},
+			Spec: v1beta1.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+				ResolverRef: v1beta1.ResolverRef{
+					Resolver: "git",
+					Params: []v1beta1.ResolverParameter{
+						{Name: "url", Value: "https://github.com/redhat-appstudio/integration-examples.git"},
+						{Name: "revision", Value: "main"},
+						{Name: "pathInRepo", Value: "pipelines/integration_resolver_pipeline_pass.yaml"},
+					},
+				},
+				Params:   []v1beta1.PipelineParameter{{Name: "param", Values: []string{"value1", "value2"}}},
+				Contexts: []v1beta1.TestContext{{Name: "component_sample"}},
+			},
+			Status: v1beta1.IntegrationTestScenarioStatus{Conditions: []metav1.Condition{validCondition}},
+		}
+
+		hub := &v1beta2.IntegrationTestScenario{}
+		Expect(scenario.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.ResolverRef.Resolver).To(Equal("git"))
+		Expect(hub.Spec.ResolverRef.Params).To(HaveLen(3))
+		Expect(hub.Spec.Params).To(Equal([]v1beta2.PipelineParameter{{Name: "param", Values: []string{"value1", "value2"}}}))
+		Expect(hub.Spec.Contexts).To(Equal([]v1beta2.TestContext{{Name: "component_sample"}}))
+		Expect(meta.IsStatusConditionTrue(hub.Status.Conditions, validCondition.Type)).To(BeTrue())
+
+		converted := &v1beta1.IntegrationTestScenario{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec).To(Equal(scenario.Spec))
+		Expect(converted.Status.Conditions).To(HaveLen(1))
+	})
+
+	Context("when the v1beta2 IntegrationTestScenario has fields the older API versions don't have", func() {
+		var hubScenario *v1beta2.IntegrationTestScenario
+
+		BeforeEach(func() {
+			maxAllowedFailures := 2
+			completionTime := metav1.NewTime(time.Now().Truncate(time.Second))
+			hubScenario = &v1beta2.IntegrationTestScenario{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "scenario",
+					Namespace:   "default",
+					Annotations: map[string]string{"example.com/annotation": "value"},
+				},
+				Spec: v1beta2.IntegrationTestScenarioSpec{
+					Application: "application-sample",
+					ResolverRef: v1beta2.ResolverRef{
+						Resolver: "bundles",
+						Params: []v1beta2.ResolverParameter{
+							{Name: "bundle", Value: "quay.io/redhat-appstudio/example-tekton-bundle:component-pipeline-pass"},
+							{Name: "name", Value: "component-pipeline-pass"},
+							{Name: "kind", Value: "pipeline"},
+						},
+					},
+					ComponentSelector:  &v1beta2.ComponentSelector{Names: []string{"component-sample"}},
+					MaxAllowedFailures: &maxAllowedFailures,
+					Architecture:       "arm64",
+					Matrix:             []v1beta2.MatrixParameter{{Name: "platform", Values: []string{"linux", "windows"}}},
+					ServiceAccountName: "integration-tests",
+					Workspaces:         []v1beta2.PipelineWorkspace{{Name: "cache", PersistentVolumeClaim: "cache"}},
+					PodTemplate:        &v1beta2.PipelinePodTemplate{NodeSelector: map[string]string{"kubernetes.io/arch": "arm64"}},
+					TaskRunSpecs:       []v1beta2.PipelineTaskRunSpec{{PipelineTaskName: "test"}},
+					Secrets:            []v1beta2.PipelineSecret{{Name: "credentials", Workspace: "credentials"}},
+					ObserveOnlyRuns:    3,
+				},
+				Status: v1beta2.IntegrationTestScenarioStatus{
+					Conditions:   []metav1.Condition{validCondition},
+					ObservedRuns: 1,
+					RunHistory: []v1beta2.ScenarioRunResult{
+						{Snapshot: "snapshot", PipelineRun: "pipelinerun", Passed: true, CompletionTime: completionTime},
+					},
+					Trend: &v1beta2.ScenarioTrend{Runs: 1, PassRate: 100},
+				},
+			}
+		})
+
+		It("keeps the fields through a round trip through v1beta1", func() {
+			scenario := &v1beta1.IntegrationTestScenario{}
+			Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+			Expect(scenario.Annotations).To(HaveKey(v1beta2.ConversionDataAnnotation))
+			Expect(hubScenario.Annotations).NotTo(HaveKey(v1beta2.ConversionDataAnnotation))
+
+			converted := &v1beta2.IntegrationTestScenario{}
+			Expect(scenario.ConvertTo(converted)).To(Succeed())
+			Expect(converted.Annotations).To(Equal(hubScenario.Annotations))
+			Expect(scenario.Annotations).To(HaveKey(v1beta2.ConversionDataAnnotation))
+			Expect(converted.Spec).To(Equal(hubScenario.Spec))
+			Expect(converted.Status).To(Equal(hubScenario.Status))
+		})
+
+		It("keeps the fields through a round trip through v1alpha1", func() {
+			scenario := &v1alpha1.IntegrationTestScenario{}
+			Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+			Expect(scenario.Annotations).To(HaveKey(v1beta2.ConversionDataAnnotation))
+
+			converted := &v1beta2.IntegrationTestScenario{}
+			Expect(scenario.ConvertTo(converted)).To(Succeed())
+			Expect(converted.Annotations).To(Equal(hubScenario.Annotations))
+			Expect(converted.Spec).To(Equal(hubScenario.Spec))
+			Expect(converted.Status).To(Equal(hubScenario.Status))
+		})
+
+		It("keeps the changes made through the older API version", func() {
+			scenario := &v1beta1.IntegrationTestScenario{}
+			Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+			scenario.Spec.Application = "other-application"
+
+			converted := &v1beta2.IntegrationTestScenario{}
+			Expect(scenario.ConvertTo(converted)).To(Succeed())
+			Expect(converted.Spec.Application).To(Equal("other-application"))
+			Expect(converted.Spec.Architecture).To(Equal("arm64"))
+		})
+
+		It("doesn't add the annotation when there are no such fields", func() {
+			hubScenario.Annotations = nil
+			hubScenario.Spec = v1beta2.IntegrationTestScenarioSpec{Application: "application-sample"}
+			hubScenario.Status = v1beta2.IntegrationTestScenarioStatus{Conditions: []metav1.Condition{validCondition}}
+
+			scenario := &v1beta1.IntegrationTestScenario{}
+			Expect(scenario.ConvertFrom(hubScenario)).To(Succeed())
+			Expect(scenario.Annotations).To(BeNil())
+		})
+	})
+})
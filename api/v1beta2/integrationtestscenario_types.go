@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta2
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,11 +35,113 @@ type IntegrationTestScenarioSpec struct {
 	Params []PipelineParameter `json:"params,omitempty"`
 	// Contexts where this IntegrationTestScenario can be applied
 	Contexts []TestContext `json:"contexts,omitempty"`
+	// ComponentSelector restricts the IntegrationTestScenario to the Snapshots created for the builds of the selected
+	// components. The Snapshots which weren't created for the build of a component aren't restricted
+	// +optional
+	ComponentSelector *ComponentSelector `json:"componentSelector,omitempty"`
+	// MaxAllowedFailures is the number of failing test cases reported in the structured test output
+	// that are tolerated before the scenario is considered failed
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAllowedFailures *int `json:"maxAllowedFailures,omitempty"`
+	// Architecture is the architecture of the component images the scenario tests, e.g. amd64 or arm64.
+	// The images of the multi-arch components are replaced by their image for the architecture in the tested Snapshot
+	// +kubebuilder:validation:Pattern=^[a-z0-9]+$
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+	// Matrix lists the parameters whose combinations of values are each tested in a separate PipelineRun.
+	// The Snapshot passes the scenario only when the PipelineRuns of all matrix cells passed. The matrix can have
+	// at most 64 cells
+	// +optional
+	Matrix []MatrixParameter `json:"matrix,omitempty"`
+	// ServiceAccountName is the name of the ServiceAccount the integration PipelineRuns of the scenario run as.
+	// Defaults to the appstudio-pipeline ServiceAccount
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Workspaces to bind to the workspaces declared by the pipeline
+	// +optional
+	Workspaces []PipelineWorkspace `json:"workspaces,omitempty"`
+	// PodTemplate is the template of the pods running the tasks of the integration PipelineRuns
+	// +optional
+	PodTemplate *PipelinePodTemplate `json:"podTemplate,omitempty"`
+	// TaskRunSpecs override the compute resources of the tasks of the integration PipelineRuns
+	// +optional
+	TaskRunSpecs []PipelineTaskRunSpec `json:"taskRunSpecs,omitempty"`
+	// Secrets of the namespace bound to the integration PipelineRuns as workspaces or environment variables
+	// +optional
+	Secrets []PipelineSecret `json:"secrets,omitempty"`
+	// SnapshotWorkspace is the name of the workspace declared by the pipeline the Snapshot is delivered in as the
+	// snapshot.json file, instead of the SNAPSHOT param which can exceed the size limits of Tekton params for
+	// applications with many components
+	// +optional
+	SnapshotWorkspace string `json:"snapshotWorkspace,omitempty"`
+	// Suspended temporarily excludes the IntegrationTestScenario from the testing of the Snapshots without deleting
+	// it, the suspended scenario isn't run nor required for the Snapshots to pass
+	// +optional
+	Suspended bool `json:"suspended,omitempty"`
+	// ObserveOnlyRuns is the number of the first runs of the IntegrationTestScenario which only observe the Snapshots,
+	// their results are recorded and reported as neutral without gating the Snapshots. The scenario gates the
+	// Snapshots once these runs were started
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ObserveOnlyRuns int `json:"observeOnlyRuns,omitempty"`
+	// QuarantineFlaky makes the IntegrationTestScenario non-blocking while it is flagged as flaky, until the flag
+	// is cleared by a human
+	// +optional
+	QuarantineFlaky bool `json:"quarantineFlaky,omitempty"`
 }
 
 // IntegrationTestScenarioStatus defines the observed state of IntegrationTestScenario
 type IntegrationTestScenarioStatus struct {
 	Conditions []metav1.Condition `json:"conditions"`
+	// ObservedRuns is the number of the observe-only runs of the IntegrationTestScenario started so far
+	// +optional
+	ObservedRuns int `json:"observedRuns,omitempty"`
+	// RunHistory contains the results of the latest finished runs of the IntegrationTestScenario, it is used to
+	// detect the flaky scenarios and to compute the trends of the scenario
+	// +optional
+	RunHistory []ScenarioRunResult `json:"runHistory,omitempty"`
+	// Trend contains the rolling pass rate and duration of the runs of the IntegrationTestScenario
+	// +optional
+	Trend *ScenarioTrend `json:"trend,omitempty"`
+}
+
+// ScenarioTrend contains the trends computed from the latest finished runs of an IntegrationTestScenario
+type ScenarioTrend struct {
+	// Runs is the number of the runs the trends are computed from
+	Runs int `json:"runs"`
+	// PassRate is the percentage of the runs which passed
+	PassRate int `json:"passRate"`
+	// AverageDuration is the average duration of the runs
+	// +optional
+	AverageDuration metav1.Duration `json:"averageDuration,omitempty"`
+}
+
+// ScenarioRunResult contains the result of a finished run of an IntegrationTestScenario for a Snapshot
+type ScenarioRunResult struct {
+	// Snapshot is the name of the Snapshot the IntegrationTestScenario was run for
+	Snapshot string `json:"snapshot"`
+	// PipelineRun is the name of the integration PipelineRun of the run
+	PipelineRun string `json:"pipelineRun"`
+	// Passed is set when the run passed
+	// +optional
+	Passed bool `json:"passed,omitempty"`
+	// CompletionTime is the time the run finished
+	CompletionTime metav1.Time `json:"completionTime"`
+	// Duration is the time the run took, from the start of its PipelineRun
+	// +optional
+	Duration metav1.Duration `json:"duration,omitempty"`
+}
+
+// ComponentSelector selects components by their names or labels, a component is selected when it matches either
+type ComponentSelector struct {
+	// Names of the selected components
+	// +optional
+	Names []string `json:"names,omitempty"`
+	// LabelSelector selects the components by their labels
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
 }
 
 // PipelineParameter contains the name and values of a Tekton Pipeline parameter
@@ -47,6 +151,89 @@ type PipelineParameter struct {
 	Values []string `json:"values,omitempty"`
 }
 
+// PipelinePodTemplate contains the scheduling and security settings of the pods running the tasks of the Tekton Pipeline
+type PipelinePodTemplate struct {
+	// NodeSelector must match the labels of the nodes the pods are scheduled on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the pods
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// SecurityContext of the pods
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+}
+
+// PipelineSecret contains the name of a Secret of the namespace and the way it's bound to the Tekton Pipeline
+type PipelineSecret struct {
+	Name string `json:"name"`
+	// Workspace is the name of the workspace declared by the pipeline the Secret is bound to
+	// +optional
+	Workspace string `json:"workspace,omitempty"`
+	// Env lists the environment variables of the pipeline steps set to the values of keys of the Secret
+	// +optional
+	Env []SecretEnvVar `json:"env,omitempty"`
+}
+
+// SecretEnvVar contains the name of an environment variable and the key of the Secret holding its value
+type SecretEnvVar struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// PipelineTaskRunSpec contains the name of a task of the Tekton Pipeline and the compute resources of its TaskRun
+type PipelineTaskRunSpec struct {
+	PipelineTaskName string `json:"pipelineTaskName"`
+	// ComputeResources of the TaskRun of the pipeline task
+	// +optional
+	ComputeResources *corev1.ResourceRequirements `json:"computeResources,omitempty"`
+}
+
+// PipelineWorkspace contains the name of a workspace declared by the Tekton Pipeline and the volume bound to it.
+// The workspace is bound to an emptyDir volume when none of the volume sources is specified
+type PipelineWorkspace struct {
+	Name string `json:"name"`
+	// SubPath is the directory of the volume bound to the workspace
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// VolumeClaimTemplate is the template of the PersistentVolumeClaim created for every PipelineRun
+	// +optional
+	VolumeClaimTemplate *WorkspaceVolumeClaimTemplate `json:"volumeClaimTemplate,omitempty"`
+	// PersistentVolumeClaim is the name of an existing PersistentVolumeClaim bound to the workspace
+	// +optional
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+	// ConfigMap is the name of a ConfigMap bound to the workspace
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+	// Secret is the name of a Secret bound to the workspace
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// WorkspaceVolumeClaimTemplate contains the specification of the PersistentVolumeClaims created for the workspace
+type WorkspaceVolumeClaimTemplate struct {
+	// AccessModes of the PersistentVolumeClaim, defaults to ReadWriteOnce
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// StorageClassName is the name of the StorageClass of the PersistentVolumeClaim
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// Storage is the size of the volume requested by the PersistentVolumeClaim, e.g. 1Gi
+	// +required
+	Storage resource.Quantity `json:"storage"`
+}
+
+// MaxMatrixCells is the maximum number of matrix cells, i.e. combinations of the matrix parameter values, of an
+// IntegrationTestScenario, since every matrix cell is tested in its own PipelineRun.
+const MaxMatrixCells = 64
+
+// MatrixParameter contains the name of a Tekton Pipeline parameter and the values it's tested with
+type MatrixParameter struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
 // TestContext contains the name and values of a Test context
 type TestContext struct {
 	Name        string `json:"name"`
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta2
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -55,15 +56,104 @@ func (r *IntegrationTestScenario) ValidateCreate() (warnings admission.Warnings,
 		}
 	}
 
-	return nil, nil
+	if err := r.validateComponentSelector(); err != nil {
+		return nil, err
+	}
+
+	if err := r.validateMatrix(); err != nil {
+		return nil, err
+	}
+
+	return nil, r.validateWorkspaces()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *IntegrationTestScenario) ValidateUpdate(old runtime.Object) (warnings admission.Warnings, err error) {
-	return nil, nil
+	if err := r.validateComponentSelector(); err != nil {
+		return nil, err
+	}
+
+	if err := r.validateMatrix(); err != nil {
+		return nil, err
+	}
+
+	return nil, r.validateWorkspaces()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *IntegrationTestScenario) ValidateDelete() (warnings admission.Warnings, err error) {
 	return nil, nil
 }
+
+// validateComponentSelector ensures that the label selector of the ComponentSelector of the IntegrationTestScenario is valid.
+func (r *IntegrationTestScenario) validateComponentSelector() error {
+	if r.Spec.ComponentSelector == nil || r.Spec.ComponentSelector.LabelSelector == nil {
+		return nil
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(r.Spec.ComponentSelector.LabelSelector); err != nil {
+		return field.Invalid(field.NewPath("spec").Child("componentSelector").Child("labelSelector"),
+			r.Spec.ComponentSelector.LabelSelector, err.Error())
+	}
+
+	return nil
+}
+
+// validateMatrix ensures that the matrix of the IntegrationTestScenario doesn't have more than MaxMatrixCells cells.
+func (r *IntegrationTestScenario) validateMatrix() error {
+	cells := 1
+	for _, matrixParam := range r.Spec.Matrix {
+		cells *= len(matrixParam.Values)
+		if cells > MaxMatrixCells {
+			return field.TooMany(field.NewPath("spec").Child("matrix"), cells, MaxMatrixCells)
+		}
+	}
+
+	return nil
+}
+
+// validateWorkspaces ensures that the workspaces of the IntegrationTestScenario have unique names and are each bound
+// to at most one volume source, that its Secrets are each bound to a workspace not used by another volume or to
+// environment variables, and that the Snapshot workspace isn't used by another volume.
+func (r *IntegrationTestScenario) validateWorkspaces() error {
+	workspaceNames := map[string]bool{}
+	for i, workspace := range r.Spec.Workspaces {
+		workspacePath := field.NewPath("spec").Child("workspaces").Index(i)
+		if workspaceNames[workspace.Name] {
+			return field.Duplicate(workspacePath.Child("name"), workspace.Name)
+		}
+		workspaceNames[workspace.Name] = true
+
+		volumeSources := 0
+		for _, isSet := range []bool{workspace.VolumeClaimTemplate != nil, workspace.PersistentVolumeClaim != "",
+			workspace.ConfigMap != "", workspace.Secret != ""} {
+			if isSet {
+				volumeSources++
+			}
+		}
+		if volumeSources > 1 {
+			return field.Invalid(workspacePath, workspace.Name,
+				"a workspace can only be bound to one of volumeClaimTemplate, persistentVolumeClaim, configMap or secret")
+		}
+	}
+
+	for i, secret := range r.Spec.Secrets {
+		secretPath := field.NewPath("spec").Child("secrets").Index(i)
+		if secret.Workspace == "" && len(secret.Env) == 0 {
+			return field.Invalid(secretPath, secret.Name, "a secret has to be bound to a workspace or to environment variables")
+		}
+		if secret.Workspace == "" {
+			continue
+		}
+		if workspaceNames[secret.Workspace] {
+			return field.Duplicate(secretPath.Child("workspace"), secret.Workspace)
+		}
+		workspaceNames[secret.Workspace] = true
+	}
+
+	if workspaceNames[r.Spec.SnapshotWorkspace] {
+		return field.Duplicate(field.NewPath("spec").Child("snapshotWorkspace"), r.Spec.SnapshotWorkspace)
+	}
+
+	return nil
+}
@@ -19,9 +19,12 @@ package v1beta2
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("IntegrationTestScenario webhook", func() {
@@ -88,4 +91,107 @@ var _ = Describe("IntegrationTestScenario webhook", func() {
 		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
 	})
 
+	It("should fail to create scenario with a workspace bound to several volumes", func() {
+		integrationTestScenario.Spec.Workspaces = []PipelineWorkspace{
+			{Name: "shared", PersistentVolumeClaim: "shared-pvc", Secret: "shared-secret"},
+		}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
+	})
+
+	It("should fail to create scenario with duplicated workspaces", func() {
+		integrationTestScenario.Spec.Workspaces = []PipelineWorkspace{{Name: "shared"}, {Name: "shared"}}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
+	})
+
+	It("should fail to create scenario with too many matrix cells", func() {
+		integrationTestScenario.Spec.Matrix = []MatrixParameter{
+			{Name: "OCP_VERSION", Values: []string{"4.12", "4.13", "4.14", "4.15", "4.16", "4.17", "4.18", "4.19"}},
+			{Name: "DATABASE", Values: []string{"postgres", "mysql", "mariadb", "sqlite", "mongodb", "redis", "oracle", "db2", "mssql"}},
+		}
+		err := k8sClient.Create(ctx, integrationTestScenario)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.matrix"))
+
+		integrationTestScenario.Spec.Matrix[1].Values = integrationTestScenario.Spec.Matrix[1].Values[:8]
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).Should(Succeed())
+	})
+
+	It("should create scenario with workspaces", func() {
+		integrationTestScenario.Spec.Workspaces = []PipelineWorkspace{
+			{Name: "shared", VolumeClaimTemplate: &WorkspaceVolumeClaimTemplate{Storage: resource.MustParse("1Gi")}},
+			{Name: "cache"},
+		}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).Should(Succeed())
+	})
+
+	It("should fail to create scenario with a secret which isn't bound", func() {
+		integrationTestScenario.Spec.Secrets = []PipelineSecret{{Name: "api-keys"}}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
+	})
+
+	It("should fail to create scenario with a secret bound to a workspace already in use", func() {
+		integrationTestScenario.Spec.Workspaces = []PipelineWorkspace{{Name: "shared"}}
+		integrationTestScenario.Spec.Secrets = []PipelineSecret{{Name: "api-keys", Workspace: "shared"}}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
+	})
+
+	It("should fail to create scenario with a snapshot workspace already in use", func() {
+		integrationTestScenario.Spec.Workspaces = []PipelineWorkspace{{Name: "shared"}}
+		integrationTestScenario.Spec.SnapshotWorkspace = "shared"
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
+	})
+
+	It("should create scenario with secrets", func() {
+		integrationTestScenario.Spec.Secrets = []PipelineSecret{
+			{Name: "api-keys", Workspace: "api-keys", Env: []SecretEnvVar{{Name: "API_TOKEN", Key: "token"}}},
+		}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).Should(Succeed())
+
+		createdScenario := &IntegrationTestScenario{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(integrationTestScenario), createdScenario)).To(Succeed())
+		Expect(createdScenario.Spec.Secrets).To(Equal(integrationTestScenario.Spec.Secrets))
+	})
+
+	It("should fail to create scenario with an invalid component label selector", func() {
+		integrationTestScenario.Spec.ComponentSelector = &ComponentSelector{
+			LabelSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpIn},
+			}},
+		}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).ShouldNot(Succeed())
+	})
+
+	It("should create scenario with a component selector", func() {
+		integrationTestScenario.Spec.ComponentSelector = &ComponentSelector{
+			Names:         []string{"frontend"},
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ui"}},
+		}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).Should(Succeed())
+
+		createdScenario := &IntegrationTestScenario{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(integrationTestScenario), createdScenario)).To(Succeed())
+		Expect(createdScenario.Spec.ComponentSelector).To(Equal(integrationTestScenario.Spec.ComponentSelector))
+	})
+
+	It("should create scenario with a pod template and task compute resources", func() {
+		runAsUser := int64(1000)
+		integrationTestScenario.Spec.PodTemplate = &PipelinePodTemplate{
+			NodeSelector:    map[string]string{"node-role.kubernetes.io/e2e": ""},
+			Tolerations:     []corev1.Toleration{{Key: "e2e", Operator: corev1.TolerationOpExists}},
+			SecurityContext: &corev1.PodSecurityContext{RunAsUser: &runAsUser},
+		}
+		integrationTestScenario.Spec.TaskRunSpecs = []PipelineTaskRunSpec{{
+			PipelineTaskName: "e2e-tests",
+			ComputeResources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+		}}
+		Expect(k8sClient.Create(ctx, integrationTestScenario)).Should(Succeed())
+
+		createdScenario := &IntegrationTestScenario{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(integrationTestScenario), createdScenario)).To(Succeed())
+		Expect(*createdScenario.Spec.PodTemplate.SecurityContext.RunAsUser).To(Equal(runAsUser))
+		Expect(createdScenario.Spec.TaskRunSpecs[0].ComputeResources.Limits.Cpu().String()).To(Equal("4"))
+	})
+
 })
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SnapshotRunSucceededCondition is the condition of a SnapshotRun reporting the outcome of its integration tests.
+	SnapshotRunSucceededCondition = "Succeeded"
+
+	// SnapshotRunPendingReason is the reason of the SnapshotRuns whose integration PipelineRuns weren't created yet.
+	SnapshotRunPendingReason = "Pending"
+
+	// SnapshotRunRunningReason is the reason of the SnapshotRuns whose integration PipelineRuns are running.
+	SnapshotRunRunningReason = "Running"
+
+	// SnapshotRunPassedReason is the reason of the SnapshotRuns whose integration tests all passed.
+	SnapshotRunPassedReason = "Passed"
+
+	// SnapshotRunFailedReason is the reason of the SnapshotRuns with failed integration tests.
+	SnapshotRunFailedReason = "Failed"
+
+	// SnapshotRunInvalidReason is the reason of the SnapshotRuns which can't be run,
+	// e.g. since their Snapshot or IntegrationTestScenario doesn't exist.
+	SnapshotRunInvalidReason = "Invalid"
+)
+
+// SnapshotRunSpec defines the desired state of SnapshotRun
+type SnapshotRunSpec struct {
+	// Snapshot is the name of the Snapshot the integration tests are run against
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	// +required
+	Snapshot string `json:"snapshot"`
+	// Scenario is the name of the IntegrationTestScenario to run, all of the IntegrationTestScenarios
+	// applicable to the Snapshot are run when it's not set
+	// +optional
+	Scenario string `json:"scenario,omitempty"`
+	// ReportStatus requests the results of the integration tests to be reported to the git provider, even when
+	// the Snapshot was created for a push event
+	// +optional
+	ReportStatus bool `json:"reportStatus,omitempty"`
+}
+
+// SnapshotRunStatus defines the observed state of SnapshotRun
+type SnapshotRunStatus struct {
+	// StartTime is the time the integration PipelineRuns of the SnapshotRun were created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time all of the integration tests of the SnapshotRun finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Scenarios holds the status of each of the integration tests run
+	// +optional
+	Scenarios []SnapshotRunScenarioStatus `json:"scenarios,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SnapshotRunScenarioStatus contains the status of the integration test of an IntegrationTestScenario
+type SnapshotRunScenarioStatus struct {
+	// Name is the name of the IntegrationTestScenario
+	Name string `json:"name"`
+	// PipelineRun is the name of the integration PipelineRun created for the IntegrationTestScenario
+	// +optional
+	PipelineRun string `json:"pipelineRun,omitempty"`
+	// Status is the status of the integration test, as recorded in the test status of the Snapshot
+	// +optional
+	Status string `json:"status,omitempty"`
+	// Details holds the details of the status of the integration test
+	// +optional
+	Details string `json:"details,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshot`
+// +kubebuilder:printcolumn:name="Scenario",type=string,JSONPath=`.spec.scenario`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Succeeded")].reason`
+
+// SnapshotRun is the Schema for the snapshotruns API, requesting integration tests to be run against a Snapshot
+type SnapshotRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnapshotRunSpec   `json:"spec,omitempty"`
+	Status SnapshotRunStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SnapshotRunList contains a list of SnapshotRun
+type SnapshotRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnapshotRun `json:"items"`
+}
+
+// HasStarted returns true if the integration PipelineRuns of the SnapshotRun were created.
+func (r *SnapshotRun) HasStarted() bool {
+	return r.Status.StartTime != nil
+}
+
+// HasFinished returns true if the SnapshotRun finished or is invalid.
+func (r *SnapshotRun) HasFinished() bool {
+	for _, condition := range r.Status.Conditions {
+		if condition.Type == SnapshotRunSucceededCondition {
+			return condition.Status != metav1.ConditionUnknown
+		}
+	}
+
+	return false
+}
+
+func init() {
+	SchemeBuilder.Register(&SnapshotRun{}, &SnapshotRunList{})
+}
@@ -22,10 +22,146 @@ limitations under the License.
 package v1beta2
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentSelector) DeepCopyInto(out *ComponentSelector) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSelector.
+func (in *ComponentSelector) DeepCopy() *ComponentSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntegrationPolicy) DeepCopyInto(out *IntegrationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationPolicy.
+func (in *IntegrationPolicy) DeepCopy() *IntegrationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IntegrationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IntegrationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntegrationPolicyList) DeepCopyInto(out *IntegrationPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IntegrationPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationPolicyList.
+func (in *IntegrationPolicyList) DeepCopy() *IntegrationPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(IntegrationPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IntegrationPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntegrationPolicySpec) DeepCopyInto(out *IntegrationPolicySpec) {
+	*out = *in
+	if in.RequiredScenarios != nil {
+		in, out := &in.RequiredScenarios, &out.RequiredScenarios
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OptionalScenarios != nil {
+		in, out := &in.OptionalScenarios, &out.OptionalScenarios
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxAllowedFailures != nil {
+		in, out := &in.MaxAllowedFailures, &out.MaxAllowedFailures
+		*out = new(int)
+		**out = **in
+	}
+	if in.AutoRelease != nil {
+		in, out := &in.AutoRelease, &out.AutoRelease
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Quorums != nil {
+		in, out := &in.Quorums, &out.Quorums
+		*out = make([]ScenarioQuorum, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReleaseWindows != nil {
+		in, out := &in.ReleaseWindows, &out.ReleaseWindows
+		*out = make([]ReleaseWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.TestingDeadline != nil {
+		in, out := &in.TestingDeadline, &out.TestingDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationPolicySpec.
+func (in *IntegrationPolicySpec) DeepCopy() *IntegrationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IntegrationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntegrationTestScenario) DeepCopyInto(out *IntegrationTestScenario) {
 	*out = *in
@@ -101,6 +237,49 @@ func (in *IntegrationTestScenarioSpec) DeepCopyInto(out *IntegrationTestScenario
 		*out = make([]TestContext, len(*in))
 		copy(*out, *in)
 	}
+	if in.ComponentSelector != nil {
+		in, out := &in.ComponentSelector, &out.ComponentSelector
+		*out = new(ComponentSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxAllowedFailures != nil {
+		in, out := &in.MaxAllowedFailures, &out.MaxAllowedFailures
+		*out = new(int)
+		**out = **in
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make([]MatrixParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workspaces != nil {
+		in, out := &in.Workspaces, &out.Workspaces
+		*out = make([]PipelineWorkspace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PipelinePodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TaskRunSpecs != nil {
+		in, out := &in.TaskRunSpecs, &out.TaskRunSpecs
+		*out = make([]PipelineTaskRunSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]PipelineSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationTestScenarioSpec.
@@ -123,6 +302,18 @@ func (in *IntegrationTestScenarioStatus) DeepCopyInto(out *IntegrationTestScenar
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RunHistory != nil {
+		in, out := &in.RunHistory, &out.RunHistory
+		*out = make([]ScenarioRunResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Trend != nil {
+		in, out := &in.Trend, &out.Trend
+		*out = new(ScenarioTrend)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationTestScenarioStatus.
@@ -135,6 +326,26 @@ func (in *IntegrationTestScenarioStatus) DeepCopy() *IntegrationTestScenarioStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixParameter) DeepCopyInto(out *MatrixParameter) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixParameter.
+func (in *MatrixParameter) DeepCopy() *MatrixParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineParameter) DeepCopyInto(out *PipelineParameter) {
 	*out = *in
@@ -155,6 +366,116 @@ func (in *PipelineParameter) DeepCopy() *PipelineParameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinePodTemplate) DeepCopyInto(out *PipelinePodTemplate) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinePodTemplate.
+func (in *PipelinePodTemplate) DeepCopy() *PipelinePodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinePodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineSecret) DeepCopyInto(out *PipelineSecret) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]SecretEnvVar, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSecret.
+func (in *PipelineSecret) DeepCopy() *PipelineSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineTaskRunSpec) DeepCopyInto(out *PipelineTaskRunSpec) {
+	*out = *in
+	if in.ComputeResources != nil {
+		in, out := &in.ComputeResources, &out.ComputeResources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineTaskRunSpec.
+func (in *PipelineTaskRunSpec) DeepCopy() *PipelineTaskRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineTaskRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineWorkspace) DeepCopyInto(out *PipelineWorkspace) {
+	*out = *in
+	if in.VolumeClaimTemplate != nil {
+		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
+		*out = new(WorkspaceVolumeClaimTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineWorkspace.
+func (in *PipelineWorkspace) DeepCopy() *PipelineWorkspace {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineWorkspace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseWindow) DeepCopyInto(out *ReleaseWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseWindow.
+func (in *ReleaseWindow) DeepCopy() *ReleaseWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResolverParameter) DeepCopyInto(out *ResolverParameter) {
 	*out = *in
@@ -190,6 +511,198 @@ func (in *ResolverRef) DeepCopy() *ResolverRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioQuorum) DeepCopyInto(out *ScenarioQuorum) {
+	*out = *in
+	if in.Scenarios != nil {
+		in, out := &in.Scenarios, &out.Scenarios
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioQuorum.
+func (in *ScenarioQuorum) DeepCopy() *ScenarioQuorum {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioQuorum)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioRunResult) DeepCopyInto(out *ScenarioRunResult) {
+	*out = *in
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioRunResult.
+func (in *ScenarioRunResult) DeepCopy() *ScenarioRunResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioRunResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioTrend) DeepCopyInto(out *ScenarioTrend) {
+	*out = *in
+	out.AverageDuration = in.AverageDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioTrend.
+func (in *ScenarioTrend) DeepCopy() *ScenarioTrend {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioTrend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretEnvVar) DeepCopyInto(out *SecretEnvVar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretEnvVar.
+func (in *SecretEnvVar) DeepCopy() *SecretEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRun) DeepCopyInto(out *SnapshotRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRun.
+func (in *SnapshotRun) DeepCopy() *SnapshotRun {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnapshotRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRunList) DeepCopyInto(out *SnapshotRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SnapshotRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRunList.
+func (in *SnapshotRunList) DeepCopy() *SnapshotRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SnapshotRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRunScenarioStatus) DeepCopyInto(out *SnapshotRunScenarioStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRunScenarioStatus.
+func (in *SnapshotRunScenarioStatus) DeepCopy() *SnapshotRunScenarioStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRunScenarioStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRunSpec) DeepCopyInto(out *SnapshotRunSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRunSpec.
+func (in *SnapshotRunSpec) DeepCopy() *SnapshotRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRunStatus) DeepCopyInto(out *SnapshotRunStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Scenarios != nil {
+		in, out := &in.Scenarios, &out.Scenarios
+		*out = make([]SnapshotRunScenarioStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRunStatus.
+func (in *SnapshotRunStatus) DeepCopy() *SnapshotRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestContext) DeepCopyInto(out *TestContext) {
 	*out = *in
@@ -204,3 +717,24 @@ func (in *TestContext) DeepCopy() *TestContext {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceVolumeClaimTemplate) DeepCopyInto(out *WorkspaceVolumeClaimTemplate) {
+	*out = *in
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	out.Storage = in.Storage.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceVolumeClaimTemplate.
+func (in *WorkspaceVolumeClaimTemplate) DeepCopy() *WorkspaceVolumeClaimTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceVolumeClaimTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,13 +20,23 @@ import (
 	"context"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/tekton"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// PipelineRunSnapshotScenarioIndex is the name of the index of the integration PipelineRuns by their Snapshot and
+// IntegrationTestScenario, the indexed values are formatted by PipelineRunSnapshotScenarioIndexValue.
+const PipelineRunSnapshotScenarioIndex = "metadata.labels.snapshotScenario"
+
+// SnapshotContentHashIndex is the name of the index of the Snapshots by the hash of their components.
+const SnapshotContentHashIndex = "metadata.labels.contentHash"
+
 // SetupReleasePlanCache adds a new index field to be able to search ReleasePlans by application.
 func SetupReleasePlanCache(mgr ctrl.Manager) error {
 	releasePlanIndexFunc := func(obj client.Object) []string {
@@ -67,6 +77,30 @@ func SetupSnapshotCache(mgr ctrl.Manager) error {
 		"spec.application", snapshotIndexFunc)
 }
 
+// SetupSnapshotContentHashCache adds a new index field to be able to search Snapshots by the hash of their components.
+func SetupSnapshotContentHashCache(mgr ctrl.Manager) error {
+	snapshotContentHashIndexFunc := func(obj client.Object) []string {
+		contentHash, ok := obj.GetLabels()[gitops.SnapshotContentHashLabel]
+		if !ok {
+			return nil
+		}
+		return []string{contentHash}
+	}
+
+	return mgr.GetCache().IndexField(context.Background(), &applicationapiv1alpha1.Snapshot{},
+		SnapshotContentHashIndex, snapshotContentHashIndexFunc)
+}
+
+// SetupSnapshotRunCache adds a new index field to be able to search SnapshotRuns by Snapshot.
+func SetupSnapshotRunCache(mgr ctrl.Manager) error {
+	snapshotRunIndexFunc := func(obj client.Object) []string {
+		return []string{obj.(*v1beta2.SnapshotRun).Spec.Snapshot}
+	}
+
+	return mgr.GetCache().IndexField(context.Background(), &v1beta2.SnapshotRun{},
+		"spec.snapshot", snapshotRunIndexFunc)
+}
+
 // SetupIntegrationTestScenarioCache adds a new index field to be able to search IntegrationTestScenarios by Application.
 func SetupIntegrationTestScenarioCache(mgr ctrl.Manager) error {
 	integrationTestScenariosIndexFunc := func(obj client.Object) []string {
@@ -76,3 +110,28 @@ func SetupIntegrationTestScenarioCache(mgr ctrl.Manager) error {
 	return mgr.GetCache().IndexField(context.Background(), &v1beta2.IntegrationTestScenario{},
 		"spec.application", integrationTestScenariosIndexFunc)
 }
+
+// SetupPipelineRunCache adds a new index field to be able to search integration PipelineRuns by Snapshot and
+// IntegrationTestScenario without filtering all of the PipelineRuns of the namespace by their labels.
+func SetupPipelineRunCache(mgr ctrl.Manager) error {
+	pipelineRunIndexFunc := func(obj client.Object) []string {
+		labels := obj.GetLabels()
+		if labels[tekton.PipelinesTypeLabel] != tekton.PipelineTypeTest {
+			return nil
+		}
+		snapshotName, scenarioName := labels[tekton.SnapshotNameLabel], labels[tekton.ScenarioNameLabel]
+		if snapshotName == "" || scenarioName == "" {
+			return nil
+		}
+		return []string{PipelineRunSnapshotScenarioIndexValue(snapshotName, scenarioName)}
+	}
+
+	return mgr.GetCache().IndexField(context.Background(), &tektonv1.PipelineRun{},
+		PipelineRunSnapshotScenarioIndex, pipelineRunIndexFunc)
+}
+
+// PipelineRunSnapshotScenarioIndexValue returns the value of the PipelineRunSnapshotScenarioIndex of the integration
+// PipelineRuns of the Snapshot and IntegrationTestScenario.
+func PipelineRunSnapshotScenarioIndexValue(snapshotName, scenarioName string) string {
+	return snapshotName + "/" + scenarioName
+}
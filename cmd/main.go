@@ -17,12 +17,33 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
+	"path/filepath"
+	"time"
+	// embedded so that the release windows of the integration policies can be evaluated in any time zone
+	_ "time/tzdata"
 
+	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/internal/controller"
+	"github.com/konflux-ci/integration-service/internal/webhook"
+	"github.com/konflux-ci/integration-service/pkg/buildwebhook"
+	"github.com/konflux-ci/integration-service/pkg/diagnostics"
+	"github.com/konflux-ci/integration-service/pkg/dryrun"
+	"github.com/konflux-ci/integration-service/pkg/githubwebhook"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
 	imetrics "github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/ratelimit"
+	"github.com/konflux-ci/integration-service/pkg/readiness"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
+	"github.com/konflux-ci/integration-service/pkg/statusapi"
+	"github.com/konflux-ci/integration-service/pkg/tektonresults"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -53,6 +74,9 @@ import (
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// webhookCertDir is the directory of the TLS certificate and key of the webhook server of the manager.
+	webhookCertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
 )
 
 func init() {
@@ -72,8 +96,77 @@ func main() {
 	var enableHttp2 bool
 	var enableLeaderElection bool
 	var probeAddr string
+	var statusAPIAddr string
+	var statusAPICertFile string
+	var statusAPIKeyFile string
+	var githubWebhookAddr string
+	var githubWebhookCertFile string
+	var githubWebhookKeyFile string
+	var buildWebhookAddr string
+	var buildWebhookCertFile string
+	var buildWebhookKeyFile string
+	var pipelineConcurrency int
+	var snapshotConcurrency int
+	var scenarioConcurrency int
+	var pipelineRunCreationQPS float64
+	var pipelineRunCreationBurst int
+	var watchNamespaces string
+	var ignoreNamespaces string
+	var watchNamespaceSelector string
+	var controllerServiceAccount string
+	var shardCount int
+	var shardIndex int
+	var readinessCheckGitCredentials bool
+	var gracefulShutdownTimeout time.Duration
+	var dryRun bool
+	var eventClasses string
+	var enableDebugEndpoints bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&statusAPIAddr, "status-api-bind-address", "0",
+		"The address the snapshot status API binds to. Use 0 to disable the status API.")
+	flag.StringVar(&statusAPICertFile, "status-api-cert-file", "", "The TLS certificate file of the status API.")
+	flag.StringVar(&statusAPIKeyFile, "status-api-key-file", "", "The TLS key file of the status API.")
+	flag.StringVar(&githubWebhookAddr, "github-webhook-bind-address", "0",
+		"The address the GitHub webhook endpoint re-running the re-requested check runs binds to. Use 0 to disable it.")
+	flag.StringVar(&githubWebhookCertFile, "github-webhook-cert-file", "", "The TLS certificate file of the GitHub webhook endpoint.")
+	flag.StringVar(&githubWebhookKeyFile, "github-webhook-key-file", "", "The TLS key file of the GitHub webhook endpoint.")
+	flag.StringVar(&buildWebhookAddr, "build-webhook-bind-address", "0",
+		"The address the webhook endpoint receiving the images built by external CI systems binds to. Use 0 to disable it.")
+	flag.StringVar(&buildWebhookCertFile, "build-webhook-cert-file", "", "The TLS certificate file of the build webhook endpoint.")
+	flag.StringVar(&buildWebhookKeyFile, "build-webhook-key-file", "", "The TLS key file of the build webhook endpoint.")
+	flag.IntVar(&pipelineConcurrency, "pipeline-max-concurrent-reconciles", 1,
+		"The maximum number of PipelineRuns reconciled concurrently by each of the build and integration pipeline controllers.")
+	flag.IntVar(&snapshotConcurrency, "snapshot-max-concurrent-reconciles", 1,
+		"The maximum number of Snapshots reconciled concurrently by each of the snapshot and status report controllers.")
+	flag.IntVar(&scenarioConcurrency, "scenario-max-concurrent-reconciles", 1,
+		"The maximum number of IntegrationTestScenarios reconciled concurrently by the scenario controller.")
+	flag.Float64Var(&pipelineRunCreationQPS, "pipelinerun-creation-qps", 0,
+		"The maximum number of integration PipelineRuns created per second by the operator. Use 0 to disable the limit.")
+	flag.IntVar(&pipelineRunCreationBurst, "pipelinerun-creation-burst", 10,
+		"The maximum number of integration PipelineRuns created in a burst when their creation is rate limited.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"The comma separated list of the namespaces watched by the controllers. All namespaces are watched when empty.")
+	flag.StringVar(&ignoreNamespaces, "ignore-namespaces", "",
+		"The comma separated list of the namespaces which aren't watched by the controllers.")
+	flag.StringVar(&watchNamespaceSelector, "watch-namespace-selector", "",
+		"The label selector of the namespaces watched by the controllers, e.g. tenant-group=a.")
+	flag.IntVar(&shardCount, "shard-count", 1,
+		"The number of active replicas the namespaces are sharded across by the hash of their name. Use 1 to disable sharding.")
+	flag.IntVar(&shardIndex, "shard-index", -1,
+		"The index of the shard reconciled by this replica. The ordinal of the replica in its hostname is used when negative.")
+	flag.StringVar(&controllerServiceAccount, "controller-service-account", webhook.GetControllerServiceAccount(),
+		"The user name of the service account the operator runs as, which is allowed to change the components of Snapshots.")
+	flag.BoolVar(&readinessCheckGitCredentials, "readiness-check-git-credentials", false,
+		"Report the operator as not ready while the credentials of the GitHub App of Pipelines as Code aren't available.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", operations.DefaultShutdownDrainTimeout,
+		"The longest time the reconciliations in flight are given to finish their current operation when the operator shuts down.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Log the decisions of the controllers without mutating the cluster or calling the git providers and the other external services.")
+	flag.StringVar(&eventClasses, "event-classes", reasons.AllEventClasses,
+		"The comma separated list of the classes of the events emitted by the controllers, among lifecycle, verdict and error.")
+	flag.BoolVar(&enableDebugEndpoints, "enable-debug-endpoints", false,
+		"Serve the pprof and expvar endpoints and a dump of the controller queues and of the cache on the health probe address.")
 	flag.BoolVar(&enableHttp2, "enable-http2", false, "Enable HTTP/2 for the metrics and webhook servers.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
@@ -88,16 +181,69 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	namespaceScope, err := helpers.NewNamespaceScope(watchNamespaces, ignoreNamespaces, watchNamespaceSelector)
+	if err != nil {
+		setupLog.Error(err, "unable to configure the watched namespaces")
+		os.Exit(1)
+	}
+
+	if shardCount > 1 && shardIndex < 0 {
+		hostname, err := os.Hostname()
+		if err == nil {
+			shardIndex, err = helpers.ShardIndexFromHostname(hostname)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to get the shard index from the hostname")
+			os.Exit(1)
+		}
+	}
+	namespaceShard, err := helpers.NewNamespaceShard(max(shardIndex, 0), shardCount)
+	if err != nil {
+		setupLog.Error(err, "unable to configure the namespace shard")
+		os.Exit(1)
+	}
+	if namespaceShard.IsSharded() {
+		setupLog.Info("reconciling a shard of the namespaces", "shardIndex", namespaceShard.Index, "shardCount", namespaceShard.Count)
+	}
+
+	enabledEventClasses, err := reasons.ParseEventClasses(eventClasses)
+	if err != nil {
+		setupLog.Error(err, "unable to configure the emitted event classes")
+		os.Exit(1)
+	}
+
+	operations.SetShutdownDrainTimeout(gracefulShutdownTimeout)
+	ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(pipelineRunCreationQPS, pipelineRunCreationBurst, ratelimit.DefaultMaxWait))
+
+	// the probes are served by the diagnostics server together with the debug endpoints when they are enabled
+	managerProbeAddr := probeAddr
+	if enableDebugEndpoints {
+		managerProbeAddr = "0"
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		HealthProbeBindAddress: probeAddr,
+		HealthProbeBindAddress: managerProbeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "f1944211.redhat.com",
+		LeaderElectionID:       namespaceShard.LeaderElectionID("f1944211.redhat.com"),
+		// the manager waits for the reconciliations in flight at least as long as they are given to finish
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
 		},
+		Cache: namespaceScope.CacheOptions(imetrics.IntegrationServiceNamespaceName),
+		// the concurrency is configured by the kind reconciled by the controllers, so it is shared by the
+		// controllers reconciling the same kind
+		Controller: config.Controller{
+			GroupKindConcurrency: map[string]int{
+				"PipelineRun.tekton.dev":                       pipelineConcurrency,
+				"Snapshot.appstudio.redhat.com":                snapshotConcurrency,
+				"IntegrationTestScenario.appstudio.redhat.com": scenarioConcurrency,
+			},
+		},
 		WebhookServer: crwebhook.NewServer(crwebhook.Options{
-			Port: 9443,
+			Port:    9443,
+			CertDir: webhookCertDir,
 			TLSOpts: []func(*tls.Config){
 				func(c *tls.Config) {
 					if !enableHttp2 {
@@ -112,7 +258,30 @@ func main() {
 		os.Exit(1)
 	}
 
-	err = controllers.SetupControllers(mgr)
+	// the controllers and the webhook endpoints mutating the cluster get a manager which doesn't in dry-run mode
+	controllerMgr := mgr
+	if dryRun {
+		dryrun.Enable()
+		controllerMgr = dryrun.NewManager(mgr, ctrl.Log.WithName("dryrun"))
+		setupLog.Info("running in dry-run mode, the cluster isn't mutated and the external services aren't called")
+	}
+	if len(enabledEventClasses) < len(reasons.EventClasses) {
+		controllerMgr = reasons.NewEventFilteringManager(controllerMgr, enabledEventClasses)
+		setupLog.Info("emitting only some classes of events", "eventClasses", enabledEventClasses)
+	}
+
+	imageVerifier, err := signature.NewVerifierFromEnv()
+	if err != nil {
+		setupLog.Error(err, "unable to set up the image verification")
+		os.Exit(1)
+	}
+	resultsClient, err := tektonresults.NewClientFromEnv()
+	if err != nil {
+		setupLog.Error(err, "unable to set up the Tekton Results client")
+		os.Exit(1)
+	}
+	helpers.SetTektonResultsClient(resultsClient)
+	err = controllers.SetupControllers(controllerMgr, imageVerifier, namespaceScope.Predicate(mgr.GetClient()), namespaceShard.Predicate())
 	if err != nil {
 		setupLog.Error(err, "unable to setup controllers")
 		os.Exit(1)
@@ -121,15 +290,88 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "IntegrationTestScenario")
 		os.Exit(1)
 	}
+	if err = webhook.SetupSnapshotWebhookWithManager(mgr, controllerServiceAccount); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Snapshot")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
+	if statusAPIAddr != "0" {
+		statusAPILogger := ctrl.Log.WithName("statusapi")
+		statusAPIServer := httpserver.NewServer("status API", statusAPIAddr, statusAPICertFile, statusAPIKeyFile,
+			statusapi.NewServer(mgr.GetClient(), statusapi.NewKubernetesAuthorizer(mgr.GetClient()), statusAPILogger).Handler(),
+			statusAPILogger)
+		if err := mgr.Add(statusAPIServer); err != nil {
+			setupLog.Error(err, "unable to set up the status API")
+			os.Exit(1)
+		}
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+
+	if githubWebhookAddr != "0" {
+		// the secret of the webhooks is read through the API reader since its namespace may not be in the cache
+		githubWebhookLogger := ctrl.Log.WithName("githubwebhook")
+		githubWebhookServer := httpserver.NewServer("GitHub webhook", githubWebhookAddr, githubWebhookCertFile, githubWebhookKeyFile,
+			githubwebhook.NewServer(controllerMgr.GetClient(), mgr.GetAPIReader(), githubWebhookLogger).Handler(),
+			githubWebhookLogger)
+		if err := mgr.Add(githubWebhookServer); err != nil {
+			setupLog.Error(err, "unable to set up the GitHub webhook endpoint")
+			os.Exit(1)
+		}
+	}
+
+	if buildWebhookAddr != "0" {
+		buildWebhookLogger := ctrl.Log.WithName("buildwebhook")
+		buildWebhookServer := httpserver.NewServer("build webhook", buildWebhookAddr, buildWebhookCertFile, buildWebhookKeyFile,
+			buildwebhook.NewServer(controllerMgr.GetClient(), statusapi.NewKubernetesAuthorizer(mgr.GetClient()), buildWebhookLogger).Handler(),
+			buildWebhookLogger)
+		if err := mgr.Add(buildWebhookServer); err != nil {
+			setupLog.Error(err, "unable to set up the build webhook endpoint")
+			os.Exit(1)
+		}
+	}
+
+	healthzChecks := map[string]healthz.Checker{
+		"healthz": healthz.Ping,
+	}
+	readyzChecks := map[string]healthz.Checker{
+		"readyz":              healthz.Ping,
+		"informers":           readiness.NewCacheSyncChecker(mgr.GetCache()),
+		"webhook":             mgr.GetWebhookServer().StartedChecker(),
+		"webhook-certificate": readiness.NewCertificateChecker(filepath.Join(webhookCertDir, "tls.crt"), filepath.Join(webhookCertDir, "tls.key")),
+	}
+	if statusAPIAddr != "0" && statusAPICertFile != "" {
+		readyzChecks["status-api-certificate"] = readiness.NewCertificateChecker(statusAPICertFile, statusAPIKeyFile)
+	}
+	if githubWebhookAddr != "0" && githubWebhookCertFile != "" {
+		readyzChecks["github-webhook-certificate"] = readiness.NewCertificateChecker(githubWebhookCertFile, githubWebhookKeyFile)
+	}
+	if buildWebhookAddr != "0" && buildWebhookCertFile != "" {
+		readyzChecks["build-webhook-certificate"] = readiness.NewCertificateChecker(buildWebhookCertFile, buildWebhookKeyFile)
+	}
+	if readinessCheckGitCredentials {
+		readyzChecks["git-credentials"] = readiness.NewCredentialsChecker(imetrics.NewGithubAppAvailabilityProbe(mgr.GetClient()))
+	}
+	if enableDebugEndpoints {
+		diagnosticsServer := diagnostics.NewServer(probeAddr, healthzChecks, readyzChecks, mgr.GetCache(), metrics.Registry,
+			ctrl.Log.WithName("diagnostics"))
+		if err := mgr.Add(diagnosticsServer); err != nil {
+			setupLog.Error(err, "unable to set up the debug endpoints")
+			os.Exit(1)
+		}
+		setupLog.Info("serving the debug endpoints on the health probe address", "address", probeAddr)
+	} else {
+		for name, checker := range healthzChecks {
+			if err := mgr.AddHealthzCheck(name, checker); err != nil {
+				setupLog.Error(err, "unable to set up health check", "check", name)
+				os.Exit(1)
+			}
+		}
+		for name, checker := range readyzChecks {
+			if err := mgr.AddReadyzCheck(name, checker); err != nil {
+				setupLog.Error(err, "unable to set up ready check", "check", name)
+				os.Exit(1)
+			}
+		}
 	}
 
 	ctx := ctrl.SetupSignalHandler()
@@ -140,6 +382,17 @@ func main() {
 	}
 	integrationMetrics.StartAvailabilityProbes(ctx)
 
+	shutdownTracing, err := tracing.InitTracerProvider(ctx)
+	if err != nil {
+		setupLog.Error(err, "unable to initialize tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "problem shutting down tracing")
+		}
+	}()
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/helpers"
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	zap2 "go.uber.org/zap"
@@ -92,17 +93,24 @@ func getTenantNamespaces(
 		"toolchain.dev.openshift.com/type", selection.In, []string{"tenant"},
 	)
 	selector := labels.NewSelector().Add(*req)
+	var namespaces []core.Namespace
 	namespaceList := &core.NamespaceList{}
-	err := cl.List(
+	err := helpers.ListWithPagination(
 		context.Background(),
+		cl,
 		namespaceList,
+		helpers.DefaultListPageSize,
+		func() error {
+			namespaces = append(namespaces, namespaceList.Items...)
+			return nil
+		},
 		&client.ListOptions{LabelSelector: selector},
 	)
 	if err != nil {
 		logger.Error(err, "Failed listing namespaces")
 		return nil, err
 	}
-	return namespaceList.Items, nil
+	return namespaces, nil
 }
 
 // Gets a map to allow to tell with direct lookup if a snapshot is associated with
@@ -114,24 +122,28 @@ func getSnapshotsForNSReleases(
 	logger logr.Logger,
 ) (map[string]snapshotData, error) {
 	releases := &releasev1alpha1.ReleaseList{}
-	err := cl.List(
+	err := helpers.ListWithPagination(
 		context.Background(),
+		cl,
 		releases,
+		helpers.DefaultListPageSize,
+		func() error {
+			for _, release := range releases.Items {
+				data, ok := snapToData[release.Spec.Snapshot]
+				if !ok {
+					data = snapshotData{}
+				}
+				data.release = release
+				snapToData[release.Spec.Snapshot] = data
+			}
+			return nil
+		},
 		&client.ListOptions{Namespace: namespace},
 	)
 	if err != nil {
 		logger.Error(err, "Failed to list releases")
 		return nil, err
 	}
-
-	for _, release := range releases.Items {
-		data, ok := snapToData[release.Spec.Snapshot]
-		if !ok {
-			data = snapshotData{}
-		}
-		data.release = release
-		snapToData[release.Spec.Snapshot] = data
-	}
 	return snapToData, nil
 }
 
@@ -142,10 +154,28 @@ func getUnassociatedNSSnapshots(
 	namespace string,
 	logger logr.Logger,
 ) ([]applicationapiv1alpha1.Snapshot, error) {
+	var unAssociatedSnaps []applicationapiv1alpha1.Snapshot
+
 	snaps := &applicationapiv1alpha1.SnapshotList{}
-	err := cl.List(
+	err := helpers.ListWithPagination(
 		context.Background(),
+		cl,
 		snaps,
+		helpers.DefaultListPageSize,
+		func() error {
+			for _, snap := range snaps.Items {
+				if _, found := snapToData[snap.Name]; found {
+					logger.V(1).Info(
+						"Skipping snapshot as it's associated with release",
+						"namespace", snap.Namespace,
+						"snapshot.name", snap.Name,
+					)
+					continue
+				}
+				unAssociatedSnaps = append(unAssociatedSnaps, snap)
+			}
+			return nil
+		},
 		&client.ListOptions{Namespace: namespace},
 	)
 	if err != nil {
@@ -153,20 +183,6 @@ func getUnassociatedNSSnapshots(
 		return nil, err
 	}
 
-	var unAssociatedSnaps []applicationapiv1alpha1.Snapshot
-
-	for _, snap := range snaps.Items {
-		if _, found := snapToData[snap.Name]; found {
-			logger.V(1).Info(
-				"Skipping snapshot as it's associated with release",
-				"namespace", snap.Namespace,
-				"snapshot.name", snap.Name,
-			)
-			continue
-		}
-		unAssociatedSnaps = append(unAssociatedSnaps, snap)
-	}
-
 	return unAssociatedSnaps, nil
 }
 
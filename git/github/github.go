@@ -41,6 +41,7 @@ type CheckRunAdapter struct {
 	Title          string
 	Summary        string
 	Text           string
+	Annotations    []*ghapi.CheckRunAnnotation
 	StartTime      time.Time
 	CompletionTime time.Time
 }
@@ -58,7 +59,7 @@ type CommitStatusAdapter struct {
 
 // GetStatus returns the appropriate status based on conclusion and start time.
 func (s *CheckRunAdapter) GetStatus() string {
-	if s.Conclusion == "success" || s.Conclusion == "failure" {
+	if s.Conclusion == "success" || s.Conclusion == "failure" || s.Conclusion == "neutral" {
 		return "completed"
 	} else if s.StartTime.IsZero() {
 		return "queued"
@@ -195,9 +196,15 @@ func NewClient(logger logr.Logger, opts ...ClientOption) *Client {
 	return &client
 }
 
-// CreateAppInstallationToken creates an installation token for a GitHub App.
+// CreateAppInstallationToken creates an installation token for a GitHub App. The tokens are cached per installation
+// until shortly before they expire, so the cached token is returned when there is one.
 func (c *Client) CreateAppInstallationToken(ctx context.Context, appID int64, installationID int64, privateKey []byte) (string, error) {
-	transport, err := ghinstallation.NewAppsTransport(http.DefaultTransport, appID, privateKey)
+	key := installationKey{appID: appID, installationID: installationID}
+	if token, found := installationTokens.get(key, time.Now()); found {
+		return token, nil
+	}
+
+	transport, err := ghinstallation.NewAppsTransport(newRateLimitTransport(http.DefaultTransport, c.logger), appID, privateKey)
 	if err != nil {
 		return "", err
 	}
@@ -214,16 +221,23 @@ func (c *Client) CreateAppInstallationToken(ctx context.Context, appID int64, in
 		return "", err
 	}
 
+	if expiresAt := installToken.GetExpiresAt(); !expiresAt.IsZero() {
+		installationTokens.set(key, installToken.GetToken(), expiresAt)
+	}
+
 	return installToken.GetToken(), nil
 }
 
-// SetOAuthToken configures the client with a GitHub OAuth token.
+// SetOAuthToken configures the client with a GitHub OAuth token. The requests hitting the GitHub API rate limits
+// are retried once the rate limits allow it.
 func (c *Client) SetOAuthToken(ctx context.Context, token string) {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 
-	c.gh = ghapi.NewClient(oauth2.NewClient(ctx, ts))
+	c.gh = ghapi.NewClient(&http.Client{
+		Transport: newRateLimitTransport(&oauth2.Transport{Source: ts, Base: http.DefaultTransport}, c.logger),
+	})
 }
 
 // CreateCheckRun creates a new CheckRun via the GitHub API.
@@ -236,9 +250,10 @@ func (c *Client) CreateCheckRun(ctx context.Context, cra *CheckRunAdapter) (*int
 		ExternalID: &cra.ExternalID,
 		Status:     &status,
 		Output: &ghapi.CheckRunOutput{
-			Title:   &cra.Title,
-			Summary: &cra.Summary,
-			Text:    &cra.Text,
+			Title:       &cra.Title,
+			Summary:     &cra.Summary,
+			Text:        &cra.Text,
+			Annotations: cra.Annotations,
 		},
 	}
 
@@ -283,9 +298,10 @@ func (c *Client) UpdateCheckRun(ctx context.Context, checkRunID int64, cra *Chec
 		Name:   cra.Name,
 		Status: &status,
 		Output: &ghapi.CheckRunOutput{
-			Title:   &cra.Title,
-			Summary: &cra.Summary,
-			Text:    &cra.Text,
+			Title:       &cra.Title,
+			Summary:     &cra.Summary,
+			Text:        &cra.Text,
+			Annotations: cra.Annotations,
 		},
 	}
 
@@ -18,6 +18,7 @@ package github_test
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -53,6 +54,21 @@ func (MockAppsService) CreateInstallationToken(
 	return &ghapi.InstallationToken{Token: &token}, nil, nil
 }
 
+// MockExpiringAppsService creates numbered installation tokens expiring at the given time
+type MockExpiringAppsService struct {
+	created   *int
+	expiresAt *time.Time
+}
+
+// CreateInstallationToken implements github.AppsService
+func (m *MockExpiringAppsService) CreateInstallationToken(
+	ctx context.Context, id int64, opts *ghapi.InstallationTokenOptions,
+) (*ghapi.InstallationToken, *ghapi.Response, error) {
+	*m.created++
+	token := fmt.Sprintf("expiring-token-%d", *m.created)
+	return &ghapi.InstallationToken{Token: &token, ExpiresAt: m.expiresAt}, nil, nil
+}
+
 type MockChecksService struct {
 	ListCheckRunsForRefResult []*ghapi.CheckRun
 }
@@ -155,6 +171,8 @@ var _ = Describe("CheckRunAdapter", func() {
 		Expect(adapter.GetStatus()).To(Equal("completed"))
 		adapter.Conclusion = "failure"
 		Expect(adapter.GetStatus()).To(Equal("completed"))
+		adapter.Conclusion = "neutral"
+		Expect(adapter.GetStatus()).To(Equal("completed"))
 		adapter.Conclusion = ""
 		Expect(adapter.GetStatus()).To(Equal("queued"))
 		adapter.StartTime = time.Now()
@@ -217,6 +235,38 @@ var _ = Describe("Client", func() {
 		Expect(token).To(Equal("example-token"))
 	})
 
+	It("caches the app installation tokens until shortly before they expire", func() {
+		created := 0
+		expiresAt := time.Now().Add(time.Hour)
+		client = github.NewClient(logr.Discard(), github.WithAppsService(&MockExpiringAppsService{
+			created:   &created,
+			expiresAt: &expiresAt,
+		}))
+
+		token, err := client.CreateAppInstallationToken(context.TODO(), 2, 2, []byte(samplePrivateKey))
+		Expect(err).To(BeNil())
+		Expect(token).To(Equal("expiring-token-1"))
+		token, err = client.CreateAppInstallationToken(context.TODO(), 2, 2, []byte(samplePrivateKey))
+		Expect(err).To(BeNil())
+		Expect(token).To(Equal("expiring-token-1"))
+		Expect(created).To(Equal(1))
+
+		// the tokens of other installations aren't shared
+		token, err = client.CreateAppInstallationToken(context.TODO(), 2, 3, []byte(samplePrivateKey))
+		Expect(err).To(BeNil())
+		Expect(token).To(Equal("expiring-token-2"))
+		Expect(created).To(Equal(2))
+
+		// the tokens about to expire are refreshed
+		expiresAt = time.Now().Add(time.Minute)
+		_, err = client.CreateAppInstallationToken(context.TODO(), 2, 4, []byte(samplePrivateKey))
+		Expect(err).To(BeNil())
+		token, err = client.CreateAppInstallationToken(context.TODO(), 2, 4, []byte(samplePrivateKey))
+		Expect(err).To(BeNil())
+		Expect(token).To(Equal("expiring-token-4"))
+		Expect(created).To(Equal(4))
+	})
+
 	It("accepts an OAuth token", func() {
 		client.SetOAuthToken(context.TODO(), "example-token")
 		Expect(client.GetAppsService()).To(Equal(mockAppsSvc))
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// rateLimitBackoff is how long to wait before retrying the requests hitting a rate limit which doesn't say when
+	// to retry.
+	rateLimitBackoff = 5 * time.Second
+
+	// installationTokenExpiryMargin is how long before its expiration a cached installation token is refreshed.
+	installationTokenExpiryMargin = 5 * time.Minute
+)
+
+// RateLimitError is returned for the GitHub API requests which hit the primary or secondary rate limits,
+// holding how long to wait before retrying them.
+type RateLimitError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+// Error returns the message of the RateLimitError.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit hit with status %d, retry after %s", e.StatusCode, e.RetryAfter)
+}
+
+// GetRateLimitRetryAfter returns how long to wait before retrying the request which failed with the given error and
+// whether the error is a RateLimitError, so that the reconcilers requeue the request instead of waiting for the
+// rate limit to reset.
+func GetRateLimitRetryAfter(err error) (time.Duration, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// rateLimitTransport is a http.RoundTripper failing the GitHub API requests which hit the primary or secondary
+// rate limits with a RateLimitError, instead of blocking the reconcilers until the rate limit resets.
+type rateLimitTransport struct {
+	base   http.RoundTripper
+	logger logr.Logger
+	now    func() time.Time
+}
+
+// newRateLimitTransport returns a rateLimitTransport sending the requests through the given transport.
+func newRateLimitTransport(base http.RoundTripper, logger logr.Logger) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:   base,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// RoundTrip sends the request and returns a RateLimitError when it hits a rate limit.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	wait, limited := getRateLimitWait(resp, t.now())
+	if !limited {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	t.logger.Info("GitHub API rate limit hit, the request will be retried later",
+		"method", req.Method, "url", req.URL.Redacted(), "status", resp.StatusCode, "wait", wait.String())
+	return nil, &RateLimitError{StatusCode: resp.StatusCode, RetryAfter: wait}
+}
+
+// getRateLimitWait returns how long to wait before retrying the request whose response is given, and whether
+// the request hit a rate limit at all. The Retry-After header of the secondary rate limits takes precedence over
+// the reset time of the exhausted primary rate limit, the rate limits which don't say when to retry are backed off.
+func getRateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Unix(reset, 0).Sub(now) + time.Second
+			if wait < 0 {
+				wait = 0
+			}
+			return wait, true
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return rateLimitBackoff, true
+	}
+
+	// forbidden for another reason than a rate limit
+	return 0, false
+}
+
+// installationKey identifies the installation of a GitHub App.
+type installationKey struct {
+	appID          int64
+	installationID int64
+}
+
+// cachedInstallationToken is an installation token along with its expiration time.
+type cachedInstallationToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// installationTokenCache caches the installation tokens of the GitHub Apps, so that a new token isn't created
+// for each of the reported Snapshots.
+type installationTokenCache struct {
+	mutex  sync.Mutex
+	tokens map[installationKey]cachedInstallationToken
+}
+
+// installationTokens is the cache of the installation tokens shared by all of the clients.
+var installationTokens = &installationTokenCache{tokens: map[installationKey]cachedInstallationToken{}}
+
+// get returns the cached token of the installation unless it expires within installationTokenExpiryMargin.
+func (c *installationTokenCache) get(key installationKey, now time.Time) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, found := c.tokens[key]
+	if !found {
+		return "", false
+	}
+	if !now.Add(installationTokenExpiryMargin).Before(cached.expiresAt) {
+		delete(c.tokens, key)
+		return "", false
+	}
+
+	return cached.token, true
+}
+
+// set caches the token of the installation until it expires.
+func (c *installationTokenCache) set(key installationKey, token string, expiresAt time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.tokens[key] = cachedInstallationToken{token: token, expiresAt: expiresAt}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeTransport returns the queued responses and records the bodies of the requests
+type fakeTransport struct {
+	responses []*http.Response
+	bodies    []string
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+	}
+	t.bodies = append(t.bodies, body)
+
+	resp := t.responses[0]
+	t.responses = t.responses[1:]
+	return resp, nil
+}
+
+func newResponse(code int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: code, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("{}"))}
+	for name, value := range headers {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+var _ = Describe("GitHub rate limits", func() {
+	now := time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC)
+
+	DescribeTable("determines how long to wait before retrying",
+		func(resp *http.Response, expectedWait time.Duration, expectedLimited bool) {
+			wait, limited := getRateLimitWait(resp, now)
+			Expect(limited).To(Equal(expectedLimited))
+			Expect(wait).To(Equal(expectedWait))
+		},
+		Entry("successful requests", newResponse(http.StatusOK, nil), time.Duration(0), false),
+		Entry("forbidden requests", newResponse(http.StatusForbidden, nil), time.Duration(0), false),
+		Entry("secondary rate limit", newResponse(http.StatusForbidden, map[string]string{"Retry-After": "30"}), 30*time.Second, true),
+		Entry("exhausted primary rate limit", newResponse(http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(now.Add(10*time.Second).Unix(), 10),
+		}), 11*time.Second, true),
+		Entry("primary rate limit which already reset", newResponse(http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(now.Add(-10*time.Second).Unix(), 10),
+		}), time.Duration(0), true),
+		Entry("too many requests", newResponse(http.StatusTooManyRequests, nil), rateLimitBackoff, true),
+	)
+
+	It("fails the requests hitting a rate limit with a RateLimitError without retrying them", func() {
+		base := &fakeTransport{responses: []*http.Response{
+			newResponse(http.StatusForbidden, map[string]string{"Retry-After": "3600"}),
+		}}
+		transport := newRateLimitTransport(base, logr.Discard())
+
+		req, err := http.NewRequest(http.MethodPost, "https://api.github.com/repos/org/repo/check-runs", bytes.NewBufferString(`{"name":"check"}`))
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := transport.RoundTrip(req)
+		Expect(resp).To(BeNil())
+		Expect(err).To(MatchError(&RateLimitError{StatusCode: http.StatusForbidden, RetryAfter: time.Hour}))
+		Expect(base.bodies).To(Equal([]string{`{"name":"check"}`}))
+
+		retryAfter, limited := GetRateLimitRetryAfter(&url.Error{Op: "Post", URL: req.URL.String(), Err: err})
+		Expect(limited).To(BeTrue())
+		Expect(retryAfter).To(Equal(time.Hour))
+	})
+
+	It("returns the responses which didn't hit a rate limit", func() {
+		base := &fakeTransport{responses: []*http.Response{newResponse(http.StatusForbidden, nil)}}
+		transport := newRateLimitTransport(base, logr.Discard())
+		req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/org/repo/statuses/abc", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := transport.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+
+		_, limited := GetRateLimitRetryAfter(errors.New("failed to update status"))
+		Expect(limited).To(BeFalse())
+	})
+})
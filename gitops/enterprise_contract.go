@@ -0,0 +1,106 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	h "github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EnterpriseContractPolicyAnnotation is the Application annotation which enables the built-in Enterprise Contract
+	// gate. Its value is the reference of the EnterpriseContractPolicy the Snapshots are evaluated against.
+	EnterpriseContractPolicyAnnotation = "test.appstudio.openshift.io/enterprise-contract-policy"
+
+	// EnterpriseContractScenarioName is the name under which the result of the Enterprise Contract gate is reported
+	// alongside the results of the IntegrationTestScenarios of the Application.
+	EnterpriseContractScenarioName = "enterprise-contract-gate"
+
+	// EnterpriseContractPipelineRepoURL is the git repository of the pipeline evaluating the Enterprise Contract policy.
+	EnterpriseContractPipelineRepoURL = "https://github.com/konflux-ci/build-definitions"
+
+	// EnterpriseContractPipelineRevision is the revision of the pipeline evaluating the Enterprise Contract policy.
+	EnterpriseContractPipelineRevision = "main"
+
+	// EnterpriseContractPipelinePath is the path of the pipeline evaluating the Enterprise Contract policy.
+	EnterpriseContractPipelinePath = "pipelines/enterprise-contract.yaml"
+
+	// EnterpriseContractPolicyParamName is the name of the pipeline parameter the policy reference is passed in.
+	EnterpriseContractPolicyParamName = "POLICY_CONFIGURATION"
+)
+
+// IsEnterpriseContractGateEnabled returns true if the Application opted into the built-in Enterprise Contract gate.
+func IsEnterpriseContractGateEnabled(application *applicationapiv1alpha1.Application) bool {
+	return application.GetAnnotations()[EnterpriseContractPolicyAnnotation] != ""
+}
+
+// NewEnterpriseContractScenario returns the in-memory IntegrationTestScenario which runs the Enterprise Contract
+// policy evaluation for the Snapshots of the Application, or nil if the gate isn't enabled for the Application.
+func NewEnterpriseContractScenario(application *applicationapiv1alpha1.Application) *v1beta2.IntegrationTestScenario {
+	if !IsEnterpriseContractGateEnabled(application) {
+		return nil
+	}
+
+	scenario := &v1beta2.IntegrationTestScenario{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      EnterpriseContractScenarioName,
+			Namespace: application.Namespace,
+		},
+		Spec: v1beta2.IntegrationTestScenarioSpec{
+			Application: application.Name,
+			ResolverRef: v1beta2.ResolverRef{
+				Resolver: "git",
+				Params: []v1beta2.ResolverParameter{
+					{Name: "url", Value: EnterpriseContractPipelineRepoURL},
+					{Name: "revision", Value: EnterpriseContractPipelineRevision},
+					{Name: "pathInRepo", Value: EnterpriseContractPipelinePath},
+				},
+			},
+			Params: []v1beta2.PipelineParameter{
+				{Name: EnterpriseContractPolicyParamName, Value: application.GetAnnotations()[EnterpriseContractPolicyAnnotation]},
+			},
+		},
+	}
+	h.SetScenarioIntegrationStatusAsValid(scenario, "Built-in Enterprise Contract gate")
+
+	return scenario
+}
+
+// AddEnterpriseContractScenario appends the built-in Enterprise Contract scenario to the given list of the
+// IntegrationTestScenarios of the Application if the gate is enabled for it. The list is returned unchanged when
+// the gate isn't enabled or when one of the IntegrationTestScenarios already uses the name of the built-in gate.
+func AddEnterpriseContractScenario(application *applicationapiv1alpha1.Application, scenarios *[]v1beta2.IntegrationTestScenario) *[]v1beta2.IntegrationTestScenario {
+	enterpriseContractScenario := NewEnterpriseContractScenario(application)
+	if enterpriseContractScenario == nil {
+		return scenarios
+	}
+
+	filteredScenarios := []v1beta2.IntegrationTestScenario{}
+	if scenarios != nil {
+		for _, scenario := range *scenarios {
+			if scenario.Name == EnterpriseContractScenarioName {
+				return scenarios
+			}
+		}
+		filteredScenarios = append(filteredScenarios, *scenarios...)
+	}
+
+	filteredScenarios = append(filteredScenarios, *enterpriseContractScenario)
+	return &filteredScenarios
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	h "github.com/konflux-ci/integration-service/helpers"
+)
+
+var _ = Describe("Enterprise Contract gate", func() {
+
+	const policy = "enterprise-contract-service/default"
+	var application *applicationapiv1alpha1.Application
+
+	BeforeEach(func() {
+		application = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: "default",
+			},
+		}
+	})
+
+	It("is disabled unless the application has the policy annotation", func() {
+		Expect(gitops.IsEnterpriseContractGateEnabled(application)).To(BeFalse())
+		Expect(gitops.NewEnterpriseContractScenario(application)).To(BeNil())
+
+		scenarios := &[]v1beta2.IntegrationTestScenario{{ObjectMeta: metav1.ObjectMeta{Name: "scenario-sample"}}}
+		Expect(gitops.AddEnterpriseContractScenario(application, scenarios)).To(Equal(scenarios))
+	})
+
+	It("creates a valid scenario evaluating the policy of the application", func() {
+		application.Annotations = map[string]string{gitops.EnterpriseContractPolicyAnnotation: policy}
+		Expect(gitops.IsEnterpriseContractGateEnabled(application)).To(BeTrue())
+
+		scenario := gitops.NewEnterpriseContractScenario(application)
+		Expect(scenario).NotTo(BeNil())
+		Expect(scenario.Name).To(Equal(gitops.EnterpriseContractScenarioName))
+		Expect(scenario.Namespace).To(Equal(application.Namespace))
+		Expect(scenario.Spec.Application).To(Equal(application.Name))
+		Expect(scenario.Spec.ResolverRef.Resolver).To(Equal("git"))
+		Expect(scenario.Spec.ResolverRef.Params).To(ContainElement(
+			v1beta2.ResolverParameter{Name: "pathInRepo", Value: gitops.EnterpriseContractPipelinePath}))
+		Expect(scenario.Spec.Params).To(ConsistOf(
+			v1beta2.PipelineParameter{Name: gitops.EnterpriseContractPolicyParamName, Value: policy}))
+		Expect(h.IsScenarioValid(scenario)).To(BeTrue())
+		Expect(h.IsScenarioOptional(scenario)).To(BeFalse())
+	})
+
+	It("adds the scenario to the scenarios of the application only once", func() {
+		application.Annotations = map[string]string{gitops.EnterpriseContractPolicyAnnotation: policy}
+
+		scenarios := gitops.AddEnterpriseContractScenario(application, nil)
+		Expect(*scenarios).To(HaveLen(1))
+		Expect((*scenarios)[0].Name).To(Equal(gitops.EnterpriseContractScenarioName))
+
+		scenarios = gitops.AddEnterpriseContractScenario(application,
+			&[]v1beta2.IntegrationTestScenario{{ObjectMeta: metav1.ObjectMeta{Name: "scenario-sample"}}})
+		Expect(*scenarios).To(HaveLen(2))
+		Expect(gitops.AddEnterpriseContractScenario(application, scenarios)).To(Equal(scenarios))
+	})
+})
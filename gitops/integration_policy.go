@@ -0,0 +1,222 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	h "github.com/konflux-ci/integration-service/helpers"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/schedule"
+	"github.com/konflux-ci/integration-service/tekton"
+)
+
+// SnapshotTestingDeadlineEnvVar is the environment variable holding how long the Snapshots of all applications may
+// await the results of their integration tests before they're failed as timed out
+const SnapshotTestingDeadlineEnvVar = "SNAPSHOT_TESTING_DEADLINE"
+
+// IsScenarioRequired returns true if the IntegrationTestScenario is required for the Snapshots of its application
+// to pass. The IntegrationPolicy of the application, when there is one, takes precedence over the optional label
+// of the scenario. The suspended scenarios, the scenarios still in their observe-only runs and the quarantined flaky
+// scenarios are never required.
+func IsScenarioRequired(policy *v1beta2.IntegrationPolicy, scenario *v1beta2.IntegrationTestScenario) bool {
+	if h.IsScenarioSuspended(scenario) || h.IsScenarioObserveOnly(scenario) || h.IsScenarioQuarantined(scenario) {
+		return false
+	}
+	if policy == nil {
+		return !h.IsScenarioOptional(scenario)
+	}
+
+	return policy.RequiresScenario(scenario.Name, h.IsScenarioOptional(scenario))
+}
+
+// IsTestStatusPassedForPolicy returns true if the integration test status counts as passed for the gating of the
+// Snapshot, the tests which passed with warnings don't when the IntegrationPolicy blocks on warnings.
+func IsTestStatusPassedForPolicy(policy *v1beta2.IntegrationPolicy, status intgteststat.IntegrationTestStatus) bool {
+	if policy != nil && policy.Spec.BlockOnWarnings && status == intgteststat.IntegrationTestStatusTestWarning {
+		return false
+	}
+
+	return status.IsPassed()
+}
+
+// IsQuorumScenario returns true if the IntegrationTestScenario, or the scenario whose matrix cell it is, belongs
+// to a quorum of the IntegrationPolicy, so that it isn't required to pass on its own.
+func IsQuorumScenario(policy *v1beta2.IntegrationPolicy, scenario *v1beta2.IntegrationTestScenario) bool {
+	if policy == nil {
+		return false
+	}
+
+	return policy.IsQuorumScenario(getMatrixScenarioName(scenario))
+}
+
+// EvaluateScenarioQuorums returns the outcomes of the quorums of the IntegrationPolicy for the given required
+// IntegrationTestScenarios, with their matrices expanded, and the integration test statuses of the Snapshot.
+// A scenario of a quorum passes when all of its matrix cells passed. The quorums without any scenario applicable
+// to the Snapshot are left out, and the number of scenarios a quorum requires is capped to its applicable scenarios.
+func EvaluateScenarioQuorums(policy *v1beta2.IntegrationPolicy, scenarios *[]v1beta2.IntegrationTestScenario, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) []GatingDecisionQuorum {
+	if policy == nil || len(policy.Spec.Quorums) == 0 || scenarios == nil {
+		return nil
+	}
+
+	// the matrix cells are grouped by the scenario defining the matrix
+	cells := map[string][]string{}
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		name := getMatrixScenarioName(&scenario)
+		cells[name] = append(cells[name], scenario.Name)
+	}
+
+	quorums := []GatingDecisionQuorum{}
+	for _, quorum := range policy.Spec.Quorums {
+		result := GatingDecisionQuorum{Name: quorum.Name}
+		for _, scenarioName := range quorum.Scenarios {
+			scenarioCells, ok := cells[scenarioName]
+			if !ok {
+				continue
+			}
+			switch getQuorumScenarioStatus(policy, scenarioCells, testStatuses) {
+			case GatingDecisionPassed:
+				result.PassedScenarios = append(result.PassedScenarios, scenarioName)
+			case GatingDecisionFailed:
+				result.FailedScenarios = append(result.FailedScenarios, scenarioName)
+			default:
+				result.PendingScenarios = append(result.PendingScenarios, scenarioName)
+			}
+		}
+
+		applicable := len(result.PassedScenarios) + len(result.FailedScenarios) + len(result.PendingScenarios)
+		if applicable == 0 {
+			continue
+		}
+		result.MinPassed = min(quorum.MinPassed, applicable)
+		if len(result.PassedScenarios) >= result.MinPassed {
+			result.Verdict = GatingDecisionPassed
+		} else if applicable-len(result.FailedScenarios) < result.MinPassed {
+			result.Verdict = GatingDecisionFailed
+		}
+		quorums = append(quorums, result)
+	}
+
+	return quorums
+}
+
+// getQuorumScenarioStatus returns GatingDecisionPassed if all of the given matrix cells of a scenario passed,
+// GatingDecisionFailed if any of them finished without passing, and an empty string otherwise.
+func getQuorumScenarioStatus(policy *v1beta2.IntegrationPolicy, scenarioCells []string, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) string {
+	if testStatuses == nil {
+		return ""
+	}
+
+	passed := true
+	for _, cell := range scenarioCells {
+		testDetails, ok := testStatuses.GetScenarioStatus(cell)
+		if !ok || !testDetails.Status.IsFinal() {
+			passed = false
+			continue
+		}
+		if !IsTestStatusPassedForPolicy(policy, testDetails.Status) {
+			return GatingDecisionFailed
+		}
+	}
+
+	if passed {
+		return GatingDecisionPassed
+	}
+	return ""
+}
+
+// getMatrixScenarioName returns the name of the IntegrationTestScenario whose matrix cell the given scenario is,
+// or the name of the scenario itself when it isn't a matrix cell.
+func getMatrixScenarioName(scenario *v1beta2.IntegrationTestScenario) string {
+	if matrixScenario, ok := scenario.GetLabels()[tekton.MatrixScenarioLabel]; ok {
+		return matrixScenario
+	}
+
+	return scenario.Name
+}
+
+// IsWithinReleaseWindow returns true if any of the release windows of the IntegrationPolicy is open at the given
+// time, the Snapshots can be auto-released at any time when there's no policy or it has no release windows.
+// When none of the windows is open, the time the next of them opens at is returned as well, or the zero time when
+// none of them will ever open. The windows which can't be parsed are skipped and reported in the returned error.
+func IsWithinReleaseWindow(policy *v1beta2.IntegrationPolicy, now time.Time) (bool, time.Time, error) {
+	if policy == nil || len(policy.Spec.ReleaseWindows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	var errs error
+	var nextOpening time.Time
+	for _, window := range policy.Spec.ReleaseWindows {
+		location := time.UTC
+		if window.TimeZone != "" {
+			var err error
+			location, err = time.LoadLocation(window.TimeZone)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("invalid time zone %q of the release window %q: %w", window.TimeZone, window.Schedule, err))
+				continue
+			}
+		}
+		windowSchedule, err := schedule.ParseInLocation(window.Schedule, location)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid schedule of the release window %q: %w", window.Schedule, err))
+			continue
+		}
+
+		// the window is open if it opened after the given time minus its duration, at the given time the latest
+		lastOpening := windowSchedule.Next(now.Add(-window.Duration.Duration))
+		if !lastOpening.IsZero() && !lastOpening.After(now) {
+			return true, time.Time{}, errs
+		}
+
+		opening := windowSchedule.Next(now)
+		if !opening.IsZero() && (nextOpening.IsZero() || opening.Before(nextOpening)) {
+			nextOpening = opening
+		}
+	}
+
+	return false, nextOpening, errs
+}
+
+// GetSnapshotTestingDeadline returns how long the Snapshots of the application may await the results of their
+// integration tests before they're failed as timed out. The deadline of the IntegrationPolicy of the application
+// takes precedence over the one set for all applications through the SnapshotTestingDeadlineEnvVar. 0 is returned
+// when there's no deadline, which is also the case when the deadline of the environment variable is invalid.
+func GetSnapshotTestingDeadline(policy *v1beta2.IntegrationPolicy) (time.Duration, error) {
+	if policy != nil && policy.Spec.TestingDeadline != nil {
+		if policy.Spec.TestingDeadline.Duration < 0 {
+			return 0, fmt.Errorf("invalid testing deadline %q of the IntegrationPolicy %s, a non-negative duration is expected",
+				policy.Spec.TestingDeadline.Duration, policy.Name)
+		}
+		return policy.Spec.TestingDeadline.Duration, nil
+	}
+
+	value := os.Getenv(SnapshotTestingDeadlineEnvVar)
+	if value == "" {
+		return 0, nil
+	}
+	deadline, err := time.ParseDuration(value)
+	if err != nil || deadline < 0 {
+		return 0, fmt.Errorf("invalid testing deadline %q of the Snapshots, a non-negative duration is expected", value)
+	}
+
+	return deadline, nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+)
+
+var _ = Describe("Integration policy", func() {
+
+	var (
+		required *v1beta2.IntegrationTestScenario
+		optional *v1beta2.IntegrationTestScenario
+		policy   *v1beta2.IntegrationPolicy
+	)
+
+	BeforeEach(func() {
+		required = &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{Name: "required", Namespace: "default"},
+		}
+		optional = &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "optional",
+				Namespace: "default",
+				Labels:    map[string]string{helpers.OptionalScenarioLabel: "true"},
+			},
+		}
+		policy = &v1beta2.IntegrationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       v1beta2.IntegrationPolicySpec{Application: "application"},
+		}
+	})
+
+	It("requires the scenarios which aren't labeled as optional without a policy", func() {
+		Expect(gitops.IsScenarioRequired(nil, required)).To(BeTrue())
+		Expect(gitops.IsScenarioRequired(nil, optional)).To(BeFalse())
+		Expect(gitops.IsScenarioRequired(policy, required)).To(BeTrue())
+		Expect(gitops.IsScenarioRequired(policy, optional)).To(BeFalse())
+	})
+
+	It("never requires the suspended scenarios", func() {
+		required.Spec.Suspended = true
+		Expect(gitops.IsScenarioRequired(nil, required)).To(BeFalse())
+		policy.Spec.RequiredScenarios = []string{"required"}
+		Expect(gitops.IsScenarioRequired(policy, required)).To(BeFalse())
+	})
+
+	It("doesn't require the scenarios during their observe-only runs", func() {
+		required.Spec.ObserveOnlyRuns = 2
+		required.Status.ObservedRuns = 1
+		Expect(gitops.IsScenarioRequired(nil, required)).To(BeFalse())
+		required.Status.ObservedRuns = 2
+		Expect(gitops.IsScenarioRequired(nil, required)).To(BeTrue())
+	})
+
+	It("requires only the required scenarios of the policy", func() {
+		policy.Spec.RequiredScenarios = []string{"optional"}
+		Expect(gitops.IsScenarioRequired(policy, required)).To(BeFalse())
+		Expect(gitops.IsScenarioRequired(policy, optional)).To(BeTrue())
+
+		scenarios := []v1beta2.IntegrationTestScenario{*required, *optional}
+		filtered := gitops.FilterRequiredIntegrationTestScenarios(policy, &scenarios)
+		Expect(*filtered).To(HaveLen(1))
+		Expect((*filtered)[0].Name).To(Equal("optional"))
+	})
+
+	It("doesn't require the optional scenarios of the policy", func() {
+		policy.Spec.OptionalScenarios = []string{"required"}
+		Expect(gitops.IsScenarioRequired(policy, required)).To(BeFalse())
+		Expect(gitops.IsScenarioRequired(policy, optional)).To(BeFalse())
+	})
+
+	It("fails the tests which passed with warnings when the policy blocks on warnings", func() {
+		Expect(gitops.IsTestStatusPassedForPolicy(nil, intgteststat.IntegrationTestStatusTestWarning)).To(BeTrue())
+		Expect(gitops.IsTestStatusPassedForPolicy(policy, intgteststat.IntegrationTestStatusTestWarning)).To(BeTrue())
+
+		policy.Spec.BlockOnWarnings = true
+		Expect(gitops.IsTestStatusPassedForPolicy(policy, intgteststat.IntegrationTestStatusTestWarning)).To(BeFalse())
+		Expect(gitops.IsTestStatusPassedForPolicy(policy, intgteststat.IntegrationTestStatusTestPassed)).To(BeTrue())
+		Expect(gitops.IsTestStatusPassedForPolicy(policy, intgteststat.IntegrationTestStatusTestFail)).To(BeFalse())
+	})
+
+	It("requires the scenarios of the quorums of the policy together", func() {
+		policy.Spec.RequiredScenarios = []string{"required"}
+		policy.Spec.Quorums = []v1beta2.ScenarioQuorum{{Name: "platforms", Scenarios: []string{"optional"}, MinPassed: 1}}
+		Expect(gitops.IsScenarioRequired(policy, optional)).To(BeTrue())
+		Expect(gitops.IsQuorumScenario(policy, optional)).To(BeTrue())
+		Expect(gitops.IsQuorumScenario(policy, required)).To(BeFalse())
+		Expect(gitops.IsQuorumScenario(nil, optional)).To(BeFalse())
+	})
+
+	It("evaluates the quorums of the policy", func() {
+		newScenarios := func(names ...string) *[]v1beta2.IntegrationTestScenario {
+			scenarios := []v1beta2.IntegrationTestScenario{}
+			for _, name := range names {
+				scenarios = append(scenarios, v1beta2.IntegrationTestScenario{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			}
+			return &scenarios
+		}
+		policy.Spec.Quorums = []v1beta2.ScenarioQuorum{
+			{Name: "platforms", Scenarios: []string{"linux", "windows", "macos"}, MinPassed: 2},
+		}
+		scenarios := newScenarios("required", "linux", "windows", "macos")
+		testStatuses, err := intgteststat.NewSnapshotIntegrationTestStatuses("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gitops.EvaluateScenarioQuorums(nil, scenarios, testStatuses)).To(BeNil())
+
+		testStatuses.UpdateTestStatusIfChanged("linux", intgteststat.IntegrationTestStatusTestPassed, "")
+		testStatuses.UpdateTestStatusIfChanged("windows", intgteststat.IntegrationTestStatusInProgress, "")
+		quorums := gitops.EvaluateScenarioQuorums(policy, scenarios, testStatuses)
+		Expect(quorums).To(HaveLen(1))
+		Expect(quorums[0].Verdict).To(BeEmpty())
+		Expect(quorums[0].PendingScenarios).To(Equal([]string{"windows", "macos"}))
+
+		testStatuses.UpdateTestStatusIfChanged("windows", intgteststat.IntegrationTestStatusTestPassed, "")
+		quorums = gitops.EvaluateScenarioQuorums(policy, scenarios, testStatuses)
+		Expect(quorums[0].Verdict).To(Equal(gitops.GatingDecisionPassed))
+		Expect(quorums[0].PassedScenarios).To(Equal([]string{"linux", "windows"}))
+		Expect(quorums[0].String()).To(Equal("quorum platforms: 2 of 3 scenarios passed, 2 required"))
+
+		testStatuses.UpdateTestStatusIfChanged("windows", intgteststat.IntegrationTestStatusTestFail, "")
+		testStatuses.UpdateTestStatusIfChanged("macos", intgteststat.IntegrationTestStatusTestError, "")
+		quorums = gitops.EvaluateScenarioQuorums(policy, scenarios, testStatuses)
+		Expect(quorums[0].Verdict).To(Equal(gitops.GatingDecisionFailed))
+		Expect(quorums[0].FailedScenarios).To(Equal([]string{"windows", "macos"}))
+
+		// the quorum only requires its scenarios applicable to the snapshot
+		quorums = gitops.EvaluateScenarioQuorums(policy, newScenarios("required", "linux"), testStatuses)
+		Expect(quorums[0].MinPassed).To(Equal(1))
+		Expect(quorums[0].Verdict).To(Equal(gitops.GatingDecisionPassed))
+		Expect(gitops.EvaluateScenarioQuorums(policy, newScenarios("required"), testStatuses)).To(BeEmpty())
+	})
+
+	It("evaluates the scenarios of the quorums defining a matrix by all of their cells", func() {
+		matrix := v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{Name: "linux"},
+			Spec: v1beta2.IntegrationTestScenarioSpec{
+				Matrix: []v1beta2.MatrixParameter{{Name: "arch", Values: []string{"amd64", "arm64"}}},
+			},
+		}
+		scenarios := gitops.ExpandIntegrationTestScenarioMatrix(&[]v1beta2.IntegrationTestScenario{matrix})
+		policy.Spec.Quorums = []v1beta2.ScenarioQuorum{{Name: "platforms", Scenarios: []string{"linux"}, MinPassed: 1}}
+		Expect(gitops.IsQuorumScenario(policy, &(*scenarios)[0])).To(BeTrue())
+
+		testStatuses, err := intgteststat.NewSnapshotIntegrationTestStatuses("")
+		Expect(err).ToNot(HaveOccurred())
+		testStatuses.UpdateTestStatusIfChanged((*scenarios)[0].Name, intgteststat.IntegrationTestStatusTestPassed, "")
+		Expect(gitops.EvaluateScenarioQuorums(policy, scenarios, testStatuses)[0].Verdict).To(BeEmpty())
+		testStatuses.UpdateTestStatusIfChanged((*scenarios)[1].Name, intgteststat.IntegrationTestStatusTestPassed, "")
+		Expect(gitops.EvaluateScenarioQuorums(policy, scenarios, testStatuses)[0].Verdict).To(Equal(gitops.GatingDecisionPassed))
+	})
+
+	It("enables auto-release unless the policy disables it", func() {
+		Expect(policy.IsAutoReleaseEnabled()).To(BeTrue())
+		autoRelease := false
+		policy.Spec.AutoRelease = &autoRelease
+		Expect(policy.IsAutoReleaseEnabled()).To(BeFalse())
+	})
+	It("allows the Snapshots to be auto-released within the release windows of the policy", func() {
+		now := time.Date(2024, 10, 15, 10, 30, 0, 0, time.UTC)
+		open, _, err := gitops.IsWithinReleaseWindow(nil, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+		open, _, err = gitops.IsWithinReleaseWindow(policy, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+
+		policy.Spec.ReleaseWindows = []v1beta2.ReleaseWindow{
+			{Schedule: "0 9 * * 1-5", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+		}
+		open, _, err = gitops.IsWithinReleaseWindow(policy, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+
+		policy.Spec.ReleaseWindows[0].Duration = metav1.Duration{Duration: time.Hour}
+		open, nextOpening, err := gitops.IsWithinReleaseWindow(policy, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(nextOpening).To(BeTemporally("==", time.Date(2024, 10, 16, 9, 0, 0, 0, time.UTC)))
+
+		// the window opening at noon in Prague is open at 10:30 UTC
+		policy.Spec.ReleaseWindows = append(policy.Spec.ReleaseWindows,
+			v1beta2.ReleaseWindow{Schedule: "0 12 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Prague"})
+		open, _, err = gitops.IsWithinReleaseWindow(policy, now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+
+	It("reports the release windows of the policy which can't be evaluated", func() {
+		now := time.Date(2024, 10, 15, 10, 30, 0, 0, time.UTC)
+		policy.Spec.ReleaseWindows = []v1beta2.ReleaseWindow{
+			{Schedule: "0 9 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus_Mons"},
+			{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			{Schedule: "0 12 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+		}
+		open, nextOpening, err := gitops.IsWithinReleaseWindow(policy, now)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Mars/Olympus_Mons"))
+		Expect(err.Error()).To(ContainSubstring("0 25 * * *"))
+		Expect(open).To(BeFalse())
+		Expect(nextOpening).To(BeTemporally("==", time.Date(2024, 10, 15, 12, 0, 0, 0, time.UTC)))
+	})
+
+	It("gets the testing deadline of the Snapshots from the policy or the environment", func() {
+		Expect(gitops.GetSnapshotTestingDeadline(nil)).To(Equal(time.Duration(0)))
+		Expect(gitops.GetSnapshotTestingDeadline(policy)).To(Equal(time.Duration(0)))
+
+		GinkgoT().Setenv(gitops.SnapshotTestingDeadlineEnvVar, "6h")
+		Expect(gitops.GetSnapshotTestingDeadline(policy)).To(Equal(6 * time.Hour))
+
+		policy.Spec.TestingDeadline = &metav1.Duration{Duration: 2 * time.Hour}
+		Expect(gitops.GetSnapshotTestingDeadline(policy)).To(Equal(2 * time.Hour))
+		// the policy can disable the deadline set for all applications
+		policy.Spec.TestingDeadline = &metav1.Duration{}
+		Expect(gitops.GetSnapshotTestingDeadline(policy)).To(Equal(time.Duration(0)))
+
+		GinkgoT().Setenv(gitops.SnapshotTestingDeadlineEnvVar, "forever")
+		_, err := gitops.GetSnapshotTestingDeadline(nil)
+		Expect(err).To(MatchError(ContainSubstring("invalid testing deadline \"forever\"")))
+	})
+})
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+)
+
+const (
+	// ScenarioRunHistoryLimit is the maximum number of the latest finished runs kept in the run history of an
+	// IntegrationTestScenario
+	ScenarioRunHistoryLimit = 20
+
+	// FlakySnapshotsThreshold is the number of the Snapshots of the run history of an IntegrationTestScenario whose
+	// runs both passed and failed from which the scenario is flagged as flaky
+	FlakySnapshotsThreshold = 2
+)
+
+// GetScenarioRunOutcome returns whether the finished run of an IntegrationTestScenario with the given test status
+// passed, and false as the second value when the status isn't the outcome of the tests, e.g. when the test was
+// invalid or deleted, so that it isn't part of the run history of the scenario.
+func GetScenarioRunOutcome(status intgteststat.IntegrationTestStatus) (bool, bool) {
+	switch status {
+	case intgteststat.IntegrationTestStatusTestPassed, intgteststat.IntegrationTestStatusTestWarning:
+		return true, true
+	case intgteststat.IntegrationTestStatusTestFail, intgteststat.IntegrationTestStatusTestError:
+		return false, true
+	}
+
+	return false, false
+}
+
+// RecordScenarioRunResult appends the result of a finished run to the run history of the IntegrationTestScenario,
+// dropping the oldest results beyond ScenarioRunHistoryLimit. It returns false if the run was already recorded.
+func RecordScenarioRunResult(scenario *v1beta2.IntegrationTestScenario, result v1beta2.ScenarioRunResult) bool {
+	for _, recorded := range scenario.Status.RunHistory {
+		if recorded.PipelineRun == result.PipelineRun {
+			return false
+		}
+	}
+
+	scenario.Status.RunHistory = append(scenario.Status.RunHistory, result)
+	if len(scenario.Status.RunHistory) > ScenarioRunHistoryLimit {
+		scenario.Status.RunHistory = scenario.Status.RunHistory[len(scenario.Status.RunHistory)-ScenarioRunHistoryLimit:]
+	}
+
+	return true
+}
+
+// IsScenarioRunHistoryFlaky returns true if the run history of an IntegrationTestScenario looks flaky, along with
+// the reason. The history looks flaky when the runs of the scenario both passed and failed for at least
+// FlakySnapshotsThreshold Snapshots, meaning their results changed when they were re-run for the same content.
+func IsScenarioRunHistoryFlaky(history []v1beta2.ScenarioRunResult) (bool, string) {
+	passed, failed := map[string]bool{}, map[string]bool{}
+	for _, result := range history {
+		if result.Passed {
+			passed[result.Snapshot] = true
+		} else {
+			failed[result.Snapshot] = true
+		}
+	}
+
+	flakySnapshots := []string{}
+	for snapshot := range passed {
+		if failed[snapshot] {
+			flakySnapshots = append(flakySnapshots, snapshot)
+		}
+	}
+	if len(flakySnapshots) < FlakySnapshotsThreshold {
+		return false, ""
+	}
+	sort.Strings(flakySnapshots)
+
+	return true, fmt.Sprintf("the runs of the scenario both passed and failed for the Snapshots %s",
+		strings.Join(flakySnapshots, ", "))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	h "github.com/konflux-ci/integration-service/helpers"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+)
+
+var _ = Describe("IntegrationTestScenario flakiness", func() {
+
+	var scenario *v1beta2.IntegrationTestScenario
+
+	// newRunResult returns the result of a run of the scenario for the given snapshot
+	newRunResult := func(snapshot, pipelineRun string, passed bool) v1beta2.ScenarioRunResult {
+		return v1beta2.ScenarioRunResult{
+			Snapshot:       snapshot,
+			PipelineRun:    pipelineRun,
+			Passed:         passed,
+			CompletionTime: metav1.Now(),
+		}
+	}
+
+	BeforeEach(func() {
+		scenario = &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "scenario-sample",
+				Namespace: "default",
+			},
+		}
+	})
+
+	DescribeTable("returns the outcome of the runs",
+		func(status intgteststat.IntegrationTestStatus, expectedPassed, expectedOk bool) {
+			passed, ok := gitops.GetScenarioRunOutcome(status)
+			Expect(passed).To(Equal(expectedPassed))
+			Expect(ok).To(Equal(expectedOk))
+		},
+		Entry("Passed", intgteststat.IntegrationTestStatusTestPassed, true, true),
+		Entry("Warning", intgteststat.IntegrationTestStatusTestWarning, true, true),
+		Entry("Failed", intgteststat.IntegrationTestStatusTestFail, false, true),
+		Entry("Error", intgteststat.IntegrationTestStatusTestError, false, true),
+		Entry("Invalid", intgteststat.IntegrationTestStatusTestInvalid, false, false),
+		Entry("Deleted", intgteststat.IntegrationTestStatusDeleted, false, false),
+		Entry("In progress", intgteststat.IntegrationTestStatusInProgress, false, false),
+	)
+
+	It("records the runs once and keeps only the latest ones", func() {
+		Expect(gitops.RecordScenarioRunResult(scenario, newRunResult("snapshot", "run-0", true))).To(BeTrue())
+		Expect(gitops.RecordScenarioRunResult(scenario, newRunResult("snapshot", "run-0", true))).To(BeFalse())
+		Expect(scenario.Status.RunHistory).To(HaveLen(1))
+
+		for i := 1; i <= gitops.ScenarioRunHistoryLimit; i++ {
+			Expect(gitops.RecordScenarioRunResult(scenario, newRunResult("snapshot", fmt.Sprintf("run-%d", i), true))).To(BeTrue())
+		}
+		Expect(scenario.Status.RunHistory).To(HaveLen(gitops.ScenarioRunHistoryLimit))
+		Expect(scenario.Status.RunHistory[0].PipelineRun).To(Equal("run-1"))
+	})
+
+	It("detects the run histories whose results change for the same snapshots", func() {
+		history := []v1beta2.ScenarioRunResult{
+			newRunResult("snapshot-a", "run-1", false),
+			newRunResult("snapshot-a", "run-2", true),
+			newRunResult("snapshot-b", "run-3", false),
+			newRunResult("snapshot-c", "run-4", true),
+		}
+		flaky, _ := gitops.IsScenarioRunHistoryFlaky(history)
+		Expect(flaky).To(BeFalse())
+
+		history = append(history, newRunResult("snapshot-b", "run-5", true))
+		flaky, reason := gitops.IsScenarioRunHistoryFlaky(history)
+		Expect(flaky).To(BeTrue())
+		Expect(reason).To(Equal("the runs of the scenario both passed and failed for the Snapshots snapshot-a, snapshot-b"))
+	})
+
+	It("quarantines the flaky scenarios only when they opted into it", func() {
+		scenario.Annotations = map[string]string{h.FlakyScenarioAnnotation: "flaky"}
+		Expect(h.IsScenarioFlaky(scenario)).To(BeTrue())
+		Expect(h.IsScenarioQuarantined(scenario)).To(BeFalse())
+		Expect(gitops.IsScenarioRequired(nil, scenario)).To(BeTrue())
+
+		scenario.Spec.QuarantineFlaky = true
+		Expect(h.IsScenarioQuarantined(scenario)).To(BeTrue())
+		Expect(gitops.IsScenarioRequired(nil, scenario)).To(BeFalse())
+	})
+})
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/tekton"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// invalidMatrixCellNameChars matches the characters which can't be part of the name of a matrix cell.
+var invalidMatrixCellNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// IsMatrixScenario returns true if the IntegrationTestScenario defines a matrix of parameters.
+func IsMatrixScenario(scenario *v1beta2.IntegrationTestScenario) bool {
+	return len(scenario.Spec.Matrix) > 0
+}
+
+// NewMatrixCellScenarios returns one IntegrationTestScenario per combination of the values of the matrix parameters
+// of the given IntegrationTestScenario. Every matrix cell is named after the scenario and the values it's tested with,
+// passes its values as pipeline parameters and is labeled with the name of the scenario it belongs to. Only the first
+// v1beta2.MaxMatrixCells combinations are returned for the scenarios created before the webhook rejected larger matrices.
+func NewMatrixCellScenarios(scenario *v1beta2.IntegrationTestScenario) []v1beta2.IntegrationTestScenario {
+	combinations := [][]v1beta2.PipelineParameter{{}}
+	for _, matrixParam := range scenario.Spec.Matrix {
+		expandedCombinations := [][]v1beta2.PipelineParameter{}
+		for _, combination := range combinations {
+			for _, value := range matrixParam.Values {
+				if len(expandedCombinations) == v1beta2.MaxMatrixCells {
+					break
+				}
+				expandedCombination := append(append([]v1beta2.PipelineParameter{}, combination...),
+					v1beta2.PipelineParameter{Name: matrixParam.Name, Value: value})
+				expandedCombinations = append(expandedCombinations, expandedCombination)
+			}
+		}
+		combinations = expandedCombinations
+	}
+
+	cells := []v1beta2.IntegrationTestScenario{}
+	cellNames := map[string]bool{}
+	for i, combination := range combinations {
+		cell := scenario.DeepCopy()
+		cell.Name = getMatrixCellName(scenario.Name, combination, i)
+		if cellNames[cell.Name] {
+			cell.Name = fmt.Sprintf("%s-%d", scenario.Name, i+1)
+		}
+		cellNames[cell.Name] = true
+		cell.Spec.Matrix = nil
+		cell.Spec.Params = withMatrixCellParams(cell.Spec.Params, combination)
+		_ = metadata.SetLabel(cell, tekton.MatrixScenarioLabel, scenario.Name)
+		cells = append(cells, *cell)
+	}
+
+	return cells
+}
+
+// ExpandIntegrationTestScenarioMatrix returns the given list of IntegrationTestScenarios with the scenarios defining a
+// matrix replaced by their matrix cells, so that every matrix cell is tested and reported as a separate scenario.
+func ExpandIntegrationTestScenarioMatrix(scenarios *[]v1beta2.IntegrationTestScenario) *[]v1beta2.IntegrationTestScenario {
+	if scenarios == nil {
+		return nil
+	}
+
+	expandedScenarios := []v1beta2.IntegrationTestScenario{}
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		if IsMatrixScenario(&scenario) {
+			expandedScenarios = append(expandedScenarios, NewMatrixCellScenarios(&scenario)...)
+		} else {
+			expandedScenarios = append(expandedScenarios, scenario)
+		}
+	}
+
+	return &expandedScenarios
+}
+
+// FindMatrixCellScenario returns the matrix cell with the given name among the matrix cells of the given
+// IntegrationTestScenarios, or nil if none of the scenarios has a matrix cell with that name.
+func FindMatrixCellScenario(scenarios *[]v1beta2.IntegrationTestScenario, cellName string) *v1beta2.IntegrationTestScenario {
+	if scenarios == nil {
+		return nil
+	}
+
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		if !IsMatrixScenario(&scenario) {
+			continue
+		}
+		for _, cell := range NewMatrixCellScenarios(&scenario) {
+			if cell.Name == cellName {
+				return &cell
+			}
+		}
+	}
+
+	return nil
+}
+
+// getMatrixCellName returns the name of the matrix cell of the scenario tested with the given parameters. The index
+// of the matrix cell is used instead of the parameter values when they don't make up a valid label value.
+func getMatrixCellName(scenarioName string, params []v1beta2.PipelineParameter, index int) string {
+	values := []string{scenarioName}
+	for _, param := range params {
+		values = append(values, strings.Trim(invalidMatrixCellNameChars.ReplaceAllString(strings.ToLower(param.Value), "-"), "-."))
+	}
+
+	cellName := strings.Join(values, "-")
+	if len(validation.IsValidLabelValue(cellName)) > 0 {
+		return fmt.Sprintf("%s-%d", scenarioName, index+1)
+	}
+
+	return cellName
+}
+
+// withMatrixCellParams returns the pipeline parameters of the scenario with the values of the matrix cell, which take
+// precedence over the values of the parameters of the same name.
+func withMatrixCellParams(params, cellParams []v1beta2.PipelineParameter) []v1beta2.PipelineParameter {
+	cellParamNames := map[string]bool{}
+	for _, cellParam := range cellParams {
+		cellParamNames[cellParam.Name] = true
+	}
+
+	mergedParams := []v1beta2.PipelineParameter{}
+	for _, param := range params {
+		if !cellParamNames[param.Name] {
+			mergedParams = append(mergedParams, param)
+		}
+	}
+
+	return append(mergedParams, cellParams...)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	h "github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/tekton"
+)
+
+var _ = Describe("IntegrationTestScenario matrix", func() {
+
+	var matrixScenario, scenario *v1beta2.IntegrationTestScenario
+
+	BeforeEach(func() {
+		scenario = &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "scenario-sample",
+				Namespace: "default",
+			},
+			Spec: v1beta2.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+			},
+		}
+		matrixScenario = &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "e2e",
+				Namespace: "default",
+				Labels:    map[string]string{h.OptionalScenarioLabel: "false"},
+			},
+			Spec: v1beta2.IntegrationTestScenarioSpec{
+				Application: "application-sample",
+				Params: []v1beta2.PipelineParameter{
+					{Name: "DATABASE", Value: "sqlite"},
+					{Name: "TIMEOUT", Value: "1h"},
+				},
+				Matrix: []v1beta2.MatrixParameter{
+					{Name: "OCP_VERSION", Values: []string{"4.14", "4.15"}},
+					{Name: "DATABASE", Values: []string{"postgres", "MySQL 8"}},
+				},
+			},
+		}
+	})
+
+	It("creates a matrix cell per combination of the matrix parameter values", func() {
+		Expect(gitops.IsMatrixScenario(scenario)).To(BeFalse())
+		Expect(gitops.IsMatrixScenario(matrixScenario)).To(BeTrue())
+
+		cells := gitops.NewMatrixCellScenarios(matrixScenario)
+		Expect(cells).To(HaveLen(4))
+		Expect(cells[0].Name).To(Equal("e2e-4.14-postgres"))
+		Expect(cells[1].Name).To(Equal("e2e-4.14-mysql-8"))
+		Expect(cells[2].Name).To(Equal("e2e-4.15-postgres"))
+		Expect(cells[3].Name).To(Equal("e2e-4.15-mysql-8"))
+
+		Expect(cells[1].Spec.Matrix).To(BeEmpty())
+		Expect(cells[1].Spec.Params).To(ConsistOf(
+			v1beta2.PipelineParameter{Name: "TIMEOUT", Value: "1h"},
+			v1beta2.PipelineParameter{Name: "OCP_VERSION", Value: "4.14"},
+			v1beta2.PipelineParameter{Name: "DATABASE", Value: "MySQL 8"},
+		))
+		Expect(cells[1].Labels).To(HaveKeyWithValue(tekton.MatrixScenarioLabel, "e2e"))
+		Expect(cells[1].Labels).To(HaveKeyWithValue(h.OptionalScenarioLabel, "false"))
+		Expect(matrixScenario.Labels).NotTo(HaveKey(tekton.MatrixScenarioLabel))
+	})
+
+	It("names the matrix cells by their index when their values don't make up a valid name", func() {
+		matrixScenario.Spec.Matrix = []v1beta2.MatrixParameter{
+			{Name: "VERSION", Values: []string{"1", "1"}},
+			{Name: "DESCRIPTION", Values: []string{strings.Repeat("a", 64)}},
+		}
+
+		cells := gitops.NewMatrixCellScenarios(matrixScenario)
+		Expect(cells).To(HaveLen(2))
+		Expect(cells[0].Name).To(Equal("e2e-1"))
+		Expect(cells[1].Name).To(Equal("e2e-2"))
+	})
+
+	It("caps the number of matrix cells", func() {
+		values := []string{}
+		for i := 0; i < 10; i++ {
+			values = append(values, fmt.Sprintf("%d", i))
+		}
+		matrixScenario.Spec.Matrix = []v1beta2.MatrixParameter{
+			{Name: "MAJOR", Values: values},
+			{Name: "MINOR", Values: values},
+		}
+
+		cells := gitops.NewMatrixCellScenarios(matrixScenario)
+		Expect(cells).To(HaveLen(v1beta2.MaxMatrixCells))
+		Expect(cells[v1beta2.MaxMatrixCells-1].Name).To(Equal("e2e-6-3"))
+	})
+
+	It("expands the matrix scenarios of the list into their matrix cells", func() {
+		Expect(gitops.ExpandIntegrationTestScenarioMatrix(nil)).To(BeNil())
+
+		scenarios := gitops.ExpandIntegrationTestScenarioMatrix(&[]v1beta2.IntegrationTestScenario{*scenario, *matrixScenario})
+		Expect(*scenarios).To(HaveLen(5))
+		Expect((*scenarios)[0].Name).To(Equal(scenario.Name))
+		Expect((*scenarios)[4].Name).To(Equal("e2e-4.15-mysql-8"))
+	})
+
+	It("finds the matrix cells of the scenarios by their name", func() {
+		scenarios := &[]v1beta2.IntegrationTestScenario{*scenario, *matrixScenario}
+
+		cell := gitops.FindMatrixCellScenario(scenarios, "e2e-4.15-postgres")
+		Expect(cell).NotTo(BeNil())
+		Expect(cell.Spec.Params).To(ContainElement(v1beta2.PipelineParameter{Name: "OCP_VERSION", Value: "4.15"}))
+		Expect(gitops.FindMatrixCellScenario(scenarios, scenario.Name)).To(BeNil())
+		Expect(gitops.FindMatrixCellScenario(nil, "e2e-4.15-postgres")).To(BeNil())
+	})
+})
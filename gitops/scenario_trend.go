@@ -0,0 +1,74 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"math"
+	"time"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScenarioTrend contains the trends computed from the run history of an IntegrationTestScenario.
+type ScenarioTrend struct {
+	// Runs is the number of the runs of the history
+	Runs int
+	// Passed is the number of the runs of the history which passed
+	Passed int
+	// AverageDuration is the average duration of the runs of the history whose duration is known
+	AverageDuration time.Duration
+}
+
+// ComputeScenarioTrend returns the trends of the given run history of an IntegrationTestScenario.
+func ComputeScenarioTrend(history []v1beta2.ScenarioRunResult) ScenarioTrend {
+	trend := ScenarioTrend{Runs: len(history)}
+	var totalDuration time.Duration
+	timedRuns := 0
+	for _, result := range history {
+		if result.Passed {
+			trend.Passed++
+		}
+		if result.Duration.Duration > 0 {
+			totalDuration += result.Duration.Duration
+			timedRuns++
+		}
+	}
+	if timedRuns > 0 {
+		trend.AverageDuration = totalDuration / time.Duration(timedRuns)
+	}
+
+	return trend
+}
+
+// PassRate returns the ratio of the runs which passed, between 0 and 1, or 0 when there are no runs.
+func (t ScenarioTrend) PassRate() float64 {
+	if t.Runs == 0 {
+		return 0
+	}
+
+	return float64(t.Passed) / float64(t.Runs)
+}
+
+// Status returns the trends in the form they are exposed in the status of the IntegrationTestScenarios.
+func (t ScenarioTrend) Status() *v1beta2.ScenarioTrend {
+	return &v1beta2.ScenarioTrend{
+		Runs:            t.Runs,
+		PassRate:        int(math.Round(t.PassRate() * 100)),
+		AverageDuration: metav1.Duration{Duration: t.AverageDuration.Round(time.Second)},
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+)
+
+var _ = Describe("IntegrationTestScenario trends", func() {
+
+	// newRunResult returns the result of a run of the scenario which took the given duration
+	newRunResult := func(pipelineRun string, passed bool, duration time.Duration) v1beta2.ScenarioRunResult {
+		return v1beta2.ScenarioRunResult{
+			Snapshot:       "snapshot-sample",
+			PipelineRun:    pipelineRun,
+			Passed:         passed,
+			CompletionTime: metav1.Now(),
+			Duration:       metav1.Duration{Duration: duration},
+		}
+	}
+
+	It("computes the pass rate and average duration of the runs", func() {
+		trend := gitops.ComputeScenarioTrend([]v1beta2.ScenarioRunResult{
+			newRunResult("run-1", true, time.Minute),
+			newRunResult("run-2", false, 3*time.Minute),
+			newRunResult("run-3", true, 0),
+		})
+		Expect(trend.Runs).To(Equal(3))
+		Expect(trend.Passed).To(Equal(2))
+		Expect(trend.PassRate()).To(BeNumerically("~", 0.667, 0.001))
+		// the runs of unknown duration aren't part of the average
+		Expect(trend.AverageDuration).To(Equal(2 * time.Minute))
+
+		status := trend.Status()
+		Expect(status.Runs).To(Equal(3))
+		Expect(status.PassRate).To(Equal(67))
+		Expect(status.AverageDuration.Duration).To(Equal(2 * time.Minute))
+	})
+
+	It("computes empty trends for an empty history", func() {
+		trend := gitops.ComputeScenarioTrend(nil)
+		Expect(trend.Runs).To(BeZero())
+		Expect(trend.PassRate()).To(BeZero())
+		Expect(trend.AverageDuration).To(BeZero())
+	})
+})
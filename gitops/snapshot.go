@@ -18,15 +18,21 @@ package gitops
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
 	"github.com/konflux-ci/integration-service/tekton"
 	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
@@ -44,6 +50,9 @@ const (
 	// SnapshotTypeLabel contains the type of the Snapshot.
 	SnapshotTypeLabel = "test.appstudio.openshift.io/type"
 
+	// SnapshotContentHashLabel contains the hash of the components of the Snapshot, see GetSnapshotContentHash.
+	SnapshotContentHashLabel = "test.appstudio.openshift.io/content-hash"
+
 	// SnapshotIntegrationTestRun contains name of test we want to trigger run
 	SnapshotIntegrationTestRun = "test.appstudio.openshift.io/run"
 
@@ -68,6 +77,55 @@ const (
 	// SnapshotStatusReportAnnotation contains metadata of tests related to status reporting to git provider
 	SnapshotStatusReportAnnotation = "test.appstudio.openshift.io/git-reporter-status"
 
+	// SnapshotStatusReportRequestedAnnotation is set on the Snapshots created for push events whose test status
+	// was requested to be reported to the git provider anyway, e.g. by a scheduled SnapshotRun
+	SnapshotStatusReportRequestedAnnotation = "test.appstudio.openshift.io/report-status"
+
+	// SnapshotRemovedComponentAnnotation is set on the Snapshots created after a Component was removed from
+	// the application, holding the name of the removed Component
+	SnapshotRemovedComponentAnnotation = "test.appstudio.openshift.io/removed-component"
+
+	// PushedImageAnnotation is set on a Component by the image-controller or any other external tooling, holding the
+	// pull spec by digest of the image pushed to the image repository of the Component without a build in the cluster.
+	// It is also set on the Snapshots created for the pushed images.
+	PushedImageAnnotation = "test.appstudio.openshift.io/pushed-image"
+
+	// ComposedPushedImageAnnotation is set on a Component, holding the last pushed image a Snapshot was created for
+	ComposedPushedImageAnnotation = "test.appstudio.openshift.io/composed-pushed-image"
+
+	// SnapshotDiffAnnotation contains the json description of the component images which changed in the Snapshot
+	// relative to the previous Snapshot of the application
+	SnapshotDiffAnnotation = "test.appstudio.openshift.io/diff"
+
+	// SnapshotArchitectureDigestsAnnotation contains the json map of the multi-arch components of the Snapshot to
+	// the digests of their images per architecture
+	SnapshotArchitectureDigestsAnnotation = "test.appstudio.openshift.io/component-architecture-digests"
+
+	// ComponentArchitectureDigestsAnnotation contains the json map of the architectures to the digests of the images
+	// of the multi-arch Component image in the global candidate list
+	ComponentArchitectureDigestsAnnotation = "test.appstudio.openshift.io/architecture-digests"
+
+	// SnapshotImageVerificationAnnotation contains the json result of the verification of the signature and provenance
+	// attestation of the image built for the Snapshot, it is set when the Snapshot is created
+	SnapshotImageVerificationAnnotation = "test.appstudio.openshift.io/image-verification"
+
+	// SnapshotSBOMsAnnotation contains the json map of the components of the Snapshot to the references of the SBOMs
+	// of their images
+	SnapshotSBOMsAnnotation = "test.appstudio.openshift.io/component-sboms"
+
+	// ComponentSBOMAnnotation contains the reference of the SBOM of the Component image in the global candidate list
+	ComponentSBOMAnnotation = "test.appstudio.openshift.io/sbom"
+
+	// SnapshotGatingDecisionsAnnotation contains the json list of the latest gating decisions made about the Snapshot
+	SnapshotGatingDecisionsAnnotation = "test.appstudio.openshift.io/gating-decisions"
+
+	// SnapshotDecisionExplanationAnnotation contains the compact json explanation of the latest gating verdict of the Snapshot
+	SnapshotDecisionExplanationAnnotation = "test.appstudio.openshift.io/decision-explanation"
+
+	// SnapshotOnboardingFailedAnnotation contains the name of the new component which wasn't added to the global
+	// candidate list since the Snapshot failed its integration tests, so that it's only reported once
+	SnapshotOnboardingFailedAnnotation = "test.appstudio.openshift.io/onboarding-failed"
+
 	// BuildPipelineRunPrefix contains the build pipeline run related labels and annotations
 	BuildPipelineRunPrefix = "build.appstudio"
 
@@ -116,6 +174,9 @@ const (
 	// PipelineAsCodePullRequestAnnotation is the git repository's pull request identifier
 	PipelineAsCodePullRequestAnnotation = PipelinesAsCodePrefix + "/pull-request"
 
+	// PipelineAsCodeTargetBranchAnnotation is the branch targeted by the event which triggered the pipelinerun in build service.
+	PipelineAsCodeTargetBranchAnnotation = PipelinesAsCodePrefix + "/branch"
+
 	// PipelineAsCodeSourceProjectIDAnnotation is the source project ID for gitlab
 	PipelineAsCodeSourceProjectIDAnnotation = PipelinesAsCodePrefix + "/source-project-id"
 
@@ -158,28 +219,18 @@ const (
 	// SnapshotAutoReleasedCondition is the condition for marking if Snapshot was auto-released released with AppStudio.
 	SnapshotAutoReleasedCondition = "AutoReleased"
 
+	// SnapshotRevalidatedCondition is the condition for marking whether the released Snapshot still passes its
+	// required integration tests when they are periodically re-run.
+	SnapshotRevalidatedCondition = "Revalidated"
+
+	// SnapshotImageVerifiedCondition is the condition for marking whether the signature and provenance attestation
+	// of the image built for the Snapshot were verified.
+	SnapshotImageVerifiedCondition = "ImageVerified"
+
 	// SnapshotAddedToGlobalCandidateListCondition is the condition for marking if Snapshot's component was added to
 	// the global candidate list.
 	SnapshotAddedToGlobalCandidateListCondition = "AddedToGlobalCandidateList"
 
-	// AppStudioTestSucceededConditionSatisfied is the reason that's set when the AppStudio tests succeed.
-	AppStudioTestSucceededConditionSatisfied = "Passed"
-
-	// AppStudioTestSucceededConditionFailed is the reason that's set when the AppStudio tests fail.
-	AppStudioTestSucceededConditionFailed = "Failed"
-
-	// AppStudioIntegrationStatusInvalid is the reason that's set when the AppStudio integration gets into an invalid state.
-	AppStudioIntegrationStatusInvalid = "Invalid"
-
-	// AppStudioIntegrationStatusErrorOccured is the reason that's set when the AppStudio integration gets into an error state.
-	AppStudioIntegrationStatusErrorOccured = "ErrorOccured"
-
-	//AppStudioIntegrationStatusInProgress is the reason that's set when the AppStudio tests gets into an in progress state.
-	AppStudioIntegrationStatusInProgress = "InProgress"
-
-	//AppStudioIntegrationStatusFinished is the reason that's set when the AppStudio tests finish.
-	AppStudioIntegrationStatusFinished = "Finished"
-
 	// the statuses needed to report to GiHub when creating check run or commit status, see doc
 	// https://docs.github.com/en/rest/guides/using-the-rest-api-to-interact-with-checks?apiVersion=2022-11-28
 	// https://docs.github.com/en/free-pro-team@latest/rest/checks/runs?apiVersion=2022-11-28#create-a-check-run
@@ -195,10 +246,21 @@ const (
 	//IntegrationTestStatusErrorGithub is the status reported to github when integration test experience error
 	IntegrationTestStatusErrorGithub = "error"
 
+	//IntegrationTestStatusNeutralGithub is the conclusion reported to github when integration test passed with warnings
+	IntegrationTestStatusNeutralGithub = "neutral"
+
+	//IntegrationTestStatusSkippedGithub is the conclusion reported to github when integration test was suspended
+	IntegrationTestStatusSkippedGithub = "skipped"
+
 	//IntegrationTestStatusInProgressGithub is the status reported to github when integration test is in progress
 	IntegrationTestStatusInProgressGithub = "in_progress"
 )
 
+const (
+	// IntegrationServiceEventRecorderName is the name of the component reported as the source of the emitted events.
+	IntegrationServiceEventRecorderName = "integration-service"
+)
+
 var (
 	// SnapshotComponentLabel contains the name of the updated Snapshot component - it should match the pipeline label.
 	SnapshotComponentLabel = tekton.ComponentNameLabel
@@ -209,25 +271,24 @@ func IsSnapshotMarkedAsPassed(snapshot *applicationapiv1alpha1.Snapshot) bool {
 	return IsSnapshotStatusConditionSet(snapshot, AppStudioTestSucceededCondition, metav1.ConditionTrue, "")
 }
 
-// MarkSnapshotAsPassed updates the AppStudio Test succeeded condition for the Snapshot to passed.
+// MarkSnapshotAsPassed server-side applies the AppStudio Test succeeded condition for the Snapshot as passed. The
+// Snapshot is fetched again and the condition re-applied if the Snapshot was changed in the meantime.
 // If the patch command fails, an error will be returned.
 func MarkSnapshotAsPassed(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
 	condition := metav1.Condition{
 		Type:    AppStudioTestSucceededCondition,
 		Status:  metav1.ConditionTrue,
-		Reason:  AppStudioTestSucceededConditionSatisfied,
+		Reason:  reasons.AppStudioTestSucceededConditionSatisfied,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
-
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
+	err := applySnapshotConditions(ctx, adapterClient, snapshot, condition)
 	if err != nil {
 		return err
 	}
 
 	snapshotCompletionTime := &metav1.Time{Time: time.Now()}
 	go metrics.RegisterCompletedSnapshot(condition.Type, condition.Reason, snapshot.GetCreationTimestamp(), snapshotCompletionTime)
+	go metrics.RegisterSnapshotGatingDecision(snapshot.Namespace, snapshot.Spec.Application, true, snapshot.GetCreationTimestamp(), snapshotCompletionTime)
 	return nil
 }
 
@@ -239,77 +300,114 @@ func IsSnapshotMarkedAsFailed(snapshot *applicationapiv1alpha1.Snapshot) bool {
 // MarkSnapshotAsFailed updates the AppStudio Test succeeded condition for the Snapshot to failed.
 // If the patch command fails, an error will be returned.
 func MarkSnapshotAsFailed(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
+	return markSnapshotAsFailed(ctx, adapterClient, snapshot, reasons.AppStudioTestSucceededConditionFailed, message)
+}
+
+// IsSnapshotMarkedAsTimedOut returns true if snapshot is marked as failed since its tests didn't finish before
+// its testing deadline
+func IsSnapshotMarkedAsTimedOut(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return IsSnapshotStatusConditionSet(snapshot, AppStudioTestSucceededCondition, metav1.ConditionFalse, reasons.AppStudioTestSucceededConditionTimedOut)
+}
+
+// MarkSnapshotAsTimedOut updates the AppStudio Test succeeded condition for the Snapshot to failed with the TimedOut
+// reason. If the patch command fails, an error will be returned.
+func MarkSnapshotAsTimedOut(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
+	return markSnapshotAsFailed(ctx, adapterClient, snapshot, reasons.AppStudioTestSucceededConditionTimedOut, message)
+}
+
+// markSnapshotAsFailed server-side applies the AppStudio Test succeeded condition for the Snapshot as failed with the
+// given reason.
+// The Snapshot is fetched again and the condition re-applied if the Snapshot was changed in the meantime.
+func markSnapshotAsFailed(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, reason, message string) error {
 	condition := metav1.Condition{
 		Type:    AppStudioTestSucceededCondition,
 		Status:  metav1.ConditionFalse,
-		Reason:  AppStudioTestSucceededConditionFailed,
+		Reason:  reason,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
-
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
+	err := applySnapshotConditions(ctx, adapterClient, snapshot, condition)
 	if err != nil {
 		return err
 	}
 
 	snapshotCompletionTime := &metav1.Time{Time: time.Now()}
 	go metrics.RegisterCompletedSnapshot(condition.Type, condition.Reason, snapshot.GetCreationTimestamp(), snapshotCompletionTime)
+	go metrics.RegisterSnapshotGatingDecision(snapshot.Namespace, snapshot.Spec.Application, false, snapshot.GetCreationTimestamp(), snapshotCompletionTime)
 	return nil
 }
 
-// MarkSnapshotAsInvalid updates the AppStudio integration status condition for the Snapshot to invalid.
-// If the patch command fails, an error will be returned.
+// MarkSnapshotAsInvalid server-side applies the AppStudio integration status condition for the Snapshot as invalid.
+// If the apply fails, an error will be returned.
 func MarkSnapshotAsInvalid(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
-	SetSnapshotIntegrationStatusAsInvalid(snapshot, message)
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
+	err := applySnapshotConditions(ctx, adapterClient, snapshot, newSnapshotIntegrationStatusInvalidCondition(message))
 	if err != nil {
 		return err
 	}
 
+	go metrics.RegisterInvalidSnapshot(AppStudioIntegrationStatusCondition, reasons.AppStudioIntegrationStatusInvalid)
 	return nil
 }
 
 // IsSnapshotMarkedAsInvalid returns true if snapshot is marked as failed
 func IsSnapshotMarkedAsInvalid(snapshot *applicationapiv1alpha1.Snapshot) bool {
-	return IsSnapshotStatusConditionSet(snapshot, AppStudioIntegrationStatusCondition, metav1.ConditionFalse, AppStudioIntegrationStatusInvalid)
+	return IsSnapshotStatusConditionSet(snapshot, AppStudioIntegrationStatusCondition, metav1.ConditionFalse, reasons.AppStudioIntegrationStatusInvalid)
 }
 
 // SetSnapshotIntegrationStatusAsInvalid sets the AppStudio integration status condition for the Snapshot to invalid.
 func SetSnapshotIntegrationStatusAsInvalid(snapshot *applicationapiv1alpha1.Snapshot, message string) {
-	condition := metav1.Condition{
+	meta.SetStatusCondition(&snapshot.Status.Conditions, newSnapshotIntegrationStatusInvalidCondition(message))
+	go metrics.RegisterInvalidSnapshot(AppStudioIntegrationStatusCondition, reasons.AppStudioIntegrationStatusInvalid)
+}
+
+// newSnapshotIntegrationStatusInvalidCondition returns the AppStudio integration status condition of an invalid Snapshot.
+func newSnapshotIntegrationStatusInvalidCondition(message string) metav1.Condition {
+	return metav1.Condition{
 		Type:    AppStudioIntegrationStatusCondition,
 		Status:  metav1.ConditionFalse,
-		Reason:  AppStudioIntegrationStatusInvalid,
+		Reason:  reasons.AppStudioIntegrationStatusInvalid,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
-	go metrics.RegisterInvalidSnapshot(AppStudioIntegrationStatusCondition, AppStudioIntegrationStatusInvalid)
 }
 
 // SetSnapshotIntegrationStatusAsError sets the AppStudio integration status condition for the Snapshot to error.
 func SetSnapshotIntegrationStatusAsError(snapshot *applicationapiv1alpha1.Snapshot, message string) {
-	condition := metav1.Condition{
+	meta.SetStatusCondition(&snapshot.Status.Conditions, newSnapshotIntegrationStatusErrorCondition(message))
+}
+
+// MarkSnapshotIntegrationStatusAsError server-side applies the AppStudio integration status condition for the Snapshot
+// as error. If the apply fails, an error will be returned.
+func MarkSnapshotIntegrationStatusAsError(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
+	return applySnapshotConditions(ctx, adapterClient, snapshot, newSnapshotIntegrationStatusErrorCondition(message))
+}
+
+// newSnapshotIntegrationStatusErrorCondition returns the AppStudio integration status condition of a Snapshot whose
+// integration failed with an error.
+func newSnapshotIntegrationStatusErrorCondition(message string) metav1.Condition {
+	return metav1.Condition{
 		Type:    AppStudioIntegrationStatusCondition,
 		Status:  metav1.ConditionFalse,
-		Reason:  AppStudioIntegrationStatusErrorOccured,
+		Reason:  reasons.AppStudioIntegrationStatusErrorOccured,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
+}
+
+// applySnapshotConditions server-side applies the given conditions to the status of the Snapshot, re-applying them to
+// the latest version of the Snapshot when it was changed in the meantime.
+func applySnapshotConditions(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, conditions ...metav1.Condition) error {
+	return helpers.ApplyStatusConditionWithRetry(ctx, adapterClient, snapshot, func() *[]metav1.Condition {
+		return &snapshot.Status.Conditions
+	}, conditions...)
 }
 
 // MarkSnapshotIntegrationStatusAsInProgress sets the AppStudio integration status condition for the Snapshot to In Progress.
 func MarkSnapshotIntegrationStatusAsInProgress(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
 	log := log.FromContext(ctx)
-	patch := client.MergeFrom(snapshot.DeepCopy())
-	meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+	err := applySnapshotConditions(ctx, adapterClient, snapshot, metav1.Condition{
 		Type:    AppStudioIntegrationStatusCondition,
 		Status:  metav1.ConditionUnknown,
-		Reason:  AppStudioIntegrationStatusInProgress,
+		Reason:  reasons.AppStudioIntegrationStatusInProgress,
 		Message: message,
 	})
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
 	if err != nil {
 		return err
 	}
@@ -332,6 +430,24 @@ func MarkSnapshotIntegrationStatusAsInProgress(ctx context.Context, adapterClien
 	return nil
 }
 
+// UpdateSnapshotIntegrationStatusProgress sets the AppStudio integration status condition for the Snapshot to In
+// Progress with the given message describing the progress of its integration tests. The condition is only applied
+// when it changed, so that the Snapshot isn't updated on every re-evaluation.
+func UpdateSnapshotIntegrationStatusProgress(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) (bool, error) {
+	condition := metav1.Condition{
+		Type:    AppStudioIntegrationStatusCondition,
+		Status:  metav1.ConditionUnknown,
+		Reason:  reasons.AppStudioIntegrationStatusInProgress,
+		Message: message,
+	}
+	current := meta.FindStatusCondition(snapshot.Status.Conditions, condition.Type)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return false, nil
+	}
+
+	return true, applySnapshotConditions(ctx, adapterClient, snapshot, condition)
+}
+
 // PrepareToRegisterIntegrationPipelineRunStarted is to do preparation before calling RegisterPipelineRunStarted
 // Don't use this function for PLR re-runs
 func PrepareToRegisterIntegrationPipelineRunStarted(snapshot *applicationapiv1alpha1.Snapshot) {
@@ -341,21 +457,13 @@ func PrepareToRegisterIntegrationPipelineRunStarted(snapshot *applicationapiv1al
 
 // MarkSnapshotIntegrationStatusAsFinished sets the AppStudio integration status condition for the Snapshot to Finished.
 func MarkSnapshotIntegrationStatusAsFinished(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
 	condition := metav1.Condition{
 		Type:    AppStudioIntegrationStatusCondition,
 		Status:  metav1.ConditionTrue,
-		Reason:  AppStudioIntegrationStatusFinished,
+		Reason:  reasons.AppStudioIntegrationStatusFinished,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
-
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return applySnapshotConditions(ctx, adapterClient, snapshot, condition)
 }
 
 // IsSnapshotNotStarted checks if the AppStudio Integration Status condition is not in progress status.
@@ -364,7 +472,7 @@ func IsSnapshotNotStarted(snapshot *applicationapiv1alpha1.Snapshot) bool {
 	if condition == nil {
 		condition = meta.FindStatusCondition(snapshot.Status.Conditions, LegacyIntegrationStatusCondition)
 	}
-	if condition == nil || condition.Reason != AppStudioIntegrationStatusInProgress {
+	if condition == nil || condition.Reason != reasons.AppStudioIntegrationStatusInProgress {
 		return true
 	}
 	return false
@@ -376,7 +484,7 @@ func IsSnapshotError(snapshot *applicationapiv1alpha1.Snapshot) bool {
 	if condition == nil {
 		condition = meta.FindStatusCondition(snapshot.Status.Conditions, LegacyIntegrationStatusCondition)
 	}
-	if condition.Reason == AppStudioIntegrationStatusErrorOccured {
+	if condition.Reason == reasons.AppStudioIntegrationStatusErrorOccured {
 		return true
 	}
 	return false
@@ -388,7 +496,7 @@ func IsSnapshotValid(snapshot *applicationapiv1alpha1.Snapshot) bool {
 	if condition == nil {
 		condition = meta.FindStatusCondition(snapshot.Status.Conditions, LegacyIntegrationStatusCondition)
 	}
-	if condition == nil || condition.Reason != AppStudioIntegrationStatusInvalid {
+	if condition == nil || condition.Reason != reasons.AppStudioIntegrationStatusInvalid {
 		return true
 	}
 	return false
@@ -396,7 +504,7 @@ func IsSnapshotValid(snapshot *applicationapiv1alpha1.Snapshot) bool {
 
 // IsSnapshotIntegrationStatusMarkedAsFinished returns true if snapshot is marked as finished
 func IsSnapshotIntegrationStatusMarkedAsFinished(snapshot *applicationapiv1alpha1.Snapshot) bool {
-	return IsSnapshotStatusConditionSet(snapshot, AppStudioIntegrationStatusCondition, metav1.ConditionTrue, AppStudioIntegrationStatusFinished)
+	return IsSnapshotStatusConditionSet(snapshot, AppStudioIntegrationStatusCondition, metav1.ConditionTrue, reasons.AppStudioIntegrationStatusFinished)
 }
 
 // IsSnapshotStatusConditionSet checks if the condition with the conditionType in the status of Snapshot has been marked as the conditionStatus and reason.
@@ -425,20 +533,140 @@ func IsSnapshotMarkedAsAutoReleased(snapshot *applicationapiv1alpha1.Snapshot) b
 // MarkSnapshotAsAutoReleased updates the SnapshotAutoReleasedCondition for the Snapshot to 'AutoReleased'.
 // If the patch command fails, an error will be returned.
 func MarkSnapshotAsAutoReleased(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
 	condition := metav1.Condition{
 		Type:    SnapshotAutoReleasedCondition,
 		Status:  metav1.ConditionTrue,
-		Reason:  "AutoReleased",
+		Reason:  reasons.SnapshotAutoReleasedReason,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
+	return applySnapshotConditions(ctx, adapterClient, snapshot, condition)
+}
 
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
+// IsSnapshotPendingReleaseWindow returns true if the snapshot passed outside of the release windows of its
+// application and waits for the next window to open to be auto-released
+func IsSnapshotPendingReleaseWindow(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return IsSnapshotStatusConditionSet(snapshot, SnapshotAutoReleasedCondition, metav1.ConditionFalse, reasons.SnapshotPassedPendingWindowReason)
+}
+
+// MarkSnapshotAsPendingReleaseWindow updates the SnapshotAutoReleasedCondition for the Snapshot to 'PassedPendingWindow'.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotAsPendingReleaseWindow(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
+	condition := metav1.Condition{
+		Type:    SnapshotAutoReleasedCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasons.SnapshotPassedPendingWindowReason,
+		Message: message,
+	}
+	return applySnapshotConditions(ctx, adapterClient, snapshot, condition)
+}
+
+// IsSnapshotRevalidationFailed returns true if the re-run integration tests of the released snapshot failed
+func IsSnapshotRevalidationFailed(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return IsSnapshotStatusConditionSet(snapshot, SnapshotRevalidatedCondition, metav1.ConditionFalse, reasons.SnapshotRevalidationFailed)
+}
+
+// MarkSnapshotRevalidation updates the SnapshotRevalidatedCondition for the Snapshot with the result of its re-run
+// integration tests. If the patch command fails, an error will be returned.
+func MarkSnapshotRevalidation(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, passed bool, message string) error {
+	condition := metav1.Condition{
+		Type:    SnapshotRevalidatedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasons.SnapshotRevalidationPassed,
+		Message: message,
+	}
+	if !passed {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasons.SnapshotRevalidationFailed
+	}
+	return applySnapshotConditions(ctx, adapterClient, snapshot, condition)
+}
+
+// ImageVerification is the result of the verification of the image built for a Snapshot.
+type ImageVerification struct {
+	// Verified is true when the image is signed and has a provenance attestation
+	Verified bool `json:"verified"`
+	// Message is a human readable description of the result
+	Message string `json:"message"`
+}
+
+// SetSnapshotImageVerification records the given result of the verification of the built image in the Snapshot
+// annotation, so that it is stored together with the Snapshot when it gets created.
+func SetSnapshotImageVerification(snapshot *applicationapiv1alpha1.Snapshot, verification ImageVerification) error {
+	value, err := json.Marshal(verification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal image verification into JSON: %w", err)
+	}
+
+	return metadata.SetAnnotation(&snapshot.ObjectMeta, SnapshotImageVerificationAnnotation, string(value))
+}
+
+// VerifySnapshotImage verifies that the image of the component is signed and has a provenance attestation with the
+// given verifier and records the result in the Snapshot annotation, so that it is stored together with the Snapshot
+// when it gets created. Nothing is recorded when the verifier is nil, i.e. image verification is disabled. An error is
+// returned only when the verification couldn't be completed, e.g. as the registry isn't reachable.
+func VerifySnapshotImage(ctx context.Context, verifier *signature.Verifier, snapshot *applicationapiv1alpha1.Snapshot, componentName, image string) (*ImageVerification, error) {
+	if verifier == nil {
+		return nil, nil
+	}
+
+	verification := ImageVerification{
+		Verified: true,
+		Message:  fmt.Sprintf("The image %s is signed and has a provenance attestation", image),
+	}
+	err := verifier.Verify(ctx, image)
+	if signature.IsVerificationFailed(err) {
+		verification.Verified = false
+		verification.Message = fmt.Sprintf("The image of component %s failed verification: %s", componentName, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &verification, SetSnapshotImageVerification(snapshot, verification)
+}
+
+// GetSnapshotImageVerification returns the result of the verification of the built image recorded in the Snapshot
+// annotation, or nil if the image wasn't verified.
+func GetSnapshotImageVerification(snapshot *applicationapiv1alpha1.Snapshot) (*ImageVerification, error) {
+	value, ok := snapshot.GetAnnotations()[SnapshotImageVerificationAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	verification := &ImageVerification{}
+	if err := json.Unmarshal([]byte(value), verification); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal image verification from snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return verification, nil
+}
+
+// IsSnapshotImageVerificationFailed returns true if the image built for the snapshot couldn't be verified
+func IsSnapshotImageVerificationFailed(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return IsSnapshotStatusConditionSet(snapshot, SnapshotImageVerifiedCondition, metav1.ConditionFalse, reasons.SnapshotImageVerificationFailed)
+}
+
+// MarkSnapshotImageVerification updates the SnapshotImageVerifiedCondition for the Snapshot with the result of the
+// verification of its built image. A Snapshot whose image couldn't be verified is also marked as invalid, so that
+// it's neither tested nor promoted. If the patch command fails, an error will be returned.
+func MarkSnapshotImageVerification(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, verified bool, message string) error {
+	condition := metav1.Condition{
+		Type:    SnapshotImageVerifiedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasons.SnapshotImageVerificationPassed,
+		Message: message,
+	}
+	if verified {
+		return applySnapshotConditions(ctx, adapterClient, snapshot, condition)
+	}
+
+	condition.Status = metav1.ConditionFalse
+	condition.Reason = reasons.SnapshotImageVerificationFailed
+	err := applySnapshotConditions(ctx, adapterClient, snapshot, condition, newSnapshotIntegrationStatusInvalidCondition(message))
 	if err != nil {
 		return err
 	}
 
+	go metrics.RegisterInvalidSnapshot(AppStudioIntegrationStatusCondition, reasons.AppStudioIntegrationStatusInvalid)
 	return nil
 }
 
@@ -450,21 +678,13 @@ func IsSnapshotMarkedAsAddedToGlobalCandidateList(snapshot *applicationapiv1alph
 // MarkSnapshotAsAddedToGlobalCandidateList updates the SnapshotAddedToGlobalCandidateListCondition for the Snapshot to true with reason 'Added'.
 // If the patch command fails, an error will be returned.
 func MarkSnapshotAsAddedToGlobalCandidateList(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
 	condition := metav1.Condition{
 		Type:    SnapshotAddedToGlobalCandidateListCondition,
 		Status:  metav1.ConditionTrue,
-		Reason:  "Added",
+		Reason:  reasons.SnapshotAddedToGlobalCandidateListReason,
 		Message: message,
 	}
-	meta.SetStatusCondition(&snapshot.Status.Conditions, condition)
-
-	err := adapterClient.Status().Patch(ctx, snapshot, patch)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return applySnapshotConditions(ctx, adapterClient, snapshot, condition)
 }
 
 // ValidateImageDigest checks if image url contains valid digest, return error if check fails
@@ -483,6 +703,13 @@ func HaveAppStudioTestsFinished(snapshot *applicationapiv1alpha1.Snapshot) bool
 	return statusCondition != nil && statusCondition.Status != metav1.ConditionUnknown
 }
 
+// IsSnapshotAwaitingTests returns true if the Snapshot is neither finished nor invalid, so that it's still awaiting
+// the results of its integration tests.
+func IsSnapshotAwaitingTests(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return !IsSnapshotIntegrationStatusMarkedAsFinished(snapshot) && !HaveAppStudioTestsFinished(snapshot) &&
+		!IsSnapshotMarkedAsInvalid(snapshot)
+}
+
 // HaveAppStudioTestsSucceeded checks if the AppStudio tests have finished by checking if the AppStudio Test Succeeded condition is set.
 func HaveAppStudioTestsSucceeded(snapshot *applicationapiv1alpha1.Snapshot) bool {
 	if meta.FindStatusCondition(snapshot.Status.Conditions, AppStudioTestSucceededCondition) == nil {
@@ -513,6 +740,19 @@ func GetAppStudioTestsFinishedTime(snapshot *applicationapiv1alpha1.Snapshot) (m
 	return metav1.Time{}, false
 }
 
+// GetBuildPipelineRunFinishTime returns the finish time of the build pipelineRun which produced the Snapshot
+// as recorded in its label, if the label is present and valid
+func GetBuildPipelineRunFinishTime(snapshot *applicationapiv1alpha1.Snapshot) (metav1.Time, bool) {
+	if !metadata.HasLabel(snapshot, BuildPipelineRunFinishTimeLabel) {
+		return metav1.Time{}, false
+	}
+	buildPipelineRunFinishTime, err := strconv.ParseInt(snapshot.Labels[BuildPipelineRunFinishTimeLabel], 10, 64)
+	if err != nil {
+		return metav1.Time{}, false
+	}
+	return metav1.Time{Time: time.Unix(buildPipelineRunFinishTime, 0)}, true
+}
+
 // CanSnapshotBePromoted checks if the Snapshot in question can be promoted for deployment and release.
 func CanSnapshotBePromoted(snapshot *applicationapiv1alpha1.Snapshot) (bool, []string) {
 	canBePromoted := true
@@ -549,9 +789,32 @@ func NewSnapshot(application *applicationapiv1alpha1.Application, snapshotCompon
 			Components:  *snapshotComponents,
 		},
 	}
+	snapshot.Labels = map[string]string{
+		SnapshotContentHashLabel: GetSnapshotContentHash(snapshot),
+	}
 	return snapshot
 }
 
+// GetSnapshotContentHash returns the hash of the components of the Snapshot, which doesn't depend on their order.
+// Snapshots with the same components have the same hash, so the hash stored in the SnapshotContentHashLabel
+// can be used to look up the candidates matching a Snapshot with a single indexed List. The hex encoded SHA-224
+// digest is used so the hash fits in a label value.
+func GetSnapshotContentHash(snapshot *applicationapiv1alpha1.Snapshot) string {
+	components := make([]applicationapiv1alpha1.SnapshotComponent, len(snapshot.Spec.Components))
+	copy(components, snapshot.Spec.Components)
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Name != components[j].Name {
+			return components[i].Name < components[j].Name
+		}
+		return components[i].ContainerImage < components[j].ContainerImage
+	})
+
+	// the components consist of strings only, so marshalling them can't fail
+	data, _ := json.Marshal(components)
+	digest := sha256.Sum224(data)
+	return hex.EncodeToString(digest[:])
+}
+
 // CompareSnapshots compares two Snapshots and returns boolean true if their images match exactly.
 func CompareSnapshots(expectedSnapshot *applicationapiv1alpha1.Snapshot, foundSnapshot *applicationapiv1alpha1.Snapshot) bool {
 	// Check if the snapshots are created by the same event type
@@ -725,7 +988,9 @@ func PrepareSnapshot(ctx context.Context, adapterClient client.Client, applicati
 	return snapshot, nil
 }
 
-// FindMatchingSnapshot tries to find the expected Snapshot with the same set of images.
+// FindMatchingSnapshot tries to find the expected Snapshot with the same set of images. The candidates are expected to be
+// the Snapshots with the same SnapshotContentHashLabel, the hash is only used to narrow them down since the
+// components are compared exactly.
 func FindMatchingSnapshot(application *applicationapiv1alpha1.Application, allSnapshots *[]applicationapiv1alpha1.Snapshot, expectedSnapshot *applicationapiv1alpha1.Snapshot) *applicationapiv1alpha1.Snapshot {
 	for _, foundSnapshot := range *allSnapshots {
 		foundSnapshot := foundSnapshot
@@ -768,16 +1033,80 @@ func RemoveIntegrationTestRerunLabel(ctx context.Context, adapterClient client.C
 	return nil
 }
 
-// AddIntegrationTestRerunLabel adding re-run label to snapshot
-func AddIntegrationTestRerunLabel(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarioName string) error {
-	patch := client.MergeFrom(snapshot.DeepCopy())
-	newLabel := map[string]string{}
-	newLabel[SnapshotIntegrationTestRun] = integrationTestScenarioName
-	err := metadata.AddLabels(snapshot, newLabel)
+// IsSnapshotCreatedForComponentRemoval returns true if the Snapshot was created after a Component was removed from
+// the application, so only their required integration tests are run against the remaining components.
+func IsSnapshotCreatedForComponentRemoval(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return metadata.HasAnnotation(snapshot, SnapshotRemovedComponentAnnotation)
+}
+
+// IsSnapshotStatusReportRequested returns true if the test status of the Snapshot was requested to be reported to
+// the git provider regardless of the event the Snapshot was created for.
+func IsSnapshotStatusReportRequested(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return metadata.HasAnnotationWithValue(snapshot, SnapshotStatusReportRequestedAnnotation, "true")
+}
+
+// RequestSnapshotStatusReport annotates the Snapshot so its test status is reported to the git provider
+// regardless of the event the Snapshot was created for. The annotation is server-side applied.
+func RequestSnapshotStatusReport(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot) error {
+	err := helpers.ApplyMetadata(ctx, adapterClient, snapshot, nil, map[string]string{SnapshotStatusReportRequestedAnnotation: "true"})
 	if err != nil {
-		return fmt.Errorf("failed to add label %s: %w", SnapshotIntegrationTestRun, err)
+		return fmt.Errorf("failed to patch snapshot: %w", err)
 	}
-	err = adapterClient.Patch(ctx, snapshot, patch)
+
+	return nil
+}
+
+// GetLatestPassedSnapshot returns the most recently created Snapshot of the list which passed its integration tests
+// and wasn't created for a pull request, or nil if there isn't any.
+func GetLatestPassedSnapshot(snapshots *[]applicationapiv1alpha1.Snapshot) *applicationapiv1alpha1.Snapshot {
+	var latestSnapshot *applicationapiv1alpha1.Snapshot
+	for i := range *snapshots {
+		snapshot := &(*snapshots)[i]
+		if !IsSnapshotMarkedAsPassed(snapshot) || IsSnapshotMarkedAsInvalid(snapshot) || !IsSnapshotCreatedByPACPushEvent(snapshot) {
+			continue
+		}
+		if latestSnapshot == nil || latestSnapshot.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			latestSnapshot = snapshot
+		}
+	}
+
+	return latestSnapshot
+}
+
+// GetLatestReleasedSnapshot returns the most recently created Snapshot of the list which was auto-released and
+// isn't invalid, or nil if there isn't any.
+func GetLatestReleasedSnapshot(snapshots *[]applicationapiv1alpha1.Snapshot) *applicationapiv1alpha1.Snapshot {
+	var latestSnapshot *applicationapiv1alpha1.Snapshot
+	for i := range *snapshots {
+		snapshot := &(*snapshots)[i]
+		if !IsSnapshotMarkedAsAutoReleased(snapshot) || IsSnapshotMarkedAsInvalid(snapshot) {
+			continue
+		}
+		if latestSnapshot == nil || latestSnapshot.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			latestSnapshot = snapshot
+		}
+	}
+
+	return latestSnapshot
+}
+
+// GetSnapshotWithPushedImage returns the Snapshot of the list created for the given image pushed for the component,
+// or nil if there isn't any.
+func GetSnapshotWithPushedImage(snapshots *[]applicationapiv1alpha1.Snapshot, componentName, pushedImage string) *applicationapiv1alpha1.Snapshot {
+	for i := range *snapshots {
+		snapshot := &(*snapshots)[i]
+		if metadata.HasLabelWithValue(snapshot, SnapshotComponentLabel, componentName) &&
+			metadata.HasAnnotationWithValue(snapshot, PushedImageAnnotation, pushedImage) {
+			return snapshot
+		}
+	}
+
+	return nil
+}
+
+// AddIntegrationTestRerunLabel adding re-run label to snapshot, the label is server-side applied
+func AddIntegrationTestRerunLabel(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenarioName string) error {
+	err := helpers.ApplyMetadata(ctx, adapterClient, snapshot, map[string]string{SnapshotIntegrationTestRun: integrationTestScenarioName}, nil)
 	if err != nil {
 		return fmt.Errorf("failed to patch snapshot: %w", err)
 	}
@@ -801,22 +1130,17 @@ func GetLatestUpdateTime(snapshot *applicationapiv1alpha1.Snapshot) (time.Time,
 
 func ResetSnapshotStatusConditions(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, message string) error {
 	if HaveAppStudioTestsFinished(snapshot) {
-		patch := client.MergeFrom(snapshot.DeepCopy())
-		meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		return applySnapshotConditions(ctx, adapterClient, snapshot, metav1.Condition{
 			Type:    AppStudioIntegrationStatusCondition,
 			Status:  metav1.ConditionUnknown,
-			Reason:  AppStudioIntegrationStatusInProgress,
+			Reason:  reasons.AppStudioIntegrationStatusInProgress,
 			Message: message,
-		})
-		meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+		}, metav1.Condition{
 			Type:    AppStudioTestSucceededCondition,
 			Status:  metav1.ConditionUnknown,
-			Reason:  AppStudioIntegrationStatusInProgress,
+			Reason:  reasons.AppStudioIntegrationStatusInProgress,
 			Message: message,
 		})
-
-		err := adapterClient.Status().Patch(ctx, snapshot, patch)
-		return err
 	}
 
 	return nil
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+// GetSnapshotArchitectureDigests returns the map of the multi-arch components of the Snapshot to the digests of
+// their images per architecture, as recorded in the Snapshot annotation.
+func GetSnapshotArchitectureDigests(snapshot *applicationapiv1alpha1.Snapshot) (map[string]map[string]string, error) {
+	digests := map[string]map[string]string{}
+	value, ok := snapshot.GetAnnotations()[SnapshotArchitectureDigestsAnnotation]
+	if !ok || value == "" {
+		return digests, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &digests); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal architecture digests from snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return digests, nil
+}
+
+// SetSnapshotArchitectureDigests records the given digests per architecture of the multi-arch components in the
+// Snapshot annotation. The annotation isn't set when none of the components is multi-arch.
+func SetSnapshotArchitectureDigests(snapshot *applicationapiv1alpha1.Snapshot, digests map[string]map[string]string) error {
+	if len(digests) == 0 {
+		return nil
+	}
+
+	value, err := json.Marshal(digests)
+	if err != nil {
+		return fmt.Errorf("failed to marshal architecture digests into JSON: %w", err)
+	}
+
+	return metadata.SetAnnotation(&snapshot.ObjectMeta, SnapshotArchitectureDigestsAnnotation, string(value))
+}
+
+// GetComponentArchitectureDigests returns the map of the architectures to the digests of the images of the
+// multi-arch Component image, or nil if the Component image isn't multi-arch.
+func GetComponentArchitectureDigests(component *applicationapiv1alpha1.Component) (map[string]string, error) {
+	value, ok := component.GetAnnotations()[ComponentArchitectureDigestsAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	digests := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &digests); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal architecture digests from component %s: %w", component.Name, err)
+	}
+
+	return digests, nil
+}
+
+// SetComponentArchitectureDigests records the given digests per architecture of the Component image in the
+// Component annotation, the annotation is removed when the Component image isn't multi-arch.
+func SetComponentArchitectureDigests(component *applicationapiv1alpha1.Component, digests map[string]string) error {
+	if len(digests) == 0 {
+		return metadata.DeleteAnnotation(component, ComponentArchitectureDigestsAnnotation)
+	}
+
+	value, err := json.Marshal(digests)
+	if err != nil {
+		return fmt.Errorf("failed to marshal architecture digests into JSON: %w", err)
+	}
+
+	return metadata.SetAnnotation(&component.ObjectMeta, ComponentArchitectureDigestsAnnotation, string(value))
+}
+
+// GetComponentsArchitectureDigests returns the digests per architecture of the multi-arch application components
+// which are part of the Snapshot with the image of the global candidate list. Components whose digests can't be
+// read are considered as not multi-arch.
+func GetComponentsArchitectureDigests(applicationComponents *[]applicationapiv1alpha1.Component, snapshot *applicationapiv1alpha1.Snapshot) map[string]map[string]string {
+	digests := map[string]map[string]string{}
+	for i := range *applicationComponents {
+		component := &(*applicationComponents)[i]
+		for _, snapshotComponent := range snapshot.Spec.Components {
+			if snapshotComponent.Name != component.Name || snapshotComponent.ContainerImage != component.Spec.ContainerImage {
+				continue
+			}
+			componentDigests, err := GetComponentArchitectureDigests(component)
+			if err == nil && len(componentDigests) > 0 {
+				digests[component.Name] = componentDigests
+			}
+		}
+	}
+
+	return digests
+}
+
+// NewSnapshotForArchitecture returns a copy of the Snapshot in which the images of the multi-arch components are
+// replaced by their image for the given architecture. The images of the other components are kept as they are.
+func NewSnapshotForArchitecture(snapshot *applicationapiv1alpha1.Snapshot, architecture string) (*applicationapiv1alpha1.Snapshot, error) {
+	digests, err := GetSnapshotArchitectureDigests(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	architectureSnapshot := snapshot.DeepCopy()
+	for i, component := range architectureSnapshot.Spec.Components {
+		digest, ok := digests[component.Name][architecture]
+		if !ok {
+			continue
+		}
+		repository, _, _ := strings.Cut(component.ContainerImage, "@")
+		containerImage := repository + "@" + digest
+		if err := ValidateImageDigest(containerImage); err != nil {
+			return nil, fmt.Errorf("invalid %s image of component %s: %w", architecture, component.Name, err)
+		}
+		architectureSnapshot.Spec.Components[i].ContainerImage = containerImage
+	}
+
+	return architectureSnapshot, nil
+}
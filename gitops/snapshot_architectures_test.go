@@ -0,0 +1,121 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+)
+
+var _ = Describe("Snapshot architecture digests", func() {
+
+	const (
+		sampleImage = "quay.io/redhat-appstudio/sample-image"
+		indexDigest = "sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+		armDigest   = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+	var (
+		snapshot  *applicationapiv1alpha1.Snapshot
+		component *applicationapiv1alpha1.Component
+	)
+
+	BeforeEach(func() {
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-multi-arch",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "component-multi-arch", ContainerImage: sampleImage + "@" + indexDigest},
+					{Name: "component-single-arch", ContainerImage: sampleImage + "-single@" + indexDigest},
+				},
+			},
+		}
+		component = &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "component-multi-arch",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.ComponentSpec{
+				ComponentName:  "component-multi-arch",
+				Application:    "application-sample",
+				ContainerImage: sampleImage + "@" + indexDigest,
+			},
+		}
+	})
+
+	It("records the architecture digests of the multi-arch components only", func() {
+		Expect(gitops.SetSnapshotArchitectureDigests(snapshot, map[string]map[string]string{})).To(Succeed())
+		Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotArchitectureDigestsAnnotation))
+
+		digests := map[string]map[string]string{"component-multi-arch": {"arm64": armDigest}}
+		Expect(gitops.SetSnapshotArchitectureDigests(snapshot, digests)).To(Succeed())
+		Expect(gitops.GetSnapshotArchitectureDigests(snapshot)).To(Equal(digests))
+
+		Expect(metadata.SetAnnotation(&snapshot.ObjectMeta, gitops.SnapshotArchitectureDigestsAnnotation, "{invalid")).To(Succeed())
+		_, err := gitops.GetSnapshotArchitectureDigests(snapshot)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("carries the architecture digests of the global candidate images over to the snapshot", func() {
+		Expect(gitops.SetComponentArchitectureDigests(component, map[string]string{"arm64": armDigest})).To(Succeed())
+		Expect(gitops.GetComponentArchitectureDigests(component)).To(Equal(map[string]string{"arm64": armDigest}))
+
+		components := []applicationapiv1alpha1.Component{*component}
+		Expect(gitops.GetComponentsArchitectureDigests(&components, snapshot)).To(Equal(map[string]map[string]string{
+			"component-multi-arch": {"arm64": armDigest},
+		}))
+
+		// the digests of another image of the component aren't carried over
+		snapshot.Spec.Components[0].ContainerImage = sampleImage + "@" + armDigest
+		Expect(gitops.GetComponentsArchitectureDigests(&components, snapshot)).To(BeEmpty())
+
+		Expect(gitops.SetComponentArchitectureDigests(component, nil)).To(Succeed())
+		Expect(component.GetAnnotations()).NotTo(HaveKey(gitops.ComponentArchitectureDigestsAnnotation))
+		Expect(gitops.GetComponentArchitectureDigests(component)).To(BeNil())
+	})
+
+	It("replaces the images of the multi-arch components by their image for the architecture", func() {
+		Expect(gitops.SetSnapshotArchitectureDigests(snapshot, map[string]map[string]string{
+			"component-multi-arch": {"arm64": armDigest},
+		})).To(Succeed())
+
+		armSnapshot, err := gitops.NewSnapshotForArchitecture(snapshot, "arm64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(armSnapshot.Spec.Components[0].ContainerImage).To(Equal(sampleImage + "@" + armDigest))
+		Expect(armSnapshot.Spec.Components[1].ContainerImage).To(Equal(sampleImage + "-single@" + indexDigest))
+		Expect(snapshot.Spec.Components[0].ContainerImage).To(Equal(sampleImage + "@" + indexDigest))
+
+		amdSnapshot, err := gitops.NewSnapshotForArchitecture(snapshot, "amd64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(amdSnapshot.Spec.Components).To(Equal(snapshot.Spec.Components))
+
+		Expect(gitops.SetSnapshotArchitectureDigests(snapshot, map[string]map[string]string{
+			"component-multi-arch": {"arm64": "invalid"},
+		})).To(Succeed())
+		_, err = gitops.NewSnapshotForArchitecture(snapshot, "arm64")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	h "github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// ApplicationContext is the context of the IntegrationTestScenarios which apply to all Snapshots of the application.
+	ApplicationContext = "application"
+
+	// AllContext is an alias of the application context.
+	AllContext = "all"
+
+	// ComponentContext is the context of the IntegrationTestScenarios which apply to the Snapshots created for
+	// the builds of any component.
+	ComponentContext = "component"
+
+	// ComponentContextPrefix prefixes the contexts of the IntegrationTestScenarios which apply to the Snapshots
+	// created for the builds of a single component, e.g. "component_frontend".
+	ComponentContextPrefix = "component_"
+
+	// PushContext is the context of the IntegrationTestScenarios which apply to the Snapshots created for push events.
+	PushContext = "push"
+
+	// PullRequestContext is the context of the IntegrationTestScenarios which apply to the Snapshots created for
+	// pull or merge request events.
+	PullRequestContext = "pull_request"
+
+	// OverrideContext is the context of the IntegrationTestScenarios which apply to the override Snapshots.
+	OverrideContext = "override"
+)
+
+// IsSnapshotCreatedByPACPullRequestEvent checks if a snapshot has label PipelineAsCodeEventTypeLabel with
+// a pull request or merge request value
+func IsSnapshotCreatedByPACPullRequestEvent(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return !IsSnapshotCreatedByPACPushEvent(snapshot)
+}
+
+// IsContextValidForSnapshot returns true if the given IntegrationTestScenario context applies to the Snapshot.
+func IsContextValidForSnapshot(scenarioContextName string, snapshot *applicationapiv1alpha1.Snapshot) bool {
+	switch {
+	case scenarioContextName == ApplicationContext || scenarioContextName == AllContext:
+		return true
+	case scenarioContextName == ComponentContext:
+		return IsComponentSnapshot(snapshot)
+	case strings.HasPrefix(scenarioContextName, ComponentContextPrefix):
+		return IsComponentSnapshot(snapshot) &&
+			snapshot.Labels[SnapshotComponentLabel] == strings.TrimPrefix(scenarioContextName, ComponentContextPrefix)
+	case scenarioContextName == PushContext:
+		return IsSnapshotCreatedByPACPushEvent(snapshot)
+	case scenarioContextName == PullRequestContext:
+		return IsSnapshotCreatedByPACPullRequestEvent(snapshot)
+	case scenarioContextName == OverrideContext:
+		return IsOverrideSnapshot(snapshot)
+	}
+
+	return false
+}
+
+// IsScenarioApplicableToSnapshotsContext returns true if the IntegrationTestScenario applies to the Snapshot,
+// i.e. if any of its contexts applies to it. Contexts which aren't known to the integration service don't restrict
+// the Snapshots a scenario applies to, so the scenarios without any known context apply to all Snapshots.
+func IsScenarioApplicableToSnapshotsContext(scenario *v1beta2.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot) bool {
+	hasKnownContext := false
+	for _, scenarioContext := range scenario.Spec.Contexts {
+		if IsContextValidForSnapshot(scenarioContext.Name, snapshot) {
+			return true
+		}
+		hasKnownContext = hasKnownContext || isKnownContext(scenarioContext.Name)
+	}
+
+	return !hasKnownContext
+}
+
+// isKnownContext returns true if the IntegrationTestScenario context is one of the contexts known to
+// the integration service.
+func isKnownContext(scenarioContextName string) bool {
+	switch scenarioContextName {
+	case ApplicationContext, AllContext, ComponentContext, PushContext, PullRequestContext, OverrideContext:
+		return true
+	}
+
+	return strings.HasPrefix(scenarioContextName, ComponentContextPrefix)
+}
+
+// FilterIntegrationTestScenariosWithContext returns the IntegrationTestScenarios applicable to the Snapshot,
+// so that e.g. long running tests can be run only for push events while quicker ones are run for pull requests.
+func FilterIntegrationTestScenariosWithContext(scenarios *[]v1beta2.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot) *[]v1beta2.IntegrationTestScenario {
+	if scenarios == nil {
+		return nil
+	}
+
+	filteredScenarios := []v1beta2.IntegrationTestScenario{}
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		if IsScenarioApplicableToSnapshotsContext(&scenario, snapshot) {
+			filteredScenarios = append(filteredScenarios, scenario)
+		}
+	}
+
+	return &filteredScenarios
+}
+
+// IsScenarioApplicableToSnapshotComponent returns true if the ComponentSelector of the IntegrationTestScenario selects
+// the component the Snapshot was created for, by its name or by the labels of the given Component. The scenarios
+// without a ComponentSelector and the Snapshots which weren't created for the build of a component aren't restricted.
+// The component labels aren't matched when the Component isn't given, e.g. because it was already deleted.
+func IsScenarioApplicableToSnapshotComponent(scenario *v1beta2.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot, component *applicationapiv1alpha1.Component) bool {
+	selector := scenario.Spec.ComponentSelector
+	if selector == nil || (len(selector.Names) == 0 && selector.LabelSelector == nil) || !IsComponentSnapshot(snapshot) {
+		return true
+	}
+
+	if slices.Contains(selector.Names, snapshot.Labels[SnapshotComponentLabel]) {
+		return true
+	}
+	if selector.LabelSelector == nil || component == nil {
+		return false
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector.LabelSelector)
+	if err != nil {
+		return false
+	}
+
+	return labelSelector.Matches(labels.Set(component.Labels))
+}
+
+// HasComponentLabelSelector returns true if any of the IntegrationTestScenarios selects components by their labels,
+// so that the Component of the Snapshot has to be loaded to filter them.
+func HasComponentLabelSelector(scenarios *[]v1beta2.IntegrationTestScenario) bool {
+	if scenarios == nil {
+		return false
+	}
+
+	for _, scenario := range *scenarios {
+		if scenario.Spec.ComponentSelector != nil && scenario.Spec.ComponentSelector.LabelSelector != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterIntegrationTestScenariosWithComponentSelector returns the IntegrationTestScenarios whose ComponentSelector
+// selects the component the Snapshot was created for, so that the scenarios testing only some components aren't run
+// nor required for the Snapshots of the other components.
+func FilterIntegrationTestScenariosWithComponentSelector(scenarios *[]v1beta2.IntegrationTestScenario, snapshot *applicationapiv1alpha1.Snapshot, component *applicationapiv1alpha1.Component) *[]v1beta2.IntegrationTestScenario {
+	if scenarios == nil {
+		return nil
+	}
+
+	filteredScenarios := []v1beta2.IntegrationTestScenario{}
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		if IsScenarioApplicableToSnapshotComponent(&scenario, snapshot, component) {
+			filteredScenarios = append(filteredScenarios, scenario)
+		}
+	}
+
+	return &filteredScenarios
+}
+
+// FilterActiveIntegrationTestScenarios returns the IntegrationTestScenarios of the list which aren't suspended.
+func FilterActiveIntegrationTestScenarios(scenarios *[]v1beta2.IntegrationTestScenario) *[]v1beta2.IntegrationTestScenario {
+	if scenarios == nil {
+		return nil
+	}
+
+	filteredScenarios := []v1beta2.IntegrationTestScenario{}
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		if !h.IsScenarioSuspended(&scenario) {
+			filteredScenarios = append(filteredScenarios, scenario)
+		}
+	}
+
+	return &filteredScenarios
+}
+
+// FilterRequiredIntegrationTestScenarios returns the IntegrationTestScenarios of the list which aren't optional,
+// according to the given IntegrationPolicy of their application when there is one.
+func FilterRequiredIntegrationTestScenarios(policy *v1beta2.IntegrationPolicy, scenarios *[]v1beta2.IntegrationTestScenario) *[]v1beta2.IntegrationTestScenario {
+	if scenarios == nil {
+		return nil
+	}
+
+	filteredScenarios := []v1beta2.IntegrationTestScenario{}
+	for _, scenario := range *scenarios {
+		scenario := scenario //G601
+		if IsScenarioRequired(policy, &scenario) {
+			filteredScenarios = append(filteredScenarios, scenario)
+		}
+	}
+
+	return &filteredScenarios
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+var _ = Describe("Snapshot contexts", func() {
+
+	var (
+		pushSnapshot        *applicationapiv1alpha1.Snapshot
+		pullRequestSnapshot *applicationapiv1alpha1.Snapshot
+		overrideSnapshot    *applicationapiv1alpha1.Snapshot
+	)
+
+	// newScenario returns an IntegrationTestScenario applying to the given contexts
+	newScenario := func(name string, contexts ...string) v1beta2.IntegrationTestScenario {
+		scenario := v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+		for _, context := range contexts {
+			scenario.Spec.Contexts = append(scenario.Spec.Contexts, v1beta2.TestContext{Name: context})
+		}
+		return scenario
+	}
+
+	BeforeEach(func() {
+		pushSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-push",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:            gitops.SnapshotComponentType,
+					gitops.SnapshotComponentLabel:       "frontend",
+					gitops.PipelineAsCodeEventTypeLabel: gitops.PipelineAsCodePushType,
+				},
+			},
+		}
+		pullRequestSnapshot = pushSnapshot.DeepCopy()
+		pullRequestSnapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodePullRequestType
+		overrideSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-override",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel: gitops.SnapshotOverrideType,
+				},
+			},
+		}
+	})
+
+	It("applies the scenarios without contexts to all snapshots", func() {
+		scenario := newScenario("scenario-all")
+		Expect(gitops.IsScenarioApplicableToSnapshotsContext(&scenario, pushSnapshot)).To(BeTrue())
+		Expect(gitops.IsScenarioApplicableToSnapshotsContext(&scenario, pullRequestSnapshot)).To(BeTrue())
+		Expect(gitops.IsScenarioApplicableToSnapshotsContext(&scenario, overrideSnapshot)).To(BeTrue())
+
+		scenario = newScenario("scenario-unknown-context", "test-ctx")
+		Expect(gitops.IsScenarioApplicableToSnapshotsContext(&scenario, pullRequestSnapshot)).To(BeTrue())
+		scenario = newScenario("scenario-push-context", "test-ctx", gitops.PushContext)
+		Expect(gitops.IsScenarioApplicableToSnapshotsContext(&scenario, pullRequestSnapshot)).To(BeFalse())
+	})
+
+	It("applies the scenarios to the snapshots of the event types of their contexts", func() {
+		Expect(gitops.IsContextValidForSnapshot(gitops.PushContext, pushSnapshot)).To(BeTrue())
+		Expect(gitops.IsContextValidForSnapshot(gitops.PushContext, pullRequestSnapshot)).To(BeFalse())
+		Expect(gitops.IsContextValidForSnapshot(gitops.PullRequestContext, pullRequestSnapshot)).To(BeTrue())
+		Expect(gitops.IsContextValidForSnapshot(gitops.PullRequestContext, pushSnapshot)).To(BeFalse())
+
+		pullRequestSnapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodeMergeRequestType
+		Expect(gitops.IsContextValidForSnapshot(gitops.PullRequestContext, pullRequestSnapshot)).To(BeTrue())
+	})
+
+	It("applies the scenarios to the snapshots of the components and types of their contexts", func() {
+		Expect(gitops.IsContextValidForSnapshot(gitops.ApplicationContext, overrideSnapshot)).To(BeTrue())
+		Expect(gitops.IsContextValidForSnapshot(gitops.ComponentContext, pushSnapshot)).To(BeTrue())
+		Expect(gitops.IsContextValidForSnapshot(gitops.ComponentContext, overrideSnapshot)).To(BeFalse())
+		Expect(gitops.IsContextValidForSnapshot("component_frontend", pushSnapshot)).To(BeTrue())
+		Expect(gitops.IsContextValidForSnapshot("component_backend", pushSnapshot)).To(BeFalse())
+		Expect(gitops.IsContextValidForSnapshot(gitops.OverrideContext, overrideSnapshot)).To(BeTrue())
+		Expect(gitops.IsContextValidForSnapshot(gitops.OverrideContext, pushSnapshot)).To(BeFalse())
+		Expect(gitops.IsContextValidForSnapshot("unknown", pushSnapshot)).To(BeFalse())
+	})
+
+	It("filters the scenarios applicable to the snapshot", func() {
+		scenarios := []v1beta2.IntegrationTestScenario{
+			newScenario("smoke", gitops.PullRequestContext, gitops.PushContext),
+			newScenario("soak", gitops.PushContext),
+			newScenario("lint", gitops.PullRequestContext),
+			newScenario("e2e"),
+		}
+
+		names := func(scenarios *[]v1beta2.IntegrationTestScenario) []string {
+			result := []string{}
+			for _, scenario := range *scenarios {
+				result = append(result, scenario.Name)
+			}
+			return result
+		}
+		Expect(names(gitops.FilterIntegrationTestScenariosWithContext(&scenarios, pushSnapshot))).To(Equal([]string{"smoke", "soak", "e2e"}))
+		Expect(names(gitops.FilterIntegrationTestScenariosWithContext(&scenarios, pullRequestSnapshot))).To(Equal([]string{"smoke", "lint", "e2e"}))
+		Expect(gitops.FilterIntegrationTestScenariosWithContext(nil, pushSnapshot)).To(BeNil())
+	})
+
+	It("applies the scenarios to the snapshots of the components they select", func() {
+		component := &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "frontend",
+				Namespace: "default",
+				Labels:    map[string]string{"team": "ui"},
+			},
+		}
+
+		scenario := newScenario("scenario-all")
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, component)).To(BeTrue())
+		scenario.Spec.ComponentSelector = &v1beta2.ComponentSelector{}
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, component)).To(BeTrue())
+
+		scenario.Spec.ComponentSelector = &v1beta2.ComponentSelector{Names: []string{"backend"}}
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, component)).To(BeFalse())
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, overrideSnapshot, nil)).To(BeTrue())
+		scenario.Spec.ComponentSelector.Names = append(scenario.Spec.ComponentSelector.Names, "frontend")
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, nil)).To(BeTrue())
+
+		scenario.Spec.ComponentSelector = &v1beta2.ComponentSelector{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ui"}},
+		}
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, component)).To(BeTrue())
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, nil)).To(BeFalse())
+		component.Labels["team"] = "backend"
+		Expect(gitops.IsScenarioApplicableToSnapshotComponent(&scenario, pushSnapshot, component)).To(BeFalse())
+	})
+
+	It("filters the scenarios selecting the component of the snapshot", func() {
+		byName := newScenario("by-name")
+		byName.Spec.ComponentSelector = &v1beta2.ComponentSelector{Names: []string{"frontend"}}
+		other := newScenario("other")
+		other.Spec.ComponentSelector = &v1beta2.ComponentSelector{Names: []string{"backend"}}
+		scenarios := []v1beta2.IntegrationTestScenario{newScenario("all"), byName, other}
+
+		filtered := gitops.FilterIntegrationTestScenariosWithComponentSelector(&scenarios, pushSnapshot, nil)
+		Expect(*filtered).To(HaveLen(2))
+		Expect((*filtered)[0].Name).To(Equal("all"))
+		Expect((*filtered)[1].Name).To(Equal("by-name"))
+		Expect(*gitops.FilterIntegrationTestScenariosWithComponentSelector(&scenarios, overrideSnapshot, nil)).To(HaveLen(3))
+		Expect(gitops.FilterIntegrationTestScenariosWithComponentSelector(nil, pushSnapshot, nil)).To(BeNil())
+
+		Expect(gitops.HasComponentLabelSelector(&scenarios)).To(BeFalse())
+		other.Spec.ComponentSelector.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ui"}}
+		scenarios = append(scenarios, other)
+		Expect(gitops.HasComponentLabelSelector(&scenarios)).To(BeTrue())
+		Expect(gitops.HasComponentLabelSelector(nil)).To(BeFalse())
+	})
+
+	It("filters the required scenarios", func() {
+		optional := newScenario("optional")
+		optional.Labels = map[string]string{helpers.OptionalScenarioLabel: "true"}
+		scenarios := []v1beta2.IntegrationTestScenario{newScenario("required"), optional}
+
+		filtered := gitops.FilterRequiredIntegrationTestScenarios(nil, &scenarios)
+		Expect(*filtered).To(HaveLen(1))
+		Expect((*filtered)[0].Name).To(Equal("required"))
+		Expect(gitops.FilterRequiredIntegrationTestScenarios(nil, nil)).To(BeNil())
+	})
+
+	It("filters the suspended scenarios out", func() {
+		suspended := newScenario("suspended")
+		suspended.Spec.Suspended = true
+		scenarios := []v1beta2.IntegrationTestScenario{newScenario("active"), suspended}
+
+		filtered := gitops.FilterActiveIntegrationTestScenarios(&scenarios)
+		Expect(*filtered).To(HaveLen(1))
+		Expect((*filtered)[0].Name).To(Equal("active"))
+		Expect(*gitops.FilterRequiredIntegrationTestScenarios(nil, &scenarios)).To(HaveLen(1))
+		Expect(gitops.FilterActiveIntegrationTestScenarios(nil)).To(BeNil())
+	})
+})
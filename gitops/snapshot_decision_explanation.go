@@ -0,0 +1,173 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxExplainedFailedTestCases is the maximum number of failed test cases listed for each scenario in the decision
+// explanation, the annotation is kept compact and the full details are in the Snapshot test status.
+const MaxExplainedFailedTestCases = 5
+
+// DecisionExplanation is a compact explanation of the latest gating verdict reached for a Snapshot.
+type DecisionExplanation struct {
+	// Verdict is the outcome of the gating decision
+	Verdict string `json:"verdict"`
+	// Reason is a human readable summary of the verdict
+	Reason string `json:"reason,omitempty"`
+	// Scenarios are the IntegrationTestScenarios considered for the verdict, the required ones first
+	Scenarios []ExplainedScenario `json:"scenarios"`
+	// Quorums are the rationales of the quorums of scenarios of the IntegrationPolicy
+	Quorums []string `json:"quorums,omitempty"`
+}
+
+// ExplainedScenario is an IntegrationTestScenario considered for a gating verdict, with its matched PipelineRun
+// and the results seen for it.
+type ExplainedScenario struct {
+	// Name is the name of the IntegrationTestScenario
+	Name string `json:"name"`
+	// Gating is set when the result of the scenario was counted for the verdict
+	Gating bool `json:"gating"`
+	// PipelineRun is the name of the integration PipelineRun matched to the scenario
+	PipelineRun string `json:"pipelineRun,omitempty"`
+	// Status is the integration test status of the scenario when the verdict was reached
+	Status string `json:"status"`
+	// Details are the details of the integration test status
+	Details string `json:"details,omitempty"`
+	// Results are the test case counts reported by the integration PipelineRun
+	Results *ExplainedResults `json:"results,omitempty"`
+}
+
+// ExplainedResults are the test case counts reported by an integration PipelineRun.
+type ExplainedResults struct {
+	Successes int `json:"successes"`
+	Failures  int `json:"failures"`
+	Warnings  int `json:"warnings"`
+	// FailedTestCases are the names of the first failed test cases
+	FailedTestCases []string `json:"failedTestCases,omitempty"`
+}
+
+// NewDecisionExplanation creates the explanation of the given gating decision. The required scenarios of the decision
+// are listed first, followed by the other scenarios found in the test statuses, which didn't gate the Snapshot.
+func NewDecisionExplanation(decision GatingDecision, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) DecisionExplanation {
+	explanation := DecisionExplanation{
+		Verdict:   decision.Verdict,
+		Reason:    decision.Message,
+		Scenarios: []ExplainedScenario{},
+	}
+	for _, quorum := range decision.Quorums {
+		explanation.Quorums = append(explanation.Quorums, quorum.String())
+	}
+
+	required := map[string]bool{}
+	for _, scenarioName := range decision.RequiredScenarios {
+		required[scenarioName] = true
+		explained := ExplainedScenario{
+			Name:   scenarioName,
+			Gating: true,
+			Status: intgteststat.IntegrationTestStatusPending.String(),
+		}
+		if testStatuses != nil {
+			if testDetails, ok := testStatuses.GetScenarioStatus(scenarioName); ok {
+				explained = newExplainedScenario(testDetails)
+				// the observe-only runs started before the scenario gated the Snapshots aren't counted
+				explained.Gating = !testDetails.ObserveOnly
+			}
+		}
+		explanation.Scenarios = append(explanation.Scenarios, explained)
+	}
+	if testStatuses == nil {
+		return explanation
+	}
+
+	others := []ExplainedScenario{}
+	for _, testDetails := range testStatuses.GetStatuses() {
+		if !required[testDetails.ScenarioName] {
+			others = append(others, newExplainedScenario(testDetails))
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	explanation.Scenarios = append(explanation.Scenarios, others...)
+
+	return explanation
+}
+
+// newExplainedScenario creates the non-gating ExplainedScenario of the given integration test status.
+func newExplainedScenario(testDetails *intgteststat.IntegrationTestStatusDetail) ExplainedScenario {
+	explained := ExplainedScenario{
+		Name:        testDetails.ScenarioName,
+		PipelineRun: testDetails.TestPipelineRunName,
+		Status:      testDetails.Status.String(),
+		Details:     testDetails.Details,
+	}
+	if summary := testDetails.TestResultsSummary; summary != nil {
+		explained.Results = &ExplainedResults{
+			Successes: summary.Successes,
+			Failures:  summary.Failures,
+			Warnings:  summary.Warnings,
+		}
+		for i, testCase := range summary.FailedTestCases {
+			if i == MaxExplainedFailedTestCases {
+				break
+			}
+			explained.Results.FailedTestCases = append(explained.Results.FailedTestCases, testCase.Name)
+		}
+	}
+
+	return explained
+}
+
+// GetDecisionExplanationFromSnapshot returns the explanation of the latest gating verdict of the Snapshot,
+// or nil if no verdict was reached for it yet.
+func GetDecisionExplanationFromSnapshot(snapshot *applicationapiv1alpha1.Snapshot) (*DecisionExplanation, error) {
+	value, ok := snapshot.GetAnnotations()[SnapshotDecisionExplanationAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	explanation := &DecisionExplanation{}
+	if err := json.Unmarshal([]byte(value), explanation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decision explanation from snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return explanation, nil
+}
+
+// WriteDecisionExplanation writes the given explanation into the Snapshot annotation, replacing the explanation
+// of the previous verdict if there is one.
+func WriteDecisionExplanation(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, explanation DecisionExplanation) error {
+	value, err := json.Marshal(explanation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal decision explanation into JSON: %w", err)
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if err := metadata.SetAnnotation(&snapshot.ObjectMeta, SnapshotDecisionExplanationAnnotation, string(value)); err != nil {
+		return fmt.Errorf("failed to add annotations: %w", err)
+	}
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+)
+
+var _ = Describe("Snapshot decision explanation", func() {
+
+	const (
+		namespace        = "default"
+		applicationName  = "application-sample"
+		snapshotName     = "snapshot-explanation-sample"
+		testScenarioName = "test-scenario"
+		pipelineRunName  = "pipeline-run-abcdf"
+	)
+	var (
+		snapshot *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      snapshotName,
+				Namespace: namespace,
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: applicationName,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+	})
+
+	It("returns no explanation when the annotation is missing", func() {
+		explanation, err := gitops.GetDecisionExplanationFromSnapshot(snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(explanation).To(BeNil())
+	})
+
+	It("returns an error when the annotation is not valid JSON", func() {
+		Expect(metadata.SetAnnotation(&snapshot.ObjectMeta, gitops.SnapshotDecisionExplanationAnnotation, "{invalid")).To(Succeed())
+		_, err := gitops.GetDecisionExplanationFromSnapshot(snapshot)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("explains the decision with the scenarios considered, their PipelineRuns and their results", func() {
+		sits, err := intgteststat.NewSnapshotIntegrationTestStatuses("")
+		Expect(err).ToNot(HaveOccurred())
+		sits.UpdateTestStatusIfChanged(testScenarioName, intgteststat.IntegrationTestStatusTestFail, "Integration test failed")
+		Expect(sits.UpdateTestPipelineRunName(testScenarioName, pipelineRunName)).To(Succeed())
+		failedTestCases := []intgteststat.TestCaseDetail{}
+		for i := 0; i < gitops.MaxExplainedFailedTestCases+2; i++ {
+			failedTestCases = append(failedTestCases, intgteststat.TestCaseDetail{Name: fmt.Sprintf("test-%d", i), Status: "failed"})
+		}
+		Expect(sits.UpdateTestResultsSummary(testScenarioName, &intgteststat.TestResultsSummary{
+			Successes: 3, Failures: len(failedTestCases), FailedTestCases: failedTestCases,
+		})).To(Succeed())
+		sits.UpdateTestStatusIfChanged("observed-scenario", intgteststat.IntegrationTestStatusTestPassed, "passed")
+		Expect(sits.UpdateTestObserveOnly("observed-scenario", true)).To(Succeed())
+		sits.UpdateTestStatusIfChanged("optional-scenario", intgteststat.IntegrationTestStatusTestPassed, "passed")
+
+		decision := gitops.NewGatingDecision(gitops.GatingDecisionFailed,
+			[]string{testScenarioName, "observed-scenario", "missing-scenario"}, sits, "Some Integration pipeline tests failed")
+		decision.Quorums = []gitops.GatingDecisionQuorum{{Name: "e2e", MinPassed: 1, PassedScenarios: []string{"e2e-a"}}}
+
+		explanation := gitops.NewDecisionExplanation(decision, sits)
+		Expect(explanation.Verdict).To(Equal(gitops.GatingDecisionFailed))
+		Expect(explanation.Reason).To(Equal("Some Integration pipeline tests failed"))
+		Expect(explanation.Quorums).To(ConsistOf("quorum e2e: 1 of 1 scenarios passed, 1 required"))
+		Expect(explanation.Scenarios).To(HaveLen(4))
+
+		failed := explanation.Scenarios[0]
+		Expect(failed.Name).To(Equal(testScenarioName))
+		Expect(failed.Gating).To(BeTrue())
+		Expect(failed.PipelineRun).To(Equal(pipelineRunName))
+		Expect(failed.Status).To(Equal(intgteststat.IntegrationTestStatusTestFail.String()))
+		Expect(failed.Details).To(Equal("Integration test failed"))
+		Expect(failed.Results).NotTo(BeNil())
+		Expect(failed.Results.Successes).To(Equal(3))
+		Expect(failed.Results.Failures).To(Equal(gitops.MaxExplainedFailedTestCases + 2))
+		Expect(failed.Results.FailedTestCases).To(HaveLen(gitops.MaxExplainedFailedTestCases))
+		Expect(failed.Results.FailedTestCases[0]).To(Equal("test-0"))
+
+		Expect(explanation.Scenarios[1].Name).To(Equal("observed-scenario"))
+		Expect(explanation.Scenarios[1].Gating).To(BeFalse())
+		Expect(explanation.Scenarios[2]).To(Equal(gitops.ExplainedScenario{
+			Name:   "missing-scenario",
+			Gating: true,
+			Status: intgteststat.IntegrationTestStatusPending.String(),
+		}))
+		Expect(explanation.Scenarios[3].Name).To(Equal("optional-scenario"))
+		Expect(explanation.Scenarios[3].Gating).To(BeFalse())
+		Expect(explanation.Scenarios[3].Results).To(BeNil())
+	})
+
+	It("explains the decision without test statuses", func() {
+		decision := gitops.NewGatingDecision(gitops.GatingDecisionPassed, []string{}, nil, "No required IntegrationTestScenarios found, skipped testing")
+		explanation := gitops.NewDecisionExplanation(decision, nil)
+		Expect(explanation.Verdict).To(Equal(gitops.GatingDecisionPassed))
+		Expect(explanation.Scenarios).To(BeEmpty())
+	})
+
+	Context("when the snapshot exists in the cluster", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, snapshot)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, snapshot)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("replaces the explanation of the previous verdict", func() {
+			Expect(gitops.WriteDecisionExplanation(ctx, k8sClient, snapshot, gitops.NewDecisionExplanation(
+				gitops.NewGatingDecision(gitops.GatingDecisionFailed, []string{testScenarioName}, nil, "failed"), nil))).To(Succeed())
+			Expect(gitops.WriteDecisionExplanation(ctx, k8sClient, snapshot, gitops.NewDecisionExplanation(
+				gitops.NewGatingDecision(gitops.GatingDecisionPassed, []string{testScenarioName}, nil, "passed"), nil))).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				updatedSnapshot := &applicationapiv1alpha1.Snapshot{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: snapshotName, Namespace: namespace}, updatedSnapshot)).To(Succeed())
+				explanation, err := gitops.GetDecisionExplanationFromSnapshot(updatedSnapshot)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(explanation).NotTo(BeNil())
+				g.Expect(explanation.Verdict).To(Equal(gitops.GatingDecisionPassed))
+				g.Expect(explanation.Reason).To(Equal("passed"))
+			}).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SnapshotDiff describes the component images which changed in a Snapshot relative to the previous Snapshot
+// of its application.
+type SnapshotDiff struct {
+	// PreviousSnapshot is the name of the Snapshot the components were compared to, it is empty when the
+	// application had no previous Snapshot
+	PreviousSnapshot string `json:"previousSnapshot,omitempty"`
+	// Added are the components which weren't part of the previous Snapshot
+	Added []SnapshotDiffComponent `json:"added,omitempty"`
+	// Removed are the components of the previous Snapshot which aren't part of the Snapshot anymore
+	Removed []SnapshotDiffComponent `json:"removed,omitempty"`
+	// Updated are the components whose image differs from the one of the previous Snapshot
+	Updated []SnapshotDiffComponent `json:"updated,omitempty"`
+}
+
+// SnapshotDiffComponent is a component image which changed in a Snapshot.
+type SnapshotDiffComponent struct {
+	// Name of the component
+	Name string `json:"name"`
+	// ContainerImage is the image of the component in the Snapshot, it is empty for removed components
+	ContainerImage string `json:"containerImage,omitempty"`
+	// PreviousContainerImage is the image of the component in the previous Snapshot, it is empty for added components
+	PreviousContainerImage string `json:"previousContainerImage,omitempty"`
+}
+
+// NewSnapshotDiff compares the components of the Snapshot to the ones of the previous Snapshot and returns the
+// resulting SnapshotDiff. All the components are considered as added when there is no previous Snapshot.
+func NewSnapshotDiff(previousSnapshot, snapshot *applicationapiv1alpha1.Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{}
+	previousImages := map[string]string{}
+	if previousSnapshot != nil {
+		diff.PreviousSnapshot = previousSnapshot.Name
+		for _, component := range previousSnapshot.Spec.Components {
+			previousImages[component.Name] = component.ContainerImage
+		}
+	}
+
+	currentImages := map[string]string{}
+	for _, component := range snapshot.Spec.Components {
+		currentImages[component.Name] = component.ContainerImage
+		previousImage, ok := previousImages[component.Name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, SnapshotDiffComponent{
+				Name:           component.Name,
+				ContainerImage: component.ContainerImage,
+			})
+		case previousImage != component.ContainerImage:
+			diff.Updated = append(diff.Updated, SnapshotDiffComponent{
+				Name:                   component.Name,
+				ContainerImage:         component.ContainerImage,
+				PreviousContainerImage: previousImage,
+			})
+		}
+	}
+
+	if previousSnapshot != nil {
+		for _, component := range previousSnapshot.Spec.Components {
+			if _, ok := currentImages[component.Name]; !ok {
+				diff.Removed = append(diff.Removed, SnapshotDiffComponent{
+					Name:                   component.Name,
+					PreviousContainerImage: component.ContainerImage,
+				})
+			}
+		}
+	}
+
+	return diff
+}
+
+// GetPreviousSnapshot returns the most recently created Snapshot of the list which was created before the given
+// Snapshot and wasn't created for a pull request, or nil if there isn't any. Snapshots created for pull requests
+// are ignored as their components were never part of the application.
+func GetPreviousSnapshot(snapshots *[]applicationapiv1alpha1.Snapshot, snapshot *applicationapiv1alpha1.Snapshot) *applicationapiv1alpha1.Snapshot {
+	var previousSnapshot *applicationapiv1alpha1.Snapshot
+	for i := range *snapshots {
+		candidate := &(*snapshots)[i]
+		if candidate.Name == snapshot.Name || !IsSnapshotCreatedByPACPushEvent(candidate) ||
+			!candidate.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			continue
+		}
+		if previousSnapshot == nil || previousSnapshot.CreationTimestamp.Before(&candidate.CreationTimestamp) {
+			previousSnapshot = candidate
+		}
+	}
+
+	return previousSnapshot
+}
+
+// HasSnapshotDiff returns true if the diff of the Snapshot was already recorded.
+func HasSnapshotDiff(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return metadata.HasAnnotation(snapshot, SnapshotDiffAnnotation)
+}
+
+// GetSnapshotDiff returns the diff recorded in the Snapshot annotation or nil if it wasn't recorded.
+func GetSnapshotDiff(snapshot *applicationapiv1alpha1.Snapshot) (*SnapshotDiff, error) {
+	value, ok := snapshot.GetAnnotations()[SnapshotDiffAnnotation]
+	if !ok || value == "" {
+		return nil, nil
+	}
+
+	diff := &SnapshotDiff{}
+	if err := json.Unmarshal([]byte(value), diff); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal diff from snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return diff, nil
+}
+
+// RecordSnapshotDiff stores the given diff in the Snapshot annotation.
+func RecordSnapshotDiff(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, diff *SnapshotDiff) error {
+	value, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot diff into JSON: %w", err)
+	}
+
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	if err := metadata.SetAnnotation(&snapshot.ObjectMeta, SnapshotDiffAnnotation, string(value)); err != nil {
+		return fmt.Errorf("failed to add annotations: %w", err)
+	}
+
+	return adapterClient.Patch(ctx, snapshot, patch)
+}
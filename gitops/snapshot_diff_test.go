@@ -0,0 +1,152 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+)
+
+var _ = Describe("Snapshot diff", func() {
+
+	const (
+		namespace       = "default"
+		applicationName = "application-sample"
+		snapshotName    = "snapshot-diff-sample"
+	)
+	var (
+		previousSnapshot *applicationapiv1alpha1.Snapshot
+		snapshot         *applicationapiv1alpha1.Snapshot
+	)
+
+	newSnapshot := func(name string, created time.Time, components ...applicationapiv1alpha1.SnapshotComponent) *applicationapiv1alpha1.Snapshot {
+		return &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: applicationName,
+				Components:  components,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now := time.Now()
+		previousSnapshot = newSnapshot("snapshot-previous", now.Add(-time.Hour),
+			applicationapiv1alpha1.SnapshotComponent{Name: "component-unchanged", ContainerImage: "quay.io/sample/unchanged@sha256:aaa"},
+			applicationapiv1alpha1.SnapshotComponent{Name: "component-updated", ContainerImage: "quay.io/sample/updated@sha256:bbb"},
+			applicationapiv1alpha1.SnapshotComponent{Name: "component-removed", ContainerImage: "quay.io/sample/removed@sha256:ccc"},
+		)
+		snapshot = newSnapshot(snapshotName, now,
+			applicationapiv1alpha1.SnapshotComponent{Name: "component-unchanged", ContainerImage: "quay.io/sample/unchanged@sha256:aaa"},
+			applicationapiv1alpha1.SnapshotComponent{Name: "component-updated", ContainerImage: "quay.io/sample/updated@sha256:ddd"},
+			applicationapiv1alpha1.SnapshotComponent{Name: "component-added", ContainerImage: "quay.io/sample/added@sha256:eee"},
+		)
+	})
+
+	It("computes the added, removed and updated components", func() {
+		diff := gitops.NewSnapshotDiff(previousSnapshot, snapshot)
+		Expect(diff.PreviousSnapshot).To(Equal("snapshot-previous"))
+		Expect(diff.Added).To(ConsistOf(gitops.SnapshotDiffComponent{
+			Name:           "component-added",
+			ContainerImage: "quay.io/sample/added@sha256:eee",
+		}))
+		Expect(diff.Removed).To(ConsistOf(gitops.SnapshotDiffComponent{
+			Name:                   "component-removed",
+			PreviousContainerImage: "quay.io/sample/removed@sha256:ccc",
+		}))
+		Expect(diff.Updated).To(ConsistOf(gitops.SnapshotDiffComponent{
+			Name:                   "component-updated",
+			ContainerImage:         "quay.io/sample/updated@sha256:ddd",
+			PreviousContainerImage: "quay.io/sample/updated@sha256:bbb",
+		}))
+	})
+
+	It("considers all the components as added when there is no previous snapshot", func() {
+		diff := gitops.NewSnapshotDiff(nil, snapshot)
+		Expect(diff.PreviousSnapshot).To(BeEmpty())
+		Expect(diff.Added).To(HaveLen(3))
+		Expect(diff.Removed).To(BeEmpty())
+		Expect(diff.Updated).To(BeEmpty())
+	})
+
+	It("finds the latest snapshot created before the snapshot which doesn't belong to a pull request", func() {
+		olderSnapshot := newSnapshot("snapshot-older", snapshot.CreationTimestamp.Add(-2*time.Hour))
+		pullRequestSnapshot := newSnapshot("snapshot-pull-request", snapshot.CreationTimestamp.Add(-time.Minute))
+		pullRequestSnapshot.Labels = map[string]string{gitops.PipelineAsCodeEventTypeLabel: gitops.PipelineAsCodePullRequestType}
+		newerSnapshot := newSnapshot("snapshot-newer", snapshot.CreationTimestamp.Add(time.Minute))
+
+		snapshots := []applicationapiv1alpha1.Snapshot{*olderSnapshot, *previousSnapshot, *pullRequestSnapshot, *snapshot, *newerSnapshot}
+		Expect(gitops.GetPreviousSnapshot(&snapshots, snapshot).Name).To(Equal("snapshot-previous"))
+
+		snapshots = []applicationapiv1alpha1.Snapshot{*pullRequestSnapshot, *snapshot, *newerSnapshot}
+		Expect(gitops.GetPreviousSnapshot(&snapshots, snapshot)).To(BeNil())
+	})
+
+	It("returns no diff when the annotation is missing and an error when it is not valid JSON", func() {
+		Expect(gitops.HasSnapshotDiff(snapshot)).To(BeFalse())
+		diff, err := gitops.GetSnapshotDiff(snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff).To(BeNil())
+
+		Expect(metadata.SetAnnotation(&snapshot.ObjectMeta, gitops.SnapshotDiffAnnotation, "{invalid")).To(Succeed())
+		Expect(gitops.HasSnapshotDiff(snapshot)).To(BeTrue())
+		_, err = gitops.GetSnapshotDiff(snapshot)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("when the snapshot exists in the cluster", func() {
+		BeforeEach(func() {
+			snapshot.CreationTimestamp = metav1.Time{}
+			Expect(k8sClient.Create(ctx, snapshot)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, snapshot)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("records the diff in the annotation", func() {
+			Expect(gitops.RecordSnapshotDiff(ctx, k8sClient, snapshot, gitops.NewSnapshotDiff(previousSnapshot, snapshot))).To(Succeed())
+
+			Eventually(func() *gitops.SnapshotDiff {
+				updatedSnapshot := &applicationapiv1alpha1.Snapshot{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: snapshotName, Namespace: namespace}, updatedSnapshot); err != nil {
+					return nil
+				}
+				diff, err := gitops.GetSnapshotDiff(updatedSnapshot)
+				if err != nil {
+					return nil
+				}
+				return diff
+			}, time.Second*10).Should(Equal(gitops.NewSnapshotDiff(previousSnapshot, snapshot)))
+		})
+	})
+})
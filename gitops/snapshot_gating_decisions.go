@@ -0,0 +1,190 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions andF
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// GatingDecisionPassed is the verdict recorded when the Snapshot passed all required integration tests.
+	GatingDecisionPassed = "Passed"
+
+	// GatingDecisionFailed is the verdict recorded when the Snapshot failed some required integration tests.
+	GatingDecisionFailed = "Failed"
+
+	// GatingDecisionOverride is the verdict recorded when the components of an override Snapshot were promoted
+	// to the global candidate list without being gated by integration tests.
+	GatingDecisionOverride = "Override"
+
+	// MaxGatingDecisions is the maximum number of the latest gating decisions kept in the Snapshot annotation.
+	MaxGatingDecisions = 20
+)
+
+// GatingDecision is a record of a single gating decision made about a Snapshot.
+type GatingDecision struct {
+	// Verdict is the computed outcome of the decision
+	Verdict string `json:"verdict"`
+	// RequiredScenarios are the names of the IntegrationTestScenarios which were considered required
+	RequiredScenarios []string `json:"requiredScenarios"`
+	// PipelineRuns are the integration test results which were counted for the decision
+	PipelineRuns []GatingDecisionPipelineRun `json:"pipelineRuns,omitempty"`
+	// Quorums are the outcomes of the quorums of scenarios of the IntegrationPolicy counted for the decision
+	Quorums []GatingDecisionQuorum `json:"quorums,omitempty"`
+	// Actor identifies who created the override Snapshot, it is set only for override decisions
+	Actor string `json:"actor,omitempty"`
+	// Message is a human readable explanation of the decision
+	Message string `json:"message,omitempty"`
+	// Timestamp is the time when the decision was made
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// GatingDecisionPipelineRun is an integration test result counted for a gating decision.
+type GatingDecisionPipelineRun struct {
+	// ScenarioName is the name of the IntegrationTestScenario
+	ScenarioName string `json:"scenario"`
+	// PipelineRunName is the name of the integration PipelineRun which ran the test
+	PipelineRunName string `json:"pipelineRun,omitempty"`
+	// Status is the integration test status at the time of the decision
+	Status string `json:"status"`
+}
+
+// GatingDecisionQuorum is the outcome of a quorum of IntegrationTestScenarios counted for a gating decision.
+type GatingDecisionQuorum struct {
+	// Name is the name of the quorum in the IntegrationPolicy
+	Name string `json:"name"`
+	// MinPassed is the number of the scenarios of the quorum which had to pass
+	MinPassed int `json:"minPassed"`
+	// PassedScenarios are the names of the scenarios of the quorum which passed
+	PassedScenarios []string `json:"passedScenarios,omitempty"`
+	// FailedScenarios are the names of the scenarios of the quorum which didn't pass
+	FailedScenarios []string `json:"failedScenarios,omitempty"`
+	// PendingScenarios are the names of the scenarios of the quorum which didn't finish yet
+	PendingScenarios []string `json:"pendingScenarios,omitempty"`
+	// Verdict is the outcome of the quorum, it's empty while the quorum is undecided
+	Verdict string `json:"verdict,omitempty"`
+}
+
+// String returns the rationale of the outcome of the quorum.
+func (q GatingDecisionQuorum) String() string {
+	applicable := len(q.PassedScenarios) + len(q.FailedScenarios) + len(q.PendingScenarios)
+	return fmt.Sprintf("quorum %s: %d of %d scenarios passed, %d required", q.Name, len(q.PassedScenarios), applicable, q.MinPassed)
+}
+
+// NewGatingDecision creates a new GatingDecision for the given verdict and required scenarios, the integration test
+// results of the required scenarios are taken from the given test statuses.
+func NewGatingDecision(verdict string, requiredScenarios []string, testStatuses *intgteststat.SnapshotIntegrationTestStatuses, message string) GatingDecision {
+	decision := GatingDecision{
+		Verdict:           verdict,
+		RequiredScenarios: requiredScenarios,
+		Message:           message,
+		Timestamp:         time.Now().UTC(),
+	}
+	if testStatuses == nil {
+		return decision
+	}
+
+	for _, scenarioName := range requiredScenarios {
+		testDetails, ok := testStatuses.GetScenarioStatus(scenarioName)
+		if !ok {
+			continue
+		}
+		decision.PipelineRuns = append(decision.PipelineRuns, GatingDecisionPipelineRun{
+			ScenarioName:    scenarioName,
+			PipelineRunName: testDetails.TestPipelineRunName,
+			Status:          testDetails.Status.String(),
+		})
+	}
+
+	return decision
+}
+
+// GetGatingDecisionsFromSnapshot returns the gating decisions recorded in the Snapshot annotation.
+func GetGatingDecisionsFromSnapshot(snapshot *applicationapiv1alpha1.Snapshot) ([]GatingDecision, error) {
+	decisions := []GatingDecision{}
+	value, ok := snapshot.GetAnnotations()[SnapshotGatingDecisionsAnnotation]
+	if !ok || value == "" {
+		return decisions, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &decisions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal gating decisions from snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return decisions, nil
+}
+
+// RecordGatingDecision appends the given decision to the gating decisions of the Snapshot, unless the latest recorded
+// decision has the same verdict, so that the re-evaluations of the Snapshot don't grow the annotation. Only the latest
+// MaxGatingDecisions decisions are kept, the recorded decisions are otherwise never modified so that they can be used
+// as an audit trail. The Snapshot is patched with an optimistic lock and reloaded on conflicts, so that the decisions
+// recorded meanwhile aren't lost.
+func RecordGatingDecision(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, decision GatingDecision) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		decisions, err := GetGatingDecisionsFromSnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+		if len(decisions) > 0 && decisions[len(decisions)-1].Verdict == decision.Verdict {
+			return nil
+		}
+		decisions = append(decisions, decision)
+		if len(decisions) > MaxGatingDecisions {
+			decisions = decisions[len(decisions)-MaxGatingDecisions:]
+		}
+
+		value, err := json.Marshal(decisions)
+		if err != nil {
+			return fmt.Errorf("failed to marshal gating decisions into JSON: %w", err)
+		}
+
+		patch := client.MergeFromWithOptions(snapshot.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if err := metadata.SetAnnotation(&snapshot.ObjectMeta, SnapshotGatingDecisionsAnnotation, string(value)); err != nil {
+			return fmt.Errorf("failed to add annotations: %w", err)
+		}
+		err = adapterClient.Patch(ctx, snapshot, patch)
+		if errors.IsConflict(err) {
+			if getErr := adapterClient.Get(ctx, client.ObjectKeyFromObject(snapshot), snapshot); getErr != nil {
+				return getErr
+			}
+		}
+
+		return err
+	})
+}
+
+// GetSnapshotCreator returns the field manager of the first managed fields entry of the Snapshot, which
+// corresponds to the client that created it unless the managed fields were reset later on.
+func GetSnapshotCreator(snapshot *applicationapiv1alpha1.Snapshot) string {
+	for _, managedFields := range snapshot.GetManagedFields() {
+		if managedFields.Manager != "" {
+			return managedFields.Manager
+		}
+	}
+
+	return ""
+}
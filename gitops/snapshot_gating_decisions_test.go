@@ -0,0 +1,184 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions andF
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+)
+
+var _ = Describe("Snapshot gating decisions", func() {
+
+	const (
+		namespace        = "default"
+		applicationName  = "application-sample"
+		componentName    = "component-sample"
+		snapshotName     = "snapshot-gating-sample"
+		testScenarioName = "test-scenario"
+		pipelineRunName  = "pipeline-run-abcdf"
+	)
+	var (
+		snapshot *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      snapshotName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					gitops.SnapshotTypeLabel:      gitops.SnapshotComponentType,
+					gitops.SnapshotComponentLabel: componentName,
+				},
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: applicationName,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           componentName,
+						ContainerImage: "quay.io/redhat-appstudio/sample-image:latest",
+					},
+				},
+			},
+		}
+	})
+
+	It("returns no decisions when the annotation is missing", func() {
+		decisions, err := gitops.GetGatingDecisionsFromSnapshot(snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decisions).To(BeEmpty())
+	})
+
+	It("returns an error when the annotation is not valid JSON", func() {
+		Expect(metadata.SetAnnotation(&snapshot.ObjectMeta, gitops.SnapshotGatingDecisionsAnnotation, "{invalid")).To(Succeed())
+		_, err := gitops.GetGatingDecisionsFromSnapshot(snapshot)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("creates a decision including the results of the required scenarios", func() {
+		sits, err := intgteststat.NewSnapshotIntegrationTestStatuses("")
+		Expect(err).ToNot(HaveOccurred())
+		sits.UpdateTestStatusIfChanged(testScenarioName, intgteststat.IntegrationTestStatusTestPassed, "passed")
+		Expect(sits.UpdateTestPipelineRunName(testScenarioName, pipelineRunName)).To(Succeed())
+		sits.UpdateTestStatusIfChanged("optional-scenario", intgteststat.IntegrationTestStatusTestFail, "failed")
+
+		decision := gitops.NewGatingDecision(gitops.GatingDecisionPassed, []string{testScenarioName}, sits, "all passed")
+		Expect(decision.Verdict).To(Equal(gitops.GatingDecisionPassed))
+		Expect(decision.RequiredScenarios).To(ConsistOf(testScenarioName))
+		Expect(decision.Message).To(Equal("all passed"))
+		Expect(decision.Timestamp.IsZero()).To(BeFalse())
+		Expect(decision.PipelineRuns).To(ConsistOf(gitops.GatingDecisionPipelineRun{
+			ScenarioName:    testScenarioName,
+			PipelineRunName: pipelineRunName,
+			Status:          intgteststat.IntegrationTestStatusTestPassed.String(),
+		}))
+	})
+
+	It("returns the field manager which created the snapshot", func() {
+		Expect(gitops.GetSnapshotCreator(snapshot)).To(BeEmpty())
+		snapshot.SetManagedFields([]metav1.ManagedFieldsEntry{
+			{Manager: "kubectl-create", Operation: metav1.ManagedFieldsOperationUpdate},
+			{Manager: "integration-service", Operation: metav1.ManagedFieldsOperationUpdate},
+		})
+		Expect(gitops.GetSnapshotCreator(snapshot)).To(Equal("kubectl-create"))
+	})
+
+	Context("when the snapshot exists in the cluster", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, snapshot)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, snapshot)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("appends each recorded decision to the annotation", func() {
+			Expect(gitops.RecordGatingDecision(ctx, k8sClient, snapshot,
+				gitops.NewGatingDecision(gitops.GatingDecisionFailed, []string{testScenarioName}, nil, "failed"))).To(Succeed())
+			Expect(gitops.RecordGatingDecision(ctx, k8sClient, snapshot,
+				gitops.NewGatingDecision(gitops.GatingDecisionPassed, []string{testScenarioName}, nil, "passed"))).To(Succeed())
+
+			Eventually(func() []string {
+				updatedSnapshot := &applicationapiv1alpha1.Snapshot{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: snapshotName, Namespace: namespace}, updatedSnapshot); err != nil {
+					return nil
+				}
+				decisions, err := gitops.GetGatingDecisionsFromSnapshot(updatedSnapshot)
+				if err != nil {
+					return nil
+				}
+				verdicts := []string{}
+				for _, decision := range decisions {
+					verdicts = append(verdicts, decision.Verdict)
+				}
+				return verdicts
+			}, time.Second*10).Should(Equal([]string{gitops.GatingDecisionFailed, gitops.GatingDecisionPassed}))
+		})
+
+		It("doesn't append the decisions with the same verdict as the latest one", func() {
+			Expect(gitops.RecordGatingDecision(ctx, k8sClient, snapshot,
+				gitops.NewGatingDecision(gitops.GatingDecisionFailed, []string{testScenarioName}, nil, "failed"))).To(Succeed())
+			Expect(gitops.RecordGatingDecision(ctx, k8sClient, snapshot,
+				gitops.NewGatingDecision(gitops.GatingDecisionFailed, []string{testScenarioName}, nil, "failed again"))).To(Succeed())
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(snapshot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decisions).To(HaveLen(1))
+			Expect(decisions[0].Message).To(Equal("failed"))
+		})
+
+		It("keeps only the latest decisions", func() {
+			verdicts := []string{gitops.GatingDecisionFailed, gitops.GatingDecisionPassed}
+			for i := 0; i <= gitops.MaxGatingDecisions; i++ {
+				Expect(gitops.RecordGatingDecision(ctx, k8sClient, snapshot,
+					gitops.NewGatingDecision(verdicts[i%2], []string{testScenarioName}, nil, fmt.Sprintf("decision %d", i)))).To(Succeed())
+			}
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(snapshot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decisions).To(HaveLen(gitops.MaxGatingDecisions))
+			Expect(decisions[0].Message).To(Equal("decision 1"))
+		})
+
+		It("keeps the decisions recorded meanwhile through an outdated snapshot", func() {
+			outdatedSnapshot := snapshot.DeepCopy()
+			Expect(gitops.RecordGatingDecision(ctx, k8sClient, snapshot,
+				gitops.NewGatingDecision(gitops.GatingDecisionFailed, []string{testScenarioName}, nil, "failed"))).To(Succeed())
+			Expect(gitops.RecordGatingDecision(ctx, k8sClient, outdatedSnapshot,
+				gitops.NewGatingDecision(gitops.GatingDecisionPassed, []string{testScenarioName}, nil, "passed"))).To(Succeed())
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(outdatedSnapshot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decisions).To(HaveLen(2))
+			Expect(decisions[0].Verdict).To(Equal(gitops.GatingDecisionFailed))
+			Expect(decisions[1].Verdict).To(Equal(gitops.GatingDecisionPassed))
+		})
+	})
+})
@@ -21,8 +21,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/konflux-ci/integration-service/helpers"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
-	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -52,18 +52,13 @@ func WriteIntegrationTestStatusesIntoSnapshot(ctx context.Context, s *applicatio
 		// No updates were done, we don't need to update snapshot
 		return nil
 	}
-	patch := client.MergeFrom(s.DeepCopy())
-
 	value, err := json.Marshal(sts)
 	if err != nil {
 		return fmt.Errorf("failed to marshal test results into JSON: %w", err)
 	}
 
-	if err := metadata.SetAnnotation(&s.ObjectMeta, SnapshotTestsStatusAnnotation, string(value)); err != nil {
-		return fmt.Errorf("failed to add annotations: %w", err)
-	}
-
-	err = c.Patch(ctx, s, patch)
+	// the statuses were computed from this version of the snapshot, a conflict makes the callers compute them again
+	err = helpers.ApplyMetadataWithOptimisticLock(ctx, c, s, nil, map[string]string{SnapshotTestsStatusAnnotation: string(value)})
 	if err != nil {
 		// don't return wrapped err, so we can use RetryOnConflict
 		return err
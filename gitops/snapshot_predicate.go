@@ -17,6 +17,12 @@ limitations under the License.
 package gitops
 
 import (
+	"reflect"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	h "github.com/konflux-ci/integration-service/helpers"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -59,6 +65,25 @@ func SnapshotIntegrationTestRerunTriggerPredicate() predicate.Predicate {
 	}
 }
 
+// SnapshotPromotionRequestedPredicate returns a predicate which filters out all objects except
+// when the annotation requesting the promotion of the Snapshot to an Environment is added or changed.
+func SnapshotPromotionRequestedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return HasSnapshotPromoteToAnnotationChanged(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
 // SnapshotTestAnnotationChangePredicate returns a predicate which filters out all objects except
 // when Snapshot annotation "test.appstudio.openshift.io/status" is changed for update events.
 func SnapshotTestAnnotationChangePredicate() predicate.Predicate {
@@ -77,3 +102,121 @@ func SnapshotTestAnnotationChangePredicate() predicate.Predicate {
 		},
 	}
 }
+
+// SnapshotRunCreatedPredicate returns a predicate which filters out all SnapshotRun events except the creation of
+// the SnapshotRuns.
+func SnapshotRunCreatedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return false
+		},
+	}
+}
+
+// IntegrationTestScenarioChangePredicate returns a predicate which filters out all IntegrationTestScenario events
+// except the ones which can change the integration tests run for the Snapshots of their application, this is
+// the deletion of the scenarios, and the creation and the changes of the spec or labels of the valid scenarios.
+func IntegrationTestScenarioChangePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return isIntegrationTestScenarioValid(createEvent.Object)
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return HasIntegrationTestScenarioChanged(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// HasIntegrationTestScenarioChanged returns a boolean indicating whether the valid IntegrationTestScenario has
+// changed in a way affecting the integration tests run for the Snapshots, which is also the case when it has just
+// become valid. If the objects passed to this function are not IntegrationTestScenarios, the function will return false.
+func HasIntegrationTestScenarioChanged(objectOld, objectNew client.Object) bool {
+	if oldScenario, ok := objectOld.(*v1beta2.IntegrationTestScenario); ok {
+		if newScenario, ok := objectNew.(*v1beta2.IntegrationTestScenario); ok {
+			if !isIntegrationTestScenarioValid(newScenario) {
+				return false
+			}
+			return !isIntegrationTestScenarioValid(oldScenario) || oldScenario.Generation != newScenario.Generation ||
+				!reflect.DeepEqual(oldScenario.GetLabels(), newScenario.GetLabels())
+		}
+	}
+	return false
+}
+
+// IntegrationTestScenarioNoLongerRequiredPredicate returns a predicate which filters out all IntegrationTestScenario
+// events except the ones after which the scenarios may no longer be required for the Snapshots of their application,
+// this is the deletion of the scenarios, and the changes making the required scenarios optional or changing which
+// Snapshots they apply to.
+func IntegrationTestScenarioNoLongerRequiredPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return HasIntegrationTestScenarioStoppedBeingRequired(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
+
+// IntegrationPolicyChangePredicate returns a predicate which filters out all IntegrationPolicy events except their
+// creation, deletion and the changes of their spec.
+func IntegrationPolicyChangePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+	}
+}
+
+// HasIntegrationTestScenarioStoppedBeingRequired returns a boolean indicating whether the required
+// IntegrationTestScenario was made optional, or whether the Snapshots it applies to may have changed. If the objects
+// passed to this function are not IntegrationTestScenarios, the function will return false.
+func HasIntegrationTestScenarioStoppedBeingRequired(objectOld, objectNew client.Object) bool {
+	if oldScenario, ok := objectOld.(*v1beta2.IntegrationTestScenario); ok {
+		if newScenario, ok := objectNew.(*v1beta2.IntegrationTestScenario); ok {
+			if !IsScenarioRequired(nil, oldScenario) {
+				return false
+			}
+			return !IsScenarioRequired(nil, newScenario) ||
+				!reflect.DeepEqual(oldScenario.Spec.Contexts, newScenario.Spec.Contexts) ||
+				!reflect.DeepEqual(oldScenario.Spec.ComponentSelector, newScenario.Spec.ComponentSelector)
+		}
+	}
+	return false
+}
+
+// isIntegrationTestScenarioValid returns true if the object is an IntegrationTestScenario which was validated.
+func isIntegrationTestScenarioValid(object client.Object) bool {
+	scenario, ok := object.(*v1beta2.IntegrationTestScenario)
+	return ok && meta.IsStatusConditionTrue(scenario.Status.Conditions, h.IntegrationTestScenarioValid)
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package gitops_test
 
 import (
+	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -291,4 +293,118 @@ var _ = Describe("Predicates", Ordered, func() {
 		})
 
 	})
+
+	Context("when testing SnapshotRunCreatedPredicate predicate", func() {
+		instance := gitops.SnapshotRunCreatedPredicate()
+
+		It("returns true only for the creation of SnapshotRuns", func() {
+			snapshotRun := &v1beta2.SnapshotRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "snapshotrun-sample",
+					Namespace: namespace,
+				},
+				Spec: v1beta2.SnapshotRunSpec{
+					Snapshot: snapshotOldName,
+				},
+			}
+
+			Expect(instance.Create(event.CreateEvent{Object: snapshotRun})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: snapshotRun, ObjectNew: snapshotRun})).To(BeFalse())
+			Expect(instance.Delete(event.DeleteEvent{Object: snapshotRun})).To(BeFalse())
+			Expect(instance.Generic(event.GenericEvent{Object: snapshotRun})).To(BeFalse())
+		})
+	})
+
+	Context("when testing IntegrationTestScenarioChangePredicate predicate", func() {
+		instance := gitops.IntegrationTestScenarioChangePredicate()
+
+		It("returns true for the changes of the valid scenarios affecting the integration tests of the Snapshots", func() {
+			pendingScenario := &v1beta2.IntegrationTestScenario{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "scenario-sample",
+					Namespace:  namespace,
+					Generation: 1,
+				},
+				Spec: v1beta2.IntegrationTestScenarioSpec{
+					Application: applicationName,
+				},
+			}
+			validScenario := pendingScenario.DeepCopy()
+			helpers.SetScenarioIntegrationStatusAsValid(validScenario, "valid")
+
+			Expect(instance.Create(event.CreateEvent{Object: pendingScenario})).To(BeFalse())
+			Expect(instance.Create(event.CreateEvent{Object: validScenario})).To(BeTrue())
+			Expect(instance.Delete(event.DeleteEvent{Object: pendingScenario})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{Object: validScenario})).To(BeFalse())
+
+			// the scenario has just become valid
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: pendingScenario, ObjectNew: validScenario})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: validScenario, ObjectNew: validScenario})).To(BeFalse())
+
+			changedScenario := validScenario.DeepCopy()
+			changedScenario.Generation = 2
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: validScenario, ObjectNew: changedScenario})).To(BeTrue())
+			relabeledScenario := validScenario.DeepCopy()
+			relabeledScenario.Labels = map[string]string{helpers.OptionalScenarioLabel: "true"}
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: validScenario, ObjectNew: relabeledScenario})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: changedScenario, ObjectNew: pendingScenario})).To(BeFalse())
+		})
+	})
+
+	Context("when testing IntegrationTestScenarioNoLongerRequiredPredicate predicate", func() {
+		instance := gitops.IntegrationTestScenarioNoLongerRequiredPredicate()
+
+		It("returns true only when the required scenario was deleted, made optional or retargeted", func() {
+			requiredScenario := &v1beta2.IntegrationTestScenario{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "scenario-sample",
+					Namespace:  namespace,
+					Generation: 1,
+				},
+				Spec: v1beta2.IntegrationTestScenarioSpec{
+					Application: applicationName,
+				},
+			}
+			optionalScenario := requiredScenario.DeepCopy()
+			optionalScenario.Labels = map[string]string{helpers.OptionalScenarioLabel: "true"}
+			retargetedScenario := requiredScenario.DeepCopy()
+			retargetedScenario.Spec.Contexts = []v1beta2.TestContext{{Name: "component_sample"}}
+
+			Expect(instance.Create(event.CreateEvent{Object: requiredScenario})).To(BeFalse())
+			Expect(instance.Delete(event.DeleteEvent{Object: requiredScenario})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{Object: requiredScenario})).To(BeFalse())
+
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: requiredScenario, ObjectNew: optionalScenario})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: requiredScenario, ObjectNew: retargetedScenario})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: requiredScenario, ObjectNew: requiredScenario})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: optionalScenario, ObjectNew: requiredScenario})).To(BeFalse())
+		})
+	})
+
+	Context("when testing IntegrationPolicyChangePredicate predicate", func() {
+		instance := gitops.IntegrationPolicyChangePredicate()
+
+		It("returns true for the creation, deletion and spec changes of the IntegrationPolicies", func() {
+			policy := &v1beta2.IntegrationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "policy-sample",
+					Namespace:  namespace,
+					Generation: 1,
+				},
+				Spec: v1beta2.IntegrationPolicySpec{
+					Application: applicationName,
+				},
+			}
+			changedPolicy := policy.DeepCopy()
+			changedPolicy.Generation = 2
+			relabeledPolicy := policy.DeepCopy()
+			relabeledPolicy.Labels = map[string]string{"foo": "bar"}
+
+			Expect(instance.Create(event.CreateEvent{Object: policy})).To(BeTrue())
+			Expect(instance.Delete(event.DeleteEvent{Object: policy})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{Object: policy})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: policy, ObjectNew: changedPolicy})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: policy, ObjectNew: relabeledPolicy})).To(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SnapshotPromoteToAnnotation is set on a Snapshot to request its manual promotion to the Environment named in
+	// the annotation value. The annotation is removed once the request has been handled.
+	SnapshotPromoteToAnnotation = "appstudio.openshift.io/promote-to"
+)
+
+// GetSnapshotPromotionTarget returns the name of the Environment the Snapshot was requested to be promoted to,
+// and whether such a request was made at all.
+func GetSnapshotPromotionTarget(snapshot *applicationapiv1alpha1.Snapshot) (string, bool) {
+	environmentName, ok := snapshot.GetAnnotations()[SnapshotPromoteToAnnotation]
+	return environmentName, ok && environmentName != ""
+}
+
+// HasSnapshotPromoteToAnnotationChanged returns a boolean indicating whether the Snapshot annotation requesting its
+// promotion to an Environment was added or changed. If the objects passed to this function are not Snapshots,
+// the function will return false.
+func HasSnapshotPromoteToAnnotationChanged(objectOld, objectNew client.Object) bool {
+	if oldSnapshot, ok := objectOld.(*applicationapiv1alpha1.Snapshot); ok {
+		if newSnapshot, ok := objectNew.(*applicationapiv1alpha1.Snapshot); ok {
+			newValue, found := GetSnapshotPromotionTarget(newSnapshot)
+			if !found {
+				return false
+			}
+			oldValue, _ := GetSnapshotPromotionTarget(oldSnapshot)
+			return oldValue != newValue
+		}
+	}
+	return false
+}
+
+// RemoveSnapshotPromoteToAnnotation removes the promotion request annotation from the Snapshot.
+func RemoveSnapshotPromoteToAnnotation(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot) error {
+	patch := client.MergeFrom(snapshot.DeepCopy())
+	err := metadata.DeleteAnnotation(snapshot, SnapshotPromoteToAnnotation)
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation %s: %w", SnapshotPromoteToAnnotation, err)
+	}
+	err = adapterClient.Patch(ctx, snapshot, patch)
+	if err != nil {
+		return fmt.Errorf("failed to patch snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// NewSnapshotEnvironmentBinding creates a new SnapshotEnvironmentBinding deploying the given Snapshot of the
+// application to the Environment.
+func NewSnapshotEnvironmentBinding(snapshot *applicationapiv1alpha1.Snapshot, application *applicationapiv1alpha1.Application, environmentName string) *applicationapiv1alpha1.SnapshotEnvironmentBinding {
+	binding := &applicationapiv1alpha1.SnapshotEnvironmentBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: application.Name + "-" + environmentName + "-",
+			Namespace:    application.Namespace,
+		},
+		Spec: applicationapiv1alpha1.SnapshotEnvironmentBindingSpec{
+			Application: application.Name,
+			Environment: environmentName,
+		},
+	}
+	SetSnapshotEnvironmentBindingSnapshot(binding, snapshot)
+
+	return binding
+}
+
+// SetSnapshotEnvironmentBindingSnapshot points the SnapshotEnvironmentBinding to the given Snapshot and updates
+// its components to match the ones of the Snapshot. The configuration of the components which are already part of
+// the binding is preserved.
+func SetSnapshotEnvironmentBindingSnapshot(binding *applicationapiv1alpha1.SnapshotEnvironmentBinding, snapshot *applicationapiv1alpha1.Snapshot) {
+	existingComponents := map[string]applicationapiv1alpha1.BindingComponent{}
+	for _, component := range binding.Spec.Components {
+		existingComponents[component.Name] = component
+	}
+
+	components := make([]applicationapiv1alpha1.BindingComponent, 0, len(snapshot.Spec.Components))
+	for _, snapshotComponent := range snapshot.Spec.Components {
+		component, ok := existingComponents[snapshotComponent.Name]
+		if !ok {
+			component = applicationapiv1alpha1.BindingComponent{Name: snapshotComponent.Name}
+		}
+		components = append(components, component)
+	}
+
+	binding.Spec.Snapshot = snapshot.Name
+	binding.Spec.Components = components
+}
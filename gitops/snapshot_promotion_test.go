@@ -0,0 +1,123 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+var _ = Describe("Snapshot promotion", func() {
+
+	var (
+		application *applicationapiv1alpha1.Application
+		snapshot    *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		application = &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-sample",
+				Namespace: "default",
+			},
+		}
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: application.Name,
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "component-a", ContainerImage: "quay.io/redhat-appstudio/sample-image-a"},
+					{Name: "component-b", ContainerImage: "quay.io/redhat-appstudio/sample-image-b"},
+				},
+			},
+		}
+	})
+
+	It("gets the environment the snapshot was requested to be promoted to", func() {
+		_, found := gitops.GetSnapshotPromotionTarget(snapshot)
+		Expect(found).To(BeFalse())
+
+		snapshot.Annotations = map[string]string{gitops.SnapshotPromoteToAnnotation: ""}
+		_, found = gitops.GetSnapshotPromotionTarget(snapshot)
+		Expect(found).To(BeFalse())
+
+		snapshot.Annotations[gitops.SnapshotPromoteToAnnotation] = "staging"
+		environmentName, found := gitops.GetSnapshotPromotionTarget(snapshot)
+		Expect(found).To(BeTrue())
+		Expect(environmentName).To(Equal("staging"))
+	})
+
+	It("triggers the reconciliation only when the promotion request is added or changed", func() {
+		instance := gitops.SnapshotPromotionRequestedPredicate()
+		Expect(instance.Create(event.CreateEvent{Object: snapshot})).To(BeFalse())
+
+		requested := snapshot.DeepCopy()
+		requested.Annotations = map[string]string{gitops.SnapshotPromoteToAnnotation: "staging"}
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: snapshot, ObjectNew: requested})).To(BeTrue())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: requested})).To(BeFalse())
+		// removing the request once it's handled doesn't trigger the reconciliation
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: snapshot})).To(BeFalse())
+
+		changed := requested.DeepCopy()
+		changed.Annotations[gitops.SnapshotPromoteToAnnotation] = "production"
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: requested, ObjectNew: changed})).To(BeTrue())
+	})
+
+	It("creates a binding deploying the snapshot to the environment", func() {
+		binding := gitops.NewSnapshotEnvironmentBinding(snapshot, application, "staging")
+		Expect(binding.GenerateName).To(Equal("application-sample-staging-"))
+		Expect(binding.Namespace).To(Equal(application.Namespace))
+		Expect(binding.Spec.Application).To(Equal(application.Name))
+		Expect(binding.Spec.Environment).To(Equal("staging"))
+		Expect(binding.Spec.Snapshot).To(Equal(snapshot.Name))
+		Expect(binding.Spec.Components).To(Equal([]applicationapiv1alpha1.BindingComponent{
+			{Name: "component-a"},
+			{Name: "component-b"},
+		}))
+	})
+
+	It("preserves the configuration of the existing components when updating the binding", func() {
+		replicas := 3
+		binding := &applicationapiv1alpha1.SnapshotEnvironmentBinding{
+			Spec: applicationapiv1alpha1.SnapshotEnvironmentBindingSpec{
+				Application: application.Name,
+				Environment: "staging",
+				Snapshot:    "previous-snapshot",
+				Components: []applicationapiv1alpha1.BindingComponent{
+					{Name: "component-a", Configuration: applicationapiv1alpha1.BindingComponentConfiguration{Replicas: &replicas}},
+					{Name: "removed-component"},
+				},
+			},
+		}
+
+		gitops.SetSnapshotEnvironmentBindingSnapshot(binding, snapshot)
+		Expect(binding.Spec.Snapshot).To(Equal(snapshot.Name))
+		Expect(binding.Spec.Components).To(HaveLen(2))
+		Expect(binding.Spec.Components[0].Name).To(Equal("component-a"))
+		Expect(*binding.Spec.Components[0].Configuration.Replicas).To(Equal(3))
+		Expect(binding.Spec.Components[1].Name).To(Equal("component-b"))
+		Expect(binding.Spec.Components[1].Configuration.Replicas).To(BeNil())
+	})
+})
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+// GetSnapshotSBOMs returns the map of the components of the Snapshot to the references of the SBOMs of their
+// images, as recorded in the Snapshot annotation.
+func GetSnapshotSBOMs(snapshot *applicationapiv1alpha1.Snapshot) (map[string]string, error) {
+	sboms := map[string]string{}
+	value, ok := snapshot.GetAnnotations()[SnapshotSBOMsAnnotation]
+	if !ok || value == "" {
+		return sboms, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &sboms); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SBOMs from snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return sboms, nil
+}
+
+// SetSnapshotSBOMs records the given SBOM references of the components in the Snapshot annotation. The annotation
+// isn't set when none of the component images has a SBOM.
+func SetSnapshotSBOMs(snapshot *applicationapiv1alpha1.Snapshot, sboms map[string]string) error {
+	if len(sboms) == 0 {
+		return nil
+	}
+
+	value, err := json.Marshal(sboms)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SBOMs into JSON: %w", err)
+	}
+
+	return metadata.SetAnnotation(&snapshot.ObjectMeta, SnapshotSBOMsAnnotation, string(value))
+}
+
+// SetComponentSBOM records the given SBOM reference of the Component image in the Component annotation, the
+// annotation is removed when the Component image has no SBOM.
+func SetComponentSBOM(component *applicationapiv1alpha1.Component, sbom string) error {
+	if sbom == "" {
+		return metadata.DeleteAnnotation(component, ComponentSBOMAnnotation)
+	}
+
+	return metadata.SetAnnotation(&component.ObjectMeta, ComponentSBOMAnnotation, sbom)
+}
+
+// GetComponentsSBOMs returns the SBOM references of the application components which are part of the Snapshot with
+// the image of the global candidate list.
+func GetComponentsSBOMs(applicationComponents *[]applicationapiv1alpha1.Component, snapshot *applicationapiv1alpha1.Snapshot) map[string]string {
+	sboms := map[string]string{}
+	for i := range *applicationComponents {
+		component := &(*applicationComponents)[i]
+		sbom := component.GetAnnotations()[ComponentSBOMAnnotation]
+		if sbom == "" {
+			continue
+		}
+		for _, snapshotComponent := range snapshot.Spec.Components {
+			if snapshotComponent.Name == component.Name && snapshotComponent.ContainerImage == component.Spec.ContainerImage {
+				sboms[component.Name] = sbom
+			}
+		}
+	}
+
+	return sboms
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+)
+
+var _ = Describe("Snapshot SBOMs", func() {
+
+	const (
+		image = "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+		sbom  = "quay.io/redhat-appstudio/sample-image@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+	var (
+		snapshot  *applicationapiv1alpha1.Snapshot
+		component *applicationapiv1alpha1.Component
+	)
+
+	BeforeEach(func() {
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sbom",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{Name: "component-sample", ContainerImage: image},
+				},
+			},
+		}
+		component = &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "component-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.ComponentSpec{
+				ComponentName:  "component-sample",
+				Application:    "application-sample",
+				ContainerImage: image,
+			},
+		}
+	})
+
+	It("records the SBOMs of the components in the snapshot", func() {
+		Expect(gitops.SetSnapshotSBOMs(snapshot, map[string]string{})).To(Succeed())
+		Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotSBOMsAnnotation))
+		Expect(gitops.GetSnapshotSBOMs(snapshot)).To(BeEmpty())
+
+		Expect(gitops.SetSnapshotSBOMs(snapshot, map[string]string{"component-sample": sbom})).To(Succeed())
+		Expect(gitops.GetSnapshotSBOMs(snapshot)).To(Equal(map[string]string{"component-sample": sbom}))
+
+		Expect(metadata.SetAnnotation(&snapshot.ObjectMeta, gitops.SnapshotSBOMsAnnotation, "{invalid")).To(Succeed())
+		_, err := gitops.GetSnapshotSBOMs(snapshot)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("carries the SBOMs of the global candidate images over to the snapshot", func() {
+		components := []applicationapiv1alpha1.Component{*component}
+		Expect(gitops.GetComponentsSBOMs(&components, snapshot)).To(BeEmpty())
+
+		Expect(gitops.SetComponentSBOM(component, sbom)).To(Succeed())
+		components = []applicationapiv1alpha1.Component{*component}
+		Expect(gitops.GetComponentsSBOMs(&components, snapshot)).To(Equal(map[string]string{"component-sample": sbom}))
+
+		// the SBOM of another image of the component isn't carried over
+		snapshot.Spec.Components[0].ContainerImage = sbom
+		Expect(gitops.GetComponentsSBOMs(&components, snapshot)).To(BeEmpty())
+
+		Expect(gitops.SetComponentSBOM(component, "")).To(Succeed())
+		Expect(component.GetAnnotations()).NotTo(HaveKey(gitops.ComponentSBOMAnnotation))
+	})
+})
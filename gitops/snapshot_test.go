@@ -21,9 +21,18 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
 	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -155,7 +164,7 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		condition := metav1.Condition{
 			Type:    gitops.LegacyTestSucceededCondition,
 			Status:  metav1.ConditionTrue,
-			Reason:  gitops.AppStudioTestSucceededConditionSatisfied,
+			Reason:  reasons.AppStudioTestSucceededConditionSatisfied,
 			Message: "Test message",
 		}
 		meta.SetStatusCondition(&hasSnapshot.Status.Conditions, condition)
@@ -174,7 +183,7 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		condition := metav1.Condition{
 			Type:    gitops.LegacyIntegrationStatusCondition,
 			Status:  metav1.ConditionFalse,
-			Reason:  gitops.AppStudioIntegrationStatusInvalid,
+			Reason:  reasons.AppStudioIntegrationStatusInvalid,
 			Message: "Test message",
 		}
 		meta.SetStatusCondition(&hasSnapshot.Status.Conditions, condition)
@@ -194,6 +203,14 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(hasSnapshot.Status.Conditions).NotTo(BeNil())
 		Expect(meta.IsStatusConditionTrue(hasSnapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition)).To(BeFalse())
 		Expect(gitops.IsSnapshotMarkedAsFailed(hasSnapshot)).To(BeTrue())
+		Expect(gitops.IsSnapshotMarkedAsTimedOut(hasSnapshot)).To(BeFalse())
+	})
+
+	It("ensures the Snapshots status can be marked as timed out", func() {
+		err := gitops.MarkSnapshotAsTimedOut(ctx, k8sClient, hasSnapshot, "Test message")
+		Expect(err).To(BeNil())
+		Expect(gitops.IsSnapshotMarkedAsFailed(hasSnapshot)).To(BeTrue())
+		Expect(gitops.IsSnapshotMarkedAsTimedOut(hasSnapshot)).To(BeTrue())
 	})
 
 	It("ensures the Snapshots status can be marked as error", func() {
@@ -215,7 +232,7 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(err).To(BeNil())
 		Expect(hasSnapshot.Status.Conditions).NotTo(BeNil())
 		foundStatusCondition := meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioIntegrationStatusCondition)
-		Expect(foundStatusCondition.Reason).To(Equal(gitops.AppStudioIntegrationStatusInProgress))
+		Expect(foundStatusCondition.Reason).To(Equal(reasons.AppStudioIntegrationStatusInProgress))
 	})
 
 	It("ensures the Snapshots status can be marked as invalid", func() {
@@ -239,6 +256,21 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(gitops.IsSnapshotMarkedAsAutoReleased(hasSnapshot)).To(BeTrue())
 	})
 
+	It("ensures the Snapshots status can be marked as pending the release window", func() {
+		Expect(gitops.IsSnapshotPendingReleaseWindow(hasSnapshot)).To(BeFalse())
+
+		err := gitops.MarkSnapshotAsPendingReleaseWindow(ctx, k8sClient, hasSnapshot, "Test message")
+		Expect(err).To(BeNil())
+		Expect(gitops.IsSnapshotPendingReleaseWindow(hasSnapshot)).To(BeTrue())
+		Expect(gitops.IsSnapshotMarkedAsAutoReleased(hasSnapshot)).To(BeFalse())
+
+		Eventually(func(g Gomega) {
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(hasSnapshot), snapshot)).To(Succeed())
+			g.Expect(gitops.IsSnapshotPendingReleaseWindow(snapshot)).To(BeTrue())
+		}).Should(Succeed())
+	})
+
 	It("ensures the Snapshots status can be marked as component added to global candidate list", func() {
 		Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(hasSnapshot)).To(BeFalse())
 		Expect(gitops.IsComponentSnapshotCreatedByPACPushEvent(hasSnapshot)).To(BeTrue())
@@ -253,6 +285,38 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(hasSnapshot)).To(BeTrue())
 	})
 
+	It("ensures the conditions of the Snapshots written concurrently are all kept", func() {
+		releasingSnapshot := hasSnapshot.DeepCopy()
+		promotingSnapshot := hasSnapshot.DeepCopy()
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		wg.Add(2)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			errs[0] = gitops.MarkSnapshotAsAutoReleased(ctx, k8sClient, releasingSnapshot, "Test message")
+		}()
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			errs[1] = gitops.MarkSnapshotAsAddedToGlobalCandidateList(ctx, k8sClient, promotingSnapshot, "Test message")
+		}()
+		wg.Wait()
+		Expect(errs[0]).To(Succeed())
+		Expect(errs[1]).To(Succeed())
+
+		// a writer holding an outdated version of the Snapshot re-applies its condition to the latest one
+		Expect(gitops.MarkSnapshotAsInvalid(ctx, k8sClient, hasSnapshot, "Test message")).To(Succeed())
+		Eventually(func(g Gomega) {
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(hasSnapshot), snapshot)).To(Succeed())
+			g.Expect(gitops.IsSnapshotMarkedAsAutoReleased(snapshot)).To(BeTrue())
+			g.Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(snapshot)).To(BeTrue())
+			g.Expect(gitops.IsSnapshotMarkedAsInvalid(snapshot)).To(BeTrue())
+		}).Should(Succeed())
+	})
+
 	It("ensures the Snapshots can be checked for the AppStudioTestSucceededCondition", func() {
 		checkResult := gitops.HaveAppStudioTestsFinished(hasSnapshot)
 		Expect(checkResult).To(BeFalse())
@@ -319,10 +383,35 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(returnedTime).To(Equal(metav1.Time{})) // Empty or zero time
 	})
 
+	It("returns the build pipelineRun finish time when the label is set", func() {
+		finishTime, ok := gitops.GetBuildPipelineRunFinishTime(hasSnapshot)
+		Expect(ok).To(BeTrue())
+		Expect(finishTime.Unix()).To(Equal(int64(1675992257)))
+
+		delete(hasSnapshot.Labels, gitops.BuildPipelineRunFinishTimeLabel)
+		_, ok = gitops.GetBuildPipelineRunFinishTime(hasSnapshot)
+		Expect(ok).To(BeFalse())
+	})
+
 	It("ensures that a new Snapshots can be successfully created", func() {
 		snapshotComponents := []applicationapiv1alpha1.SnapshotComponent{}
 		createdSnapshot := gitops.NewSnapshot(hasApp, &snapshotComponents)
 		Expect(createdSnapshot).NotTo(BeNil())
+		Expect(createdSnapshot.Labels).To(HaveKeyWithValue(gitops.SnapshotContentHashLabel, gitops.GetSnapshotContentHash(createdSnapshot)))
+	})
+
+	It("ensures the content hash of the Snapshots doesn't depend on the order of their components", func() {
+		snapshotComponents := []applicationapiv1alpha1.SnapshotComponent{
+			{Name: "component-a", ContainerImage: sampleImage},
+			{Name: "component-b", ContainerImage: sampleImage},
+		}
+		snapshot := gitops.NewSnapshot(hasApp, &snapshotComponents)
+		reversedSnapshot := gitops.NewSnapshot(hasApp, &[]applicationapiv1alpha1.SnapshotComponent{snapshotComponents[1], snapshotComponents[0]})
+		Expect(gitops.GetSnapshotContentHash(snapshot)).To(Equal(gitops.GetSnapshotContentHash(reversedSnapshot)))
+		Expect(gitops.GetSnapshotContentHash(snapshot)).To(HaveLen(56))
+
+		snapshot.Spec.Components[1].ContainerImage = sampleImage + "-other"
+		Expect(gitops.GetSnapshotContentHash(snapshot)).NotTo(Equal(gitops.GetSnapshotContentHash(reversedSnapshot)))
 	})
 
 	It("ensures the same Snapshots can be successfully compared", func() {
@@ -363,7 +452,7 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		Expect(hasSnapshot.Status.Conditions).NotTo(BeNil())
 		Expect(gitops.IsSnapshotValid(hasSnapshot)).To(BeFalse())
 		Expect(gitops.IsSnapshotStatusConditionSet(hasSnapshot, gitops.AppStudioIntegrationStatusCondition,
-			metav1.ConditionFalse, gitops.AppStudioIntegrationStatusInvalid)).To(BeTrue())
+			metav1.ConditionFalse, reasons.AppStudioIntegrationStatusInvalid)).To(BeTrue())
 	})
 
 	It("ensures the Snapshots status can be detected to be valid", func() {
@@ -602,6 +691,33 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 
 	})
 
+	Context("CopySnapshotLabelsAndAnnotation tests", func() {
+		It("propagates the git metadata of the build pipelineRun to the snapshot", func() {
+			buildPipelineRunMeta := &metav1.ObjectMeta{
+				Labels: map[string]string{
+					"pipelinesascode.tekton.dev/event-type":   "pull_request",
+					"pipelinesascode.tekton.dev/sha":          "6c65b2fcaea3e1a0a92476c8b5dc89e92a85f025",
+					"pipelinesascode.tekton.dev/pull-request": "42",
+				},
+				Annotations: map[string]string{
+					"pipelinesascode.tekton.dev/repo-url":     "https://github.com/devfile-samples/devfile-sample-go-basic",
+					"pipelinesascode.tekton.dev/branch":       "main",
+					"pipelinesascode.tekton.dev/pull-request": "42",
+				},
+			}
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+
+			gitops.CopySnapshotLabelsAndAnnotation(hasApp, snapshot, "component-sample", buildPipelineRunMeta, gitops.BuildPipelineRunPrefix, false)
+			Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.PipelineAsCodeEventTypeLabel, "pull_request"))
+			Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.PipelineAsCodeSHALabel, "6c65b2fcaea3e1a0a92476c8b5dc89e92a85f025"))
+			Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.PipelineAsCodePullRequestAnnotation, "42"))
+			Expect(snapshot.Annotations).To(HaveKeyWithValue(gitops.PipelineAsCodeRepoURLAnnotation, "https://github.com/devfile-samples/devfile-sample-go-basic"))
+			Expect(snapshot.Annotations).To(HaveKeyWithValue(gitops.PipelineAsCodeTargetBranchAnnotation, "main"))
+			Expect(snapshot.Annotations).To(HaveKeyWithValue(gitops.PipelineAsCodePullRequestAnnotation, "42"))
+			Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.SnapshotComponentLabel, "component-sample"))
+		})
+	})
+
 	Context("Override snapshot tests", func() {
 		When("Snapshot has snapshot type label", func() {
 			var overrideSnapshot *applicationapiv1alpha1.Snapshot
@@ -617,4 +733,131 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 		})
 
 	})
+
+	Context("Scheduled run tests", func() {
+		It("returns the latest passed Snapshot created by push", func() {
+			older := hasSnapshot.DeepCopy()
+			older.Name = "snapshot-older"
+			older.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			newer := hasSnapshot.DeepCopy()
+			newer.Name = "snapshot-newer"
+			newer.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+			pullRequest := hasSnapshot.DeepCopy()
+			pullRequest.Name = "snapshot-pull-request"
+			pullRequest.CreationTimestamp = metav1.NewTime(time.Now())
+			pullRequest.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodePullRequestType
+			failed := hasSnapshot.DeepCopy()
+			failed.Name = "snapshot-failed"
+			failed.CreationTimestamp = metav1.NewTime(time.Now())
+
+			for _, snapshot := range []*applicationapiv1alpha1.Snapshot{older, newer, pullRequest} {
+				meta.SetStatusCondition(&snapshot.Status.Conditions, metav1.Condition{
+					Type:   gitops.AppStudioTestSucceededCondition,
+					Status: metav1.ConditionTrue,
+					Reason: reasons.AppStudioTestSucceededConditionSatisfied,
+				})
+			}
+
+			snapshots := []applicationapiv1alpha1.Snapshot{*older, *newer, *pullRequest, *failed}
+			Expect(gitops.GetLatestPassedSnapshot(&snapshots).Name).To(Equal("snapshot-newer"))
+			Expect(gitops.GetLatestPassedSnapshot(&[]applicationapiv1alpha1.Snapshot{*failed})).To(BeNil())
+		})
+
+		It("returns the latest auto-released Snapshot", func() {
+			released := hasSnapshot.DeepCopy()
+			released.Name = "snapshot-released"
+			released.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+			meta.SetStatusCondition(&released.Status.Conditions, metav1.Condition{
+				Type:   gitops.SnapshotAutoReleasedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: "AutoReleased",
+			})
+			notReleased := hasSnapshot.DeepCopy()
+			notReleased.Name = "snapshot-not-released"
+			notReleased.CreationTimestamp = metav1.NewTime(time.Now())
+
+			snapshots := []applicationapiv1alpha1.Snapshot{*released, *notReleased}
+			Expect(gitops.GetLatestReleasedSnapshot(&snapshots).Name).To(Equal("snapshot-released"))
+			Expect(gitops.GetLatestReleasedSnapshot(&[]applicationapiv1alpha1.Snapshot{*notReleased})).To(BeNil())
+		})
+
+		It("finds the Snapshot created for the image pushed for a component", func() {
+			pushedImage := "quay.io/sample/image@sha256:" + strings.Repeat("a", 64)
+			pushed := hasSnapshot.DeepCopy()
+			pushed.Name = "snapshot-pushed"
+			pushed.Labels = map[string]string{gitops.SnapshotComponentLabel: "component-sample"}
+			pushed.Annotations = map[string]string{gitops.PushedImageAnnotation: pushedImage}
+			otherComponent := pushed.DeepCopy()
+			otherComponent.Name = "snapshot-other-component"
+			otherComponent.Labels[gitops.SnapshotComponentLabel] = "component-other"
+
+			snapshots := []applicationapiv1alpha1.Snapshot{*otherComponent, *hasSnapshot, *pushed}
+			Expect(gitops.GetSnapshotWithPushedImage(&snapshots, "component-sample", pushedImage).Name).To(Equal("snapshot-pushed"))
+			Expect(gitops.GetSnapshotWithPushedImage(&snapshots, "component-sample", "quay.io/sample/image@sha256:"+strings.Repeat("b", 64))).To(BeNil())
+		})
+
+		It("records the revalidation result of the Snapshot", func() {
+			Expect(gitops.MarkSnapshotRevalidation(ctx, k8sClient, hasSnapshot, false, "failed")).To(Succeed())
+			Expect(gitops.IsSnapshotRevalidationFailed(hasSnapshot)).To(BeTrue())
+
+			Expect(gitops.MarkSnapshotRevalidation(ctx, k8sClient, hasSnapshot, true, "passed")).To(Succeed())
+			Expect(gitops.IsSnapshotRevalidationFailed(hasSnapshot)).To(BeFalse())
+			Expect(meta.IsStatusConditionTrue(hasSnapshot.Status.Conditions, gitops.SnapshotRevalidatedCondition)).To(BeTrue())
+
+			// the revalidation of an outdated Snapshot keeps the conditions written by the other controllers
+			outdatedSnapshot := hasSnapshot.DeepCopy()
+			Expect(gitops.MarkSnapshotAsAutoReleased(ctx, k8sClient, hasSnapshot, "released")).To(Succeed())
+			Expect(gitops.MarkSnapshotRevalidation(ctx, k8sClient, outdatedSnapshot, false, "failed")).To(Succeed())
+			Expect(gitops.IsSnapshotRevalidationFailed(outdatedSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsAutoReleased(outdatedSnapshot)).To(BeTrue())
+		})
+
+		It("verifies the image of the Snapshot only when image verification is enabled", func() {
+			snapshot := hasSnapshot.DeepCopy()
+			verification, err := gitops.VerifySnapshotImage(ctx, nil, snapshot, "component-sample", "quay.io/sample/image:latest")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification).To(BeNil())
+			Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotImageVerificationAnnotation))
+
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			verifier, err := signature.NewVerifier(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+			Expect(err).NotTo(HaveOccurred())
+
+			// the image isn't referenced by digest, so it fails the verification without reaching the registry
+			verification, err = gitops.VerifySnapshotImage(ctx, verifier, snapshot, "component-sample", "quay.io/sample/image:latest")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification.Verified).To(BeFalse())
+			Expect(verification.Message).To(ContainSubstring("The image of component component-sample failed verification"))
+			Expect(gitops.GetSnapshotImageVerification(snapshot)).To(Equal(verification))
+		})
+
+		It("records the verification result of the image built for the Snapshot", func() {
+			verification, err := gitops.GetSnapshotImageVerification(hasSnapshot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verification).To(BeNil())
+			Expect(gitops.SetSnapshotImageVerification(hasSnapshot, gitops.ImageVerification{Verified: false, Message: "not signed"})).To(Succeed())
+			Expect(gitops.GetSnapshotImageVerification(hasSnapshot)).To(Equal(&gitops.ImageVerification{Verified: false, Message: "not signed"}))
+
+			Expect(gitops.MarkSnapshotImageVerification(ctx, k8sClient, hasSnapshot, true, "signed")).To(Succeed())
+			Expect(gitops.IsSnapshotImageVerificationFailed(hasSnapshot)).To(BeFalse())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(hasSnapshot)).To(BeFalse())
+
+			// the verification result of an outdated Snapshot keeps the conditions written by the other controllers
+			outdatedSnapshot := hasSnapshot.DeepCopy()
+			Expect(gitops.MarkSnapshotAsAddedToGlobalCandidateList(ctx, k8sClient, hasSnapshot, "added")).To(Succeed())
+			Expect(gitops.MarkSnapshotImageVerification(ctx, k8sClient, outdatedSnapshot, false, "not signed")).To(Succeed())
+			Expect(gitops.IsSnapshotImageVerificationFailed(outdatedSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(outdatedSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(outdatedSnapshot)).To(BeTrue())
+		})
+
+		It("requests the status of the Snapshot to be reported", func() {
+			Expect(gitops.IsSnapshotStatusReportRequested(hasSnapshot)).To(BeFalse())
+			Expect(gitops.RequestSnapshotStatusReport(ctx, k8sClient, hasSnapshot)).To(Succeed())
+			Expect(gitops.IsSnapshotStatusReportRequested(hasSnapshot)).To(BeTrue())
+		})
+	})
 })
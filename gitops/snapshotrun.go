@@ -0,0 +1,173 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ScheduledSnapshotRunLabel is set on the SnapshotRuns created for the scheduled runs of IntegrationTestScenarios.
+	ScheduledSnapshotRunLabel = "test.appstudio.openshift.io/scheduled"
+
+	// RevalidationSnapshotRunLabel is set on the SnapshotRuns re-running the required IntegrationTestScenarios
+	// against a released Snapshot.
+	RevalidationSnapshotRunLabel = "test.appstudio.openshift.io/revalidation"
+
+	// MaxPeriodicSnapshotRuns is the number of the latest SnapshotRuns kept for each kind of periodic run of an
+	// IntegrationTestScenario, the older finished ones are deleted.
+	MaxPeriodicSnapshotRuns = 10
+
+	// periodicSnapshotRunTimeFormat is the format of the schedule tick in the names of the periodic SnapshotRuns.
+	periodicSnapshotRunTimeFormat = "20060102-1504"
+
+	// maxSnapshotRunNameLength is the maximum length of the name of a SnapshotRun.
+	maxSnapshotRunNameLength = 253
+)
+
+// NewScheduledSnapshotRun creates a new SnapshotRun running the IntegrationTestScenario against the Snapshot for
+// the scheduled run of the scenario due at the given schedule tick.
+func NewScheduledSnapshotRun(snapshot *applicationapiv1alpha1.Snapshot, scenarioName string, reportStatus bool, tick time.Time) *v1beta2.SnapshotRun {
+	return newPeriodicSnapshotRun(snapshot, scenarioName, "scheduled", reportStatus, tick)
+}
+
+// newPeriodicSnapshotRun creates a new SnapshotRun for the given kind of periodic run of the IntegrationTestScenario.
+// Its name is derived from the scenario, the kind of run and the schedule tick, so that retrying the creation of
+// the run of the same tick doesn't create a second SnapshotRun.
+func newPeriodicSnapshotRun(snapshot *applicationapiv1alpha1.Snapshot, scenarioName, kind string, reportStatus bool, tick time.Time) *v1beta2.SnapshotRun {
+	suffix := fmt.Sprintf("-%s-%s", kind, tick.UTC().Format(periodicSnapshotRunTimeFormat))
+	prefix := scenarioName
+	if len(prefix)+len(suffix) > maxSnapshotRunNameLength {
+		prefix = prefix[:maxSnapshotRunNameLength-len(suffix)]
+	}
+
+	return &v1beta2.SnapshotRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prefix + suffix,
+			Namespace: snapshot.Namespace,
+			Labels: map[string]string{
+				SnapshotTestScenarioLabel: scenarioName,
+				ScheduledSnapshotRunLabel: "true",
+			},
+		},
+		Spec: v1beta2.SnapshotRunSpec{
+			Snapshot:     snapshot.Name,
+			Scenario:     scenarioName,
+			ReportStatus: reportStatus,
+		},
+	}
+}
+
+// MarkSnapshotRunAsRunning sets the start time of the SnapshotRun and its Succeeded condition to running.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotRunAsRunning(ctx context.Context, adapterClient client.Client, snapshotRun *v1beta2.SnapshotRun, message string) error {
+	patch := client.MergeFrom(snapshotRun.DeepCopy())
+	snapshotRun.Status.StartTime = &metav1.Time{Time: time.Now()}
+	meta.SetStatusCondition(&snapshotRun.Status.Conditions, metav1.Condition{
+		Type:    v1beta2.SnapshotRunSucceededCondition,
+		Status:  metav1.ConditionUnknown,
+		Reason:  v1beta2.SnapshotRunRunningReason,
+		Message: message,
+	})
+
+	return adapterClient.Status().Patch(ctx, snapshotRun, patch)
+}
+
+// MarkSnapshotRunAsFinished sets the completion time of the SnapshotRun and its Succeeded condition to passed or
+// failed. If the patch command fails, an error will be returned.
+func MarkSnapshotRunAsFinished(ctx context.Context, adapterClient client.Client, snapshotRun *v1beta2.SnapshotRun, passed bool, message string) error {
+	patch := client.MergeFrom(snapshotRun.DeepCopy())
+	condition := metav1.Condition{
+		Type:    v1beta2.SnapshotRunSucceededCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1beta2.SnapshotRunPassedReason,
+		Message: message,
+	}
+	if !passed {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1beta2.SnapshotRunFailedReason
+	}
+	snapshotRun.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	meta.SetStatusCondition(&snapshotRun.Status.Conditions, condition)
+
+	return adapterClient.Status().Patch(ctx, snapshotRun, patch)
+}
+
+// MarkSnapshotRunAsInvalid sets the Succeeded condition of the SnapshotRun to invalid, so it's never run.
+// If the patch command fails, an error will be returned.
+func MarkSnapshotRunAsInvalid(ctx context.Context, adapterClient client.Client, snapshotRun *v1beta2.SnapshotRun, message string) error {
+	patch := client.MergeFrom(snapshotRun.DeepCopy())
+	meta.SetStatusCondition(&snapshotRun.Status.Conditions, metav1.Condition{
+		Type:    v1beta2.SnapshotRunSucceededCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1beta2.SnapshotRunInvalidReason,
+		Message: message,
+	})
+
+	return adapterClient.Status().Patch(ctx, snapshotRun, patch)
+}
+
+// NewRevalidationSnapshotRun creates a new SnapshotRun re-running the IntegrationTestScenario against the released
+// Snapshot for a revalidation of the Snapshot.
+func NewRevalidationSnapshotRun(snapshot *applicationapiv1alpha1.Snapshot, scenarioName string, tick time.Time) *v1beta2.SnapshotRun {
+	snapshotRun := newPeriodicSnapshotRun(snapshot, scenarioName, "revalidation", false, tick)
+	snapshotRun.Labels[RevalidationSnapshotRunLabel] = "true"
+	return snapshotRun
+}
+
+// IsScheduledSnapshotRun returns true if the SnapshotRun was created for a scheduled run of an
+// IntegrationTestScenario, excluding the revalidations of the released Snapshots.
+func IsScheduledSnapshotRun(snapshotRun *v1beta2.SnapshotRun) bool {
+	return metadata.HasLabelWithValue(snapshotRun, ScheduledSnapshotRunLabel, "true") && !IsRevalidationSnapshotRun(snapshotRun)
+}
+
+// IsRevalidationSnapshotRun returns true if the SnapshotRun was created for the revalidation of a released Snapshot.
+func IsRevalidationSnapshotRun(snapshotRun *v1beta2.SnapshotRun) bool {
+	return metadata.HasLabelWithValue(snapshotRun, RevalidationSnapshotRunLabel, "true")
+}
+
+// GetPrunableSnapshotRuns returns the finished SnapshotRuns matching the given filter which are older than the latest
+// MaxPeriodicSnapshotRuns of them.
+func GetPrunableSnapshotRuns(snapshotRuns *[]v1beta2.SnapshotRun, filter func(snapshotRun *v1beta2.SnapshotRun) bool) []v1beta2.SnapshotRun {
+	matching := []v1beta2.SnapshotRun{}
+	for _, snapshotRun := range *snapshotRuns {
+		if filter(&snapshotRun) {
+			matching = append(matching, snapshotRun)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[j].CreationTimestamp.Before(&matching[i].CreationTimestamp)
+	})
+
+	prunable := []v1beta2.SnapshotRun{}
+	for i, snapshotRun := range matching {
+		if i >= MaxPeriodicSnapshotRuns && snapshotRun.HasFinished() {
+			prunable = append(prunable, snapshotRun)
+		}
+	}
+	return prunable
+}
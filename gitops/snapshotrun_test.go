@@ -0,0 +1,170 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Gitops functions for managing SnapshotRuns", func() {
+
+	var snapshotRun *v1beta2.SnapshotRun
+
+	BeforeEach(func() {
+		snapshotRun = &v1beta2.SnapshotRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshotrun-sample",
+				Namespace: "default",
+			},
+			Spec: v1beta2.SnapshotRunSpec{
+				Snapshot: "snapshot-sample",
+				Scenario: "scenario-sample",
+			},
+		}
+		Expect(k8sClient.Create(ctx, snapshotRun)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, snapshotRun)).Should(Succeed())
+	})
+
+	It("marks the SnapshotRun as running", func() {
+		Expect(snapshotRun.HasStarted()).To(BeFalse())
+		Expect(gitops.MarkSnapshotRunAsRunning(ctx, k8sClient, snapshotRun, "running")).To(Succeed())
+		Expect(snapshotRun.HasStarted()).To(BeTrue())
+		Expect(snapshotRun.HasFinished()).To(BeFalse())
+
+		condition := meta.FindStatusCondition(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal(v1beta2.SnapshotRunRunningReason))
+	})
+
+	It("marks the SnapshotRun as passed or failed", func() {
+		Expect(gitops.MarkSnapshotRunAsFinished(ctx, k8sClient, snapshotRun, false, "failed")).To(Succeed())
+		Expect(snapshotRun.HasFinished()).To(BeTrue())
+		Expect(snapshotRun.Status.CompletionTime).NotTo(BeNil())
+		Expect(meta.IsStatusConditionFalse(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)).To(BeTrue())
+
+		Expect(gitops.MarkSnapshotRunAsFinished(ctx, k8sClient, snapshotRun, true, "passed")).To(Succeed())
+		condition := meta.FindStatusCondition(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(v1beta2.SnapshotRunPassedReason))
+	})
+
+	It("marks the SnapshotRun as invalid", func() {
+		Expect(gitops.MarkSnapshotRunAsInvalid(ctx, k8sClient, snapshotRun, "the Snapshot doesn't exist")).To(Succeed())
+		Expect(snapshotRun.HasStarted()).To(BeFalse())
+		Expect(snapshotRun.HasFinished()).To(BeTrue())
+
+		condition := meta.FindStatusCondition(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+		Expect(condition.Reason).To(Equal(v1beta2.SnapshotRunInvalidReason))
+		Expect(condition.Message).To(Equal("the Snapshot doesn't exist"))
+	})
+
+	It("creates the SnapshotRuns of the scheduled runs", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+		}
+		tick := time.Date(2026, time.October, 14, 2, 0, 0, 0, time.UTC)
+		scheduledRun := gitops.NewScheduledSnapshotRun(snapshot, "scenario-nightly", true, tick)
+		Expect(scheduledRun.Name).To(Equal("scenario-nightly-scheduled-20261014-0200"))
+		Expect(scheduledRun.Namespace).To(Equal("default"))
+		Expect(scheduledRun.Labels).To(HaveKeyWithValue(gitops.SnapshotTestScenarioLabel, "scenario-nightly"))
+		Expect(scheduledRun.Labels).To(HaveKeyWithValue(gitops.ScheduledSnapshotRunLabel, "true"))
+		Expect(scheduledRun.Spec.Snapshot).To(Equal("snapshot-sample"))
+		Expect(scheduledRun.Spec.Scenario).To(Equal("scenario-nightly"))
+		Expect(scheduledRun.Spec.ReportStatus).To(BeTrue())
+	})
+
+	It("creates the SnapshotRuns revalidating released Snapshots", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+		}
+		tick := time.Date(2026, time.October, 14, 2, 0, 0, 0, time.UTC)
+		revalidationRun := gitops.NewRevalidationSnapshotRun(snapshot, "scenario-required", tick)
+		Expect(revalidationRun.Name).To(Equal("scenario-required-revalidation-20261014-0200"))
+		Expect(gitops.IsRevalidationSnapshotRun(revalidationRun)).To(BeTrue())
+		Expect(gitops.IsScheduledSnapshotRun(revalidationRun)).To(BeFalse())
+		Expect(revalidationRun.Spec.ReportStatus).To(BeFalse())
+		scheduledRun := gitops.NewScheduledSnapshotRun(snapshot, "scenario-required", false, tick)
+		Expect(gitops.IsRevalidationSnapshotRun(scheduledRun)).To(BeFalse())
+		Expect(gitops.IsScheduledSnapshotRun(scheduledRun)).To(BeTrue())
+	})
+
+	It("keeps the names of the periodic SnapshotRuns of long scenario names valid", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+		}
+		scenarioName := strings.Repeat("a", 253)
+		scheduledRun := gitops.NewScheduledSnapshotRun(snapshot, scenarioName, false, time.Now())
+		Expect(scheduledRun.Name).To(HaveLen(253))
+		Expect(scheduledRun.Name).To(HaveSuffix(time.Now().UTC().Format("20060102-1504")))
+		Expect(scheduledRun.Labels).To(HaveKeyWithValue(gitops.SnapshotTestScenarioLabel, scenarioName))
+	})
+
+	It("returns the finished periodic SnapshotRuns older than the latest ones", func() {
+		now := time.Now()
+		snapshotRuns := []v1beta2.SnapshotRun{}
+		for i := 0; i < gitops.MaxPeriodicSnapshotRuns+2; i++ {
+			snapshotRun := v1beta2.SnapshotRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              fmt.Sprintf("scheduled-%d", i),
+					CreationTimestamp: metav1.NewTime(now.Add(time.Duration(-i) * time.Hour)),
+					Labels:            map[string]string{gitops.ScheduledSnapshotRunLabel: "true"},
+				},
+			}
+			if i != gitops.MaxPeriodicSnapshotRuns {
+				meta.SetStatusCondition(&snapshotRun.Status.Conditions, metav1.Condition{
+					Type:   v1beta2.SnapshotRunSucceededCondition,
+					Status: metav1.ConditionTrue,
+					Reason: v1beta2.SnapshotRunPassedReason,
+				})
+			}
+			snapshotRuns = append(snapshotRuns, snapshotRun)
+		}
+		revalidationRun := snapshotRuns[len(snapshotRuns)-1].DeepCopy()
+		revalidationRun.Name = "revalidation"
+		revalidationRun.Labels[gitops.RevalidationSnapshotRunLabel] = "true"
+		snapshotRuns = append(snapshotRuns, *revalidationRun)
+
+		prunable := gitops.GetPrunableSnapshotRuns(&snapshotRuns, gitops.IsScheduledSnapshotRun)
+		Expect(prunable).To(HaveLen(1))
+		Expect(prunable[0].Name).To(Equal(fmt.Sprintf("scheduled-%d", gitops.MaxPeriodicSnapshotRuns+1)))
+		Expect(gitops.GetPrunableSnapshotRuns(&snapshotRuns, gitops.IsRevalidationSnapshotRun)).To(BeEmpty())
+	})
+})
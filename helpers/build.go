@@ -1,3 +1,6 @@
 package helpers
 
 const CreateSnapshotAnnotationName = "test.appstudio.openshift.io/create-snapshot-status"
+
+// BuildFailureReportedAnnotationName is set on the failed build pipelineRuns whose failure was reported to the git provider
+const BuildFailureReportedAnnotationName = "test.appstudio.openshift.io/build-failure-reported"
@@ -19,13 +19,21 @@ package helpers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"slices"
 	"sort"
 	"time"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/junit"
+	"github.com/konflux-ci/integration-service/pkg/tap"
+	"github.com/konflux-ci/integration-service/pkg/tektonresults"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -36,6 +44,7 @@ import (
 	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 )
@@ -48,6 +57,54 @@ const (
 	//LegacyTestOutputName is the previous name of the standardized AppStudio Test output Tekton task result
 	LegacyTestOutputName = "HACBS_TEST_OUTPUT"
 
+	// TestOutputNameEnvVar is the environment variable which overrides the name of the Tekton task result
+	// holding the Test output globally
+	TestOutputNameEnvVar = "TEST_OUTPUT_NAME"
+
+	// TestOutputNameAnnotation is the IntegrationTestScenario annotation which overrides the name of the Tekton
+	// task result holding the Test output for the scenario, it is copied to the Integration PipelineRuns
+	TestOutputNameAnnotation = "test.appstudio.openshift.io/test-output-name"
+
+	// OptionalScenarioLabel is the IntegrationTestScenario label which, when set to "true", makes the scenario
+	// optional, so its result doesn't affect the outcome of the Snapshots it's run for
+	OptionalScenarioLabel = "test.appstudio.openshift.io/optional"
+
+	// ScheduleAnnotation is the IntegrationTestScenario annotation holding the cron expression of the schedule the
+	// scenario is periodically run on against the latest passed Snapshot of its application
+	ScheduleAnnotation = "test.appstudio.openshift.io/schedule"
+
+	// ScheduleReportStatusAnnotation is the IntegrationTestScenario annotation which, when set to "true", requests
+	// the results of the scheduled runs of the scenario to be reported to the git provider
+	ScheduleReportStatusAnnotation = "test.appstudio.openshift.io/schedule-report-status"
+
+	// LastScheduledRunAnnotation is the IntegrationTestScenario annotation holding the time of the last scheduled
+	// run of the scenario
+	LastScheduledRunAnnotation = "test.appstudio.openshift.io/last-scheduled-run"
+
+	// RevalidationScheduleAnnotation is the IntegrationTestScenario annotation holding the cron expression of the
+	// schedule the required scenario is re-run on against the latest released Snapshot of its application
+	RevalidationScheduleAnnotation = "test.appstudio.openshift.io/revalidation-schedule"
+
+	// LastRevalidationRunAnnotation is the IntegrationTestScenario annotation holding the time of the last
+	// revalidation run of the scenario
+	LastRevalidationRunAnnotation = "test.appstudio.openshift.io/last-revalidation-run"
+
+	// FlakyScenarioAnnotation is the IntegrationTestScenario annotation flagging the scenario as flaky, it holds the
+	// reason the scenario was flagged and is kept until it is removed by a human
+	FlakyScenarioAnnotation = "test.appstudio.openshift.io/flaky"
+
+	// JUnitResultsName is the name of the Tekton task result holding either JUnit XML test results or
+	// an oci:// reference to an OCI artifact containing them
+	JUnitResultsName = "JUNIT_RESULTS"
+
+	// TAPResultsName is the name of the Tekton task result holding Test Anything Protocol output, it is mapped
+	// to the Test output of tasks which don't produce one
+	TAPResultsName = "TAP_RESULTS"
+
+	// maxFailedTestCases is the maximum number of failed test cases kept in the test results summary, it matches
+	// the number of annotations GitHub accepts in a single check run update and keeps the snapshot annotation small
+	maxFailedTestCases = 50
+
 	// AppStudioTestOutputSuccess is the result that's set when the AppStudio test succeeds.
 	AppStudioTestOutputSuccess = "SUCCESS"
 
@@ -66,6 +123,7 @@ const (
 
 // AppStudioTestResult matches AppStudio TaskRun result contract
 type AppStudioTestResult struct {
+	Version   string `json:"version,omitempty"`
 	Result    string `json:"result"`
 	Namespace string `json:"namespace"`
 	Timestamp string `json:"timestamp"`
@@ -80,12 +138,22 @@ type AppStudioTestResult struct {
 type IntegrationTestTaskResult struct {
 	TestOutput      *AppStudioTestResult
 	ValidationError error
+	// FailedTestCases contains the failed test cases of results which report them individually, like TAP
+	FailedTestCases []intgteststat.TestCaseDetail
 }
 
-var testResultSchema = `{
+// TestOutputSchemaVersionV1 is the version of the TEST_OUTPUT schema used when the result doesn't specify one
+const TestOutputSchemaVersionV1 = "v1"
+
+// testResultSchemas maps the supported TEST_OUTPUT schema versions to their JSON schemas
+var testResultSchemas = map[string]string{
+	TestOutputSchemaVersionV1: `{
   "$schema": "http://json-schema.org/draft/2020-12/schema#",
   "type": "object",
   "properties": {
+    "version": {
+      "type": "string"
+    },
     "result": {
       "type": "string",
       "enum": ["SUCCESS", "FAILURE", "WARNING", "SKIPPED", "ERROR"]
@@ -114,13 +182,51 @@ var testResultSchema = `{
     }
   },
   "required": ["result", "timestamp", "successes", "failures", "warnings"]
-}`
+}`,
+}
+
+// compiledTestResultSchemas holds the compiled testResultSchemas, keyed by version
+var compiledTestResultSchemas = map[string]*jsonschema.Schema{}
+
+func init() {
+	for version, schema := range testResultSchemas {
+		compiledTestResultSchemas[version] = jsonschema.MustCompileString(fmt.Sprintf("test-output-%s.json", version), schema)
+	}
+}
+
+// ValidateTestOutput validates the given TEST_OUTPUT JSON against the schema of the version it declares,
+// TestOutputSchemaVersionV1 is assumed when no version is declared.
+func ValidateTestOutput(data []byte) (*AppStudioTestResult, error) {
+	var testOutput AppStudioTestResult
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("test output is not valid JSON: %w", err)
+	}
+	if err := json.Unmarshal(data, &testOutput); err != nil {
+		return nil, fmt.Errorf("test output doesn't match the AppStudioTestResult contract: %w", err)
+	}
+
+	version := testOutput.Version
+	if version == "" {
+		version = TestOutputSchemaVersionV1
+	}
+	schema, ok := compiledTestResultSchemas[version]
+	if !ok {
+		return nil, fmt.Errorf("test output schema version %q is not supported", version)
+	}
+	if err := schema.Validate(v); err != nil {
+		return nil, fmt.Errorf("test output doesn't match the %s schema: %w", version, err)
+	}
+
+	return &testOutput, nil
+}
 
 // TaskRun is an integration specific wrapper around the status of a Tekton TaskRun.
 type TaskRun struct {
 	pipelineTaskName string
 	trStatus         *tektonv1.TaskRunStatus
 	testResult       *IntegrationTestTaskResult
+	testOutputNames  []string
 }
 
 // NewTaskRunFromTektonTaskRun creates and returns am integration TaskRun from the TaskRunStatus.
@@ -128,6 +234,12 @@ func NewTaskRunFromTektonTaskRun(pipelineTaskName string, status *tektonv1.TaskR
 	return &TaskRun{pipelineTaskName: pipelineTaskName, trStatus: status}
 }
 
+// WithTestOutputNames sets the names of the task results which are parsed as the Test output of the TaskRun.
+func (t *TaskRun) WithTestOutputNames(names []string) *TaskRun {
+	t.testOutputNames = names
+	return t
+}
+
 // GetPipelineTaskName returns the name of the PipelineTask.
 func (t *TaskRun) GetPipelineTaskName() string {
 	return t.pipelineTaskName
@@ -166,34 +278,117 @@ func (t *TaskRun) GetTestResult() (*IntegrationTestTaskResult, error) {
 	if t.testResult != nil {
 		return t.testResult, nil
 	}
-	// load schema for test validation
-	sch, err := jsonschema.CompileString("schema.json", testResultSchema)
-	if err != nil {
-		return nil, fmt.Errorf("error while compiling json data for schema validation: %w", err)
+	testOutputNames := t.testOutputNames
+	if len(testOutputNames) == 0 {
+		testOutputNames = GetTestOutputNames(nil)
 	}
 
 	for _, taskRunResult := range t.trStatus.TaskRunStatusFields.Results {
-		if taskRunResult.Name == LegacyTestOutputName || taskRunResult.Name == TestOutputName {
-			var testOutput AppStudioTestResult
+		if slices.Contains(testOutputNames, taskRunResult.Name) {
 			var testResult IntegrationTestTaskResult = IntegrationTestTaskResult{}
-			var v interface{}
-
-			if err := json.Unmarshal([]byte(taskRunResult.Value.StringVal), &testOutput); err != nil {
-				testResult.ValidationError = fmt.Errorf("error while mapping json data from task %s result %s to AppStudioTestResult: %w", t.GetPipelineTaskName(), taskRunResult.Name, err)
-			} else if err := json.Unmarshal([]byte(taskRunResult.Value.StringVal), &v); err != nil {
-				testResult.ValidationError = fmt.Errorf("error while mapping json data from task %s result %s: %w", t.GetPipelineTaskName(), taskRunResult.Name, err)
-			} else if err = sch.Validate(v); err != nil {
-				testResult.ValidationError = fmt.Errorf("error validating schema of results from task %s result %s: %w", t.GetPipelineTaskName(), taskRunResult.Name, err)
+
+			testOutput, err := ValidateTestOutput([]byte(taskRunResult.Value.StringVal))
+			if err != nil {
+				testResult.ValidationError = fmt.Errorf("error validating results from task %s result %s: %w", t.GetPipelineTaskName(), taskRunResult.Name, err)
 			} else {
-				testResult.TestOutput = &testOutput
+				testResult.TestOutput = testOutput
 			}
 			t.testResult = &testResult
 			return &testResult, nil
 		}
 	}
+
+	for _, taskRunResult := range t.trStatus.TaskRunStatusFields.Results {
+		if taskRunResult.Name == TAPResultsName {
+			t.testResult = t.getTestResultFromTAP(taskRunResult.Value.StringVal)
+			return t.testResult, nil
+		}
+	}
 	return nil, nil
 }
 
+// getTestResultFromTAP maps the Test Anything Protocol output of the TaskRun to an IntegrationTestTaskResult.
+// Incomplete runs are reported as ERROR, failing test points as FAILURE and test points marked with
+// the TODO directive as WARNING.
+func (t *TaskRun) getTestResultFromTAP(output string) *IntegrationTestTaskResult {
+	report, err := tap.Parse(output)
+	if err != nil {
+		return &IntegrationTestTaskResult{
+			ValidationError: fmt.Errorf("error parsing results from task %s result %s: %w", t.GetPipelineTaskName(), TAPResultsName, err),
+		}
+	}
+
+	timestamp := time.Now()
+	if t.trStatus.CompletionTime != nil {
+		timestamp = t.trStatus.CompletionTime.Time
+	}
+	passed, failed, skipped, todo := report.Count()
+	testOutput := &AppStudioTestResult{
+		Version:   TestOutputSchemaVersionV1,
+		Successes: passed,
+		Failures:  failed,
+		Warnings:  todo,
+		Note:      fmt.Sprintf("Mapped from %s: %d passed, %d failed, %d skipped, %d todo", TAPResultsName, passed, failed, skipped, todo),
+		Timestamp: timestamp.UTC().Format(time.RFC3339),
+	}
+	switch {
+	case report.BailedOut:
+		testOutput.Result = AppStudioTestOutputError
+		testOutput.Note = fmt.Sprintf("Bail out! %s", report.BailOutReason)
+	case !report.IsComplete():
+		testOutput.Result = AppStudioTestOutputError
+		testOutput.Note = fmt.Sprintf("Planned %d tests but ran %d", report.Planned, len(report.TestPoints))
+	case failed > 0:
+		testOutput.Result = AppStudioTestOutputFailure
+	case todo > 0:
+		testOutput.Result = AppStudioTestOutputWarning
+	case passed == 0 && skipped > 0:
+		testOutput.Result = AppStudioTestOutputSkipped
+	default:
+		testOutput.Result = AppStudioTestOutputSuccess
+	}
+
+	testResult := &IntegrationTestTaskResult{TestOutput: testOutput}
+	for _, tp := range report.GetFailedTestPoints() {
+		name := tp.Description
+		if name == "" {
+			name = fmt.Sprintf("test %d", tp.Number)
+		}
+		testResult.FailedTestCases = append(testResult.FailedTestCases, intgteststat.TestCaseDetail{
+			Name:   name,
+			Status: junit.TestCaseStatusFailure,
+		})
+	}
+	return testResult
+}
+
+// GetJUnitResultsReference returns the value of the JUnitResultsName result of the TaskRun,
+// an empty string is returned when the TaskRun didn't produce it.
+func (t *TaskRun) GetJUnitResultsReference() string {
+	for _, taskRunResult := range t.trStatus.TaskRunStatusFields.Results {
+		if taskRunResult.Name == JUnitResultsName {
+			return taskRunResult.Value.StringVal
+		}
+	}
+	return ""
+}
+
+// GetTestOutputNames returns the names of the task results which are parsed as the Test output for the given
+// Integration PipelineRun. The TestOutputNameAnnotation of the PipelineRun takes precedence over the name configured
+// through the TestOutputNameEnvVar, if neither is set, both the TestOutputName and LegacyTestOutputName are used.
+func GetTestOutputNames(pipelineRun *tektonv1.PipelineRun) []string {
+	if pipelineRun != nil {
+		if name := pipelineRun.GetAnnotations()[TestOutputNameAnnotation]; name != "" {
+			return []string{name}
+		}
+	}
+	if name := os.Getenv(TestOutputNameEnvVar); name != "" {
+		return []string{name}
+	}
+
+	return []string{TestOutputName, LegacyTestOutputName}
+}
+
 // SortTaskRunsByStartTime can sort TaskRuns by their start time. It implements sort.Interface.
 type SortTaskRunsByStartTime []*TaskRun
 
@@ -218,6 +413,10 @@ type IntegrationPipelineRunOutcome struct {
 	pipelineRun          *tektonv1.PipelineRun
 	// map: task name to results
 	results map[string]*IntegrationTestTaskResult
+	// map: task name to JUnit test cases
+	testCases map[string][]junit.TestCase
+	// map: task name to errors encountered while loading the JUnit test cases
+	testCasesErrors map[string]error
 }
 
 // HasPipelineRunSucceeded returns true when pipeline in outcome succeeded
@@ -265,6 +464,107 @@ func (ipro *IntegrationPipelineRunOutcome) HasPipelineRunPassedTesting() bool {
 	return true
 }
 
+// HasPipelineRunTestWarnings returns true when any of the tasks reported the WARNING result in their TEST_OUTPUT
+func (ipro *IntegrationPipelineRunOutcome) HasPipelineRunTestWarnings() bool {
+	return ipro.hasTestOutputResult(AppStudioTestOutputWarning)
+}
+
+// HasPipelineRunTestErrors returns true when any of the tasks reported the ERROR result in their TEST_OUTPUT,
+// which indicates a failure of the test infrastructure rather than a failure of the tests themselves
+func (ipro *IntegrationPipelineRunOutcome) HasPipelineRunTestErrors() bool {
+	return ipro.hasTestOutputResult(AppStudioTestOutputError)
+}
+
+// hasTestOutputResult returns true when any of the tasks reported the given result in their TEST_OUTPUT
+func (ipro *IntegrationPipelineRunOutcome) hasTestOutputResult(testOutputResult string) bool {
+	for _, result := range ipro.results {
+		if result.TestOutput != nil && result.TestOutput.Result == testOutputResult {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPipelineRunFailuresWithinThreshold returns true when the pipelineRun succeeded with valid test outputs
+// which didn't report any ERROR result, and the number of failing test cases across all of them is at most maxAllowedFailures
+func (ipro *IntegrationPipelineRunOutcome) HasPipelineRunFailuresWithinThreshold(maxAllowedFailures int) bool {
+	if !ipro.HasPipelineRunSucceeded() || !ipro.HasPipelineRunValidTestOutputs() || ipro.HasPipelineRunTestErrors() {
+		return false
+	}
+	summary := ipro.GetTestResultsSummary()
+	if summary == nil {
+		return false
+	}
+	return summary.Failures <= maxAllowedFailures
+}
+
+// GetTestResultsSummary returns the test case counts aggregated across the valid test outputs of all tasks,
+// together with the failed test cases reported in their JUnit results. The JUnit test cases are counted only
+// for the tasks without a test output. nil is returned when none of the tasks produced a valid test output
+// or JUnit results.
+func (ipro *IntegrationPipelineRunOutcome) GetTestResultsSummary() *intgteststat.TestResultsSummary {
+	var summary *intgteststat.TestResultsSummary
+	for _, result := range ipro.results {
+		if result.TestOutput == nil {
+			continue
+		}
+		if summary == nil {
+			summary = &intgteststat.TestResultsSummary{}
+		}
+		summary.Successes += result.TestOutput.Successes
+		summary.Failures += result.TestOutput.Failures
+		summary.Warnings += result.TestOutput.Warnings
+	}
+
+	resultTaskNames := make([]string, 0, len(ipro.results))
+	for taskName := range ipro.results {
+		resultTaskNames = append(resultTaskNames, taskName)
+	}
+	sort.Strings(resultTaskNames)
+	for _, taskName := range resultTaskNames {
+		for _, testCase := range ipro.results[taskName].FailedTestCases {
+			if len(summary.FailedTestCases) < maxFailedTestCases {
+				summary.FailedTestCases = append(summary.FailedTestCases, testCase)
+			}
+		}
+	}
+
+	taskNames := make([]string, 0, len(ipro.testCases))
+	for taskName := range ipro.testCases {
+		taskNames = append(taskNames, taskName)
+	}
+	sort.Strings(taskNames)
+	for _, taskName := range taskNames {
+		if summary == nil {
+			summary = &intgteststat.TestResultsSummary{}
+		}
+		result, hasTestOutput := ipro.results[taskName]
+		countTestCases := !hasTestOutput || result.TestOutput == nil
+		for _, testCase := range ipro.testCases[taskName] {
+			status := testCase.GetStatus()
+			if countTestCases {
+				switch status {
+				case junit.TestCaseStatusPassed:
+					summary.Successes++
+				case junit.TestCaseStatusFailure, junit.TestCaseStatusError:
+					summary.Failures++
+				}
+			}
+			if (status == junit.TestCaseStatusFailure || status == junit.TestCaseStatusError) && len(summary.FailedTestCases) < maxFailedTestCases {
+				summary.FailedTestCases = append(summary.FailedTestCases, intgteststat.TestCaseDetail{
+					Name:      testCase.Name,
+					ClassName: testCase.ClassName,
+					File:      testCase.File,
+					Line:      testCase.GetLine(),
+					Status:    status,
+					Message:   testCase.GetMessage(),
+				})
+			}
+		}
+	}
+	return summary
+}
+
 // LogResults writes tasks names with results into given logger, each task on separate line
 func (ipro *IntegrationPipelineRunOutcome) LogResults(logger logr.Logger) {
 	for k, v := range ipro.results {
@@ -280,6 +580,12 @@ func (ipro *IntegrationPipelineRunOutcome) LogResults(logger logr.Logger) {
 				"task.Name", k, "task.ValidationError", v.ValidationError.Error())
 		}
 	}
+	for k, v := range ipro.testCasesErrors {
+		logger.Info(fmt.Sprintf("Failed to load JUnit results for pipeline run %s", ipro.pipelineRun.Name),
+			"pipelineRun.Name", ipro.pipelineRun.Name,
+			"pipelineRun.Namespace", ipro.pipelineRun.Namespace,
+			"task.Name", k, "task.JUnitError", v.Error())
+	}
 }
 
 // GetIntegrationPipelineRunOutcome returns the IntegrationPipelineRunOutcome
@@ -295,25 +601,37 @@ func GetIntegrationPipelineRunOutcome(ctx context.Context, adapterClient client.
 			results:              map[string]*IntegrationTestTaskResult{},
 		}, nil
 	}
-	// Check if the pipelineRun.Status contains the childReferences to TaskRuns
-	if !reflect.ValueOf(pipelineRun.Status.ChildReferences).IsZero() {
-		// If the pipelineRun.Status contains the childReferences, parse them in the new way by querying for TaskRuns
-		results, err := GetIntegrationTestTaskResultsFromPipelineRunWithChildReferences(ctx, adapterClient, pipelineRun)
+	// Parse the results by querying for the child TaskRuns of the pipelineRun
+	taskRuns, err := GetAllChildTaskRunsForPipelineRun(ctx, adapterClient, pipelineRun)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting test results from pipelineRun %s: %w", pipelineRun.Name, err)
+	}
+	results, err := getIntegrationTestTaskResults(taskRuns)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting test results from pipelineRun %s: %w", pipelineRun.Name, err)
+	}
+
+	testCases := map[string][]junit.TestCase{}
+	testCasesErrors := map[string]error{}
+	for _, tr := range taskRuns {
+		reference := tr.GetJUnitResultsReference()
+		if reference == "" {
+			continue
+		}
+		taskTestCases, err := junit.Load(ctx, reference)
 		if err != nil {
-			return nil, fmt.Errorf("error while getting test results from pipelineRun %s: %w", pipelineRun.Name, err)
+			testCasesErrors[tr.GetPipelineTaskName()] = err
+			continue
 		}
-		return &IntegrationPipelineRunOutcome{
-			pipelineRunSucceeded: true,
-			pipelineRun:          pipelineRun,
-			results:              results,
-		}, nil
+		testCases[tr.GetPipelineTaskName()] = taskTestCases
 	}
 
-	// PLR passed but no results were found
 	return &IntegrationPipelineRunOutcome{
 		pipelineRunSucceeded: true,
 		pipelineRun:          pipelineRun,
-		results:              map[string]*IntegrationTestTaskResult{},
+		results:              results,
+		testCases:            testCases,
+		testCasesErrors:      testCasesErrors,
 	}, nil
 }
 
@@ -325,7 +643,12 @@ func GetIntegrationTestTaskResultsFromPipelineRunWithChildReferences(ctx context
 	if err != nil {
 		return nil, err
 	}
+	return getIntegrationTestTaskResults(taskRuns)
+}
 
+// getIntegrationTestTaskResults returns the parsed test outputs or validation errors of the given TaskRuns
+// returns map taskName: result
+func getIntegrationTestTaskResults(taskRuns []*TaskRun) (map[string]*IntegrationTestTaskResult, error) {
 	results := map[string]*IntegrationTestTaskResult{}
 	for _, tr := range taskRuns {
 		r, err := tr.GetTestResult()
@@ -341,29 +664,98 @@ func GetIntegrationTestTaskResultsFromPipelineRunWithChildReferences(ctx context
 
 // GetAllChildTaskRunsForPipelineRun finds all Child TaskRuns for a given PipelineRun and
 // returns integration TaskRun wrappers for them sorted by start time.
+// An error is returned for a PipelineRun which succeeded without childReferences nor skipped tasks, since its
+// TaskRuns can't be found and its results would be silently lost.
 func GetAllChildTaskRunsForPipelineRun(ctx context.Context, adapterClient client.Client, pipelineRun *tektonv1.PipelineRun) ([]*TaskRun, error) {
 	taskRuns := []*TaskRun{}
-	// If there are no childReferences, skip trying to get tasks
+	// If there are no childReferences, skip trying to get tasks. No v1beta1 fallback is needed: the PipelineRuns are
+	// always read as v1, whose status only references the child TaskRuns through the childReferences, and Tekton
+	// converts the status of the v1beta1 PipelineRuns created before the migration to v1 into childReferences as well
 	if reflect.ValueOf(pipelineRun.Status.ChildReferences).IsZero() {
+		if HasPipelineRunSucceeded(pipelineRun) && len(pipelineRun.Status.SkippedTasks) == 0 {
+			return nil, fmt.Errorf("pipelineRun %s succeeded without the childReferences of its taskRuns", pipelineRun.Name)
+		}
+		if HasPipelineRunFinished(pipelineRun) {
+			// the PipelineRuns failing before any TaskRun was created, e.g. when their Pipeline couldn't be found,
+			// or skipping all of their tasks don't have childReferences
+			ctrllog.FromContext(ctx).Info("PipelineRun finished without child TaskRuns",
+				"pipelineRun.Namespace", pipelineRun.Namespace, "pipelineRun.Name", pipelineRun.Name,
+				"reason", pipelineRun.Status.GetCondition(apis.ConditionSucceeded).GetReason())
+		}
 		return nil, nil
 	}
+	testOutputNames := GetTestOutputNames(pipelineRun)
 	for _, childReference := range pipelineRun.Status.ChildReferences {
 		pipelineTaskRun := &tektonv1.TaskRun{}
 		err := adapterClient.Get(ctx, types.NamespacedName{
 			Namespace: pipelineRun.Namespace,
 			Name:      childReference.Name,
 		}, pipelineTaskRun)
+		if k8serrors.IsNotFound(err) {
+			pipelineTaskRun, err = GetPrunedTaskRun(ctx, pipelineRun.Namespace, childReference.Name, err)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("error while getting the child taskRun %s from pipelineRun: %w", childReference.Name, err)
 		}
 
-		integrationTaskRun := NewTaskRunFromTektonTaskRun(childReference.PipelineTaskName, &pipelineTaskRun.Status)
+		integrationTaskRun := NewTaskRunFromTektonTaskRun(childReference.PipelineTaskName, &pipelineTaskRun.Status).
+			WithTestOutputNames(testOutputNames)
 		taskRuns = append(taskRuns, integrationTaskRun)
 	}
 	sort.Sort(SortTaskRunsByStartTime(taskRuns))
 	return taskRuns, nil
 }
 
+// tektonResultsClient is the client of the Tekton Results API the pruned PipelineRuns and TaskRuns are fetched from,
+// nil when the Tekton Results API isn't configured.
+var tektonResultsClient *tektonresults.Client
+
+// SetTektonResultsClient sets the client of the Tekton Results API the pruned PipelineRuns and TaskRuns are fetched
+// from, it's called once on startup so that the client is shared by all the lookups. A nil client disables the lookups.
+func SetTektonResultsClient(resultsClient *tektonresults.Client) {
+	tektonResultsClient = resultsClient
+}
+
+// GetPrunedTaskRun fetches the TaskRun which was pruned from the cluster from the Tekton Results API.
+// If the Tekton Results API isn't configured or doesn't hold a record of the TaskRun, notFoundErr is returned.
+func GetPrunedTaskRun(ctx context.Context, namespace, name string, notFoundErr error) (*tektonv1.TaskRun, error) {
+	if tektonResultsClient == nil {
+		return nil, notFoundErr
+	}
+
+	taskRun, err := tektonResultsClient.GetTaskRun(ctx, namespace, name)
+	if errors.Is(err, tektonresults.ErrRecordNotFound) {
+		return nil, notFoundErr
+	}
+
+	return taskRun, err
+}
+
+// GetPrunedPipelineRun fetches the PipelineRun which was pruned from the cluster from the Tekton Results API.
+// If the Tekton Results API isn't configured or doesn't hold a record of the PipelineRun, notFoundErr is returned.
+func GetPrunedPipelineRun(ctx context.Context, namespace, name string, notFoundErr error) (*tektonv1.PipelineRun, error) {
+	if tektonResultsClient == nil {
+		return nil, notFoundErr
+	}
+
+	pipelineRun, err := tektonResultsClient.GetPipelineRun(ctx, namespace, name)
+	if errors.Is(err, tektonresults.ErrRecordNotFound) {
+		return nil, notFoundErr
+	}
+
+	return pipelineRun, err
+}
+
+// GetPrunedPipelineRuns fetches the PipelineRuns with all of the given labels from the Tekton Results API, including
+// the ones which were pruned from the cluster. If the Tekton Results API isn't configured, no PipelineRun is returned.
+func GetPrunedPipelineRuns(ctx context.Context, namespace string, labels map[string]string) ([]tektonv1.PipelineRun, error) {
+	if tektonResultsClient == nil {
+		return nil, nil
+	}
+
+	return tektonResultsClient.ListPipelineRuns(ctx, namespace, labels)
+}
+
 // HasPipelineRunSucceeded returns a boolean indicating whether the PipelineRun succeeded or not.
 // If the object passed to this function is not a PipelineRun, the function will return false.
 func HasPipelineRunSucceeded(object client.Object) bool {
@@ -29,6 +29,7 @@ import (
 
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"github.com/tonglil/buflogr"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -680,6 +681,57 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(integrationTaskRun.GetTestResult()).To(BeNil())
 	})
 
+	It("can parse the Test output from a configured task result name", func() {
+		Expect(helpers.GetTestOutputNames(nil)).To(Equal([]string{helpers.TestOutputName, helpers.LegacyTestOutputName}))
+
+		GinkgoT().Setenv(helpers.TestOutputNameEnvVar, "CUSTOM_OUTPUT")
+		Expect(helpers.GetTestOutputNames(integrationPipelineRun)).To(Equal([]string{"CUSTOM_OUTPUT"}))
+		integrationTaskRun := helpers.NewTaskRunFromTektonTaskRun("task-success", &successfulTaskRun.Status)
+		Expect(integrationTaskRun.GetTestResult()).To(BeNil())
+
+		pipelineRun := integrationPipelineRun.DeepCopy()
+		pipelineRun.Annotations = map[string]string{helpers.TestOutputNameAnnotation: "TEST_OUTPUT"}
+		Expect(helpers.GetTestOutputNames(pipelineRun)).To(Equal([]string{"TEST_OUTPUT"}))
+		integrationTaskRun = helpers.NewTaskRunFromTektonTaskRun("task-success", &successfulTaskRun.Status).
+			WithTestOutputNames(helpers.GetTestOutputNames(pipelineRun))
+		result, err := integrationTaskRun.GetTestResult()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.TestOutput.Result).To(Equal(helpers.AppStudioTestOutputSuccess))
+	})
+
+	It("can map TAP output to the Test output of a task", func() {
+		tapTaskRunStatus := func(output string) *tektonv1.TaskRunStatus {
+			return &tektonv1.TaskRunStatus{
+				TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+					CompletionTime: &metav1.Time{Time: time.Date(2024, 5, 22, 6, 42, 21, 0, time.UTC)},
+					Results: []tektonv1.TaskRunResult{
+						{Name: helpers.TAPResultsName, Value: *tektonv1.NewStructuredValues(output)},
+					},
+				},
+			}
+		}
+
+		result, err := helpers.NewTaskRunFromTektonTaskRun("task-tap", tapTaskRunStatus("1..3\nok 1 - a\nnot ok 2 - b\nnot ok 3 - c # TODO later\n")).GetTestResult()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ValidationError).ToNot(HaveOccurred())
+		Expect(result.TestOutput.Result).To(Equal(helpers.AppStudioTestOutputFailure))
+		Expect(result.TestOutput.Timestamp).To(Equal("2024-05-22T06:42:21Z"))
+		Expect([]int{result.TestOutput.Successes, result.TestOutput.Failures, result.TestOutput.Warnings}).To(Equal([]int{1, 1, 1}))
+		Expect(result.FailedTestCases).To(Equal([]intgteststat.TestCaseDetail{{Name: "b", Status: "failure"}}))
+
+		result, err = helpers.NewTaskRunFromTektonTaskRun("task-tap", tapTaskRunStatus("1..2\nok 1\nok 2 # skip\n")).GetTestResult()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.TestOutput.Result).To(Equal(helpers.AppStudioTestOutputSuccess))
+
+		result, err = helpers.NewTaskRunFromTektonTaskRun("task-tap", tapTaskRunStatus("1..2\nok 1\n")).GetTestResult()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.TestOutput.Result).To(Equal(helpers.AppStudioTestOutputError))
+
+		result, err = helpers.NewTaskRunFromTektonTaskRun("task-tap", tapTaskRunStatus("no tests were run")).GetTestResult()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ValidationError).To(MatchError(ContainSubstring("error parsing results from task task-tap result TAP_RESULTS")))
+	})
+
 	It("ensures multiple task pipelinerun outcome when AppStudio Tests succeeded", func() {
 		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
 			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
@@ -711,6 +763,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(pipelineRunOutcome.HasPipelineRunPassedTesting()).To(BeTrue())
 		Expect(pipelineRunOutcome.HasPipelineRunValidTestOutputs()).To(BeTrue())
 		Expect(pipelineRunOutcome.GetValidationErrorsList()).Should(BeEmpty())
+		Expect(pipelineRunOutcome.GetTestResultsSummary()).To(Equal(&intgteststat.TestResultsSummary{Successes: 10}))
 
 		err = gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "test passed")
 		Expect(err).To(Succeed())
@@ -747,6 +800,8 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(err).To(BeNil())
 		Expect(pipelineRunOutcome.HasPipelineRunPassedTesting()).To(BeTrue())
 		Expect(pipelineRunOutcome.HasPipelineRunValidTestOutputs()).To(BeTrue())
+		Expect(pipelineRunOutcome.HasPipelineRunTestWarnings()).To(BeTrue())
+		Expect(pipelineRunOutcome.HasPipelineRunTestErrors()).To(BeFalse())
 		Expect(pipelineRunOutcome.GetValidationErrorsList()).Should(BeEmpty())
 
 		err = gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "test passed")
@@ -848,6 +903,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(pipelineRunOutcome.HasPipelineRunPassedTesting()).To(BeFalse())
 		Expect(pipelineRunOutcome.HasPipelineRunValidTestOutputs()).To(BeTrue())
 		Expect(pipelineRunOutcome.GetValidationErrorsList()).Should(BeEmpty())
+		Expect(pipelineRunOutcome.GetTestResultsSummary()).To(Equal(&intgteststat.TestResultsSummary{Failures: 1}))
 
 		pipelineRunOutcome.LogResults(buflogr.NewWithBuffer(&buf))
 		expectedLogEntry := "Found task results for pipeline run"
@@ -855,6 +911,82 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 
 	})
 
+	It("collects the failed test cases from the JUnit results of the tasks", func() {
+		junitTaskRun := &tektonv1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-taskrun-junit",
+				Namespace: "default",
+			},
+			Spec: successfulTaskRun.Spec,
+		}
+		Expect(k8sClient.Create(ctx, junitTaskRun)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, junitTaskRun)).Should(Succeed())
+		}()
+		junitTaskRun.Status = tektonv1.TaskRunStatus{
+			TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+				Results: []tektonv1.TaskRunResult{
+					{
+						Name: helpers.JUnitResultsName,
+						Value: *tektonv1.NewStructuredValues(`<testsuite name="e2e">
+							<testcase name="TestPass" classname="e2e"/>
+							<testcase name="TestFail" classname="e2e" file="test/e2e_test.go" line="12"><failure message="timed out"/></testcase>
+						</testsuite>`),
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Status().Update(ctx, junitTaskRun)).Should(Succeed())
+		// the TaskRun is read through the cache by GetIntegrationPipelineRunOutcome
+		Eventually(func() []tektonv1.TaskRunResult {
+			cachedTaskRun := &tektonv1.TaskRun{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(junitTaskRun), cachedTaskRun)).To(Succeed())
+			return cachedTaskRun.Status.Results
+		}, time.Second*10).ShouldNot(BeEmpty())
+
+		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				ChildReferences: []tektonv1.ChildStatusReference{
+					{
+						Name:             successfulTaskRun.Name,
+						PipelineTaskName: "pipeline1-task1",
+					},
+					{
+						Name:             junitTaskRun.Name,
+						PipelineTaskName: "pipeline1-task2",
+					},
+				},
+			},
+			Status: v1.Status{
+				Conditions: v1.Conditions{
+					apis.Condition{
+						Reason: "Completed",
+						Status: "True",
+						Type:   apis.ConditionSucceeded,
+					},
+				},
+			},
+		}
+
+		pipelineRunOutcome, err := helpers.GetIntegrationPipelineRunOutcome(ctx, k8sClient, integrationPipelineRun)
+		Expect(err).To(BeNil())
+		Expect(pipelineRunOutcome.HasPipelineRunPassedTesting()).To(BeTrue())
+		Expect(pipelineRunOutcome.GetTestResultsSummary()).To(Equal(&intgteststat.TestResultsSummary{
+			Successes: 11,
+			Failures:  1,
+			FailedTestCases: []intgteststat.TestCaseDetail{
+				{
+					Name:      "TestFail",
+					ClassName: "e2e",
+					File:      "test/e2e_test.go",
+					Line:      12,
+					Status:    "failure",
+					Message:   "timed out",
+				},
+			},
+		}))
+	})
+
 	It("ensure No Task pipelinerun passed when AppStudio Tests passed", func() {
 
 		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
@@ -970,6 +1102,26 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
 	})
 
+	It("can validate TEST_OUTPUT against the versioned schema", func() {
+		testOutput, err := helpers.ValidateTestOutput([]byte(`{"result": "SUCCESS", "timestamp": "1665405318", "successes": 1, "failures": 0, "warnings": 0}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testOutput.Result).To(Equal(helpers.AppStudioTestOutputSuccess))
+
+		testOutput, err = helpers.ValidateTestOutput([]byte(`{"version": "v1", "result": "FAILURE", "timestamp": "1665405318", "successes": 0, "failures": 1, "warnings": 0, "note": "failed"}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testOutput.Version).To(Equal(helpers.TestOutputSchemaVersionV1))
+		Expect(testOutput.Note).To(Equal("failed"))
+
+		_, err = helpers.ValidateTestOutput([]byte(`{"version": "v0", "result": "SUCCESS", "timestamp": "1665405318", "successes": 1, "failures": 0, "warnings": 0}`))
+		Expect(err).To(MatchError(ContainSubstring(`schema version "v0" is not supported`)))
+
+		_, err = helpers.ValidateTestOutput([]byte(`{"result": "SUCCESS", "timestamp": "1665405318"}`))
+		Expect(err).To(MatchError(ContainSubstring("doesn't match the v1 schema")))
+
+		_, err = helpers.ValidateTestOutput([]byte(`{"result": "SUCCESS"`))
+		Expect(err).To(MatchError(ContainSubstring("not valid JSON")))
+	})
+
 	It("can get all the TaskRuns for a PipelineRun with childReferences", func() {
 		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
 			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
@@ -1036,6 +1188,66 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		Expect(taskRuns).To(BeNil())
 	})
 
+	It("returns nil for a finished PipelineRun which didn't create any TaskRun", func() {
+		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
+			Status: v1.Status{
+				Conditions: v1.Conditions{
+					apis.Condition{
+						Reason: "CouldntGetPipeline",
+						Status: "False",
+						Type:   apis.ConditionSucceeded,
+					},
+				},
+			},
+		}
+
+		taskRuns, err := helpers.GetAllChildTaskRunsForPipelineRun(ctx, k8sClient, integrationPipelineRun)
+		Expect(err).To(BeNil())
+		Expect(taskRuns).To(BeNil())
+
+		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
+			PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+				SkippedTasks: []tektonv1.SkippedTask{
+					{
+						Name:   "pipeline1-task1",
+						Reason: tektonv1.WhenExpressionsSkip,
+					},
+				},
+			},
+			Status: v1.Status{
+				Conditions: v1.Conditions{
+					apis.Condition{
+						Reason: "Completed",
+						Status: "True",
+						Type:   apis.ConditionSucceeded,
+					},
+				},
+			},
+		}
+
+		taskRuns, err = helpers.GetAllChildTaskRunsForPipelineRun(ctx, k8sClient, integrationPipelineRun)
+		Expect(err).To(BeNil())
+		Expect(taskRuns).To(BeNil())
+	})
+
+	It("returns an error for a succeeded PipelineRun with no childReferences", func() {
+		integrationPipelineRun.Status = tektonv1.PipelineRunStatus{
+			Status: v1.Status{
+				Conditions: v1.Conditions{
+					apis.Condition{
+						Reason: "Succeeded",
+						Status: "True",
+						Type:   apis.ConditionSucceeded,
+					},
+				},
+			},
+		}
+
+		taskRuns, err := helpers.GetAllChildTaskRunsForPipelineRun(ctx, k8sClient, integrationPipelineRun)
+		Expect(err).To(MatchError(ContainSubstring("succeeded without the childReferences of its taskRuns")))
+		Expect(taskRuns).To(BeNil())
+	})
+
 	It("can remove finalizer from an IntegrationTestScenario", func() {
 		var buf bytes.Buffer
 
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultListPageSize is the number of objects requested in each page by ListWithPagination.
+const DefaultListPageSize = 250
+
+// ListWithPagination lists the objects matching the given options page by page, using the Limit and Continue list
+// options, and calls processPage after each of the pages is read into the list. The list holds only the objects of
+// the current page, so the memory used doesn't grow with the number of listed objects.
+// The client has to read from the API server, the informer cache of the manager doesn't support the Continue option
+// and would silently truncate the results to a single page.
+func ListWithPagination(ctx context.Context, c client.Reader, list client.ObjectList, pageSize int64, processPage func() error, opts ...client.ListOption) error {
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+
+	continueToken := ""
+	for {
+		pageOpts := make([]client.ListOption, 0, len(opts)+2)
+		pageOpts = append(pageOpts, opts...)
+		pageOpts = append(pageOpts, client.Limit(pageSize), client.Continue(continueToken))
+		if err := c.List(ctx, list, pageOpts...); err != nil {
+			return err
+		}
+		if err := processPage(); err != nil {
+			return err
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
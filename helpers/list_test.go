@@ -0,0 +1,108 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Paginated List", func() {
+
+	var (
+		pagedClient client.Client
+		listOptions []client.ListOptions
+	)
+
+	BeforeEach(func() {
+		listOptions = []client.ListOptions{}
+		// the fake client doesn't paginate, so the pages of five snapshots are served by the interceptor
+		pagedClient = fake.NewClientBuilder().
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					options := client.ListOptions{}
+					options.ApplyOptions(opts)
+					listOptions = append(listOptions, options)
+
+					start := 0
+					if options.Continue != "" {
+						start, _ = strconv.Atoi(options.Continue)
+					}
+					snapshots := list.(*applicationapiv1alpha1.SnapshotList)
+					snapshots.Items = []applicationapiv1alpha1.Snapshot{}
+					for i := start; i < 5 && int64(i-start) < options.Limit; i++ {
+						snapshots.Items = append(snapshots.Items, applicationapiv1alpha1.Snapshot{
+							ObjectMeta: metav1.ObjectMeta{Name: "snapshot-" + strconv.Itoa(i), Namespace: options.Namespace},
+						})
+					}
+					snapshots.Continue = ""
+					if next := start + len(snapshots.Items); next < 5 {
+						snapshots.Continue = strconv.Itoa(next)
+					}
+					return nil
+				},
+			}).
+			Build()
+	})
+
+	It("lists the objects page by page", func() {
+		names := []string{}
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		err := helpers.ListWithPagination(context.Background(), pagedClient, snapshots, 2, func() error {
+			Expect(len(snapshots.Items)).To(BeNumerically("<=", 2))
+			for _, snapshot := range snapshots.Items {
+				names = append(names, snapshot.Name)
+			}
+			return nil
+		}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(names).To(Equal([]string{"snapshot-0", "snapshot-1", "snapshot-2", "snapshot-3", "snapshot-4"}))
+		Expect(listOptions).To(HaveLen(3))
+		Expect(listOptions[0].Namespace).To(Equal("default"))
+		Expect(listOptions[0].Continue).To(BeEmpty())
+		Expect(listOptions[2].Continue).To(Equal("4"))
+	})
+
+	It("uses the default page size when none is given", func() {
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		err := helpers.ListWithPagination(context.Background(), pagedClient, snapshots, 0, func() error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listOptions).To(HaveLen(1))
+		Expect(listOptions[0].Limit).To(Equal(int64(helpers.DefaultListPageSize)))
+	})
+
+	It("stops listing when a page fails to be processed", func() {
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		err := helpers.ListWithPagination(context.Background(), pagedClient, snapshots, 2, func() error {
+			return errors.New("failed to process the page")
+		})
+		Expect(err).To(MatchError("failed to process the page"))
+		Expect(listOptions).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// NamespaceScope describes the namespaces watched by the controllers, so the integration-service instances of a
+// shared cluster can be sharded by tenant group.
+type NamespaceScope struct {
+	// Namespaces are the only namespaces watched, all namespaces are watched when empty.
+	Namespaces []string
+
+	// IgnoredNamespaces are the namespaces which aren't watched.
+	IgnoredNamespaces []string
+
+	// Selector selects the labels of the watched namespaces, it is nil when the namespaces aren't selected by labels.
+	Selector labels.Selector
+}
+
+// NewNamespaceScope creates and returns a NamespaceScope from the comma separated lists of the watched and ignored
+// namespaces and the label selector of the watched namespaces, any of which may be empty.
+func NewNamespaceScope(namespaces, ignoredNamespaces, selector string) (*NamespaceScope, error) {
+	scope := &NamespaceScope{
+		Namespaces:        splitNamespaces(namespaces),
+		IgnoredNamespaces: splitNamespaces(ignoredNamespaces),
+	}
+	for _, namespace := range scope.Namespaces {
+		if slices.Contains(scope.IgnoredNamespaces, namespace) {
+			return nil, fmt.Errorf("the namespace %s can't be both watched and ignored", namespace)
+		}
+	}
+
+	if strings.TrimSpace(selector) != "" {
+		labelSelector, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the namespace label selector %q: %w", selector, err)
+		}
+		scope.Selector = labelSelector
+	}
+
+	return scope, nil
+}
+
+// CacheOptions returns the options of the manager cache, caching only the objects of the watched namespaces.
+// The Secrets of the shared namespaces, e.g. the namespace of the integration service holding the credentials of
+// its GitHub app, are cached even when they aren't watched. The options have no namespaces set when all namespaces
+// are watched.
+func (s *NamespaceScope) CacheOptions(sharedNamespaces ...string) cache.Options {
+	options := cache.Options{
+		DefaultNamespaces: s.cacheNamespaces(nil),
+	}
+	if options.DefaultNamespaces != nil && len(sharedNamespaces) > 0 {
+		secretNamespaces := s.cacheNamespaces(sharedNamespaces)
+		if secretNamespaces == nil {
+			// an empty map caches the Secrets of all namespaces, instead of the default ones
+			secretNamespaces = map[string]cache.Config{}
+		}
+		options.ByObject = map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {Namespaces: secretNamespaces},
+		}
+	}
+
+	return options
+}
+
+// cacheNamespaces returns the cache configurations of the watched namespaces and the additional ones, or nil when
+// all namespaces are watched. The ignored namespaces are excluded from the cache through a field selector.
+func (s *NamespaceScope) cacheNamespaces(additionalNamespaces []string) map[string]cache.Config {
+	if len(s.Namespaces) > 0 {
+		namespaces := map[string]cache.Config{}
+		for _, namespace := range s.Namespaces {
+			namespaces[namespace] = cache.Config{}
+		}
+		for _, namespace := range additionalNamespaces {
+			namespaces[namespace] = cache.Config{}
+		}
+		return namespaces
+	}
+
+	selectors := []fields.Selector{}
+	for _, namespace := range s.IgnoredNamespaces {
+		if !slices.Contains(additionalNamespaces, namespace) {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+		}
+	}
+	if len(selectors) == 0 {
+		return nil
+	}
+	return map[string]cache.Config{
+		cache.AllNamespaces: {FieldSelector: fields.AndSelectors(selectors...)},
+	}
+}
+
+// Predicate returns a predicate which filters out the objects of the namespaces whose labels aren't matched by the
+// selector of the scope, reading the namespaces through the given reader. All objects pass the filter when the
+// scope has no selector.
+func (s *NamespaceScope) Predicate(reader client.Reader) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		if s.Selector == nil || s.Selector.Empty() {
+			return true
+		}
+
+		namespace := &corev1.Namespace{}
+		if err := reader.Get(context.Background(), client.ObjectKey{Name: object.GetNamespace()}, namespace); err != nil {
+			return false
+		}
+		return s.Selector.Matches(labels.Set(namespace.GetLabels()))
+	})
+}
+
+// splitNamespaces splits the comma separated list of namespaces, dropping the empty entries.
+func splitNamespaces(namespaces string) []string {
+	result := []string{}
+	for _, namespace := range strings.Split(namespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			result = append(result, namespace)
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Namespace scope", func() {
+
+	It("watches all namespaces when nothing is configured", func() {
+		scope, err := helpers.NewNamespaceScope("", " ", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scope.Namespaces).To(BeEmpty())
+		Expect(scope.IgnoredNamespaces).To(BeEmpty())
+		Expect(scope.Selector).To(BeNil())
+
+		options := scope.CacheOptions("integration-service")
+		Expect(options.DefaultNamespaces).To(BeNil())
+		Expect(options.ByObject).To(BeNil())
+	})
+
+	It("caches only the watched namespaces and the Secrets of the shared ones", func() {
+		scope, err := helpers.NewNamespaceScope("tenant-a, tenant-b,", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scope.Namespaces).To(Equal([]string{"tenant-a", "tenant-b"}))
+
+		options := scope.CacheOptions("integration-service")
+		Expect(options.DefaultNamespaces).To(HaveLen(2))
+		Expect(options.DefaultNamespaces).To(HaveKey("tenant-a"))
+		Expect(options.DefaultNamespaces).To(HaveKey("tenant-b"))
+		Expect(options.ByObject).To(HaveLen(1))
+		for object, byObject := range options.ByObject {
+			Expect(object).To(BeAssignableToTypeOf(&corev1.Secret{}))
+			Expect(byObject.Namespaces).To(HaveLen(3))
+			Expect(byObject.Namespaces).To(HaveKey("integration-service"))
+		}
+	})
+
+	It("excludes the ignored namespaces from the cache", func() {
+		scope, err := helpers.NewNamespaceScope("", "tenant-c,integration-service", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		options := scope.CacheOptions("integration-service")
+		Expect(options.DefaultNamespaces).To(HaveLen(1))
+		Expect(options.DefaultNamespaces[cache.AllNamespaces].FieldSelector.String()).
+			To(Equal("metadata.namespace!=tenant-c,metadata.namespace!=integration-service"))
+		for _, byObject := range options.ByObject {
+			Expect(byObject.Namespaces[cache.AllNamespaces].FieldSelector.String()).To(Equal("metadata.namespace!=tenant-c"))
+		}
+	})
+
+	It("fails to create a scope watching and ignoring the same namespace", func() {
+		_, err := helpers.NewNamespaceScope("tenant-a", "tenant-a", "")
+		Expect(err).To(MatchError("the namespace tenant-a can't be both watched and ignored"))
+	})
+
+	It("fails to create a scope with an invalid label selector", func() {
+		_, err := helpers.NewNamespaceScope("", "", "tenant-group in (a")
+		Expect(err).To(MatchError(ContainSubstring("failed to parse the namespace label selector")))
+	})
+
+	It("filters out the objects of the namespaces which aren't selected", func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant-group": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Labels: map[string]string{"tenant-group": "b"}}},
+		).Build()
+
+		scope, err := helpers.NewNamespaceScope("", "", "tenant-group=a")
+		Expect(err).NotTo(HaveOccurred())
+		instance := scope.Predicate(reader)
+
+		snapshot := func(namespace string) *applicationapiv1alpha1.Snapshot {
+			return &applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-sample", Namespace: namespace}}
+		}
+		Expect(instance.Create(event.CreateEvent{Object: snapshot("tenant-a")})).To(BeTrue())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: snapshot("tenant-b"), ObjectNew: snapshot("tenant-b")})).To(BeFalse())
+		Expect(instance.Delete(event.DeleteEvent{Object: snapshot("missing")})).To(BeFalse())
+	})
+
+	It("lets all objects through without a label selector", func() {
+		scope, err := helpers.NewNamespaceScope("tenant-a", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		instance := scope.Predicate(fake.NewClientBuilder().Build())
+
+		Expect(instance.Generic(event.GenericEvent{Object: &corev1.Secret{}})).To(BeTrue())
+	})
+})
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// IntegrationServiceFieldManager is the field manager of the changes server-side applied by the integration service.
+const IntegrationServiceFieldManager = "integration-service"
+
+// ApplyStatusConditionWithRetry sets the given conditions in the conditions of the given object returned by
+// getConditions, and server-side applies the conditions to its status as the IntegrationServiceFieldManager. As the
+// conditions are an atomic list in the CRDs, the whole list is applied along with the resourceVersion of the object, so
+// that the conditions set by the other controllers in the meantime aren't overwritten: the object is fetched again and
+// the conditions re-set on conflicts, with bounded retries and backoffs long enough for the cache of the client to
+// catch up. The object holds the applied object once this function returns.
+func ApplyStatusConditionWithRetry(ctx context.Context, c client.Client, object client.Object, getConditions func() *[]metav1.Condition, conditions ...metav1.Condition) error {
+	gvk, err := apiutil.GVKForObject(object, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	refetch := false
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if refetch {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+				return err
+			}
+		}
+		refetch = true
+
+		objectConditions := getConditions()
+		for _, condition := range conditions {
+			meta.SetStatusCondition(objectConditions, condition)
+		}
+		value, err := json.Marshal(objectConditions)
+		if err != nil {
+			return err
+		}
+		appliedConditions := []interface{}{}
+		if err := json.Unmarshal(value, &appliedConditions); err != nil {
+			return err
+		}
+
+		applied := &unstructured.Unstructured{}
+		applied.SetGroupVersionKind(gvk)
+		applied.SetNamespace(object.GetNamespace())
+		applied.SetName(object.GetName())
+		applied.SetResourceVersion(object.GetResourceVersion())
+		if err := unstructured.SetNestedSlice(applied.Object, appliedConditions, "status", "conditions"); err != nil {
+			return err
+		}
+
+		err = c.Status().Patch(ctx, applied, client.Apply, client.FieldOwner(IntegrationServiceFieldManager), client.ForceOwnership)
+		if err != nil {
+			return err
+		}
+		return runtime.DefaultUnstructuredConverter.FromUnstructured(applied.UnstructuredContent(), object)
+	})
+}
+
+// ApplyMetadataWithOptimisticLock server-side applies the given labels and annotations like ApplyMetadata, along with
+// the resourceVersion of the object, so that a Conflict error is returned when the object was changed since it was
+// read. It's used for the annotations whose value is computed from the object, so that they can be re-computed.
+func ApplyMetadataWithOptimisticLock(ctx context.Context, c client.Client, object client.Object, labels, annotations map[string]string) error {
+	return applyMetadata(ctx, c, object, labels, annotations, object.GetResourceVersion())
+}
+
+// ApplyMetadata server-side applies the given labels and annotations to the given object as the
+// IntegrationServiceFieldManager, so that the integration service only owns these keys, and neither overwrites the
+// labels and annotations owned by the other controllers nor has its own overwritten by them. The labels and
+// annotations previously applied by the integration service are applied again with their current values, as the
+// apply would otherwise remove them from the object. The UID of the object is applied as a precondition, so that the
+// apply can't create the object once it was deleted: a NotFound error is returned instead.
+// The object holds the applied object once this function returns.
+func ApplyMetadata(ctx context.Context, c client.Client, object client.Object, labels, annotations map[string]string) error {
+	return applyMetadata(ctx, c, object, labels, annotations, "")
+}
+
+// applyMetadata server-side applies the given labels and annotations as described by ApplyMetadata, with the given
+// resourceVersion unless it's empty.
+func applyMetadata(ctx context.Context, c client.Client, object client.Object, labels, annotations map[string]string, resourceVersion string) error {
+	gvk, err := apiutil.GVKForObject(object, c.Scheme())
+	if err != nil {
+		return err
+	}
+	notFoundErr := k8serrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, object.GetName())
+
+	uid := object.GetUID()
+	if uid == "" {
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(gvk)
+		if err := c.Get(ctx, client.ObjectKeyFromObject(object), current); err != nil {
+			return err
+		}
+		uid = current.GetUID()
+	}
+
+	appliedLabels, appliedAnnotations, err := getAppliedMetadata(object)
+	if err != nil {
+		return err
+	}
+	for key, value := range labels {
+		appliedLabels[key] = value
+	}
+	for key, value := range annotations {
+		appliedAnnotations[key] = value
+	}
+
+	applied := &unstructured.Unstructured{}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(object.GetNamespace())
+	applied.SetName(object.GetName())
+	applied.SetUID(uid)
+	applied.SetResourceVersion(resourceVersion)
+	if len(appliedLabels) > 0 {
+		applied.SetLabels(appliedLabels)
+	}
+	if len(appliedAnnotations) > 0 {
+		applied.SetAnnotations(appliedAnnotations)
+	}
+
+	err = c.Patch(ctx, applied, client.Apply, client.FieldOwner(IntegrationServiceFieldManager), client.ForceOwnership)
+	if isUIDPreconditionError(err) && resourceVersion == "" {
+		return notFoundErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applied.UnstructuredContent(), object)
+}
+
+// isUIDPreconditionError returns true if the apply of an object with its UID failed since the object with the UID
+// doesn't exist anymore: the API server returns a Conflict when no object has the name, and rejects the change of
+// the UID when the object was re-created with the same name.
+func isUIDPreconditionError(err error) bool {
+	if k8serrors.IsConflict(err) {
+		return true
+	}
+	if !k8serrors.IsInvalid(err) {
+		return false
+	}
+
+	var statusErr *k8serrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return false
+	}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Field == "metadata.uid" {
+			return true
+		}
+	}
+	return false
+}
+
+// getAppliedMetadata returns the current values of the labels and annotations of the object which were server-side
+// applied by the IntegrationServiceFieldManager, as recorded in the managed fields of the object.
+func getAppliedMetadata(object client.Object) (map[string]string, map[string]string, error) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	for _, entry := range object.GetManagedFields() {
+		if entry.Manager != IntegrationServiceFieldManager || entry.Operation != metav1.ManagedFieldsOperationApply ||
+			entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+
+		fields := map[string]map[string]map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, nil, fmt.Errorf("failed to read the fields applied to %s: %w", object.GetName(), err)
+		}
+		copyAppliedKeys(fields["f:metadata"]["f:labels"], object.GetLabels(), labels)
+		copyAppliedKeys(fields["f:metadata"]["f:annotations"], object.GetAnnotations(), annotations)
+	}
+
+	return labels, annotations, nil
+}
+
+// copyAppliedKeys copies the current values of the applied keys found in the given managed fields into applied.
+func copyAppliedKeys(fields map[string]interface{}, current, applied map[string]string) {
+	for field := range fields {
+		key, ok := strings.CutPrefix(field, "f:")
+		if value, found := current[key]; ok && found {
+			applied[key] = value
+		}
+	}
+}
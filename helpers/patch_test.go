@@ -0,0 +1,183 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Status patches with retries", func() {
+
+	var (
+		ctx      context.Context
+		snapshot *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "snapshot-sample", Namespace: "default"},
+			Spec:       applicationapiv1alpha1.SnapshotSpec{Application: "application-sample"},
+		}
+	})
+
+	It("doesn't retry the applies failing for other reasons than conflicts", func() {
+		applies := 0
+		fakeClient := fake.NewClientBuilder().WithObjects(snapshot).WithStatusSubresource(snapshot).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					applies++
+					return errors.New("apply failed")
+				},
+			}).
+			Build()
+
+		err := helpers.ApplyStatusConditionWithRetry(ctx, fakeClient, snapshot, func() *[]metav1.Condition {
+			return &snapshot.Status.Conditions
+		}, metav1.Condition{Type: "Applied", Status: metav1.ConditionTrue, Reason: "Set"})
+		Expect(err).To(MatchError("apply failed"))
+		Expect(applies).To(Equal(1))
+	})
+
+	Context("when server-side applying labels and annotations", func() {
+		var appliedSnapshot *applicationapiv1alpha1.Snapshot
+
+		BeforeEach(func() {
+			appliedSnapshot = &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "snapshot-applied",
+					Namespace:   "default",
+					Labels:      map[string]string{"other-controller/label": "other"},
+					Annotations: map[string]string{"other-controller/annotation": "other"},
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Application: "application-sample",
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						{Name: "component-sample", ContainerImage: "quay.io/redhat-appstudio/sample-image"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, appliedSnapshot)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, appliedSnapshot)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("only owns the applied keys and keeps the ones applied previously", func() {
+			Expect(helpers.ApplyMetadata(ctx, k8sClient, appliedSnapshot,
+				map[string]string{"integration/label": "first"}, nil)).To(Succeed())
+			Expect(appliedSnapshot.Labels).To(HaveKeyWithValue("integration/label", "first"))
+
+			Expect(helpers.ApplyMetadata(ctx, k8sClient, appliedSnapshot,
+				nil, map[string]string{"integration/annotation": "second"})).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				snapshot := &applicationapiv1alpha1.Snapshot{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(appliedSnapshot), snapshot)).To(Succeed())
+				g.Expect(snapshot.Labels).To(HaveKeyWithValue("integration/label", "first"))
+				g.Expect(snapshot.Labels).To(HaveKeyWithValue("other-controller/label", "other"))
+				g.Expect(snapshot.Annotations).To(HaveKeyWithValue("integration/annotation", "second"))
+				g.Expect(snapshot.Annotations).To(HaveKeyWithValue("other-controller/annotation", "other"))
+			}).Should(Succeed())
+		})
+
+		It("doesn't create the deleted objects", func() {
+			Expect(k8sClient.Delete(ctx, appliedSnapshot)).To(Succeed())
+
+			err := helpers.ApplyMetadata(ctx, k8sClient, appliedSnapshot, map[string]string{"integration/label": "first"}, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			Eventually(func() bool {
+				snapshot := &applicationapiv1alpha1.Snapshot{}
+				return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(appliedSnapshot), snapshot))
+			}).Should(BeTrue())
+		})
+
+		It("doesn't apply to the objects re-created with the same name", func() {
+			deletedSnapshot := appliedSnapshot.DeepCopy()
+			Expect(k8sClient.Delete(ctx, appliedSnapshot)).To(Succeed())
+			appliedSnapshot = &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{Name: deletedSnapshot.Name, Namespace: deletedSnapshot.Namespace},
+				Spec:       deletedSnapshot.Spec,
+			}
+			Eventually(func() error {
+				return k8sClient.Create(ctx, appliedSnapshot)
+			}).Should(Succeed())
+
+			err := helpers.ApplyMetadata(ctx, k8sClient, deletedSnapshot, map[string]string{"integration/label": "first"}, nil)
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(appliedSnapshot), snapshot)).To(Succeed())
+			Expect(snapshot.UID).To(Equal(appliedSnapshot.UID))
+			Expect(snapshot.Labels).NotTo(HaveKey("integration/label"))
+		})
+
+		It("returns a conflict when applying to an outdated object with the optimistic lock", func() {
+			outdatedSnapshot := appliedSnapshot.DeepCopy()
+			Expect(helpers.ApplyMetadata(ctx, k8sClient, appliedSnapshot,
+				map[string]string{"integration/label": "first"}, nil)).To(Succeed())
+
+			err := helpers.ApplyMetadataWithOptimisticLock(ctx, k8sClient, outdatedSnapshot,
+				nil, map[string]string{"integration/annotation": "second"})
+			Expect(err).To(HaveOccurred())
+			Expect(k8serrors.IsConflict(err)).To(BeTrue())
+
+			Expect(helpers.ApplyMetadataWithOptimisticLock(ctx, k8sClient, appliedSnapshot,
+				nil, map[string]string{"integration/annotation": "second"})).To(Succeed())
+			Expect(appliedSnapshot.Annotations).To(HaveKeyWithValue("integration/annotation", "second"))
+		})
+
+		It("applies the status conditions and keeps the ones set in the meantime", func() {
+			outdatedSnapshot := appliedSnapshot.DeepCopy()
+			meta.SetStatusCondition(&appliedSnapshot.Status.Conditions, metav1.Condition{
+				Type: "OtherCondition", Status: metav1.ConditionTrue, Reason: "Other"})
+			Expect(k8sClient.Status().Update(ctx, appliedSnapshot)).To(Succeed())
+
+			Expect(helpers.ApplyStatusConditionWithRetry(ctx, k8sClient, outdatedSnapshot, func() *[]metav1.Condition {
+				return &outdatedSnapshot.Status.Conditions
+			}, metav1.Condition{Type: "IntegrationCondition", Status: metav1.ConditionTrue, Reason: "Integration"})).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(outdatedSnapshot.Status.Conditions, "OtherCondition")).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(outdatedSnapshot.Status.Conditions, "IntegrationCondition")).To(BeTrue())
+
+			Eventually(func(g Gomega) {
+				snapshot := &applicationapiv1alpha1.Snapshot{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(appliedSnapshot), snapshot)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(snapshot.Status.Conditions, "OtherCondition")).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(snapshot.Status.Conditions, "IntegrationCondition")).To(BeTrue())
+				g.Expect(snapshot.ManagedFields).To(ContainElement(And(
+					HaveField("Manager", helpers.IntegrationServiceFieldManager),
+					HaveField("Subresource", "status"),
+				)))
+			}).Should(Succeed())
+		})
+	})
+})
@@ -21,18 +21,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 )
 
 const (
 
 	// IntegrationTestScenarioValid is the condition for marking the AppStudio integration status of the Scenario.
 	IntegrationTestScenarioValid = "IntegrationTestScenarioValid"
-
-	// AppStudioIntegrationStatusInvalid is the reason that's set when the AppStudio integration gets into an invalid state.
-	AppStudioIntegrationStatusInvalid = "Invalid"
-
-	// AppStudioIntegrationStatusValid is the reason that's set when the AppStudio integration gets into an valid state.
-	AppStudioIntegrationStatusValid = "Valid"
 )
 
 // SetScenarioIntegrationStatusAsInvalid sets the IntegrationTestScenarioValid status condition for the Scenario to invalid.
@@ -40,7 +36,7 @@ func SetScenarioIntegrationStatusAsInvalid(scenario *v1beta2.IntegrationTestScen
 	meta.SetStatusCondition(&scenario.Status.Conditions, metav1.Condition{
 		Type:    IntegrationTestScenarioValid,
 		Status:  metav1.ConditionFalse,
-		Reason:  AppStudioIntegrationStatusInvalid,
+		Reason:  reasons.AppStudioIntegrationStatusInvalid,
 		Message: message,
 	})
 }
@@ -50,11 +46,37 @@ func SetScenarioIntegrationStatusAsValid(scenario *v1beta2.IntegrationTestScenar
 	meta.SetStatusCondition(&scenario.Status.Conditions, metav1.Condition{
 		Type:    IntegrationTestScenarioValid,
 		Status:  metav1.ConditionTrue,
-		Reason:  AppStudioIntegrationStatusValid,
+		Reason:  reasons.AppStudioIntegrationStatusValid,
 		Message: message,
 	})
 }
 
+// IsScenarioOptional returns true if the Scenario is labeled as optional.
+func IsScenarioOptional(scenario *v1beta2.IntegrationTestScenario) bool {
+	return metadata.HasLabelWithValue(scenario, OptionalScenarioLabel, "true")
+}
+
+// IsScenarioSuspended returns true if the Scenario is suspended, so that it isn't run for the Snapshots.
+func IsScenarioSuspended(scenario *v1beta2.IntegrationTestScenario) bool {
+	return scenario.Spec.Suspended
+}
+
+// IsScenarioObserveOnly returns true if the Scenario is still in its observe-only runs, so that it doesn't gate the Snapshots.
+func IsScenarioObserveOnly(scenario *v1beta2.IntegrationTestScenario) bool {
+	return scenario.Status.ObservedRuns < scenario.Spec.ObserveOnlyRuns
+}
+
+// IsScenarioFlaky returns true if the Scenario is flagged as flaky.
+func IsScenarioFlaky(scenario *v1beta2.IntegrationTestScenario) bool {
+	return metadata.HasAnnotation(scenario, FlakyScenarioAnnotation)
+}
+
+// IsScenarioQuarantined returns true if the Scenario is flagged as flaky and opted into the quarantine of the flaky
+// scenarios, so that it doesn't gate the Snapshots.
+func IsScenarioQuarantined(scenario *v1beta2.IntegrationTestScenario) bool {
+	return scenario.Spec.QuarantineFlaky && IsScenarioFlaky(scenario)
+}
+
 // IsScenarioValid sets the IntegrationTestScenarioValid integration status condition for the Scenario to valid.
 func IsScenarioValid(scenario *v1beta2.IntegrationTestScenario) bool {
 	statusCondition := meta.FindStatusCondition(scenario.Status.Conditions, IntegrationTestScenarioValid)
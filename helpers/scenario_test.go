@@ -87,4 +87,11 @@ var _ = Describe("Gitops functions for managing Snapshots", Ordered, func() {
 			Expect(meta.IsStatusConditionTrue(integrationTestScenario.Status.Conditions, helpers.IntegrationTestScenarioValid)).To(BeTrue())
 		})
 	})
+
+	It("ensures the optional Scenarios are recognized", func() {
+		Expect(helpers.IsScenarioOptional(integrationTestScenario)).To(BeFalse())
+		optionalScenario := integrationTestScenario.DeepCopy()
+		optionalScenario.Labels[helpers.OptionalScenarioLabel] = "true"
+		Expect(helpers.IsScenarioOptional(optionalScenario)).To(BeTrue())
+	})
 })
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// NamespaceShard describes the share of the namespaces reconciled by one of the replicas of the operator, when the
+// reconciliations are spread across several active replicas. Each namespace is owned by a single shard, chosen by
+// the hash of its name.
+type NamespaceShard struct {
+	// Index is the index of the shard, from 0 to Count-1.
+	Index int
+
+	// Count is the total number of shards, the namespaces aren't sharded when it's 1.
+	Count int
+}
+
+// NewNamespaceShard creates and returns a NamespaceShard from the index of the shard and the total number of shards.
+func NewNamespaceShard(index, count int) (*NamespaceShard, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("the number of shards must be at least 1, got %d", count)
+	}
+	if index < 0 || index >= count {
+		return nil, fmt.Errorf("the shard index %d is out of the range of the %d shards", index, count)
+	}
+
+	return &NamespaceShard{Index: index, Count: count}, nil
+}
+
+// ShardIndexFromHostname returns the shard index of a replica from its hostname, which ends with the ordinal of the
+// replica when it's run by a StatefulSet, e.g. integration-service-controller-manager-2.
+func ShardIndexFromHostname(hostname string) (int, error) {
+	separator := strings.LastIndex(hostname, "-")
+	index, err := strconv.Atoi(hostname[separator+1:])
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("the hostname %q doesn't end with the ordinal of the replica", hostname)
+	}
+
+	return index, nil
+}
+
+// IsSharded returns true if the namespaces are spread across several shards.
+func (s *NamespaceShard) IsSharded() bool {
+	return s.Count > 1
+}
+
+// Owns returns true if the namespace is reconciled by the shard.
+func (s *NamespaceShard) Owns(namespace string) bool {
+	if !s.IsSharded() {
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(namespace))
+	return int(hash.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// LeaderElectionID returns the leader election ID of the shard derived from the given one, so the replicas of each
+// shard elect their own leader. The ID is returned unchanged when the namespaces aren't sharded.
+func (s *NamespaceShard) LeaderElectionID(id string) string {
+	if !s.IsSharded() {
+		return id
+	}
+
+	return fmt.Sprintf("shard-%d-%s", s.Index, id)
+}
+
+// Predicate returns a predicate which filters out the objects of the namespaces which aren't owned by the shard.
+// Cluster-scoped objects pass the filter.
+func (s *NamespaceShard) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == "" || s.Owns(object.GetNamespace())
+	})
+}
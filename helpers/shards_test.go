@@ -0,0 +1,105 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/helpers"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Namespace shard", func() {
+
+	It("owns all namespaces when the namespaces aren't sharded", func() {
+		shard, err := helpers.NewNamespaceShard(0, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shard.IsSharded()).To(BeFalse())
+		Expect(shard.Owns("tenant-a")).To(BeTrue())
+		Expect(shard.LeaderElectionID("f1944211.redhat.com")).To(Equal("f1944211.redhat.com"))
+	})
+
+	It("assigns each namespace to a single shard", func() {
+		shards := []*helpers.NamespaceShard{}
+		for index := 0; index < 3; index++ {
+			shard, err := helpers.NewNamespaceShard(index, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shard.IsSharded()).To(BeTrue())
+			shards = append(shards, shard)
+		}
+
+		ownedNamespaces := map[int]int{}
+		for i := 0; i < 30; i++ {
+			namespace := fmt.Sprintf("tenant-%d", i)
+			owners := 0
+			for _, shard := range shards {
+				if shard.Owns(namespace) {
+					owners++
+					ownedNamespaces[shard.Index]++
+				}
+			}
+			Expect(owners).To(Equal(1))
+		}
+		// the namespaces are spread across all the shards
+		Expect(ownedNamespaces).To(HaveLen(3))
+		Expect(shards[1].LeaderElectionID("f1944211.redhat.com")).To(Equal("shard-1-f1944211.redhat.com"))
+	})
+
+	It("filters out the objects of the namespaces owned by other shards", func() {
+		shard, err := helpers.NewNamespaceShard(0, 2)
+		Expect(err).NotTo(HaveOccurred())
+		ownedNamespace, otherNamespace := "", ""
+		for i := 0; ownedNamespace == "" || otherNamespace == ""; i++ {
+			namespace := fmt.Sprintf("tenant-%d", i)
+			if shard.Owns(namespace) {
+				ownedNamespace = namespace
+			} else {
+				otherNamespace = namespace
+			}
+		}
+
+		instance := shard.Predicate()
+		owned := &applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: ownedNamespace}}
+		other := &applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: otherNamespace}}
+		Expect(instance.Create(event.CreateEvent{Object: owned})).To(BeTrue())
+		Expect(instance.Create(event.CreateEvent{Object: other})).To(BeFalse())
+		Expect(instance.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other})).To(BeFalse())
+		Expect(instance.Create(event.CreateEvent{Object: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: otherNamespace}}})).To(BeTrue())
+	})
+
+	It("fails to create a shard out of the range of the shards", func() {
+		_, err := helpers.NewNamespaceShard(2, 2)
+		Expect(err).To(MatchError("the shard index 2 is out of the range of the 2 shards"))
+		_, err = helpers.NewNamespaceShard(0, 0)
+		Expect(err).To(MatchError("the number of shards must be at least 1, got 0"))
+	})
+
+	It("gets the shard index from the ordinal of the replica in its hostname", func() {
+		index, err := helpers.ShardIndexFromHostname("integration-service-controller-manager-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(index).To(Equal(2))
+
+		_, err = helpers.ShardIndexFromHostname("integration-service-controller-manager-7d4f9c-x2kz")
+		Expect(err).To(HaveOccurred())
+	})
+})
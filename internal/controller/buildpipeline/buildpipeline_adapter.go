@@ -25,45 +25,120 @@ import (
 
 	"k8s.io/client-go/util/retry"
 
+	"github.com/konflux-ci/integration-service/git/github"
 	"github.com/konflux-ci/integration-service/gitops"
 	h "github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
 	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
+	"github.com/konflux-ci/integration-service/status"
 	"github.com/konflux-ci/integration-service/tekton"
 	"github.com/konflux-ci/operator-toolkit/controller"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // Adapter holds the objects needed to reconcile a build PipelineRun.
 type Adapter struct {
-	pipelineRun *tektonv1.PipelineRun
-	component   *applicationapiv1alpha1.Component
-	application *applicationapiv1alpha1.Application
-	loader      loader.ObjectLoader
-	logger      h.IntegrationLogger
-	client      client.Client
-	context     context.Context
+	pipelineRun   *tektonv1.PipelineRun
+	component     *applicationapiv1alpha1.Component
+	application   *applicationapiv1alpha1.Application
+	loader        loader.ObjectLoader
+	logger        h.IntegrationLogger
+	client        client.Client
+	recorder      record.EventRecorder
+	status        status.StatusInterface
+	imageVerifier *signature.Verifier
+	context       context.Context
 }
 
 // NewAdapter creates and returns an Adapter instance.
 func NewAdapter(context context.Context, pipelineRun *tektonv1.PipelineRun, component *applicationapiv1alpha1.Component, application *applicationapiv1alpha1.Application,
-	logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client,
+	logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client, recorder record.EventRecorder,
+	imageVerifier *signature.Verifier,
 ) *Adapter {
 	return &Adapter{
-		pipelineRun: pipelineRun,
-		component:   component,
-		application: application,
-		logger:      logger,
-		loader:      loader,
-		client:      client,
-		context:     context,
+		pipelineRun:   pipelineRun,
+		component:     component,
+		application:   application,
+		logger:        logger,
+		loader:        loader,
+		client:        client,
+		recorder:      recorder,
+		status:        status.NewStatus(logger.Logger, client),
+		imageVerifier: imageVerifier,
+		context:       context,
 	}
 }
 
+// EnsureBuildFailureReported is an operation that will ensure that the failure of the build PipelineRun being processed
+// is reported to the git provider which triggered it, since no Snapshot will be created for it and no integration tests
+// will report their status. The failure is reported only once, the build PipelineRun is annotated when it was reported.
+func (a *Adapter) EnsureBuildFailureReported() (controller.OperationResult, error) {
+	if !h.HasPipelineRunFinished(a.pipelineRun) || h.HasPipelineRunSucceeded(a.pipelineRun) ||
+		metadata.HasAnnotation(a.pipelineRun, h.BuildFailureReportedAnnotationName) {
+		return controller.ContinueProcessing()
+	}
+
+	// the Snapshot isn't created, it only carries the git provider metadata of the build pipelineRun for the reporter
+	snapshot := &applicationapiv1alpha1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      a.pipelineRun.Name,
+			Namespace: a.pipelineRun.Namespace,
+		},
+	}
+	gitops.CopySnapshotLabelsAndAnnotation(a.application, snapshot, a.component.Name, &a.pipelineRun.ObjectMeta, gitops.BuildPipelineRunPrefix, false)
+
+	reporter := a.status.GetReporter(snapshot)
+	if reporter == nil {
+		a.logger.Info("No suitable reporter found, skipping report of the build pipelineRun failure")
+		return controller.ContinueProcessing()
+	}
+	a.logger.Info(fmt.Sprintf("Detected reporter: %s", reporter.GetReporterName()))
+
+	// the report is best effort, so that it never blocks the removal of the finalizer of the build pipelineRun
+	// when the git provider keeps rejecting it, e.g. since the GitHub App isn't installed in the repository, only
+	// the reports hitting the rate limit of the git provider are retried once it resets
+	err := a.status.ReportBuildPipelineRunFailure(a.context, reporter, snapshot, a.pipelineRun)
+	if retryAfter, limited := github.GetRateLimitRetryAfter(err); limited {
+		a.logger.Error(err, "The git provider rate limited the report of the build pipelineRun failure, retrying later")
+		return controller.RequeueAfter(retryAfter, nil)
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to report the build pipelineRun failure to git provider, skipping the report")
+		a.recorder.Eventf(a.pipelineRun, corev1.EventTypeWarning, string(reasons.BuildFailureReportFailedEventReason),
+			"The failure of the build pipelineRun couldn't be reported to the git provider: %s", err.Error())
+		return controller.ContinueProcessing()
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		a.pipelineRun, err = a.loader.GetPipelineRun(a.context, a.client, a.pipelineRun.Name, a.pipelineRun.Namespace)
+		if err != nil {
+			return err
+		}
+		return tekton.AnnotateBuildPipelineRun(a.context, a.pipelineRun, h.BuildFailureReportedAnnotationName, "true", a.client)
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return controller.ContinueProcessing()
+		}
+		a.logger.Error(err, "Failed to annotate the build pipelineRun after reporting its failure")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Reported the build pipelineRun failure to git provider", a.pipelineRun, h.LogActionUpdate,
+		"reporter", reporter.GetReporterName())
+
+	return controller.ContinueProcessing()
+}
+
 // EnsureSnapshotExists is an operation that will ensure that a pipeline Snapshot associated
 // to the build PipelineRun being processed exists. Otherwise, it will create a new pipeline Snapshot.
 func (a *Adapter) EnsureSnapshotExists() (result controller.OperationResult, err error) {
@@ -115,16 +190,29 @@ func (a *Adapter) EnsureSnapshotExists() (result controller.OperationResult, err
 		return controller.RequeueWithError(err)
 	}
 
+	err = a.verifyBuiltImage(expectedSnapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to verify the image built by the pipelineRun")
+		return controller.RequeueWithError(err)
+	}
+
 	err = a.client.Create(a.context, expectedSnapshot)
 	if err != nil {
 		result, err = a.handleSnapshotCreationFailure(&canRemoveFinalizer, err)
 		return result, err
 	}
 	go metrics.RegisterNewSnapshot()
+	go metrics.RegisterSnapshotCreated(expectedSnapshot.Namespace, expectedSnapshot.Spec.Application)
+	if a.pipelineRun.Status.CompletionTime != nil {
+		go metrics.RegisterBuildPipelineRunCompletedToSnapshotCreated(expectedSnapshot.Namespace, expectedSnapshot.Spec.Application,
+			*a.pipelineRun.Status.CompletionTime, expectedSnapshot.GetCreationTimestamp())
+	}
 
 	a.logger.LogAuditEvent("Created new Snapshot", expectedSnapshot, h.LogActionAdd,
 		"snapshot.Name", expectedSnapshot.Name,
 		"snapshot.Spec.Components", expectedSnapshot.Spec.Components)
+	a.recorder.Eventf(expectedSnapshot, corev1.EventTypeNormal, string(reasons.SnapshotCreatedEventReason),
+		"Snapshot created for build pipelineRun %s of component %s", a.pipelineRun.Name, a.component.Name)
 
 	err = a.annotateBuildPipelineRunWithSnapshot(expectedSnapshot)
 	if err != nil {
@@ -151,6 +239,27 @@ func (a *Adapter) EnsurePipelineIsFinalized() (controller.OperationResult, error
 	return controller.ContinueProcessing()
 }
 
+// verifyBuiltImage verifies that the image of the component built by the build PipelineRun is signed and has a
+// provenance attestation when image verification is enabled, and records the result in the Snapshot before it's
+// created. An error is returned only when the verification couldn't be completed, e.g. as the registry isn't reachable.
+func (a *Adapter) verifyBuiltImage(snapshot *applicationapiv1alpha1.Snapshot) error {
+	if a.imageVerifier == nil {
+		return nil
+	}
+
+	image, err := a.getImagePullSpecFromPipelineRun(a.pipelineRun)
+	if err != nil {
+		return err
+	}
+	verification, err := gitops.VerifySnapshotImage(a.context, a.imageVerifier, snapshot, a.component.Name, image)
+	if err != nil {
+		return err
+	}
+	a.logger.Info("The image built by the pipelineRun was verified", "image", image, "verified", verification.Verified)
+
+	return nil
+}
+
 // getImagePullSpecFromPipelineRun gets the full image pullspec from the given build PipelineRun,
 // In case the Image pullspec can't be composed, an error will be returned.
 func (a *Adapter) getImagePullSpecFromPipelineRun(pipelineRun *tektonv1.PipelineRun) (string, error) {
@@ -210,6 +319,30 @@ func (a *Adapter) prepareSnapshotForPipelineRun(pipelineRun *tektonv1.PipelineRu
 		return nil, err
 	}
 
+	// record the digests per architecture of the multi-arch images, so that the scenarios can test a single architecture
+	architectureDigests := gitops.GetComponentsArchitectureDigests(applicationComponents, snapshot)
+	builtArchitectureDigests, err := tekton.GetOutputImageArchitectureDigests(pipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	delete(architectureDigests, component.Name)
+	if len(builtArchitectureDigests) > 0 {
+		architectureDigests[component.Name] = builtArchitectureDigests
+	}
+	if err = gitops.SetSnapshotArchitectureDigests(snapshot, architectureDigests); err != nil {
+		return nil, err
+	}
+
+	// attach the SBOM references of the component images, so that the test results can be linked to them
+	sboms := gitops.GetComponentsSBOMs(applicationComponents, snapshot)
+	delete(sboms, component.Name)
+	if sbom := tekton.GetOutputSBOMBlobURL(pipelineRun); sbom != "" {
+		sboms[component.Name] = sbom
+	}
+	if err = gitops.SetSnapshotSBOMs(snapshot, sboms); err != nil {
+		return nil, err
+	}
+
 	gitops.CopySnapshotLabelsAndAnnotation(application, snapshot, a.component.Name, &pipelineRun.ObjectMeta, gitops.BuildPipelineRunPrefix, false)
 
 	snapshot.Labels[gitops.BuildPipelineRunNameLabel] = pipelineRun.Name
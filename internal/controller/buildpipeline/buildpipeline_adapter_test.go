@@ -18,22 +18,37 @@ package buildpipeline
 
 import (
 	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"net/http"
 	"reflect"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/konflux-ci/integration-service/git/github"
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
+	"github.com/konflux-ci/integration-service/status"
 	"github.com/konflux-ci/integration-service/tekton"
+	"go.uber.org/mock/gomock"
 	"knative.dev/pkg/apis"
 	v1 "knative.dev/pkg/apis/duck/v1"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/strings/slices"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -48,6 +63,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// unreachableRegistryTransport fails all the requests, as if the registry wasn't reachable.
+type unreachableRegistryTransport struct{}
+
+func (unreachableRegistryTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("registry unreachable")
+}
+
 var _ = Describe("Pipeline Adapter", Ordered, func() {
 	var (
 		adapter       *Adapter
@@ -349,7 +371,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 
 	When("NewAdapter is called", func() {
 		It("creates and return a new adapter", func() {
-			Expect(reflect.TypeOf(NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient))).To(Equal(reflect.TypeOf(&Adapter{})))
+			Expect(reflect.TypeOf(NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil))).To(Equal(reflect.TypeOf(&Adapter{})))
 		})
 	})
 
@@ -507,6 +529,55 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 			Expect(info["message"]).To(Equal("Failed to create snapshot. Error: " + messageError))
 		})
 
+		It("ensures the image digests per architecture of the built component are recorded in the snapshot", func() {
+			snapshot, err := adapter.prepareSnapshotForPipelineRun(buildPipelineRun, hasComp, hasApp)
+			Expect(err).To(BeNil())
+			Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotArchitectureDigestsAnnotation))
+
+			multiArchPipelineRun := buildPipelineRun.DeepCopy()
+			multiArchPipelineRun.Status.Results = append(multiArchPipelineRun.Status.Results, tektonv1.PipelineRunResult{
+				Name:  tekton.PipelineRunImageArchitectureDigestsParamName,
+				Value: *tektonv1.NewStructuredValues(`{"amd64":"sha256:aaa","arm64":"sha256:bbb"}`),
+			})
+			snapshot, err = adapter.prepareSnapshotForPipelineRun(multiArchPipelineRun, hasComp, hasApp)
+			Expect(err).To(BeNil())
+			digests, err := gitops.GetSnapshotArchitectureDigests(snapshot)
+			Expect(err).To(BeNil())
+			Expect(digests).To(HaveKeyWithValue(hasComp.Name, map[string]string{"amd64": "sha256:aaa", "arm64": "sha256:bbb"}))
+		})
+
+		It("ensures the SBOM of the built component is attached to the snapshot", func() {
+			snapshot, err := adapter.prepareSnapshotForPipelineRun(buildPipelineRun, hasComp, hasApp)
+			Expect(err).To(BeNil())
+			Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotSBOMsAnnotation))
+
+			sbomPipelineRun := buildPipelineRun.DeepCopy()
+			sbomPipelineRun.Status.Results = append(sbomPipelineRun.Status.Results, tektonv1.PipelineRunResult{
+				Name:  tekton.PipelineRunSBOMBlobURLParamName,
+				Value: *tektonv1.NewStructuredValues("quay.io/redhat-appstudio/sample-image@sha256:aaa"),
+			})
+			snapshot, err = adapter.prepareSnapshotForPipelineRun(sbomPipelineRun, hasComp, hasApp)
+			Expect(err).To(BeNil())
+			Expect(gitops.GetSnapshotSBOMs(snapshot)).To(HaveKeyWithValue(hasComp.Name, "quay.io/redhat-appstudio/sample-image@sha256:aaa"))
+		})
+
+		It("ensures the built image is verified only when image verification is enabled", func() {
+			snapshot, err := adapter.prepareSnapshotForPipelineRun(buildPipelineRun, hasComp, hasApp)
+			Expect(err).To(BeNil())
+			Expect(adapter.verifyBuiltImage(snapshot)).To(Succeed())
+			Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotImageVerificationAnnotation))
+
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).To(BeNil())
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).To(BeNil())
+			adapter.imageVerifier, err = signature.NewVerifier(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+				remote.WithTransport(unreachableRegistryTransport{}))
+			Expect(err).To(BeNil())
+			Expect(adapter.verifyBuiltImage(snapshot)).To(MatchError(ContainSubstring("registry unreachable")))
+			Expect(snapshot.GetAnnotations()).NotTo(HaveKey(gitops.SnapshotImageVerificationAnnotation))
+		})
+
 		It("ensures pipelines as code labels and annotations are propagated to the snapshot", func() {
 			snapshot, err := adapter.prepareSnapshotForPipelineRun(buildPipelineRun, hasComp, hasApp)
 			Expect(err).To(BeNil())
@@ -578,7 +649,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 				"build.appstudio.openshift.io/repo":             "https://github.com/devfile-samples/devfile-sample-go-basic?rev=c713067b0e65fb3de50d1f7c457eb51c2ab0dbb0",
 				"foo":                                           "bar",
 			}
-			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 
 			Eventually(func() bool {
 				result, err := adapter.EnsureSnapshotExists()
@@ -669,7 +740,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
 
 			// check the behavior when there are multiple Snapshots associated with the build pipelineRun
-			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -866,7 +937,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		})
 
 		It("can annotate the build pipelineRun with the Snapshot name", func() {
-			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 			err := adapter.annotateBuildPipelineRunWithSnapshot(hasSnapshot)
 			Expect(err).To(BeNil())
 			Expect(adapter.pipelineRun.ObjectMeta.Annotations[tekton.SnapshotNameLabel]).To(Equal(hasSnapshot.Name))
@@ -874,7 +945,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 
 		It("Can annotate the build pipelineRun with the CreateSnapshot annotate", func() {
 			sampleErr := errors.New("this is a sample error")
-			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 			err := tekton.AnnotateBuildPipelineRunWithCreateSnapshotAnnotation(adapter.context, buildPipelineRun, adapter.client, sampleErr)
 			Expect(err).NotTo(HaveOccurred())
 
@@ -906,7 +977,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 
 		It("can find matching snapshot", func() {
 			// make sure the first pipeline started as first
-			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -930,11 +1001,108 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		})
 	})
 
+	When("a build pipelineRun triggered by a Pull Request fails", func() {
+		var (
+			mockReporter *status.MockReporterInterface
+			mockStatus   *status.MockStatusInterface
+		)
+
+		BeforeEach(func() {
+			buildPipelineRun.Status.Conditions = v1.Conditions{
+				apis.Condition{
+					Reason:  "Failed",
+					Status:  "False",
+					Type:    apis.ConditionSucceeded,
+					Message: "Tasks Completed: 1 (Failed: 1, Cancelled 0), Skipped: 2",
+				},
+			}
+			buildPipelineRun.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+			Expect(k8sClient.Status().Update(ctx, buildPipelineRun)).Should(Succeed())
+			buildPipelineRun.Labels["pipelinesascode.tekton.dev/git-provider"] = "github"
+
+			ctrl := gomock.NewController(GinkgoT())
+			mockReporter = status.NewMockReporterInterface(ctrl)
+			mockStatus = status.NewMockStatusInterface(ctrl)
+			mockReporter.EXPECT().GetReporterName().Return("mocked_reporter").AnyTimes()
+
+			adapter = createAdapter()
+			adapter.status = mockStatus
+		})
+
+		It("reports the failure to the git provider only once", func() {
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Return(mockReporter).Times(1)
+			mockStatus.EXPECT().ReportBuildPipelineRunFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ status.ReporterInterface, snapshot *applicationapiv1alpha1.Snapshot, pipelineRun *tektonv1.PipelineRun) error {
+					Expect(pipelineRun.Name).To(Equal(buildPipelineRun.Name))
+					Expect(snapshot.Name).To(Equal(buildPipelineRun.Name))
+					Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.PipelineAsCodeGitProviderLabel, "github"))
+					Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.SnapshotComponentLabel, hasComp.Name))
+					return nil
+				}).Times(1)
+
+			result, err := adapter.EnsureBuildFailureReported()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			Eventually(func() bool {
+				pipelineRun := &tektonv1.PipelineRun{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: buildPipelineRun.Namespace, Name: buildPipelineRun.Name}, pipelineRun)
+				return err == nil && pipelineRun.Annotations[helpers.BuildFailureReportedAnnotationName] == "true"
+			}, time.Second*10).Should(BeTrue())
+
+			result, err = adapter.EnsureBuildFailureReported()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		})
+
+		It("continues without blocking the snapshot creation when the failure can't be reported", func() {
+			recorder := record.NewFakeRecorder(10)
+			adapter.recorder = recorder
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Return(mockReporter).Times(1)
+			mockStatus.EXPECT().ReportBuildPipelineRunFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("forbidden")).Times(1)
+
+			result, err := adapter.EnsureBuildFailureReported()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(adapter.pipelineRun.Annotations).NotTo(HaveKey(helpers.BuildFailureReportedAnnotationName))
+			Expect(recorder.Events).To(Receive(And(ContainSubstring(string(reasons.BuildFailureReportFailedEventReason)), ContainSubstring("forbidden"))))
+		})
+
+		It("retries the report once the rate limit of the git provider resets", func() {
+			recorder := record.NewFakeRecorder(10)
+			adapter.recorder = recorder
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Return(mockReporter).Times(1)
+			mockStatus.EXPECT().ReportBuildPipelineRunFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(fmt.Errorf("failed to report build pipelineRun failure: %w", &github.RateLimitError{StatusCode: 403, RetryAfter: time.Minute})).Times(1)
+
+			result, err := adapter.EnsureBuildFailureReported()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(time.Minute))
+			Expect(adapter.pipelineRun.Annotations).NotTo(HaveKey(helpers.BuildFailureReportedAnnotationName))
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("skips the report when no suitable reporter is found", func() {
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Return(nil).Times(1)
+			mockStatus.EXPECT().ReportBuildPipelineRunFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			result, err := adapter.EnsureBuildFailureReported()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		})
+
+		It("doesn't report the build pipelineRun which succeeded", func() {
+			adapter.pipelineRun.Status.Conditions[0].Status = "True"
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Times(0)
+			mockStatus.EXPECT().ReportBuildPipelineRunFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			result, err := adapter.EnsureBuildFailureReported()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		})
+	})
+
 	When("A new Build pipelineRun is created", func() {
 
 		When("can add and remove finalizers from the pipelineRun", func() {
 			BeforeEach(func() {
-				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 			})
 			It("can add and remove finalizers from build pipelineRun", func() {
 				// Mark build PLR as incomplete
@@ -1078,7 +1246,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 				}
 				Expect(k8sClient.Status().Update(ctx, runningDeletingBuildPipeline)).Should(Succeed())
 
-				adapter = NewAdapter(ctx, runningDeletingBuildPipeline, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, runningDeletingBuildPipeline, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 					{
 						ContextKey: loader.GetPipelineRunContextKey,
@@ -1124,7 +1292,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 				var buf bytes.Buffer
 				log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
 				buildPipelineRun.ObjectMeta.Annotations[gitops.SnapshotLabel] = hasSnapshot.Name
-				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 					{
 						ContextKey: loader.GetPipelineRunContextKey,
@@ -1159,7 +1327,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 				var buf bytes.Buffer
 				log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
 				buildPipelineRun.ObjectMeta.Annotations[gitops.SnapshotLabel] = hasSnapshot.Name
-				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 					{
 						ContextKey: loader.GetPipelineRunContextKey,
@@ -1196,7 +1364,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 
 				var buf bytes.Buffer
 				log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 					{
 						ContextKey: loader.GetPipelineRunContextKey,
@@ -1230,7 +1398,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 	})
 
 	createAdapter = func() *Adapter {
-		adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient)
+		adapter = NewAdapter(ctx, buildPipelineRun, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 		return adapter
 	}
 })
@@ -18,19 +18,29 @@ package buildpipeline
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/konflux-ci/integration-service/cache"
 	"k8s.io/client-go/util/retry"
 
 	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
 	"github.com/konflux-ci/integration-service/tekton"
 	"github.com/konflux-ci/operator-toolkit/controller"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -39,16 +49,20 @@ import (
 // Reconciler reconciles a build PipelineRun object
 type Reconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ImageVerifier verifies the images of the Snapshots, it's nil when image verification is disabled
+	ImageVerifier *signature.Verifier
 }
 
 // NewIntegrationReconciler creates and returns a Reconciler.
-func NewIntegrationReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme) *Reconciler {
+func NewIntegrationReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
-		Client: client,
-		Log:    logger.WithName("build pipeline"),
-		Scheme: scheme,
+		Client:   client,
+		Log:      logger.WithName("build pipeline"),
+		Scheme:   scheme,
+		Recorder: recorder,
 	}
 }
 
@@ -66,7 +80,8 @@ func NewIntegrationReconciler(client client.Client, logger *logr.Logger, scheme
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("buildpipelineRun", req.NamespacedName)}
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("buildpipelineRun", req.NamespacedName, correlation.LogKey, correlationID)}
 	loader := loader.NewLoader()
 
 	pipelineRun := &tektonv1.PipelineRun{}
@@ -79,23 +94,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 		return ctrl.Result{}, err
 	}
+	logger.Logger = correlation.WithParentID(logger.Logger, pipelineRun)
 
 	var component *applicationapiv1alpha1.Component
-	err = retry.OnError(retry.DefaultRetry, func(_ error) bool { return true }, func() error {
+	err = retry.OnError(retry.DefaultRetry, func(err error) bool { return !errors.IsNotFound(err) }, func() error {
 		component, err = loader.GetComponentFromPipelineRun(ctx, r.Client, pipelineRun)
 		return err
 	})
 	if err != nil {
 		if errors.IsNotFound(err) {
-			if err := helpers.RemoveFinalizerFromPipelineRun(ctx, r.Client, logger, pipelineRun, helpers.IntegrationPipelineRunFinalizer); err != nil {
-				return ctrl.Result{}, err
-			}
+			return r.handleMissingComponent(ctx, logger, correlation.NewRecorder(r.Recorder, correlationID), pipelineRun, err)
 		}
 		return helpers.HandleLoaderError(logger, err, "component", "pipelineRun")
 	} else if component == nil {
 		// if both component and error are nil then the component label for the pipeline did not exist
 		// in this case we should stop reconciliation
-		logger.Info("Failed to  get component for build pipeline - component label does not exist", "name", pipelineRun.Name, "namespace", pipelineRun.Namespace)
+		logger.Info("Failed to  get component for build pipeline - component label or param does not exist", "name", pipelineRun.Name, "namespace", pipelineRun.Namespace)
 		return ctrl.Result{}, nil
 	}
 
@@ -113,23 +127,58 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	logger = logger.WithApp(*application)
 
-	adapter := NewAdapter(ctx, pipelineRun, component, application, logger, loader, r.Client)
+	ctx, span := tracing.StartReconcileSpan(ctx, "buildpipeline", map[string]client.Object{"pipelinerun": pipelineRun, "component": component, "application": application})
+	defer span.End()
+
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, pipelineRun, component, application, logger, loader, correlation.NewClient(r.Client, correlationID), correlation.NewRecorder(r.Recorder, correlationID), r.ImageVerifier)
 
-	return controller.ReconcileHandler([]controller.Operation{
+	return operations.NewChain("buildpipeline",
 		adapter.EnsurePipelineIsFinalized,
+		adapter.EnsureBuildFailureReported,
 		adapter.EnsureSnapshotExists,
-	})
+	).Run(ctx)
+}
+
+// handleMissingComponent stops the reconciliation of a build pipelineRun whose Component was deleted before it was reconciled.
+// The reason is recorded in the create-snapshot-status annotation and in an event of the pipelineRun, so that it's clear why
+// no Snapshot was created for it, and the finalizer is removed so that the pipelineRun isn't blocked.
+func (r *Reconciler) handleMissingComponent(ctx context.Context, logger helpers.IntegrationLogger, recorder record.EventRecorder, pipelineRun *tektonv1.PipelineRun, notFoundErr error) (ctrl.Result, error) {
+	componentName, _ := tekton.GetComponentName(pipelineRun)
+	if !metadata.HasAnnotation(pipelineRun, helpers.CreateSnapshotAnnotationName) {
+		missingErr := fmt.Errorf("component %s of the build pipelineRun was not found, it may have been deleted: %w", componentName, notFoundErr)
+		if err := tekton.AnnotateBuildPipelineRunWithCreateSnapshotAnnotation(ctx, pipelineRun, r.Client, missingErr); err != nil {
+			if errors.IsNotFound(err) {
+				return ctrl.Result{}, nil
+			}
+			logger.Error(err, "Could not add create snapshot annotation to build pipelineRun", "pipelineRun.Name", pipelineRun.Name)
+			return ctrl.Result{}, err
+		}
+		recorder.Eventf(pipelineRun, corev1.EventTypeWarning, string(reasons.ComponentNotFoundEventReason),
+			"Component %s was not found, no Snapshot will be created for the build pipelineRun", componentName)
+	}
+
+	if err := helpers.RemoveFinalizerFromPipelineRun(ctx, r.Client, logger, pipelineRun, helpers.IntegrationPipelineRunFinalizer); err != nil {
+		return ctrl.Result{}, err
+	}
+	return helpers.HandleLoaderError(logger, notFoundErr, "component", "pipelineRun")
 }
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
 	EnsurePipelineIsFinalized() (controller.OperationResult, error)
+	EnsureBuildFailureReported() (controller.OperationResult, error)
 	EnsureSnapshotExists() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration controller and adds it to the Manager.
-func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewIntegrationReconciler(manager.GetClient(), log, manager.GetScheme()))
+func SetupController(manager ctrl.Manager, log *logr.Logger, imageVerifier *signature.Verifier, eventFilters ...predicate.Predicate) error {
+	reconciler := NewIntegrationReconciler(manager.GetClient(), log, manager.GetScheme(),
+		manager.GetEventRecorderFor(gitops.IntegrationServiceEventRecorderName))
+	reconciler.ImageVerifier = imageVerifier
+	return setupControllerWithManager(manager, reconciler, eventFilters...)
 }
 
 // setupCache indexes fields for each of the resources used in the build pipeline adapter in those cases where
@@ -140,7 +189,7 @@ func setupCache(mgr ctrl.Manager) error {
 
 // setupControllerWithManager sets up the controller with the Manager which monitors new build PipelineRuns and filters
 // out status updates.
-func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
 	err := setupCache(manager)
 	if err != nil {
 		return err
@@ -154,5 +203,6 @@ func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) er
 			tekton.BuildPipelineRunCreatedPredicate(),
 			tekton.BuildPipelineRunDeletingPredicate(),
 		)).
+		WithEventFilter(predicate.And(eventFilters...)).
 		Complete(controller)
 }
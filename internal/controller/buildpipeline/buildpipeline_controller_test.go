@@ -18,6 +18,8 @@ package buildpipeline
 
 import (
 	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"k8s.io/client-go/tools/record"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -223,7 +225,7 @@ var _ = Describe("PipelineController", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(err).To(BeNil())
 
-		pipelineReconciler = NewIntegrationReconciler(k8sClient, &logf.Log, &scheme)
+		pipelineReconciler = NewIntegrationReconciler(k8sClient, &logf.Log, &scheme, &record.FakeRecorder{})
 	})
 
 	AfterEach(func() {
@@ -263,7 +265,7 @@ var _ = Describe("PipelineController", func() {
 	})
 
 	It("can setup a new Controller manager", func() {
-		err := SetupController(manager, &ctrl.Log)
+		err := SetupController(manager, &ctrl.Log, nil)
 		Expect(err).To(BeNil())
 	})
 
@@ -300,6 +302,42 @@ var _ = Describe("PipelineController", func() {
 		}, time.Second*20).Should(BeTrue())
 	})
 
+	It("records why no snapshot is created when the component was deleted", func() {
+		controllerutil.AddFinalizer(buildPipelineRun, helpers.IntegrationPipelineRunFinalizer)
+		Expect(k8sClient.Update(ctx, buildPipelineRun)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, hasComp)).To(Succeed())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: hasComp.ObjectMeta.Namespace,
+				Name:      hasComp.ObjectMeta.Name,
+			}, hasComp)
+			return errors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		recorder := record.NewFakeRecorder(10)
+		reconciler := NewIntegrationReconciler(k8sClient, &logf.Log, &scheme, recorder)
+		result, err := reconciler.Reconcile(ctx, req)
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(err).To(BeNil())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: buildPipelineRun.Namespace,
+				Name:      buildPipelineRun.Name,
+			}, buildPipelineRun)
+			return err == nil && !controllerutil.ContainsFinalizer(buildPipelineRun, helpers.IntegrationPipelineRunFinalizer)
+		}, time.Second*20).Should(BeTrue())
+		Expect(buildPipelineRun.Annotations[helpers.CreateSnapshotAnnotationName]).To(ContainSubstring("failed"))
+		Expect(buildPipelineRun.Annotations[helpers.CreateSnapshotAnnotationName]).To(ContainSubstring("it may have been deleted"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(string(reasons.ComponentNotFoundEventReason))))
+
+		// the reason isn't recorded again when the build pipelineRun is reconciled again
+		result, err = reconciler.Reconcile(ctx, req)
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(err).To(BeNil())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
 	When("pipelinerun has no component", func() {
 
 		var (
@@ -25,9 +25,14 @@ import (
 	h "github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
 	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/signature"
 	"github.com/konflux-ci/operator-toolkit/controller"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -35,25 +40,30 @@ import (
 
 // Adapter holds the objects needed to reconcile a integration PipelineRun.
 type Adapter struct {
-	component   *applicationapiv1alpha1.Component
-	application *applicationapiv1alpha1.Application
-	loader      loader.ObjectLoader
-	logger      h.IntegrationLogger
-	client      client.Client
-	context     context.Context
+	component     *applicationapiv1alpha1.Component
+	application   *applicationapiv1alpha1.Application
+	loader        loader.ObjectLoader
+	logger        h.IntegrationLogger
+	client        client.Client
+	recorder      record.EventRecorder
+	imageVerifier *signature.Verifier
+	context       context.Context
 }
 
 // NewAdapter creates and returns an Adapter instance.
 func NewAdapter(context context.Context, component *applicationapiv1alpha1.Component, application *applicationapiv1alpha1.Application,
-	logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client,
+	logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client, recorder record.EventRecorder,
+	imageVerifier *signature.Verifier,
 ) *Adapter {
 	return &Adapter{
-		component:   component,
-		application: application,
-		logger:      logger,
-		loader:      loader,
-		client:      client,
-		context:     context,
+		component:     component,
+		application:   application,
+		logger:        logger,
+		loader:        loader,
+		client:        client,
+		recorder:      recorder,
+		imageVerifier: imageVerifier,
+		context:       context,
 	}
 }
 
@@ -73,23 +83,29 @@ func (a *Adapter) EnsureComponentHasFinalizer() (controller.OperationResult, err
 }
 
 // EnsureComponentIsCleanedUp is an operation that will ensure components
-// marked for deletion have a snapshot created without said component
+// marked for deletion have a snapshot created without said component, so the required integration tests of
+// the application are run against its global candidate list without the removed component
 func (a *Adapter) EnsureComponentIsCleanedUp() (controller.OperationResult, error) {
 	if !isComponentMarkedForDeletion(a.component) {
 		return controller.ContinueProcessing()
 	}
 
-	applicationComponents, err := a.loader.GetAllApplicationComponents(a.context, a.client, a.application)
-	if err != nil {
-		a.logger.Error(err, "Failed to load application components")
-		return controller.RequeueWithError(err)
-	}
+	// the components of an application being deleted are removed along with it, there's nothing left to test
+	if a.application.DeletionTimestamp.IsZero() {
+		applicationComponents, err := a.loader.GetAllApplicationComponents(a.context, a.client, a.application)
+		if err != nil {
+			a.logger.Error(err, "Failed to load application components")
+			return controller.RequeueWithError(err)
+		}
 
-	var snapshotComponents []applicationapiv1alpha1.SnapshotComponent
+		var snapshotComponents []applicationapiv1alpha1.SnapshotComponent
 
-	for _, individualComponent := range *applicationComponents {
-		component := individualComponent
-		if a.component.Name != component.Name {
+		for _, individualComponent := range *applicationComponents {
+			component := individualComponent
+			// skip the other components being removed too, and the ones which were never built
+			if a.component.Name == component.Name || isComponentMarkedForDeletion(&component) || component.Spec.ContainerImage == "" {
+				continue
+			}
 			containerImage := component.Spec.ContainerImage
 			componentSource := gitops.GetComponentSourceFromComponent(&component)
 			snapshotComponents = append(snapshotComponents, applicationapiv1alpha1.SnapshotComponent{
@@ -98,15 +114,17 @@ func (a *Adapter) EnsureComponentIsCleanedUp() (controller.OperationResult, erro
 				Source:         *componentSource,
 			})
 		}
-	}
 
-	if len(snapshotComponents) != 0 {
-		_, err = a.createUpdatedSnapshot(&snapshotComponents)
-		if err != nil {
-			a.logger.Error(err, "Failed to create new snapshot after component deletion")
-			return controller.RequeueWithError(err)
+		if len(snapshotComponents) != 0 {
+			_, err = a.createUpdatedSnapshot(applicationComponents, &snapshotComponents)
+			if err != nil {
+				a.logger.Error(err, "Failed to create new snapshot after component deletion")
+				return controller.RequeueWithError(err)
+			}
 		}
 	}
+
+	var err error
 	// STONEINTG-828: We are refreshing state of component to minimize race condition with updating finalizers
 	a.component, err = a.loader.GetComponent(a.context, a.client, a.component.Name, a.component.Namespace)
 
@@ -128,9 +146,113 @@ func (a *Adapter) EnsureComponentIsCleanedUp() (controller.OperationResult, erro
 
 }
 
+// EnsurePushedImageSnapshotExists is an operation that will ensure that a Snapshot exists for the image pushed to the
+// image repository of the component without a build in the cluster, as announced through the PushedImageAnnotation of
+// the component, so that the externally built images are tested by the same integration tests as the built ones.
+// The Snapshot already created for the pushed image, e.g. when recording the composed image on the component failed
+// before, isn't created again.
+func (a *Adapter) EnsurePushedImageSnapshotExists() (controller.OperationResult, error) {
+	pushedImage := a.component.GetAnnotations()[gitops.PushedImageAnnotation]
+	if isComponentMarkedForDeletion(a.component) || pushedImage == "" ||
+		metadata.HasAnnotationWithValue(a.component, gitops.ComposedPushedImageAnnotation, pushedImage) {
+		return controller.ContinueProcessing()
+	}
+
+	allSnapshots, err := a.loader.GetAllSnapshots(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to load the snapshots of the application")
+		return controller.RequeueWithError(err)
+	}
+	if existingSnapshot := gitops.GetSnapshotWithPushedImage(allSnapshots, a.component.Name, pushedImage); existingSnapshot != nil {
+		a.logger.Info("The snapshot for the pushed image already exists", "image", pushedImage, "snapshot.Name", existingSnapshot.Name)
+		return a.markPushedImageAsComposed(pushedImage)
+	}
+
+	applicationComponents, err := a.loader.GetAllApplicationComponents(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to load application components")
+		return controller.RequeueWithError(err)
+	}
+
+	snapshot, err := a.prepareSnapshotForPushedImage(applicationComponents, pushedImage)
+	if h.IsInvalidImageDigestError(err) || h.IsMissingValidComponentError(err) {
+		// the annotation has to be changed to a valid image, there's no point in retrying
+		a.logger.Error(err, "Failed to prepare a snapshot for the pushed image", "image", pushedImage)
+		a.recorder.Eventf(a.component, corev1.EventTypeWarning, string(reasons.PushedImageInvalidEventReason),
+			"No Snapshot can be created for the pushed image %s: %s", pushedImage, err.Error())
+		return a.markPushedImageAsComposed(pushedImage)
+	} else if err != nil {
+		a.logger.Error(err, "Failed to prepare a snapshot for the pushed image", "image", pushedImage)
+		return controller.RequeueWithError(err)
+	}
+
+	err = a.client.Create(a.context, snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to create the snapshot for the pushed image", "image", pushedImage)
+		return controller.RequeueWithError(err)
+	}
+	go metrics.RegisterNewSnapshot()
+	go metrics.RegisterSnapshotCreated(snapshot.Namespace, snapshot.Spec.Application)
+
+	a.logger.LogAuditEvent("Created new Snapshot for the pushed image", snapshot, h.LogActionAdd,
+		"snapshot.Name", snapshot.Name,
+		"snapshot.Spec.Components", snapshot.Spec.Components)
+	a.recorder.Eventf(snapshot, corev1.EventTypeNormal, string(reasons.SnapshotCreatedEventReason),
+		"Snapshot created for the image %s pushed for component %s", pushedImage, a.component.Name)
+
+	return a.markPushedImageAsComposed(pushedImage)
+}
+
+// prepareSnapshotForPushedImage prepares a Snapshot of the application holding the given image pushed for the
+// component along with the images of the other components. The pushed image is verified just like the built images.
+func (a *Adapter) prepareSnapshotForPushedImage(applicationComponents *[]applicationapiv1alpha1.Component, pushedImage string) (*applicationapiv1alpha1.Snapshot, error) {
+	snapshot, err := gitops.PrepareSnapshot(a.context, a.client, a.application, applicationComponents, a.component,
+		pushedImage, gitops.GetComponentSourceFromComponent(a.component))
+	if err != nil {
+		return nil, err
+	}
+	gitops.CopySnapshotLabelsAndAnnotation(a.application, snapshot, a.component.Name, &a.component.ObjectMeta, gitops.BuildPipelineRunPrefix, false)
+	if err = metadata.SetAnnotation(snapshot, gitops.PushedImageAnnotation, pushedImage); err != nil {
+		return nil, err
+	}
+
+	// the pushed image has no known architecture digests nor SBOM, unlike the images of the other components
+	architectureDigests := gitops.GetComponentsArchitectureDigests(applicationComponents, snapshot)
+	delete(architectureDigests, a.component.Name)
+	if err = gitops.SetSnapshotArchitectureDigests(snapshot, architectureDigests); err != nil {
+		return nil, err
+	}
+	sboms := gitops.GetComponentsSBOMs(applicationComponents, snapshot)
+	delete(sboms, a.component.Name)
+	if err = gitops.SetSnapshotSBOMs(snapshot, sboms); err != nil {
+		return nil, err
+	}
+
+	if _, err = gitops.VerifySnapshotImage(a.context, a.imageVerifier, snapshot, a.component.Name, pushedImage); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// markPushedImageAsComposed records the pushed image in the ComposedPushedImageAnnotation of the component, so that
+// no other Snapshot is created for it.
+func (a *Adapter) markPushedImageAsComposed(pushedImage string) (controller.OperationResult, error) {
+	err := h.ApplyMetadata(a.context, a.client, a.component, nil, map[string]string{gitops.ComposedPushedImageAnnotation: pushedImage})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return controller.ContinueProcessing()
+		}
+		a.logger.Error(err, "Failed to annotate the component with the composed pushed image", "image", pushedImage)
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
 // createUpdatedSnapshot prepares a Snapshot for a given application and component(s).
 // In case the Snapshot can't be created, an error will be returned.
-func (a *Adapter) createUpdatedSnapshot(snapshotComponents *[]applicationapiv1alpha1.SnapshotComponent) (*applicationapiv1alpha1.Snapshot, error) {
+func (a *Adapter) createUpdatedSnapshot(applicationComponents *[]applicationapiv1alpha1.Component, snapshotComponents *[]applicationapiv1alpha1.SnapshotComponent) (*applicationapiv1alpha1.Snapshot, error) {
 	snapshot := gitops.NewSnapshot(a.application, snapshotComponents)
 	if snapshot.Labels == nil {
 		snapshot.Labels = map[string]string{}
@@ -140,8 +262,22 @@ func (a *Adapter) createUpdatedSnapshot(snapshotComponents *[]applicationapiv1al
 		snapshotType = gitops.SnapshotComponentType
 	}
 	snapshot.Labels[gitops.SnapshotTypeLabel] = snapshotType
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[gitops.SnapshotRemovedComponentAnnotation] = a.component.Name
+	err := gitops.SetSnapshotArchitectureDigests(snapshot, gitops.GetComponentsArchitectureDigests(applicationComponents, snapshot))
+	if err != nil {
+		a.logger.Error(err, "Failed to set the architecture digests of the snapshot")
+		return nil, err
+	}
+	err = gitops.SetSnapshotSBOMs(snapshot, gitops.GetComponentsSBOMs(applicationComponents, snapshot))
+	if err != nil {
+		a.logger.Error(err, "Failed to set the SBOMs of the snapshot")
+		return nil, err
+	}
 
-	err := ctrl.SetControllerReference(a.application, snapshot, a.client.Scheme())
+	err = ctrl.SetControllerReference(a.application, snapshot, a.client.Scheme())
 	if err != nil {
 		a.logger.Error(err, "Failed to set controller reference")
 		return nil, err
@@ -154,6 +290,9 @@ func (a *Adapter) createUpdatedSnapshot(snapshotComponents *[]applicationapiv1al
 	}
 
 	go metrics.RegisterNewSnapshot()
+	go metrics.RegisterSnapshotCreated(snapshot.Namespace, snapshot.Spec.Application)
+	a.recorder.Eventf(snapshot, corev1.EventTypeNormal, string(reasons.SnapshotCreatedEventReason),
+		"Snapshot created with the updated global candidate list after component %s was removed", a.component.Name)
 	return snapshot, nil
 }
 
@@ -176,3 +315,17 @@ func hasComponentChangedToDeleting(objectOld, objectNew client.Object) bool {
 
 	return false
 }
+
+// hasComponentPushedImageChanged returns a boolean indicating whether a new image was announced through the
+// PushedImageAnnotation of the Component. If the objects passed to this function is not a Component, the function
+// will return false.
+func hasComponentPushedImageChanged(objectOld, objectNew client.Object) bool {
+	if oldComponent, ok := objectOld.(*applicationapiv1alpha1.Component); ok {
+		if newComponent, ok := objectNew.(*applicationapiv1alpha1.Component); ok {
+			newImage := newComponent.GetAnnotations()[gitops.PushedImageAnnotation]
+			return newImage != "" && oldComponent.GetAnnotations()[gitops.PushedImageAnnotation] != newImage
+		}
+	}
+
+	return false
+}
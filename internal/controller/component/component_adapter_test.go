@@ -3,6 +3,7 @@ package component
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/tonglil/buflogr"
@@ -10,13 +11,16 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/loader"
 	toolkit "github.com/konflux-ci/operator-toolkit/loader"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -109,13 +113,13 @@ var _ = Describe("Component Adapter", Ordered, func() {
 	})
 
 	It("can create a new Adapter instance", func() {
-		Expect(reflect.TypeOf(NewAdapter(ctx, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient))).To(Equal(reflect.TypeOf(&Adapter{})))
+		Expect(reflect.TypeOf(NewAdapter(ctx, hasComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil))).To(Equal(reflect.TypeOf(&Adapter{})))
 	})
 	It("ensures removing a component will result in a new snapshot being created", func() {
 		buf := bytes.Buffer{}
 
 		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-		adapter = NewAdapter(ctx, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient)
+		adapter = NewAdapter(ctx, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
 		adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 			{
 				ContextKey: loader.ApplicationContextKey,
@@ -143,4 +147,197 @@ var _ = Describe("Component Adapter", Ordered, func() {
 		}, time.Second*20).Should(BeTrue())
 	})
 
+	It("ensures the Snapshot created after removing a component omits the other removed and unbuilt components", func() {
+		buf := bytes.Buffer{}
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		removedComp := hasComp2.DeepCopy()
+		removedComp.Name = "component-removed-too"
+		deletionTime := metav1.Now()
+		removedComp.SetDeletionTimestamp(&deletionTime)
+		unbuiltComp := hasComp2.DeepCopy()
+		unbuiltComp.Name = "component-unbuilt"
+		unbuiltComp.Spec.ContainerImage = ""
+
+		adapter = NewAdapter(ctx, hasComp, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
+		adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+			{
+				ContextKey: loader.ApplicationComponentsContextKey,
+				Resource:   []applicationapiv1alpha1.Component{*hasComp, *hasComp2, *removedComp, *unbuiltComp},
+			},
+		})
+		hasComp.SetDeletionTimestamp(&deletionTime)
+
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+		existing := len(snapshots.Items)
+
+		result, err := adapter.EnsureComponentIsCleanedUp()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+
+		Eventually(func() int {
+			Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+			return len(snapshots.Items)
+		}, time.Second*20).Should(BeNumerically(">", existing))
+		var created *applicationapiv1alpha1.Snapshot
+		for i := range snapshots.Items {
+			if len(snapshots.Items[i].Spec.Components) == 1 && snapshots.Items[i].Spec.Components[0].Name == hasComp2.Name {
+				created = &snapshots.Items[i]
+			}
+		}
+		Expect(created).NotTo(BeNil())
+		Expect(created.Annotations).To(HaveKeyWithValue(gitops.SnapshotRemovedComponentAnnotation, hasComp.Name))
+		Expect(gitops.IsSnapshotCreatedForComponentRemoval(created)).To(BeTrue())
+	})
+
+	It("ensures no Snapshot is created for the components of an application being deleted", func() {
+		buf := bytes.Buffer{}
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+
+		deletedApp := hasApp.DeepCopy()
+		deletionTime := metav1.Now()
+		deletedApp.SetDeletionTimestamp(&deletionTime)
+		adapter = NewAdapter(ctx, hasComp, deletedApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
+		adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+			{
+				ContextKey: loader.ApplicationComponentsContextKey,
+				Resource:   []applicationapiv1alpha1.Component{*hasComp, *hasComp2},
+			},
+		})
+		hasComp.SetDeletionTimestamp(&deletionTime)
+
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+		existing := len(snapshots.Items)
+
+		result, err := adapter.EnsureComponentIsCleanedUp()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Consistently(func() int {
+			Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+			return len(snapshots.Items)
+		}, time.Second*2).Should(Equal(existing))
+	})
+
+	It("ensures a Snapshot is created once for the image pushed for a component", func() {
+		pushedImage := SampleImageWithoutDigest + "@sha256:" + strings.Repeat("a", 64)
+		pushedComp := &applicationapiv1alpha1.Component{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(hasComp2), pushedComp)).To(Succeed())
+		pushedComp.Annotations = map[string]string{gitops.PushedImageAnnotation: pushedImage}
+		Expect(k8sClient.Update(ctx, pushedComp)).To(Succeed())
+
+		adapter = NewAdapter(ctx, pushedComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
+		adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+			{
+				ContextKey: loader.ApplicationComponentsContextKey,
+				Resource:   []applicationapiv1alpha1.Component{*hasComp2},
+			},
+		})
+
+		getPushedImageSnapshots := func() []applicationapiv1alpha1.Snapshot {
+			snapshots := &applicationapiv1alpha1.SnapshotList{}
+			Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+			var pushedImageSnapshots []applicationapiv1alpha1.Snapshot
+			for _, snapshot := range snapshots.Items {
+				if snapshot.Annotations[gitops.PushedImageAnnotation] == pushedImage {
+					pushedImageSnapshots = append(pushedImageSnapshots, snapshot)
+				}
+			}
+			return pushedImageSnapshots
+		}
+
+		result, err := adapter.EnsurePushedImageSnapshotExists()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Eventually(getPushedImageSnapshots, time.Second*20).Should(HaveLen(1))
+		snapshot := getPushedImageSnapshots()[0]
+		Expect(snapshot.Spec.Components).To(HaveLen(1))
+		Expect(snapshot.Spec.Components[0].ContainerImage).To(Equal(pushedImage))
+		Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.SnapshotTypeLabel, gitops.SnapshotComponentType))
+		Expect(snapshot.Labels).To(HaveKeyWithValue(gitops.SnapshotComponentLabel, hasComp2.Name))
+		Expect(pushedComp.Annotations).To(HaveKeyWithValue(gitops.ComposedPushedImageAnnotation, pushedImage))
+
+		// the image was already composed into a Snapshot
+		result, err = adapter.EnsurePushedImageSnapshotExists()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Consistently(getPushedImageSnapshots, time.Second*2).Should(HaveLen(1))
+	})
+
+	It("ensures the existing Snapshot of the pushed image is reused", func() {
+		pushedImage := SampleImageWithoutDigest + "@sha256:" + strings.Repeat("c", 64)
+		pushedComp := &applicationapiv1alpha1.Component{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(hasComp2), pushedComp)).To(Succeed())
+		pushedComp.Annotations = map[string]string{gitops.PushedImageAnnotation: pushedImage}
+		Expect(k8sClient.Update(ctx, pushedComp)).To(Succeed())
+
+		existingSnapshot := applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "snapshot-pushed-image",
+				Namespace:   hasApp.Namespace,
+				Labels:      map[string]string{gitops.SnapshotComponentLabel: pushedComp.Name},
+				Annotations: map[string]string{gitops.PushedImageAnnotation: pushedImage},
+			},
+		}
+		adapter = NewAdapter(ctx, pushedComp, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}, nil)
+		adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+			{
+				ContextKey: loader.AllSnapshotsContextKey,
+				Resource:   []applicationapiv1alpha1.Snapshot{existingSnapshot},
+			},
+		})
+
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+		existing := len(snapshots.Items)
+
+		result, err := adapter.EnsurePushedImageSnapshotExists()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(pushedComp.Annotations).To(HaveKeyWithValue(gitops.ComposedPushedImageAnnotation, pushedImage))
+		Consistently(func() int {
+			Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+			return len(snapshots.Items)
+		}, time.Second*2).Should(Equal(existing))
+	})
+
+	It("ensures no Snapshot is created for a pushed image without a digest", func() {
+		pushedComp := &applicationapiv1alpha1.Component{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(hasComp2), pushedComp)).To(Succeed())
+		pushedComp.Annotations[gitops.PushedImageAnnotation] = SampleImageWithoutDigest + ":latest"
+		Expect(k8sClient.Update(ctx, pushedComp)).To(Succeed())
+
+		recorder := record.NewFakeRecorder(1)
+		adapter = NewAdapter(ctx, pushedComp, hasApp, logger, loader.NewMockLoader(), k8sClient, recorder, nil)
+		adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+			{
+				ContextKey: loader.ApplicationComponentsContextKey,
+				Resource:   []applicationapiv1alpha1.Component{*hasComp2},
+			},
+		})
+
+		snapshots := &applicationapiv1alpha1.SnapshotList{}
+		Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+		existing := len(snapshots.Items)
+
+		result, err := adapter.EnsurePushedImageSnapshotExists()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring(string(reasons.PushedImageInvalidEventReason))))
+		Expect(pushedComp.Annotations).To(HaveKeyWithValue(gitops.ComposedPushedImageAnnotation, SampleImageWithoutDigest+":latest"))
+		Consistently(func() int {
+			Expect(k8sClient.List(ctx, snapshots, &client.ListOptions{Namespace: hasApp.Namespace})).To(Succeed())
+			return len(snapshots.Items)
+		}, time.Second*2).Should(Equal(existing))
+	})
+
+	It("detects the changes of the pushed image of a component", func() {
+		oldComp := hasComp2.DeepCopy()
+		newComp := hasComp2.DeepCopy()
+		newComp.Annotations = map[string]string{gitops.PushedImageAnnotation: SampleImage}
+		Expect(hasComponentPushedImageChanged(oldComp, newComp)).To(BeTrue())
+		Expect(hasComponentPushedImageChanged(newComp, newComp)).To(BeFalse())
+		Expect(hasComponentPushedImageChanged(newComp, oldComp)).To(BeFalse())
+	})
 })
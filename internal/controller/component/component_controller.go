@@ -21,12 +21,18 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/signature"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
 	"github.com/konflux-ci/operator-toolkit/controller"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -35,16 +41,20 @@ import (
 // Reconciler reconciles a component object
 type Reconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ImageVerifier verifies the images of the Snapshots, it's nil when image verification is disabled
+	ImageVerifier *signature.Verifier
 }
 
 // NewComponentReconciler creates and returns a Reconciler.
-func NewComponentReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme) *Reconciler {
+func NewComponentReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
-		Client: client,
-		Log:    logger.WithName("component"),
-		Scheme: scheme,
+		Client:   client,
+		Log:      logger.WithName("component"),
+		Scheme:   scheme,
+		Recorder: recorder,
 	}
 }
 
@@ -55,7 +65,8 @@ func NewComponentReconciler(client client.Client, logger *logr.Logger, scheme *r
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("component", req.NamespacedName)}
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("component", req.NamespacedName, correlation.LogKey, correlationID)}
 	loader := loader.NewLoader()
 
 	component := &applicationapiv1alpha1.Component{}
@@ -67,6 +78,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 		return ctrl.Result{}, err
 	}
+	logger.Logger = correlation.WithParentID(logger.Logger, component)
 
 	var application *applicationapiv1alpha1.Application
 	application, err = loader.GetApplicationFromComponent(ctx, r.Client, component)
@@ -86,33 +98,46 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 	logger = logger.WithApp(*application)
 
-	adapter := NewAdapter(ctx, component, application, logger, loader, r.Client)
+	ctx, span := tracing.StartReconcileSpan(ctx, "component", map[string]client.Object{"component": component, "application": application})
+	defer span.End()
 
-	return controller.ReconcileHandler([]controller.Operation{
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, component, application, logger, loader, correlation.NewClient(r.Client, correlationID), correlation.NewRecorder(r.Recorder, correlationID), r.ImageVerifier)
+
+	return operations.NewChain("component",
 		adapter.EnsureComponentHasFinalizer,
+		adapter.EnsurePushedImageSnapshotExists,
 		adapter.EnsureComponentIsCleanedUp,
-	})
+	).Run(ctx)
 }
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
 	EnsureComponentHasFinalizer() (controller.OperationResult, error)
+	EnsurePushedImageSnapshotExists() (controller.OperationResult, error)
 	EnsureComponentIsCleanedUp() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Component controller and adds it to the Manager.
-func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewComponentReconciler(manager.GetClient(), log, manager.GetScheme()))
+func SetupController(manager ctrl.Manager, log *logr.Logger, imageVerifier *signature.Verifier, eventFilters ...predicate.Predicate) error {
+	reconciler := NewComponentReconciler(manager.GetClient(), log, manager.GetScheme(),
+		manager.GetEventRecorderFor(gitops.IntegrationServiceEventRecorderName))
+	reconciler.ImageVerifier = imageVerifier
+	return setupControllerWithManager(manager, reconciler, eventFilters...)
 
 }
 
 // setupControllerWithManager sets up the controller with the Manager which monitors Components and filters
 // out status updates.
-func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
 	return ctrl.NewControllerManagedBy(manager).
 		For(&applicationapiv1alpha1.Component{}).
 		WithEventFilter(predicate.Or(
 			ComponentCreatedPredicate(),
-			ComponentDeletedPredicate())).
+			ComponentDeletedPredicate(),
+			ComponentImagePushedPredicate())).
+		WithEventFilter(predicate.And(eventFilters...)).
 		Complete(controller)
 }
@@ -4,6 +4,7 @@ import (
 	"reflect"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -101,7 +102,7 @@ var _ = Describe("ComponentController", Ordered, func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(err).To(BeNil())
 
-		componentReconciler = NewComponentReconciler(k8sClient, &logf.Log, &scheme)
+		componentReconciler = NewComponentReconciler(k8sClient, &logf.Log, &scheme, &record.FakeRecorder{})
 	})
 	AfterAll(func() {
 		err := k8sClient.Delete(ctx, hasApp)
@@ -148,7 +149,7 @@ var _ = Describe("ComponentController", Ordered, func() {
 	})
 
 	It("can setup a new Controller manager and start it", func() {
-		err := SetupController(manager, &ctrl.Log)
+		err := SetupController(manager, &ctrl.Log, nil)
 		Expect(err).To(BeNil())
 	})
 
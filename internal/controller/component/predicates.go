@@ -44,3 +44,22 @@ func ComponentDeletedPredicate() predicate.Predicate {
 		},
 	}
 }
+
+// ComponentImagePushedPredicate returns a predicate which filters out
+// only components whose pushed image annotation changed to a new image.
+func ComponentImagePushedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return hasComponentPushedImageChanged(e.ObjectOld, e.ObjectNew)
+		},
+	}
+}
@@ -23,28 +23,44 @@ import (
 	"github.com/konflux-ci/integration-service/internal/controller/integrationpipeline"
 	"github.com/konflux-ci/integration-service/internal/controller/scenario"
 	"github.com/konflux-ci/integration-service/internal/controller/snapshot"
+	"github.com/konflux-ci/integration-service/internal/controller/snapshotrun"
 	"github.com/konflux-ci/integration-service/internal/controller/statusreport"
+	"github.com/konflux-ci/integration-service/pkg/signature"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // setupFunctions is a list of register functions to be invoked so all controllers are added to the Manager
-var setupFunctions = []func(manager.Manager, *logr.Logger) error{
+var setupFunctions = []func(manager.Manager, *logr.Logger, ...predicate.Predicate) error{
 	integrationpipeline.SetupController,
-	buildpipeline.SetupController,
 	snapshot.SetupController,
 	scenario.SetupController,
 	statusreport.SetupController,
+	snapshotrun.SetupController,
+}
+
+// imageVerifyingSetupFunctions is a list of register functions of the controllers verifying the images of the
+// Snapshots, which share the Verifier built when the operator starts
+var imageVerifyingSetupFunctions = []func(manager.Manager, *logr.Logger, *signature.Verifier, ...predicate.Predicate) error{
+	buildpipeline.SetupController,
 	component.SetupController,
 }
 
-// SetupControllers invoke all SetupController functions defined in setupFunctions, setting all controllers up and
-// adding them to the Manager.
-func SetupControllers(manager manager.Manager) error {
+// SetupControllers invoke all SetupController functions defined in setupFunctions and imageVerifyingSetupFunctions,
+// setting all controllers up and adding them to the Manager. The images are verified with the given Verifier, which
+// is nil when image verification is disabled. The event filters are applied to the events of all controllers, on top
+// of their own.
+func SetupControllers(manager manager.Manager, imageVerifier *signature.Verifier, eventFilters ...predicate.Predicate) error {
 	log := logf.Log.WithName("controllers")
 
 	for _, function := range setupFunctions {
-		if err := function(manager, &log); err != nil {
+		if err := function(manager, &log, eventFilters...); err != nil {
+			return err
+		}
+	}
+	for _, function := range imageVerifyingSetupFunctions {
+		if err := function(manager, &log, imageVerifier, eventFilters...); err != nil {
 			return err
 		}
 	}
@@ -20,16 +20,28 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/integration-service/gitops"
 	h "github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/dryrun"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/pkg/reportportal"
+	"github.com/konflux-ci/integration-service/status"
 	"github.com/konflux-ci/integration-service/tekton"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
 	"github.com/konflux-ci/operator-toolkit/controller"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -65,7 +77,9 @@ func NewAdapter(context context.Context, pipelineRun *tektonv1.PipelineRun, appl
 func (a *Adapter) EnsureStatusReportedInSnapshot() (controller.OperationResult, error) {
 	var pipelinerunStatus intgteststat.IntegrationTestStatus
 	var detail string
+	var testResultsSummary *intgteststat.TestResultsSummary
 	var err error
+	var statusChangedToFinal bool
 
 	// pipelines run in parallel and have great potential to cause conflict on update
 	// thus `RetryOnConflict` is easy solution here, given the snapshot must be loaded specifically here
@@ -81,14 +95,25 @@ func (a *Adapter) EnsureStatusReportedInSnapshot() (controller.OperationResult,
 			return err
 		}
 
-		pipelinerunStatus, detail, err = a.GetIntegrationPipelineRunStatus(a.context, a.client, a.pipelineRun)
+		pipelinerunStatus, detail, testResultsSummary, err = a.GetIntegrationPipelineRunStatus(a.context, a.client, a.pipelineRun)
 		if err != nil {
 			return err
 		}
-		statuses.UpdateTestStatusIfChanged(a.pipelineRun.Labels[tekton.ScenarioNameLabel], pipelinerunStatus, detail)
+		scenarioName := a.pipelineRun.Labels[tekton.ScenarioNameLabel]
+		previousStatus, ok := statuses.GetScenarioStatus(scenarioName)
+		if ok && previousStatus.TestPipelineRunName == a.pipelineRun.Name && previousStatus.Status.IsFinal() && !pipelinerunStatus.IsFinal() {
+			// the result of the running pipelineRun was already decided, e.g. it was detected as stuck
+			statusChangedToFinal = false
+			return nil
+		}
+		statusChangedToFinal = pipelinerunStatus.IsFinal() && (!ok || previousStatus.Status != pipelinerunStatus)
+		statuses.UpdateTestStatusIfChanged(scenarioName, pipelinerunStatus, detail)
 		if err = statuses.UpdateTestPipelineRunName(a.pipelineRun.Labels[tekton.ScenarioNameLabel], a.pipelineRun.Name); err != nil {
 			return err
 		}
+		if err = statuses.UpdateTestResultsSummary(scenarioName, testResultsSummary); err != nil {
+			return err
+		}
 
 		// don't return wrapped err for retries
 		err = gitops.WriteIntegrationTestStatusesIntoSnapshot(a.context, a.snapshot, statuses, a.client)
@@ -99,6 +124,15 @@ func (a *Adapter) EnsureStatusReportedInSnapshot() (controller.OperationResult,
 		return controller.RequeueWithError(fmt.Errorf("failed to update test status in snapshot: %w", err))
 	}
 
+	if statusChangedToFinal {
+		go metrics.RegisterIntegrationTestScenarioResult(a.snapshot.Namespace, a.snapshot.Spec.Application,
+			a.pipelineRun.Labels[tekton.ScenarioNameLabel], pipelinerunStatus.String())
+		if a.pipelineRun.Status.StartTime != nil && a.pipelineRun.Status.CompletionTime != nil {
+			go metrics.RegisterIntegrationPipelineRunDuration(a.snapshot.Namespace, a.snapshot.Spec.Application,
+				a.pipelineRun.Status.StartTime, a.pipelineRun.Status.CompletionTime)
+		}
+	}
+
 	// Remove the finalizer from Integration PLRs only if they are related to Snapshots created by Push event
 	// If they are related, then the statusreport controller removes the finalizers from these PLRs
 	if gitops.IsSnapshotCreatedByPACPushEvent(a.snapshot) && (h.HasPipelineRunFinished(a.pipelineRun) || pipelinerunStatus == intgteststat.IntegrationTestStatusDeleted) {
@@ -111,21 +145,441 @@ func (a *Adapter) EnsureStatusReportedInSnapshot() (controller.OperationResult,
 	return controller.ContinueProcessing()
 }
 
-// GetIntegrationPipelineRunStatus checks the Tekton results for a given PipelineRun and returns status of test.
-func (a *Adapter) GetIntegrationPipelineRunStatus(ctx context.Context, adapterClient client.Client, pipelineRun *tektonv1.PipelineRun) (intgteststat.IntegrationTestStatus, string, error) {
+// EnsureScenarioRunHistoryRecorded will ensure that the result of the finished integration test pipeline is recorded
+// in the run history of its IntegrationTestScenario, together with the rolling pass rate and duration computed from
+// the history, which are also updated in the metrics, and that the scenario is flagged as flaky once its run history
+// looks flaky. The runs of the scenarios already flagged as flaky aren't recorded, so that the flag is only raised
+// again from new runs once it's cleared.
+func (a *Adapter) EnsureScenarioRunHistoryRecorded() (controller.OperationResult, error) {
+	result, scenarioName, err := a.getFinishedRunResult()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if result == nil {
+		return controller.ContinueProcessing()
+	}
+
+	var application string
+	var trend *gitops.ScenarioTrend
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		trend = nil
+		scenario, err := a.loader.GetScenario(a.context, a.client, scenarioName, a.pipelineRun.Namespace)
+		if err != nil {
+			return err
+		}
+		if h.IsScenarioFlaky(scenario) {
+			return nil
+		}
+		application = scenario.Spec.Application
+
+		patch := client.MergeFromWithOptions(scenario.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if gitops.RecordScenarioRunResult(scenario, *result) {
+			recordedTrend := gitops.ComputeScenarioTrend(scenario.Status.RunHistory)
+			scenario.Status.Trend = recordedTrend.Status()
+			if err = a.client.Status().Patch(a.context, scenario, patch); err != nil {
+				return err
+			}
+			trend = &recordedTrend
+		}
+
+		flaky, reason := gitops.IsScenarioRunHistoryFlaky(scenario.Status.RunHistory)
+		if !flaky {
+			return nil
+		}
+		patch = client.MergeFromWithOptions(scenario.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		_ = metadata.SetAnnotation(&scenario.ObjectMeta, h.FlakyScenarioAnnotation, reason)
+		if err = a.client.Patch(a.context, scenario, patch); err != nil {
+			return err
+		}
+		a.logger.LogAuditEvent("Flagged the IntegrationTestScenario as flaky", scenario, h.LogActionUpdate,
+			"reason", reason,
+			"quarantined", h.IsScenarioQuarantined(scenario))
+
+		// the history is started over so that the scenario isn't flagged again from the same runs once the flag is
+		// cleared, the trend computed from the history is kept until the next recorded run
+		patch = client.MergeFromWithOptions(scenario.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		scenario.Status.RunHistory = nil
+		return a.client.Status().Patch(a.context, scenario, patch)
+	})
+	if errors.IsNotFound(err) {
+		// the scenario was deleted, or the pipelineRun tests the Enterprise Contract policy of the application
+		return controller.ContinueProcessing()
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to record the run in the run history of the IntegrationTestScenario",
+			"integrationTestScenario.Name", scenarioName)
+		return controller.RequeueWithError(err)
+	}
+	if trend != nil {
+		go metrics.RegisterIntegrationTestScenarioTrend(a.pipelineRun.Namespace, application, scenarioName,
+			trend.PassRate(), trend.AverageDuration)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// getFinishedRunResult returns the result of the finished integration test pipeline and the name of its
+// IntegrationTestScenario, or nil if the pipelineRun didn't finish, or its status isn't the outcome of the tests.
+// The runs of the matrix cells are the runs of their scenario.
+func (a *Adapter) getFinishedRunResult() (*v1beta2.ScenarioRunResult, string, error) {
+	if !h.HasPipelineRunFinished(a.pipelineRun) {
+		return nil, "", nil
+	}
+
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		return nil, "", err
+	}
+	detail, ok := testStatuses.GetScenarioStatus(a.pipelineRun.Labels[tekton.ScenarioNameLabel])
+	if !ok || detail.TestPipelineRunName != a.pipelineRun.Name {
+		return nil, "", nil
+	}
+	passed, ok := gitops.GetScenarioRunOutcome(detail.Status)
+	if !ok {
+		return nil, "", nil
+	}
+
+	scenarioName := a.pipelineRun.Labels[tekton.ScenarioNameLabel]
+	if matrixScenario, ok := a.pipelineRun.Labels[tekton.MatrixScenarioLabel]; ok {
+		scenarioName = matrixScenario
+	}
+	result := &v1beta2.ScenarioRunResult{
+		Snapshot:       a.snapshot.Name,
+		PipelineRun:    a.pipelineRun.Name,
+		Passed:         passed,
+		CompletionTime: metav1.Now(),
+	}
+	if a.pipelineRun.Status.CompletionTime != nil {
+		result.CompletionTime = *a.pipelineRun.Status.CompletionTime
+		if a.pipelineRun.Status.StartTime != nil {
+			result.Duration = metav1.Duration{Duration: result.CompletionTime.Sub(a.pipelineRun.Status.StartTime.Time)}
+		}
+	}
+
+	return result, scenarioName, nil
+}
+
+// EnsureStuckPipelineRunDetected will ensure that the integration test pipeline which made no status progress for
+// longer than the configured timeout, e.g. because its pipeline can't be resolved or the Tekton webhook is missing,
+// is marked as errored in the test status of the snapshot. The running pipelineRuns are requeued to be checked
+// again once they would be stuck for longer than the timeout.
+func (a *Adapter) EnsureStuckPipelineRunDetected() (controller.OperationResult, error) {
+	if h.HasPipelineRunFinished(a.pipelineRun) || a.pipelineRun.GetDeletionTimestamp() != nil {
+		return controller.ContinueProcessing()
+	}
+
+	timeout, err := tekton.GetStuckPipelineRunTimeout()
+	if err != nil {
+		a.logger.Error(err, "Failed to get the timeout of the stuck integration pipelineRuns, using the default one")
+	}
+	if timeout == 0 {
+		return controller.ContinueProcessing()
+	}
+	stuckFor := time.Since(tekton.GetLastProgressTime(a.pipelineRun))
+	if stuckFor < timeout {
+		return controller.RequeueAfter(timeout-stuckFor, nil)
+	}
+
+	scenarioName := a.pipelineRun.Labels[tekton.ScenarioNameLabel]
+	detail := fmt.Sprintf("Integration test pipelineRun '%s' made no status progress for %s, it might be stuck, e.g. on "+
+		"resolving its pipeline or because the Tekton webhook is missing", a.pipelineRun.Name, timeout)
+	marked := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		a.snapshot, err = a.loader.GetSnapshotFromPipelineRun(a.context, a.client, a.pipelineRun)
+		if err != nil {
+			return err
+		}
+		statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+		if err != nil {
+			return err
+		}
+		testStatus, ok := statuses.GetScenarioStatus(scenarioName)
+		if !ok || testStatus.TestPipelineRunName != a.pipelineRun.Name || testStatus.Status.IsFinal() {
+			marked = false
+			return nil
+		}
+
+		statuses.UpdateTestStatusIfChanged(scenarioName, intgteststat.IntegrationTestStatusTestError, detail)
+		marked = true
+		return gitops.WriteIntegrationTestStatusesIntoSnapshot(a.context, a.snapshot, statuses, a.client)
+	})
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the stuck integration pipelineRun as errored in the snapshot")
+		return controller.RequeueWithError(err)
+	}
+	if marked {
+		a.logger.LogAuditEvent("Marked the test of the stuck integration pipelineRun as errored", a.snapshot, h.LogActionUpdate,
+			"pipelineRun.Name", a.pipelineRun.Name,
+			"integrationTestScenario.Name", scenarioName,
+			"stuckFor", stuckFor.Round(time.Second).String())
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureResultsExportedToReportPortal will ensure that the results of the finished integration test pipeline
+// are exported to the ReportPortal instance configured for its namespace, if there is one. The pipelineRun is
+// annotated with the launch before its items are exported, and a launch whose items couldn't be exported is stopped
+// instead of being exported again, so that the results of a pipelineRun are never split across several launches.
+// The launch is recorded on a copy of the pipelineRun, as the operation runs concurrently with the notifications.
+func (a *Adapter) EnsureResultsExportedToReportPortal() (controller.OperationResult, error) {
+	if !h.HasPipelineRunFinished(a.pipelineRun) || metadata.HasAnnotation(a.pipelineRun, reportportal.LaunchAnnotation) {
+		return controller.ContinueProcessing()
+	}
+
+	secret := &corev1.Secret{}
+	err := a.client.Get(a.context, types.NamespacedName{Namespace: a.pipelineRun.Namespace, Name: reportportal.SecretName}, secret)
+	if errors.IsNotFound(err) {
+		return controller.ContinueProcessing()
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to get the ReportPortal secret", "secret.Name", reportportal.SecretName)
+		return controller.RequeueWithError(err)
+	}
+	rpClient, err := reportportal.NewClientFromSecret(secret)
+	if err != nil {
+		// a misconfigured secret won't be fixed by retrying, so don't block the pipelineRun on it
+		a.logger.Error(err, "Failed to configure the ReportPortal client, skipping the export")
+		return controller.ContinueProcessing()
+	}
+	if dryrun.IsEnabled() {
+		a.logger.Info("Dry-run: skipping the export of the results to ReportPortal")
+		return controller.ContinueProcessing()
+	}
+
+	launch, err := a.newReportPortalLaunch()
+	if err != nil {
+		a.logger.Error(err, "Failed to prepare the ReportPortal launch")
+		return controller.RequeueWithError(err)
+	}
+	launchUUID, err := rpClient.StartLaunch(a.context, launch)
+	if err != nil {
+		a.logger.Error(err, "Failed to start the ReportPortal launch")
+		return controller.RequeueWithError(fmt.Errorf("failed to export the integration test results to ReportPortal: %w", err))
+	}
+
+	// persist the launch before exporting its items, so that a retry doesn't export the results into a second launch
+	err = h.ApplyMetadata(a.context, a.client, a.pipelineRun.DeepCopy(), nil, map[string]string{reportportal.LaunchAnnotation: launchUUID})
+	if err != nil {
+		a.logger.Error(err, "Failed to annotate the pipelineRun with the ReportPortal launch", "launch.UUID", launchUUID)
+		if stopErr := rpClient.StopLaunch(a.context, launchUUID, launch); stopErr != nil {
+			a.logger.Error(stopErr, "Failed to stop the unused ReportPortal launch", "launch.UUID", launchUUID)
+		}
+		if errors.IsNotFound(err) {
+			return controller.ContinueProcessing()
+		}
+		return controller.RequeueWithError(err)
+	}
+
+	err = rpClient.FinishLaunch(a.context, launchUUID, launch)
+	if err != nil {
+		// the launch was already recorded on the pipelineRun, retrying would duplicate the items exported so far
+		a.logger.Error(err, "Failed to export the integration test results to ReportPortal, the launch was stopped",
+			"launch.UUID", launchUUID)
+		return controller.ContinueProcessing()
+	}
+
+	a.logger.LogAuditEvent("Exported the integration test results to ReportPortal", a.pipelineRun, h.LogActionUpdate,
+		"launch.UUID", launchUUID)
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureTestEventsNotified will ensure that the notification sinks configured for the namespace, if there are any,
+// are notified once the integration test pipeline started and once it finished. The failures of the sinks aren't
+// returned, so that they don't block the detection of the stuck pipelineRuns: the delivery to the running pipelineRun
+// is retried when it's reconciled again, and the finished one is requeued after notification.RetryInterval.
+// The notified events are recorded on a copy of the pipelineRun, as the operation runs concurrently with the export
+// of the results to ReportPortal.
+func (a *Adapter) EnsureTestEventsNotified() (controller.OperationResult, error) {
+	eventTypes := []string{}
+	if !notification.IsEventNotified(a.pipelineRun, notification.EventTestStarted) {
+		eventTypes = append(eventTypes, notification.EventTestStarted)
+	}
+	if h.HasPipelineRunFinished(a.pipelineRun) && !notification.IsEventNotified(a.pipelineRun, notification.EventTestFinished) {
+		eventTypes = append(eventTypes, notification.EventTestFinished)
+	}
+	if len(eventTypes) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	sinks, err := notification.LoadSinks(a.context, a.client, a.pipelineRun.Namespace)
+	if notification.IsInvalidConfiguration(err) {
+		// a misconfigured secret won't be fixed by retrying, so don't block the pipelineRun on it
+		a.logger.Error(err, "Failed to configure the notification sinks, skipping the notification")
+		return controller.ContinueProcessing()
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to load the notification sinks", "secret.Name", notification.SecretName)
+		return controller.RequeueWithError(err)
+	}
+	if len(sinks) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	scenarioResult, err := a.newScenarioResult()
+	if err != nil {
+		a.logger.Error(err, "Failed to prepare the integration test notification")
+		return controller.RequeueWithError(err)
+	}
+
+	// stop at the first failure to deliver the events in order, the remaining ones are delivered when retrying
+	pipelineRun := a.pipelineRun.DeepCopy()
+	patch := client.MergeFrom(a.pipelineRun)
+	var errForNotification error
+	for _, eventType := range eventTypes {
+		event := notification.NewSnapshotEvent(eventType, a.snapshot)
+		event.Message = fmt.Sprintf("the integration test of scenario %s started", scenarioResult.Name)
+		if eventType == notification.EventTestFinished {
+			event.Message = fmt.Sprintf("the integration test of scenario %s finished", scenarioResult.Name)
+		}
+		event.Scenarios = []notification.ScenarioResult{scenarioResult}
+		if err := notification.SendOnce(a.context, pipelineRun, sinks, event); err != nil {
+			a.logger.Error(err, "Failed to notify about the integration test event", "event.Type", eventType)
+			errForNotification = err
+			break
+		}
+		_ = notification.SetEventNotified(pipelineRun, eventType)
+		a.logger.LogAuditEvent("Notified about the integration test event", pipelineRun, h.LogActionUpdate,
+			"event.Type", eventType,
+			"sinks", len(sinks))
+	}
+
+	err = a.client.Patch(a.context, pipelineRun, patch)
+	if err != nil && !errors.IsNotFound(err) {
+		a.logger.Error(err, "Failed to annotate the pipelineRun with the notified integration test events")
+		return controller.RequeueWithError(err)
+	}
+	if errForNotification != nil && h.HasPipelineRunFinished(a.pipelineRun) {
+		a.logger.Info("Retrying the notification of the integration test events later",
+			"requeueAfter", notification.RetryInterval.String())
+		return controller.RequeueAfter(notification.RetryInterval, nil)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// newScenarioResult returns the result of the IntegrationTestScenario tested by the pipelineRun, as recorded
+// in the test status of the snapshot
+func (a *Adapter) newScenarioResult() (notification.ScenarioResult, error) {
+	scenarioName := a.pipelineRun.Labels[tekton.ScenarioNameLabel]
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		return notification.ScenarioResult{}, err
+	}
+	detail, ok := testStatuses.GetScenarioStatus(scenarioName)
+	if !ok {
+		return notification.ScenarioResult{
+			Name:            scenarioName,
+			PipelineRunName: a.pipelineRun.Name,
+			PipelineRunURL:  status.FormatPipelineURL(a.pipelineRun.Name, a.pipelineRun.Namespace, a.logger.Logger),
+		}, nil
+	}
+	return notification.NewScenarioResult(detail, a.pipelineRun.Namespace, a.logger.Logger), nil
+}
+
+// newReportPortalLaunch creates the ReportPortal launch describing the integration test pipeline, with a suite
+// for the pipelineRun holding a step for each of its tasks
+func (a *Adapter) newReportPortalLaunch() (*reportportal.Launch, error) {
+	scenarioName := a.pipelineRun.Labels[tekton.ScenarioNameLabel]
+	startTime, endTime := a.pipelineRun.CreationTimestamp.Time, a.pipelineRun.CreationTimestamp.Time
+	if a.pipelineRun.Status.StartTime != nil {
+		startTime = a.pipelineRun.Status.StartTime.Time
+	}
+	if a.pipelineRun.Status.CompletionTime != nil {
+		endTime = a.pipelineRun.Status.CompletionTime.Time
+	}
+
+	suite := reportportal.Item{
+		Name:      a.pipelineRun.Name,
+		Type:      reportportal.ItemTypeSuite,
+		Status:    reportportal.StatusFailed,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	launch := &reportportal.Launch{
+		Name:      scenarioName,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Attributes: map[string]string{
+			"namespace":   a.pipelineRun.Namespace,
+			"application": a.application.Name,
+			"snapshot":    a.snapshot.Name,
+			"pipelineRun": a.pipelineRun.Name,
+		},
+	}
+	if component, ok := a.snapshot.Labels[gitops.SnapshotComponentLabel]; ok {
+		launch.Attributes["component"] = component
+	}
+
+	statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if detail, ok := statuses.GetScenarioStatus(scenarioName); ok {
+		launch.Description = detail.Details
+		launch.Attributes["status"] = detail.Status.String()
+		if detail.Status.IsPassed() {
+			suite.Status = reportportal.StatusPassed
+		}
+	}
+
+	taskRuns, err := h.GetAllChildTaskRunsForPipelineRun(a.context, a.client, a.pipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	for _, taskRun := range taskRuns {
+		step := reportportal.Item{
+			Name:      taskRun.GetPipelineTaskName(),
+			Type:      reportportal.ItemTypeStep,
+			Status:    reportportal.StatusFailed,
+			StartTime: taskRun.GetStartTime(),
+			EndTime:   taskRun.GetStartTime().Add(taskRun.GetDuration()),
+		}
+		if condition := taskRun.GetStatusCondition("Succeeded"); condition != nil && condition.IsTrue() {
+			step.Status = reportportal.StatusPassed
+		}
+		testResult, err := taskRun.GetTestResult()
+		if err != nil {
+			return nil, err
+		}
+		if testResult != nil && testResult.ValidationError != nil {
+			step.Status = reportportal.StatusFailed
+			step.Description = testResult.ValidationError.Error()
+		} else if testResult != nil {
+			step.Description = testResult.TestOutput.Note
+			switch testResult.TestOutput.Result {
+			case h.AppStudioTestOutputSuccess, h.AppStudioTestOutputWarning:
+				step.Status = reportportal.StatusPassed
+			case h.AppStudioTestOutputSkipped:
+				step.Status = reportportal.StatusSkipped
+			default:
+				step.Status = reportportal.StatusFailed
+			}
+		}
+		suite.Children = append(suite.Children, step)
+	}
+	launch.Items = []reportportal.Item{suite}
+
+	return launch, nil
+}
+
+// GetIntegrationPipelineRunStatus checks the Tekton results for a given PipelineRun and returns status of test
+// together with the summary of the test outputs of all its tasks, if any.
+func (a *Adapter) GetIntegrationPipelineRunStatus(ctx context.Context, adapterClient client.Client, pipelineRun *tektonv1.PipelineRun) (intgteststat.IntegrationTestStatus, string, *intgteststat.TestResultsSummary, error) {
 	// Check if the pipelineRun finished from the condition of status
 	if !h.HasPipelineRunFinished(pipelineRun) {
 		// Mark the pipelineRun's status as "Deleted" if its not finished yet and is marked for deletion (with a non-nil deletionTimestamp)
 		if pipelineRun.GetDeletionTimestamp() != nil {
-			return intgteststat.IntegrationTestStatusDeleted, fmt.Sprintf("Integration test which is running as pipeline run '%s', has been deleted", pipelineRun.Name), nil
+			return intgteststat.IntegrationTestStatusDeleted, fmt.Sprintf("Integration test which is running as pipeline run '%s', has been deleted", pipelineRun.Name), nil, nil
 		} else {
-			return intgteststat.IntegrationTestStatusInProgress, fmt.Sprintf("Integration test is running as pipeline run '%s'", pipelineRun.Name), nil
+			return intgteststat.IntegrationTestStatusInProgress, fmt.Sprintf("Integration test is running as pipeline run '%s'", pipelineRun.Name), nil, nil
 		}
 	}
 
 	taskRuns, err := a.loader.GetAllTaskRunsWithMatchingPipelineRunLabel(ctx, adapterClient, pipelineRun)
 	if err != nil {
-		return intgteststat.IntegrationTestStatusTestInvalid, fmt.Sprintf("Unable to get all the TaskRun(s) related to the pipelineRun '%s'", pipelineRun.Name), err
+		return intgteststat.IntegrationTestStatusTestInvalid, fmt.Sprintf("Unable to get all the TaskRun(s) related to the pipelineRun '%s'", pipelineRun.Name), nil, err
 	}
 
 	taskRunsInClusterCount := len(*taskRuns)
@@ -134,20 +588,30 @@ func (a *Adapter) GetIntegrationPipelineRunStatus(ctx context.Context, adapterCl
 	if taskRunsInClusterCount != taskRunsInChildRefCount {
 		return intgteststat.IntegrationTestStatusTestInvalid, fmt.Sprintf("Failed to determine status of pipelinerun '%s'"+
 			", due to mismatch in TaskRuns present in cluster (%v) and those referenced within childReferences (%v)",
-			pipelineRun.Name, taskRunsInClusterCount, taskRunsInChildRefCount), nil
+			pipelineRun.Name, taskRunsInClusterCount, taskRunsInChildRefCount), nil, nil
 	}
 
 	outcome, err := h.GetIntegrationPipelineRunOutcome(ctx, adapterClient, pipelineRun)
 	if err != nil {
-		return intgteststat.IntegrationTestStatusTestFail, "", fmt.Errorf("failed to evaluate integration test results: %w", err)
+		return intgteststat.IntegrationTestStatusTestFail, "", nil, fmt.Errorf("failed to evaluate integration test results: %w", err)
 	}
 
 	if !outcome.HasPipelineRunPassedTesting() {
 		if !outcome.HasPipelineRunValidTestOutputs() {
-			return intgteststat.IntegrationTestStatusTestFail, strings.Join(outcome.GetValidationErrorsList(), "; "), nil
+			return intgteststat.IntegrationTestStatusTestInvalid, strings.Join(outcome.GetValidationErrorsList(), "; "), outcome.GetTestResultsSummary(), nil
+		}
+		if outcome.HasPipelineRunTestErrors() {
+			return intgteststat.IntegrationTestStatusTestError, "Integration test experienced an error", outcome.GetTestResultsSummary(), nil
 		}
-		return intgteststat.IntegrationTestStatusTestFail, "Integration test failed", nil
+		if maxAllowedFailures, ok := tekton.GetMaxAllowedFailures(pipelineRun); ok && outcome.HasPipelineRunFailuresWithinThreshold(maxAllowedFailures) {
+			return intgteststat.IntegrationTestStatusTestPassed, fmt.Sprintf("Integration test passed, %d failing test case(s) are within the allowed threshold of %d",
+				outcome.GetTestResultsSummary().Failures, maxAllowedFailures), outcome.GetTestResultsSummary(), nil
+		}
+		return intgteststat.IntegrationTestStatusTestFail, "Integration test failed", outcome.GetTestResultsSummary(), nil
 	}
 
-	return intgteststat.IntegrationTestStatusTestPassed, "Integration test passed", nil
+	if outcome.HasPipelineRunTestWarnings() {
+		return intgteststat.IntegrationTestStatusTestWarning, "Integration test passed with warnings", outcome.GetTestResultsSummary(), nil
+	}
+	return intgteststat.IntegrationTestStatusTestPassed, "Integration test passed", outcome.GetTestResultsSummary(), nil
 }
@@ -17,7 +17,11 @@ limitations under the License.
 package integrationpipeline
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"time"
 
@@ -26,6 +30,10 @@ import (
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/pkg/reportportal"
+	"github.com/konflux-ci/integration-service/tekton"
+	"github.com/konflux-ci/operator-toolkit/controller"
 	toolkit "github.com/konflux-ci/operator-toolkit/loader"
 
 	"knative.dev/pkg/apis"
@@ -36,13 +44,28 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// failingSecretsClient fails to get the Secrets, as if they couldn't be read from the API server.
+type failingSecretsClient struct {
+	client.Client
+}
+
+func (c failingSecretsClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*corev1.Secret); ok {
+		return fmt.Errorf("failed to get the secret %s", key.Name)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
 var _ = Describe("Pipeline Adapter", Ordered, func() {
 	var (
 		adapter *Adapter
@@ -799,7 +822,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		})
 
 		It("ensures test status in snapshot is updated to failed", func() {
-			status, detail, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, intgPipelineRunWithDeletionTimestamp)
+			status, detail, _, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, intgPipelineRunWithDeletionTimestamp)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(status).To(Equal(intgteststat.IntegrationTestStatusDeleted))
@@ -926,11 +949,11 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
 		})
 
-		It("ensures test status in snapshot is updated to failed", func() {
-			status, detail, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, intgPipelineInvalidResult)
+		It("ensures test status in snapshot is updated to invalid", func() {
+			status, detail, _, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, intgPipelineInvalidResult)
 
 			Expect(err).ToNot(HaveOccurred())
-			Expect(status).To(Equal(intgteststat.IntegrationTestStatusTestFail))
+			Expect(status).To(Equal(intgteststat.IntegrationTestStatusTestInvalid))
 			Expect(detail).To(ContainSubstring("Invalid result:"))
 		})
 	})
@@ -941,7 +964,7 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		})
 
 		It("ensures test status in snapshot is updated to failed", func() {
-			status, detail, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, integrationPipelineRunComponent)
+			status, detail, _, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, integrationPipelineRunComponent)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(status).To(Equal(intgteststat.IntegrationTestStatusTestInvalid))
 			Expect(detail).To(ContainSubstring(fmt.Sprintf("Failed to determine status of pipelinerun '%s', due to mismatch"+
@@ -961,10 +984,543 @@ var _ = Describe("Pipeline Adapter", Ordered, func() {
 		})
 
 		It("ensures test status in snapshot is updated to failed", func() {
-			status, detail, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, integrationPipelineRunComponent)
+			status, detail, summary, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, integrationPipelineRunComponent)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(status).To(Equal(intgteststat.IntegrationTestStatusTestPassed))
 			Expect(detail).To(ContainSubstring("Integration test passed"))
+			Expect(summary).To(Equal(&intgteststat.TestResultsSummary{Successes: 10}))
+		})
+	})
+	When("GetIntegrationPipelineRunStatus is called with a PLR of a scenario with a failure tolerance threshold", func() {
+		var intgPipelineRunWithThreshold *tektonv1.PipelineRun
+
+		BeforeEach(func() {
+			intgPipelineRunWithThreshold = &tektonv1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pipelinerun-component-sample-threshold",
+					Namespace: "default",
+					Annotations: map[string]string{
+						tekton.MaxAllowedFailuresAnnotation: "1",
+					},
+				},
+				Status: tektonv1.PipelineRunStatus{
+					PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+						CompletionTime: &metav1.Time{Time: time.Now()},
+						ChildReferences: []tektonv1.ChildStatusReference{
+							{
+								Name:             failedTaskRun.Name,
+								PipelineTaskName: "task1",
+							},
+						},
+					},
+					Status: v1.Status{
+						Conditions: v1.Conditions{
+							apis.Condition{
+								Reason: "Completed",
+								Status: "True",
+								Type:   apis.ConditionSucceeded,
+							},
+						},
+					},
+				},
+			}
+
+			adapter = NewAdapter(ctx, intgPipelineRunWithThreshold, hasApp, hasSnapshot, logger, loader.NewMockLoader(), k8sClient)
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllTaskRunsWithMatchingPipelineRunLabelContextKey,
+					Resource:   []tektonv1.TaskRun{*failedTaskRun},
+				},
+			})
+		})
+
+		It("ensures test status is passed when the failing test cases are within the threshold", func() {
+			status, detail, summary, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, intgPipelineRunWithThreshold)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status).To(Equal(intgteststat.IntegrationTestStatusTestPassed))
+			Expect(detail).To(ContainSubstring("within the allowed threshold of 1"))
+			Expect(summary).To(Equal(&intgteststat.TestResultsSummary{Failures: 1}))
+		})
+
+		It("ensures test status is failed when the failing test cases exceed the threshold", func() {
+			intgPipelineRunWithThreshold.Annotations[tekton.MaxAllowedFailuresAnnotation] = "0"
+			status, detail, _, err := adapter.GetIntegrationPipelineRunStatus(adapter.context, adapter.client, intgPipelineRunWithThreshold)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status).To(Equal(intgteststat.IntegrationTestStatusTestFail))
+			Expect(detail).To(Equal("Integration test failed"))
+		})
+	})
+	When("EnsureScenarioRunHistoryRecorded is called", func() {
+		var (
+			flakyScenario *v1beta2.IntegrationTestScenario
+			// the history is read back right after each run, so the cache of the manager's client is bypassed
+			directClient client.Client
+		)
+
+		BeforeEach(func() {
+			var err error
+			directClient, err = client.New(cfg, client.Options{Scheme: k8sClient.Scheme()})
+			Expect(err).NotTo(HaveOccurred())
+
+			flakyScenario = integrationTestScenario.DeepCopy()
+			flakyScenario.ObjectMeta = metav1.ObjectMeta{
+				Name:      "flaky-scenario",
+				Namespace: "default",
+			}
+			flakyScenario.Spec.QuarantineFlaky = true
+			Expect(k8sClient.Create(ctx, flakyScenario)).Should(Succeed())
+			helpers.SetScenarioIntegrationStatusAsValid(flakyScenario, "valid")
+			Expect(k8sClient.Status().Update(ctx, flakyScenario)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, flakyScenario)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		runOperation := func(snapshotName, pipelineRunName string, status intgteststat.IntegrationTestStatus) (controller.OperationResult, error) {
+			snapshot := hasSnapshot.DeepCopy()
+			snapshot.Name = snapshotName
+			snapshot.Annotations = map[string]string{
+				gitops.SnapshotTestsStatusAnnotation: fmt.Sprintf(
+					"[{\"scenario\":\"%s\",\"status\":\"%s\",\"testPipelineRunName\":\"%s\",\"lastUpdateTime\":\"2023-08-26T17:57:50+02:00\"}]",
+					flakyScenario.Name, status, pipelineRunName),
+			}
+			pipelineRun := integrationPipelineRunComponent.DeepCopy()
+			pipelineRun.Name = pipelineRunName
+			pipelineRun.Labels[tekton.ScenarioNameLabel] = flakyScenario.Name
+
+			adapter = NewAdapter(ctx, pipelineRun, hasApp, snapshot, logger, loader.NewMockLoader(), directClient)
+			return adapter.EnsureScenarioRunHistoryRecorded()
+		}
+
+		recordRun := func(snapshotName, pipelineRunName string, status intgteststat.IntegrationTestStatus) *v1beta2.IntegrationTestScenario {
+			result, err := runOperation(snapshotName, pipelineRunName, status)
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			scenario := &v1beta2.IntegrationTestScenario{}
+			Expect(directClient.Get(ctx, types.NamespacedName{Namespace: flakyScenario.Namespace, Name: flakyScenario.Name}, scenario)).To(Succeed())
+			return scenario
+		}
+
+		It("records the runs and flags the scenario whose results change for the same snapshots", func() {
+			scenario := recordRun("snapshot-a", "flaky-run-1", intgteststat.IntegrationTestStatusTestFail)
+			Expect(scenario.Status.RunHistory).To(HaveLen(1))
+			Expect(scenario.Status.RunHistory[0].Passed).To(BeFalse())
+			scenario = recordRun("snapshot-a", "flaky-run-2", intgteststat.IntegrationTestStatusTestPassed)
+			scenario = recordRun("snapshot-b", "flaky-run-3", intgteststat.IntegrationTestStatusTestFail)
+			Expect(scenario.Status.RunHistory).To(HaveLen(3))
+			Expect(helpers.IsScenarioFlaky(scenario)).To(BeFalse())
+
+			// the same run isn't recorded twice, and the invalid runs aren't recorded at all
+			scenario = recordRun("snapshot-b", "flaky-run-3", intgteststat.IntegrationTestStatusTestFail)
+			scenario = recordRun("snapshot-b", "flaky-run-4", intgteststat.IntegrationTestStatusTestInvalid)
+			Expect(scenario.Status.RunHistory).To(HaveLen(3))
+
+			scenario = recordRun("snapshot-b", "flaky-run-5", intgteststat.IntegrationTestStatusTestPassed)
+			Expect(helpers.IsScenarioFlaky(scenario)).To(BeTrue())
+			Expect(helpers.IsScenarioQuarantined(scenario)).To(BeTrue())
+			Expect(scenario.Annotations[helpers.FlakyScenarioAnnotation]).To(ContainSubstring("snapshot-a, snapshot-b"))
+			Expect(scenario.Status.RunHistory).To(BeEmpty())
+			// the trend of the history is kept until the next recorded run
+			Expect(scenario.Status.Trend).NotTo(BeNil())
+			Expect(scenario.Status.Trend.Runs).To(Equal(4))
+
+			// the runs of the flagged scenario aren't recorded
+			scenario = recordRun("snapshot-c", "flaky-run-6", intgteststat.IntegrationTestStatusTestFail)
+			Expect(scenario.Status.RunHistory).To(BeEmpty())
+		})
+
+		It("records the results and updates the trend of the scenario computed from its run history", func() {
+			for i, status := range []intgteststat.IntegrationTestStatus{
+				intgteststat.IntegrationTestStatusTestPassed,
+				intgteststat.IntegrationTestStatusTestFail,
+				intgteststat.IntegrationTestStatusTestWarning,
+				intgteststat.IntegrationTestStatusTestPassed,
+			} {
+				pipelineRunName := fmt.Sprintf("trend-run-%d", i)
+				snapshot := hasSnapshot.DeepCopy()
+				snapshot.Annotations = map[string]string{
+					gitops.SnapshotTestsStatusAnnotation: fmt.Sprintf(
+						"[{\"scenario\":\"%s\",\"status\":\"%s\",\"testPipelineRunName\":\"%s\",\"lastUpdateTime\":\"2023-08-26T17:57:50+02:00\"}]",
+						flakyScenario.Name, status, pipelineRunName),
+				}
+				pipelineRun := integrationPipelineRunComponent.DeepCopy()
+				pipelineRun.Name = pipelineRunName
+				pipelineRun.Labels[tekton.ScenarioNameLabel] = flakyScenario.Name
+				pipelineRun.Status.StartTime = &metav1.Time{Time: pipelineRun.Status.CompletionTime.Add(-2 * time.Minute)}
+
+				adapter = NewAdapter(ctx, pipelineRun, hasApp, snapshot, logger, loader.NewMockLoader(), directClient)
+				result, err := adapter.EnsureScenarioRunHistoryRecorded()
+				Expect(!result.CancelRequest && err == nil).To(BeTrue())
+				// the same run isn't recorded twice
+				result, err = adapter.EnsureScenarioRunHistoryRecorded()
+				Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			}
+
+			scenario := &v1beta2.IntegrationTestScenario{}
+			Expect(directClient.Get(ctx, types.NamespacedName{Namespace: flakyScenario.Namespace, Name: flakyScenario.Name}, scenario)).To(Succeed())
+			Expect(scenario.Status.RunHistory).To(HaveLen(4))
+			Expect(scenario.Status.RunHistory[0].Duration.Duration).To(Equal(2 * time.Minute))
+			Expect(scenario.Status.Trend).NotTo(BeNil())
+			Expect(scenario.Status.Trend.Runs).To(Equal(4))
+			Expect(scenario.Status.Trend.PassRate).To(Equal(75))
+			Expect(scenario.Status.Trend.AverageDuration.Duration).To(Equal(2 * time.Minute))
+		})
+
+		It("doesn't fail when the scenario doesn't exist", func() {
+			Expect(k8sClient.Delete(ctx, flakyScenario)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: flakyScenario.Namespace, Name: flakyScenario.Name}, &v1beta2.IntegrationTestScenario{})
+				return k8serrors.IsNotFound(err)
+			}).Should(BeTrue())
+
+			result, err := runOperation("snapshot-a", "flaky-run-1", intgteststat.IntegrationTestStatusTestFail)
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(result.RequeueRequest).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("EnsureStuckPipelineRunDetected is called", func() {
+		var (
+			stuckPipelineRun *tektonv1.PipelineRun
+			stuckSnapshot    *applicationapiv1alpha1.Snapshot
+		)
+
+		BeforeEach(func() {
+			stuckPipelineRun = integrationPipelineRunComponent.DeepCopy()
+			stuckPipelineRun.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			stuckPipelineRun.Status = tektonv1.PipelineRunStatus{}
+
+			stuckSnapshot = hasSnapshot.DeepCopy()
+			stuckSnapshot.Annotations = map[string]string{
+				gitops.SnapshotTestsStatusAnnotation: fmt.Sprintf(
+					"[{\"scenario\":\"%s\",\"status\":\"InProgress\",\"testPipelineRunName\":\"%s\",\"lastUpdateTime\":\"2023-08-26T17:57:50+02:00\"}]",
+					stuckPipelineRun.Labels[tekton.ScenarioNameLabel], stuckPipelineRun.Name),
+			}
+			Expect(k8sClient.Update(ctx, stuckSnapshot)).Should(Succeed())
+
+			adapter = NewAdapter(ctx, stuckPipelineRun, hasApp, stuckSnapshot, logger, loader.NewMockLoader(), k8sClient)
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.SnapshotContextKey,
+					Resource:   stuckSnapshot,
+				},
+			})
+		})
+
+		// the Snapshot is read through the cache, so the test status is polled until the cache catches up
+		getTestStatus := func() *intgteststat.IntegrationTestStatusDetail {
+			var detail *intgteststat.IntegrationTestStatusDetail
+			Eventually(func(g Gomega) {
+				snapshot := &applicationapiv1alpha1.Snapshot{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: stuckSnapshot.Namespace, Name: stuckSnapshot.Name}, snapshot)).To(Succeed())
+				statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(snapshot)
+				g.Expect(err).ToNot(HaveOccurred())
+				var ok bool
+				detail, ok = statuses.GetScenarioStatus(stuckPipelineRun.Labels[tekton.ScenarioNameLabel])
+				g.Expect(ok).To(BeTrue())
+			}).Should(Succeed())
+			return detail
+		}
+
+		It("requeues the pipelineRun which isn't stuck for longer than the timeout yet", func() {
+			stuckPipelineRun.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+
+			result, err := adapter.EnsureStuckPipelineRunDetected()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(BeNumerically("~", 50*time.Minute, time.Minute))
+			Expect(getTestStatus().Status).To(Equal(intgteststat.IntegrationTestStatusInProgress))
+		})
+
+		It("marks the test of the stuck pipelineRun as errored and keeps it errored", func() {
+			result, err := adapter.EnsureStuckPipelineRunDetected()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+
+			Eventually(func() intgteststat.IntegrationTestStatus {
+				return getTestStatus().Status
+			}).Should(Equal(intgteststat.IntegrationTestStatusTestError))
+			detail := getTestStatus()
+			Expect(detail.Details).To(ContainSubstring("made no status progress for 1h0m0s"))
+
+			// the running pipelineRun doesn't revert the result
+			result, err = adapter.EnsureStatusReportedInSnapshot()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Consistently(func() intgteststat.IntegrationTestStatus {
+				return getTestStatus().Status
+			}, time.Second).Should(Equal(intgteststat.IntegrationTestStatusTestError))
+		})
+
+		It("doesn't detect the stuck pipelineRuns when it's disabled", func() {
+			GinkgoT().Setenv(tekton.StuckPipelineRunTimeoutEnvVar, "0")
+
+			result, err := adapter.EnsureStuckPipelineRunDetected()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(getTestStatus().Status).To(Equal(intgteststat.IntegrationTestStatusInProgress))
+		})
+	})
+
+	When("EnsureResultsExportedToReportPortal is called", func() {
+		var (
+			server   *httptest.Server
+			secret   *corev1.Secret
+			requests []string
+			failOn   string
+		)
+
+		BeforeEach(func() {
+			requests = []string{}
+			failOn = ""
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+				requests = append(requests, request)
+				if request == failOn {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(map[string]string{"id": fmt.Sprintf("uuid-%d", len(requests))})).To(Succeed())
+			}))
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      reportportal.SecretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					reportportal.SecretURLKey:     []byte(server.URL),
+					reportportal.SecretProjectKey: []byte("integration"),
+					reportportal.SecretTokenKey:   []byte("rp-token"),
+				},
+			}
+
+			adapter = NewAdapter(ctx, integrationPipelineRunComponent, hasApp, hasSnapshot, logger, loader.NewMockLoader(), k8sClient)
+		})
+
+		AfterEach(func() {
+			server.Close()
+			err := k8sClient.Delete(ctx, secret)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+				return k8serrors.IsNotFound(err)
+			}).Should(BeTrue())
+		})
+
+		reloadAdapter := func() {
+			pipelineRun := &tektonv1.PipelineRun{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: integrationPipelineRunComponent.Namespace,
+				Name:      integrationPipelineRunComponent.Name,
+			}, pipelineRun)).To(Succeed())
+			adapter = NewAdapter(ctx, pipelineRun, hasApp, hasSnapshot, logger, loader.NewMockLoader(), k8sClient)
+		}
+
+		It("doesn't export anything when ReportPortal isn't configured for the namespace", func() {
+			result, err := adapter.EnsureResultsExportedToReportPortal()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(requests).To(BeEmpty())
+			Expect(integrationPipelineRunComponent.Annotations).ToNot(HaveKey(reportportal.LaunchAnnotation))
+		})
+
+		It("exports the results of the finished pipelineRun once", func() {
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+			}).Should(Succeed())
+
+			result, err := adapter.EnsureResultsExportedToReportPortal()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(requests).To(Equal([]string{
+				"POST /api/v1/integration/launch",
+				"POST /api/v1/integration/item",
+				"POST /api/v1/integration/item/uuid-2",
+				"PUT /api/v1/integration/item/uuid-3",
+				"PUT /api/v1/integration/item/uuid-2",
+				"PUT /api/v1/integration/launch/uuid-1/finish",
+			}))
+
+			Eventually(func() map[string]string {
+				pipelineRun := &tektonv1.PipelineRun{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: integrationPipelineRunComponent.Namespace,
+					Name:      integrationPipelineRunComponent.Name,
+				}, pipelineRun)).To(Succeed())
+				return pipelineRun.Annotations
+			}).Should(HaveKeyWithValue(reportportal.LaunchAnnotation, "uuid-1"))
+
+			// the launch recorded on the pipelineRun by the previous reconciliation isn't exported again
+			reloadAdapter()
+			result, err = adapter.EnsureResultsExportedToReportPortal()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(requests).To(HaveLen(6))
+		})
+
+		It("keeps the launch of the pipelineRun when its items can't be exported", func() {
+			failOn = "POST /api/v1/integration/item"
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+			}).Should(Succeed())
+
+			result, err := adapter.EnsureResultsExportedToReportPortal()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(requests).To(Equal([]string{
+				"POST /api/v1/integration/launch",
+				"POST /api/v1/integration/item",
+				"PUT /api/v1/integration/launch/uuid-1/finish",
+			}))
+
+			Eventually(func() map[string]string {
+				pipelineRun := &tektonv1.PipelineRun{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: integrationPipelineRunComponent.Namespace,
+					Name:      integrationPipelineRunComponent.Name,
+				}, pipelineRun)).To(Succeed())
+				return pipelineRun.Annotations
+			}).Should(HaveKeyWithValue(reportportal.LaunchAnnotation, "uuid-1"))
+
+			// the launch recorded on the pipelineRun by the previous reconciliation isn't exported again
+			reloadAdapter()
+			result, err = adapter.EnsureResultsExportedToReportPortal()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(requests).To(HaveLen(3))
+		})
+	})
+	When("EnsureTestEventsNotified is called", func() {
+		var (
+			server *httptest.Server
+			secret *corev1.Secret
+			events []http.Header
+		)
+
+		BeforeEach(func() {
+			events = []http.Header{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event := notification.Event{}
+				Expect(json.NewDecoder(r.Body).Decode(&event)).To(Succeed())
+				Expect(event.Scenarios).To(HaveLen(1))
+				Expect(event.Scenarios[0].PipelineRunName).To(Equal(integrationPipelineRunComponent.Name))
+				events = append(events, r.Header)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      notification.SecretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					notification.SecretCloudEventsSinkURLKey: []byte(server.URL),
+				},
+			}
+
+			adapter = NewAdapter(ctx, integrationPipelineRunComponent, hasApp, hasSnapshot, logger, loader.NewMockLoader(), k8sClient)
+		})
+
+		AfterEach(func() {
+			server.Close()
+			err := k8sClient.Delete(ctx, secret)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+				return k8serrors.IsNotFound(err)
+			}).Should(BeTrue())
+		})
+
+		getNotifiedEvents := func() string {
+			pipelineRun := &tektonv1.PipelineRun{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: integrationPipelineRunComponent.Namespace,
+				Name:      integrationPipelineRunComponent.Name,
+			}, pipelineRun)).To(Succeed())
+			return pipelineRun.Annotations[notification.NotifiedEventsAnnotation]
+		}
+
+		It("doesn't notify when no sink is configured", func() {
+			result, err := adapter.EnsureTestEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(events).To(BeEmpty())
+			Expect(getNotifiedEvents()).To(BeEmpty())
+		})
+
+		It("sends the CloudEvents of the finished pipelineRun once", func() {
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+			}).Should(Succeed())
+
+			result, err := adapter.EnsureTestEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Get("Ce-Type")).To(Equal("dev.konflux-ci.integration.test.started"))
+			Expect(events[1].Get("Ce-Type")).To(Equal("dev.konflux-ci.integration.test.finished"))
+			Expect(events[1].Get("Ce-Specversion")).To(Equal("1.0"))
+			Expect(events[1].Get("Ce-Source")).To(Equal("/namespaces/default/applications/" + hasApp.Name))
+			Expect(events[1].Get("Ce-Subject")).To(Equal(hasSnapshot.Name))
+			Eventually(getNotifiedEvents).Should(Equal("test.started,test.finished"))
+
+			// the events recorded on the pipelineRun by the previous reconciliation aren't sent again
+			notifiedPipelineRun := &tektonv1.PipelineRun{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: integrationPipelineRunComponent.Namespace,
+				Name:      integrationPipelineRunComponent.Name,
+			}, notifiedPipelineRun)).To(Succeed())
+			adapter = NewAdapter(ctx, notifiedPipelineRun, hasApp, hasSnapshot, logger, loader.NewMockLoader(), k8sClient)
+			result, err = adapter.EnsureTestEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(events).To(HaveLen(2))
+		})
+
+		It("requeues the finished pipelineRun without an error when the sink fails", func() {
+			failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer failingServer.Close()
+			GinkgoT().Setenv(notification.CloudEventsSinkEnvVar, failingServer.URL)
+
+			result, err := adapter.EnsureTestEventsNotified()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(notification.RetryInterval))
+			Expect(getNotifiedEvents()).To(BeEmpty())
+		})
+
+		It("sends the CloudEvents to the sink configured for all namespaces", func() {
+			GinkgoT().Setenv(notification.CloudEventsSinkEnvVar, server.URL)
+
+			result, err := adapter.EnsureTestEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(events).To(HaveLen(2))
+		})
+	})
+	When("the operations of the reconciliation are run", func() {
+		BeforeEach(func() {
+			adapter = NewAdapter(ctx, integrationPipelineRunComponent, hasApp, hasSnapshot, logger, loader.NewMockLoader(), failingSecretsClient{k8sClient})
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.SnapshotContextKey,
+					Resource:   hasSnapshot,
+				},
+				{
+					ContextKey: loader.TaskRunContextKey,
+					Resource:   successfulTaskRun,
+				},
+			})
+		})
+
+		It("exports the results and notifies the events concurrently and returns the errors of both", func() {
+			chain := newOperationChain(adapter)
+			Expect(chain.Names()).To(Equal([]string{
+				"EnsureStatusReportedInSnapshot",
+				"EnsureScenarioRunHistoryRecorded",
+				"EnsureResultsExportedToReportPortal",
+				"EnsureTestEventsNotified",
+				"EnsureStuckPipelineRunDetected",
+			}))
+
+			_, err := chain.Run(ctx)
+			Expect(err).To(MatchError(ContainSubstring("failed to get the secret " + reportportal.SecretName)))
+			Expect(err).To(MatchError(ContainSubstring("failed to get the secret " + notification.SecretName)))
 		})
 	})
 })
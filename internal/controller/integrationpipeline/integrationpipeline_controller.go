@@ -27,6 +27,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
 	"github.com/konflux-ci/integration-service/tekton"
 	"github.com/konflux-ci/operator-toolkit/controller"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
@@ -58,16 +61,20 @@ func NewIntegrationReconciler(client client.Client, logger *logr.Logger, scheme
 //+kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns/finalizers,verbs=update
 //+kubebuilder:rbac:groups=tekton.dev,resources=taskruns,verbs=get;list;watch
 //+kubebuilder:rbac:groups=tekton.dev,resources=taskruns/status,verbs=get
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/finalizers,verbs=update
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=pipelinesascode.tekton.dev,resources=repositories,verbs=get;list;watch
+//+kubebuilder:rbac:groups=results.tekton.dev,resources=results;records,verbs=get;list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("pipelineRun", req.NamespacedName)}
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("pipelineRun", req.NamespacedName, correlation.LogKey, correlationID)}
 	loader := loader.NewLoader()
 
 	pipelineRun := &tektonv1.PipelineRun{}
@@ -80,6 +87,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 		return ctrl.Result{}, err
 	}
+	logger.Logger = correlation.WithParentID(logger.Logger, pipelineRun)
 
 	var snapshot *applicationapiv1alpha1.Snapshot
 	err = retry.OnError(retry.DefaultRetry, func(_ error) bool { return true }, func() error {
@@ -97,6 +105,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	application, err := loader.GetApplicationFromPipelineRun(ctx, r.Client, pipelineRun)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			// the application was deleted, the pipelineRun is garbage collected with its snapshot and shouldn't be blocked
+			if err := helpers.RemoveFinalizerFromPipelineRun(ctx, r.Client, logger, pipelineRun, helpers.IntegrationPipelineRunFinalizer); err != nil {
+				return ctrl.Result{}, err
+			}
+			return helpers.HandleLoaderError(logger, err, "Application", "PipelineRun")
+		}
 		logger.Error(err, "Failed to get Application from the integration pipelineRun",
 			"PipelineRun.Name", pipelineRun.Name, "PipelineRun.Namespace", pipelineRun.Namespace)
 		return ctrl.Result{}, err
@@ -109,21 +124,44 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 	logger = logger.WithApp(*application)
 
-	adapter := NewAdapter(ctx, pipelineRun, application, snapshot, logger, loader, r.Client)
+	ctx, span := tracing.StartReconcileSpan(ctx, "integrationpipeline", map[string]client.Object{"pipelinerun": pipelineRun, "snapshot": snapshot, "application": application})
+	defer span.End()
+
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, pipelineRun, application, snapshot, logger, loader, correlation.NewClient(r.Client, correlationID))
 
-	return controller.ReconcileHandler([]controller.Operation{
+	return newOperationChain(adapter).Run(ctx)
+}
+
+// newOperationChain returns the chain of the operations of the adapter run on each reconciliation.
+func newOperationChain(adapter *Adapter) *operations.Chain {
+	return operations.NewChain("integrationpipeline",
 		adapter.EnsureStatusReportedInSnapshot,
-	})
+		adapter.EnsureScenarioRunHistoryRecorded,
+	).
+		// the export and the notifications only write copies of the pipelineRun, so they don't wait for each other
+		AppendConcurrent(
+			adapter.EnsureResultsExportedToReportPortal,
+			// the sinks which are unreachable must not block the detection of the stuck pipelineRuns
+			adapter.EnsureTestEventsNotified,
+		).
+		AppendAll(adapter.EnsureStuckPipelineRunDetected)
 }
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
 	EnsureStatusReportedInSnapshot() (controller.OperationResult, error)
+	EnsureScenarioRunHistoryRecorded() (controller.OperationResult, error)
+	EnsureResultsExportedToReportPortal() (controller.OperationResult, error)
+	EnsureTestEventsNotified() (controller.OperationResult, error)
+	EnsureStuckPipelineRunDetected() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration controller and adds it to the Manager.
-func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewIntegrationReconciler(manager.GetClient(), log, manager.GetScheme()))
+func SetupController(manager ctrl.Manager, log *logr.Logger, eventFilters ...predicate.Predicate) error {
+	return setupControllerWithManager(manager, NewIntegrationReconciler(manager.GetClient(), log, manager.GetScheme()), eventFilters...)
 }
 
 // setupCache indexes fields for each of the resources used in the pipeline adapter in those cases where filtering by
@@ -133,12 +171,16 @@ func setupCache(mgr ctrl.Manager) error {
 		return err
 	}
 
+	if err := cache.SetupPipelineRunCache(mgr); err != nil {
+		return err
+	}
+
 	return cache.SetupIntegrationTestScenarioCache(mgr)
 }
 
 // setupControllerWithManager sets up the controller with the Manager which monitors new PipelineRuns and filters
 // out status updates.
-func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
 	err := setupCache(manager)
 	if err != nil {
 		return err
@@ -148,5 +190,6 @@ func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) er
 		For(&tektonv1.PipelineRun{}).
 		WithEventFilter(predicate.Or(
 			tekton.IntegrationPipelineRunPredicate())).
+		WithEventFilter(predicate.And(eventFilters...)).
 		Complete(controller)
 }
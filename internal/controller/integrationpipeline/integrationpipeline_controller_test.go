@@ -349,6 +349,33 @@ var _ = Describe("Integration PipelineController", func() {
 		}, time.Second*20).Should(BeTrue())
 	})
 
+	It("Does not return an error if the application cannot be found", func() {
+		controllerutil.AddFinalizer(integrationPipelineRun, helpers.IntegrationPipelineRunFinalizer)
+		err := k8sClient.Update(ctx, integrationPipelineRun)
+		Expect(err).To(BeNil())
+
+		Expect(k8sClient.Delete(ctx, hasApp)).To(Succeed())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: hasApp.ObjectMeta.Namespace,
+				Name:      hasApp.ObjectMeta.Name,
+			}, hasApp)
+			return errors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		result, err := pipelineReconciler.Reconcile(ctx, req)
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(err).To(BeNil())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: integrationPipelineRun.Namespace,
+				Name:      integrationPipelineRun.Name,
+			}, integrationPipelineRun)
+			return err == nil && !controllerutil.ContainsFinalizer(integrationPipelineRun, helpers.IntegrationPipelineRunFinalizer)
+		}, time.Second*20).Should(BeTrue())
+	})
+
 	When("pipelinerun has no component", func() {
 
 		var (
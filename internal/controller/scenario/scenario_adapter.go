@@ -18,12 +18,21 @@ package scenario
 
 import (
 	"context"
+	"fmt"
+	"time"
+
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
 	h "github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/schedule"
 	"github.com/konflux-ci/operator-toolkit/controller"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -85,8 +94,8 @@ func (a *Adapter) EnsureCreatedScenarioIsValid() (controller.OperationResult, er
 
 	// application exist, always log it
 	a.logger = a.logger.WithApp(*a.application)
-	// Checks if scenario has ownerReference assigned to it
-	if a.scenario.OwnerReferences == nil {
+	// Checks if scenario has a controller ownerReference assigned to it, so that it's removed with the application
+	if metav1.GetControllerOf(a.scenario) == nil {
 		patch := client.MergeFrom(a.scenario.DeepCopy())
 		err := ctrl.SetControllerReference(a.application, a.scenario, a.client.Scheme())
 		if err != nil {
@@ -114,3 +123,245 @@ func (a *Adapter) EnsureCreatedScenarioIsValid() (controller.OperationResult, er
 
 	return controller.ContinueProcessing()
 }
+
+// EnsureSuspensionReflectedInSnapshots is an operation that ensures the Snapshots of the application which are still
+// being tested reflect the suspension of the IntegrationTestScenario. The integration tests of the scenario which
+// haven't finished for them yet are marked as suspended, the suspended scenario isn't required for them to pass.
+func (a *Adapter) EnsureSuspensionReflectedInSnapshots() (controller.OperationResult, error) {
+	if a.application == nil || a.scenario.DeletionTimestamp != nil || !h.IsScenarioSuspended(a.scenario) {
+		return controller.ContinueProcessing()
+	}
+
+	snapshots, err := a.loader.GetAllSnapshots(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the Snapshots of the application")
+		return controller.RequeueWithError(err)
+	}
+
+	// the matrix cells of the scenario have their own integration test statuses
+	scenarioNames := []string{}
+	for _, scenario := range *gitops.ExpandIntegrationTestScenarioMatrix(&[]v1beta2.IntegrationTestScenario{*a.scenario}) {
+		scenarioNames = append(scenarioNames, scenario.Name)
+	}
+
+	for _, snapshot := range *snapshots {
+		snapshot := snapshot //G601
+		if gitops.HaveAppStudioTestsFinished(&snapshot) {
+			continue
+		}
+		testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(&snapshot)
+		if err != nil {
+			a.logger.Error(err, "Failed to get the integration test statuses of the Snapshot", "snapshot.Name", snapshot.Name)
+			continue
+		}
+		for _, scenarioName := range scenarioNames {
+			if testStatus, ok := testStatuses.GetScenarioStatus(scenarioName); ok && !testStatus.Status.IsFinal() {
+				testStatuses.UpdateTestStatusIfChanged(scenarioName, intgteststat.IntegrationTestStatusSuspended,
+					fmt.Sprintf("IntegrationTestScenario '%s' was suspended", a.scenario.Name))
+			}
+		}
+		if !testStatuses.IsDirty() {
+			continue
+		}
+
+		err = gitops.WriteIntegrationTestStatusesIntoSnapshot(a.context, &snapshot, testStatuses, a.client)
+		if err != nil {
+			a.logger.Error(err, "Failed to update the integration test statuses of the Snapshot", "snapshot.Name", snapshot.Name)
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("The integration tests of the suspended IntegrationTestScenario were marked as suspended",
+			&snapshot, h.LogActionUpdate)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// periodicRun describes a kind of SnapshotRuns created periodically for an IntegrationTestScenario according to
+// the schedule set in one of its annotations.
+type periodicRun struct {
+	name               string
+	scheduleAnnotation string
+	lastRunAnnotation  string
+	// requiredOnly is set when only the required scenarios are run
+	requiredOnly bool
+	// getSnapshot returns the Snapshot the scenario is run against, nil when there isn't any
+	getSnapshot func(snapshots *[]applicationapiv1alpha1.Snapshot) *applicationapiv1alpha1.Snapshot
+	// newSnapshotRun returns the SnapshotRun running the scenario against the Snapshot for the given schedule tick
+	newSnapshotRun func(snapshot *applicationapiv1alpha1.Snapshot, scenario *v1beta2.IntegrationTestScenario, tick time.Time) *v1beta2.SnapshotRun
+	// isSnapshotRun returns true if the SnapshotRun was created for this kind of periodic run
+	isSnapshotRun func(snapshotRun *v1beta2.SnapshotRun) bool
+}
+
+// periodicRuns are the kinds of SnapshotRuns the IntegrationTestScenarios can be periodically run with.
+var periodicRuns = []periodicRun{
+	{
+		name:               "scheduled",
+		scheduleAnnotation: h.ScheduleAnnotation,
+		lastRunAnnotation:  h.LastScheduledRunAnnotation,
+		getSnapshot:        gitops.GetLatestPassedSnapshot,
+		newSnapshotRun: func(snapshot *applicationapiv1alpha1.Snapshot, scenario *v1beta2.IntegrationTestScenario, tick time.Time) *v1beta2.SnapshotRun {
+			return gitops.NewScheduledSnapshotRun(snapshot, scenario.Name, scenario.GetAnnotations()[h.ScheduleReportStatusAnnotation] == "true", tick)
+		},
+		isSnapshotRun: gitops.IsScheduledSnapshotRun,
+	},
+	{
+		name:               "revalidation",
+		scheduleAnnotation: h.RevalidationScheduleAnnotation,
+		lastRunAnnotation:  h.LastRevalidationRunAnnotation,
+		requiredOnly:       true,
+		getSnapshot:        gitops.GetLatestReleasedSnapshot,
+		newSnapshotRun: func(snapshot *applicationapiv1alpha1.Snapshot, scenario *v1beta2.IntegrationTestScenario, tick time.Time) *v1beta2.SnapshotRun {
+			return gitops.NewRevalidationSnapshotRun(snapshot, scenario.Name, tick)
+		},
+		isSnapshotRun: gitops.IsRevalidationSnapshotRun,
+	},
+}
+
+// EnsureScheduledRunsCreated is an operation that ensures the IntegrationTestScenarios with a schedule are run
+// periodically against the latest passed Snapshot of their application, and that the required scenarios with
+// a revalidation schedule are re-run against the latest released Snapshot of their application. Each time one of
+// the schedules is due, a SnapshotRun named after the schedule tick is created for the scenario, the time of the run is
+// recorded on the scenario and the finished SnapshotRuns older than the latest gitops.MaxPeriodicSnapshotRuns are deleted.
+func (a *Adapter) EnsureScheduledRunsCreated() (controller.OperationResult, error) {
+	if a.application == nil || a.scenario.DeletionTimestamp != nil {
+		return controller.ContinueProcessing()
+	}
+	if h.IsScenarioSuspended(a.scenario) {
+		a.logger.Info("The IntegrationTestScenario is suspended, no periodic run will be created")
+		return controller.ContinueProcessing()
+	}
+
+	var snapshots *[]applicationapiv1alpha1.Snapshot
+	var requeueAfter time.Duration
+	for _, run := range periodicRuns {
+		scheduleSpec, ok := a.scenario.GetAnnotations()[run.scheduleAnnotation]
+		if !ok {
+			continue
+		}
+		if run.requiredOnly {
+			policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+			if err != nil {
+				a.logger.Error(err, "Failed to get the IntegrationPolicy of the application")
+				return controller.RequeueWithError(err)
+			}
+			if !gitops.IsScenarioRequired(policy, a.scenario) {
+				a.logger.Info("Only the required IntegrationTestScenarios are run, ignoring the schedule", "run", run.name)
+				continue
+			}
+		}
+
+		runSchedule, err := schedule.Parse(scheduleSpec)
+		if err != nil {
+			a.logger.Error(err, "Failed to parse the schedule of the IntegrationTestScenario", "run", run.name, "schedule", scheduleSpec)
+			continue
+		}
+
+		lastRun := a.scenario.CreationTimestamp.Time
+		if value, ok := a.scenario.GetAnnotations()[run.lastRunAnnotation]; ok {
+			lastRun, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				a.logger.Error(err, "Failed to parse the time of the last run, scheduling from now", "run", run.name, "lastRun", value)
+				lastRun = time.Now()
+			}
+		}
+
+		now := time.Now()
+		nextRun := runSchedule.Next(lastRun)
+		if nextRun.IsZero() {
+			a.logger.Info("The schedule of the IntegrationTestScenario never matches, no run will be scheduled", "run", run.name, "schedule", scheduleSpec)
+			continue
+		}
+
+		if !now.Before(nextRun) {
+			if snapshots == nil {
+				snapshots, err = a.loader.GetAllSnapshots(a.context, a.client, a.application)
+				if err != nil {
+					a.logger.Error(err, "Failed to get the Snapshots of the application")
+					return controller.RequeueWithError(err)
+				}
+			}
+			if err = a.createPeriodicRun(run, snapshots, nextRun, now); err != nil {
+				return controller.RequeueWithError(err)
+			}
+			nextRun = runSchedule.Next(now)
+			if nextRun.IsZero() {
+				continue
+			}
+		}
+
+		if requeueAfter == 0 || nextRun.Sub(now) < requeueAfter {
+			requeueAfter = nextRun.Sub(now)
+		}
+	}
+
+	if requeueAfter == 0 {
+		return controller.ContinueProcessing()
+	}
+	return controller.RequeueAfter(requeueAfter, nil)
+}
+
+// createPeriodicRun creates the SnapshotRun of the periodic run for the scenario due at the given schedule tick, when
+// the application has a Snapshot to run it against, records the time of the run in the scenario and prunes the old
+// SnapshotRuns of the periodic run. The SnapshotRun already existing for the tick, e.g. when recording the time of the
+// run failed before, isn't created again.
+func (a *Adapter) createPeriodicRun(run periodicRun, snapshots *[]applicationapiv1alpha1.Snapshot, tick, now time.Time) error {
+	snapshot := run.getSnapshot(snapshots)
+	if snapshot == nil {
+		a.logger.Info("No Snapshot of the application to run the IntegrationTestScenario against was found, skipping the run", "run", run.name)
+	} else {
+		snapshotRun := run.newSnapshotRun(snapshot, a.scenario, tick)
+		err := ctrl.SetControllerReference(a.scenario, snapshotRun, a.client.Scheme())
+		if err != nil {
+			a.logger.Error(err, "Error setting owner reference of the SnapshotRun.")
+			return err
+		}
+		err = a.client.Create(a.context, snapshotRun)
+		if errors.IsAlreadyExists(err) {
+			a.logger.Info("The SnapshotRun of the periodic run was already created", "run", run.name, "snapshotRun.Name", snapshotRun.Name)
+		} else if err != nil {
+			a.logger.Error(err, "Failed to create the SnapshotRun", "run", run.name, "snapshot.Name", snapshot.Name)
+			return err
+		} else {
+			a.logger.LogAuditEvent("Created the SnapshotRun for the periodic run of the IntegrationTestScenario", snapshotRun, h.LogActionAdd,
+				"run", run.name,
+				"snapshot.Name", snapshot.Name)
+		}
+	}
+
+	patch := client.MergeFrom(a.scenario.DeepCopy())
+	err := metadata.SetAnnotation(&a.scenario.ObjectMeta, run.lastRunAnnotation, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		a.logger.Error(err, "Failed to set the time of the last run", "run", run.name)
+		return err
+	}
+	err = a.client.Patch(a.context, a.scenario, patch)
+	if err != nil {
+		a.logger.Error(err, "Failed to update Scenario")
+		return err
+	}
+
+	return a.pruneSnapshotRuns(run)
+}
+
+// pruneSnapshotRuns deletes the finished SnapshotRuns of the periodic run for the scenario which are older than
+// the latest gitops.MaxPeriodicSnapshotRuns of them.
+func (a *Adapter) pruneSnapshotRuns(run periodicRun) error {
+	snapshotRuns, err := a.loader.GetAllSnapshotRunsForScenario(a.context, a.client, a.scenario)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the SnapshotRuns of the IntegrationTestScenario")
+		return err
+	}
+
+	for _, snapshotRun := range gitops.GetPrunableSnapshotRuns(snapshotRuns, run.isSnapshotRun) {
+		snapshotRun := snapshotRun
+		err = a.client.Delete(a.context, &snapshotRun)
+		if err != nil && !errors.IsNotFound(err) {
+			a.logger.Error(err, "Failed to delete the old SnapshotRun", "run", run.name, "snapshotRun.Name", snapshotRun.Name)
+			return err
+		}
+		a.logger.LogAuditEvent("Deleted the old SnapshotRun of the periodic run of the IntegrationTestScenario", &snapshotRun, h.LogActionDelete,
+			"run", run.name)
+	}
+
+	return nil
+}
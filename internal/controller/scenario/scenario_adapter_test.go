@@ -27,11 +27,18 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	toolkit "github.com/konflux-ci/operator-toolkit/loader"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("Scenario Adapter", Ordered, func() {
@@ -165,4 +172,312 @@ var _ = Describe("Scenario Adapter", Ordered, func() {
 			return !result.CancelRequest && err == nil
 		}, time.Second*20).Should(BeTrue())
 	})
+
+	It("sets the application as the owner of the scenarios with other owners", func() {
+		ownedScenario := integrationTestScenario.DeepCopy()
+		ownedScenario.ObjectMeta = metav1.ObjectMeta{
+			Name:      "example-owned",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       "scenarios",
+				UID:        "12345",
+			}},
+		}
+		ownedScenario.Status = v1beta2.IntegrationTestScenarioStatus{}
+		Expect(k8sClient.Create(ctx, ownedScenario)).Should(Succeed())
+		defer func() {
+			err := k8sClient.Delete(ctx, ownedScenario)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		}()
+
+		a := NewAdapter(ctx, hasApp, ownedScenario, logger, loader.NewMockLoader(), k8sClient)
+		result, err := a.EnsureCreatedScenarioIsValid()
+		Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		Expect(metav1.IsControlledBy(ownedScenario, hasApp)).To(BeTrue())
+		Expect(ownedScenario.OwnerReferences).To(HaveLen(2))
+	})
+
+	When("the IntegrationTestScenario has a schedule", func() {
+		var (
+			scheduledScenario *v1beta2.IntegrationTestScenario
+			passedSnapshot    *applicationapiv1alpha1.Snapshot
+		)
+
+		BeforeEach(func() {
+			scheduledScenario = integrationTestScenario.DeepCopy()
+			scheduledScenario.ObjectMeta = metav1.ObjectMeta{
+				Name:      "example-scheduled",
+				Namespace: "default",
+				Annotations: map[string]string{
+					helpers.ScheduleAnnotation:             "@hourly",
+					helpers.ScheduleReportStatusAnnotation: "true",
+					helpers.LastScheduledRunAnnotation:     time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+				},
+			}
+			scheduledScenario.Status = v1beta2.IntegrationTestScenarioStatus{}
+			Expect(k8sClient.Create(ctx, scheduledScenario)).Should(Succeed())
+
+			passedSnapshot = &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "snapshot-passed",
+					Namespace:         "default",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Application: hasApp.Name,
+				},
+			}
+			meta.SetStatusCondition(&passedSnapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.AppStudioTestSucceededCondition,
+				Status: metav1.ConditionTrue,
+				Reason: reasons.AppStudioTestSucceededConditionSatisfied,
+			})
+		})
+
+		AfterEach(func() {
+			err := k8sClient.DeleteAllOf(ctx, &v1beta2.SnapshotRun{}, client.InNamespace("default"))
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Delete(ctx, scheduledScenario)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		getScheduledSnapshotRuns := func() []v1beta2.SnapshotRun {
+			snapshotRuns := &v1beta2.SnapshotRunList{}
+			Expect(k8sClient.List(ctx, snapshotRuns, client.InNamespace("default"),
+				client.MatchingLabels{gitops.SnapshotTestScenarioLabel: scheduledScenario.Name})).To(Succeed())
+			return snapshotRuns.Items
+		}
+
+		It("creates a SnapshotRun for the latest passed Snapshot when the schedule is due", func() {
+			a := NewAdapter(toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*passedSnapshot},
+				},
+			}), hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(BeNumerically("<=", time.Hour))
+
+			snapshotRuns := getScheduledSnapshotRuns()
+			Expect(snapshotRuns).To(HaveLen(1))
+			Expect(snapshotRuns[0].Spec.Snapshot).To(Equal(passedSnapshot.Name))
+			Expect(snapshotRuns[0].Spec.Scenario).To(Equal(scheduledScenario.Name))
+			Expect(snapshotRuns[0].Spec.ReportStatus).To(BeTrue())
+			Expect(snapshotRuns[0].Labels).To(HaveKeyWithValue(gitops.ScheduledSnapshotRunLabel, "true"))
+			Expect(metav1.IsControlledBy(&snapshotRuns[0], scheduledScenario)).To(BeTrue())
+
+			updatedScenario := &v1beta2.IntegrationTestScenario{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: scheduledScenario.Name}, updatedScenario)).To(Succeed())
+			lastRun, err := time.Parse(time.RFC3339, updatedScenario.Annotations[helpers.LastScheduledRunAnnotation])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastRun).To(BeTemporally("~", time.Now(), time.Minute))
+
+			result, err = a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(getScheduledSnapshotRuns()).To(HaveLen(1))
+		})
+
+		It("doesn't create a second SnapshotRun for the same schedule tick", func() {
+			staleScenario := scheduledScenario.DeepCopy()
+			mockedContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*passedSnapshot},
+				},
+			})
+			a := NewAdapter(mockedContext, hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(getScheduledSnapshotRuns()).To(HaveLen(1))
+
+			// the time of the run recorded on the scenario is lost, e.g. when the patch failed
+			a = NewAdapter(mockedContext, hasApp, staleScenario, logger, loader.NewMockLoader(), k8sClient)
+			result, err = a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(getScheduledSnapshotRuns()).To(HaveLen(1))
+		})
+
+		It("deletes the finished SnapshotRuns older than the latest ones once the schedule is due", func() {
+			oldSnapshotRuns := []v1beta2.SnapshotRun{}
+			for i := 0; i <= gitops.MaxPeriodicSnapshotRuns; i++ {
+				snapshotRun := gitops.NewScheduledSnapshotRun(passedSnapshot, scheduledScenario.Name, false, time.Now().Add(time.Duration(-i-3)*time.Hour))
+				Expect(k8sClient.Create(ctx, snapshotRun)).Should(Succeed())
+				snapshotRun.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(-i-3) * time.Hour))
+				meta.SetStatusCondition(&snapshotRun.Status.Conditions, metav1.Condition{
+					Type:   v1beta2.SnapshotRunSucceededCondition,
+					Status: metav1.ConditionTrue,
+					Reason: v1beta2.SnapshotRunPassedReason,
+				})
+				oldSnapshotRuns = append(oldSnapshotRuns, *snapshotRun)
+			}
+			a := NewAdapter(toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*passedSnapshot},
+				},
+				{
+					ContextKey: loader.AllSnapshotRunsForScenarioContextKey,
+					Resource:   oldSnapshotRuns,
+				},
+			}), hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: oldSnapshotRuns[gitops.MaxPeriodicSnapshotRuns].Name}, &v1beta2.SnapshotRun{})
+				return errors.IsNotFound(err)
+			}).Should(BeTrue())
+			Expect(getScheduledSnapshotRuns()).To(HaveLen(gitops.MaxPeriodicSnapshotRuns + 1))
+		})
+
+		It("skips the scheduled run when the application has no passed Snapshot", func() {
+			gitops.SetSnapshotIntegrationStatusAsInvalid(passedSnapshot, "the snapshot is invalid")
+			a := NewAdapter(toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*passedSnapshot},
+				},
+			}), hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(getScheduledSnapshotRuns()).To(BeEmpty())
+			Expect(scheduledScenario.Annotations).To(HaveKey(helpers.LastScheduledRunAnnotation))
+		})
+
+		It("creates a SnapshotRun revalidating the latest released Snapshot when the revalidation is due", func() {
+			delete(scheduledScenario.Annotations, helpers.ScheduleAnnotation)
+			scheduledScenario.Annotations[helpers.RevalidationScheduleAnnotation] = "@daily"
+			scheduledScenario.Annotations[helpers.LastRevalidationRunAnnotation] = time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+			meta.SetStatusCondition(&passedSnapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.SnapshotAutoReleasedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: "AutoReleased",
+			})
+			a := NewAdapter(toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*passedSnapshot},
+				},
+			}), hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(BeNumerically("<=", 24*time.Hour))
+
+			snapshotRuns := getScheduledSnapshotRuns()
+			Expect(snapshotRuns).To(HaveLen(1))
+			Expect(snapshotRuns[0].Spec.Snapshot).To(Equal(passedSnapshot.Name))
+			Expect(gitops.IsRevalidationSnapshotRun(&snapshotRuns[0])).To(BeTrue())
+			lastRun, err := time.Parse(time.RFC3339, scheduledScenario.Annotations[helpers.LastRevalidationRunAnnotation])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastRun).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+
+		It("doesn't revalidate the released Snapshots with the optional scenarios", func() {
+			delete(scheduledScenario.Annotations, helpers.ScheduleAnnotation)
+			scheduledScenario.Annotations[helpers.RevalidationScheduleAnnotation] = "@daily"
+			scheduledScenario.Labels = map[string]string{"test.appstudio.openshift.io/optional": "true"}
+			a := NewAdapter(ctx, hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeFalse())
+			Expect(getScheduledSnapshotRuns()).To(BeEmpty())
+		})
+
+		It("doesn't create periodic runs for the suspended IntegrationTestScenarios", func() {
+			scheduledScenario.Spec.Suspended = true
+			a := NewAdapter(ctx, hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeFalse())
+			Expect(getScheduledSnapshotRuns()).To(BeEmpty())
+		})
+
+		It("continues processing when the schedule is invalid", func() {
+			scheduledScenario.Annotations[helpers.ScheduleAnnotation] = "every morning"
+			a := NewAdapter(ctx, hasApp, scheduledScenario, logger, loader.NewMockLoader(), k8sClient)
+
+			result, err := a.EnsureScheduledRunsCreated()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(result.RequeueRequest).To(BeFalse())
+			Expect(getScheduledSnapshotRuns()).To(BeEmpty())
+		})
+	})
+
+	It("doesn't schedule runs for the IntegrationTestScenarios without a schedule", func() {
+		result, err := adapter.EnsureScheduledRunsCreated()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(result.RequeueRequest).To(BeFalse())
+	})
+	It("marks the unfinished integration tests of the suspended IntegrationTestScenario as suspended in the Snapshots", func() {
+		testedSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-tested",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, testedSnapshot)).Should(Succeed())
+		defer func() {
+			err := k8sClient.Delete(ctx, testedSnapshot)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		}()
+		testStatuses, err := intgteststat.NewSnapshotIntegrationTestStatuses("")
+		Expect(err).NotTo(HaveOccurred())
+		testStatuses.UpdateTestStatusIfChanged(integrationTestScenario.Name, intgteststat.IntegrationTestStatusInProgress, "")
+		testStatuses.UpdateTestStatusIfChanged(invalidScenario.Name, intgteststat.IntegrationTestStatusPending, "")
+		Expect(gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, testedSnapshot, testStatuses, k8sClient)).To(Succeed())
+
+		suspendedScenario := integrationTestScenario.DeepCopy()
+		suspendedScenario.Spec.Suspended = true
+		a := NewAdapter(toolkit.GetMockedContext(ctx, []toolkit.MockData{
+			{
+				ContextKey: loader.AllSnapshotsContextKey,
+				Resource:   []applicationapiv1alpha1.Snapshot{*testedSnapshot},
+			},
+		}), hasApp, suspendedScenario, logger, loader.NewMockLoader(), k8sClient)
+
+		result, err := a.EnsureSuspensionReflectedInSnapshots()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(result.RequeueRequest).To(BeFalse())
+
+		updatedSnapshot := &applicationapiv1alpha1.Snapshot{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: testedSnapshot.Name}, updatedSnapshot)).To(Succeed())
+		testStatuses, err = gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(updatedSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		suspendedStatus, ok := testStatuses.GetScenarioStatus(integrationTestScenario.Name)
+		Expect(ok).To(BeTrue())
+		Expect(suspendedStatus.Status).To(Equal(intgteststat.IntegrationTestStatusSuspended))
+		Expect(suspendedStatus.Details).To(ContainSubstring("was suspended"))
+		otherStatus, ok := testStatuses.GetScenarioStatus(invalidScenario.Name)
+		Expect(ok).To(BeTrue())
+		Expect(otherStatus.Status).To(Equal(intgteststat.IntegrationTestStatusPending))
+	})
+
+	It("doesn't update the Snapshots for the IntegrationTestScenarios which aren't suspended", func() {
+		result, err := adapter.EnsureSuspensionReflectedInSnapshots()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(result.RequeueRequest).To(BeFalse())
+	})
 })
@@ -20,6 +20,9 @@ import (
 	"context"
 
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
 
 	"github.com/go-logr/logr"
 	"github.com/konflux-ci/integration-service/api/v1beta2"
@@ -31,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // Reconciler reconciles an scenario object
@@ -51,15 +55,19 @@ func NewScenarioReconciler(client client.Client, logger *logr.Logger, scheme *ru
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationpolicies,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/status,verbs=get
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotruns,verbs=get;list;watch;create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("integrationTestScenario", req.NamespacedName)}
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("integrationTestScenario", req.NamespacedName, correlation.LogKey, correlationID)}
 	loader := loader.NewLoader()
 
 	scenario := &v1beta2.IntegrationTestScenario{}
@@ -72,17 +80,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 		return ctrl.Result{}, err
 	}
+	logger.Logger = correlation.WithParentID(logger.Logger, scenario)
 
 	application, err := r.getApplicationFromScenario(ctx, scenario)
 	if err != nil {
 		logger.Info("Failed to get Application from the IntegrationTestScenario", "error:", err)
 	}
 
-	adapter := NewAdapter(ctx, application, scenario, logger, loader, r.Client)
+	ctx, span := tracing.StartReconcileSpan(ctx, "scenario", map[string]client.Object{"scenario": scenario, "application": application})
+	defer span.End()
 
-	return controller.ReconcileHandler([]controller.Operation{
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, application, scenario, logger, loader, correlation.NewClient(r.Client, correlationID))
+
+	return operations.NewChain("scenario",
 		adapter.EnsureCreatedScenarioIsValid,
-	})
+		adapter.EnsureSuspensionReflectedInSnapshots,
+		adapter.EnsureScheduledRunsCreated,
+	).Run(ctx)
 }
 
 // getApplicationFromScenario loads from the cluster the Application referenced in the given scenario.
@@ -104,16 +121,19 @@ func (r *Reconciler) getApplicationFromScenario(context context.Context, scenari
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
 	EnsureCreatedScenarioIsValid() (controller.OperationResult, error)
+	EnsureSuspensionReflectedInSnapshots() (controller.OperationResult, error)
+	EnsureScheduledRunsCreated() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration controller and adds it to the Manager.
-func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewScenarioReconciler(manager.GetClient(), log, manager.GetScheme()))
+func SetupController(manager ctrl.Manager, log *logr.Logger, eventFilters ...predicate.Predicate) error {
+	return setupControllerWithManager(manager, NewScenarioReconciler(manager.GetClient(), log, manager.GetScheme()), eventFilters...)
 }
 
-func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
 
 	return ctrl.NewControllerManagedBy(manager).
 		For(&v1beta2.IntegrationTestScenario{}).
+		WithEventFilter(predicate.And(eventFilters...)).
 		Complete(controller)
 }
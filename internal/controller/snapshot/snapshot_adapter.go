@@ -22,11 +22,13 @@ import (
 	"fmt"
 	"k8s.io/client-go/util/retry"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	clienterrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
@@ -34,7 +36,11 @@ import (
 	h "github.com/konflux-ci/integration-service/helpers"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
 	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/pkg/ratelimit"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
 	"github.com/konflux-ci/integration-service/release"
+	"github.com/konflux-ci/integration-service/status"
 	"github.com/konflux-ci/integration-service/tekton"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
@@ -43,11 +49,17 @@ import (
 	"github.com/konflux-ci/operator-toolkit/metadata"
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const SnapshotRetryTimeout = time.Duration(3 * time.Hour)
 
+// QueuedPipelineRunsRequeueDelay is the delay after which the Snapshots with queued integration PipelineRuns are
+// reconciled again to check whether their namespace has capacity for them.
+const QueuedPipelineRunsRequeueDelay = time.Duration(30 * time.Second)
+
 // configuration options for scenario
 type ScenarioOptions struct {
 	IsReRun bool
@@ -60,11 +72,13 @@ type Adapter struct {
 	logger      h.IntegrationLogger
 	loader      loader.ObjectLoader
 	client      client.Client
+	recorder    record.EventRecorder
+	status      status.StatusInterface
 	context     context.Context
 }
 
 // NewAdapter creates and returns an Adapter instance.
-func NewAdapter(context context.Context, snapshot *applicationapiv1alpha1.Snapshot, application *applicationapiv1alpha1.Application, logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client,
+func NewAdapter(context context.Context, snapshot *applicationapiv1alpha1.Snapshot, application *applicationapiv1alpha1.Application, logger h.IntegrationLogger, loader loader.ObjectLoader, client client.Client, recorder record.EventRecorder,
 ) *Adapter {
 	return &Adapter{
 		snapshot:    snapshot,
@@ -72,6 +86,8 @@ func NewAdapter(context context.Context, snapshot *applicationapiv1alpha1.Snapsh
 		logger:      logger,
 		loader:      loader,
 		client:      client,
+		recorder:    recorder,
+		status:      status.NewStatus(logger.Logger, client),
 		context:     context,
 	}
 }
@@ -94,6 +110,20 @@ func (a *Adapter) EnsureRerunPipelineRunsExist() (controller.OperationResult, er
 		return controller.ContinueProcessing()
 	}
 	integrationTestScenario, err := a.loader.GetScenario(a.context, a.client, scenarioName, a.application.Namespace)
+	if clienterrors.IsNotFound(err) && scenarioName == gitops.EnterpriseContractScenarioName && gitops.IsEnterpriseContractGateEnabled(a.application) {
+		// the built-in Enterprise Contract gate isn't backed by an IntegrationTestScenario resource
+		integrationTestScenario, err = gitops.NewEnterpriseContractScenario(a.application), nil
+	}
+	if clienterrors.IsNotFound(err) {
+		// the matrix cells of the scenarios aren't backed by IntegrationTestScenario resources
+		integrationTestScenarios, getErr := a.loader.GetAllIntegrationTestScenariosForApplication(a.context, a.client, a.application)
+		if getErr != nil {
+			return controller.RequeueWithError(fmt.Errorf("failed to fetch the scenarios of application %s: %w", a.application.Name, getErr))
+		}
+		if matrixCell := gitops.FindMatrixCellScenario(integrationTestScenarios, scenarioName); matrixCell != nil {
+			integrationTestScenario, err = matrixCell, nil
+		}
+	}
 
 	if err != nil {
 		if clienterrors.IsNotFound(err) {
@@ -107,6 +137,14 @@ func (a *Adapter) EnsureRerunPipelineRunsExist() (controller.OperationResult, er
 		return controller.RequeueWithError(fmt.Errorf("failed to fetch requested scenario %s: %w", scenarioName, err))
 	}
 
+	if gitops.IsMatrixScenario(integrationTestScenario) {
+		a.logger.Info("The scenario for integration test re-run defines a matrix, only its matrix cells can be re-run", "scenario", scenarioName)
+		if err = gitops.RemoveIntegrationTestRerunLabel(a.context, a.client, a.snapshot); err != nil {
+			return controller.RequeueWithError(err)
+		}
+		return controller.ContinueProcessing()
+	}
+
 	a.logger.Info("Re-running integration test for scenario", "scenario", scenarioName)
 
 	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
@@ -124,6 +162,11 @@ func (a *Adapter) EnsureRerunPipelineRunsExist() (controller.OperationResult, er
 		}
 		return controller.ContinueProcessing()
 	}
+	if err = ratelimit.WaitForPipelineRunCreation(a.context); err != nil {
+		a.logger.Info("The creation of the pipelineRun is rate limited, retrying the re-run later",
+			"integrationTestScenario.Name", integrationTestScenario.Name)
+		return controller.RequeueAfter(QueuedPipelineRunsRequeueDelay, nil)
+	}
 	testStatuses.ResetStatus(scenarioName)
 
 	pipelineRun, err := a.createIntegrationPipelineRun(a.application, integrationTestScenario, a.snapshot)
@@ -141,6 +184,7 @@ func (a *Adapter) EnsureRerunPipelineRunsExist() (controller.OperationResult, er
 	if err = gitops.WriteIntegrationTestStatusesIntoSnapshot(a.context, a.snapshot, testStatuses, a.client); err != nil {
 		return controller.RequeueWithError(err)
 	}
+	a.reportIntegrationTestsStarted()
 
 	if err = gitops.ResetSnapshotStatusConditions(a.context, a.client, a.snapshot, "Integration test is being rerun for snapshot"); err != nil {
 		a.logger.Error(err, "Failed to reset snapshot status conditions")
@@ -150,10 +194,209 @@ func (a *Adapter) EnsureRerunPipelineRunsExist() (controller.OperationResult, er
 	if err = gitops.RemoveIntegrationTestRerunLabel(a.context, a.client, a.snapshot); err != nil {
 		return controller.RequeueWithError(err)
 	}
+	a.recorder.Eventf(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotTestStartedEventReason),
+		"Re-running integration test for scenario %s in pipelineRun %s", integrationTestScenario.Name, pipelineRun.Name)
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureSnapshotRunsStarted is an operation that will ensure that the integration PipelineRuns requested by the
+// SnapshotRuns of the Snapshot are created.
+func (a *Adapter) EnsureSnapshotRunsStarted() (controller.OperationResult, error) {
+	snapshotRuns, err := a.loader.GetAllSnapshotRunsForSnapshot(a.context, a.client, a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the SnapshotRuns of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+
+	for i := range *snapshotRuns {
+		snapshotRun := &(*snapshotRuns)[i]
+		if snapshotRun.HasStarted() || snapshotRun.HasFinished() {
+			continue
+		}
+
+		result, err := a.startSnapshotRun(snapshotRun)
+		if err != nil || result.RequeueRequest {
+			return result, err
+		}
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// startSnapshotRun creates the integration PipelineRuns of the IntegrationTestScenarios requested by the SnapshotRun,
+// recording each of them in its status as soon as it's created so that no PipelineRun is created twice when the
+// operation is retried.
+func (a *Adapter) startSnapshotRun(snapshotRun *v1beta2.SnapshotRun) (controller.OperationResult, error) {
+	integrationTestScenarios, invalidReason, err := a.getSnapshotRunScenarios(snapshotRun)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if invalidReason != "" {
+		if err = gitops.MarkSnapshotRunAsInvalid(a.context, a.client, snapshotRun, invalidReason); err != nil {
+			a.logger.Error(err, "Failed to mark the SnapshotRun as invalid", "snapshotRun.Name", snapshotRun.Name)
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("SnapshotRun marked as invalid", snapshotRun, h.LogActionUpdate, "reason", invalidReason)
+		a.recorder.Event(snapshotRun, corev1.EventTypeWarning, string(reasons.SnapshotRunInvalidEventReason), invalidReason)
+		return controller.ContinueProcessing()
+	}
+
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+
+	for i := range integrationTestScenarios {
+		integrationTestScenario := &integrationTestScenarios[i]
+		if slices.ContainsFunc(snapshotRun.Status.Scenarios, func(scenario v1beta2.SnapshotRunScenarioStatus) bool {
+			return scenario.Name == integrationTestScenario.Name
+		}) {
+			continue
+		}
+
+		scenarioStatus := v1beta2.SnapshotRunScenarioStatus{Name: integrationTestScenario.Name}
+		integrationTestScenarioStatus, ok := testStatuses.GetScenarioStatus(integrationTestScenario.Name)
+		if ok && (integrationTestScenarioStatus.Status == intgteststat.IntegrationTestStatusInProgress ||
+			integrationTestScenarioStatus.Status == intgteststat.IntegrationTestStatusPending) {
+			a.logger.Info(fmt.Sprintf("Found existing test in %s status, tracking it in the SnapshotRun", integrationTestScenarioStatus.Status),
+				"integrationTestScenario.Name", integrationTestScenario.Name, "snapshotRun.Name", snapshotRun.Name)
+			scenarioStatus.PipelineRun = integrationTestScenarioStatus.TestPipelineRunName
+		} else {
+			if err = ratelimit.WaitForPipelineRunCreation(a.context); err != nil {
+				a.logger.Info("The creation of the pipelineRun is rate limited, starting the SnapshotRun later",
+					"integrationTestScenario.Name", integrationTestScenario.Name, "snapshotRun.Name", snapshotRun.Name)
+				return controller.RequeueAfter(QueuedPipelineRunsRequeueDelay, nil)
+			}
+			testStatuses.ResetStatus(integrationTestScenario.Name)
+
+			pipelineRun, err := a.createIntegrationPipelineRun(a.application, integrationTestScenario, a.snapshot)
+			if err != nil {
+				result, err := a.HandlePipelineCreationError(err, integrationTestScenario, testStatuses)
+				if err != nil || result.RequeueRequest {
+					return result, err
+				}
+			} else {
+				scenarioStatus.PipelineRun = pipelineRun.Name
+				testStatuses.UpdateTestStatusIfChanged(
+					integrationTestScenario.Name, intgteststat.IntegrationTestStatusInProgress,
+					fmt.Sprintf("IntegrationTestScenario pipeline '%s' has been created", pipelineRun.Name))
+				if err = testStatuses.UpdateTestPipelineRunName(integrationTestScenario.Name, pipelineRun.Name); err != nil {
+					// it doesn't make sense to restart reconciliation here, it will be eventually updated by integrationpipeline adapter
+					a.logger.Error(err, "Failed to update pipelinerun name in test status")
+				}
+				if err = gitops.WriteIntegrationTestStatusesIntoSnapshot(a.context, a.snapshot, testStatuses, a.client); err != nil {
+					return controller.RequeueWithError(err)
+				}
+			}
+		}
+
+		patch := client.MergeFrom(snapshotRun.DeepCopy())
+		snapshotRun.Status.Scenarios = append(snapshotRun.Status.Scenarios, scenarioStatus)
+		if err = a.client.Status().Patch(a.context, snapshotRun, patch); err != nil {
+			a.logger.Error(err, "Failed to update the scenarios of the SnapshotRun", "snapshotRun.Name", snapshotRun.Name)
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	if snapshotRun.Spec.ReportStatus && !gitops.IsSnapshotStatusReportRequested(a.snapshot) {
+		if err = gitops.RequestSnapshotStatusReport(a.context, a.client, a.snapshot); err != nil {
+			a.logger.Error(err, "Failed to request the test status of the snapshot to be reported")
+			return controller.RequeueWithError(err)
+		}
+	}
+
+	if err = gitops.ResetSnapshotStatusConditions(a.context, a.client, a.snapshot,
+		fmt.Sprintf("Integration tests are being run for SnapshotRun %s", snapshotRun.Name)); err != nil {
+		a.logger.Error(err, "Failed to reset snapshot status conditions")
+		return controller.RequeueWithError(err)
+	}
+
+	if err = gitops.MarkSnapshotRunAsRunning(a.context, a.client, snapshotRun,
+		fmt.Sprintf("The integration tests of %d IntegrationTestScenarios are running", len(snapshotRun.Status.Scenarios))); err != nil {
+		a.logger.Error(err, "Failed to mark the SnapshotRun as running", "snapshotRun.Name", snapshotRun.Name)
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("SnapshotRun started", snapshotRun, h.LogActionUpdate)
+	a.recorder.Eventf(snapshotRun, corev1.EventTypeNormal, string(reasons.SnapshotRunStartedEventReason),
+		"Running the integration tests of %d IntegrationTestScenarios against Snapshot %s", len(snapshotRun.Status.Scenarios), a.snapshot.Name)
+	a.reportIntegrationTestsStarted()
 
 	return controller.ContinueProcessing()
 }
 
+// reportIntegrationTestsStarted reports the integration tests of the Snapshot whose pipelineRuns were just created to
+// the git provider, so that their in progress checks appear as soon as the testing starts. This is best effort, any
+// status which couldn't be reported here is reported by the statusreport controller when it processes the Snapshot.
+func (a *Adapter) reportIntegrationTestsStarted() {
+	if gitops.IsSnapshotCreatedByPACPushEvent(a.snapshot) && !gitops.IsSnapshotStatusReportRequested(a.snapshot) {
+		return
+	}
+
+	reporter := a.status.GetReporter(a.snapshot)
+	if reporter == nil {
+		return
+	}
+
+	if err := a.status.ReportSnapshotStatus(a.context, reporter, a.snapshot); err != nil {
+		a.logger.Error(err, "Failed to report the started integration tests to git provider, they will be reported later",
+			"reporter", reporter.GetReporterName())
+	}
+}
+
+// filterScenariosWithComponentSelector returns the IntegrationTestScenarios whose ComponentSelector selects the component
+// the Snapshot was created for. The Component is only loaded when some of the scenarios select components by their labels.
+func (a *Adapter) filterScenariosWithComponentSelector(scenarios *[]v1beta2.IntegrationTestScenario) (*[]v1beta2.IntegrationTestScenario, error) {
+	var component *applicationapiv1alpha1.Component
+	if gitops.IsComponentSnapshot(a.snapshot) && gitops.HasComponentLabelSelector(scenarios) {
+		var err error
+		component, err = a.loader.GetComponentFromSnapshot(a.context, a.client, a.snapshot)
+		if err != nil && !clienterrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the Component of the Snapshot to select its IntegrationTestScenarios: %w", err)
+		}
+	}
+
+	return gitops.FilterIntegrationTestScenariosWithComponentSelector(scenarios, a.snapshot, component), nil
+}
+
+// getSnapshotRunScenarios returns the IntegrationTestScenarios requested by the SnapshotRun, or the reason why the
+// SnapshotRun is invalid if none of them can be run.
+func (a *Adapter) getSnapshotRunScenarios(snapshotRun *v1beta2.SnapshotRun) ([]v1beta2.IntegrationTestScenario, string, error) {
+	if snapshotRun.Spec.Scenario != "" {
+		integrationTestScenario, err := a.loader.GetScenario(a.context, a.client, snapshotRun.Spec.Scenario, a.snapshot.Namespace)
+		if err != nil {
+			if clienterrors.IsNotFound(err) {
+				return nil, fmt.Sprintf("the IntegrationTestScenario %s doesn't exist", snapshotRun.Spec.Scenario), nil
+			}
+			return nil, "", fmt.Errorf("failed to fetch requested scenario %s: %w", snapshotRun.Spec.Scenario, err)
+		}
+		if integrationTestScenario.Spec.Application != a.application.Name {
+			return nil, fmt.Sprintf("the IntegrationTestScenario %s doesn't belong to the application %s",
+				snapshotRun.Spec.Scenario, a.application.Name), nil
+		}
+		if h.IsScenarioSuspended(integrationTestScenario) {
+			return nil, fmt.Sprintf("the IntegrationTestScenario %s is suspended", snapshotRun.Spec.Scenario), nil
+		}
+		return *gitops.ExpandIntegrationTestScenarioMatrix(&[]v1beta2.IntegrationTestScenario{*integrationTestScenario}), "", nil
+	}
+
+	integrationTestScenarios, err := a.loader.GetAllIntegrationTestScenariosForApplication(a.context, a.client, a.application)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get the IntegrationTestScenarios of the application %s: %w", a.application.Name, err)
+	}
+	integrationTestScenarios, err = a.filterScenariosWithComponentSelector(gitops.FilterActiveIntegrationTestScenarios(
+		gitops.FilterIntegrationTestScenariosWithContext(integrationTestScenarios, a.snapshot)))
+	if err != nil {
+		return nil, "", err
+	}
+	integrationTestScenarios = gitops.ExpandIntegrationTestScenarioMatrix(integrationTestScenarios)
+	if integrationTestScenarios == nil || len(*integrationTestScenarios) == 0 {
+		return nil, fmt.Sprintf("no IntegrationTestScenario of the application %s applies to the Snapshot", a.application.Name), nil
+	}
+
+	return *integrationTestScenarios, "", nil
+}
+
 // EnsureIntegrationPipelineRunsExist is an operation that will ensure that all Integration pipeline runs
 // associated with the Snapshot and the Application's IntegrationTestScenarios exist.
 func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResult, error) {
@@ -167,8 +410,35 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 		a.logger.Error(err, "Failed to get Integration test scenarios for the following application",
 			"Application.Namespace", a.application.Namespace)
 	}
+	if integrationTestScenarios != nil {
+		integrationTestScenarios = gitops.FilterIntegrationTestScenariosWithContext(integrationTestScenarios, a.snapshot)
+		integrationTestScenarios, err = a.filterScenariosWithComponentSelector(integrationTestScenarios)
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+		if gitops.IsSnapshotCreatedForComponentRemoval(a.snapshot) {
+			// only the required tests are run to gate the application without the removed component
+			policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+			if err != nil {
+				a.logger.Error(err, "Failed to get the IntegrationPolicy of the application")
+				return controller.RequeueWithError(err)
+			}
+			integrationTestScenarios = gitops.FilterRequiredIntegrationTestScenarios(policy, integrationTestScenarios)
+		}
+		integrationTestScenarios = gitops.ExpandIntegrationTestScenarioMatrix(integrationTestScenarios)
+	}
+	if err == nil {
+		// the Enterprise Contract policy evaluation is run alongside the scenarios when the application opted into it
+		integrationTestScenarios = gitops.AddEnterpriseContractScenario(a.application, integrationTestScenarios)
+	}
 
+	queued := false
 	if integrationTestScenarios != nil {
+		maxRunningPipelineRuns, runningPipelineRuns, err := a.getIntegrationPipelineRunsCapacity()
+		if err != nil {
+			return controller.RequeueWithError(err)
+		}
+
 		a.logger.Info(
 			fmt.Sprintf("Found %d IntegrationTestScenarios for application", len(*integrationTestScenarios)),
 			"Application.Name", a.application.Name,
@@ -198,8 +468,20 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 		}()
 
 		var errsForPLRCreation error
+		started := false
+		observedScenarios := map[string]bool{}
 		for _, integrationTestScenario := range *integrationTestScenarios {
 			integrationTestScenario := integrationTestScenario //G601
+			if h.IsScenarioSuspended(&integrationTestScenario) {
+				a.logger.Info("IntegrationTestScenario is suspended, will not create pipelineRun for it",
+					"integrationTestScenario.Name", integrationTestScenario.Name)
+				if testStatus, ok := testStatuses.GetScenarioStatus(integrationTestScenario.Name); !ok || !testStatus.Status.IsFinal() {
+					testStatuses.UpdateTestStatusIfChanged(
+						integrationTestScenario.Name, intgteststat.IntegrationTestStatusSuspended,
+						fmt.Sprintf("IntegrationTestScenario '%s' is suspended", integrationTestScenario.Name))
+				}
+				continue
+			}
 			if !h.IsScenarioValid(&integrationTestScenario) {
 				a.logger.Info("IntegrationTestScenario is invalid, will not create pipelineRun for it",
 					"integrationTestScenario.Name", integrationTestScenario.Name)
@@ -216,6 +498,21 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 				a.logger.Info("Found existing integrationPipelineRun",
 					"integrationTestScenario.Name", integrationTestScenario.Name,
 					"pipelineRun.Name", integrationTestScenarioStatus.TestPipelineRunName)
+			} else if maxRunningPipelineRuns > 0 && runningPipelineRuns >= maxRunningPipelineRuns {
+				a.logger.Info("The namespace reached the maximum number of running integration pipelineRuns, queueing the pipelineRun",
+					"integrationTestScenario.Name", integrationTestScenario.Name,
+					"maxRunningPipelineRuns", maxRunningPipelineRuns)
+				testStatuses.UpdateTestStatusIfChanged(
+					integrationTestScenario.Name, intgteststat.IntegrationTestStatusPending,
+					fmt.Sprintf("Queued until one of the %d running integration pipelineRuns of the namespace finishes", maxRunningPipelineRuns))
+				queued = true
+			} else if err := ratelimit.WaitForPipelineRunCreation(a.context); err != nil {
+				a.logger.Info("The creation of the pipelineRun is rate limited, queueing the pipelineRun",
+					"integrationTestScenario.Name", integrationTestScenario.Name)
+				testStatuses.UpdateTestStatusIfChanged(
+					integrationTestScenario.Name, intgteststat.IntegrationTestStatusPending,
+					"Queued until the rate limit of the pipelineRun creations allows creating the pipelineRun")
+				queued = true
 			} else {
 				pipelineRun, err := a.createIntegrationPipelineRun(a.application, &integrationTestScenario, a.snapshot)
 				if err != nil {
@@ -231,7 +528,11 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 					}
 					continue
 				}
+				runningPipelineRuns++
+				started = true
 				gitops.PrepareToRegisterIntegrationPipelineRunStarted(a.snapshot) // don't count re-runs
+				a.recorder.Eventf(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotTestStartedEventReason),
+					"Started integration test for scenario %s in pipelineRun %s", integrationTestScenario.Name, pipelineRun.Name)
 				testStatuses.UpdateTestStatusIfChanged(
 					integrationTestScenario.Name, intgteststat.IntegrationTestStatusInProgress,
 					fmt.Sprintf("IntegrationTestScenario pipeline '%s' has been created", pipelineRun.Name))
@@ -239,6 +540,22 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 					// it doesn't make sense to restart reconciliation here, it will be eventually updated by integrationpipeline adapter
 					a.logger.Error(err, "Failed to update pipelinerun name in test status")
 				}
+				if h.IsScenarioObserveOnly(&integrationTestScenario) {
+					a.logger.Info("IntegrationTestScenario is in its observe-only runs, its result won't gate the Snapshot",
+						"integrationTestScenario.Name", integrationTestScenario.Name)
+					if err = testStatuses.UpdateTestObserveOnly(integrationTestScenario.Name, true); err != nil {
+						a.logger.Error(err, "Failed to mark the test status as observe-only")
+					}
+					observedScenarios[getParentScenarioName(&integrationTestScenario)] = true
+				}
+			}
+		}
+
+		for scenarioName := range observedScenarios {
+			if err := a.registerObservedRun(scenarioName); err != nil {
+				a.logger.Error(err, "Failed to register the observe-only run of the IntegrationTestScenario",
+					"integrationTestScenario.Name", scenarioName)
+				errsForPLRCreation = errors.Join(errsForPLRCreation, err)
 			}
 		}
 
@@ -246,6 +563,8 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 		if err != nil {
 			a.logger.Error(err, "Failed to update test status in snapshot annotation")
 			errsForPLRCreation = errors.Join(errsForPLRCreation, err)
+		} else if started {
+			a.reportIntegrationTestsStarted()
 		}
 
 		if errsForPLRCreation != nil {
@@ -256,13 +575,28 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 	requiredIntegrationTestScenarios, err := a.loader.GetRequiredIntegrationTestScenariosForApplication(a.context, a.client, a.application)
 	if err != nil {
 		a.logger.Error(err, "Failed to get all required IntegrationTestScenarios")
-		patch := client.MergeFrom(a.snapshot.DeepCopy())
-		gitops.SetSnapshotIntegrationStatusAsError(a.snapshot, "Failed to get all required IntegrationTestScenarios: "+err.Error())
 		a.logger.LogAuditEvent("Snapshot integration status marked as Invalid. Failed to get all required IntegrationTestScenarios",
 			a.snapshot, h.LogActionUpdate)
-		return controller.RequeueOnErrorOrStop(a.client.Status().Patch(a.context, a.snapshot, patch))
+		return controller.RequeueOnErrorOrStop(gitops.MarkSnapshotIntegrationStatusAsError(a.context, a.client, a.snapshot,
+			"Failed to get all required IntegrationTestScenarios: "+err.Error()))
 	}
+	requiredIntegrationTestScenarios = gitops.FilterIntegrationTestScenariosWithContext(requiredIntegrationTestScenarios, a.snapshot)
+	requiredIntegrationTestScenarios, err = a.filterScenariosWithComponentSelector(requiredIntegrationTestScenarios)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	requiredIntegrationTestScenarios = gitops.ExpandIntegrationTestScenarioMatrix(requiredIntegrationTestScenarios)
+	requiredIntegrationTestScenarios = gitops.AddEnterpriseContractScenario(a.application, requiredIntegrationTestScenarios)
 	if len(*requiredIntegrationTestScenarios) == 0 && !gitops.IsSnapshotMarkedAsPassed(a.snapshot) {
+		decision := gitops.NewGatingDecision(gitops.GatingDecisionPassed, []string{}, nil, "No required IntegrationTestScenarios found, skipped testing")
+		if err := gitops.RecordGatingDecision(a.context, a.client, a.snapshot, decision); err != nil {
+			a.logger.Error(err, "Failed to record the gating decision for the Snapshot")
+			return controller.RequeueWithError(err)
+		}
+		if err := gitops.WriteDecisionExplanation(a.context, a.client, a.snapshot, gitops.NewDecisionExplanation(decision, nil)); err != nil {
+			a.logger.Error(err, "Failed to write the decision explanation for the Snapshot")
+			return controller.RequeueWithError(err)
+		}
 		err := gitops.MarkSnapshotAsPassed(a.context, a.client, a.snapshot, "No required IntegrationTestScenarios found, skipped testing")
 		if err != nil {
 			a.logger.Error(err, "Failed to update Snapshot status")
@@ -271,11 +605,216 @@ func (a *Adapter) EnsureIntegrationPipelineRunsExist() (controller.OperationResu
 		a.logger.LogAuditEvent("Snapshot marked as successful. No required IntegrationTestScenarios found, skipped testing",
 			a.snapshot, h.LogActionUpdate,
 			"snapshot.Status", a.snapshot.Status)
+		a.recorder.Event(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotPassedEventReason),
+			"No required IntegrationTestScenarios found, skipped testing")
+	}
+
+	if queued {
+		return controller.RequeueAfter(QueuedPipelineRunsRequeueDelay, nil)
 	}
 
 	return controller.ContinueProcessing()
 }
 
+// registerObservedRun increments the number of the observe-only runs of the IntegrationTestScenario with the given
+// name, so that the scenario starts gating the Snapshots once all of its observe-only runs were started.
+func (a *Adapter) registerObservedRun(scenarioName string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scenario, err := a.loader.GetScenario(a.context, a.client, scenarioName, a.snapshot.Namespace)
+		if err != nil {
+			return err
+		}
+		if !h.IsScenarioObserveOnly(scenario) {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(scenario.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		scenario.Status.ObservedRuns++
+		if err = a.client.Status().Patch(a.context, scenario, patch); err != nil {
+			return err
+		}
+		a.logger.LogAuditEvent("Registered the observe-only run of the IntegrationTestScenario", scenario, h.LogActionUpdate,
+			"observedRuns", scenario.Status.ObservedRuns,
+			"observeOnlyRuns", scenario.Spec.ObserveOnlyRuns)
+		return nil
+	})
+}
+
+// getParentScenarioName returns the name of the IntegrationTestScenario the given scenario was expanded from when it
+// is a matrix cell, or the name of the scenario itself otherwise.
+func getParentScenarioName(scenario *v1beta2.IntegrationTestScenario) string {
+	if matrixScenario, ok := scenario.GetLabels()[tekton.MatrixScenarioLabel]; ok {
+		return matrixScenario
+	}
+	return scenario.Name
+}
+
+// getIntegrationPipelineRunsCapacity returns the maximum number of integration PipelineRuns which may be running at
+// once in the namespace of the Snapshot, 0 meaning there is no limit, and the number of the running ones.
+// An invalid limit is logged and ignored, so a misconfigured namespace doesn't stop its Snapshots from being tested.
+func (a *Adapter) getIntegrationPipelineRunsCapacity() (int, int, error) {
+	namespace, err := a.loader.GetNamespace(a.context, a.client, a.snapshot.Namespace)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the namespace %s of the snapshot: %w", a.snapshot.Namespace, err)
+	}
+	maxRunningPipelineRuns, err := tekton.GetMaxRunningIntegrationPipelineRuns(namespace)
+	if err != nil {
+		a.logger.Error(err, "Ignoring the maximum number of running integration pipelineRuns of the namespace")
+		return 0, 0, nil
+	}
+	if maxRunningPipelineRuns == 0 {
+		return 0, 0, nil
+	}
+
+	runningPipelineRuns, err := a.loader.GetRunningIntegrationPipelineRuns(a.context, a.client, a.snapshot.Namespace)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the running integration pipelineRuns of the namespace %s: %w", a.snapshot.Namespace, err)
+	}
+	return maxRunningPipelineRuns, len(*runningPipelineRuns), nil
+}
+
+// EnsureSnapshotOwnedByApplication is an operation that will ensure that the Snapshot is owned by its Application, so that
+// the Snapshot and its integration PipelineRuns are garbage collected when the Application is deleted. The Snapshots created
+// by the integration service are already owned by their Application, but the ones created by users, e.g. override Snapshots,
+// aren't. Snapshots controlled by another object are left as they are.
+func (a *Adapter) EnsureSnapshotOwnedByApplication() (controller.OperationResult, error) {
+	if metav1.GetControllerOf(a.snapshot) != nil {
+		return controller.ContinueProcessing()
+	}
+
+	patch := client.MergeFrom(a.snapshot.DeepCopy())
+	if err := ctrl.SetControllerReference(a.application, a.snapshot, a.client.Scheme()); err != nil {
+		a.logger.Error(err, "Error setting owner reference of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	if err := a.client.Patch(a.context, a.snapshot, patch); err != nil {
+		a.logger.Error(err, "Failed to set the Application as the owner of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Snapshot is now owned by its Application", a.snapshot, h.LogActionUpdate)
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureSnapshotDiffRecorded is an operation that will ensure that the component images which changed in the
+// Snapshot relative to the previous Snapshot of the application are recorded in the Snapshot annotation, so that
+// they can be shown at a glance by the UIs and reporters.
+func (a *Adapter) EnsureSnapshotDiffRecorded() (controller.OperationResult, error) {
+	if gitops.HasSnapshotDiff(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	snapshots, err := a.loader.GetAllSnapshots(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to load the Snapshots of the application")
+		return controller.RequeueWithError(err)
+	}
+
+	diff := gitops.NewSnapshotDiff(gitops.GetPreviousSnapshot(snapshots, a.snapshot), a.snapshot)
+	if err = gitops.RecordSnapshotDiff(a.context, a.client, a.snapshot, diff); err != nil {
+		a.logger.Error(err, "Failed to record the diff of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Snapshot diff was recorded", a.snapshot, h.LogActionUpdate,
+		"previousSnapshot", diff.PreviousSnapshot, "added", len(diff.Added), "removed", len(diff.Removed), "updated", len(diff.Updated))
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureImageVerificationRecorded is an operation that will ensure that the result of the verification of the
+// signature and provenance attestation of the image built for the Snapshot is set as its condition. A Snapshot whose
+// image failed the verification is marked as invalid and won't be tested nor added to the global candidate list.
+func (a *Adapter) EnsureImageVerificationRecorded() (controller.OperationResult, error) {
+	if gitops.IsSnapshotImageVerificationFailed(a.snapshot) {
+		a.logger.Info("The image of the Snapshot failed verification, it won't be processed further.")
+		return controller.StopProcessing()
+	}
+
+	verification, err := gitops.GetSnapshotImageVerification(a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the image verification of the Snapshot")
+		return controller.StopProcessing()
+	}
+	if verification == nil || meta.FindStatusCondition(a.snapshot.Status.Conditions, gitops.SnapshotImageVerifiedCondition) != nil {
+		return controller.ContinueProcessing()
+	}
+
+	if err = gitops.MarkSnapshotImageVerification(a.context, a.client, a.snapshot, verification.Verified, verification.Message); err != nil {
+		a.logger.Error(err, "Failed to update the image verification condition of the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Snapshot image verification condition was set", a.snapshot, h.LogActionUpdate,
+		"verified", verification.Verified, "message", verification.Message)
+	if !verification.Verified {
+		a.recorder.Event(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotImageVerificationFailedEventReason), verification.Message)
+		return controller.StopProcessing()
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureOverrideSnapshotIsValid is an operation that will ensure that the components of an override Snapshot,
+// which may have been composed manually, are components of its application and reference images by digest.
+// Otherwise, the Snapshot will be marked as invalid and won't be tested nor added to the global candidate list.
+func (a *Adapter) EnsureOverrideSnapshotIsValid() (controller.OperationResult, error) {
+	if !gitops.IsOverrideSnapshot(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	if gitops.IsSnapshotMarkedAsInvalid(a.snapshot) {
+		a.logger.Info("The override Snapshot was marked as invalid, it won't be processed further.")
+		return controller.StopProcessing()
+	}
+
+	invalidReason, err := a.getOverrideSnapshotInvalidReason()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if invalidReason == "" {
+		return controller.ContinueProcessing()
+	}
+
+	message := fmt.Sprintf("The override Snapshot is invalid: %s", invalidReason)
+	if err = gitops.MarkSnapshotAsInvalid(a.context, a.client, a.snapshot, message); err != nil {
+		a.logger.Error(err, "Failed to update the status to Invalid for the snapshot")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Snapshot integration status condition marked as invalid, the override Snapshot is invalid",
+		a.snapshot, h.LogActionUpdate, "reason", invalidReason)
+	a.recorder.Event(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotInvalidEventReason), message)
+
+	return controller.StopProcessing()
+}
+
+// getOverrideSnapshotInvalidReason returns the reason why the components of the override Snapshot can't be tested,
+// or an empty string when all of them are valid. An error is returned when the components couldn't be loaded.
+func (a *Adapter) getOverrideSnapshotInvalidReason() (string, error) {
+	if len(a.snapshot.Spec.Components) == 0 {
+		return "it doesn't have any components", nil
+	}
+
+	for _, snapshotComponent := range a.snapshot.Spec.Components {
+		if err := gitops.ValidateImageDigest(snapshotComponent.ContainerImage); err != nil {
+			return fmt.Sprintf("the image %s of component %s isn't referenced by a valid digest",
+				snapshotComponent.ContainerImage, snapshotComponent.Name), nil
+		}
+
+		component, err := a.loader.GetComponent(a.context, a.client, snapshotComponent.Name, a.snapshot.Namespace)
+		if err != nil {
+			if clienterrors.IsNotFound(err) {
+				return fmt.Sprintf("the component %s doesn't exist", snapshotComponent.Name), nil
+			}
+			return "", fmt.Errorf("failed to get the component %s of the override snapshot: %w", snapshotComponent.Name, err)
+		}
+		if component.Spec.Application != a.snapshot.Spec.Application {
+			return fmt.Sprintf("the component %s doesn't belong to the application %s",
+				snapshotComponent.Name, a.snapshot.Spec.Application), nil
+		}
+	}
+
+	return "", nil
+}
+
 // EnsureGlobalCandidateImageUpdated is an operation that ensure the ContainerImage in the Global Candidate List
 // being updated when the Snapshot is created
 func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResult, error) {
@@ -306,6 +845,17 @@ func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResul
 			return controller.ContinueProcessing()
 		}
 
+		// the first build of a component is only added once it was tested with the rest of the application
+		if componentToUpdate.Spec.ContainerImage == "" {
+			canBeAdded, err := a.canNewComponentBeAddedToGlobalCandidateList(componentToUpdate)
+			if err != nil {
+				return controller.RequeueWithError(err)
+			}
+			if !canBeAdded {
+				return controller.ContinueProcessing()
+			}
+		}
+
 		// look for the expected snapshotComponnet and update
 		for _, snapshotComponent := range a.snapshot.Spec.Components {
 			snapshotComponent := snapshotComponent //G601
@@ -364,6 +914,18 @@ func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResul
 		}
 	}
 
+	if gitops.IsOverrideSnapshot(a.snapshot) {
+		decision := gitops.NewGatingDecision(gitops.GatingDecisionOverride, []string{}, nil,
+			"The components of the override Snapshot were added to the global candidate list without integration testing")
+		decision.Actor = gitops.GetSnapshotCreator(a.snapshot)
+		if err = gitops.RecordGatingDecision(a.context, a.client, a.snapshot, decision); err != nil {
+			a.logger.Error(err, "Failed to record the override gating decision for the Snapshot")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Recorded override gating decision for the Snapshot", a.snapshot, h.LogActionUpdate,
+			"actor", decision.Actor)
+	}
+
 	// Mark the Snapshot as already added to global candidate list to prevent it from getting added again when the Snapshot
 	// gets reconciled at a later time
 	err = gitops.MarkSnapshotAsAddedToGlobalCandidateList(a.context, a.client, a.snapshot, "The Snapshot's component(s) was/were added to the global candidate list")
@@ -371,18 +933,63 @@ func (a *Adapter) EnsureGlobalCandidateImageUpdated() (controller.OperationResul
 		a.logger.Error(err, "Failed to update the Snapshot's status to AddedToGlobalCandidateList")
 		return controller.RequeueWithError(err)
 	}
+	if gitops.IsOverrideSnapshot(a.snapshot) {
+		a.recorder.Event(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotOverrideEventReason),
+			"The components of the override Snapshot were added to the global candidate list")
+	}
 
 	return controller.ContinueProcessing()
 }
 
+// canNewComponentBeAddedToGlobalCandidateList returns whether the first build of the Component, which isn't in
+// the global candidate list yet, can be added to it. When the application already has passed Snapshots, the new
+// Component is only added once the Snapshot including it passed its integration tests, so that onboarding it can't
+// break the tested state of the application. The failed onboarding is reported once per Snapshot.
+func (a *Adapter) canNewComponentBeAddedToGlobalCandidateList(component *applicationapiv1alpha1.Component) (bool, error) {
+	if gitops.IsSnapshotMarkedAsPassed(a.snapshot) {
+		return true, nil
+	}
+
+	snapshots, err := a.loader.GetAllSnapshots(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the Snapshots of the application")
+		return false, err
+	}
+	if gitops.GetLatestPassedSnapshot(snapshots) == nil {
+		// nothing was tested yet, the application is still being set up
+		return true, nil
+	}
+
+	if !gitops.HaveAppStudioTestsFinished(a.snapshot) {
+		a.logger.Info("The new component is added to the global candidate list once the Snapshot passes its integration tests",
+			"component.Name", component.Name)
+		return false, nil
+	}
+
+	a.logger.Info("The Snapshot failed its integration tests, the new component isn't added to the global candidate list",
+		"component.Name", component.Name)
+	if a.snapshot.GetAnnotations()[gitops.SnapshotOnboardingFailedAnnotation] == component.Name {
+		return false, nil
+	}
+	err = h.ApplyMetadata(a.context, a.client, a.snapshot, nil,
+		map[string]string{gitops.SnapshotOnboardingFailedAnnotation: component.Name})
+	if err != nil {
+		a.logger.Error(err, "Failed to annotate the Snapshot with the component which failed its onboarding")
+		return false, err
+	}
+	a.recorder.Eventf(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotOnboardingFailedEventReason),
+		"The new component %s wasn't added to the global candidate list since the Snapshot failed its integration tests", component.Name)
+	return false, nil
+}
+
 // EnsureAllReleasesExist is an operation that will ensure that all pipeline Releases associated
 // to the Snapshot and the Application's ReleasePlans exist.
 // Otherwise, it will create new Releases for each ReleasePlan.
 func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
-	canSnapshotBePromoted, reasons := gitops.CanSnapshotBePromoted(a.snapshot)
+	canSnapshotBePromoted, notPromotedReasons := gitops.CanSnapshotBePromoted(a.snapshot)
 	if !canSnapshotBePromoted {
 		a.logger.Info("The Snapshot won't be released.",
-			"reasons", strings.Join(reasons, ","))
+			"reasons", strings.Join(notPromotedReasons, ","))
 		return controller.ContinueProcessing()
 	}
 	if gitops.IsSnapshotMarkedAsAutoReleased(a.snapshot) {
@@ -390,14 +997,50 @@ func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
 		return controller.ContinueProcessing()
 	}
 
+	policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the IntegrationPolicy of the application")
+		return controller.RequeueWithError(err)
+	}
+	if policy != nil && !policy.IsAutoReleaseEnabled() {
+		a.logger.Info("Auto-release is disabled by the IntegrationPolicy of the application, skipping auto-release.",
+			"integrationPolicy.Name", policy.Name)
+		return controller.ContinueProcessing()
+	}
+
+	withinReleaseWindow, nextOpening, err := gitops.IsWithinReleaseWindow(policy, time.Now())
+	if err != nil {
+		a.logger.Error(err, "Failed to evaluate some of the release windows of the IntegrationPolicy",
+			"integrationPolicy.Name", policy.Name)
+	}
+	if !withinReleaseWindow {
+		a.logger.Info("The Snapshot passed outside of the release windows of the IntegrationPolicy, holding auto-release.",
+			"integrationPolicy.Name", policy.Name, "nextOpening", nextOpening)
+		if gitops.IsSnapshotPendingReleaseWindow(a.snapshot) {
+			return controller.ContinueProcessing()
+		}
+		message := "The Snapshot passed outside of the release windows, no release window will open"
+		if !nextOpening.IsZero() {
+			message = fmt.Sprintf("The Snapshot passed outside of the release windows, it will be auto-released once the next window opens at %s",
+				nextOpening.UTC().Format(time.RFC3339))
+		}
+		err = gitops.MarkSnapshotAsPendingReleaseWindow(a.context, a.client, a.snapshot, message)
+		if err != nil {
+			a.logger.Error(err, "Failed to update the Snapshot's status to pending the release window")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Snapshot auto-release is held until the next release window opens", a.snapshot, h.LogActionUpdate,
+			"nextOpening", nextOpening)
+		a.recorder.Event(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotPendingReleaseWindowEventReason), message)
+		return controller.ContinueProcessing()
+	}
+
 	releasePlans, err := a.loader.GetAutoReleasePlansForApplication(a.context, a.client, a.application)
 	if err != nil {
 		a.logger.Error(err, "Failed to get all ReleasePlans")
-		patch := client.MergeFrom(a.snapshot.DeepCopy())
-		gitops.SetSnapshotIntegrationStatusAsError(a.snapshot, "Failed to get all ReleasePlans: "+err.Error())
 		a.logger.LogAuditEvent("Snapshot integration status marked as Invalid. Failed to get all ReleasePlans",
 			a.snapshot, h.LogActionUpdate)
-		er := a.client.Status().Patch(a.context, a.snapshot, patch)
+		er := gitops.MarkSnapshotIntegrationStatusAsError(a.context, a.client, a.snapshot, "Failed to get all ReleasePlans: "+err.Error())
 		if er != nil {
 			a.logger.Error(er, "Failed to mark snapshot integration status as invalid",
 				"snapshot.Name", a.snapshot.Name)
@@ -409,11 +1052,9 @@ func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
 	err = a.createMissingReleasesForReleasePlans(a.application, releasePlans, a.snapshot)
 	if err != nil {
 		a.logger.Error(err, "Failed to create new Releases")
-		patch := client.MergeFrom(a.snapshot.DeepCopy())
-		gitops.SetSnapshotIntegrationStatusAsError(a.snapshot, "Failed to create new Releases: "+err.Error())
 		a.logger.LogAuditEvent("Snapshot integration status marked as Invalid. Failed to create new Releases",
 			a.snapshot, h.LogActionUpdate)
-		er := a.client.Status().Patch(a.context, a.snapshot, patch)
+		er := gitops.MarkSnapshotIntegrationStatusAsError(a.context, a.client, a.snapshot, "Failed to create new Releases: "+err.Error())
 		if er != nil {
 			a.logger.Error(er, "Failed to mark snapshot integration status as invalid",
 				"snapshot.Name", a.snapshot.Name)
@@ -433,6 +1074,190 @@ func (a *Adapter) EnsureAllReleasesExist() (controller.OperationResult, error) {
 	return controller.ContinueProcessing()
 }
 
+// EnsureLifecycleEventsNotified is an operation that will ensure that the notification sinks configured for
+// the namespace, if there are any, are notified once the Snapshot was created and once it was auto-released.
+func (a *Adapter) EnsureLifecycleEventsNotified() (controller.OperationResult, error) {
+	events := []*notification.Event{}
+	if !notification.IsEventNotified(a.snapshot, notification.EventSnapshotCreated) {
+		event := notification.NewSnapshotEvent(notification.EventSnapshotCreated, a.snapshot)
+		event.Message = "the Snapshot was created"
+		events = append(events, event)
+	}
+	if gitops.IsSnapshotMarkedAsAutoReleased(a.snapshot) && !notification.IsEventNotified(a.snapshot, notification.EventSnapshotReleased) {
+		event := notification.NewSnapshotEvent(notification.EventSnapshotReleased, a.snapshot)
+		event.Message = "the Snapshot was auto-released"
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	sinks, err := notification.LoadSinks(a.context, a.client, a.snapshot.Namespace)
+	if notification.IsInvalidConfiguration(err) {
+		// a misconfigured secret won't be fixed by retrying, so don't block the snapshot on it
+		a.logger.Error(err, "Failed to configure the notification sinks, skipping the notification")
+		return controller.ContinueProcessing()
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to load the notification sinks", "secret.Name", notification.SecretName)
+		return a.RequeueIfYoungerThanThreshold(err)
+	}
+	if len(sinks) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	// stop at the first failure to deliver the events in order, the remaining ones are delivered when retrying
+	patch := client.MergeFrom(a.snapshot.DeepCopy())
+	var errForNotification error
+	for _, event := range events {
+		if err := notification.SendOnce(a.context, a.snapshot, sinks, event); err != nil {
+			a.logger.Error(err, "Failed to notify about the snapshot lifecycle event", "event.Type", event.Type)
+			errForNotification = err
+			break
+		}
+		_ = notification.SetEventNotified(a.snapshot, event.Type)
+		a.logger.LogAuditEvent("Notified about the snapshot lifecycle event", a.snapshot, h.LogActionUpdate,
+			"event.Type", event.Type,
+			"sinks", len(sinks))
+	}
+
+	err = a.client.Patch(a.context, a.snapshot, patch)
+	if err != nil && !clienterrors.IsNotFound(err) {
+		a.logger.Error(err, "Failed to annotate the snapshot with the notified lifecycle events")
+		return a.RequeueIfYoungerThanThreshold(err)
+	}
+	if errForNotification != nil {
+		return a.RequeueIfYoungerThanThreshold(errForNotification)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureReleaseWindowAwaited is an operation that will ensure that the Snapshot which passed outside of the release
+// windows of its application is reconciled again once the next window opens, so that it gets auto-released then.
+func (a *Adapter) EnsureReleaseWindowAwaited() (controller.OperationResult, error) {
+	if !gitops.IsSnapshotPendingReleaseWindow(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+	if canSnapshotBePromoted, _ := gitops.CanSnapshotBePromoted(a.snapshot); !canSnapshotBePromoted {
+		return controller.ContinueProcessing()
+	}
+
+	policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the IntegrationPolicy of the application")
+		return controller.RequeueWithError(err)
+	}
+	if policy != nil && !policy.IsAutoReleaseEnabled() {
+		return controller.ContinueProcessing()
+	}
+
+	// the window could have opened since the Snapshot was held, it's then auto-released right away
+	withinReleaseWindow, nextOpening, _ := gitops.IsWithinReleaseWindow(policy, time.Now())
+	if withinReleaseWindow {
+		return controller.Requeue()
+	}
+	if nextOpening.IsZero() {
+		return controller.ContinueProcessing()
+	}
+
+	a.logger.Info("Requeueing the Snapshot until the next release window opens", "nextOpening", nextOpening)
+	return controller.RequeueAfter(time.Until(nextOpening), nil)
+}
+
+// EnsurePromotionRequestHandled is an operation that will ensure that the Snapshot which was manually requested to be
+// promoted to an Environment through its annotation is deployed there once, by creating or updating the
+// SnapshotEnvironmentBinding of its application for that Environment.
+func (a *Adapter) EnsurePromotionRequestHandled() (controller.OperationResult, error) {
+	environmentName, found := gitops.GetSnapshotPromotionTarget(a.snapshot)
+	if !found {
+		return controller.ContinueProcessing()
+	}
+	// the request is kept until the Snapshot finishes testing, it's then reconciled again
+	if !gitops.HaveAppStudioTestsFinished(a.snapshot) && !gitops.IsSnapshotMarkedAsInvalid(a.snapshot) {
+		a.logger.Info("The Snapshot has not yet finished testing, holding its promotion request", "environment", environmentName)
+		return controller.ContinueProcessing()
+	}
+
+	canSnapshotBePromoted, notPromotedReasons := gitops.CanSnapshotBePromoted(a.snapshot)
+	if !gitops.HaveAppStudioTestsFinished(a.snapshot) {
+		canSnapshotBePromoted, notPromotedReasons = false, []string{"the Snapshot is invalid"}
+	}
+	if !canSnapshotBePromoted {
+		return a.rejectPromotionRequest(environmentName, "the Snapshot can't be promoted: "+strings.Join(notPromotedReasons, ", "))
+	}
+
+	environment, err := a.loader.GetEnvironment(a.context, a.client, environmentName, a.snapshot.Namespace)
+	if err != nil {
+		if clienterrors.IsNotFound(err) {
+			return a.rejectPromotionRequest(environmentName, "the Environment doesn't exist")
+		}
+		a.logger.Error(err, "Failed to get the Environment the Snapshot was requested to be promoted to",
+			"environment", environmentName)
+		return controller.RequeueWithError(err)
+	}
+
+	binding, err := a.loader.GetSnapshotEnvironmentBinding(a.context, a.client, a.application, environment.Name)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the SnapshotEnvironmentBinding of the Environment",
+			"environment", environment.Name)
+		return controller.RequeueWithError(err)
+	}
+
+	if binding == nil {
+		binding = gitops.NewSnapshotEnvironmentBinding(a.snapshot, a.application, environment.Name)
+		err = ctrl.SetControllerReference(a.application, binding, a.client.Scheme())
+		if err != nil {
+			a.logger.Error(err, "Failed to set the owner reference of the SnapshotEnvironmentBinding")
+			return controller.RequeueWithError(err)
+		}
+		err = a.client.Create(a.context, binding)
+		if err != nil {
+			a.logger.Error(err, "Failed to create the SnapshotEnvironmentBinding", "environment", environment.Name)
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Created the SnapshotEnvironmentBinding promoting the Snapshot", binding, h.LogActionAdd,
+			"snapshot.Name", a.snapshot.Name)
+	} else {
+		patch := client.MergeFrom(binding.DeepCopy())
+		gitops.SetSnapshotEnvironmentBindingSnapshot(binding, a.snapshot)
+		err = a.client.Patch(a.context, binding, patch)
+		if err != nil {
+			a.logger.Error(err, "Failed to update the SnapshotEnvironmentBinding", "binding.Name", binding.Name)
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Updated the SnapshotEnvironmentBinding promoting the Snapshot", binding, h.LogActionUpdate,
+			"snapshot.Name", a.snapshot.Name)
+	}
+
+	a.recorder.Eventf(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotPromotedEventReason),
+		"The Snapshot was promoted to the Environment %s through the SnapshotEnvironmentBinding %s", environment.Name, binding.Name)
+
+	err = gitops.RemoveSnapshotPromoteToAnnotation(a.context, a.client, a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to remove the promotion request annotation from the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// rejectPromotionRequest records the reason why the Snapshot can't be promoted to the Environment as an event
+// and removes the promotion request annotation, so that it's not processed again.
+func (a *Adapter) rejectPromotionRequest(environmentName, reason string) (controller.OperationResult, error) {
+	a.logger.Info("Rejecting the promotion request of the Snapshot", "environment", environmentName, "reason", reason)
+	a.recorder.Eventf(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotPromotionFailedEventReason),
+		"The Snapshot wasn't promoted to the Environment %s, %s", environmentName, reason)
+
+	err := gitops.RemoveSnapshotPromoteToAnnotation(a.context, a.client, a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to remove the promotion request annotation from the Snapshot")
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
 // createMissingReleasesForReleasePlans checks if there's existing Releases for a given list of ReleasePlans and creates
 // new ones if they are missing. In case the Releases can't be created, an error will be returned.
 func (a *Adapter) createMissingReleasesForReleasePlans(application *applicationapiv1alpha1.Application, releasePlans *[]releasev1alpha1.ReleasePlan, snapshot *applicationapiv1alpha1.Snapshot) error {
@@ -442,6 +1267,7 @@ func (a *Adapter) createMissingReleasesForReleasePlans(application *applicationa
 	}
 
 	firstRelease := true
+	firstCreatedRelease := true
 
 	for _, releasePlan := range *releasePlans {
 		releasePlan := releasePlan // G601
@@ -479,6 +1305,15 @@ func (a *Adapter) createMissingReleasesForReleasePlans(application *applicationa
 				return err
 			}
 			a.logger.Info("Marked Release status automated", "release.Name", newRelease.Name)
+
+			// Register the lead time from the build completion only once per snapshot
+			if firstCreatedRelease {
+				buildFinishTime, ok := gitops.GetBuildPipelineRunFinishTime(snapshot)
+				if ok {
+					go metrics.RegisterBuildToReleaseLeadTime(buildFinishTime)
+				}
+				firstCreatedRelease = false
+			}
 		}
 		// Register the first release time for metrics calculation
 		if firstRelease {
@@ -549,15 +1384,48 @@ func (a *Adapter) createIntegrationPipelineRun(application *applicationapiv1alph
 	a.logger.Info("Creating new pipelinerun for integrationTestscenario",
 		"integrationTestScenario.Name", integrationTestScenario.Name)
 
-	pipelineRunBuilder := tekton.NewIntegrationPipelineRun(snapshot.Name, application.Namespace, *integrationTestScenario).
-		WithSnapshot(snapshot).
+	// the scenarios targeting an architecture test the images of the multi-arch components for that architecture
+	testedSnapshot := snapshot
+	if integrationTestScenario.Spec.Architecture != "" {
+		var err error
+		testedSnapshot, err = gitops.NewSnapshotForArchitecture(snapshot, integrationTestScenario.Spec.Architecture)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the %s images of snapshot %s: %w", integrationTestScenario.Spec.Architecture, snapshot.Name, err)
+		}
+	}
+
+	pipelineRunBuilder := tekton.NewIntegrationPipelineRun(snapshot.Name, application.Namespace, *integrationTestScenario)
+	if integrationTestScenario.Spec.SnapshotWorkspace != "" {
+		configMap, err := a.createSnapshotConfigMap(testedSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		pipelineRunBuilder.WithSnapshotInWorkspace(testedSnapshot, integrationTestScenario.Spec.SnapshotWorkspace, configMap.Name)
+	} else {
+		pipelineRunBuilder.WithSnapshot(testedSnapshot)
+	}
+
+	pipelineRunBuilder.
 		WithIntegrationLabels(integrationTestScenario).
 		WithIntegrationAnnotations(integrationTestScenario).
 		WithApplication(a.application).
+		WithServiceAccount(integrationTestScenario).
+		WithWorkspaces(integrationTestScenario.Spec.Workspaces).
+		WithPodTemplate(integrationTestScenario.Spec.PodTemplate).
+		WithSecrets(integrationTestScenario.Spec.Secrets).
+		WithTaskRunSpecs(integrationTestScenario.Spec.TaskRunSpecs).
 		WithExtraParams(integrationTestScenario.Spec.Params).
 		WithFinalizer(h.IntegrationPipelineRunFinalizer).
 		WithDefaultIntegrationTimeouts(a.logger.Logger)
 
+	policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the IntegrationPolicy of application %s: %w", a.application.Name, err)
+	}
+	if policy != nil {
+		pipelineRunBuilder.WithDefaultMaxAllowedFailures(policy.Spec.MaxAllowedFailures)
+	}
+
 	if shouldUpdateIntegrationTestGitResolver(integrationTestScenario, snapshot) {
 		pipelineRunBuilder.WithUpdatedTestsGitResolver(getGitResolverUpdateMap(snapshot))
 	}
@@ -571,7 +1439,7 @@ func (a *Adapter) createIntegrationPipelineRun(application *applicationapiv1alph
 	_ = metadata.CopyLabelsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.BuildPipelineRunPrefix)
 	_ = metadata.CopyAnnotationsByPrefix(&snapshot.ObjectMeta, &pipelineRun.ObjectMeta, gitops.BuildPipelineRunPrefix)
 
-	err := ctrl.SetControllerReference(snapshot, pipelineRun, a.client.Scheme())
+	err = ctrl.SetControllerReference(snapshot, pipelineRun, a.client.Scheme())
 	if err != nil {
 		return nil, fmt.Errorf("failed to set snapshot %s as ControllerReference of pipelineRun: %w", snapshot.Name, err)
 	}
@@ -596,6 +1464,26 @@ func (a *Adapter) createIntegrationPipelineRun(application *applicationapiv1alph
 	return pipelineRun, nil
 }
 
+// createSnapshotConfigMap creates the ConfigMap delivering the given Snapshot to the integration PipelineRuns which
+// get it in a workspace. The ConfigMap is owned by the Snapshot being tested so that it's removed along with it.
+func (a *Adapter) createSnapshotConfigMap(snapshot *applicationapiv1alpha1.Snapshot) (*corev1.ConfigMap, error) {
+	configMap, err := tekton.NewSnapshotConfigMap(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctrl.SetControllerReference(snapshot, configMap, a.client.Scheme())
+	if err != nil {
+		return nil, fmt.Errorf("failed to set snapshot %s as ControllerReference of the snapshot configMap: %w", snapshot.Name, err)
+	}
+	err = a.client.Create(a.context, configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the configMap delivering snapshot %s: %w", snapshot.Name, err)
+	}
+
+	return configMap, nil
+}
+
 // RequeueIfYoungerThanThreshold checks if the adapter' snapshot is younger than the threshold defined
 // in the function.  If it is, the function returns an operation result instructing the reconciler
 // to requeue the object and the error message passed to the function.  If not, the function returns
@@ -632,9 +1520,26 @@ func (a *Adapter) HandlePipelineCreationError(err error, integrationTestScenario
 }
 
 func (a *Adapter) updateComponentContainerImage(ctx context.Context, c client.Client, component *applicationapiv1alpha1.Component, snapshotComponent *applicationapiv1alpha1.SnapshotComponent) error {
+	architectureDigests, err := gitops.GetSnapshotArchitectureDigests(a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the architecture digests of the Snapshot")
+		return err
+	}
+	sboms, err := gitops.GetSnapshotSBOMs(a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the SBOMs of the Snapshot")
+		return err
+	}
+
 	patch := client.MergeFrom(component.DeepCopy())
 	component.Spec.ContainerImage = snapshotComponent.ContainerImage
-	err := a.client.Patch(a.context, component, patch)
+	if err = gitops.SetComponentArchitectureDigests(component, architectureDigests[component.Name]); err != nil {
+		return err
+	}
+	if err = gitops.SetComponentSBOM(component, sboms[component.Name]); err != nil {
+		return err
+	}
+	err = a.client.Patch(a.context, component, patch)
 	if err != nil {
 		a.logger.Error(err, "Failed to update .Spec.ContainerImage of Global Candidate for the Component",
 			"component.Name", component.Name)
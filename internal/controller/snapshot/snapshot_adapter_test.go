@@ -19,7 +19,11 @@ package snapshot
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"time"
 
@@ -31,20 +35,30 @@ import (
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/ratelimit"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
 	"github.com/konflux-ci/integration-service/tekton"
 	toolkit "github.com/konflux-ci/operator-toolkit/loader"
+	"github.com/konflux-ci/operator-toolkit/metadata"
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	releasemetadata "github.com/konflux-ci/release-service/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/status"
+	"go.uber.org/mock/gomock"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -316,12 +330,13 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		var buf bytes.Buffer
 
 		It("can create a new Adapter instance", func() {
-			Expect(reflect.TypeOf(NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient))).To(Equal(reflect.TypeOf(&Adapter{})))
+			Expect(reflect.TypeOf(NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}))).To(Equal(reflect.TypeOf(&Adapter{})))
 		})
 
 		It("ensures the integrationTestPipelines are created", func() {
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			recorder := record.NewFakeRecorder(100)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, recorder)
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -368,6 +383,8 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			detail, ok := statuses.GetScenarioStatus(integrationTestScenario.Name)
 			Expect(ok).To(BeTrue())
 			Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusInProgress))
+			Expect(recorder.Events).To(Receive(ContainSubstring(fmt.Sprintf("%s %s Started integration test for scenario %s",
+				corev1.EventTypeNormal, reasons.SnapshotTestStartedEventReason, integrationTestScenario.Name))))
 
 			integrationPipelineRuns := []tektonv1.PipelineRun{}
 			Eventually(func() error {
@@ -394,6 +411,147 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("queues the integrationTestPipelines when the namespace reached its maximum of running pipelineRuns", func() {
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(100))
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+				{
+					ContextKey: loader.GetNamespaceContextKey,
+					Resource: &corev1.Namespace{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "default",
+							Annotations: map[string]string{tekton.MaxRunningPipelineRunsAnnotation: "1"},
+						},
+					},
+				},
+				{
+					ContextKey: loader.RunningIntegrationPipelineRunsContextKey,
+					Resource:   []tektonv1.PipelineRun{{ObjectMeta: metav1.ObjectMeta{Name: "running-pipelinerun"}}},
+				},
+			})
+
+			result, err := adapter.EnsureIntegrationPipelineRunsExist()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(QueuedPipelineRunsRequeueDelay))
+			Expect(buf.String()).Should(ContainSubstring("The namespace reached the maximum number of running integration pipelineRuns"))
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			detail, ok := statuses.GetScenarioStatus(integrationTestScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusPending))
+			Expect(detail.Details).To(Equal("Queued until one of the 1 running integration pipelineRuns of the namespace finishes"))
+			Expect(detail.TestPipelineRunName).To(BeEmpty())
+			Expect(buf.String()).ShouldNot(ContainSubstring("Creating new pipelinerun for integrationTestscenario"))
+		})
+
+		It("queues the integrationTestPipelines when their creation is rate limited", func() {
+			ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(0.001, 1, 10*time.Millisecond))
+			defer ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(0, 0, ratelimit.DefaultMaxWait))
+			Expect(ratelimit.WaitForPipelineRunCreation(ctx)).To(Succeed())
+
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(100))
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+			})
+
+			result, err := adapter.EnsureIntegrationPipelineRunsExist()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(QueuedPipelineRunsRequeueDelay))
+			Expect(buf.String()).Should(ContainSubstring("The creation of the pipelineRun is rate limited"))
+			Expect(buf.String()).ShouldNot(ContainSubstring("Creating new pipelinerun for integrationTestscenario"))
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			detail, ok := statuses.GetScenarioStatus(integrationTestScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusPending))
+		})
+
+		It("runs only the required integrationTestPipelines for the Snapshots created after a component removal", func() {
+			ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(0.001, 1, 10*time.Millisecond))
+			defer ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(0, 0, ratelimit.DefaultMaxWait))
+			Expect(ratelimit.WaitForPipelineRunCreation(ctx)).To(Succeed())
+
+			patch := client.MergeFrom(hasSnapshot.DeepCopy())
+			Expect(metadata.SetAnnotation(hasSnapshot, gitops.SnapshotRemovedComponentAnnotation, "removed-component")).To(Succeed())
+			Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).To(Succeed())
+			defer func() {
+				patch := client.MergeFrom(hasSnapshot.DeepCopy())
+				delete(hasSnapshot.Annotations, gitops.SnapshotRemovedComponentAnnotation)
+				Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).To(Succeed())
+			}()
+
+			optionalScenario := integrationTestScenario.DeepCopy()
+			optionalScenario.Name = "example-optional"
+			optionalScenario.Labels = map[string]string{helpers.OptionalScenarioLabel: "true"}
+
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(100))
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario, *optionalScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+			})
+
+			result, err := adapter.EnsureIntegrationPipelineRunsExist()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := statuses.GetScenarioStatus(integrationTestScenario.Name)
+			Expect(ok).To(BeTrue())
+			_, ok = statuses.GetScenarioStatus(optionalScenario.Name)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("ensures the architecture digests and SBOM of the promoted image are recorded in the Component", func() {
+			promotedSnapshot := hasSnapshot.DeepCopy()
+			Expect(gitops.SetSnapshotArchitectureDigests(promotedSnapshot, map[string]map[string]string{
+				hasComp.Name: {"arm64": sampleDigest},
+			})).To(Succeed())
+			Expect(gitops.SetSnapshotSBOMs(promotedSnapshot, map[string]string{hasComp.Name: sample_image + "@" + sampleDigest})).To(Succeed())
+			adapter.snapshot = promotedSnapshot
+
+			component := hasComp.DeepCopy()
+			Expect(adapter.updateComponentContainerImage(ctx, k8sClient, component, &promotedSnapshot.Spec.Components[0])).To(Succeed())
+			Expect(gitops.GetComponentArchitectureDigests(component)).To(Equal(map[string]string{"arm64": sampleDigest}))
+			Expect(component.GetAnnotations()).To(HaveKeyWithValue(gitops.ComponentSBOMAnnotation, sample_image+"@"+sampleDigest))
+
+			// the annotations are removed when the promoted image is neither multi-arch nor has a SBOM
+			adapter.snapshot = hasSnapshot
+			Expect(adapter.updateComponentContainerImage(ctx, k8sClient, component, &hasSnapshot.Spec.Components[0])).To(Succeed())
+			Expect(component.GetAnnotations()).NotTo(HaveKey(gitops.ComponentArchitectureDigestsAnnotation))
+			Expect(component.GetAnnotations()).NotTo(HaveKey(gitops.ComponentSBOMAnnotation))
+		})
+
 		It("ensures global Component Image will not be updated in the PR context", func() {
 			adapter.snapshot = hasSnapshotPR
 
@@ -406,6 +564,71 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(hasComp.Status.LastBuiltCommit).To(Equal(""))
 		})
 
+		It("ensures the new Component is added to the global candidate list only once its Snapshot passed", func() {
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			recorder := record.NewFakeRecorder(10)
+
+			passedSnapshot := hasSnapshot.DeepCopy()
+			passedSnapshot.Name = "snapshot-previously-passed"
+			meta.SetStatusCondition(&passedSnapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.AppStudioTestSucceededCondition,
+				Status: metav1.ConditionTrue,
+				Reason: reasons.AppStudioTestSucceededConditionSatisfied,
+			})
+			newComponent := hasComp.DeepCopy()
+			newComponent.Spec.ContainerImage = ""
+
+			onboardingSnapshot := hasSnapshot.DeepCopy()
+			onboardingSnapshot.Status.Conditions = []metav1.Condition{}
+			newAdapter := func() *Adapter {
+				a := NewAdapter(ctx, onboardingSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, recorder)
+				a.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+					{
+						ContextKey: loader.ComponentContextKey,
+						Resource:   newComponent,
+					},
+					{
+						ContextKey: loader.AllSnapshotsContextKey,
+						Resource:   []applicationapiv1alpha1.Snapshot{*passedSnapshot},
+					},
+				})
+				return a
+			}
+
+			result, err := newAdapter().EnsureGlobalCandidateImageUpdated()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(buf.String()).Should(ContainSubstring("The new component is added to the global candidate list once the Snapshot passes its integration tests"))
+			Expect(buf.String()).ShouldNot(ContainSubstring("Updated .Spec.ContainerImage of Global Candidate for the Component"))
+			Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(onboardingSnapshot)).To(BeFalse())
+
+			meta.SetStatusCondition(&onboardingSnapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.AppStudioTestSucceededCondition,
+				Status: metav1.ConditionFalse,
+				Reason: reasons.AppStudioTestSucceededConditionFailed,
+			})
+			result, err = newAdapter().EnsureGlobalCandidateImageUpdated()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(buf.String()).ShouldNot(ContainSubstring("Updated .Spec.ContainerImage of Global Candidate for the Component"))
+			Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(onboardingSnapshot)).To(BeFalse())
+			Expect(recorder.Events).To(Receive(ContainSubstring(string(reasons.SnapshotOnboardingFailedEventReason))))
+			Expect(onboardingSnapshot.GetAnnotations()).To(HaveKeyWithValue(gitops.SnapshotOnboardingFailedAnnotation, newComponent.Name))
+
+			// the failed onboarding is only reported once
+			meta.SetStatusCondition(&onboardingSnapshot.Status.Conditions, metav1.Condition{
+				Type:   gitops.AppStudioTestSucceededCondition,
+				Status: metav1.ConditionFalse,
+				Reason: reasons.AppStudioTestSucceededConditionFailed,
+			})
+			result, err = newAdapter().EnsureGlobalCandidateImageUpdated()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(gitops.IsSnapshotMarkedAsAddedToGlobalCandidateList(onboardingSnapshot)).To(BeFalse())
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
 		It("ensures global Component Image updated when AppStudio Tests failed", func() {
 			var buf bytes.Buffer
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
@@ -500,7 +723,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(err).To(Succeed())
 			Expect(gitops.HaveAppStudioTestsFinished(hasSnapshot)).To(BeTrue())
 			Expect(gitops.HaveAppStudioTestsSucceeded(hasSnapshot)).To(BeTrue())
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
@@ -547,6 +770,104 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
 		})
 
+		It("ensures the Snapshot isn't auto-released when the IntegrationPolicy of the application disables it", func() {
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			err := gitops.MarkSnapshotIntegrationStatusAsFinished(ctx, k8sClient, hasSnapshot, "Snapshot integration status condition is finished since all testing pipelines completed")
+			Expect(err).ToNot(HaveOccurred())
+			err = gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "test passed")
+			Expect(err).To(Succeed())
+			passedSnapshot := hasSnapshot.DeepCopy()
+			meta.RemoveStatusCondition(&passedSnapshot.Status.Conditions, gitops.SnapshotAutoReleasedCondition)
+
+			autoRelease := false
+			policyAdapter := NewAdapter(ctx, passedSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			policyAdapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.IntegrationPolicyContextKey,
+					Resource: &v1beta2.IntegrationPolicy{
+						ObjectMeta: metav1.ObjectMeta{Name: "integrationpolicy-sample", Namespace: "default"},
+						Spec: v1beta2.IntegrationPolicySpec{
+							Application: hasApp.Name,
+							AutoRelease: &autoRelease,
+						},
+					},
+				},
+			})
+
+			result, err := policyAdapter.EnsureAllReleasesExist()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(gitops.IsSnapshotMarkedAsAutoReleased(passedSnapshot)).To(BeFalse())
+			expectedLogEntry := "Auto-release is disabled by the IntegrationPolicy of the application, skipping auto-release."
+			Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+		})
+
+		It("ensures the Snapshot passed outside of the release windows is held until the next window opens", func() {
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			err := gitops.MarkSnapshotIntegrationStatusAsFinished(ctx, k8sClient, hasSnapshot, "Snapshot integration status condition is finished since all testing pipelines completed")
+			Expect(err).ToNot(HaveOccurred())
+			err = gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "test passed")
+			Expect(err).To(Succeed())
+			passedSnapshot := hasSnapshot.DeepCopy()
+			meta.RemoveStatusCondition(&passedSnapshot.Status.Conditions, gitops.SnapshotAutoReleasedCondition)
+
+			// the window opens half a day from now
+			now := time.Now().UTC()
+			policy := &v1beta2.IntegrationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "integrationpolicy-sample", Namespace: "default"},
+				Spec: v1beta2.IntegrationPolicySpec{
+					Application: hasApp.Name,
+					ReleaseWindows: []v1beta2.ReleaseWindow{
+						{
+							Schedule: fmt.Sprintf("%d %d * * *", now.Minute(), (now.Hour()+12)%24),
+							Duration: metav1.Duration{Duration: time.Hour},
+						},
+					},
+				},
+			}
+			policyAdapter := NewAdapter(ctx, passedSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			policyAdapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.IntegrationPolicyContextKey,
+					Resource:   policy,
+				},
+				{
+					ContextKey: loader.AutoReleasePlansContextKey,
+					Resource:   []releasev1alpha1.ReleasePlan{},
+				},
+				{
+					ContextKey: loader.ReleaseContextKey,
+					Resource:   &releasev1alpha1.Release{},
+				},
+			})
+
+			result, err := policyAdapter.EnsureAllReleasesExist()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(result.RequeueRequest).To(BeFalse())
+			Expect(gitops.IsSnapshotMarkedAsAutoReleased(passedSnapshot)).To(BeFalse())
+			Expect(gitops.IsSnapshotPendingReleaseWindow(passedSnapshot)).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("The Snapshot passed outside of the release windows of the IntegrationPolicy, holding auto-release."))
+
+			result, err = policyAdapter.EnsureReleaseWindowAwaited()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(BeNumerically(">", 11*time.Hour))
+			Expect(result.RequeueDelay).To(BeNumerically("<=", 12*time.Hour))
+
+			// the Snapshot is auto-released once the window is open
+			policy.Spec.ReleaseWindows[0].Schedule = "* * * * *"
+			result, err = policyAdapter.EnsureAllReleasesExist()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(gitops.IsSnapshotMarkedAsAutoReleased(passedSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotPendingReleaseWindow(passedSnapshot)).To(BeFalse())
+
+			result, err = policyAdapter.EnsureReleaseWindowAwaited()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeFalse())
+		})
+
 		It("ensures Snapshot labels/annotations prefixed with 'appstudio.openshift.io' are propagated to the release", func() {
 			releasePlans := []releasev1alpha1.ReleasePlan{*testReleasePlan}
 			releaseList := &releasev1alpha1.ReleaseList{}
@@ -585,7 +906,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(gitops.HaveAppStudioTestsSucceeded(hasSnapshot)).To(BeFalse())
 			Expect(gitops.IsSnapshotValid(hasSnapshot)).To(BeFalse())
 
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			Eventually(func() bool {
 				result, err := adapter.EnsureAllReleasesExist()
 				return !result.CancelRequest && err == nil
@@ -615,6 +936,214 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(label).To(Equal("enterprise-contract"))
 		})
 
+		It("ensures the Integration test PLR is owned by the Snapshot so that it's garbage collected with it", func() {
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(metav1.IsControlledBy(pipelineRun, hasSnapshot)).To(BeTrue())
+
+			ownerReference := metav1.GetControllerOf(pipelineRun)
+			Expect(ownerReference.Kind).To(Equal("Snapshot"))
+			Expect(ownerReference.Name).To(Equal(hasSnapshot.Name))
+			Expect(ownerReference.BlockOwnerDeletion).To(HaveValue(BeTrue()))
+		})
+
+		It("ensures the Integration test PLR of a scenario targeting an architecture tests the images of that architecture", func() {
+			armSnapshot := hasSnapshot.DeepCopy()
+			armSnapshot.Spec.Components[0].ContainerImage = sample_image + "@" + sampleDigest
+			Expect(gitops.SetSnapshotArchitectureDigests(armSnapshot, map[string]map[string]string{
+				"component-sample": {"arm64": "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+			})).To(Succeed())
+			armScenario := integrationTestScenario.DeepCopy()
+			armScenario.Spec.Architecture = "arm64"
+
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, armScenario, armSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.GetLabels()).To(HaveKeyWithValue(tekton.ArchitectureLabel, "arm64"))
+			Expect(pipelineRun.Spec.Params).To(ContainElement(And(
+				HaveField("Name", "SNAPSHOT"),
+				HaveField("Value.StringVal", ContainSubstring(sample_image+"@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")),
+			)))
+		})
+
+		It("ensures the Integration test PLR tolerates the failures allowed by the IntegrationPolicy of the application", func() {
+			maxAllowedFailures := 2
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.IntegrationPolicyContextKey,
+					Resource: &v1beta2.IntegrationPolicy{
+						ObjectMeta: metav1.ObjectMeta{Name: "integrationpolicy-sample", Namespace: "default"},
+						Spec: v1beta2.IntegrationPolicySpec{
+							Application:        hasApp.Name,
+							MaxAllowedFailures: &maxAllowedFailures,
+						},
+					},
+				},
+			})
+			defer func() {
+				adapter.context = ctx
+			}()
+
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.GetAnnotations()).To(HaveKeyWithValue(tekton.MaxAllowedFailuresAnnotation, "2"))
+
+			// the threshold of the scenario takes precedence over the one of the policy
+			scenario := integrationTestScenario.DeepCopy()
+			scenarioMaxAllowedFailures := 0
+			scenario.Spec.MaxAllowedFailures = &scenarioMaxAllowedFailures
+			pipelineRun, err = adapter.createIntegrationPipelineRun(hasApp, scenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.GetAnnotations()).To(HaveKeyWithValue(tekton.MaxAllowedFailuresAnnotation, "0"))
+		})
+
+		It("ensures the Integration test PLR runs as the ServiceAccount of its scenario", func() {
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Spec.TaskRunTemplate.ServiceAccountName).To(Equal(tekton.DefaultIntegrationPipelineServiceAccount))
+
+			scenario := integrationTestScenario.DeepCopy()
+			scenario.Spec.ServiceAccountName = "integration-tests"
+			pipelineRun, err = adapter.createIntegrationPipelineRun(hasApp, scenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Spec.TaskRunTemplate.ServiceAccountName).To(Equal("integration-tests"))
+		})
+
+		It("ensures the workspaces of the scenario are bound to the Integration test PLR", func() {
+			scenario := integrationTestScenario.DeepCopy()
+			scenario.Spec.Workspaces = []v1beta2.PipelineWorkspace{
+				{Name: "shared", VolumeClaimTemplate: &v1beta2.WorkspaceVolumeClaimTemplate{Storage: resource.MustParse("1Gi")}},
+			}
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, scenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Spec.Workspaces).To(HaveLen(1))
+			Expect(pipelineRun.Spec.Workspaces[0].Name).To(Equal("shared"))
+			Expect(pipelineRun.Spec.Workspaces[0].VolumeClaimTemplate).NotTo(BeNil())
+		})
+
+		It("ensures the pod template and task compute resources of the scenario are set on the Integration test PLR", func() {
+			scenario := integrationTestScenario.DeepCopy()
+			scenario.Spec.PodTemplate = &v1beta2.PipelinePodTemplate{NodeSelector: map[string]string{"node-role.kubernetes.io/e2e": ""}}
+			scenario.Spec.TaskRunSpecs = []v1beta2.PipelineTaskRunSpec{{
+				PipelineTaskName: "e2e-tests",
+				ComputeResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+				},
+			}}
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, scenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Spec.TaskRunTemplate.PodTemplate.NodeSelector).To(HaveKey("node-role.kubernetes.io/e2e"))
+			Expect(pipelineRun.Spec.TaskRunSpecs).To(HaveLen(1))
+			Expect(pipelineRun.Spec.TaskRunSpecs[0].PipelineTaskName).To(Equal("e2e-tests"))
+		})
+
+		It("ensures the Snapshot is delivered in a workspace to the Integration test PLR when the scenario requests it", func() {
+			scenario := integrationTestScenario.DeepCopy()
+			scenario.Spec.SnapshotWorkspace = "snapshot"
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, scenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Spec.Params).NotTo(ContainElement(HaveField("Name", "SNAPSHOT")))
+			Expect(pipelineRun.Spec.Params).To(ContainElement(HaveField("Name", tekton.SnapshotFileParamName)))
+			Expect(pipelineRun.Spec.Workspaces).To(HaveLen(1))
+			Expect(pipelineRun.Spec.Workspaces[0].Name).To(Equal("snapshot"))
+
+			configMap := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: pipelineRun.Spec.Workspaces[0].ConfigMap.Name, Namespace: hasSnapshot.Namespace}, configMap)
+			}, time.Second*10).Should(Succeed())
+			Expect(metav1.IsControlledBy(configMap, hasSnapshot)).To(BeTrue())
+			Expect(configMap.Data[tekton.SnapshotConfigMapKey]).To(ContainSubstring(hasSnapshot.Spec.Components[0].ContainerImage))
+		})
+
+		It("ensures the secrets of the scenario are bound to the Integration test PLR", func() {
+			scenario := integrationTestScenario.DeepCopy()
+			scenario.Spec.PodTemplate = &v1beta2.PipelinePodTemplate{NodeSelector: map[string]string{"node-role.kubernetes.io/e2e": ""}}
+			scenario.Spec.Secrets = []v1beta2.PipelineSecret{
+				{Name: "api-keys", Workspace: "api-keys", Env: []v1beta2.SecretEnvVar{{Name: "API_TOKEN", Key: "token"}}},
+			}
+			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, scenario, hasSnapshot)
+			Expect(err).To(BeNil())
+			Expect(pipelineRun.Spec.Workspaces).To(ContainElement(HaveField("Name", "api-keys")))
+			Expect(pipelineRun.Spec.TaskRunTemplate.PodTemplate.NodeSelector).To(HaveKey("node-role.kubernetes.io/e2e"))
+			Expect(pipelineRun.Spec.TaskRunTemplate.PodTemplate.Env).To(ContainElement(HaveField("Name", "API_TOKEN")))
+		})
+
+		It("ensures the Enterprise Contract gate is run alongside the scenarios when the application enabled it", func() {
+			application := hasApp.DeepCopy()
+			application.Annotations = map[string]string{gitops.EnterpriseContractPolicyAnnotation: "enterprise-contract-service/default"}
+			adapter = NewAdapter(ctx, hasSnapshot, application, logger, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(100))
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{},
+				},
+			})
+
+			result, err := adapter.EnsureIntegrationPipelineRunsExist()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsPassed(hasSnapshot)).To(BeFalse())
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			detail, ok := statuses.GetScenarioStatus(gitops.EnterpriseContractScenarioName)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusInProgress))
+
+			pipelineRun := &tektonv1.PipelineRun{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: detail.TestPipelineRunName, Namespace: hasSnapshot.Namespace}, pipelineRun)
+			}, time.Second*10).Should(Succeed())
+			Expect(pipelineRun.GetLabels()).To(HaveKeyWithValue(tekton.ScenarioNameLabel, gitops.EnterpriseContractScenarioName))
+			Expect(pipelineRun.Spec.Params).To(ContainElement(And(
+				HaveField("Name", gitops.EnterpriseContractPolicyParamName),
+				HaveField("Value.StringVal", "enterprise-contract-service/default"),
+			)))
+		})
+
+		It("ensures an integration PipelineRun is created for every matrix cell of a scenario", func() {
+			matrixScenario := integrationTestScenario.DeepCopy()
+			matrixScenario.Name = "example-matrix"
+			matrixScenario.Spec.Matrix = []v1beta2.MatrixParameter{{Name: "OCP_VERSION", Values: []string{"4.14", "4.15"}}}
+
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(100))
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*matrixScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*matrixScenario},
+				},
+			})
+
+			result, err := adapter.EnsureIntegrationPipelineRunsExist()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := statuses.GetScenarioStatus(matrixScenario.Name)
+			Expect(ok).To(BeFalse())
+			for _, version := range []string{"4.14", "4.15"} {
+				detail, ok := statuses.GetScenarioStatus(matrixScenario.Name + "-" + version)
+				Expect(ok).To(BeTrue())
+				Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusInProgress))
+
+				pipelineRun := &tektonv1.PipelineRun{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, types.NamespacedName{Name: detail.TestPipelineRunName, Namespace: hasSnapshot.Namespace}, pipelineRun)
+				}, time.Second*10).Should(Succeed())
+				Expect(pipelineRun.GetLabels()).To(HaveKeyWithValue(tekton.MatrixScenarioLabel, matrixScenario.Name))
+				Expect(pipelineRun.Spec.Params).To(ContainElement(And(
+					HaveField("Name", "OCP_VERSION"),
+					HaveField("Value.StringVal", version),
+				)))
+			}
+		})
+
 		It("ensures build labels/annotations non-prefixed with 'build.appstudio' are NOT propagated from snapshot to Integration test PLR", func() {
 			pipelineRun, err := adapter.createIntegrationPipelineRun(hasApp, integrationTestScenario, hasSnapshot)
 			Expect(err).To(BeNil())
@@ -674,7 +1203,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		It("Ensure error is logged when experiencing error when fetching ITS for application", func() {
 			var buf bytes.Buffer
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -708,7 +1237,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		It("Mark snapshot as pass when required ITS is not found", func() {
 			var buf bytes.Buffer
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -734,32 +1263,62 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(err).To(BeNil())
 		})
 
-		It("Skip integration test for passed Snapshot", func() {
-			err := gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "test pass")
-			Expect(err).To(Succeed())
-			Expect(gitops.HaveAppStudioTestsSucceeded(hasSnapshot)).To(BeTrue())
+		It("Skips the integration tests whose contexts don't apply to the event of the Snapshot", func() {
 			var buf bytes.Buffer
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			pullRequestScenario := integrationTestScenario.DeepCopy()
+			pullRequestScenario.Spec.Contexts = []v1beta2.TestContext{{Name: gitops.PullRequestContext}}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
 					Resource:   hasApp,
 				},
-				{
-					ContextKey: loader.ComponentContextKey,
-					Resource:   hasComp,
-				},
 				{
 					ContextKey: loader.SnapshotContextKey,
 					Resource:   hasSnapshot,
 				},
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*pullRequestScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*pullRequestScenario},
+				},
 			})
 			result, err := adapter.EnsureIntegrationPipelineRunsExist()
-			Expect(buf.String()).Should(ContainSubstring("The Snapshot has finished testing."))
-			Expect(result.CancelRequest).To(BeFalse())
-			Expect(result.RequeueRequest).To(BeFalse())
-			Expect(err == nil).To(BeTrue())
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("Found 0 IntegrationTestScenarios for application"))
+			Expect(buf.String()).Should(ContainSubstring("Snapshot marked as successful. No required IntegrationTestScenarios found, skipped testing"))
+		})
+
+		It("Skip integration test for passed Snapshot", func() {
+			err := gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "test pass")
+			Expect(err).To(Succeed())
+			Expect(gitops.HaveAppStudioTestsSucceeded(hasSnapshot)).To(BeTrue())
+			var buf bytes.Buffer
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.ApplicationContextKey,
+					Resource:   hasApp,
+				},
+				{
+					ContextKey: loader.ComponentContextKey,
+					Resource:   hasComp,
+				},
+				{
+					ContextKey: loader.SnapshotContextKey,
+					Resource:   hasSnapshot,
+				},
+			})
+			result, err := adapter.EnsureIntegrationPipelineRunsExist()
+			Expect(buf.String()).Should(ContainSubstring("The Snapshot has finished testing."))
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(result.RequeueRequest).To(BeFalse())
+			Expect(err == nil).To(BeTrue())
 		})
 	})
 
@@ -854,7 +1413,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			var buf bytes.Buffer
 
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-			adapter = NewAdapter(ctx, hasInvalidSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasInvalidSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
@@ -895,7 +1454,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			var buf bytes.Buffer
 
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 
 			helpers.SetScenarioIntegrationStatusAsInvalid(integrationTestScenarioForInvalidSnapshot, "invalid")
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
@@ -941,6 +1500,98 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(ok).To(BeTrue())
 			Expect(detail.Status).Should(Equal(intgteststat.IntegrationTestStatusTestInvalid))
 		})
+
+		It("Write the suspended status of the suspended scenario to snapshot annotation", func() {
+			var buf bytes.Buffer
+
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			suspendedScenario := integrationTestScenarioForInvalidSnapshot.DeepCopy()
+			suspendedScenario.Name = "suspended-scenario"
+			suspendedScenario.Spec.Suspended = true
+			suspendedAdapter := NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			suspendedAdapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.ApplicationContextKey,
+					Resource:   hasApp,
+				},
+				{
+					ContextKey: loader.SnapshotComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*suspendedScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{},
+				},
+			})
+			result, err := suspendedAdapter.EnsureIntegrationPipelineRunsExist()
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			expectedLogEntry := "IntegrationTestScenario is suspended, will not create pipelineRun for it"
+			Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).To(Succeed())
+			detail, ok := statuses.GetScenarioStatus(suspendedScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).Should(Equal(intgteststat.IntegrationTestStatusSuspended))
+		})
+
+		It("Marks the tests of the observe-only scenario and registers its observe-only run", func() {
+			observeOnlyScenario := integrationTestScenarioForInvalidSnapshot.DeepCopy()
+			observeOnlyScenario.ObjectMeta = metav1.ObjectMeta{
+				Name:      "observe-only-scenario",
+				Namespace: "default",
+			}
+			observeOnlyScenario.Spec.ObserveOnlyRuns = 1
+			Expect(k8sClient.Create(ctx, observeOnlyScenario)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, observeOnlyScenario)).Should(Succeed())
+			}()
+			helpers.SetScenarioIntegrationStatusAsValid(observeOnlyScenario, "valid")
+			Expect(k8sClient.Status().Update(ctx, observeOnlyScenario)).Should(Succeed())
+
+			observeOnlyAdapter := NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(10))
+			observeOnlyAdapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.ApplicationContextKey,
+					Resource:   hasApp,
+				},
+				{
+					ContextKey: loader.SnapshotComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+				{
+					ContextKey: loader.AllIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*observeOnlyScenario},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{},
+				},
+			})
+			result, err := observeOnlyAdapter.EnsureIntegrationPipelineRunsExist()
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).To(Succeed())
+			detail, ok := statuses.GetScenarioStatus(observeOnlyScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).Should(Equal(intgteststat.IntegrationTestStatusInProgress))
+			Expect(detail.ObserveOnly).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      observeOnlyScenario.Name,
+				Namespace: observeOnlyScenario.Namespace,
+			}, observeOnlyScenario)).To(Succeed())
+			Expect(observeOnlyScenario.Status.ObservedRuns).To(Equal(1))
+			Expect(helpers.IsScenarioObserveOnly(observeOnlyScenario)).To(BeFalse())
+		})
 	})
 
 	When("When EnsureAllReleasesExist experiences error", func() {
@@ -954,7 +1605,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		})
 
 		It("Cancel request when GetAutoReleasePlansForApplication returns an error", func() {
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			// Mock the context with error for AutoReleasePlansContextKey
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
@@ -971,7 +1622,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		})
 
 		It("Returns RequeueWithError if the snapshot is less than three hours old", func() {
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			testErr := fmt.Errorf("something went wrong with the release")
 
 			result, err := adapter.RequeueIfYoungerThanThreshold(testErr)
@@ -987,7 +1638,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			// and returns a time.Time.  Why?  Who knows.  We want the latter, so we add -3 hours here
 			hasSnapshot.CreationTimestamp = metav1.NewTime(time.Now().Add(-1 * SnapshotRetryTimeout))
 
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			testErr := fmt.Errorf("something went wrong with the release")
 
 			result, err := adapter.RequeueIfYoungerThanThreshold(testErr)
@@ -1011,7 +1662,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 				hasSnapshot.Labels[gitops.SnapshotIntegrationTestRun] = integrationTestScenario.Name
 
 				log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-				adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 					{
 						ContextKey: loader.ApplicationContextKey,
@@ -1065,6 +1716,71 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			})
 		})
 
+		When("manual re-run of a matrix cell is triggered", func() {
+			var matrixScenario *v1beta2.IntegrationTestScenario
+
+			BeforeEach(func() {
+				matrixScenario = integrationTestScenario.DeepCopy()
+				matrixScenario.Name = "example-matrix"
+				matrixScenario.Spec.Matrix = []v1beta2.MatrixParameter{{Name: "OCP_VERSION", Values: []string{"4.14", "4.15"}}}
+
+				hasSnapshot.Labels[gitops.SnapshotIntegrationTestRun] = "example-matrix-4.15"
+
+				adapter = NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+					{
+						ContextKey: loader.GetScenarioContextKey,
+						Err:        errors.NewNotFound(v1beta2.GroupVersion.WithResource("integrationtestscenarios").GroupResource(), "example-matrix-4.15"),
+					},
+					{
+						ContextKey: loader.AllIntegrationTestScenariosContextKey,
+						Resource:   []v1beta2.IntegrationTestScenario{*matrixScenario},
+					},
+				})
+			})
+
+			It("re-runs the integration test of the matrix cell", func() {
+				result, err := adapter.EnsureRerunPipelineRunsExist()
+				Expect(err).To(Succeed())
+				Expect(result.CancelRequest).To(BeFalse())
+
+				statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+				Expect(err).To(Succeed())
+				detail, ok := statuses.GetScenarioStatus("example-matrix-4.15")
+				Expect(ok).To(BeTrue())
+				Expect(detail.TestPipelineRunName).ToNot(BeEmpty())
+				_, ok = statuses.GetScenarioStatus("example-matrix-4.14")
+				Expect(ok).To(BeFalse())
+				Expect(hasSnapshot.GetLabels()).NotTo(HaveKey(gitops.SnapshotIntegrationTestRun))
+
+				pipelineRun := &tektonv1.PipelineRun{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, types.NamespacedName{Name: detail.TestPipelineRunName, Namespace: hasSnapshot.Namespace}, pipelineRun)
+				}, time.Second*10).Should(Succeed())
+				Expect(pipelineRun.GetLabels()).To(HaveKeyWithValue(tekton.ScenarioNameLabel, "example-matrix-4.15"))
+			})
+
+			It("doesn't re-run the matrix scenario itself", func() {
+				hasSnapshot.Labels[gitops.SnapshotIntegrationTestRun] = matrixScenario.Name
+				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+					{
+						ContextKey: loader.GetScenarioContextKey,
+						Resource:   matrixScenario,
+					},
+				})
+
+				result, err := adapter.EnsureRerunPipelineRunsExist()
+				Expect(err).To(Succeed())
+				Expect(result.CancelRequest).To(BeFalse())
+
+				statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+				Expect(err).To(Succeed())
+				_, ok := statuses.GetScenarioStatus(matrixScenario.Name)
+				Expect(ok).To(BeFalse())
+				Expect(hasSnapshot.GetLabels()).NotTo(HaveKey(gitops.SnapshotIntegrationTestRun))
+			})
+		})
+
 		When("test for scenario is alreday in-progress", func() {
 
 			const (
@@ -1089,7 +1805,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 				hasSnapshot.Labels[gitops.SnapshotIntegrationTestRun] = integrationTestScenario.Name
 
 				log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
-				adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+				adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 				adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 					{
 						ContextKey: loader.ApplicationContextKey,
@@ -1141,6 +1857,625 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		})
 	})
 
+	Describe("EnsureSnapshotRunsStarted", func() {
+		var (
+			buf         bytes.Buffer
+			snapshotRun *v1beta2.SnapshotRun
+		)
+
+		BeforeEach(func() {
+			snapshotRun = &v1beta2.SnapshotRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "snapshotrun-sample",
+					Namespace: "default",
+				},
+				Spec: v1beta2.SnapshotRunSpec{
+					Snapshot: hasSnapshot.Name,
+					Scenario: integrationTestScenario.Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, snapshotRun)).Should(Succeed())
+
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, snapshotRun)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		mockSnapshotRuns := func() {
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotRunsForSnapshotContextKey,
+					Resource:   []v1beta2.SnapshotRun{*snapshotRun},
+				},
+			})
+		}
+
+		It("creates the integration pipelineRun of the requested scenario and marks the SnapshotRun as running", func() {
+			mockSnapshotRuns()
+			result, err := adapter.EnsureSnapshotRunsStarted()
+			Expect(err).To(Succeed())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(result.RequeueRequest).To(BeFalse())
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: snapshotRun.Namespace, Name: snapshotRun.Name}, snapshotRun)).To(Succeed())
+				g.Expect(snapshotRun.HasStarted()).To(BeTrue())
+			}, time.Second*10).Should(Succeed())
+			Expect(snapshotRun.Status.Scenarios).To(HaveLen(1))
+			Expect(snapshotRun.Status.Scenarios[0].Name).To(Equal(integrationTestScenario.Name))
+			Expect(snapshotRun.Status.Scenarios[0].PipelineRun).NotTo(BeEmpty())
+			Expect(meta.FindStatusCondition(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition).Reason).
+				To(Equal(v1beta2.SnapshotRunRunningReason))
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).To(Succeed())
+			detail, ok := statuses.GetScenarioStatus(integrationTestScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusInProgress))
+			Expect(detail.TestPipelineRunName).To(Equal(snapshotRun.Status.Scenarios[0].PipelineRun))
+
+			// the started SnapshotRun isn't run again
+			pipelineRunName := snapshotRun.Status.Scenarios[0].PipelineRun
+			mockSnapshotRuns()
+			result, err = adapter.EnsureSnapshotRunsStarted()
+			Expect(err).To(Succeed())
+			Expect(result.CancelRequest).To(BeFalse())
+			statuses, err = gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).To(Succeed())
+			detail, ok = statuses.GetScenarioStatus(integrationTestScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.TestPipelineRunName).To(Equal(pipelineRunName))
+		})
+
+		It("marks the SnapshotRuns requesting an unknown scenario as invalid", func() {
+			snapshotRun.Spec.Scenario = "missing-scenario"
+			mockSnapshotRuns()
+			result, err := adapter.EnsureSnapshotRunsStarted()
+			Expect(err).To(Succeed())
+			Expect(result.CancelRequest).To(BeFalse())
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: snapshotRun.Namespace, Name: snapshotRun.Name}, snapshotRun)).To(Succeed())
+				g.Expect(snapshotRun.HasFinished()).To(BeTrue())
+			}, time.Second*10).Should(Succeed())
+			Expect(snapshotRun.HasStarted()).To(BeFalse())
+			condition := meta.FindStatusCondition(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+			Expect(condition.Reason).To(Equal(v1beta2.SnapshotRunInvalidReason))
+			Expect(condition.Message).To(Equal("the IntegrationTestScenario missing-scenario doesn't exist"))
+		})
+
+		It("requests the status of the Snapshot to be reported when the SnapshotRun reports its status", func() {
+			patch := client.MergeFrom(snapshotRun.DeepCopy())
+			snapshotRun.Spec.ReportStatus = true
+			Expect(k8sClient.Patch(ctx, snapshotRun, patch)).To(Succeed())
+			mockSnapshotRuns()
+			result, err := adapter.EnsureSnapshotRunsStarted()
+			Expect(err).To(Succeed())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(gitops.IsSnapshotStatusReportRequested(hasSnapshot)).To(BeTrue())
+
+			patch = client.MergeFrom(hasSnapshot.DeepCopy())
+			delete(hasSnapshot.Annotations, gitops.SnapshotStatusReportRequestedAnnotation)
+			Expect(k8sClient.Patch(ctx, hasSnapshot, patch)).To(Succeed())
+		})
+	})
+
+	Describe("reportIntegrationTestsStarted", func() {
+		var (
+			prSnapshot   *applicationapiv1alpha1.Snapshot
+			mockReporter *status.MockReporterInterface
+			mockStatus   *status.MockStatusInterface
+		)
+
+		BeforeEach(func() {
+			prSnapshot = hasSnapshot.DeepCopy()
+			prSnapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodePullRequestType
+
+			ctrl := gomock.NewController(GinkgoT())
+			mockReporter = status.NewMockReporterInterface(ctrl)
+			mockStatus = status.NewMockStatusInterface(ctrl)
+			mockReporter.EXPECT().GetReporterName().Return("mocked_reporter").AnyTimes()
+
+			adapter = NewAdapter(ctx, prSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(100))
+			adapter.status = mockStatus
+		})
+
+		It("reports the started integration tests of Pull Request snapshots to the git provider", func() {
+			mockStatus.EXPECT().GetReporter(prSnapshot).Return(mockReporter).Times(1)
+			mockStatus.EXPECT().ReportSnapshotStatus(gomock.Any(), mockReporter, prSnapshot).Times(1)
+			adapter.reportIntegrationTestsStarted()
+		})
+
+		It("doesn't fail when the started integration tests can't be reported", func() {
+			mockStatus.EXPECT().GetReporter(prSnapshot).Return(mockReporter).Times(1)
+			mockStatus.EXPECT().ReportSnapshotStatus(gomock.Any(), mockReporter, prSnapshot).Return(fmt.Errorf("failed")).Times(1)
+			adapter.reportIntegrationTestsStarted()
+		})
+
+		It("doesn't report the started integration tests of push snapshots unless it was requested", func() {
+			prSnapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodePushType
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Times(0)
+			adapter.reportIntegrationTestsStarted()
+
+			prSnapshot.Annotations[gitops.SnapshotStatusReportRequestedAnnotation] = "true"
+			mockStatus.EXPECT().GetReporter(prSnapshot).Return(nil).Times(1)
+			mockStatus.EXPECT().ReportSnapshotStatus(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			adapter.reportIntegrationTestsStarted()
+		})
+	})
+
+	Describe("EnsureLifecycleEventsNotified", func() {
+		var (
+			buf      bytes.Buffer
+			server   *httptest.Server
+			payloads []notification.Event
+			secret   *corev1.Secret
+		)
+
+		// createSecret creates the notification secret and waits for it to be visible to the cached client
+		createSecret := func() {
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			Eventually(func() map[string][]byte {
+				cached := &corev1.Secret{}
+				_ = k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, cached)
+				return cached.Data
+			}, time.Second*10).Should(Equal(secret.Data))
+		}
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+			payloads = []notification.Event{}
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(r.Header.Get(notification.WebhookSignatureHeader)).To(Equal("sha256=" + notification.Sign([]byte("hmac-key"), body)))
+				event := notification.Event{}
+				Expect(json.Unmarshal(body, &event)).To(Succeed())
+				payloads = append(payloads, event)
+			}))
+			// trust the certificate of the test server in the webhook sink
+			defaultTransport := http.DefaultTransport
+			http.DefaultTransport = server.Client().Transport
+			DeferCleanup(func() {
+				http.DefaultTransport = defaultTransport
+			})
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      notification.SecretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					notification.SecretWebhookURLsKey: []byte(server.URL),
+					notification.SecretWebhookHMACKey: []byte("hmac-key"),
+				},
+			}
+
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+		})
+
+		AfterEach(func() {
+			server.Close()
+			err := k8sClient.Delete(ctx, secret)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+				return errors.IsNotFound(err)
+			}, time.Second*10).Should(BeTrue())
+		})
+
+		It("doesn't notify when the namespace has no notification secret", func() {
+			result, err := adapter.EnsureLifecycleEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(payloads).To(BeEmpty())
+			Expect(hasSnapshot.Annotations).NotTo(HaveKey(notification.NotifiedEventsAnnotation))
+		})
+
+		It("skips the notification when the notification secret is misconfigured", func() {
+			secret.Data[notification.SecretWebhookURLsKey] = []byte("http://insecure.example.com")
+			createSecret()
+
+			result, err := adapter.EnsureLifecycleEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(payloads).To(BeEmpty())
+			Expect(buf.String()).Should(ContainSubstring("Failed to configure the notification sinks, skipping the notification"))
+		})
+
+		It("notifies the webhook once about the creation and the release of the snapshot", func() {
+			createSecret()
+
+			result, err := adapter.EnsureLifecycleEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(payloads).To(HaveLen(1))
+			Expect(payloads[0].Type).To(Equal(notification.EventSnapshotCreated))
+			Expect(payloads[0].Snapshot).To(Equal(hasSnapshot.Name))
+			Expect(payloads[0].Application).To(Equal(hasApp.Name))
+			Expect(payloads[0].Component).To(Equal("component-sample"))
+			Expect(notification.IsEventNotified(hasSnapshot, notification.EventSnapshotCreated)).To(BeTrue())
+
+			Expect(gitops.MarkSnapshotAsAutoReleased(ctx, k8sClient, hasSnapshot, "The Snapshot was auto-released")).To(Succeed())
+			result, err = adapter.EnsureLifecycleEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(payloads).To(HaveLen(2))
+			Expect(payloads[1].Type).To(Equal(notification.EventSnapshotReleased))
+			Expect(hasSnapshot.Annotations).To(HaveKeyWithValue(notification.NotifiedEventsAnnotation, "snapshot.created,snapshot.released"))
+
+			result, err = adapter.EnsureLifecycleEventsNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(payloads).To(HaveLen(2))
+		})
+
+		It("requeues when the webhook fails to receive the event", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			createSecret()
+
+			result, err := adapter.EnsureLifecycleEventsNotified()
+			Expect(err).To(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(notification.IsEventNotified(hasSnapshot, notification.EventSnapshotCreated)).To(BeFalse())
+		})
+	})
+
+	Describe("EnsureSnapshotOwnedByApplication", func() {
+		It("sets the application as the owner of the snapshots not controlled by any object", func() {
+			var buf bytes.Buffer
+			Expect(metav1.GetControllerOf(hasSnapshot)).To(BeNil())
+
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			result, err := adapter.EnsureSnapshotOwnedByApplication()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("Snapshot is now owned by its Application"))
+
+			Eventually(func() bool {
+				updatedSnapshot := &applicationapiv1alpha1.Snapshot{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: hasSnapshot.Name, Namespace: hasSnapshot.Namespace}, updatedSnapshot)
+				return err == nil && metav1.IsControlledBy(updatedSnapshot, hasApp)
+			}, time.Second*10).Should(BeTrue())
+		})
+
+		It("keeps the owner of the snapshots controlled by another object", func() {
+			var buf bytes.Buffer
+			snapshot := hasSnapshot.DeepCopy()
+			controlled := true
+			snapshot.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "appstudio.redhat.com/v1alpha1",
+				Kind:       "SnapshotRun",
+				Name:       "snapshotrun-sample",
+				UID:        "12345",
+				Controller: &controlled,
+			}}
+
+			adapter = NewAdapter(ctx, snapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			result, err := adapter.EnsureSnapshotOwnedByApplication()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(snapshot.OwnerReferences).To(HaveLen(1))
+			Expect(metav1.IsControlledBy(snapshot, hasApp)).To(BeFalse())
+		})
+	})
+
+	Describe("EnsureSnapshotDiffRecorded", func() {
+		It("records the components which changed relative to the previous snapshot of the application", func() {
+			var buf bytes.Buffer
+			previousSnapshot := hasSnapshot.DeepCopy()
+			previousSnapshot.Name = "snapshot-previous"
+			previousSnapshot.CreationTimestamp = metav1.NewTime(hasSnapshot.CreationTimestamp.Add(-time.Hour))
+			previousSnapshot.Spec.Components[0].ContainerImage = sample_image + "@" + sampleDigest
+
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{*previousSnapshot, *hasSnapshot},
+				},
+			})
+
+			result, err := adapter.EnsureSnapshotDiffRecorded()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("Snapshot diff was recorded"))
+
+			updatedSnapshot := &applicationapiv1alpha1.Snapshot{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: hasSnapshot.Name, Namespace: hasSnapshot.Namespace}, updatedSnapshot)).To(Succeed())
+			diff, err := gitops.GetSnapshotDiff(updatedSnapshot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff.PreviousSnapshot).To(Equal("snapshot-previous"))
+			Expect(diff.Added).To(BeEmpty())
+			Expect(diff.Removed).To(BeEmpty())
+			Expect(diff.Updated).To(ConsistOf(gitops.SnapshotDiffComponent{
+				Name:                   "component-sample",
+				ContainerImage:         sample_image,
+				PreviousContainerImage: sample_image + "@" + sampleDigest,
+			}))
+
+			// the recorded diff isn't computed again
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.AllSnapshotsContextKey,
+					Err:        fmt.Errorf("not found"),
+				},
+			})
+			result, err = adapter.EnsureSnapshotDiffRecorded()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+		})
+	})
+
+	Describe("EnsureImageVerificationRecorded", func() {
+		var buf bytes.Buffer
+
+		It("continues processing the snapshots whose image wasn't verified", func() {
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			result, err := adapter.EnsureImageVerificationRecorded()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.SnapshotImageVerifiedCondition)).To(BeNil())
+		})
+
+		It("sets the condition of the snapshots whose image was verified", func() {
+			Expect(gitops.SetSnapshotImageVerification(hasSnapshot, gitops.ImageVerification{Verified: true, Message: "signed"})).To(Succeed())
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			result, err := adapter.EnsureImageVerificationRecorded()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(hasSnapshot.Status.Conditions, gitops.SnapshotImageVerifiedCondition)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(hasSnapshot)).To(BeFalse())
+		})
+
+		It("marks the snapshots whose image failed verification as invalid and stops processing them", func() {
+			recorder := record.NewFakeRecorder(10)
+			Expect(gitops.SetSnapshotImageVerification(hasSnapshot, gitops.ImageVerification{Verified: false, Message: "not signed"})).To(Succeed())
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, recorder)
+			result, err := adapter.EnsureImageVerificationRecorded()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeTrue())
+			Expect(gitops.IsSnapshotImageVerificationFailed(hasSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(hasSnapshot)).To(BeTrue())
+			Expect(<-recorder.Events).To(ContainSubstring(string(reasons.SnapshotImageVerificationFailedEventReason)))
+
+			// the snapshot isn't processed further
+			result, err = adapter.EnsureImageVerificationRecorded()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeTrue())
+		})
+	})
+
+	Describe("EnsureOverrideSnapshotIsValid", func() {
+		var (
+			buf              bytes.Buffer
+			overrideSnapshot *applicationapiv1alpha1.Snapshot
+		)
+
+		BeforeEach(func() {
+			overrideSnapshot = &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "snapshot-user-override-",
+					Namespace:    "default",
+					Labels: map[string]string{
+						gitops.SnapshotTypeLabel: gitops.SnapshotOverrideType,
+					},
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Application: hasApp.Name,
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						{
+							Name:           hasComp.Name,
+							ContainerImage: sample_image + "@" + sampleDigest,
+						},
+					},
+				},
+			}
+		})
+
+		// createOverrideSnapshot creates the override snapshot and an adapter reconciling it
+		createOverrideSnapshot := func() {
+			Expect(k8sClient.Create(ctx, overrideSnapshot)).Should(Succeed())
+			DeferCleanup(func() {
+				err := k8sClient.Delete(ctx, overrideSnapshot)
+				Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			})
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			adapter = NewAdapter(ctx, overrideSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, record.NewFakeRecorder(10))
+		}
+
+		It("continues processing the snapshots which aren't override snapshots", func() {
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			result, err := adapter.EnsureOverrideSnapshotIsValid()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+		})
+
+		It("continues processing the override snapshots composed of the components of the application", func() {
+			createOverrideSnapshot()
+			result, err := adapter.EnsureOverrideSnapshotIsValid()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(overrideSnapshot)).To(BeFalse())
+		})
+
+		It("marks the override snapshots referencing unknown components as invalid", func() {
+			overrideSnapshot.Spec.Components = append(overrideSnapshot.Spec.Components, applicationapiv1alpha1.SnapshotComponent{
+				Name:           "nonexisting-component",
+				ContainerImage: sample_image + "@" + sampleDigest,
+			})
+			createOverrideSnapshot()
+
+			result, err := adapter.EnsureOverrideSnapshotIsValid()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(overrideSnapshot)).To(BeTrue())
+			condition := meta.FindStatusCondition(overrideSnapshot.Status.Conditions, gitops.AppStudioIntegrationStatusCondition)
+			Expect(condition.Message).To(Equal("The override Snapshot is invalid: the component nonexisting-component doesn't exist"))
+
+			// the invalid override snapshot isn't processed further
+			result, err = adapter.EnsureOverrideSnapshotIsValid()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeTrue())
+		})
+
+		It("marks the override snapshots referencing images without digest as invalid", func() {
+			overrideSnapshot.Spec.Components[0].ContainerImage = sample_image + ":latest"
+			createOverrideSnapshot()
+
+			result, err := adapter.EnsureOverrideSnapshotIsValid()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeTrue())
+			condition := meta.FindStatusCondition(overrideSnapshot.Status.Conditions, gitops.AppStudioIntegrationStatusCondition)
+			Expect(condition.Message).To(ContainSubstring("isn't referenced by a valid digest"))
+		})
+
+		It("marks the override snapshots without components as invalid", func() {
+			overrideSnapshot.Spec.Components = []applicationapiv1alpha1.SnapshotComponent{}
+			createOverrideSnapshot()
+
+			result, err := adapter.EnsureOverrideSnapshotIsValid()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.CancelRequest).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsInvalid(overrideSnapshot)).To(BeTrue())
+		})
+	})
+
+	Describe("EnsurePromotionRequestHandled", func() {
+		var (
+			buf              bytes.Buffer
+			recorder         *record.FakeRecorder
+			promotedSnapshot *applicationapiv1alpha1.Snapshot
+			stagingEnv       *applicationapiv1alpha1.Environment
+		)
+
+		BeforeAll(func() {
+			stagingEnv = &applicationapiv1alpha1.Environment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "staging",
+					Namespace: "default",
+				},
+				Spec: applicationapiv1alpha1.EnvironmentSpec{
+					DisplayName:        "staging",
+					DeploymentStrategy: applicationapiv1alpha1.DeploymentStrategy_AppStudioAutomated,
+				},
+			}
+			Expect(k8sClient.Create(ctx, stagingEnv)).Should(Succeed())
+		})
+
+		AfterAll(func() {
+			err := k8sClient.Delete(ctx, stagingEnv)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		BeforeEach(func() {
+			promotedSnapshot = &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "snapshot-promoted-",
+					Namespace:    "default",
+					Labels: map[string]string{
+						gitops.SnapshotTypeLabel:            gitops.SnapshotComponentType,
+						gitops.PipelineAsCodeEventTypeLabel: gitops.PipelineAsCodePushType,
+					},
+					Annotations: map[string]string{
+						gitops.SnapshotPromoteToAnnotation: stagingEnv.Name,
+					},
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Application: hasApp.Name,
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						{
+							Name:           hasComp.Name,
+							ContainerImage: sample_image + "@" + sampleDigest,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, promotedSnapshot)).Should(Succeed())
+			DeferCleanup(func() {
+				err := k8sClient.Delete(ctx, promotedSnapshot)
+				Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+			})
+			recorder = record.NewFakeRecorder(10)
+			adapter = NewAdapter(ctx, promotedSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, recorder)
+		})
+
+		// getBinding returns the SnapshotEnvironmentBinding of the application for the staging environment
+		getBinding := func(g Gomega) *applicationapiv1alpha1.SnapshotEnvironmentBinding {
+			binding, err := loader.NewLoader().GetSnapshotEnvironmentBinding(ctx, k8sClient, hasApp, stagingEnv.Name)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(binding).NotTo(BeNil())
+			return binding
+		}
+
+		It("holds the promotion request of the snapshots which haven't finished testing", func() {
+			result, err := adapter.EnsurePromotionRequestHandled()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(metadata.HasAnnotation(promotedSnapshot, gitops.SnapshotPromoteToAnnotation)).To(BeTrue())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("rejects the promotion request of the snapshots which failed testing", func() {
+			Expect(gitops.MarkSnapshotAsFailed(ctx, k8sClient, promotedSnapshot, "failed")).To(Succeed())
+
+			result, err := adapter.EnsurePromotionRequestHandled()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(metadata.HasAnnotation(promotedSnapshot, gitops.SnapshotPromoteToAnnotation)).To(BeFalse())
+			Expect(<-recorder.Events).To(ContainSubstring(string(reasons.SnapshotPromotionFailedEventReason)))
+		})
+
+		It("rejects the promotion request to environments which don't exist", func() {
+			Expect(gitops.MarkSnapshotAsPassed(ctx, k8sClient, promotedSnapshot, "passed")).To(Succeed())
+			promotedSnapshot.Annotations[gitops.SnapshotPromoteToAnnotation] = "nonexisting-environment"
+
+			result, err := adapter.EnsurePromotionRequestHandled()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(metadata.HasAnnotation(promotedSnapshot, gitops.SnapshotPromoteToAnnotation)).To(BeFalse())
+			Expect(<-recorder.Events).To(ContainSubstring("the Environment doesn't exist"))
+		})
+
+		It("creates and then updates the binding of the environment for the passed snapshots", func() {
+			Expect(gitops.MarkSnapshotAsPassed(ctx, k8sClient, promotedSnapshot, "passed")).To(Succeed())
+
+			result, err := adapter.EnsurePromotionRequestHandled()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(metadata.HasAnnotation(promotedSnapshot, gitops.SnapshotPromoteToAnnotation)).To(BeFalse())
+			Expect(<-recorder.Events).To(ContainSubstring(string(reasons.SnapshotPromotedEventReason)))
+
+			var binding *applicationapiv1alpha1.SnapshotEnvironmentBinding
+			Eventually(func(g Gomega) {
+				binding = getBinding(g)
+			}, time.Second*10).Should(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, binding)).To(Succeed())
+			})
+			Expect(binding.Spec.Snapshot).To(Equal(promotedSnapshot.Name))
+			Expect(binding.Spec.Components).To(HaveLen(1))
+			Expect(binding.Spec.Components[0].Name).To(Equal(hasComp.Name))
+			Expect(metav1.IsControlledBy(binding, hasApp)).To(BeTrue())
+
+			// promoting another snapshot updates the existing binding
+			otherSnapshot := promotedSnapshot.DeepCopy()
+			otherSnapshot.ObjectMeta = metav1.ObjectMeta{
+				GenerateName: "snapshot-promoted-",
+				Namespace:    "default",
+				Labels:       promotedSnapshot.Labels,
+				Annotations: map[string]string{
+					gitops.SnapshotPromoteToAnnotation: stagingEnv.Name,
+				},
+			}
+			otherSnapshot.Status = applicationapiv1alpha1.SnapshotStatus{}
+			Expect(k8sClient.Create(ctx, otherSnapshot)).Should(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, otherSnapshot)).To(Succeed())
+			})
+			Expect(gitops.MarkSnapshotAsPassed(ctx, k8sClient, otherSnapshot, "passed")).To(Succeed())
+
+			adapter = NewAdapter(ctx, otherSnapshot, hasApp, helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}, loader.NewMockLoader(), k8sClient, recorder)
+			result, err = adapter.EnsurePromotionRequestHandled()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(<-recorder.Events).To(ContainSubstring(binding.Name))
+
+			Eventually(func(g Gomega) {
+				g.Expect(getBinding(g).Spec.Snapshot).To(Equal(otherSnapshot.Name))
+			}, time.Second*10).Should(Succeed())
+			Expect(getBinding(Default).Name).To(Equal(binding.Name))
+		})
+	})
 })
 
 func getAllIntegrationPipelineRunsForSnapshot(ctx context.Context, snapshot *applicationapiv1alpha1.Snapshot) ([]tektonv1.PipelineRun, error) {
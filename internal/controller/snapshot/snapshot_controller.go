@@ -21,35 +21,47 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/integration-service/cache"
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
 	"github.com/konflux-ci/operator-toolkit/controller"
 	toolkitpredicates "github.com/konflux-ci/operator-toolkit/predicates"
 	toolkitutils "github.com/konflux-ci/operator-toolkit/utils"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Reconciler reconciles an Snapshot object
 type Reconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // NewSnapshotReconciler creates and returns a Reconciler.
-func NewSnapshotReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme) *Reconciler {
+func NewSnapshotReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
-		Client: client,
-		Log:    logger.WithName("snapshot"),
-		Scheme: scheme,
+		Client:   client,
+		Log:      logger.WithName("snapshot"),
+		Scheme:   scheme,
+		Recorder: recorder,
 	}
 }
 
@@ -65,13 +77,22 @@ func NewSnapshotReconciler(client client.Client, logger *logr.Logger, scheme *ru
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releases/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releases,verbs=create;delete;get;list;patch;update;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotenvironmentbindings,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=environments,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releaseplans,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=releaseplans/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotruns,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("snapshot", req.NamespacedName)}
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("snapshot", req.NamespacedName, correlation.LogKey, correlationID)}
 	loader := loader.NewLoader()
 
 	snapshot := &applicationapiv1alpha1.Snapshot{}
@@ -84,6 +105,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 		return ctrl.Result{}, err
 	}
+	logger.Logger = correlation.WithParentID(logger.Logger, snapshot)
 
 	if toolkitutils.IsObjectRestoredFromBackup(snapshot) {
 		logger.Info("Snapshot restored from backup has been updated, we cannot reconcile it, skipping.")
@@ -114,27 +136,50 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	logger = logger.WithApp(*application)
 
-	adapter := NewAdapter(ctx, snapshot, application, logger, loader, r.Client)
+	ctx, span := tracing.StartReconcileSpan(ctx, "snapshot", map[string]client.Object{"snapshot": snapshot, "application": application})
+	defer span.End()
 
-	return controller.ReconcileHandler([]controller.Operation{
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, snapshot, application, logger, loader, correlation.NewClient(r.Client, correlationID), correlation.NewRecorder(r.Recorder, correlationID))
+
+	return operations.NewChain("snapshot",
+		adapter.EnsureSnapshotOwnedByApplication,
+		adapter.EnsureSnapshotDiffRecorded,
+		adapter.EnsureImageVerificationRecorded,
+		adapter.EnsureOverrideSnapshotIsValid,
 		adapter.EnsureAllReleasesExist,
+		adapter.EnsurePromotionRequestHandled,
 		adapter.EnsureGlobalCandidateImageUpdated,
 		adapter.EnsureRerunPipelineRunsExist,
+		adapter.EnsureSnapshotRunsStarted,
 		adapter.EnsureIntegrationPipelineRunsExist,
-	})
+		adapter.EnsureLifecycleEventsNotified,
+		adapter.EnsureReleaseWindowAwaited,
+	).Run(ctx)
 }
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
+	EnsureSnapshotOwnedByApplication() (controller.OperationResult, error)
+	EnsureSnapshotDiffRecorded() (controller.OperationResult, error)
+	EnsureImageVerificationRecorded() (controller.OperationResult, error)
+	EnsureOverrideSnapshotIsValid() (controller.OperationResult, error)
 	EnsureAllReleasesExist() (controller.OperationResult, error)
+	EnsurePromotionRequestHandled() (controller.OperationResult, error)
 	EnsureRerunPipelineRunsExist() (controller.OperationResult, error)
+	EnsureSnapshotRunsStarted() (controller.OperationResult, error)
 	EnsureIntegrationPipelineRunsExist() (controller.OperationResult, error)
 	EnsureGlobalCandidateImageUpdated() (controller.OperationResult, error)
+	EnsureLifecycleEventsNotified() (controller.OperationResult, error)
+	EnsureReleaseWindowAwaited() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration controller and adds it to the Manager.
-func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewSnapshotReconciler(manager.GetClient(), log, manager.GetScheme()))
+func SetupController(manager ctrl.Manager, log *logr.Logger, eventFilters ...predicate.Predicate) error {
+	return setupControllerWithManager(manager, NewSnapshotReconciler(manager.GetClient(), log, manager.GetScheme(),
+		manager.GetEventRecorderFor(gitops.IntegrationServiceEventRecorderName)), eventFilters...)
 }
 
 // setupCache indexes fields for each of the resources used in the release adapter in those cases where filtering by
@@ -144,26 +189,78 @@ func setupCache(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return cache.SetupReleaseCache(mgr)
+	if err := cache.SetupReleaseCache(mgr); err != nil {
+		return err
+	}
+
+	return cache.SetupSnapshotRunCache(mgr)
 }
 
 // setupControllerWithManager sets up the controller with the Manager which monitors new Snapshots
-func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
 	err := setupCache(manager)
 	if err != nil {
 		return err
 	}
 
 	return ctrl.NewControllerManagedBy(manager).
-		For(&applicationapiv1alpha1.Snapshot{}).
-		WithEventFilter(
-			predicate.And(
-				toolkitpredicates.IgnoreBackups{},
-				predicate.Or(
-					gitops.IntegrationSnapshotChangePredicate(),
-					gitops.SnapshotIntegrationTestRerunTriggerPredicate(),
-				),
+		For(&applicationapiv1alpha1.Snapshot{}, builder.WithPredicates(
+			predicate.Or(
+				gitops.IntegrationSnapshotChangePredicate(),
+				gitops.SnapshotIntegrationTestRerunTriggerPredicate(),
+				gitops.SnapshotPromotionRequestedPredicate(),
 			),
-		).
+		)).
+		Watches(&v1beta2.SnapshotRun{}, handler.EnqueueRequestsFromMapFunc(snapshotRunToSnapshot),
+			builder.WithPredicates(gitops.SnapshotRunCreatedPredicate())).
+		Watches(&v1beta2.IntegrationTestScenario{}, handler.EnqueueRequestsFromMapFunc(controller.scenarioToAwaitingSnapshots),
+			builder.WithPredicates(gitops.IntegrationTestScenarioChangePredicate())).
+		WithEventFilter(toolkitpredicates.IgnoreBackups{}).
+		WithEventFilter(predicate.And(eventFilters...)).
 		Complete(controller)
 }
+
+// snapshotRunToSnapshot maps the SnapshotRuns to the reconcile requests of the Snapshots they run the integration
+// tests against.
+func snapshotRunToSnapshot(_ context.Context, obj client.Object) []reconcile.Request {
+	snapshotRun, ok := obj.(*v1beta2.SnapshotRun)
+	if !ok || snapshotRun.Spec.Snapshot == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: snapshotRun.Namespace,
+		Name:      snapshotRun.Spec.Snapshot,
+	}}}
+}
+
+// scenarioToAwaitingSnapshots maps the IntegrationTestScenarios to the reconcile requests of the Snapshots of their
+// application which are still awaiting the results of their integration tests, so that the integration tests of the
+// added scenarios are started for them and the ones of the removed scenarios are dropped.
+func (r *Reconciler) scenarioToAwaitingSnapshots(ctx context.Context, obj client.Object) []reconcile.Request {
+	scenario, ok := obj.(*v1beta2.IntegrationTestScenario)
+	if !ok || scenario.Spec.Application == "" {
+		return nil
+	}
+
+	// only the name and namespace of the application are needed to look its Snapshots up
+	application := &applicationapiv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: scenario.Namespace, Name: scenario.Spec.Application},
+	}
+	snapshots, err := loader.NewLoader().GetAllSnapshotsAwaitingTests(ctx, r.Client, application)
+	if err != nil {
+		r.Log.Error(err, "Failed to list the Snapshots of the application of the IntegrationTestScenario",
+			"integrationTestScenario", types.NamespacedName{Namespace: scenario.Namespace, Name: scenario.Name})
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, snapshot := range *snapshots {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: snapshot.Namespace,
+			Name:      snapshot.Name,
+		}})
+	}
+
+	return requests
+}
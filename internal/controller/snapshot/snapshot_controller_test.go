@@ -26,6 +26,7 @@ import (
 	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -34,6 +35,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/integration-service/gitops"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
@@ -143,7 +145,7 @@ var _ = Describe("SnapshotController", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(err).To(BeNil())
 
-		snapshotReconciler = NewSnapshotReconciler(k8sClient, &logf.Log, &scheme)
+		snapshotReconciler = NewSnapshotReconciler(k8sClient, &logf.Log, &scheme, &record.FakeRecorder{})
 	})
 	AfterEach(func() {
 		err := k8sClient.Delete(ctx, hasApp)
@@ -225,6 +227,29 @@ var _ = Describe("SnapshotController", func() {
 		Expect(err).To(BeNil())
 	})
 
+	It("maps the IntegrationTestScenarios to the Snapshots of their application still awaiting their tests", func() {
+		scenario := &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "example-pass",
+				Namespace: "default",
+			},
+			Spec: v1beta2.IntegrationTestScenarioSpec{
+				Application: hasApp.Name,
+			},
+		}
+		Eventually(func() []reconcile.Request {
+			return snapshotReconciler.scenarioToAwaitingSnapshots(ctx, scenario)
+		}).Should(ContainElement(req))
+
+		Expect(gitops.MarkSnapshotAsPassed(ctx, k8sClient, hasSnapshot, "Passed")).To(Succeed())
+		Eventually(func() []reconcile.Request {
+			return snapshotReconciler.scenarioToAwaitingSnapshots(ctx, scenario)
+		}).ShouldNot(ContainElement(req))
+
+		scenario.Spec.Application = "another-application"
+		Expect(snapshotReconciler.scenarioToAwaitingSnapshots(ctx, scenario)).To(BeEmpty())
+	})
+
 	When("snapshot is restored from backup", func() {
 
 		BeforeEach(func() {
@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/cache"
 	toolkit "github.com/konflux-ci/operator-toolkit/test"
 
 	"k8s.io/client-go/rest"
@@ -104,6 +105,9 @@ var _ = BeforeSuite(func() {
 		LeaderElection: false,
 	})
 
+	Expect(cache.SetupSnapshotRunCache(k8sManager)).To(Succeed())
+	Expect(cache.SetupSnapshotCache(k8sManager)).To(Succeed())
+
 	k8sClient = k8sManager.GetClient()
 	go func() {
 		defer GinkgoRecover()
@@ -0,0 +1,246 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshotrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/loader"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/operator-toolkit/controller"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Adapter holds the objects needed to reconcile a SnapshotRun.
+type Adapter struct {
+	snapshotRun *v1beta2.SnapshotRun
+	snapshot    *applicationapiv1alpha1.Snapshot
+	logger      helpers.IntegrationLogger
+	loader      loader.ObjectLoader
+	client      client.Client
+	recorder    record.EventRecorder
+	context     context.Context
+}
+
+// NewAdapter creates and returns an Adapter instance.
+func NewAdapter(context context.Context, snapshotRun *v1beta2.SnapshotRun, snapshot *applicationapiv1alpha1.Snapshot,
+	logger helpers.IntegrationLogger, loader loader.ObjectLoader, client client.Client, recorder record.EventRecorder,
+) *Adapter {
+	return &Adapter{
+		snapshotRun: snapshotRun,
+		snapshot:    snapshot,
+		logger:      logger,
+		loader:      loader,
+		client:      client,
+		recorder:    recorder,
+		context:     context,
+	}
+}
+
+// EnsureSnapshotRunIsValid is an operation that will ensure that the SnapshotRuns of invalid Snapshots, which are
+// never tested, are marked as invalid instead of waiting for their integration tests to be started.
+func (a *Adapter) EnsureSnapshotRunIsValid() (controller.OperationResult, error) {
+	if a.snapshotRun.HasStarted() || !gitops.IsSnapshotMarkedAsInvalid(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	message := fmt.Sprintf("the Snapshot %s is invalid", a.snapshot.Name)
+	if err := gitops.MarkSnapshotRunAsInvalid(a.context, a.client, a.snapshotRun, message); err != nil {
+		a.logger.Error(err, "Failed to mark the SnapshotRun as invalid")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("SnapshotRun marked as invalid", a.snapshotRun, helpers.LogActionUpdate, "reason", message)
+	a.recorder.Event(a.snapshotRun, corev1.EventTypeWarning, string(reasons.SnapshotRunInvalidEventReason), message)
+
+	return controller.StopProcessing()
+}
+
+// EnsureScenarioStatusesSynced is an operation that will ensure that the status of each of the integration tests of
+// the SnapshotRun matches the test status recorded in its Snapshot.
+func (a *Adapter) EnsureScenarioStatusesSynced() (controller.OperationResult, error) {
+	if !a.snapshotRun.HasStarted() {
+		// the scenarios are still being recorded by the snapshot controller
+		return controller.ContinueProcessing()
+	}
+
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the test statuses of the Snapshot")
+		return controller.StopProcessing()
+	}
+
+	scenarios := make([]v1beta2.SnapshotRunScenarioStatus, len(a.snapshotRun.Status.Scenarios))
+	copy(scenarios, a.snapshotRun.Status.Scenarios)
+	for i := range scenarios {
+		integrationTestScenarioStatus, ok := testStatuses.GetScenarioStatus(scenarios[i].Name)
+		if !ok {
+			continue
+		}
+		if integrationTestScenarioStatus.TestPipelineRunName != "" {
+			scenarios[i].PipelineRun = integrationTestScenarioStatus.TestPipelineRunName
+		}
+		scenarios[i].Status = integrationTestScenarioStatus.Status.String()
+		scenarios[i].Details = integrationTestScenarioStatus.Details
+	}
+
+	if equality.Semantic.DeepEqual(scenarios, a.snapshotRun.Status.Scenarios) {
+		return controller.ContinueProcessing()
+	}
+
+	patch := client.MergeFrom(a.snapshotRun.DeepCopy())
+	a.snapshotRun.Status.Scenarios = scenarios
+	if err = a.client.Status().Patch(a.context, a.snapshotRun, patch); err != nil {
+		a.logger.Error(err, "Failed to update the scenarios of the SnapshotRun")
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureRevalidationResultRecorded is an operation that will ensure that the result of the SnapshotRuns revalidating
+// released Snapshots is recorded in the conditions of the Snapshot once all of their integration tests finished, and
+// that the notification sinks of the namespace are notified when a previously released Snapshot now fails them.
+func (a *Adapter) EnsureRevalidationResultRecorded() (controller.OperationResult, error) {
+	if !gitops.IsRevalidationSnapshotRun(a.snapshotRun) || !a.snapshotRun.HasStarted() || a.snapshotRun.HasFinished() {
+		return controller.ContinueProcessing()
+	}
+
+	failed, finished := a.countFailedScenarios()
+	if !finished {
+		return controller.ContinueProcessing()
+	}
+
+	message := fmt.Sprintf("The released Snapshot passed the integration tests re-run by SnapshotRun %s", a.snapshotRun.Name)
+	if failed > 0 {
+		message = fmt.Sprintf("The released Snapshot failed %d of the %d integration tests re-run by SnapshotRun %s",
+			failed, len(a.snapshotRun.Status.Scenarios), a.snapshotRun.Name)
+	}
+	condition := meta.FindStatusCondition(a.snapshot.Status.Conditions, gitops.SnapshotRevalidatedCondition)
+	if condition == nil || condition.Message != message {
+		if err := gitops.MarkSnapshotRevalidation(a.context, a.client, a.snapshot, failed == 0, message); err != nil {
+			a.logger.Error(err, "Failed to record the revalidation result in the Snapshot")
+			return controller.RequeueWithError(err)
+		}
+		a.logger.LogAuditEvent("Recorded the revalidation result of the released Snapshot", a.snapshot, helpers.LogActionUpdate,
+			"message", message)
+		if failed > 0 {
+			a.recorder.Event(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotRevalidationFailedEventReason), message)
+		}
+	}
+
+	if failed == 0 || notification.IsEventNotified(a.snapshotRun, notification.EventRevalidationFailed) {
+		return controller.ContinueProcessing()
+	}
+
+	sinks, err := notification.LoadSinks(a.context, a.client, a.snapshotRun.Namespace)
+	if notification.IsInvalidConfiguration(err) {
+		// a misconfigured secret won't be fixed by retrying, so don't block the SnapshotRun on it
+		a.logger.Error(err, "Failed to configure the notification sinks, skipping the notification")
+		return controller.ContinueProcessing()
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to load the notification sinks", "secret.Name", notification.SecretName)
+		return controller.RequeueWithError(err)
+	}
+	if len(sinks) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	event := notification.NewSnapshotEvent(notification.EventRevalidationFailed, a.snapshot)
+	event.Verdict = reasons.SnapshotRevalidationFailed
+	event.Message = message
+	for _, scenario := range a.snapshotRun.Status.Scenarios {
+		event.Scenarios = append(event.Scenarios, notification.ScenarioResult{
+			Name:            scenario.Name,
+			Status:          scenario.Status,
+			Details:         scenario.Details,
+			PipelineRunName: scenario.PipelineRun,
+		})
+	}
+	// the sinks which delivered the event are recorded, so that only the failed ones are retried
+	patch := client.MergeFrom(a.snapshotRun.DeepCopy())
+	errForNotification := notification.SendOnce(a.context, a.snapshotRun, sinks, event)
+	if errForNotification == nil {
+		_ = notification.SetEventNotified(a.snapshotRun, notification.EventRevalidationFailed)
+	}
+	if err = a.client.Patch(a.context, a.snapshotRun, patch); err != nil {
+		a.logger.Error(err, "Failed to annotate the SnapshotRun with the notified event")
+		return controller.RequeueWithError(err)
+	}
+	if errForNotification != nil {
+		a.logger.Error(errForNotification, "Failed to notify about the failed revalidation of the Snapshot")
+		return controller.RequeueWithError(errForNotification)
+	}
+	a.logger.LogAuditEvent("Notified about the failed revalidation of the Snapshot", a.snapshotRun, helpers.LogActionUpdate,
+		"sinks", len(sinks))
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureSnapshotRunFinished is an operation that will ensure that the SnapshotRun is marked as passed or failed
+// once all of its integration tests finished.
+func (a *Adapter) EnsureSnapshotRunFinished() (controller.OperationResult, error) {
+	if !a.snapshotRun.HasStarted() || a.snapshotRun.HasFinished() {
+		return controller.ContinueProcessing()
+	}
+
+	failed, finished := a.countFailedScenarios()
+	if !finished {
+		return controller.ContinueProcessing()
+	}
+
+	message := "All of the integration tests passed"
+	if failed > 0 {
+		message = fmt.Sprintf("%d of the %d integration tests failed", failed, len(a.snapshotRun.Status.Scenarios))
+	}
+	if err := gitops.MarkSnapshotRunAsFinished(a.context, a.client, a.snapshotRun, failed == 0, message); err != nil {
+		a.logger.Error(err, "Failed to mark the SnapshotRun as finished")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("SnapshotRun finished", a.snapshotRun, helpers.LogActionUpdate, "message", message)
+
+	return controller.ContinueProcessing()
+}
+
+// countFailedScenarios returns the number of the integration tests of the SnapshotRun which didn't pass and whether
+// all of them finished.
+func (a *Adapter) countFailedScenarios() (int, bool) {
+	failed := 0
+	for _, scenario := range a.snapshotRun.Status.Scenarios {
+		status, err := intgteststat.IntegrationTestStatusString(scenario.Status)
+		if err != nil || !status.IsFinal() {
+			a.logger.Info("The integration tests of the SnapshotRun haven't finished yet", "scenario", scenario.Name)
+			return failed, false
+		}
+		if !status.IsPassed() {
+			failed++
+		}
+	}
+
+	return failed, true
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshotrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tonglil/buflogr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/loader"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("SnapshotRun Adapter", Ordered, func() {
+	var (
+		adapter        *Adapter
+		buf            bytes.Buffer
+		hasSnapshot    *applicationapiv1alpha1.Snapshot
+		hasSnapshotRun *v1beta2.SnapshotRun
+	)
+
+	const (
+		scenarioA = "scenario-a"
+		scenarioB = "scenario-b"
+	)
+
+	writeTestStatus := func(scenarioName string, status intgteststat.IntegrationTestStatus, pipelineRunName string) {
+		statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+		Expect(err).To(Succeed())
+		statuses.UpdateTestStatusIfChanged(scenarioName, status, "test "+status.String())
+		Expect(statuses.UpdateTestPipelineRunName(scenarioName, pipelineRunName)).To(Succeed())
+		Expect(gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		hasSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1",
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshot)).Should(Succeed())
+
+		hasSnapshotRun = &v1beta2.SnapshotRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshotrun-sample",
+				Namespace: "default",
+			},
+			Spec: v1beta2.SnapshotRunSpec{
+				Snapshot: hasSnapshot.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshotRun)).Should(Succeed())
+
+		log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+		adapter = NewAdapter(ctx, hasSnapshotRun, hasSnapshot, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshotRun)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Delete(ctx, hasSnapshot)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	startSnapshotRun := func(scenarios ...string) {
+		patch := client.MergeFrom(hasSnapshotRun.DeepCopy())
+		for _, scenario := range scenarios {
+			hasSnapshotRun.Status.Scenarios = append(hasSnapshotRun.Status.Scenarios, v1beta2.SnapshotRunScenarioStatus{
+				Name:        scenario,
+				PipelineRun: "pipelinerun-" + scenario,
+			})
+		}
+		Expect(k8sClient.Status().Patch(ctx, hasSnapshotRun, patch)).To(Succeed())
+		Expect(gitops.MarkSnapshotRunAsRunning(ctx, k8sClient, hasSnapshotRun, "running")).To(Succeed())
+	}
+
+	It("can create a new Adapter instance", func() {
+		Expect(NewAdapter(ctx, hasSnapshotRun, hasSnapshot, helpers.IntegrationLogger{}, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})).NotTo(BeNil())
+	})
+
+	It("continues processing the SnapshotRuns of valid Snapshots", func() {
+		result, err := adapter.EnsureSnapshotRunIsValid()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(hasSnapshotRun.HasFinished()).To(BeFalse())
+	})
+
+	It("marks the SnapshotRuns of invalid Snapshots as invalid", func() {
+		Expect(gitops.MarkSnapshotAsInvalid(ctx, k8sClient, hasSnapshot, "the Snapshot is invalid")).To(Succeed())
+
+		result, err := adapter.EnsureSnapshotRunIsValid()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeTrue())
+		condition := meta.FindStatusCondition(hasSnapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(v1beta2.SnapshotRunInvalidReason))
+		Expect(condition.Message).To(Equal("the Snapshot snapshot-sample is invalid"))
+	})
+
+	It("doesn't sync the scenarios of the SnapshotRuns which haven't started", func() {
+		writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestPassed, "pipelinerun-"+scenarioA)
+
+		result, err := adapter.EnsureScenarioStatusesSynced()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(hasSnapshotRun.Status.Scenarios).To(BeEmpty())
+	})
+
+	It("syncs the status of the scenarios from the test status of the Snapshot", func() {
+		startSnapshotRun(scenarioA, scenarioB)
+		writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestPassed, "pipelinerun-"+scenarioA)
+
+		result, err := adapter.EnsureScenarioStatusesSynced()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(hasSnapshotRun.Status.Scenarios).To(Equal([]v1beta2.SnapshotRunScenarioStatus{
+			{
+				Name:        scenarioA,
+				PipelineRun: "pipelinerun-" + scenarioA,
+				Status:      "TestPassed",
+				Details:     "test TestPassed",
+			},
+			{
+				Name:        scenarioB,
+				PipelineRun: "pipelinerun-" + scenarioB,
+			},
+		}))
+
+		result, err = adapter.EnsureSnapshotRunFinished()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(hasSnapshotRun.HasFinished()).To(BeFalse())
+	})
+
+	It("marks the SnapshotRun as passed once all of its integration tests passed", func() {
+		startSnapshotRun(scenarioA)
+		writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestPassed, "pipelinerun-"+scenarioA)
+
+		_, err := adapter.EnsureScenarioStatusesSynced()
+		Expect(err).To(Succeed())
+		result, err := adapter.EnsureSnapshotRunFinished()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(hasSnapshotRun.HasFinished()).To(BeTrue())
+		Expect(hasSnapshotRun.Status.CompletionTime).NotTo(BeNil())
+		Expect(meta.IsStatusConditionTrue(hasSnapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)).To(BeTrue())
+	})
+
+	It("marks the SnapshotRun as failed once all of its integration tests finished and some failed", func() {
+		startSnapshotRun(scenarioA, scenarioB)
+		writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestPassed, "pipelinerun-"+scenarioA)
+		writeTestStatus(scenarioB, intgteststat.IntegrationTestStatusTestFail, "pipelinerun-"+scenarioB)
+
+		_, err := adapter.EnsureScenarioStatusesSynced()
+		Expect(err).To(Succeed())
+		result, err := adapter.EnsureSnapshotRunFinished()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		condition := meta.FindStatusCondition(hasSnapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(v1beta2.SnapshotRunFailedReason))
+		Expect(condition.Message).To(Equal("1 of the 2 integration tests failed"))
+	})
+
+	When("the SnapshotRun revalidates a released Snapshot", func() {
+		var (
+			server   *httptest.Server
+			payloads []notification.Event
+			secret   *corev1.Secret
+		)
+
+		BeforeEach(func() {
+			patch := client.MergeFrom(hasSnapshotRun.DeepCopy())
+			hasSnapshotRun.Labels = map[string]string{gitops.RevalidationSnapshotRunLabel: "true"}
+			Expect(k8sClient.Patch(ctx, hasSnapshotRun, patch)).To(Succeed())
+
+			payloads = []notification.Event{}
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event := notification.Event{}
+				Expect(json.NewDecoder(r.Body).Decode(&event)).To(Succeed())
+				payloads = append(payloads, event)
+			}))
+			// trust the certificate of the test server in the webhook sink
+			defaultTransport := http.DefaultTransport
+			http.DefaultTransport = server.Client().Transport
+			DeferCleanup(func() {
+				http.DefaultTransport = defaultTransport
+				server.Close()
+			})
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      notification.SecretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					notification.SecretWebhookURLsKey: []byte(server.URL),
+					notification.SecretWebhookHMACKey: []byte("hmac-key"),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			Eventually(func() map[string][]byte {
+				cached := &corev1.Secret{}
+				_ = k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, cached)
+				return cached.Data
+			}, time.Second*10).Should(Equal(secret.Data))
+		})
+
+		AfterEach(func() {
+			err := k8sClient.Delete(ctx, secret)
+			Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("records the passed revalidation in the Snapshot without notifying", func() {
+			startSnapshotRun(scenarioA)
+			writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestPassed, "pipelinerun-"+scenarioA)
+
+			_, err := adapter.EnsureScenarioStatusesSynced()
+			Expect(err).To(Succeed())
+			result, err := adapter.EnsureRevalidationResultRecorded()
+			Expect(err).To(Succeed())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(meta.IsStatusConditionTrue(hasSnapshot.Status.Conditions, gitops.SnapshotRevalidatedCondition)).To(BeTrue())
+			Expect(payloads).To(BeEmpty())
+		})
+
+		It("records the failed revalidation in the Snapshot and notifies about it once", func() {
+			startSnapshotRun(scenarioA, scenarioB)
+			writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestPassed, "pipelinerun-"+scenarioA)
+			writeTestStatus(scenarioB, intgteststat.IntegrationTestStatusTestFail, "pipelinerun-"+scenarioB)
+
+			_, err := adapter.EnsureScenarioStatusesSynced()
+			Expect(err).To(Succeed())
+			result, err := adapter.EnsureRevalidationResultRecorded()
+			Expect(err).To(Succeed())
+			Expect(result.CancelRequest).To(BeFalse())
+			Expect(gitops.IsSnapshotRevalidationFailed(hasSnapshot)).To(BeTrue())
+			Expect(meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.SnapshotRevalidatedCondition).Message).
+				To(Equal("The released Snapshot failed 1 of the 2 integration tests re-run by SnapshotRun snapshotrun-sample"))
+
+			Expect(payloads).To(HaveLen(1))
+			Expect(payloads[0].Type).To(Equal(notification.EventRevalidationFailed))
+			Expect(payloads[0].Snapshot).To(Equal(hasSnapshot.Name))
+			Expect(payloads[0].Scenarios).To(HaveLen(2))
+			Expect(notification.IsEventNotified(hasSnapshotRun, notification.EventRevalidationFailed)).To(BeTrue())
+
+			_, err = adapter.EnsureRevalidationResultRecorded()
+			Expect(err).To(Succeed())
+			Expect(payloads).To(HaveLen(1))
+		})
+	})
+
+	It("doesn't record the result of the SnapshotRuns which don't revalidate a Snapshot", func() {
+		startSnapshotRun(scenarioA)
+		writeTestStatus(scenarioA, intgteststat.IntegrationTestStatusTestFail, "pipelinerun-"+scenarioA)
+
+		_, err := adapter.EnsureScenarioStatusesSynced()
+		Expect(err).To(Succeed())
+		result, err := adapter.EnsureRevalidationResultRecorded()
+		Expect(err).To(Succeed())
+		Expect(result.CancelRequest).To(BeFalse())
+		Expect(meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.SnapshotRevalidatedCondition)).To(BeNil())
+	})
+})
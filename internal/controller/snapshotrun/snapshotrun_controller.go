@@ -0,0 +1,175 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshotrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
+	"github.com/konflux-ci/operator-toolkit/controller"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconciler reconciles a SnapshotRun object
+type Reconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// NewSnapshotRunReconciler creates and returns a Reconciler.
+func NewSnapshotRunReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder) *Reconciler {
+	return &Reconciler{
+		Client:   client,
+		Log:      logger.WithName("snapshotrun"),
+		Scheme:   scheme,
+		Recorder: recorder,
+	}
+}
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshotruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("snapshotRun", req.NamespacedName, correlation.LogKey, correlationID)}
+	loader := loader.NewLoader()
+
+	snapshotRun := &v1beta2.SnapshotRun{}
+	err := r.Get(ctx, req.NamespacedName, snapshotRun)
+	if err != nil {
+		logger.Error(err, "Failed to get snapshotRun for", "req", req.NamespacedName)
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+	logger.Logger = correlation.WithParentID(logger.Logger, snapshotRun)
+
+	if snapshotRun.HasFinished() {
+		return ctrl.Result{}, nil
+	}
+
+	snapshot, err := loader.GetSnapshot(ctx, r.Client, snapshotRun.Spec.Snapshot, snapshotRun.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			message := fmt.Sprintf("the Snapshot %s doesn't exist", snapshotRun.Spec.Snapshot)
+			if err := gitops.MarkSnapshotRunAsInvalid(ctx, r.Client, snapshotRun, message); err != nil {
+				logger.Error(err, "Failed to mark the SnapshotRun as invalid")
+				return ctrl.Result{}, err
+			}
+			logger.LogAuditEvent("SnapshotRun marked as invalid", snapshotRun, helpers.LogActionUpdate, "reason", message)
+			correlation.NewRecorder(r.Recorder, correlationID).Event(snapshotRun, corev1.EventTypeWarning, string(reasons.SnapshotRunInvalidEventReason), message)
+		}
+		return helpers.HandleLoaderError(logger, err, "Snapshot", "SnapshotRun")
+	}
+
+	ctx, span := tracing.StartReconcileSpan(ctx, "snapshotrun", map[string]client.Object{"snapshotRun": snapshotRun, "snapshot": snapshot})
+	defer span.End()
+
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, snapshotRun, snapshot, logger, loader, correlation.NewClient(r.Client, correlationID), correlation.NewRecorder(r.Recorder, correlationID))
+
+	return operations.NewChain("snapshotrun",
+		adapter.EnsureSnapshotRunIsValid,
+		adapter.EnsureScenarioStatusesSynced,
+		adapter.EnsureRevalidationResultRecorded,
+		adapter.EnsureSnapshotRunFinished,
+	).Run(ctx)
+}
+
+// AdapterInterface is an interface defining all the operations that should be defined in a SnapshotRun adapter.
+type AdapterInterface interface {
+	EnsureSnapshotRunIsValid() (controller.OperationResult, error)
+	EnsureScenarioStatusesSynced() (controller.OperationResult, error)
+	EnsureRevalidationResultRecorded() (controller.OperationResult, error)
+	EnsureSnapshotRunFinished() (controller.OperationResult, error)
+}
+
+// SetupController creates a new SnapshotRun controller and adds it to the Manager.
+func SetupController(manager ctrl.Manager, log *logr.Logger, eventFilters ...predicate.Predicate) error {
+	return setupControllerWithManager(manager, NewSnapshotRunReconciler(manager.GetClient(), log, manager.GetScheme(),
+		manager.GetEventRecorderFor(gitops.IntegrationServiceEventRecorderName)), eventFilters...)
+}
+
+// setupControllerWithManager sets up the controller with the Manager which monitors the SnapshotRuns and the test
+// status of their Snapshots. The SnapshotRuns of a Snapshot are found through the index set up by the snapshot
+// controller, see cache.SetupSnapshotRunCache.
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
+	return ctrl.NewControllerManagedBy(manager).
+		For(&v1beta2.SnapshotRun{}).
+		Watches(&applicationapiv1alpha1.Snapshot{}, handler.EnqueueRequestsFromMapFunc(controller.snapshotToSnapshotRuns),
+			builder.WithPredicates(gitops.SnapshotTestAnnotationChangePredicate())).
+		WithEventFilter(predicate.And(eventFilters...)).
+		Complete(controller)
+}
+
+// snapshotToSnapshotRuns maps the Snapshots to the reconcile requests of their SnapshotRuns which haven't finished yet.
+func (r *Reconciler) snapshotToSnapshotRuns(ctx context.Context, obj client.Object) []reconcile.Request {
+	snapshot, ok := obj.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return nil
+	}
+
+	snapshotRuns, err := loader.NewLoader().GetAllSnapshotRunsForSnapshot(ctx, r.Client, snapshot)
+	if err != nil {
+		r.Log.Error(err, "Failed to get the SnapshotRuns of the Snapshot", "snapshot", client.ObjectKeyFromObject(snapshot))
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, snapshotRun := range *snapshotRuns {
+		if !snapshotRun.HasFinished() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: snapshotRun.Namespace,
+				Name:      snapshotRun.Name,
+			}})
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshotrun
+
+import (
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientsetscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("SnapshotRunController", func() {
+	var (
+		manager               ctrl.Manager
+		snapshotRunReconciler *Reconciler
+		scheme                runtime.Scheme
+		req                   ctrl.Request
+		hasSnapshotRun        *v1beta2.SnapshotRun
+	)
+
+	BeforeEach(func() {
+		hasSnapshotRun = &v1beta2.SnapshotRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshotrun-sample",
+				Namespace: "default",
+			},
+			Spec: v1beta2.SnapshotRunSpec{
+				Snapshot: "missing-snapshot",
+			},
+		}
+		Expect(k8sClient.Create(ctx, hasSnapshotRun)).Should(Succeed())
+
+		req = ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: "default",
+				Name:      hasSnapshotRun.Name,
+			},
+		}
+
+		var err error
+		manager, err = ctrl.NewManager(cfg, ctrl.Options{
+			Scheme: clientsetscheme.Scheme,
+			Metrics: server.Options{
+				BindAddress: "0", // disables metrics
+			},
+			LeaderElection: false,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		snapshotRunReconciler = NewSnapshotRunReconciler(k8sClient, &logf.Log, &scheme, &record.FakeRecorder{})
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(ctx, hasSnapshotRun)
+		Expect(err == nil || errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("can create and return a new Reconciler object", func() {
+		Expect(reflect.TypeOf(snapshotRunReconciler)).To(Equal(reflect.TypeOf(&Reconciler{})))
+	})
+
+	It("can Reconcile function prepare the adapter and return the result of the reconcile handling operation", func() {
+		req := ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      "non-existent",
+				Namespace: "default",
+			},
+		}
+		result, err := snapshotRunReconciler.Reconcile(ctx, req)
+		Expect(reflect.TypeOf(result)).To(Equal(reflect.TypeOf(reconcile.Result{})))
+		Expect(err).To(BeNil())
+	})
+
+	It("marks the SnapshotRun as invalid when its Snapshot doesn't exist", func() {
+		Eventually(func() error {
+			return k8sClient.Get(ctx, req.NamespacedName, &v1beta2.SnapshotRun{})
+		}, time.Second*10).Should(Succeed())
+
+		result, err := snapshotRunReconciler.Reconcile(ctx, req)
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(err).To(BeNil())
+
+		Eventually(func() bool {
+			snapshotRun := &v1beta2.SnapshotRun{}
+			err := k8sClient.Get(ctx, req.NamespacedName, snapshotRun)
+			return err == nil && meta.IsStatusConditionFalse(snapshotRun.Status.Conditions, v1beta2.SnapshotRunSucceededCondition)
+		}, time.Second*10).Should(BeTrue())
+	})
+
+	It("can setup a new controller manager with the given snapshotRunReconciler", func() {
+		err := setupControllerWithManager(manager, snapshotRunReconciler)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("can setup a new Controller manager and start it", func() {
+		err := SetupController(manager, &ctrl.Log)
+		Expect(err).To(BeNil())
+	})
+
+	It("maps the Snapshots to their unfinished SnapshotRuns", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      hasSnapshotRun.Spec.Snapshot,
+				Namespace: "default",
+			},
+		}
+		Eventually(func() []reconcile.Request {
+			return snapshotRunReconciler.snapshotToSnapshotRuns(ctx, snapshot)
+		}, time.Second*10).Should(ConsistOf(req))
+	})
+})
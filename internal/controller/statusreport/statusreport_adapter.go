@@ -19,29 +19,45 @@ package statusreport
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/konflux-ci/operator-toolkit/controller"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/git/github"
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
 	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
 	"github.com/konflux-ci/integration-service/status"
 	"github.com/konflux-ci/integration-service/tekton"
 	"github.com/konflux-ci/operator-toolkit/metadata"
-	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 )
 
 const SnapshotRetryTimeout = time.Duration(3 * time.Hour)
 
+const (
+	// SnapshotReevaluationIntervalEnvVar is the environment variable holding how often the Snapshots still awaiting
+	// the results of their integration tests are re-evaluated, "0" disables the re-evaluation
+	SnapshotReevaluationIntervalEnvVar = "SNAPSHOT_REEVALUATION_INTERVAL"
+
+	// DefaultSnapshotReevaluationInterval is how often the Snapshots still awaiting the results of their integration
+	// tests are re-evaluated when the SnapshotReevaluationIntervalEnvVar isn't set
+	DefaultSnapshotReevaluationInterval = 15 * time.Minute
+)
+
 // Adapter holds the objects needed to reconcile a snapshot's test status report.
 type Adapter struct {
 	snapshot    *applicationapiv1alpha1.Snapshot
@@ -49,13 +65,14 @@ type Adapter struct {
 	logger      helpers.IntegrationLogger
 	loader      loader.ObjectLoader
 	client      client.Client
+	recorder    record.EventRecorder
 	context     context.Context
 	status      status.StatusInterface
 }
 
 // NewAdapter creates and returns an Adapter instance.
 func NewAdapter(context context.Context, snapshot *applicationapiv1alpha1.Snapshot, application *applicationapiv1alpha1.Application,
-	logger helpers.IntegrationLogger, loader loader.ObjectLoader, client client.Client,
+	logger helpers.IntegrationLogger, loader loader.ObjectLoader, client client.Client, recorder record.EventRecorder,
 ) *Adapter {
 	return &Adapter{
 		snapshot:    snapshot,
@@ -63,15 +80,17 @@ func NewAdapter(context context.Context, snapshot *applicationapiv1alpha1.Snapsh
 		logger:      logger,
 		loader:      loader,
 		client:      client,
+		recorder:    recorder,
 		context:     context,
 		status:      status.NewStatus(logger.Logger, client),
 	}
 }
 
 // EnsureSnapshotTestStatusReportedToGitProvider will ensure that integration test status is reported to the git provider
-// which (indirectly) triggered its execution.
+// which (indirectly) triggered its execution. The test status of the Snapshots created for push events is reported
+// only when it was requested, see gitops.IsSnapshotStatusReportRequested.
 func (a *Adapter) EnsureSnapshotTestStatusReportedToGitProvider() (controller.OperationResult, error) {
-	if gitops.IsSnapshotCreatedByPACPushEvent(a.snapshot) {
+	if gitops.IsSnapshotCreatedByPACPushEvent(a.snapshot) && !gitops.IsSnapshotStatusReportRequested(a.snapshot) {
 		return controller.ContinueProcessing()
 	}
 
@@ -86,6 +105,10 @@ func (a *Adapter) EnsureSnapshotTestStatusReportedToGitProvider() (controller.Op
 	if err != nil {
 		a.logger.Error(err, "failed to report test status to git provider for snapshot",
 			"snapshot.Namespace", a.snapshot.Namespace, "snapshot.Name", a.snapshot.Name)
+		if retryAfter, limited := github.GetRateLimitRetryAfter(err); limited {
+			// the git provider asked to wait, the status is reported again once its rate limit resets
+			return controller.RequeueAfter(retryAfter, nil)
+		}
 		if helpers.IsObjectYoungerThanThreshold(a.snapshot, SnapshotRetryTimeout) {
 			return controller.RequeueWithError(err)
 		}
@@ -97,11 +120,7 @@ func (a *Adapter) EnsureSnapshotTestStatusReportedToGitProvider() (controller.Op
 	for _, testDetails := range testStatuses.GetStatuses() {
 		if testDetails.Status.IsFinal() && testDetails.TestPipelineRunName != "" {
 			pipelineRunName := testDetails.TestPipelineRunName
-			pipelineRun := &tektonv1.PipelineRun{}
-			err := a.client.Get(a.context, types.NamespacedName{
-				Namespace: a.snapshot.Namespace,
-				Name:      pipelineRunName,
-			}, pipelineRun)
+			pipelineRun, err := a.loader.GetPipelineRun(a.context, a.client, pipelineRunName, a.snapshot.Namespace)
 
 			// if the PLR doesn't exist on cluster we continue the loop
 			if err != nil {
@@ -125,9 +144,14 @@ func (a *Adapter) EnsureSnapshotTestStatusReportedToGitProvider() (controller.Op
 // If the Snapshot doesn't have the freshest state of components, a composite Snapshot will be created instead
 // and the original Snapshot will be marked as Invalid.
 func (a *Adapter) EnsureSnapshotFinishedAllTests() (controller.OperationResult, error) {
+	// the Snapshots which timed out stay failed even when their lost integration tests finish eventually
+	if gitops.IsSnapshotMarkedAsTimedOut(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
 	// Get all required integrationTestScenarios for the Application and then use the Snapshot status annotation
 	// to check if all Integration tests were finished for that Snapshot
-	integrationTestScenarios, err := a.loader.GetRequiredIntegrationTestScenariosForApplication(a.context, a.client, a.application)
+	integrationTestScenarios, err := a.getRequiredIntegrationTestScenarios()
 	if err != nil {
 		return controller.RequeueWithError(err)
 	}
@@ -138,7 +162,20 @@ func (a *Adapter) EnsureSnapshotFinishedAllTests() (controller.OperationResult,
 		return controller.RequeueWithError(err)
 	}
 
-	allIntegrationTestsFinished, allIntegrationTestsPassed := a.determineIfAllRequiredIntegrationTestsFinishedAndPassed(integrationTestScenarios, testStatuses)
+	policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the IntegrationPolicy of the application")
+		return controller.RequeueWithError(err)
+	}
+
+	quorums := gitops.EvaluateScenarioQuorums(policy, integrationTestScenarios, testStatuses)
+	allIntegrationTestsFinished, allIntegrationTestsPassed := a.determineIfAllRequiredIntegrationTestsFinishedAndPassed(policy, integrationTestScenarios, testStatuses)
+	for _, quorum := range quorums {
+		a.logger.Info("Evaluated the quorum of integration test scenarios", "quorum", quorum.String(), "verdict", quorum.Verdict)
+		// a quorum is finished as soon as enough of its scenarios passed, or too many of them failed
+		allIntegrationTestsFinished = allIntegrationTestsFinished && quorum.Verdict != ""
+		allIntegrationTestsPassed = allIntegrationTestsPassed && quorum.Verdict == gitops.GatingDecisionPassed
+	}
 	if err != nil {
 		a.logger.Error(err, "Failed to determine outcomes for Integration Tests",
 			"snapshot.Name", a.snapshot.Name)
@@ -150,6 +187,19 @@ func (a *Adapter) EnsureSnapshotFinishedAllTests() (controller.OperationResult,
 		a.logger.Info("Not all required Integration PipelineRuns finished",
 			"snapshot.Name", a.snapshot.Name)
 
+		// the progress is shown in the integration status condition until the Snapshot is re-evaluated, see
+		// EnsureIncompleteSnapshotReevaluated
+		progressMessage := getIntegrationTestsProgressMessage(integrationTestScenarios, testStatuses)
+		updated, err := gitops.UpdateSnapshotIntegrationStatusProgress(a.context, a.client, a.snapshot, progressMessage)
+		if err != nil {
+			a.logger.Error(err, "Failed to Update Snapshot AppStudioIntegrationStatus status")
+			return controller.RequeueWithError(err)
+		}
+		if updated {
+			a.logger.LogAuditEvent("Snapshot integration status condition updated with the progress of the integration tests",
+				a.snapshot, helpers.LogActionUpdate, "message", progressMessage)
+		}
+
 		// If for the snapshot there is an IntegrationTestScenario that is not triggered, it will add run labebl to snapshot
 		integrationTestScenarioNotTriggered := a.findUntriggeredIntegrationTestFromStatus(integrationTestScenarios, testStatuses)
 		if integrationTestScenarioNotTriggered != "" {
@@ -213,6 +263,8 @@ func (a *Adapter) EnsureSnapshotFinishedAllTests() (controller.OperationResult,
 				}
 				a.logger.LogAuditEvent("Snapshot integration status condition marked as invalid, the global component list has changed in the meantime",
 					a.snapshot, helpers.LogActionUpdate)
+				a.recorder.Eventf(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotSupersededEventReason),
+					"Snapshot superseded by composite Snapshot %s since the global component list has changed in the meantime", compositeSnapshot.Name)
 			}
 			return controller.ContinueProcessing()
 		}
@@ -222,55 +274,364 @@ func (a *Adapter) EnsureSnapshotFinishedAllTests() (controller.OperationResult,
 	// This updates the Snapshot resource on the cluster
 	if allIntegrationTestsPassed {
 		if !gitops.IsSnapshotMarkedAsPassed(a.snapshot) {
-			err = gitops.MarkSnapshotAsPassed(a.context, a.client, a.snapshot, "All Integration Pipeline tests passed")
+			message := withQuorumsRationale("All Integration Pipeline tests passed", quorums)
+			err = a.recordGatingDecision(gitops.GatingDecisionPassed, integrationTestScenarios, testStatuses, quorums, message)
+			if err != nil {
+				return controller.RequeueWithError(err)
+			}
+			err = gitops.MarkSnapshotAsPassed(a.context, a.client, a.snapshot, message)
 			if err != nil {
 				a.logger.Error(err, "Failed to Update Snapshot AppStudioTestSucceeded status")
 				return controller.RequeueWithError(err)
 			}
 			a.logger.LogAuditEvent(fmt.Sprintf("Snapshot integration status condition marked as passed, all of %d required Integration PipelineRuns succeeded", len(*integrationTestScenarios)),
 				a.snapshot, helpers.LogActionUpdate)
+			a.recorder.Eventf(a.snapshot, corev1.EventTypeNormal, string(reasons.SnapshotPassedEventReason),
+				"All of %d required integration tests passed", len(*integrationTestScenarios))
 		}
 	} else {
 		if !gitops.IsSnapshotMarkedAsFailed(a.snapshot) {
-			err = gitops.MarkSnapshotAsFailed(a.context, a.client, a.snapshot, "Some Integration pipeline tests failed")
+			message := withQuorumsRationale("Some Integration pipeline tests failed", quorums)
+			err = a.recordGatingDecision(gitops.GatingDecisionFailed, integrationTestScenarios, testStatuses, quorums, message)
+			if err != nil {
+				return controller.RequeueWithError(err)
+			}
+			err = gitops.MarkSnapshotAsFailed(a.context, a.client, a.snapshot, message)
 			if err != nil {
 				a.logger.Error(err, "Failed to Update Snapshot AppStudioTestSucceeded status")
 				return controller.RequeueWithError(err)
 			}
 			a.logger.LogAuditEvent("Snapshot integration status condition marked as failed, some tests within Integration PipelineRuns failed",
 				a.snapshot, helpers.LogActionUpdate)
+			a.recorder.Event(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotFailedEventReason),
+				"Some required integration tests failed")
 		}
 	}
 
 	return controller.ContinueProcessing()
 }
 
+// EnsureGateResultNotified will ensure that the notification sinks configured for the namespace, if there are any,
+// are notified once the Snapshot passed or failed its required integration tests. The delivery is retried after
+// notification.RetryInterval when some sinks fail, without returning their error.
+func (a *Adapter) EnsureGateResultNotified() (controller.OperationResult, error) {
+	var verdict, eventType, message string
+	switch {
+	case gitops.IsSnapshotMarkedAsPassed(a.snapshot):
+		verdict, eventType, message = gitops.GatingDecisionPassed, notification.EventGatePassed, "all required integration tests passed"
+	case gitops.IsSnapshotMarkedAsTimedOut(a.snapshot):
+		verdict, eventType, message = gitops.GatingDecisionFailed, notification.EventGateFailed, "the testing deadline passed before all required integration tests finished"
+	case gitops.IsSnapshotMarkedAsFailed(a.snapshot):
+		verdict, eventType, message = gitops.GatingDecisionFailed, notification.EventGateFailed, "some required integration tests failed"
+	default:
+		return controller.ContinueProcessing()
+	}
+	if metadata.HasAnnotationWithValue(a.snapshot, notification.GateNotifiedAnnotation, verdict) {
+		return controller.ContinueProcessing()
+	}
+
+	sinks, err := notification.LoadSinks(a.context, a.client, a.snapshot.Namespace)
+	if notification.IsInvalidConfiguration(err) {
+		// a misconfigured secret won't be fixed by retrying, so don't block the snapshot on it
+		a.logger.Error(err, "Failed to configure the notification sinks, skipping the notification")
+		return controller.ContinueProcessing()
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to load the notification sinks", "secret.Name", notification.SecretName)
+		return controller.RequeueWithError(err)
+	}
+	if len(sinks) == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		a.logger.Error(err, "Failed to prepare the gate result notification")
+		return controller.RequeueWithError(err)
+	}
+	event := notification.NewSnapshotEvent(eventType, a.snapshot)
+	event.Verdict = verdict
+	event.Message = message
+	event.Scenarios = notification.NewScenarioResults(testStatuses, a.snapshot.Namespace, a.logger.Logger)
+	// the sinks which delivered the gate result are recorded, so that only the failed ones are retried
+	patch := client.MergeFrom(a.snapshot.DeepCopy())
+	previousDeliveries := a.snapshot.GetAnnotations()[notification.DeliveriesAnnotation]
+	errForNotification := notification.SendOnce(a.context, a.snapshot, sinks, event)
+	if a.snapshot.GetAnnotations()[notification.DeliveriesAnnotation] != previousDeliveries {
+		err = a.client.Patch(a.context, a.snapshot, patch)
+		if err != nil && !errors.IsNotFound(err) {
+			a.logger.Error(err, "Failed to annotate the snapshot with the sinks which delivered the gate result")
+			return controller.RequeueWithError(err)
+		}
+	}
+	if errForNotification != nil {
+		a.logger.Error(errForNotification, "Failed to notify about the gate result of the snapshot, retrying later",
+			"requeueAfter", notification.RetryInterval.String())
+		return controller.RequeueAfter(notification.RetryInterval, nil)
+	}
+
+	err = helpers.ApplyMetadata(a.context, a.client, a.snapshot, nil, map[string]string{notification.GateNotifiedAnnotation: verdict})
+	if err != nil && !errors.IsNotFound(err) {
+		a.logger.Error(err, "Failed to annotate the snapshot with the notified gate result")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Notified about the gate result of the snapshot", a.snapshot, helpers.LogActionUpdate,
+		"verdict", verdict,
+		"sinks", len(sinks))
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureSnapshotTestingDeadlineEnforced will ensure that the Snapshot still awaiting the results of its integration
+// tests once its testing deadline passed is marked as failed with the TimedOut reason, so that it doesn't wait forever
+// when an integration PipelineRun is lost. The awaited tests are marked as errored in the Snapshot test status.
+func (a *Adapter) EnsureSnapshotTestingDeadlineEnforced() (controller.OperationResult, error) {
+	if !gitops.IsSnapshotAwaitingTests(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	deadline, err := a.getSnapshotTestingDeadline()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	// the Snapshot is requeued once its deadline passes by EnsureIncompleteSnapshotReevaluated
+	if deadline == 0 || time.Since(a.snapshot.GetCreationTimestamp().Time) < deadline {
+		return controller.ContinueProcessing()
+	}
+
+	message := fmt.Sprintf("The testing deadline of %s passed before all required integration tests finished", deadline)
+	err = gitops.MarkSnapshotIntegrationStatusAsFinished(a.context, a.client, a.snapshot, message)
+	if err != nil {
+		a.logger.Error(err, "Failed to Update Snapshot AppStudioIntegrationStatus status")
+		return controller.RequeueWithError(err)
+	}
+	err = gitops.MarkSnapshotAsTimedOut(a.context, a.client, a.snapshot, message)
+	if err != nil {
+		a.logger.Error(err, "Failed to Update Snapshot AppStudioTestSucceeded status")
+		return controller.RequeueWithError(err)
+	}
+	a.logger.LogAuditEvent("Snapshot integration status condition marked as timed out, the testing deadline passed",
+		a.snapshot, helpers.LogActionUpdate,
+		"deadline", deadline.String())
+	a.recorder.Event(a.snapshot, corev1.EventTypeWarning, string(reasons.SnapshotTimedOutEventReason), message)
+
+	// the updated test status triggers the report of the timed out tests to the git provider
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(a.snapshot)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	for _, detail := range testStatuses.GetStatuses() {
+		if !detail.Status.IsFinal() {
+			testStatuses.UpdateTestStatusIfChanged(detail.ScenarioName, intgteststat.IntegrationTestStatusTestError,
+				fmt.Sprintf("The testing deadline of %s of the snapshot passed before the test finished", deadline))
+		}
+	}
+	err = gitops.WriteIntegrationTestStatusesIntoSnapshot(a.context, a.snapshot, testStatuses, a.client)
+	if err != nil {
+		a.logger.Error(err, "Failed to mark the awaited integration tests of the timed out snapshot as errored")
+		return controller.RequeueWithError(err)
+	}
+
+	integrationTestScenarios, err := a.getRequiredIntegrationTestScenarios()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	err = a.writeDecisionExplanation(gitops.NewGatingDecision(gitops.GatingDecisionFailed, scenarioNames(integrationTestScenarios), testStatuses, message), testStatuses)
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+
+	return controller.ContinueProcessing()
+}
+
+// EnsureIncompleteSnapshotReevaluated will ensure that the Snapshot still awaiting the results of its integration
+// tests is requeued to be re-evaluated periodically, so that it recovers from the missed events of its integration
+// PipelineRuns and its testing deadline is enforced on time. It stops the chain so it has to be the last operation.
+func (a *Adapter) EnsureIncompleteSnapshotReevaluated() (controller.OperationResult, error) {
+	if !gitops.IsSnapshotAwaitingTests(a.snapshot) {
+		return controller.ContinueProcessing()
+	}
+
+	interval, err := getSnapshotReevaluationInterval()
+	if err != nil {
+		a.logger.Error(err, "Failed to get the re-evaluation interval of the snapshots, using the default one")
+	}
+	deadline, err := a.getSnapshotTestingDeadline()
+	if err != nil {
+		return controller.RequeueWithError(err)
+	}
+	if deadline > 0 {
+		untilDeadline := deadline - time.Since(a.snapshot.GetCreationTimestamp().Time)
+		if untilDeadline > 0 && (interval == 0 || untilDeadline < interval) {
+			interval = untilDeadline
+		}
+	}
+	if interval == 0 {
+		return controller.ContinueProcessing()
+	}
+
+	a.logger.Info("Snapshot is still awaiting the results of its integration tests, requeueing it to be re-evaluated",
+		"requeueAfter", interval.String())
+	return controller.RequeueAfter(interval, nil)
+}
+
+// getSnapshotTestingDeadline returns the testing deadline of the Snapshot set through the IntegrationPolicy of its
+// application or for all applications, 0 is returned when it has none.
+func (a *Adapter) getSnapshotTestingDeadline() (time.Duration, error) {
+	policy, err := a.loader.GetIntegrationPolicyForApplication(a.context, a.client, a.application)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the IntegrationPolicy of the application")
+		return 0, err
+	}
+	deadline, err := gitops.GetSnapshotTestingDeadline(policy)
+	if err != nil {
+		a.logger.Error(err, "Failed to get the testing deadline of the snapshot, not enforcing it")
+	}
+
+	return deadline, nil
+}
+
+// getSnapshotReevaluationInterval returns how often the Snapshots still awaiting the results of their integration
+// tests are re-evaluated, as set through the SnapshotReevaluationIntervalEnvVar, or DefaultSnapshotReevaluationInterval
+// when it isn't set. 0 is returned when the Snapshots aren't re-evaluated.
+func getSnapshotReevaluationInterval() (time.Duration, error) {
+	value := os.Getenv(SnapshotReevaluationIntervalEnvVar)
+	if value == "" {
+		return DefaultSnapshotReevaluationInterval, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return DefaultSnapshotReevaluationInterval, fmt.Errorf("invalid re-evaluation interval of the snapshots %q, a non-negative duration is expected", value)
+	}
+	return interval, nil
+}
+
+// recordGatingDecision records the gating decision made for the Snapshot in its audit trail.
+func (a *Adapter) recordGatingDecision(verdict string, integrationTestScenarios *[]v1beta2.IntegrationTestScenario, testStatuses *intgteststat.SnapshotIntegrationTestStatuses, quorums []gitops.GatingDecisionQuorum, message string) error {
+
+	decision := gitops.NewGatingDecision(verdict, scenarioNames(integrationTestScenarios), testStatuses, message)
+	decision.Quorums = quorums
+	err := gitops.RecordGatingDecision(a.context, a.client, a.snapshot, decision)
+	if err != nil {
+		a.logger.Error(err, "Failed to record the gating decision for the Snapshot", "verdict", verdict)
+		return err
+	}
+	a.logger.LogAuditEvent("Recorded gating decision for the Snapshot", a.snapshot, helpers.LogActionUpdate,
+		"verdict", decision.Verdict,
+		"requiredScenarios", decision.RequiredScenarios)
+
+	return a.writeDecisionExplanation(decision, testStatuses)
+}
+
+// getRequiredIntegrationTestScenarios returns the IntegrationTestScenarios of the application which gate the Snapshot,
+// with their matrix expanded and the Enterprise Contract scenario added when the application opted into it.
+func (a *Adapter) getRequiredIntegrationTestScenarios() (*[]v1beta2.IntegrationTestScenario, error) {
+	integrationTestScenarios, err := a.loader.GetRequiredIntegrationTestScenariosForApplication(a.context, a.client, a.application)
+	if err != nil {
+		return nil, err
+	}
+	integrationTestScenarios = gitops.FilterIntegrationTestScenariosWithContext(integrationTestScenarios, a.snapshot)
+	integrationTestScenarios, err = a.filterScenariosWithComponentSelector(integrationTestScenarios)
+	if err != nil {
+		return nil, err
+	}
+	// every matrix cell of the scenarios is required to pass
+	integrationTestScenarios = gitops.ExpandIntegrationTestScenarioMatrix(integrationTestScenarios)
+	// the verdict of the Enterprise Contract gate is part of the overall decision when the application opted into it
+	return gitops.AddEnterpriseContractScenario(a.application, integrationTestScenarios), nil
+}
+
+// scenarioNames returns the names of the given IntegrationTestScenarios.
+func scenarioNames(integrationTestScenarios *[]v1beta2.IntegrationTestScenario) []string {
+	names := make([]string, 0, len(*integrationTestScenarios))
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		names = append(names, integrationTestScenario.Name)
+	}
+	return names
+}
+
+// writeDecisionExplanation writes the explanation of the given gating decision into the Snapshot annotation.
+func (a *Adapter) writeDecisionExplanation(decision gitops.GatingDecision, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) error {
+	err := gitops.WriteDecisionExplanation(a.context, a.client, a.snapshot, gitops.NewDecisionExplanation(decision, testStatuses))
+	if err != nil {
+		a.logger.Error(err, "Failed to write the decision explanation for the Snapshot", "verdict", decision.Verdict)
+		return err
+	}
+
+	return nil
+}
+
 // determineIfAllRequiredIntegrationTestsFinishedAndPassed checks if all Integration tests finished and passed for the given
-// list of integrationTestScenarios.
-func (a *Adapter) determineIfAllRequiredIntegrationTestsFinishedAndPassed(integrationTestScenarios *[]v1beta2.IntegrationTestScenario, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) (bool, bool) {
+// list of integrationTestScenarios, according to the IntegrationPolicy of the application when there is one.
+func (a *Adapter) determineIfAllRequiredIntegrationTestsFinishedAndPassed(policy *v1beta2.IntegrationPolicy, integrationTestScenarios *[]v1beta2.IntegrationTestScenario, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) (bool, bool) {
 	allIntegrationTestsFinished, allIntegrationTestsPassed := true, true
 	integrationTestsFinished := 0
 	integrationTestsPassed := 0
+	integrationTestsRequired := 0
 
 	for _, integrationTestScenario := range *integrationTestScenarios {
 		integrationTestScenario := integrationTestScenario // G601
+		if gitops.IsQuorumScenario(policy, &integrationTestScenario) {
+			// the scenarios of the quorums are evaluated together, see gitops.EvaluateScenarioQuorums
+			continue
+		}
 		testDetails, ok := testStatuses.GetScenarioStatus(integrationTestScenario.Name)
+		if ok && testDetails.ObserveOnly {
+			// the test was started in the observe-only runs of the scenario, before it started gating the Snapshots
+			continue
+		}
+		integrationTestsRequired++
 		if !ok || !testDetails.Status.IsFinal() {
 			allIntegrationTestsFinished = false
 		} else {
 			integrationTestsFinished++
 		}
-		if ok && testDetails.Status != intgteststat.IntegrationTestStatusTestPassed {
+		if ok && !gitops.IsTestStatusPassedForPolicy(policy, testDetails.Status) {
 			allIntegrationTestsPassed = false
 		} else {
 			integrationTestsPassed++
 		}
 
 	}
-	a.logger.Info(fmt.Sprintf("%[1]d out of %[3]d required integration tests finished, %[2]d out of %[3]d required integration tests passed", integrationTestsFinished, integrationTestsPassed, len(*integrationTestScenarios)))
+	a.logger.Info(fmt.Sprintf("%[1]d out of %[3]d required integration tests finished, %[2]d out of %[3]d required integration tests passed", integrationTestsFinished, integrationTestsPassed, integrationTestsRequired))
 	return allIntegrationTestsFinished, allIntegrationTestsPassed
 }
 
+// getIntegrationTestsProgressMessage returns the message describing how many of the required integration tests of the
+// Snapshot finished and which ones it is still awaiting. The tests started before their scenario was gating the
+// Snapshots aren't awaited.
+func getIntegrationTestsProgressMessage(integrationTestScenarios *[]v1beta2.IntegrationTestScenario, testStatuses *intgteststat.SnapshotIntegrationTestStatuses) string {
+	required := 0
+	awaited := []string{}
+	for _, integrationTestScenario := range *integrationTestScenarios {
+		testDetails, ok := testStatuses.GetScenarioStatus(integrationTestScenario.Name)
+		if ok && testDetails.ObserveOnly {
+			continue
+		}
+		required++
+		if !ok || !testDetails.Status.IsFinal() {
+			awaited = append(awaited, integrationTestScenario.Name)
+		}
+	}
+	sort.Strings(awaited)
+
+	message := fmt.Sprintf("%d out of %d required integration tests finished", required-len(awaited), required)
+	if len(awaited) > 0 {
+		message += ", awaiting " + strings.Join(awaited, ", ")
+	}
+	return message
+}
+
+// withQuorumsRationale appends the rationale of the outcomes of the given quorums to the message of a gating decision.
+func withQuorumsRationale(message string, quorums []gitops.GatingDecisionQuorum) string {
+	for _, quorum := range quorums {
+		message += "; " + quorum.String()
+	}
+
+	return message
+}
+
 // prepareCompositeSnapshot prepares the Composite Snapshot for a given application,
 // component, containerImage and containerSource. In case the Snapshot can't be created, an error will be returned.
 func (a *Adapter) prepareCompositeSnapshot(application *applicationapiv1alpha1.Application, component *applicationapiv1alpha1.Component, newContainerImage string, newComponentSource *applicationapiv1alpha1.ComponentSource) (*applicationapiv1alpha1.Snapshot, error) {
@@ -314,11 +675,11 @@ func (a *Adapter) createCompositeSnapshotsIfConflictExists(application *applicat
 
 	// Create the new composite snapshot if it doesn't exist already
 	if !gitops.CompareSnapshots(compositeSnapshot, testedSnapshot) {
-		allSnapshots, err := a.loader.GetAllSnapshots(a.context, a.client, application)
+		matchingSnapshots, err := a.loader.GetAllSnapshotsWithContentHash(a.context, a.client, application, gitops.GetSnapshotContentHash(compositeSnapshot))
 		if err != nil {
 			return nil, err
 		}
-		existingCompositeSnapshot := gitops.FindMatchingSnapshot(a.application, allSnapshots, compositeSnapshot)
+		existingCompositeSnapshot := gitops.FindMatchingSnapshot(a.application, matchingSnapshots, compositeSnapshot)
 
 		if existingCompositeSnapshot != nil {
 			a.logger.Info("Found existing composite Snapshot",
@@ -331,6 +692,9 @@ func (a *Adapter) createCompositeSnapshotsIfConflictExists(application *applicat
 				return nil, err
 			}
 			go metrics.RegisterNewSnapshot()
+			go metrics.RegisterSnapshotCreated(compositeSnapshot.Namespace, compositeSnapshot.Spec.Application)
+			a.recorder.Eventf(compositeSnapshot, corev1.EventTypeNormal, string(reasons.SnapshotCreatedEventReason),
+				"Composite Snapshot created since the global component list changed while testing Snapshot %s", testedSnapshot.Name)
 			a.logger.LogAuditEvent("CompositeSnapshot created", compositeSnapshot, helpers.LogActionAdd,
 				"snapshot.Spec.Components", compositeSnapshot.Spec.Components)
 			return compositeSnapshot, nil
@@ -372,3 +736,18 @@ func (a *Adapter) findUntriggeredIntegrationTestFromStatus(integrationTestScenar
 	}
 	return ""
 }
+
+// filterScenariosWithComponentSelector returns the IntegrationTestScenarios whose ComponentSelector selects the component
+// the Snapshot was created for. The Component is only loaded when some of the scenarios select components by their labels.
+func (a *Adapter) filterScenariosWithComponentSelector(scenarios *[]v1beta2.IntegrationTestScenario) (*[]v1beta2.IntegrationTestScenario, error) {
+	var component *applicationapiv1alpha1.Component
+	if gitops.IsComponentSnapshot(a.snapshot) && gitops.HasComponentLabelSelector(scenarios) {
+		var err error
+		component, err = a.loader.GetComponentFromSnapshot(a.context, a.client, a.snapshot)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the Component of the Snapshot to select its IntegrationTestScenarios: %w", err)
+		}
+	}
+
+	return gitops.FilterIntegrationTestScenariosWithComponentSelector(scenarios, a.snapshot, component), nil
+}
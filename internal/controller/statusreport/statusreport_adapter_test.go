@@ -18,7 +18,10 @@ package statusreport
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"time"
 
@@ -26,21 +29,27 @@ import (
 	"github.com/tonglil/buflogr"
 	"go.uber.org/mock/gomock"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/konflux-ci/integration-service/git/github"
 	"github.com/konflux-ci/integration-service/loader"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
 	"github.com/konflux-ci/integration-service/status"
 	toolkit "github.com/konflux-ci/operator-toolkit/loader"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -238,7 +247,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 
 	When("adapter is created", func() {
 		It("can create a new Adapter instance", func() {
-			Expect(reflect.TypeOf(NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient))).To(Equal(reflect.TypeOf(&Adapter{})))
+			Expect(reflect.TypeOf(NewAdapter(ctx, hasSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{}))).To(Equal(reflect.TypeOf(&Adapter{})))
 		})
 
 		It("ensures the statusReport is called", func() {
@@ -254,7 +263,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			mockStatus.EXPECT().ReportSnapshotStatus(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 
 			mockScenarios := []v1beta2.IntegrationTestScenario{}
-			adapter = NewAdapter(ctx, hasPRSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasPRSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.status = mockStatus
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
@@ -277,8 +286,31 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 		})
 	})
 
+	When("the git provider rate limits the status report", func() {
+		It("requeues the report once the rate limit resets", func() {
+			ctrl := gomock.NewController(GinkgoT())
+			mockReporter := status.NewMockReporterInterface(ctrl)
+			mockStatus := status.NewMockStatusInterface(ctrl)
+
+			mockReporter.EXPECT().GetReporterName().Return("mocked_reporter")
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Return(mockReporter)
+			mockStatus.EXPECT().ReportSnapshotStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(fmt.Errorf("failed to update status: %w", &github.RateLimitError{StatusCode: 429, RetryAfter: 30 * time.Second})).Times(1)
+
+			adapter = NewAdapter(ctx, hasPRSnapshot, hasApp, logger, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
+			adapter.status = mockStatus
+			result, err := adapter.EnsureSnapshotTestStatusReportedToGitProvider()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(30 * time.Second))
+		})
+	})
+
 	When("New Adapter is created for a push-type Snapshot that passed all tests", func() {
+		var recorder *record.FakeRecorder
+
 		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
 			buf = bytes.Buffer{}
 			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
 
@@ -288,7 +320,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			err = gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)
 			Expect(err).To(BeNil())
 
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, recorder)
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -314,12 +346,29 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
 				},
 				{
-					ContextKey: loader.AllSnapshotsContextKey,
+					ContextKey: loader.SnapshotsWithContentHashContextKey,
 					Resource:   []applicationapiv1alpha1.Snapshot{},
 				},
 			})
 		})
 
+		It("ensures the test status of the push Snapshot is reported only when it was requested", func() {
+			ctrl := gomock.NewController(GinkgoT())
+			mockReporter := status.NewMockReporterInterface(ctrl)
+			mockStatus := status.NewMockStatusInterface(ctrl)
+			mockReporter.EXPECT().GetReporterName().Return("mocked_reporter").AnyTimes()
+			mockStatus.EXPECT().GetReporter(gomock.Any()).Return(mockReporter).Times(1)
+			mockStatus.EXPECT().ReportSnapshotStatus(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+			adapter.status = mockStatus
+
+			result, err := adapter.EnsureSnapshotTestStatusReportedToGitProvider()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			Expect(gitops.RequestSnapshotStatusReport(ctx, k8sClient, hasSnapshot)).To(Succeed())
+			result, err = adapter.EnsureSnapshotTestStatusReportedToGitProvider()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+		})
+
 		It("ensures Snapshot passed all tests", func() {
 			result, err := adapter.EnsureSnapshotFinishedAllTests()
 			Expect(!result.CancelRequest && err == nil).To(BeTrue())
@@ -334,6 +383,209 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
 			expectedLogEntry = "Snapshot integration status condition marked as passed, all of 1 required Integration PipelineRuns succeeded"
 			Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
+			Expect(recorder.Events).To(Receive(Equal(fmt.Sprintf("%s %s All of 1 required integration tests passed",
+				corev1.EventTypeNormal, reasons.SnapshotPassedEventReason))))
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decisions).To(HaveLen(1))
+			Expect(decisions[0].Verdict).To(Equal(gitops.GatingDecisionPassed))
+			Expect(decisions[0].RequiredScenarios).To(ConsistOf(integrationTestScenario.Name))
+
+			explanation, err := gitops.GetDecisionExplanationFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(explanation).NotTo(BeNil())
+			Expect(explanation.Verdict).To(Equal(gitops.GatingDecisionPassed))
+			Expect(explanation.Scenarios).NotTo(BeEmpty())
+			Expect(explanation.Scenarios[0].Name).To(Equal(integrationTestScenario.Name))
+			Expect(explanation.Scenarios[0].Gating).To(BeTrue())
+			Expect(explanation.Scenarios[0].Status).To(Equal(intgteststat.IntegrationTestStatusTestPassed.String()))
+		})
+
+		It("doesn't gate the Snapshot on the tests of the observe-only runs", func() {
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			statuses.UpdateTestStatusIfChanged(integrationTestScenario.Name, intgteststat.IntegrationTestStatusTestFail, "failed")
+			Expect(statuses.UpdateTestObserveOnly(integrationTestScenario.Name, true)).To(Succeed())
+
+			allFinished, allPassed := adapter.determineIfAllRequiredIntegrationTestsFinishedAndPassed(nil,
+				&[]v1beta2.IntegrationTestScenario{*integrationTestScenario}, statuses)
+			Expect(allFinished).To(BeTrue())
+			Expect(allPassed).To(BeTrue())
+
+			Expect(statuses.UpdateTestObserveOnly(integrationTestScenario.Name, false)).To(Succeed())
+			allFinished, allPassed = adapter.determineIfAllRequiredIntegrationTestsFinishedAndPassed(nil,
+				&[]v1beta2.IntegrationTestScenario{*integrationTestScenario}, statuses)
+			Expect(allFinished).To(BeTrue())
+			Expect(allPassed).To(BeFalse())
+		})
+
+		It("describes the progress of the required integration tests", func() {
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			statuses.UpdateTestStatusIfChanged("scenario-observed", intgteststat.IntegrationTestStatusInProgress, "")
+			Expect(statuses.UpdateTestObserveOnly("scenario-observed", true)).To(Succeed())
+			statuses.UpdateTestStatusIfChanged("scenario-running", intgteststat.IntegrationTestStatusInProgress, "")
+
+			scenarios := []v1beta2.IntegrationTestScenario{*integrationTestScenario}
+			for _, name := range []string{"scenario-running", "scenario-observed", "scenario-pending"} {
+				scenario := integrationTestScenario.DeepCopy()
+				scenario.Name = name
+				scenarios = append(scenarios, *scenario)
+			}
+			Expect(getIntegrationTestsProgressMessage(&scenarios, statuses)).To(Equal(
+				"1 out of 3 required integration tests finished, awaiting scenario-pending, scenario-running"))
+			Expect(getIntegrationTestsProgressMessage(&[]v1beta2.IntegrationTestScenario{*integrationTestScenario}, statuses)).To(Equal(
+				"1 out of 1 required integration tests finished"))
+		})
+
+		It("ensures the verdict of the Enterprise Contract gate is part of the decision when it's enabled", func() {
+			application := hasApp.DeepCopy()
+			application.Annotations = map[string]string{gitops.EnterpriseContractPolicyAnnotation: "enterprise-contract-service/default"}
+			adapter.application = application
+
+			result, err := adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition)).To(BeNil())
+			condition := meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioIntegrationStatusCondition)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+			Expect(condition.Reason).To(Equal(reasons.AppStudioIntegrationStatusInProgress))
+			Expect(condition.Message).To(Equal("1 out of 2 required integration tests finished, awaiting " + gitops.EnterpriseContractScenarioName))
+			Expect(buf.String()).Should(ContainSubstring("Snapshot integration status condition updated with the progress of the integration tests"))
+
+			// the unchanged progress isn't applied again
+			resourceVersion := hasSnapshot.ResourceVersion
+			buf.Reset()
+			result, err = adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(buf.String()).ShouldNot(ContainSubstring("Snapshot integration status condition updated with the progress of the integration tests"))
+			Expect(hasSnapshot.ResourceVersion).To(Equal(resourceVersion))
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			statuses.UpdateTestStatusIfChanged(gitops.EnterpriseContractScenarioName, intgteststat.IntegrationTestStatusTestFail, "policy violations found")
+			Expect(gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)).To(Succeed())
+
+			result, err = adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(hasSnapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition)).To(BeTrue())
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decisions).To(HaveLen(1))
+			Expect(decisions[0].Verdict).To(Equal(gitops.GatingDecisionFailed))
+			Expect(decisions[0].RequiredScenarios).To(ConsistOf(integrationTestScenario.Name, gitops.EnterpriseContractScenarioName))
+		})
+
+		It("ensures Snapshot with tests passed with warnings fails when the IntegrationPolicy blocks on warnings", func() {
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			statuses.UpdateTestStatusIfChanged(integrationTestScenario.Name, intgteststat.IntegrationTestStatusTestWarning, "testDetails")
+			Expect(gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)).To(Succeed())
+
+			policy := &v1beta2.IntegrationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "integrationpolicy-sample",
+					Namespace: "default",
+				},
+				Spec: v1beta2.IntegrationPolicySpec{
+					Application:     hasApp.Name,
+					BlockOnWarnings: true,
+				},
+			}
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.ComponentContextKey,
+					Resource:   hasComp,
+				},
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+				{
+					ContextKey: loader.SnapshotsWithContentHashContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{},
+				},
+				{
+					ContextKey: loader.IntegrationPolicyContextKey,
+					Resource:   policy,
+				},
+			})
+
+			result, err := adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(hasSnapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition)).To(BeTrue())
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decisions).To(HaveLen(1))
+			Expect(decisions[0].Verdict).To(Equal(gitops.GatingDecisionFailed))
+		})
+
+		It("ensures Snapshot passes when enough scenarios of a quorum of the IntegrationPolicy passed", func() {
+			linuxScenario := integrationTestScenario.DeepCopy()
+			linuxScenario.Name = "quorum-linux"
+			windowsScenario := integrationTestScenario.DeepCopy()
+			windowsScenario.Name = "quorum-windows"
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			statuses.UpdateTestStatusIfChanged(linuxScenario.Name, intgteststat.IntegrationTestStatusTestPassed, "testDetails")
+			statuses.UpdateTestStatusIfChanged(windowsScenario.Name, intgteststat.IntegrationTestStatusTestFail, "testDetails")
+			Expect(gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)).To(Succeed())
+
+			policy := &v1beta2.IntegrationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "integrationpolicy-sample",
+					Namespace: "default",
+				},
+				Spec: v1beta2.IntegrationPolicySpec{
+					Application: hasApp.Name,
+					Quorums: []v1beta2.ScenarioQuorum{
+						{Name: "platforms", Scenarios: []string{linuxScenario.Name, windowsScenario.Name}, MinPassed: 1},
+					},
+				},
+			}
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.ComponentContextKey,
+					Resource:   hasComp,
+				},
+				{
+					ContextKey: loader.ApplicationComponentsContextKey,
+					Resource:   []applicationapiv1alpha1.Component{*hasComp},
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario, *linuxScenario, *windowsScenario},
+				},
+				{
+					ContextKey: loader.SnapshotsWithContentHashContextKey,
+					Resource:   []applicationapiv1alpha1.Snapshot{},
+				},
+				{
+					ContextKey: loader.IntegrationPolicyContextKey,
+					Resource:   policy,
+				},
+			})
+
+			result, err := adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(hasSnapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition)).To(BeTrue())
+			condition := meta.FindStatusCondition(hasSnapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition)
+			Expect(condition.Message).To(ContainSubstring("quorum platforms: 1 of 2 scenarios passed, 1 required"))
+
+			decisions, err := gitops.GetGatingDecisionsFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decisions).To(HaveLen(1))
+			Expect(decisions[0].Verdict).To(Equal(gitops.GatingDecisionPassed))
+			Expect(decisions[0].Quorums).To(HaveLen(1))
+			Expect(decisions[0].Quorums[0].PassedScenarios).To(Equal([]string{linuxScenario.Name}))
+			Expect(decisions[0].Quorums[0].FailedScenarios).To(Equal([]string{windowsScenario.Name}))
 		})
 
 		It("testing function findUntriggeredIntegrationTestFromStatus ", func() {
@@ -405,7 +657,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			err = gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)
 			Expect(err).To(BeNil())
 
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -446,6 +698,211 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			expectedLogEntry = "Snapshot integration status condition marked as failed, some tests within Integration PipelineRuns failed"
 			Expect(buf.String()).Should(ContainSubstring(expectedLogEntry))
 		})
+
+		It("ensures the gate result isn't notified when the namespace has no notification secret", func() {
+			result, err := adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			result, err = adapter.EnsureGateResultNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(hasSnapshot.Annotations).NotTo(HaveKey(notification.GateNotifiedAnnotation))
+		})
+
+		It("ensures the failed gate result is notified to Slack only once", func() {
+			messages := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				message := map[string]string{}
+				Expect(json.NewDecoder(r.Body).Decode(&message)).To(Succeed())
+				messages = append(messages, message["text"])
+			}))
+			defer server.Close()
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      notification.SecretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					notification.SecretSlackWebhookURLKey: []byte(server.URL),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, secret)).Should(Succeed())
+			}()
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+			}, time.Second*10).Should(Succeed())
+
+			result, err := adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			result, err = adapter.EnsureGateResultNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0]).To(ContainSubstring(":x: Snapshot *" + hasSnapshot.Name + "* of application *application-sample*"))
+			Expect(messages[0]).To(ContainSubstring("some required integration tests failed"))
+			Expect(messages[0]).To(ContainSubstring("*" + integrationTestScenario.Name + "*: TestFail"))
+			Expect(hasSnapshot.Annotations).To(HaveKeyWithValue(notification.GateNotifiedAnnotation, gitops.GatingDecisionFailed))
+			Expect(buf.String()).Should(ContainSubstring("Notified about the gate result of the snapshot"))
+
+			result, err = adapter.EnsureGateResultNotified()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(messages).To(HaveLen(1))
+		})
+
+		It("requeues the notification of the gate result without an error when a sink fails", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      notification.SecretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					notification.SecretSlackWebhookURLKey: []byte(server.URL),
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, secret)).Should(Succeed())
+			}()
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, &corev1.Secret{})
+			}, time.Second*10).Should(Succeed())
+
+			result, err := adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+
+			result, err = adapter.EnsureGateResultNotified()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(notification.RetryInterval))
+			Expect(hasSnapshot.Annotations).NotTo(HaveKey(notification.GateNotifiedAnnotation))
+		})
+	})
+
+	When("New Adapter is created for a push-type Snapshot still awaiting its tests", func() {
+		var (
+			recorder *record.FakeRecorder
+			policy   *v1beta2.IntegrationPolicy
+		)
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+			log := helpers.IntegrationLogger{Logger: buflogr.NewWithBuffer(&buf)}
+			recorder = record.NewFakeRecorder(10)
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			statuses.UpdateTestStatusIfChanged(integrationTestScenario.Name, intgteststat.IntegrationTestStatusInProgress, "Running")
+			err = gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)
+			Expect(err).ToNot(HaveOccurred())
+
+			policy = &v1beta2.IntegrationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+				Spec: v1beta2.IntegrationPolicySpec{
+					Application:     hasApp.Name,
+					TestingDeadline: &metav1.Duration{Duration: time.Hour},
+				},
+			}
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, recorder)
+			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: loader.IntegrationPolicyContextKey,
+					Resource:   policy,
+				},
+				{
+					ContextKey: loader.RequiredIntegrationTestScenariosContextKey,
+					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
+				},
+			})
+		})
+
+		It("ensures the Snapshot is re-evaluated periodically and once its testing deadline passes", func() {
+			result, err := adapter.EnsureSnapshotTestingDeadlineEnforced()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(gitops.HaveAppStudioTestsFinished(hasSnapshot)).To(BeFalse())
+
+			result, err = adapter.EnsureIncompleteSnapshotReevaluated()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(Equal(DefaultSnapshotReevaluationInterval))
+
+			policy.Spec.TestingDeadline = &metav1.Duration{Duration: 5 * time.Minute}
+			result, err = adapter.EnsureIncompleteSnapshotReevaluated()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueRequest).To(BeTrue())
+			Expect(result.RequeueDelay).To(BeNumerically("~", 5*time.Minute, time.Minute))
+			Expect(buf.String()).Should(ContainSubstring("Snapshot is still awaiting the results of its integration tests"))
+
+			// the Snapshots without a deadline aren't requeued when the re-evaluation is disabled
+			policy.Spec.TestingDeadline = nil
+			GinkgoT().Setenv(SnapshotReevaluationIntervalEnvVar, "0")
+			result, err = adapter.EnsureIncompleteSnapshotReevaluated()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+		})
+
+		It("uses the default re-evaluation interval when the configured one is invalid", func() {
+			GinkgoT().Setenv(SnapshotReevaluationIntervalEnvVar, "often")
+			interval, err := getSnapshotReevaluationInterval()
+			Expect(err).To(MatchError(ContainSubstring("invalid re-evaluation interval of the snapshots \"often\"")))
+			Expect(interval).To(Equal(DefaultSnapshotReevaluationInterval))
+
+			GinkgoT().Setenv(SnapshotReevaluationIntervalEnvVar, "2m")
+			Expect(getSnapshotReevaluationInterval()).To(Equal(2 * time.Minute))
+		})
+
+		It("ensures the deadline isn't enforced when the policy disables it", func() {
+			policy.Spec.TestingDeadline = &metav1.Duration{}
+			GinkgoT().Setenv(gitops.SnapshotTestingDeadlineEnvVar, "1ms")
+
+			result, err := adapter.EnsureSnapshotTestingDeadlineEnforced()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(gitops.HaveAppStudioTestsFinished(hasSnapshot)).To(BeFalse())
+		})
+
+		It("ensures the Snapshot is marked as timed out once its testing deadline passed", func() {
+			policy.Spec.TestingDeadline = &metav1.Duration{Duration: time.Millisecond}
+			time.Sleep(10 * time.Millisecond)
+
+			result, err := adapter.EnsureSnapshotTestingDeadlineEnforced()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+			Expect(gitops.IsSnapshotIntegrationStatusMarkedAsFinished(hasSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsFailed(hasSnapshot)).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsTimedOut(hasSnapshot)).To(BeTrue())
+			Expect(buf.String()).Should(ContainSubstring("Snapshot integration status condition marked as timed out, the testing deadline passed"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(string(reasons.SnapshotTimedOutEventReason))))
+
+			statuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			detail, ok := statuses.GetScenarioStatus(integrationTestScenario.Name)
+			Expect(ok).To(BeTrue())
+			Expect(detail.Status).To(Equal(intgteststat.IntegrationTestStatusTestError))
+			Expect(detail.Details).To(ContainSubstring("testing deadline of 1ms"))
+
+			explanation, err := gitops.GetDecisionExplanationFromSnapshot(hasSnapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(explanation).NotTo(BeNil())
+			Expect(explanation.Verdict).To(Equal(gitops.GatingDecisionFailed))
+			Expect(explanation.Reason).To(ContainSubstring("testing deadline of 1ms"))
+			Expect(explanation.Scenarios).NotTo(BeEmpty())
+			Expect(explanation.Scenarios[0].Status).To(Equal(intgteststat.IntegrationTestStatusTestError.String()))
+
+			// the finished Snapshot isn't re-evaluated anymore
+			result, err = adapter.EnsureIncompleteSnapshotReevaluated()
+			Expect(!result.CancelRequest && !result.RequeueRequest && err == nil).To(BeTrue())
+
+			// the timed out Snapshot stays failed when its lost test finishes eventually
+			statuses.UpdateTestStatusIfChanged(integrationTestScenario.Name, intgteststat.IntegrationTestStatusTestPassed, "Passed")
+			Expect(gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)).To(Succeed())
+			result, err = adapter.EnsureSnapshotFinishedAllTests()
+			Expect(!result.CancelRequest && err == nil).To(BeTrue())
+			Expect(gitops.IsSnapshotMarkedAsTimedOut(hasSnapshot)).To(BeTrue())
+		})
 	})
 
 	When("New Adapter is created for a push-type Snapshot that has no tests", func() {
@@ -459,7 +916,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			err = gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)
 			Expect(err).ToNot(HaveOccurred())
 
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -513,7 +970,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 			err = gitops.WriteIntegrationTestStatusesIntoSnapshot(ctx, hasSnapshot, statuses, k8sClient)
 			Expect(err).To(BeNil())
 
-			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient)
+			adapter = NewAdapter(ctx, hasSnapshot, hasApp, log, loader.NewMockLoader(), k8sClient, &record.FakeRecorder{})
 			adapter.context = toolkit.GetMockedContext(ctx, []toolkit.MockData{
 				{
 					ContextKey: loader.ApplicationContextKey,
@@ -536,7 +993,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 					Resource:   []v1beta2.IntegrationTestScenario{*integrationTestScenario},
 				},
 				{
-					ContextKey: loader.AllSnapshotsContextKey,
+					ContextKey: loader.SnapshotsWithContentHashContextKey,
 					Resource:   []applicationapiv1alpha1.Snapshot{},
 				},
 			})
@@ -596,7 +1053,7 @@ var _ = Describe("Snapshot Adapter", Ordered, func() {
 					Resource:   []applicationapiv1alpha1.Component{*hasComp, *hasComp2},
 				},
 				{
-					ContextKey: loader.AllSnapshotsContextKey,
+					ContextKey: loader.SnapshotsWithContentHashContextKey,
 					Resource:   []applicationapiv1alpha1.Snapshot{*compositeSnapshot},
 				},
 			})
@@ -20,33 +20,46 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/cache"
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/loader"
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
 	"github.com/konflux-ci/operator-toolkit/controller"
 	toolkitpredicates "github.com/konflux-ci/operator-toolkit/predicates"
 	toolkitutils "github.com/konflux-ci/operator-toolkit/utils"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Reconciler reconciles an Snapshot object
 type Reconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // NewStatusReportReconciler creates and returns a Reconciler.
-func NewStatusReportReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme) *Reconciler {
+func NewStatusReportReconciler(client client.Client, logger *logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
-		Client: client,
-		Log:    logger.WithName("statusreport"),
-		Scheme: scheme,
+		Client:   client,
+		Log:      logger.WithName("statusreport"),
+		Scheme:   scheme,
+		Recorder: recorder,
 	}
 }
 
@@ -54,11 +67,14 @@ func NewStatusReportReconciler(client client.Client, logger *logr.Logger, scheme
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=snapshots/status,verbs=get
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications/status,verbs=get
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=integrationtestscenarios,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("snapshot", req.NamespacedName)}
+	correlationID := correlation.NewID()
+	logger := helpers.IntegrationLogger{Logger: r.Log.WithValues("snapshot", req.NamespacedName, correlation.LogKey, correlationID)}
 	loader := loader.NewLoader()
 
 	logger.Info("start to process snapshot test status since there is change in annotation test.appstudio.openshift.io/status", "snapshot", req.NamespacedName)
@@ -73,6 +89,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 		return ctrl.Result{}, err
 	}
+	logger.Logger = correlation.WithParentID(logger.Logger, snapshot)
 
 	if toolkitutils.IsObjectRestoredFromBackup(snapshot) {
 		logger.Info("Snapshot restored from backup has been updated, we cannot reconcile it, skipping.")
@@ -86,32 +103,93 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 	logger = logger.WithApp(*application)
 
-	adapter := NewAdapter(ctx, snapshot, application, logger, loader, r.Client)
-	return controller.ReconcileHandler([]controller.Operation{
+	ctx, span := tracing.StartReconcileSpan(ctx, "statusreport", map[string]client.Object{"snapshot": snapshot, "application": application})
+	defer span.End()
+
+	adapterCtx, cancel := operations.WithShutdownDrain(ctx)
+	defer cancel()
+
+	adapter := NewAdapter(adapterCtx, snapshot, application, logger, loader, correlation.NewClient(r.Client, correlationID), correlation.NewRecorder(r.Recorder, correlationID))
+
+	return operations.NewChain("statusreport",
 		adapter.EnsureSnapshotFinishedAllTests,
 		adapter.EnsureSnapshotTestStatusReportedToGitProvider,
-	})
+		adapter.EnsureSnapshotTestingDeadlineEnforced,
+		// the sinks which are unreachable must not block the reporting and the deadline of the Snapshots
+		adapter.EnsureGateResultNotified,
+		adapter.EnsureIncompleteSnapshotReevaluated,
+	).Run(ctx)
 }
 
 // AdapterInterface is an interface defining all the operations that should be defined in an Integration adapter.
 type AdapterInterface interface {
 	EnsureSnapshotTestStatusReportedToGitHub() (controller.OperationResult, error)
 	EnsureSnapshotFinishedAllTests() (controller.OperationResult, error)
+	EnsureGateResultNotified() (controller.OperationResult, error)
+	EnsureSnapshotTestingDeadlineEnforced() (controller.OperationResult, error)
+	EnsureIncompleteSnapshotReevaluated() (controller.OperationResult, error)
 }
 
 // SetupController creates a new Integration controller and adds it to the Manager.
-func SetupController(manager ctrl.Manager, log *logr.Logger) error {
-	return setupControllerWithManager(manager, NewStatusReportReconciler(manager.GetClient(), log, manager.GetScheme()))
+func SetupController(manager ctrl.Manager, log *logr.Logger, eventFilters ...predicate.Predicate) error {
+	return setupControllerWithManager(manager, NewStatusReportReconciler(manager.GetClient(), log, manager.GetScheme(),
+		manager.GetEventRecorderFor(gitops.IntegrationServiceEventRecorderName)), eventFilters...)
+}
+
+// setupCache indexes fields for each of the resources used in the statusreport adapter in those cases where filtering by
+// field is required.
+func setupCache(mgr ctrl.Manager) error {
+	return cache.SetupSnapshotContentHashCache(mgr)
 }
 
 // setupControllerWithManager sets up the controller with the Manager which monitors new Snapshots
-func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler) error {
+func setupControllerWithManager(manager ctrl.Manager, controller *Reconciler, eventFilters ...predicate.Predicate) error {
+	err := setupCache(manager)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(manager).
-		For(&applicationapiv1alpha1.Snapshot{}).
-		WithEventFilter(
-			predicate.And(
-				toolkitpredicates.IgnoreBackups{},
-				gitops.SnapshotTestAnnotationChangePredicate(),
-			)).
+		For(&applicationapiv1alpha1.Snapshot{}, builder.WithPredicates(gitops.SnapshotTestAnnotationChangePredicate())).
+		Watches(&v1beta2.IntegrationTestScenario{}, handler.EnqueueRequestsFromMapFunc(controller.toAwaitingSnapshots),
+			builder.WithPredicates(gitops.IntegrationTestScenarioNoLongerRequiredPredicate())).
+		Watches(&v1beta2.IntegrationPolicy{}, handler.EnqueueRequestsFromMapFunc(controller.toAwaitingSnapshots),
+			builder.WithPredicates(gitops.IntegrationPolicyChangePredicate())).
+		WithEventFilter(toolkitpredicates.IgnoreBackups{}).
+		WithEventFilter(predicate.And(eventFilters...)).
 		Complete(controller)
 }
+
+// toAwaitingSnapshots maps the IntegrationTestScenarios and the IntegrationPolicies to the reconcile requests of the
+// Snapshots of their application which are still awaiting the results of their integration tests, so that the
+// Snapshots are gated again once the set of their required scenarios shrinks.
+func (r *Reconciler) toAwaitingSnapshots(ctx context.Context, obj client.Object) []reconcile.Request {
+	// only the name and namespace of the application are needed to look its Snapshots up
+	application := &applicationapiv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: obj.GetNamespace()}}
+	switch object := obj.(type) {
+	case *v1beta2.IntegrationTestScenario:
+		application.Name = object.Spec.Application
+	case *v1beta2.IntegrationPolicy:
+		application.Name = object.Spec.Application
+	}
+	if application.Name == "" {
+		return nil
+	}
+
+	snapshots, err := loader.NewLoader().GetAllSnapshotsAwaitingTests(ctx, r.Client, application)
+	if err != nil {
+		r.Log.Error(err, "Failed to list the Snapshots awaiting the results of their integration tests",
+			"object", types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, "application", application.Name)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, snapshot := range *snapshots {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: snapshot.Namespace,
+			Name:      snapshot.Name,
+		}})
+	}
+
+	return requests
+}
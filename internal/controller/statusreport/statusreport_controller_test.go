@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -31,6 +32,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/integration-service/gitops"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 
@@ -118,7 +120,7 @@ var _ = Describe("StatusReportController", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(err).To(BeNil())
 
-		statusReportReconciler = NewStatusReportReconciler(k8sClient, &logf.Log, &scheme)
+		statusReportReconciler = NewStatusReportReconciler(k8sClient, &logf.Log, &scheme, &record.FakeRecorder{})
 	})
 	AfterEach(func() {
 		err := k8sClient.Delete(ctx, hasApp)
@@ -151,6 +153,32 @@ var _ = Describe("StatusReportController", func() {
 		Expect(err).To(BeNil())
 	})
 
+	It("can setup the cache by adding a new index field to search for Snapshots by content hash", func() {
+		err := setupCache(manager)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("maps the IntegrationTestScenarios and IntegrationPolicies to the Snapshots still awaiting their tests", func() {
+		scenario := &v1beta2.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-pass", Namespace: "default"},
+			Spec:       v1beta2.IntegrationTestScenarioSpec{Application: hasApp.Name},
+		}
+		policy := &v1beta2.IntegrationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       v1beta2.IntegrationPolicySpec{Application: hasApp.Name},
+		}
+		Eventually(func() []reconcile.Request {
+			return statusReportReconciler.toAwaitingSnapshots(ctx, scenario)
+		}).Should(ContainElement(req))
+		Expect(statusReportReconciler.toAwaitingSnapshots(ctx, policy)).To(ContainElement(req))
+
+		Expect(gitops.MarkSnapshotAsFailed(ctx, k8sClient, hasSnapshot, "Failed")).To(Succeed())
+		Eventually(func() []reconcile.Request {
+			return statusReportReconciler.toAwaitingSnapshots(ctx, policy)
+		}).ShouldNot(ContainElement(req))
+		Expect(statusReportReconciler.toAwaitingSnapshots(ctx, hasSnapshot)).To(BeEmpty())
+	})
+
 	It("can setup a new controller manager with the given statusReportReconciler", func() {
 		err := setupControllerWithManager(manager, statusReportReconciler)
 		Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// DefaultControllerServiceAccount is the user name of the service account the integration service runs as when
+	// deployed by config/default.
+	DefaultControllerServiceAccount = "system:serviceaccount:integration-service-system:integration-service-controller-manager"
+
+	// PodNamespaceEnv is the env var the namespace of the pod of the integration service is set in.
+	PodNamespaceEnv = "POD_NAMESPACE"
+
+	// PodServiceAccountEnv is the env var the service account of the pod of the integration service is set in.
+	PodServiceAccountEnv = "POD_SERVICE_ACCOUNT"
+)
+
+// GetControllerServiceAccount returns the user name of the service account the integration service runs as, derived
+// from the namespace and service account of its pod set by the downward API in the PodNamespaceEnv and
+// PodServiceAccountEnv env vars, or DefaultControllerServiceAccount when they aren't set.
+func GetControllerServiceAccount() string {
+	namespace, serviceAccount := os.Getenv(PodNamespaceEnv), os.Getenv(PodServiceAccountEnv)
+	if namespace == "" || serviceAccount == "" {
+		return DefaultControllerServiceAccount
+	}
+
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount)
+}
+
+//+kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-snapshot,mutating=false,failurePolicy=fail,sideEffects=None,groups=appstudio.redhat.com,resources=snapshots,verbs=update,versions=v1alpha1,name=vsnapshot.kb.io,admissionReviewVersions=v1
+
+// SnapshotValidator prevents the components of Snapshots from being changed once they were created, since the
+// gating and release decisions made for a Snapshot assume it's immutable. The listed users, e.g. the service
+// account of the integration service itself, are still allowed to change them.
+type SnapshotValidator struct {
+	allowedUsers []string
+}
+
+var _ webhook.CustomValidator = &SnapshotValidator{}
+
+// NewSnapshotValidator creates and returns a SnapshotValidator allowing the given users to change the components
+// of Snapshots.
+func NewSnapshotValidator(allowedUsers ...string) *SnapshotValidator {
+	return &SnapshotValidator{allowedUsers: allowedUsers}
+}
+
+// SetupSnapshotWebhookWithManager registers the Snapshot validating webhook on the webhook server of the manager.
+func SetupSnapshotWebhookWithManager(mgr ctrl.Manager, allowedUsers ...string) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&applicationapiv1alpha1.Snapshot{}).
+		WithValidator(NewSnapshotValidator(allowedUsers...)).
+		Complete()
+}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *SnapshotValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+// It rejects the updates changing the components of the Snapshot unless they are requested by one of
+// the allowed users.
+func (v *SnapshotValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	oldSnapshot, ok := oldObj.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return nil, fmt.Errorf("expected a Snapshot but got a %T", oldObj)
+	}
+	newSnapshot, ok := newObj.(*applicationapiv1alpha1.Snapshot)
+	if !ok {
+		return nil, fmt.Errorf("expected a Snapshot but got a %T", newObj)
+	}
+
+	if equality.Semantic.DeepEqual(oldSnapshot.Spec.Components, newSnapshot.Spec.Components) {
+		return nil, nil
+	}
+
+	request, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the admission request: %w", err)
+	}
+	if slices.Contains(v.allowedUsers, request.UserInfo.Username) {
+		return nil, nil
+	}
+
+	return nil, field.Forbidden(field.NewPath("spec").Child("components"),
+		"the components of a Snapshot can't be changed once it was created")
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *SnapshotValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return nil, nil
+}
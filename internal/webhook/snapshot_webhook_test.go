@@ -0,0 +1,114 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/internal/webhook"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Snapshot webhook", func() {
+
+	const controllerUser = "system:serviceaccount:integration-service:integration-service-controller-manager"
+
+	var (
+		validator   *webhook.SnapshotValidator
+		oldSnapshot *applicationapiv1alpha1.Snapshot
+		newSnapshot *applicationapiv1alpha1.Snapshot
+	)
+
+	// requestContext returns a context holding an admission request made by the given user.
+	requestContext := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		validator = webhook.NewSnapshotValidator(controllerUser)
+		oldSnapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+				Components: []applicationapiv1alpha1.SnapshotComponent{
+					{
+						Name:           "component-sample",
+						ContainerImage: "quay.io/redhat-appstudio/sample-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1",
+					},
+				},
+			},
+		}
+		newSnapshot = oldSnapshot.DeepCopy()
+	})
+
+	It("allows updates which don't change the components", func() {
+		newSnapshot.Annotations = map[string]string{"test.appstudio.openshift.io/status": "[]"}
+		_, err := validator.ValidateUpdate(requestContext("developer"), oldSnapshot, newSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects updates changing the components", func() {
+		newSnapshot.Spec.Components[0].ContainerImage = "quay.io/redhat-appstudio/sample-image:latest"
+		_, err := validator.ValidateUpdate(requestContext("developer"), oldSnapshot, newSnapshot)
+		Expect(err).To(MatchError(ContainSubstring("the components of a Snapshot can't be changed once it was created")))
+
+		newSnapshot = oldSnapshot.DeepCopy()
+		newSnapshot.Spec.Components = nil
+		_, err = validator.ValidateUpdate(requestContext("developer"), oldSnapshot, newSnapshot)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("allows the service account of the controller to change the components", func() {
+		newSnapshot.Spec.Components = append(newSnapshot.Spec.Components, applicationapiv1alpha1.SnapshotComponent{
+			Name:           "another-component-sample",
+			ContainerImage: "quay.io/redhat-appstudio/another-image@sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1",
+		})
+		_, err := validator.ValidateUpdate(requestContext(controllerUser), oldSnapshot, newSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("derives the service account of the controller from its pod", func() {
+		GinkgoT().Setenv(webhook.PodNamespaceEnv, "")
+		GinkgoT().Setenv(webhook.PodServiceAccountEnv, "")
+		Expect(webhook.GetControllerServiceAccount()).To(Equal(webhook.DefaultControllerServiceAccount))
+
+		GinkgoT().Setenv(webhook.PodNamespaceEnv, "integration-service")
+		GinkgoT().Setenv(webhook.PodServiceAccountEnv, "integration-service-controller-manager")
+		Expect(webhook.GetControllerServiceAccount()).To(Equal(controllerUser))
+	})
+
+	It("allows creating and deleting Snapshots", func() {
+		_, err := validator.ValidateCreate(requestContext("developer"), newSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = validator.ValidateDelete(requestContext("developer"), oldSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
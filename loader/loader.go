@@ -22,18 +22,22 @@ import (
 	"fmt"
 
 	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/cache"
 	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/integration-service/tekton"
 	toolkit "github.com/konflux-ci/operator-toolkit/loader"
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -50,12 +54,22 @@ type ObjectLoader interface {
 	GetRequiredIntegrationTestScenariosForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]v1beta2.IntegrationTestScenario, error)
 	GetAllPipelineRunsForSnapshotAndScenario(ctx context.Context, c client.Client, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta2.IntegrationTestScenario) (*[]tektonv1.PipelineRun, error)
 	GetAllSnapshots(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]applicationapiv1alpha1.Snapshot, error)
+	GetAllSnapshotsWithContentHash(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application, contentHash string) (*[]applicationapiv1alpha1.Snapshot, error)
+	GetAllSnapshotsAwaitingTests(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]applicationapiv1alpha1.Snapshot, error)
 	GetAutoReleasePlansForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]releasev1alpha1.ReleasePlan, error)
 	GetScenario(ctx context.Context, c client.Client, name, namespace string) (*v1beta2.IntegrationTestScenario, error)
 	GetAllSnapshotsForBuildPipelineRun(ctx context.Context, c client.Client, pipelineRun *tektonv1.PipelineRun) (*[]applicationapiv1alpha1.Snapshot, error)
 	GetAllTaskRunsWithMatchingPipelineRunLabel(ctx context.Context, c client.Client, pipelineRun *tektonv1.PipelineRun) (*[]tektonv1.TaskRun, error)
 	GetPipelineRun(ctx context.Context, c client.Client, name, namespace string) (*tektonv1.PipelineRun, error)
 	GetComponent(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Component, error)
+	GetNamespace(ctx context.Context, c client.Client, name string) (*corev1.Namespace, error)
+	GetRunningIntegrationPipelineRuns(ctx context.Context, c client.Client, namespace string) (*[]tektonv1.PipelineRun, error)
+	GetSnapshot(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Snapshot, error)
+	GetAllSnapshotRunsForSnapshot(ctx context.Context, c client.Client, snapshot *applicationapiv1alpha1.Snapshot) (*[]v1beta2.SnapshotRun, error)
+	GetAllSnapshotRunsForScenario(ctx context.Context, c client.Client, scenario *v1beta2.IntegrationTestScenario) (*[]v1beta2.SnapshotRun, error)
+	GetIntegrationPolicyForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*v1beta2.IntegrationPolicy, error)
+	GetEnvironment(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Environment, error)
+	GetSnapshotEnvironmentBinding(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application, environmentName string) (*applicationapiv1alpha1.SnapshotEnvironmentBinding, error)
 }
 
 type loader struct{}
@@ -129,7 +143,7 @@ func (l *loader) GetComponentFromSnapshot(ctx context.Context, c client.Client,
 // GetComponentFromPipelineRun loads from the cluster the Component referenced in the given PipelineRun. If the PipelineRun doesn't
 // specify a Component or this is not found in the cluster, an error will be returned.
 func (l *loader) GetComponentFromPipelineRun(ctx context.Context, c client.Client, pipelineRun *tektonv1.PipelineRun) (*applicationapiv1alpha1.Component, error) {
-	if componentName, found := pipelineRun.Labels[tekton.PipelineRunComponentLabel]; found {
+	if componentName, found := tekton.GetComponentName(pipelineRun); found {
 		component := &applicationapiv1alpha1.Component{}
 		err := c.Get(ctx, types.NamespacedName{
 			Namespace: pipelineRun.Namespace,
@@ -149,7 +163,7 @@ func (l *loader) GetComponentFromPipelineRun(ctx context.Context, c client.Clien
 // GetApplicationFromPipelineRun loads from the cluster the Application referenced in the given PipelineRun. If the PipelineRun doesn't
 // specify an Application or this is not found in the cluster, an error will be returned.
 func (l *loader) GetApplicationFromPipelineRun(ctx context.Context, c client.Client, pipelineRun *tektonv1.PipelineRun) (*applicationapiv1alpha1.Application, error) {
-	if applicationName, found := pipelineRun.Labels[tekton.PipelineRunApplicationLabel]; found {
+	if applicationName, found := tekton.GetApplicationName(pipelineRun); found {
 		application := &applicationapiv1alpha1.Application{}
 		err := c.Get(ctx, types.NamespacedName{
 			Namespace: pipelineRun.Namespace,
@@ -241,8 +255,21 @@ func (l *loader) GetAllIntegrationTestScenariosForApplication(ctx context.Contex
 
 // GetRequiredIntegrationTestScenariosForApplication returns the IntegrationTestScenarios used by the application being processed.
 // An IntegrationTestScenarios will only be returned if it has the test.appstudio.openshift.io/optional
-// label not set to true or if it is missing the label entirely.
+// label not set to true or if it is missing the label entirely, unless the IntegrationPolicy of the application
+// defines which scenarios are required. The suspended IntegrationTestScenarios are never returned.
 func (l *loader) GetRequiredIntegrationTestScenariosForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]v1beta2.IntegrationTestScenario, error) {
+	policy, err := l.GetIntegrationPolicyForApplication(ctx, c, application)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		integrationTestScenarios, err := l.GetAllIntegrationTestScenariosForApplication(ctx, c, application)
+		if err != nil {
+			return nil, err
+		}
+		return gitops.FilterRequiredIntegrationTestScenarios(policy, integrationTestScenarios), nil
+	}
+
 	integrationList := &v1beta2.IntegrationTestScenarioList{}
 	labelRequirement, err := labels.NewRequirement("test.appstudio.openshift.io/optional", selection.NotIn, []string{"true"})
 	if err != nil {
@@ -261,20 +288,19 @@ func (l *loader) GetRequiredIntegrationTestScenariosForApplication(ctx context.C
 		return nil, err
 	}
 
-	return &integrationList.Items, nil
+	return gitops.FilterRequiredIntegrationTestScenarios(nil, &integrationList.Items), nil
 }
 
 // GetAllPipelineRunsForSnapshotAndScenario returns all Integration PipelineRun for the
-// associated Snapshot and IntegrationTestScenario. In the case the List operation fails,
-// an error will be returned.
+// associated Snapshot and IntegrationTestScenario, they are looked up through the PipelineRunSnapshotScenarioIndex
+// of the cache. The PipelineRuns which were already pruned from the cluster are fetched from the Tekton Results API
+// when it's configured. In the case the List operation fails, an error will be returned.
 func (l *loader) GetAllPipelineRunsForSnapshotAndScenario(ctx context.Context, adapterClient client.Client, snapshot *applicationapiv1alpha1.Snapshot, integrationTestScenario *v1beta2.IntegrationTestScenario) (*[]tektonv1.PipelineRun, error) {
 	integrationPipelineRuns := &tektonv1.PipelineRunList{}
 	opts := []client.ListOption{
 		client.InNamespace(snapshot.Namespace),
-		client.MatchingLabels{
-			"pipelines.appstudio.openshift.io/type": "test",
-			"appstudio.openshift.io/snapshot":       snapshot.Name,
-			"test.appstudio.openshift.io/scenario":  integrationTestScenario.Name,
+		client.MatchingFields{
+			cache.PipelineRunSnapshotScenarioIndex: cache.PipelineRunSnapshotScenarioIndexValue(snapshot.Name, integrationTestScenario.Name),
 		},
 	}
 
@@ -282,6 +308,28 @@ func (l *loader) GetAllPipelineRunsForSnapshotAndScenario(ctx context.Context, a
 	if err != nil {
 		return nil, err
 	}
+
+	prunedPipelineRuns, err := helpers.GetPrunedPipelineRuns(ctx, snapshot.Namespace, map[string]string{
+		tekton.PipelinesTypeLabel: tekton.PipelineTypeTest,
+		tekton.SnapshotNameLabel:  snapshot.Name,
+		tekton.ScenarioNameLabel:  integrationTestScenario.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the pruned pipelineRuns from Tekton Results: %w", err)
+	}
+	for _, prunedPipelineRun := range prunedPipelineRuns {
+		inCluster := false
+		for _, pipelineRun := range integrationPipelineRuns.Items {
+			if pipelineRun.Name == prunedPipelineRun.Name {
+				inCluster = true
+				break
+			}
+		}
+		if !inCluster {
+			integrationPipelineRuns.Items = append(integrationPipelineRuns.Items, prunedPipelineRun)
+		}
+	}
+
 	return &integrationPipelineRuns.Items, nil
 }
 
@@ -302,6 +350,50 @@ func (l *loader) GetAllSnapshots(ctx context.Context, c client.Client, applicati
 	return &snapshots.Items, nil
 }
 
+// GetAllSnapshotsAwaitingTests returns the Snapshots of the Application which are still awaiting the results of
+// their integration tests. In the case the List operation fails, an error will be returned.
+func (l *loader) GetAllSnapshotsAwaitingTests(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]applicationapiv1alpha1.Snapshot, error) {
+	snapshots, err := l.GetAllSnapshots(ctx, c, application)
+	if err != nil {
+		return nil, err
+	}
+
+	awaitingSnapshots := []applicationapiv1alpha1.Snapshot{}
+	for _, snapshot := range *snapshots {
+		snapshot := snapshot //G601
+		if gitops.IsSnapshotAwaitingTests(&snapshot) {
+			awaitingSnapshots = append(awaitingSnapshots, snapshot)
+		}
+	}
+
+	return &awaitingSnapshots, nil
+}
+
+// GetAllSnapshotsWithContentHash returns all Snapshots of the Application with the given content hash, they are
+// looked up through the SnapshotContentHashIndex of the cache. In the case the List operation fails,
+// an error will be returned.
+func (l *loader) GetAllSnapshotsWithContentHash(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application, contentHash string) (*[]applicationapiv1alpha1.Snapshot, error) {
+	snapshots := &applicationapiv1alpha1.SnapshotList{}
+	opts := []client.ListOption{
+		client.InNamespace(application.Namespace),
+		client.MatchingFields{cache.SnapshotContentHashIndex: contentHash},
+	}
+
+	err := c.List(ctx, snapshots, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	applicationSnapshots := []applicationapiv1alpha1.Snapshot{}
+	for _, snapshot := range snapshots.Items {
+		if snapshot.Spec.Application == application.Name {
+			applicationSnapshots = append(applicationSnapshots, snapshot)
+		}
+	}
+
+	return &applicationSnapshots, nil
+}
+
 // GetAutoReleasePlansForApplication returns the ReleasePlans used by the application being processed. If matching
 // ReleasePlans are not found, an error will be returned. A ReleasePlan will only be returned if it has the
 // release.appstudio.openshift.io/auto-release label set to true or if it is missing the label entirely.
@@ -381,3 +473,126 @@ func (l *loader) GetComponent(ctx context.Context, c client.Client, name, namesp
 	component := &applicationapiv1alpha1.Component{}
 	return component, toolkit.GetObject(name, namespace, c, ctx, component)
 }
+
+// GetNamespace returns the namespace requested by name
+func (l *loader) GetNamespace(ctx context.Context, c client.Client, name string) (*corev1.Namespace, error) {
+	namespace := &corev1.Namespace{}
+	return namespace, toolkit.GetObject(name, "", c, ctx, namespace)
+}
+
+// GetRunningIntegrationPipelineRuns returns all integration PipelineRuns of the namespace which haven't finished
+// yet and aren't being deleted. In the case the List operation fails, an error will be returned.
+func (l *loader) GetRunningIntegrationPipelineRuns(ctx context.Context, c client.Client, namespace string) (*[]tektonv1.PipelineRun, error) {
+	integrationPipelineRuns := &tektonv1.PipelineRunList{}
+	opts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels{
+			tekton.PipelinesTypeLabel: tekton.PipelineTypeTest,
+		},
+	}
+
+	err := c.List(ctx, integrationPipelineRuns, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	runningPipelineRuns := []tektonv1.PipelineRun{}
+	for _, pipelineRun := range integrationPipelineRuns.Items {
+		if pipelineRun.GetDeletionTimestamp() == nil && pipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+			runningPipelineRuns = append(runningPipelineRuns, pipelineRun)
+		}
+	}
+	return &runningPipelineRuns, nil
+}
+
+// GetSnapshot returns the Snapshot requested by name and namespace
+func (l *loader) GetSnapshot(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Snapshot, error) {
+	snapshot := &applicationapiv1alpha1.Snapshot{}
+	return snapshot, toolkit.GetObject(name, namespace, c, ctx, snapshot)
+}
+
+// GetAllSnapshotRunsForSnapshot returns all SnapshotRuns requesting integration tests to be run against the given
+// Snapshot. In the case the List operation fails, an error will be returned.
+func (l *loader) GetAllSnapshotRunsForSnapshot(ctx context.Context, c client.Client, snapshot *applicationapiv1alpha1.Snapshot) (*[]v1beta2.SnapshotRun, error) {
+	snapshotRuns := &v1beta2.SnapshotRunList{}
+	opts := []client.ListOption{
+		client.InNamespace(snapshot.Namespace),
+		client.MatchingFields{"spec.snapshot": snapshot.Name},
+	}
+
+	err := c.List(ctx, snapshotRuns, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshotRuns.Items, nil
+}
+
+// GetAllSnapshotRunsForScenario returns all SnapshotRuns created for the given IntegrationTestScenario.
+// In the case the List operation fails, an error will be returned.
+func (l *loader) GetAllSnapshotRunsForScenario(ctx context.Context, c client.Client, scenario *v1beta2.IntegrationTestScenario) (*[]v1beta2.SnapshotRun, error) {
+	snapshotRuns := &v1beta2.SnapshotRunList{}
+	opts := []client.ListOption{
+		client.InNamespace(scenario.Namespace),
+		client.MatchingLabels{gitops.SnapshotTestScenarioLabel: scenario.Name},
+	}
+
+	err := c.List(ctx, snapshotRuns, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshotRuns.Items, nil
+}
+
+// GetIntegrationPolicyForApplication returns the IntegrationPolicy defining the gating policy of the given Application,
+// or nil if the application doesn't have any. When several IntegrationPolicies apply to the same application, the first
+// one by name is returned. In the case the List operation fails, an error will be returned.
+func (l *loader) GetIntegrationPolicyForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*v1beta2.IntegrationPolicy, error) {
+	policies := &v1beta2.IntegrationPolicyList{}
+	err := c.List(ctx, policies, client.InNamespace(application.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	var applicationPolicy *v1beta2.IntegrationPolicy
+	for i, policy := range policies.Items {
+		if policy.Spec.Application != application.Name {
+			continue
+		}
+		if applicationPolicy == nil || policy.Name < applicationPolicy.Name {
+			applicationPolicy = &policies.Items[i]
+		}
+	}
+
+	return applicationPolicy, nil
+}
+
+// GetEnvironment returns the Environment requested by name and namespace
+func (l *loader) GetEnvironment(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Environment, error) {
+	environment := &applicationapiv1alpha1.Environment{}
+	return environment, toolkit.GetObject(name, namespace, c, ctx, environment)
+}
+
+// GetSnapshotEnvironmentBinding returns the SnapshotEnvironmentBinding deploying the given Application to the named
+// Environment, or nil if there's none. When several bindings match, the first one by name is returned.
+// In the case the List operation fails, an error will be returned.
+func (l *loader) GetSnapshotEnvironmentBinding(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application, environmentName string) (*applicationapiv1alpha1.SnapshotEnvironmentBinding, error) {
+	bindings := &applicationapiv1alpha1.SnapshotEnvironmentBindingList{}
+	err := c.List(ctx, bindings, client.InNamespace(application.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	var environmentBinding *applicationapiv1alpha1.SnapshotEnvironmentBinding
+	for i, binding := range bindings.Items {
+		if binding.Spec.Application != application.Name || binding.Spec.Environment != environmentName {
+			continue
+		}
+		if environmentBinding == nil || binding.Name < environmentBinding.Name {
+			environmentBinding = &bindings.Items[i]
+		}
+	}
+
+	return environmentBinding, nil
+}
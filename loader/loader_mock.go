@@ -24,6 +24,7 @@ import (
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -55,6 +56,14 @@ const (
 	AllTaskRunsWithMatchingPipelineRunLabelContextKey
 	GetPipelineRunContextKey
 	GetComponentContextKey
+	SnapshotsWithContentHashContextKey
+	GetNamespaceContextKey
+	RunningIntegrationPipelineRunsContextKey
+	GetSnapshotContextKey
+	AllSnapshotRunsForSnapshotContextKey
+	IntegrationPolicyContextKey
+	AllSnapshotsAwaitingTestsContextKey
+	AllSnapshotRunsForScenarioContextKey
 )
 
 func NewMockLoader() ObjectLoader {
@@ -165,6 +174,15 @@ func (l *mockLoader) GetAllSnapshots(ctx context.Context, c client.Client, appli
 	return &snapshots, err
 }
 
+// GetAllSnapshotsWithContentHash returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllSnapshotsWithContentHash(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application, contentHash string) (*[]applicationapiv1alpha1.Snapshot, error) {
+	if ctx.Value(SnapshotsWithContentHashContextKey) == nil {
+		return l.loader.GetAllSnapshotsWithContentHash(ctx, c, application, contentHash)
+	}
+	snapshots, err := toolkit.GetMockedResourceAndErrorFromContext(ctx, SnapshotsWithContentHashContextKey, []applicationapiv1alpha1.Snapshot{})
+	return &snapshots, err
+}
+
 // GetAutoReleasePlansForApplication returns the resource and error passed as values of the context.
 func (l *mockLoader) GetAutoReleasePlansForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]releasev1alpha1.ReleasePlan, error) {
 	if ctx.Value(AutoReleasePlansContextKey) == nil {
@@ -213,3 +231,79 @@ func (l *mockLoader) GetComponent(ctx context.Context, c client.Client, name, na
 	}
 	return toolkit.GetMockedResourceAndErrorFromContext(ctx, GetComponentContextKey, &applicationapiv1alpha1.Component{})
 }
+
+// GetNamespace returns the resource and error passed as values of the context.
+func (l *mockLoader) GetNamespace(ctx context.Context, c client.Client, name string) (*corev1.Namespace, error) {
+	if ctx.Value(GetNamespaceContextKey) == nil {
+		return l.loader.GetNamespace(ctx, c, name)
+	}
+	return toolkit.GetMockedResourceAndErrorFromContext(ctx, GetNamespaceContextKey, &corev1.Namespace{})
+}
+
+// GetRunningIntegrationPipelineRuns returns the resource and error passed as values of the context.
+func (l *mockLoader) GetRunningIntegrationPipelineRuns(ctx context.Context, c client.Client, namespace string) (*[]tektonv1.PipelineRun, error) {
+	if ctx.Value(RunningIntegrationPipelineRunsContextKey) == nil {
+		return l.loader.GetRunningIntegrationPipelineRuns(ctx, c, namespace)
+	}
+	pipelineRuns, err := toolkit.GetMockedResourceAndErrorFromContext(ctx, RunningIntegrationPipelineRunsContextKey, []tektonv1.PipelineRun{})
+	return &pipelineRuns, err
+}
+
+// GetSnapshot returns the resource and error passed as values of the context.
+func (l *mockLoader) GetSnapshot(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Snapshot, error) {
+	if ctx.Value(GetSnapshotContextKey) == nil {
+		return l.loader.GetSnapshot(ctx, c, name, namespace)
+	}
+	return toolkit.GetMockedResourceAndErrorFromContext(ctx, GetSnapshotContextKey, &applicationapiv1alpha1.Snapshot{})
+}
+
+// GetAllSnapshotRunsForSnapshot returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllSnapshotRunsForSnapshot(ctx context.Context, c client.Client, snapshot *applicationapiv1alpha1.Snapshot) (*[]v1beta2.SnapshotRun, error) {
+	if ctx.Value(AllSnapshotRunsForSnapshotContextKey) == nil {
+		return l.loader.GetAllSnapshotRunsForSnapshot(ctx, c, snapshot)
+	}
+	snapshotRuns, err := toolkit.GetMockedResourceAndErrorFromContext(ctx, AllSnapshotRunsForSnapshotContextKey, []v1beta2.SnapshotRun{})
+	return &snapshotRuns, err
+}
+
+// GetAllSnapshotRunsForScenario returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllSnapshotRunsForScenario(ctx context.Context, c client.Client, scenario *v1beta2.IntegrationTestScenario) (*[]v1beta2.SnapshotRun, error) {
+	if ctx.Value(AllSnapshotRunsForScenarioContextKey) == nil {
+		return l.loader.GetAllSnapshotRunsForScenario(ctx, c, scenario)
+	}
+	snapshotRuns, err := toolkit.GetMockedResourceAndErrorFromContext(ctx, AllSnapshotRunsForScenarioContextKey, []v1beta2.SnapshotRun{})
+	return &snapshotRuns, err
+}
+
+// GetIntegrationPolicyForApplication returns the resource and error passed as values of the context.
+func (l *mockLoader) GetIntegrationPolicyForApplication(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*v1beta2.IntegrationPolicy, error) {
+	if ctx.Value(IntegrationPolicyContextKey) == nil {
+		return l.loader.GetIntegrationPolicyForApplication(ctx, c, application)
+	}
+	return toolkit.GetMockedResourceAndErrorFromContext(ctx, IntegrationPolicyContextKey, &v1beta2.IntegrationPolicy{})
+}
+
+// GetAllSnapshotsAwaitingTests returns the resource and error passed as values of the context.
+func (l *mockLoader) GetAllSnapshotsAwaitingTests(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application) (*[]applicationapiv1alpha1.Snapshot, error) {
+	if ctx.Value(AllSnapshotsAwaitingTestsContextKey) == nil {
+		return l.loader.GetAllSnapshotsAwaitingTests(ctx, c, application)
+	}
+	snapshots, err := toolkit.GetMockedResourceAndErrorFromContext(ctx, AllSnapshotsAwaitingTestsContextKey, []applicationapiv1alpha1.Snapshot{})
+	return &snapshots, err
+}
+
+// GetEnvironment returns the resource and error passed as values of the context.
+func (l *mockLoader) GetEnvironment(ctx context.Context, c client.Client, name, namespace string) (*applicationapiv1alpha1.Environment, error) {
+	if ctx.Value(EnvironmentContextKey) == nil {
+		return l.loader.GetEnvironment(ctx, c, name, namespace)
+	}
+	return toolkit.GetMockedResourceAndErrorFromContext(ctx, EnvironmentContextKey, &applicationapiv1alpha1.Environment{})
+}
+
+// GetSnapshotEnvironmentBinding returns the resource and error passed as values of the context.
+func (l *mockLoader) GetSnapshotEnvironmentBinding(ctx context.Context, c client.Client, application *applicationapiv1alpha1.Application, environmentName string) (*applicationapiv1alpha1.SnapshotEnvironmentBinding, error) {
+	if ctx.Value(SnapshotEnvironmentBindingContextKey) == nil {
+		return l.loader.GetSnapshotEnvironmentBinding(ctx, c, application, environmentName)
+	}
+	return toolkit.GetMockedResourceAndErrorFromContext(ctx, SnapshotEnvironmentBindingContextKey, &applicationapiv1alpha1.SnapshotEnvironmentBinding{})
+}
@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/gomega"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var _ = Describe("Release Adapter", Ordered, func() {
@@ -231,6 +232,21 @@ var _ = Describe("Release Adapter", Ordered, func() {
 		})
 	})
 
+	Context("When calling GetAllSnapshotsWithContentHash", func() {
+		It("returns snapshots and error from the context", func() {
+			snapshots := []applicationapiv1alpha1.Snapshot{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: SnapshotsWithContentHashContextKey,
+					Resource:   snapshots,
+				},
+			})
+			resource, err := loader.GetAllSnapshotsWithContentHash(mockContext, nil, nil, "")
+			Expect(resource).To(Equal(&snapshots))
+			Expect(err).To(BeNil())
+		})
+	})
+
 	Context("When calling GetAutoReleasePlansForApplication", func() {
 		It("returns snapshots and error from the context", func() {
 			releasePlans := []releasev1alpha1.ReleasePlan{}
@@ -290,4 +306,109 @@ var _ = Describe("Release Adapter", Ordered, func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("When calling GetNamespace", func() {
+		It("returns resource and error from the context", func() {
+			namespace := &corev1.Namespace{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: GetNamespaceContextKey,
+					Resource:   namespace,
+				},
+			})
+			resource, err := loader.GetNamespace(mockContext, nil, "")
+			Expect(resource).To(Equal(namespace))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When calling GetRunningIntegrationPipelineRuns", func() {
+		It("returns resource and error from the context", func() {
+			pipelineRuns := []tektonv1.PipelineRun{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: RunningIntegrationPipelineRunsContextKey,
+					Resource:   pipelineRuns,
+				},
+			})
+			resource, err := loader.GetRunningIntegrationPipelineRuns(mockContext, nil, "")
+			Expect(resource).To(Equal(&pipelineRuns))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When calling GetSnapshot", func() {
+		It("returns resource and error from the context", func() {
+			snapshot := &applicationapiv1alpha1.Snapshot{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: GetSnapshotContextKey,
+					Resource:   snapshot,
+				},
+			})
+			resource, err := loader.GetSnapshot(mockContext, nil, "", "")
+			Expect(resource).To(Equal(snapshot))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When calling GetAllSnapshotRunsForSnapshot", func() {
+		It("returns resource and error from the context", func() {
+			snapshotRuns := []v1beta2.SnapshotRun{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: AllSnapshotRunsForSnapshotContextKey,
+					Resource:   snapshotRuns,
+				},
+			})
+			resource, err := loader.GetAllSnapshotRunsForSnapshot(mockContext, nil, nil)
+			Expect(resource).To(Equal(&snapshotRuns))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When calling GetAllSnapshotRunsForScenario", func() {
+		It("returns resource and error from the context", func() {
+			snapshotRuns := []v1beta2.SnapshotRun{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: AllSnapshotRunsForScenarioContextKey,
+					Resource:   snapshotRuns,
+				},
+			})
+			resource, err := loader.GetAllSnapshotRunsForScenario(mockContext, nil, nil)
+			Expect(resource).To(Equal(&snapshotRuns))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When calling GetIntegrationPolicyForApplication", func() {
+		It("returns resource and error from the context", func() {
+			policy := &v1beta2.IntegrationPolicy{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: IntegrationPolicyContextKey,
+					Resource:   policy,
+				},
+			})
+			resource, err := loader.GetIntegrationPolicyForApplication(mockContext, nil, nil)
+			Expect(resource).To(Equal(policy))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("When calling GetAllSnapshotsAwaitingTests", func() {
+		It("returns resource and error from the context", func() {
+			snapshots := []applicationapiv1alpha1.Snapshot{}
+			mockContext := toolkit.GetMockedContext(ctx, []toolkit.MockData{
+				{
+					ContextKey: AllSnapshotsAwaitingTestsContextKey,
+					Resource:   snapshots,
+				},
+			})
+			resource, err := loader.GetAllSnapshotsAwaitingTests(mockContext, nil, nil)
+			Expect(resource).To(Equal(&snapshots))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
 })
@@ -114,6 +114,9 @@ var _ = BeforeSuite(func() {
 		Expect(cache.SetupReleasePlanCache(k8sManager)).To(Succeed())
 		Expect(cache.SetupApplicationComponentCache(k8sManager)).To(Succeed())
 		Expect(cache.SetupSnapshotCache(k8sManager)).To(Succeed())
+		Expect(cache.SetupPipelineRunCache(k8sManager)).To(Succeed())
+		Expect(cache.SetupSnapshotContentHashCache(k8sManager)).To(Succeed())
+		Expect(cache.SetupSnapshotRunCache(k8sManager)).To(Succeed())
 		Expect(k8sManager.Start(ctx)).To(Succeed())
 	}()
 })
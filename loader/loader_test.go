@@ -17,6 +17,12 @@ limitations under the License.
 package loader
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -30,6 +36,9 @@ import (
 	types "k8s.io/apimachinery/pkg/types"
 
 	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/pkg/tektonresults"
+	"github.com/konflux-ci/integration-service/tekton"
 	releasev1alpha1 "github.com/konflux-ci/release-service/api/v1alpha1"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 )
@@ -102,6 +111,7 @@ var _ = Describe("Loader", Ordered, func() {
 					gitops.SnapshotTypeLabel:         "component",
 					gitops.SnapshotComponentLabel:    "component-sample",
 					gitops.BuildPipelineRunNameLabel: "pipelinerun-sample",
+					gitops.SnapshotContentHashLabel:  "content-hash-sample",
 				},
 				Annotations: map[string]string{
 					gitops.PipelineAsCodeInstallationIDAnnotation: "123",
@@ -418,6 +428,26 @@ var _ = Describe("Loader", Ordered, func() {
 		Expect(comp.ObjectMeta).To(Equal(hasComp.ObjectMeta))
 	})
 
+	It("ensures we can get the Component and Application from the params of a Pipeline Run without their labels", func() {
+		plainPipelineRun := buildPipelineRun.DeepCopy()
+		delete(plainPipelineRun.Labels, tekton.PipelineRunComponentLabel)
+		delete(plainPipelineRun.Labels, tekton.PipelineRunApplicationLabel)
+		plainPipelineRun.Spec.Params = append(plainPipelineRun.Spec.Params,
+			tektonv1.Param{Name: tekton.PipelineRunComponentParamName, Value: *tektonv1.NewStructuredValues(hasComp.Name)},
+			tektonv1.Param{Name: tekton.PipelineRunApplicationParamName, Value: *tektonv1.NewStructuredValues(hasApp.Name)},
+		)
+
+		comp, err := loader.GetComponentFromPipelineRun(ctx, k8sClient, plainPipelineRun)
+		Expect(err).To(BeNil())
+		Expect(comp).NotTo(BeNil())
+		Expect(comp.Name).To(Equal(hasComp.Name))
+
+		app, err := loader.GetApplicationFromPipelineRun(ctx, k8sClient, plainPipelineRun)
+		Expect(err).To(BeNil())
+		Expect(app).NotTo(BeNil())
+		Expect(app.Name).To(Equal(hasApp.Name))
+	})
+
 	It("ensures we can get the application from the Pipeline Run", func() {
 		app, err := loader.GetApplicationFromPipelineRun(ctx, k8sClient, buildPipelineRun)
 		Expect(err).To(BeNil())
@@ -455,6 +485,37 @@ var _ = Describe("Loader", Ordered, func() {
 		Expect((*pipelineRuns)[0].Name).To(Equal(integrationPipelineRun.Name))
 	})
 
+	It("can fetch the pruned pipelineRuns for snapshot and scenario from Tekton Results", func() {
+		prunedPipelineRun, err := json.Marshal(&tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "pruned-pipelinerun", Namespace: "default"},
+		})
+		Expect(err).To(BeNil())
+		inClusterPipelineRun, err := json.Marshal(integrationPipelineRun)
+		Expect(err).To(BeNil())
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("filter")).To(ContainSubstring(fmt.Sprintf(`data.metadata.labels[%q] == %q`, tekton.SnapshotNameLabel, hasSnapshot.Name)))
+			Expect(json.NewEncoder(w).Encode(map[string]any{"records": []map[string]any{
+				{"name": "default/results/a/records/a", "data": map[string]any{"type": tektonresults.PipelineRunRecordType, "value": inClusterPipelineRun}},
+				{"name": "default/results/b/records/b", "data": map[string]any{"type": tektonresults.PipelineRunRecordType, "value": prunedPipelineRun}},
+			}})).To(Succeed())
+		}))
+		defer server.Close()
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("test-token"), 0600)).To(Succeed())
+		GinkgoT().Setenv(tektonresults.APIAddressEnvVar, server.URL)
+		GinkgoT().Setenv(tektonresults.APITokenFileEnvVar, tokenFile)
+		resultsClient, err := tektonresults.NewClientFromEnv()
+		Expect(err).To(BeNil())
+		helpers.SetTektonResultsClient(resultsClient)
+		defer helpers.SetTektonResultsClient(nil)
+
+		pipelineRuns, err := loader.GetAllPipelineRunsForSnapshotAndScenario(ctx, k8sClient, hasSnapshot, integrationTestScenario)
+		Expect(err).To(BeNil())
+		Expect(*pipelineRuns).To(HaveLen(2))
+		Expect((*pipelineRuns)[0].Name).To(Equal(integrationPipelineRun.Name))
+		Expect((*pipelineRuns)[1].Name).To(Equal("pruned-pipelinerun"))
+	})
+
 	It("can fetch all integrationTestScenario for application", func() {
 		integrationTestScenarios, err := loader.GetAllIntegrationTestScenariosForApplication(ctx, k8sClient, hasApp)
 		Expect(err).To(BeNil())
@@ -477,6 +538,203 @@ var _ = Describe("Loader", Ordered, func() {
 		Expect(*snapshots).To(HaveLen(1))
 	})
 
+	It("ensures that only the Snapshots awaiting their tests are returned", func() {
+		// hasSnapshot has already passed its tests
+		awaitingSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-awaiting",
+				Namespace: namespace,
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: hasApp.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, awaitingSnapshot)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, awaitingSnapshot)).To(Succeed())
+			Eventually(func() []applicationapiv1alpha1.Snapshot {
+				snapshots, err := loader.GetAllSnapshots(ctx, k8sClient, hasApp)
+				Expect(err).To(BeNil())
+				return *snapshots
+			}).Should(HaveLen(1))
+		}()
+
+		Eventually(func() []string {
+			snapshots, err := loader.GetAllSnapshotsAwaitingTests(ctx, k8sClient, hasApp)
+			Expect(err).To(BeNil())
+			names := []string{}
+			for _, snapshot := range *snapshots {
+				names = append(names, snapshot.Name)
+			}
+			return names
+		}).Should(Equal([]string{awaitingSnapshot.Name}))
+	})
+
+	It("ensures that the Snapshots with a given content hash can be found", func() {
+		snapshots, err := loader.GetAllSnapshotsWithContentHash(ctx, k8sClient, hasApp, "content-hash-sample")
+		Expect(err).To(BeNil())
+		Expect(*snapshots).To(HaveLen(1))
+		Expect((*snapshots)[0].Name).To(Equal(hasSnapshot.Name))
+
+		snapshots, err = loader.GetAllSnapshotsWithContentHash(ctx, k8sClient, hasApp, "content-hash-other")
+		Expect(err).To(BeNil())
+		Expect(*snapshots).To(BeEmpty())
+	})
+
+	It("can get the namespace by name", func() {
+		namespace, err := loader.GetNamespace(ctx, k8sClient, "default")
+		Expect(err).To(BeNil())
+		Expect(namespace.Name).To(Equal("default"))
+	})
+
+	It("can fetch the running integration pipelineRuns of the namespace", func() {
+		pipelineRuns, err := loader.GetRunningIntegrationPipelineRuns(ctx, k8sClient, integrationPipelineRun.Namespace)
+		Expect(err).To(BeNil())
+		Expect(*pipelineRuns).To(HaveLen(1))
+		Expect((*pipelineRuns)[0].Name).To(Equal(integrationPipelineRun.Name))
+
+		pipelineRuns, err = loader.GetRunningIntegrationPipelineRuns(ctx, k8sClient, "other-namespace")
+		Expect(err).To(BeNil())
+		Expect(*pipelineRuns).To(BeEmpty())
+	})
+
+	It("can get the Snapshot by name", func() {
+		snapshot, err := loader.GetSnapshot(ctx, k8sClient, hasSnapshot.Name, hasSnapshot.Namespace)
+		Expect(err).To(BeNil())
+		Expect(snapshot.Name).To(Equal(hasSnapshot.Name))
+	})
+
+	It("can fetch the SnapshotRuns of the Snapshot", func() {
+		snapshotRun := &v1beta2.SnapshotRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshotrun-sample",
+				Namespace: "default",
+			},
+			Spec: v1beta2.SnapshotRunSpec{
+				Snapshot: hasSnapshot.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, snapshotRun)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, snapshotRun)).Should(Succeed())
+		}()
+
+		Eventually(func() []v1beta2.SnapshotRun {
+			snapshotRuns, err := loader.GetAllSnapshotRunsForSnapshot(ctx, k8sClient, hasSnapshot)
+			Expect(err).To(BeNil())
+			return *snapshotRuns
+		}, time.Second*10).Should(HaveLen(1))
+	})
+
+	It("can fetch the SnapshotRuns of the IntegrationTestScenario", func() {
+		snapshotRun := &v1beta2.SnapshotRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshotrun-scenario",
+				Namespace: "default",
+				Labels: map[string]string{
+					gitops.SnapshotTestScenarioLabel: integrationTestScenario.Name,
+				},
+			},
+			Spec: v1beta2.SnapshotRunSpec{
+				Snapshot: hasSnapshot.Name,
+				Scenario: integrationTestScenario.Name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, snapshotRun)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, snapshotRun)).Should(Succeed())
+		}()
+
+		Eventually(func() []v1beta2.SnapshotRun {
+			snapshotRuns, err := loader.GetAllSnapshotRunsForScenario(ctx, k8sClient, integrationTestScenario)
+			Expect(err).To(BeNil())
+			return *snapshotRuns
+		}, time.Second*10).Should(HaveLen(1))
+	})
+
+	It("can fetch the IntegrationPolicy of the application", func() {
+		policy, err := loader.GetIntegrationPolicyForApplication(ctx, k8sClient, hasApp)
+		Expect(err).To(BeNil())
+		Expect(policy).To(BeNil())
+
+		otherPolicy := &v1beta2.IntegrationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "integrationpolicy-a",
+				Namespace: "default",
+			},
+			Spec: v1beta2.IntegrationPolicySpec{
+				Application: "other-application",
+			},
+		}
+		applicationPolicy := &v1beta2.IntegrationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "integrationpolicy-b",
+				Namespace: "default",
+			},
+			Spec: v1beta2.IntegrationPolicySpec{
+				Application:       hasApp.Name,
+				RequiredScenarios: []string{"another-scenario"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, otherPolicy)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, applicationPolicy)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, otherPolicy)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, applicationPolicy)).Should(Succeed())
+		}()
+
+		Eventually(func() string {
+			policy, err := loader.GetIntegrationPolicyForApplication(ctx, k8sClient, hasApp)
+			Expect(err).To(BeNil())
+			if policy == nil {
+				return ""
+			}
+			return policy.Name
+		}, time.Second*10).Should(Equal(applicationPolicy.Name))
+
+		// the required scenarios of the policy take precedence over the optional label of the scenarios
+		integrationTestScenarios, err := loader.GetRequiredIntegrationTestScenariosForApplication(ctx, k8sClient, hasApp)
+		Expect(err).To(BeNil())
+		Expect(*integrationTestScenarios).To(BeEmpty())
+	})
+
+	It("can fetch the SnapshotEnvironmentBinding of the application for an environment", func() {
+		binding, err := loader.GetSnapshotEnvironmentBinding(ctx, k8sClient, hasApp, "staging")
+		Expect(err).To(BeNil())
+		Expect(binding).To(BeNil())
+
+		otherBinding := &applicationapiv1alpha1.SnapshotEnvironmentBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "binding-a",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotEnvironmentBindingSpec{
+				Application: hasApp.Name,
+				Environment: "production",
+				Snapshot:    hasSnapshot.Name,
+				Components:  []applicationapiv1alpha1.BindingComponent{},
+			},
+		}
+		stagingBinding := otherBinding.DeepCopy()
+		stagingBinding.Name = "binding-b"
+		stagingBinding.Spec.Environment = "staging"
+		Expect(k8sClient.Create(ctx, otherBinding)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, stagingBinding)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, otherBinding)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, stagingBinding)).Should(Succeed())
+		}()
+
+		Eventually(func() string {
+			binding, err := loader.GetSnapshotEnvironmentBinding(ctx, k8sClient, hasApp, "staging")
+			Expect(err).To(BeNil())
+			if binding == nil {
+				return ""
+			}
+			return binding.Name
+		}, time.Second*10).Should(Equal(stagingBinding.Name))
+	})
+
 	It("ensures the ReleasePlan can be gotten for Application", func() {
 		gottenReleasePlanItems, err := loader.GetAutoReleasePlansForApplication(ctx, k8sClient, hasApp)
 		Expect(err).To(BeNil())
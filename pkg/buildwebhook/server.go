@@ -0,0 +1,179 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
+	"github.com/konflux-ci/integration-service/pkg/statusapi"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	clienterrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// WebhookPathPrefix is the prefix of the path the builds of the external CI systems are posted to, the
+	// supported path is /api/v1/namespaces/<namespace>/components/<component>/builds.
+	WebhookPathPrefix = "/api/v1/namespaces/"
+
+	// maxRequestSize is the maximum size of the body of the build requests.
+	maxRequestSize = 64 * 1024
+)
+
+// BuildRequest is the body of the requests announcing an image built by an external CI system.
+type BuildRequest struct {
+	// Image is the pull spec of the built image, which references it by digest unless the Digest is set
+	Image string `json:"image"`
+
+	// Digest is the digest of the built image, e.g. sha256:..., when the Image doesn't reference it by digest
+	Digest string `json:"digest,omitempty"`
+}
+
+// Server receives the images built by external CI systems, e.g. Jenkins or GitHub Actions, and announces them
+// through the PushedImageAnnotation of their Component, so that a Snapshot is composed and tested for them.
+// Its Handler is served by an httpserver.Server.
+type Server struct {
+	client     client.Client
+	authorizer statusapi.Authorizer
+	logger     logr.Logger
+}
+
+// NewServer creates and returns a Server. The Components of the builds are read and annotated through the given
+// client once the authorizer allowed the request.
+func NewServer(client client.Client, authorizer statusapi.Authorizer, logger logr.Logger) *Server {
+	return &Server{
+		client:     client,
+		authorizer: authorizer,
+		logger:     logger,
+	}
+}
+
+// Handler returns the handler of the builds.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebhookPathPrefix, s.handleWebhook)
+	return mux
+}
+
+// handleWebhook routes the build requests, the supported path is
+// /api/v1/namespaces/<namespace>/components/<component>/builds.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		httpserver.WriteError(w, http.StatusMethodNotAllowed, "only the POST method is supported", s.logger)
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, WebhookPathPrefix), "/")
+	if len(segments) != 4 || segments[1] != "components" || segments[3] != "builds" || segments[0] == "" || segments[2] == "" {
+		httpserver.WriteError(w, http.StatusNotFound, "the requested path is not supported", s.logger)
+		return
+	}
+
+	s.handleBuild(w, r, segments[0], segments[2])
+}
+
+// handleBuild announces the built image through the PushedImageAnnotation of the Component.
+func (s *Server) handleBuild(w http.ResponseWriter, r *http.Request, namespace, componentName string) {
+	if !s.authorize(w, r, namespace, componentName) {
+		return
+	}
+
+	build := &BuildRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(build); err != nil {
+		httpserver.WriteError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse the build: %s", err), s.logger)
+		return
+	}
+	image, err := GetImagePullSpec(build)
+	if err != nil {
+		httpserver.WriteError(w, http.StatusBadRequest, err.Error(), s.logger)
+		return
+	}
+	logger := s.logger.WithValues("component.Namespace", namespace, "component.Name", componentName, "image", image)
+
+	component := &applicationapiv1alpha1.Component{}
+	err = s.client.Get(r.Context(), client.ObjectKey{Namespace: namespace, Name: componentName}, component)
+	if err != nil {
+		if clienterrors.IsNotFound(err) {
+			httpserver.WriteError(w, http.StatusNotFound, fmt.Sprintf("component %s not found", componentName), s.logger)
+			return
+		}
+		logger.Error(err, "Failed to get the component of the build")
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to get the component", s.logger)
+		return
+	}
+
+	err = helpers.ApplyMetadata(r.Context(), s.client, component, nil, map[string]string{gitops.PushedImageAnnotation: image})
+	if err != nil {
+		if clienterrors.IsNotFound(err) {
+			httpserver.WriteError(w, http.StatusNotFound, fmt.Sprintf("component %s not found", componentName), s.logger)
+			return
+		}
+		logger.Error(err, "Failed to annotate the component with the built image")
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to annotate the component", s.logger)
+		return
+	}
+	logger.Info("Announced the image built by an external CI system")
+
+	httpserver.WriteJSON(w, http.StatusAccepted, &httpserver.Response{
+		Message: fmt.Sprintf("composing a snapshot with image %s for component %s/%s", image, namespace, componentName),
+	}, s.logger)
+}
+
+// authorize checks that the bearer token of the request allows patching the Component. The error response is
+// written when the request isn't allowed.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, namespace, componentName string) bool {
+	return statusapi.AuthorizeRequest(w, r, s.authorizer, &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "patch",
+		Group:     applicationapiv1alpha1.GroupVersion.Group,
+		Version:   applicationapiv1alpha1.GroupVersion.Version,
+		Resource:  "components",
+		Name:      componentName,
+	}, s.logger)
+}
+
+// GetImagePullSpec returns the pull spec by digest of the image of the build. The tag of the image is dropped when
+// the digest is set separately. An error is returned when the image isn't referenced by a valid digest.
+func GetImagePullSpec(build *BuildRequest) (string, error) {
+	if build.Image == "" {
+		return "", fmt.Errorf("the image of the build is missing")
+	}
+
+	image := build.Image
+	if build.Digest != "" {
+		reference, err := name.ParseReference(build.Image)
+		if err != nil {
+			return "", fmt.Errorf("invalid image %q: %w", build.Image, err)
+		}
+		image = fmt.Sprintf("%s@%s", reference.Context().Name(), build.Digest)
+	}
+	if err := gitops.ValidateImageDigest(image); err != nil {
+		return "", fmt.Errorf("the image %q isn't referenced by a valid digest: %w", image, err)
+	}
+
+	return image, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildwebhook_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/buildwebhook"
+	"github.com/konflux-ci/integration-service/pkg/statusapi"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var sampleDigest = "sha256:" + strings.Repeat("a", 64)
+
+// fakeAuthorizer allows the requests bearing the allowed token and records the authorized resource attributes.
+type fakeAuthorizer struct {
+	allowedToken string
+	attributes   []*authorizationv1.ResourceAttributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error {
+	a.attributes = append(a.attributes, attributes)
+	if token == "" {
+		return statusapi.ErrUnauthenticated
+	}
+	if token != a.allowedToken {
+		return statusapi.ErrForbidden
+	}
+	return nil
+}
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(applicationapiv1alpha1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+var _ = Describe("Build webhook", func() {
+
+	var (
+		k8sClient  client.Client
+		authorizer *fakeAuthorizer
+		server     *httptest.Server
+	)
+
+	post := func(path, token string, build any) (*http.Response, map[string]any) {
+		payload, err := json.Marshal(build)
+		Expect(err).NotTo(HaveOccurred())
+		request, err := http.NewRequest(http.MethodPost, server.URL+path, bytes.NewReader(payload))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		body := map[string]any{}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return resp, body
+	}
+
+	getPushedImage := func() string {
+		component := &applicationapiv1alpha1.Component{}
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "component-sample"}, component)).To(Succeed())
+		return component.GetAnnotations()[gitops.PushedImageAnnotation]
+	}
+
+	BeforeEach(func() {
+		component := &applicationapiv1alpha1.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "component-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.ComponentSpec{
+				ComponentName: "component-sample",
+				Application:   "application-sample",
+			},
+		}
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(newScheme()).
+			WithObjects(component).
+			// the fake client doesn't support server-side apply, the applied annotations are merged instead
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if patch.Type() != types.ApplyPatchType {
+						return c.Patch(ctx, obj, patch, opts...)
+					}
+					data, err := patch.Data(obj)
+					if err != nil {
+						return err
+					}
+					return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+				},
+			}).
+			Build()
+		authorizer = &fakeAuthorizer{allowedToken: "allowed-token"}
+		server = httptest.NewServer(buildwebhook.NewServer(k8sClient, authorizer, logr.Discard()).Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("announces the built image through the annotation of the component", func() {
+		resp, body := post("/api/v1/namespaces/default/components/component-sample/builds", "allowed-token",
+			&buildwebhook.BuildRequest{Image: "quay.io/sample/component:v1", Digest: sampleDigest})
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(body).To(HaveKeyWithValue("message", ContainSubstring("component default/component-sample")))
+		Expect(getPushedImage()).To(Equal("quay.io/sample/component@" + sampleDigest))
+
+		Expect(authorizer.attributes).To(ConsistOf(&authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "patch",
+			Group:     "appstudio.redhat.com",
+			Version:   "v1alpha1",
+			Resource:  "components",
+			Name:      "component-sample",
+		}))
+	})
+
+	It("accepts the images referenced by digest", func() {
+		resp, _ := post("/api/v1/namespaces/default/components/component-sample/builds", "allowed-token",
+			&buildwebhook.BuildRequest{Image: "quay.io/sample/component@" + sampleDigest})
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(getPushedImage()).To(Equal("quay.io/sample/component@" + sampleDigest))
+	})
+
+	It("rejects the images without a valid digest", func() {
+		resp, body := post("/api/v1/namespaces/default/components/component-sample/builds", "allowed-token",
+			&buildwebhook.BuildRequest{Image: "quay.io/sample/component:v1"})
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(body).To(HaveKey("error"))
+
+		resp, _ = post("/api/v1/namespaces/default/components/component-sample/builds", "allowed-token",
+			&buildwebhook.BuildRequest{Image: "quay.io/sample/component", Digest: "sha256:abc"})
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(getPushedImage()).To(BeEmpty())
+	})
+
+	It("rejects the requests which aren't authorized", func() {
+		build := &buildwebhook.BuildRequest{Image: "quay.io/sample/component@" + sampleDigest}
+		resp, _ := post("/api/v1/namespaces/default/components/component-sample/builds", "", build)
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(resp.Header.Get("WWW-Authenticate")).To(Equal("Bearer"))
+
+		resp, _ = post("/api/v1/namespaces/default/components/component-sample/builds", "other-token", build)
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(getPushedImage()).To(BeEmpty())
+	})
+
+	It("returns not found for the unknown components and paths", func() {
+		build := &buildwebhook.BuildRequest{Image: "quay.io/sample/component@" + sampleDigest}
+		resp, body := post("/api/v1/namespaces/default/components/component-missing/builds", "allowed-token", build)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(body).To(HaveKeyWithValue("error", "component component-missing not found"))
+
+		resp, _ = post("/api/v1/namespaces/default/components/component-sample", "allowed-token", build)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("only supports the POST method", func() {
+		resp, err := http.Get(server.URL + "/api/v1/namespaces/default/components/component-sample/builds")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		Expect(resp.Header.Get("Allow")).To(Equal(http.MethodPost))
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Annotation contains the correlation ID of the reconcile which created the resource, or emitted the event.
+	Annotation = "test.appstudio.openshift.io/correlation-id"
+
+	// LogKey is the key of the correlation ID of the reconcile in the log lines.
+	LogKey = "correlationID"
+
+	// ParentLogKey is the key of the correlation ID of the reconcile which created the reconciled resource
+	// in the log lines, it links the reconciles of the resources created along the journey of a Snapshot.
+	ParentLogKey = "parentCorrelationID"
+)
+
+// NewID generates a new random correlation ID for a reconcile.
+func NewID() string {
+	id := make([]byte, 16)
+	// crypto/rand.Read never fails on the supported platforms
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// GetID returns the correlation ID of the reconcile which created the given object, or an empty string
+// if the object wasn't created by a correlated reconcile.
+func GetID(obj client.Object) string {
+	return obj.GetAnnotations()[Annotation]
+}
+
+// WithParentID returns the logger with the correlation ID of the reconcile which created the given object,
+// if there is one.
+func WithParentID(logger logr.Logger, obj client.Object) logr.Logger {
+	if parentID := GetID(obj); parentID != "" {
+		return logger.WithValues(ParentLogKey, parentID)
+	}
+	return logger
+}
+
+// NewClient returns a client annotating the resources it creates with the given correlation ID.
+func NewClient(c client.Client, id string) client.Client {
+	return &correlatedClient{Client: c, id: id}
+}
+
+// NewRecorder returns an event recorder annotating the events it emits with the given correlation ID.
+func NewRecorder(recorder record.EventRecorder, id string) record.EventRecorder {
+	return &correlatedRecorder{recorder: recorder, id: id}
+}
+
+type correlatedClient struct {
+	client.Client
+	id string
+}
+
+func (c *correlatedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	// the annotation copied from the parent resource, if any, is replaced by the ID of the reconcile creating it
+	annotations[Annotation] = c.id
+	obj.SetAnnotations(annotations)
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+type correlatedRecorder struct {
+	recorder record.EventRecorder
+	id       string
+}
+
+func (r *correlatedRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+func (r *correlatedRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+func (r *correlatedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	correlatedAnnotations := map[string]string{Annotation: r.id}
+	for key, value := range annotations {
+		correlatedAnnotations[key] = value
+	}
+	r.recorder.AnnotatedEventf(object, correlatedAnnotations, eventtype, reason, messageFmt, args...)
+}
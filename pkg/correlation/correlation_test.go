@@ -0,0 +1,102 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package correlation_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tonglil/buflogr"
+
+	"github.com/konflux-ci/integration-service/pkg/correlation"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Correlation", func() {
+
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		snapshot   *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(applicationapiv1alpha1.AddToScheme(scheme)).To(Succeed())
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+	})
+
+	It("generates a new ID for each reconcile", func() {
+		id := correlation.NewID()
+		Expect(id).To(HaveLen(32))
+		Expect(correlation.NewID()).NotTo(Equal(id))
+	})
+
+	It("annotates the created resources with the correlation ID", func() {
+		snapshot.Annotations = map[string]string{correlation.Annotation: "parent-id", "other": "value"}
+		Expect(correlation.NewClient(fakeClient, "reconcile-id").Create(ctx, snapshot)).To(Succeed())
+
+		createdSnapshot := &applicationapiv1alpha1.Snapshot{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(snapshot), createdSnapshot)).To(Succeed())
+		Expect(correlation.GetID(createdSnapshot)).To(Equal("reconcile-id"))
+		Expect(createdSnapshot.Annotations).To(HaveKeyWithValue("other", "value"))
+	})
+
+	It("adds the correlation ID of the reconcile which created the resource to the logger", func() {
+		var buf bytes.Buffer
+		logger := buflogr.NewWithBuffer(&buf)
+
+		correlation.WithParentID(logger, snapshot).Info("without parent")
+		Expect(buf.String()).NotTo(ContainSubstring(correlation.ParentLogKey))
+
+		snapshot.Annotations = map[string]string{correlation.Annotation: "parent-id"}
+		correlation.WithParentID(logger, snapshot).Info("with parent")
+		Expect(buf.String()).To(ContainSubstring(correlation.ParentLogKey + " parent-id"))
+	})
+
+	It("annotates the emitted events with the correlation ID", func() {
+		fakeRecorder := record.NewFakeRecorder(3)
+		recorder := correlation.NewRecorder(fakeRecorder, "reconcile-id")
+
+		recorder.Event(snapshot, corev1.EventTypeNormal, "Passed", "100% passed")
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal Passed 100% passed map[" + correlation.Annotation + ":reconcile-id]")))
+
+		recorder.Eventf(snapshot, corev1.EventTypeWarning, "Failed", "%d tests failed", 2)
+		Expect(fakeRecorder.Events).To(Receive(Equal("Warning Failed 2 tests failed map[" + correlation.Annotation + ":reconcile-id]")))
+
+		recorder.AnnotatedEventf(snapshot, map[string]string{"other": "value"}, corev1.EventTypeNormal, "Passed", "passed")
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal Passed passed map[other:value " + correlation.Annotation + ":reconcile-id]")))
+	})
+})
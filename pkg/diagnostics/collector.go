@@ -0,0 +1,216 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"runtime"
+	"sort"
+
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/prometheus/client_golang/prometheus"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// queueMetrics maps the names of the workqueue and controller metrics to the setters of the matching QueueState
+// fields. The workqueue metrics are labeled by the name of the queue and the controller metrics by the name of the
+// controller, which are the same.
+var queueMetrics = map[string]func(q *QueueState, value float64){
+	"workqueue_depth":                             func(q *QueueState, v float64) { q.Depth = int64(v) },
+	"workqueue_adds_total":                        func(q *QueueState, v float64) { q.Adds = int64(v) },
+	"workqueue_retries_total":                     func(q *QueueState, v float64) { q.Retries = int64(v) },
+	"workqueue_unfinished_work_seconds":           func(q *QueueState, v float64) { q.UnfinishedWorkSeconds = v },
+	"workqueue_longest_running_processor_seconds": func(q *QueueState, v float64) { q.LongestRunningProcessorSeconds = v },
+	"controller_runtime_active_workers":           func(q *QueueState, v float64) { q.ActiveWorkers = int64(v) },
+	"controller_runtime_max_concurrent_reconciles": func(q *QueueState, v float64) {
+		q.MaxConcurrentReconciles = int64(v)
+	},
+}
+
+// watchedKinds are the kinds watched by the controllers with the constructors of their lists. Only these kinds are
+// counted, since listing any other kind would start a new informer caching all of its objects.
+var watchedKinds = []struct {
+	kind    string
+	newList func() client.ObjectList
+}{
+	{"Component", func() client.ObjectList { return &applicationapiv1alpha1.ComponentList{} }},
+	{"Snapshot", func() client.ObjectList { return &applicationapiv1alpha1.SnapshotList{} }},
+	{"IntegrationTestScenario", func() client.ObjectList { return &v1beta2.IntegrationTestScenarioList{} }},
+	{"IntegrationPolicy", func() client.ObjectList { return &v1beta2.IntegrationPolicyList{} }},
+	{"SnapshotRun", func() client.ObjectList { return &v1beta2.SnapshotRunList{} }},
+	{"PipelineRun", func() client.ObjectList { return &tektonv1.PipelineRunList{} }},
+}
+
+// ControllersState is the state of the controller queues, of the cache and of the Go runtime of the operator.
+type ControllersState struct {
+	// Queues are the states of the work queues of the controllers, sorted by controller name
+	Queues []QueueState `json:"queues"`
+	// Cache are the numbers of cached objects of the kinds watched by the controllers
+	Cache []CacheState `json:"cache"`
+	// Runtime is the state of the Go runtime
+	Runtime RuntimeState `json:"runtime"`
+}
+
+// QueueState is the state of the work queue of a controller.
+type QueueState struct {
+	// Name of the controller
+	Name string `json:"name"`
+	// Depth is the number of requests waiting in the queue
+	Depth int64 `json:"depth"`
+	// Adds is the total number of requests added to the queue
+	Adds int64 `json:"adds"`
+	// Retries is the total number of requests requeued with a backoff
+	Retries int64 `json:"retries"`
+	// UnfinishedWorkSeconds is the time spent by the reconciliations in progress
+	UnfinishedWorkSeconds float64 `json:"unfinishedWorkSeconds"`
+	// LongestRunningProcessorSeconds is the time spent by the longest reconciliation in progress
+	LongestRunningProcessorSeconds float64 `json:"longestRunningProcessorSeconds"`
+	// ActiveWorkers is the number of reconciliations in progress
+	ActiveWorkers int64 `json:"activeWorkers"`
+	// MaxConcurrentReconciles is the maximum number of reconciliations of the controller running at the same time
+	MaxConcurrentReconciles int64 `json:"maxConcurrentReconciles"`
+}
+
+// CacheState is the number of cached objects of a kind.
+type CacheState struct {
+	// Kind of the cached objects
+	Kind string `json:"kind"`
+	// Objects is the number of cached objects of the kind
+	Objects int `json:"objects"`
+	// Error is the reason the cached objects couldn't be counted
+	Error string `json:"error,omitempty"`
+}
+
+// RuntimeState is the state of the Go runtime.
+type RuntimeState struct {
+	// Goroutines is the number of goroutines
+	Goroutines int `json:"goroutines"`
+	// HeapAllocBytes is the size of the allocated heap objects
+	HeapAllocBytes uint64 `json:"heapAllocBytes"`
+	// HeapObjects is the number of allocated heap objects
+	HeapObjects uint64 `json:"heapObjects"`
+	// HeapSysBytes is the size of the heap memory obtained from the operating system
+	HeapSysBytes uint64 `json:"heapSysBytes"`
+	// NumGC is the number of completed garbage collections
+	NumGC uint32 `json:"numGC"`
+}
+
+// Collector collects the state of the controller queues from the registered metrics and counts the cached objects.
+type Collector struct {
+	reader   client.Reader
+	gatherer prometheus.Gatherer
+}
+
+// NewCollector creates and returns a Collector counting the cached objects through the given reader and reading
+// the controller queues from the given gatherer.
+func NewCollector(reader client.Reader, gatherer prometheus.Gatherer) *Collector {
+	return &Collector{
+		reader:   reader,
+		gatherer: gatherer,
+	}
+}
+
+// Collect returns the current state of the controller queues, of the cache and of the Go runtime.
+func (c *Collector) Collect(ctx context.Context) (*ControllersState, error) {
+	queues, err := c.collectQueues()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ControllersState{
+		Queues:  queues,
+		Cache:   c.collectCache(ctx),
+		Runtime: collectRuntime(),
+	}, nil
+}
+
+// collectQueues returns the states of the work queues of the controllers gathered from their metrics.
+func (c *Collector) collectQueues() ([]QueueState, error) {
+	families, err := c.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	queuesByName := map[string]*QueueState{}
+	for _, family := range families {
+		setter, ok := queueMetrics[family.GetName()]
+		if !ok {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			name := ""
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "name" || label.GetName() == "controller" {
+					name = label.GetValue()
+				}
+			}
+			if name == "" {
+				continue
+			}
+			queue, ok := queuesByName[name]
+			if !ok {
+				queue = &QueueState{Name: name}
+				queuesByName[name] = queue
+			}
+			switch {
+			case metric.GetGauge() != nil:
+				setter(queue, metric.GetGauge().GetValue())
+			case metric.GetCounter() != nil:
+				setter(queue, metric.GetCounter().GetValue())
+			}
+		}
+	}
+
+	queues := make([]QueueState, 0, len(queuesByName))
+	for _, queue := range queuesByName {
+		queues = append(queues, *queue)
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	return queues, nil
+}
+
+// collectCache counts the cached objects of the kinds watched by the controllers, without copying them.
+func (c *Collector) collectCache(ctx context.Context) []CacheState {
+	var cache []CacheState
+	for _, watched := range watchedKinds {
+		state := CacheState{Kind: watched.kind}
+		list := watched.newList()
+		if err := c.reader.List(ctx, list, client.UnsafeDisableDeepCopy); err != nil {
+			state.Error = err.Error()
+		} else {
+			state.Objects = meta.LenList(list)
+		}
+		cache = append(cache, state)
+	}
+	return cache
+}
+
+// collectRuntime returns the current state of the Go runtime.
+func collectRuntime() RuntimeState {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return RuntimeState{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: memStats.HeapAlloc,
+		HeapObjects:    memStats.HeapObjects,
+		HeapSysBytes:   memStats.HeapSys,
+		NumGC:          memStats.NumGC,
+	}
+}
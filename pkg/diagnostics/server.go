@@ -0,0 +1,151 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+const (
+	// LivenessPath is the path of the liveness probe endpoint.
+	LivenessPath = "/healthz"
+
+	// ReadinessPath is the path of the readiness probe endpoint.
+	ReadinessPath = "/readyz"
+
+	// PprofPath is the path prefix of the pprof endpoints.
+	PprofPath = "/debug/pprof/"
+
+	// ExpvarPath is the path of the endpoint serving the expvar variables.
+	ExpvarPath = "/debug/vars"
+
+	// ControllersPath is the path of the endpoint dumping the state of the controller queues and of the cache.
+	ControllersPath = "/debug/controllers"
+)
+
+// Server serves the liveness and readiness probes of the operator together with the pprof and expvar endpoints and
+// a dump of the state of the controller queues and of the cache, which are used to debug the memory growth and the
+// reconciliation backlogs of large deployments. It replaces the probe endpoint of the manager.
+type Server struct {
+	address       string
+	healthzChecks map[string]healthz.Checker
+	readyzChecks  map[string]healthz.Checker
+	collector     *Collector
+	logger        logr.Logger
+}
+
+// NewServer creates and returns a Server listening on the given address, serving the given liveness and readiness
+// checks. The controller queues are read from the given gatherer and the cached objects are counted through the
+// given reader.
+func NewServer(address string, healthzChecks, readyzChecks map[string]healthz.Checker, reader client.Reader,
+	gatherer prometheus.Gatherer, logger logr.Logger) *Server {
+	return &Server{
+		address:       address,
+		healthzChecks: healthzChecks,
+		readyzChecks:  readyzChecks,
+		collector:     NewCollector(reader, gatherer),
+		logger:        logger,
+	}
+}
+
+// NeedLeaderElection returns false since the probes of every replica of the manager have to be served.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the probe and debug endpoints until the context is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.address, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error(err, "failed to shut down the diagnostics server")
+		}
+	}()
+
+	s.logger.Info("Starting the diagnostics server", "address", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Handler returns the handler of the probe and debug requests.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	healthzHandler := &healthz.Handler{Checks: s.healthzChecks}
+	mux.Handle(LivenessPath, http.StripPrefix(LivenessPath, healthzHandler))
+	mux.Handle(LivenessPath+"/", http.StripPrefix(LivenessPath, healthzHandler))
+	readyzHandler := &healthz.Handler{Checks: s.readyzChecks}
+	mux.Handle(ReadinessPath, http.StripPrefix(ReadinessPath, readyzHandler))
+	mux.Handle(ReadinessPath+"/", http.StripPrefix(ReadinessPath, readyzHandler))
+
+	mux.HandleFunc(PprofPath, pprof.Index)
+	mux.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(PprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(PprofPath+"trace", pprof.Trace)
+	mux.Handle(ExpvarPath, expvar.Handler())
+	mux.HandleFunc(ControllersPath, s.handleControllers)
+	return mux
+}
+
+// handleControllers returns the state of the controller queues, of the cache and of the Go runtime.
+func (s *Server) handleControllers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only the GET method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, err := s.collector.Collect(r.Context())
+	if err != nil {
+		s.logger.Error(err, "Failed to collect the state of the controllers")
+		http.Error(w, "failed to collect the state of the controllers", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		s.logger.Error(err, "Failed to write the state of the controllers")
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnostics_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/api/v1beta2"
+	"github.com/konflux-ci/integration-service/pkg/diagnostics"
+	"github.com/prometheus/client_golang/prometheus"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(applicationapiv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(v1beta2.AddToScheme(scheme)).To(Succeed())
+	Expect(tektonv1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+var _ = Describe("Diagnostics server", func() {
+
+	var (
+		ready  bool
+		server *httptest.Server
+	)
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	BeforeEach(func() {
+		ready = true
+		registry := prometheus.NewRegistry()
+		depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "workqueue_depth"}, []string{"name"})
+		retries := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "workqueue_retries_total"}, []string{"name"})
+		activeWorkers := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "controller_runtime_active_workers"}, []string{"controller"})
+		unrelated := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "unrelated_gauge"}, []string{"name"})
+		registry.MustRegister(depth, retries, activeWorkers, unrelated)
+		depth.WithLabelValues("snapshot").Set(42)
+		depth.WithLabelValues("component").Set(1)
+		retries.WithLabelValues("snapshot").Add(3)
+		activeWorkers.WithLabelValues("snapshot").Set(2)
+		unrelated.WithLabelValues("unrelated").Set(7)
+
+		reader := fake.NewClientBuilder().
+			WithScheme(newScheme()).
+			WithObjects(
+				&applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-a", Namespace: "default"}},
+				&applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-b", Namespace: "default"}},
+				&tektonv1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-a", Namespace: "default"}},
+			).
+			Build()
+		healthzChecks := map[string]healthz.Checker{"healthz": healthz.Ping}
+		readyzChecks := map[string]healthz.Checker{
+			"readyz": healthz.Ping,
+			"informers": func(_ *http.Request) error {
+				if !ready {
+					return errors.New("the informers aren't synced")
+				}
+				return nil
+			},
+		}
+		server = httptest.NewServer(diagnostics.NewServer("", healthzChecks, readyzChecks, reader, registry, logr.Discard()).Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves the liveness and readiness checks", func() {
+		resp, _ := get(diagnostics.LivenessPath)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		resp, _ = get(diagnostics.ReadinessPath)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		ready = false
+		resp, _ = get(diagnostics.ReadinessPath)
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		resp, _ = get(diagnostics.ReadinessPath + "/informers")
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		resp, _ = get(diagnostics.ReadinessPath + "/readyz")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("serves the pprof and expvar endpoints", func() {
+		resp, body := get(diagnostics.PprofPath)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("goroutine"))
+		resp, _ = get(diagnostics.PprofPath + "heap")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		resp, body = get(diagnostics.ExpvarPath)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring(`"memstats"`))
+	})
+
+	It("dumps the state of the controller queues and of the cache", func() {
+		resp, body := get(diagnostics.ControllersPath)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		state := &diagnostics.ControllersState{}
+		Expect(json.Unmarshal([]byte(body), state)).To(Succeed())
+		Expect(state.Queues).To(Equal([]diagnostics.QueueState{
+			{Name: "component", Depth: 1},
+			{Name: "snapshot", Depth: 42, Retries: 3, ActiveWorkers: 2},
+		}))
+		Expect(state.Cache).To(ContainElements(
+			diagnostics.CacheState{Kind: "Snapshot", Objects: 2},
+			diagnostics.CacheState{Kind: "PipelineRun", Objects: 1},
+			diagnostics.CacheState{Kind: "Component", Objects: 0},
+		))
+		Expect(state.Cache).To(HaveLen(6))
+		Expect(state.Runtime.Goroutines).To(BeNumerically(">", 0))
+		Expect(state.Runtime.HeapAllocBytes).To(BeNumerically(">", 0))
+	})
+
+	It("rejects the methods other than GET on the controllers dump", func() {
+		resp, err := http.Post(server.URL+diagnostics.ControllersPath, "application/json", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// enabled is true when the operator runs in dry-run mode, in which the decisions of the controllers are logged
+// without mutating the cluster or calling the external services, e.g. the git providers.
+var enabled atomic.Bool
+
+// Enable turns the dry-run mode on.
+func Enable() {
+	enabled.Store(true)
+}
+
+// IsEnabled returns true if the operator runs in dry-run mode.
+func IsEnabled() bool {
+	return enabled.Load()
+}
+
+// NewManager returns a manager whose client and event recorders don't mutate the cluster, logging the skipped writes
+// instead. The objects are still read from the cache of the given manager, and the writes are validated by the API
+// server through server-side dry-run requests.
+func NewManager(mgr manager.Manager, logger logr.Logger) manager.Manager {
+	return &dryRunManager{
+		Manager: mgr,
+		client:  NewClient(mgr.GetClient(), logger),
+		logger:  logger,
+	}
+}
+
+// NewClient returns a client sending all its writes as server-side dry-run requests, logging each of them.
+func NewClient(c client.Client, logger logr.Logger) client.Client {
+	return &dryRunClient{Client: client.NewDryRunClient(c), logger: logger}
+}
+
+type dryRunManager struct {
+	manager.Manager
+	client client.Client
+	logger logr.Logger
+}
+
+// GetClient returns the dry-run client of the manager.
+func (m *dryRunManager) GetClient() client.Client {
+	return m.client
+}
+
+// GetEventRecorderFor returns a recorder logging the events instead of emitting them.
+func (m *dryRunManager) GetEventRecorderFor(name string) record.EventRecorder {
+	return &dryRunRecorder{logger: m.logger.WithValues("recorder", name)}
+}
+
+type dryRunClient struct {
+	client.Client
+	logger logr.Logger
+}
+
+// logWrite logs the write of the given object which isn't persisted.
+func (c *dryRunClient) logWrite(verb string, obj client.Object, subResource string) {
+	kind := ""
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		kind = gvk.Kind
+	}
+	keysAndValues := []any{"verb", verb, "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName()}
+	if obj.GetName() == "" {
+		keysAndValues = append(keysAndValues, "generateName", obj.GetGenerateName())
+	}
+	if subResource != "" {
+		keysAndValues = append(keysAndValues, "subResource", subResource)
+	}
+	c.logger.Info("Dry-run: skipping the write to the cluster", keysAndValues...)
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.logWrite("create", obj, "")
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.logWrite("update", obj, "")
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.logWrite("patch", obj, "")
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.logWrite("delete", obj, "")
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.logWrite("deletecollection", obj, "")
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *dryRunClient) SubResource(subResource string) client.SubResourceClient {
+	return &dryRunSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), client: c, subResource: subResource}
+}
+
+type dryRunSubResourceClient struct {
+	client.SubResourceClient
+	client      *dryRunClient
+	subResource string
+}
+
+func (c *dryRunSubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	c.client.logWrite("create", obj, c.subResource)
+	return c.SubResourceClient.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *dryRunSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	c.client.logWrite("update", obj, c.subResource)
+	return c.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+func (c *dryRunSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	c.client.logWrite("patch", obj, c.subResource)
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
+
+type dryRunRecorder struct {
+	logger logr.Logger
+}
+
+func (r *dryRunRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.log(object, eventtype, reason, "%s", message)
+}
+
+func (r *dryRunRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.log(object, eventtype, reason, messageFmt, args...)
+}
+
+func (r *dryRunRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.log(object, eventtype, reason, messageFmt, args...)
+}
+
+// log logs the event which isn't emitted.
+func (r *dryRunRecorder) log(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	keysAndValues := []any{"type", eventtype, "reason", reason, "message", fmt.Sprintf(messageFmt, args...)}
+	if obj, ok := object.(client.Object); ok {
+		keysAndValues = append(keysAndValues, "namespace", obj.GetNamespace(), "name", obj.GetName())
+	}
+	r.logger.Info("Dry-run: skipping the event", keysAndValues...)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tonglil/buflogr"
+
+	"github.com/konflux-ci/integration-service/pkg/dryrun"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Dry-run client", func() {
+
+	var (
+		ctx        context.Context
+		buf        bytes.Buffer
+		fakeClient client.Client
+		snapshot   *applicationapiv1alpha1.Snapshot
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		buf.Reset()
+		scheme := runtime.NewScheme()
+		Expect(applicationapiv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+			Spec: applicationapiv1alpha1.SnapshotSpec{
+				Application: "application-sample",
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(snapshot).
+			WithStatusSubresource(snapshot).Build()
+	})
+
+	It("is disabled by default", func() {
+		Expect(dryrun.IsEnabled()).To(BeFalse())
+	})
+
+	It("logs the writes without persisting them", func() {
+		dryRunClient := dryrun.NewClient(fakeClient, buflogr.NewWithBuffer(&buf))
+
+		newSnapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-new",
+				Namespace: "default",
+			},
+		}
+		Expect(dryRunClient.Create(ctx, newSnapshot)).To(Succeed())
+		err := fakeClient.Get(ctx, client.ObjectKeyFromObject(newSnapshot), &applicationapiv1alpha1.Snapshot{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(buf.String()).To(ContainSubstring("Dry-run: skipping the write to the cluster"))
+		Expect(buf.String()).To(ContainSubstring("verb create kind Snapshot namespace default name snapshot-new"))
+
+		patch := client.MergeFrom(snapshot.DeepCopy())
+		snapshot.Annotations = map[string]string{"test.appstudio.openshift.io/status": "[]"}
+		Expect(dryRunClient.Patch(ctx, snapshot, patch)).To(Succeed())
+		Expect(dryRunClient.Delete(ctx, snapshot)).To(Succeed())
+
+		stored := &applicationapiv1alpha1.Snapshot{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(snapshot), stored)).To(Succeed())
+		Expect(stored.Annotations).To(BeEmpty())
+		Expect(buf.String()).To(ContainSubstring("verb patch"))
+		Expect(buf.String()).To(ContainSubstring("verb delete"))
+	})
+
+	It("logs the writes of the status without persisting them", func() {
+		dryRunClient := dryrun.NewClient(fakeClient, buflogr.NewWithBuffer(&buf))
+
+		snapshot.Status.Conditions = []metav1.Condition{{
+			Type:               "AppStudioTestSucceeded",
+			Status:             metav1.ConditionTrue,
+			Reason:             "Passed",
+			LastTransitionTime: metav1.Now(),
+		}}
+		Expect(dryRunClient.Status().Update(ctx, snapshot)).To(Succeed())
+
+		stored := &applicationapiv1alpha1.Snapshot{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(snapshot), stored)).To(Succeed())
+		Expect(stored.Status.Conditions).To(BeEmpty())
+		Expect(buf.String()).To(ContainSubstring("verb update kind Snapshot namespace default name snapshot-sample subResource status"))
+	})
+
+	It("reads the objects through the wrapped client", func() {
+		dryRunClient := dryrun.NewClient(fakeClient, buflogr.NewWithBuffer(&buf))
+
+		stored := &applicationapiv1alpha1.Snapshot{}
+		Expect(dryRunClient.Get(ctx, client.ObjectKeyFromObject(snapshot), stored)).To(Succeed())
+		Expect(stored.Spec.Application).To(Equal("application-sample"))
+		Expect(buf.String()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package githubwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	ghapi "github.com/google/go-github/v45/github"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
+	"github.com/konflux-ci/integration-service/status"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// WebhookPath is the path the GitHub webhooks are delivered to.
+	WebhookPath = "/github/webhook"
+
+	// WebhookSecretNamespace is the namespace of the secret holding the secret of the GitHub webhooks.
+	WebhookSecretNamespace = "integration-service"
+
+	// WebhookSecretName is the name of the secret holding the secret of the GitHub webhooks, which is shared
+	// with the Pipelines as Code GitHub App.
+	WebhookSecretName = status.PACSecret
+
+	// WebhookSecretKey is the key of the secret of the GitHub webhooks in the secret.
+	WebhookSecretKey = "webhook.secret"
+
+	// checkRunEventType is the type of the GitHub webhooks delivered for the check run events.
+	checkRunEventType = "check_run"
+
+	// checkRunRerequestedAction is the action of the check run events delivered when the check run is re-run.
+	checkRunRerequestedAction = "rerequested"
+
+	// maxPayloadSize is the maximum size of the payload of the GitHub webhooks, which is read before its signature
+	// can be validated.
+	maxPayloadSize = 1024 * 1024
+)
+
+// Server receives the GitHub webhooks of the check runs created for the integration tests and re-runs the
+// integration test whose check run was re-requested from the GitHub UI.
+type Server struct {
+	client       client.Client
+	secretReader client.Reader
+	logger       logr.Logger
+}
+
+// NewServer creates and returns a Server. The Snapshots of the re-requested check runs are read and labeled through
+// the given client. The secret of the webhooks is read through the given secret reader, which has to be uncached
+// since the integration-service namespace isn't necessarily watched by the cache of the manager.
+func NewServer(client client.Client, secretReader client.Reader, logger logr.Logger) *Server {
+	return &Server{
+		client:       client,
+		secretReader: secretReader,
+		logger:       logger,
+	}
+}
+
+// Handler returns the handler of the GitHub webhooks.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebhookPath, s.handleWebhook)
+	return mux
+}
+
+// handleWebhook validates the signature of the GitHub webhook and re-runs the integration test of the re-requested
+// check run. The other events are acknowledged and ignored.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		httpserver.WriteError(w, http.StatusMethodNotAllowed, "only the POST method is supported", s.logger)
+		return
+	}
+
+	secret, err := s.getWebhookSecret(r.Context())
+	if err != nil {
+		s.logger.Error(err, "Failed to get the secret of the GitHub webhooks")
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to get the secret of the webhooks", s.logger)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPayloadSize)
+	payload, err := ghapi.ValidatePayload(r, secret)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		httpserver.WriteError(w, http.StatusRequestEntityTooLarge, "the webhook payload is too large", s.logger)
+		return
+	}
+	if err != nil {
+		httpserver.WriteError(w, http.StatusUnauthorized, "invalid webhook signature", s.logger)
+		return
+	}
+
+	if ghapi.WebHookType(r) != checkRunEventType {
+		httpserver.WriteJSON(w, http.StatusOK, &httpserver.Response{Message: "event ignored"}, s.logger)
+		return
+	}
+
+	event := &ghapi.CheckRunEvent{}
+	if err := json.Unmarshal(payload, event); err != nil {
+		httpserver.WriteError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse the check run event: %s", err), s.logger)
+		return
+	}
+	if event.GetAction() != checkRunRerequestedAction {
+		httpserver.WriteJSON(w, http.StatusOK, &httpserver.Response{Message: "event ignored"}, s.logger)
+		return
+	}
+
+	s.handleCheckRunRerequested(w, r, event)
+}
+
+// handleCheckRunRerequested labels the latest Snapshot the re-requested check run was reported for, so that
+// the integration test of the check run is re-run.
+func (s *Server) handleCheckRunRerequested(w http.ResponseWriter, r *http.Request, event *ghapi.CheckRunEvent) {
+	owner := event.GetRepo().GetOwner().GetLogin()
+	repo := event.GetRepo().GetName()
+	sha := event.GetCheckRun().GetHeadSHA()
+	externalID := event.GetCheckRun().GetExternalID()
+	logger := s.logger.WithValues("owner", owner, "repo", repo, "sha", sha, "checkRun.ExternalID", externalID)
+
+	snapshots := &applicationapiv1alpha1.SnapshotList{}
+	err := s.client.List(r.Context(), snapshots, client.MatchingLabels{
+		gitops.PipelineAsCodeURLOrgLabel:        owner,
+		gitops.PipelineAsCodeURLRepositoryLabel: repo,
+		gitops.PipelineAsCodeSHALabel:           sha,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to list the snapshots of the re-requested check run")
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to list the snapshots", s.logger)
+		return
+	}
+
+	snapshot, scenarioName := findCheckRunSnapshot(snapshots.Items, externalID)
+	if snapshot == nil {
+		httpserver.WriteError(w, http.StatusNotFound, fmt.Sprintf("no snapshot found for check run %s of commit %s", externalID, sha), s.logger)
+		return
+	}
+
+	if err := gitops.AddIntegrationTestRerunLabel(r.Context(), s.client, snapshot, scenarioName); err != nil {
+		logger.Error(err, "Failed to label the snapshot for the re-run of the integration test",
+			"snapshot.Namespace", snapshot.Namespace, "snapshot.Name", snapshot.Name, "scenario.Name", scenarioName)
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to re-run the integration test", s.logger)
+		return
+	}
+	logger.Info("Requested the re-run of the integration test of the re-requested check run",
+		"snapshot.Namespace", snapshot.Namespace, "snapshot.Name", snapshot.Name, "scenario.Name", scenarioName)
+
+	httpserver.WriteJSON(w, http.StatusAccepted, &httpserver.Response{
+		Message: fmt.Sprintf("re-running scenario %s for snapshot %s/%s", scenarioName, snapshot.Namespace, snapshot.Name),
+	}, s.logger)
+}
+
+// getWebhookSecret returns the secret the GitHub webhooks are signed with.
+func (s *Server) getWebhookSecret(ctx context.Context) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := s.secretReader.Get(ctx, types.NamespacedName{Namespace: WebhookSecretNamespace, Name: WebhookSecretName}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	value, found := secret.Data[WebhookSecretKey]
+	if !found || len(value) == 0 {
+		return nil, fmt.Errorf("failed to find %s secret key", WebhookSecretKey)
+	}
+
+	return value, nil
+}
+
+// findCheckRunSnapshot returns the most recently created of the Snapshots which has the integration test the check
+// run with the given external ID was reported for, along with the name of its scenario. The external ID of the check
+// runs is the name of the scenario, suffixed with the name of the component for the component Snapshots.
+func findCheckRunSnapshot(snapshots []applicationapiv1alpha1.Snapshot, externalID string) (*applicationapiv1alpha1.Snapshot, string) {
+	var latest *applicationapiv1alpha1.Snapshot
+	latestScenarioName := ""
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(snapshot)
+		if err != nil {
+			continue
+		}
+
+		componentName := snapshot.GetLabels()[gitops.SnapshotComponentLabel]
+		for _, detail := range testStatuses.GetStatuses() {
+			if externalID != detail.ScenarioName && externalID != fmt.Sprintf("%s-%s", detail.ScenarioName, componentName) {
+				continue
+			}
+			if latest == nil || latest.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+				latest = snapshot
+				latestScenarioName = detail.ScenarioName
+			}
+		}
+	}
+
+	return latest, latestScenarioName
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package githubwebhook_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/githubwebhook"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const webhookSecret = "webhook-secret"
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(applicationapiv1alpha1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+func newSnapshot(name, component string, created time.Time) *applicationapiv1alpha1.Snapshot {
+	return &applicationapiv1alpha1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				gitops.ApplicationNameLabel:             "application-sample",
+				gitops.SnapshotComponentLabel:           component,
+				gitops.PipelineAsCodeURLOrgLabel:        "devfile-samples",
+				gitops.PipelineAsCodeURLRepositoryLabel: "devfile-sample-go-basic",
+				gitops.PipelineAsCodeSHALabel:           "abc123",
+			},
+			Annotations: map[string]string{
+				gitops.SnapshotTestsStatusAnnotation: `[{"scenario":"scenario-a","status":"TestFail","lastUpdateTime":"2024-05-14T10:30:00Z","details":"test failed","testPipelineRunName":"pipelinerun-a"}]`,
+			},
+		},
+		Spec: applicationapiv1alpha1.SnapshotSpec{
+			Application: "application-sample",
+		},
+	}
+}
+
+var _ = Describe("GitHub webhook", func() {
+
+	var (
+		k8sClient    client.Client
+		secretReader client.Reader
+		server       *httptest.Server
+	)
+
+	deliver := func(eventType string, event any, secret string) (*http.Response, map[string]any) {
+		payload, err := json.Marshal(event)
+		Expect(err).NotTo(HaveOccurred())
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+
+		request, err := http.NewRequest(http.MethodPost, server.URL+githubwebhook.WebhookPath, bytes.NewReader(payload))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-GitHub-Event", eventType)
+		request.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		resp, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		body := map[string]any{}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return resp, body
+	}
+
+	checkRunEvent := func(action, externalID string) map[string]any {
+		return map[string]any{
+			"action": action,
+			"check_run": map[string]any{
+				"head_sha":    "abc123",
+				"external_id": externalID,
+			},
+			"repository": map[string]any{
+				"name":  "devfile-sample-go-basic",
+				"owner": map[string]any{"login": "devfile-samples"},
+			},
+		}
+	}
+
+	getRerunLabel := func(name string) string {
+		snapshot := &applicationapiv1alpha1.Snapshot{}
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, snapshot)).To(Succeed())
+		return snapshot.GetLabels()[gitops.SnapshotIntegrationTestRun]
+	}
+
+	BeforeEach(func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      githubwebhook.WebhookSecretName,
+				Namespace: githubwebhook.WebhookSecretNamespace,
+			},
+			Data: map[string][]byte{githubwebhook.WebhookSecretKey: []byte(webhookSecret)},
+		}
+		latest := newSnapshot("snapshot-latest", "component-sample", time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC))
+		older := newSnapshot("snapshot-older", "component-sample", time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC))
+		other := newSnapshot("snapshot-other", "another-component-sample", time.Date(2024, 5, 14, 11, 0, 0, 0, time.UTC))
+
+		// the secret is only readable through the secret reader, as it isn't in the cache of the client
+		secretReader = fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(secret).Build()
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(newScheme()).
+			WithObjects(latest, older, other).
+			// the fake client doesn't support server-side apply, the applied labels are merged instead
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if patch.Type() != types.ApplyPatchType {
+						return c.Patch(ctx, obj, patch, opts...)
+					}
+					data, err := patch.Data(obj)
+					if err != nil {
+						return err
+					}
+					return c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+				},
+			}).
+			Build()
+		server = httptest.NewServer(githubwebhook.NewServer(k8sClient, secretReader, logr.Discard()).Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("re-runs the integration test of the re-requested check run in the latest snapshot of its component", func() {
+		resp, body := deliver("check_run", checkRunEvent("rerequested", "scenario-a-component-sample"), webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(body).To(HaveKeyWithValue("message", "re-running scenario scenario-a for snapshot default/snapshot-latest"))
+
+		Expect(getRerunLabel("snapshot-latest")).To(Equal("scenario-a"))
+		Expect(getRerunLabel("snapshot-older")).To(BeEmpty())
+		Expect(getRerunLabel("snapshot-other")).To(BeEmpty())
+	})
+
+	It("re-runs the integration test of the re-requested check run which isn't qualified by a component", func() {
+		resp, _ := deliver("check_run", checkRunEvent("rerequested", "scenario-a"), webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(getRerunLabel("snapshot-other")).To(Equal("scenario-a"))
+	})
+
+	It("returns not found when no snapshot has the integration test of the check run", func() {
+		resp, body := deliver("check_run", checkRunEvent("rerequested", "scenario-b-component-sample"), webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(body).To(HaveKey("error"))
+		Expect(getRerunLabel("snapshot-latest")).To(BeEmpty())
+	})
+
+	It("ignores the other events and actions", func() {
+		resp, body := deliver("check_run", checkRunEvent("completed", "scenario-a-component-sample"), webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(HaveKeyWithValue("message", "event ignored"))
+
+		resp, _ = deliver("check_suite", map[string]any{"action": "rerequested"}, webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(getRerunLabel("snapshot-latest")).To(BeEmpty())
+	})
+
+	It("rejects the webhooks with an invalid signature", func() {
+		resp, body := deliver("check_run", checkRunEvent("rerequested", "scenario-a-component-sample"), "other-secret")
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(body).To(HaveKeyWithValue("error", "invalid webhook signature"))
+		Expect(getRerunLabel("snapshot-latest")).To(BeEmpty())
+	})
+
+	It("fails to receive the webhooks when the secret of the webhooks can't be read", func() {
+		server.Close()
+		server = httptest.NewServer(githubwebhook.NewServer(k8sClient, k8sClient, logr.Discard()).Handler())
+
+		resp, body := deliver("check_run", checkRunEvent("rerequested", "scenario-a-component-sample"), webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(body).To(HaveKeyWithValue("error", "failed to get the secret of the webhooks"))
+		Expect(getRerunLabel("snapshot-latest")).To(BeEmpty())
+	})
+
+	It("rejects the webhooks with a too large payload", func() {
+		event := checkRunEvent("rerequested", "scenario-a-component-sample")
+		event["padding"] = strings.Repeat("a", 2*1024*1024)
+		resp, body := deliver("check_run", event, webhookSecret)
+		Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(body).To(HaveKey("error"))
+		Expect(getRerunLabel("snapshot-latest")).To(BeEmpty())
+	})
+
+	It("rejects the requests which aren't POST", func() {
+		resp, err := http.Get(server.URL + githubwebhook.WebhookPath)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// readHeaderTimeout is the time allowed to read the headers of the requests.
+	readHeaderTimeout = 10 * time.Second
+
+	// shutdownTimeout is the time allowed to the requests in flight to finish once the server is stopped.
+	shutdownTimeout = 10 * time.Second
+)
+
+// Response is the JSON body of the responses of the servers, which holds either a message or an error.
+type Response struct {
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Server is a runnable of the manager serving the given handler on every replica until the manager is stopped.
+type Server struct {
+	name     string
+	address  string
+	certFile string
+	keyFile  string
+	handler  http.Handler
+	logger   logr.Logger
+}
+
+// NewServer creates and returns a Server named after what it serves, e.g. "status API", listening on the given
+// address. TLS is served when the certificate and key files are set.
+func NewServer(name, address, certFile, keyFile string, handler http.Handler, logger logr.Logger) *Server {
+	return &Server{
+		name:     name,
+		address:  address,
+		certFile: certFile,
+		keyFile:  keyFile,
+		handler:  handler,
+		logger:   logger,
+	}
+}
+
+// NeedLeaderElection returns false since every replica of the manager can serve the requests.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the requests until the context is cancelled, and then waits for the requests in flight to finish.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.address, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.logger.Error(err, fmt.Sprintf("failed to shut down the %s server", s.name))
+		}
+	}()
+
+	s.logger.Info(fmt.Sprintf("Starting the %s server", s.name), "address", listener.Addr().String())
+	if s.certFile != "" && s.keyFile != "" {
+		err = server.ServeTLS(listener, s.certFile, s.keyFile)
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// WriteError writes the error response with the given status code.
+func WriteError(w http.ResponseWriter, code int, message string, logger logr.Logger) {
+	WriteJSON(w, code, &Response{Error: message}, logger)
+}
+
+// WriteJSON writes the JSON encoded body with the given status code. The failures to write the body are logged
+// since the status code was already sent.
+func WriteJSON(w http.ResponseWriter, code int, body any, logger logr.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error(err, "Failed to write the response")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
+)
+
+var _ = Describe("Server", func() {
+	// freeAddress returns a local address nothing is listening on.
+	freeAddress := func() string {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+		return listener.Addr().String()
+	}
+
+	It("doesn't need the leader election", func() {
+		server := httpserver.NewServer("test", "127.0.0.1:0", "", "", http.NotFoundHandler(), logr.Discard())
+		Expect(server.NeedLeaderElection()).To(BeFalse())
+	})
+
+	It("serves the handler until the context is cancelled", func() {
+		address := freeAddress()
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httpserver.WriteJSON(w, http.StatusOK, &httpserver.Response{Message: "served"}, logr.Discard())
+		})
+		server := httpserver.NewServer("test", address, "", "", handler, logr.Discard())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stopped := make(chan error)
+		go func() {
+			stopped <- server.Start(ctx)
+		}()
+
+		var resp *http.Response
+		Eventually(func() error {
+			var err error
+			resp, err = http.Get("http://" + address)
+			return err
+		}).Should(Succeed())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(string(body)).To(MatchJSON(`{"message": "served"}`))
+
+		cancel()
+		Eventually(stopped).Should(Receive(BeNil()))
+	})
+
+	It("fails to start when the address is already in use", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		server := httpserver.NewServer("test", listener.Addr().String(), "", "", http.NotFoundHandler(), logr.Discard())
+		Expect(server.Start(context.Background())).To(MatchError(ContainSubstring("failed to listen on")))
+	})
+
+	It("writes the errors as JSON responses", func() {
+		recorder := httptest.NewRecorder()
+		httpserver.WriteError(recorder, http.StatusNotFound, "snapshot not found", logr.Discard())
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		body := map[string]any{}
+		Expect(json.NewDecoder(recorder.Body).Decode(&body)).To(Succeed())
+		Expect(body).To(Equal(map[string]any{"error": "snapshot not found"}))
+	})
+})
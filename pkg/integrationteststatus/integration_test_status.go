@@ -19,6 +19,7 @@ package integrationteststatus
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -45,6 +46,12 @@ const (
 	IntegrationTestStatusTestPassed // TestPassed
 	// Integration PLR is invalid
 	IntegrationTestStatusTestInvalid // TestInvalid
+	// Integration PLR passed with warnings for this ITS and snapshot
+	IntegrationTestStatusTestWarning // TestWarning
+	// Integration PLR experienced an error of the test infrastructure for this ITS and snapshot
+	IntegrationTestStatusTestError // TestError
+	// The ITS was suspended before its integration PLR finished for this snapshot
+	IntegrationTestStatusSuspended // Suspended
 )
 
 const integrationTestStatusesSchema = `{
@@ -73,6 +80,54 @@ const integrationTestStatusesSchema = `{
         },
         "testPipelineRunName": {
           "type": "string"
+        },
+        "observeOnly": {
+          "type": "boolean"
+        },
+        "testResultsSummary": {
+          "type": "object",
+          "properties": {
+            "successes": {
+              "type": "integer",
+              "minimum": 0
+            },
+            "failures": {
+              "type": "integer",
+              "minimum": 0
+            },
+            "warnings": {
+              "type": "integer",
+              "minimum": 0
+            },
+            "failedTestCases": {
+              "type": "array",
+              "items": {
+                "type": "object",
+                "properties": {
+                  "name": {
+                    "type": "string"
+                  },
+                  "className": {
+                    "type": "string"
+                  },
+                  "file": {
+                    "type": "string"
+                  },
+                  "line": {
+                    "type": "integer",
+                    "minimum": 0
+                  },
+                  "status": {
+                    "type": "string"
+                  },
+                  "message": {
+                    "type": "string"
+                  }
+                },
+                "required": ["name", "status"]
+              }
+            }
+          }
         }
       },
 	  "required": ["scenario", "status", "lastUpdateTime"]
@@ -95,6 +150,39 @@ type IntegrationTestStatusDetail struct {
 	CompletionTime *time.Time `json:"completionTime,omitempty"` // pointer to make omitempty work
 	// TestPipelineName name of testing pipelineRun
 	TestPipelineRunName string `json:"testPipelineRunName,omitempty"`
+	// ObserveOnly is set when the testing pipelineRun was started in the observe-only runs of the scenario,
+	// its result doesn't gate the snapshot
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+	// TestResultsSummary is the summary of the test output of all tasks of the testing pipelineRun
+	TestResultsSummary *TestResultsSummary `json:"testResultsSummary,omitempty"`
+}
+
+// TestResultsSummary contains the test case counts aggregated across all tasks of the testing pipelineRun
+type TestResultsSummary struct {
+	// Successes is the number of passed test cases
+	Successes int `json:"successes"`
+	// Failures is the number of failed test cases
+	Failures int `json:"failures"`
+	// Warnings is the number of test cases which passed with a warning
+	Warnings int `json:"warnings"`
+	// FailedTestCases contains the details of the failed test cases reported in structured test results like JUnit XML
+	FailedTestCases []TestCaseDetail `json:"failedTestCases,omitempty"`
+}
+
+// TestCaseDetail contains the details of a single test case reported in structured test results
+type TestCaseDetail struct {
+	// Name of the test case
+	Name string `json:"name"`
+	// ClassName is the class or suite the test case belongs to
+	ClassName string `json:"className,omitempty"`
+	// File is the path of the source file of the test case, relative to the repository root
+	File string `json:"file,omitempty"`
+	// Line is the line of the test case in the source file
+	Line int `json:"line,omitempty"`
+	// Status of the test case, either failure or error
+	Status string `json:"status"`
+	// Message describing why the test case didn't pass
+	Message string `json:"message,omitempty"`
 }
 
 // SnapshotIntegrationTestStatuses type handles details about snapshot tests
@@ -114,7 +202,21 @@ func (sits *IntegrationTestStatus) IsFinal() bool {
 		IntegrationTestStatusEnvironmentProvisionError_Deprecated,
 		IntegrationTestStatusTestFail,
 		IntegrationTestStatusTestPassed,
-		IntegrationTestStatusTestInvalid:
+		IntegrationTestStatusTestInvalid,
+		IntegrationTestStatusTestWarning,
+		IntegrationTestStatusTestError,
+		IntegrationTestStatusSuspended:
+		return true
+	}
+	return false
+}
+
+// IsPassed returns true when the status allows the Snapshot to pass the gate, i.e. the test passed,
+// possibly with warnings
+func (sits *IntegrationTestStatus) IsPassed() bool {
+	switch *sits {
+	case IntegrationTestStatusTestPassed,
+		IntegrationTestStatusTestWarning:
 		return true
 	}
 	return false
@@ -135,6 +237,8 @@ func (sits *SnapshotIntegrationTestStatuses) ResetStatus(scenarioName string) {
 	sits.UpdateTestStatusIfChanged(scenarioName, IntegrationTestStatusPending, "Pending")
 	detail := sits.statuses[scenarioName]
 	detail.TestPipelineRunName = ""
+	detail.TestResultsSummary = nil
+	detail.ObserveOnly = false
 	sits.dirty = true
 }
 
@@ -177,7 +281,10 @@ func (sits *SnapshotIntegrationTestStatuses) UpdateTestStatusIfChanged(scenarioN
 			IntegrationTestStatusDeleted,
 			IntegrationTestStatusTestFail,
 			IntegrationTestStatusTestPassed,
-			IntegrationTestStatusTestInvalid:
+			IntegrationTestStatusTestInvalid,
+			IntegrationTestStatusTestWarning,
+			IntegrationTestStatusTestError,
+			IntegrationTestStatusSuspended:
 			detail.CompletionTime = &timestamp
 		}
 	}
@@ -206,6 +313,38 @@ func (sits *SnapshotIntegrationTestStatuses) UpdateTestPipelineRunName(scenarioN
 	return nil
 }
 
+// UpdateTestObserveOnly updates ObserveOnly if changed
+// scenario must already exist in statuses
+func (sits *SnapshotIntegrationTestStatuses) UpdateTestObserveOnly(scenarioName string, observeOnly bool) error {
+	detail, ok := sits.GetScenarioStatus(scenarioName)
+	if !ok {
+		return fmt.Errorf("scenario name %s not found within the SnapshotIntegrationTestStatus, and cannot be updated", scenarioName)
+	}
+
+	if detail.ObserveOnly != observeOnly {
+		detail.ObserveOnly = observeOnly
+		sits.dirty = true
+	}
+
+	return nil
+}
+
+// UpdateTestResultsSummary updates TestResultsSummary if changed
+// scenario must already exist in statuses
+func (sits *SnapshotIntegrationTestStatuses) UpdateTestResultsSummary(scenarioName string, summary *TestResultsSummary) error {
+	detail, ok := sits.GetScenarioStatus(scenarioName)
+	if !ok {
+		return fmt.Errorf("scenario name %s not found within the SnapshotIntegrationTestStatus, and cannot be updated", scenarioName)
+	}
+
+	if !reflect.DeepEqual(detail.TestResultsSummary, summary) {
+		detail.TestResultsSummary = summary
+		sits.dirty = true
+	}
+
+	return nil
+}
+
 // InitStatuses creates initial representation all scenarios
 // This function also removes scenarios which are not defined in scenarios param
 func (sits *SnapshotIntegrationTestStatuses) InitStatuses(scenarioNames *[]string) {
@@ -271,7 +410,8 @@ func (sits *SnapshotIntegrationTestStatuses) GetScenarioStatus(scenarioName stri
 //	    "details": "Failed ...",
 //	    "startTime": "2023-07-26T14:57:49+02:00",
 //	    "completionTime": "2023-07-26T16:57:49+02:00",
-//	    "testPipelineRunName": "pipeline-run-feedbeef"
+//	    "testPipelineRunName": "pipeline-run-feedbeef",
+//	    "testResultsSummary": {"successes": 10, "failures": 1, "warnings": 0}
 //	  }
 //	]
 func (sits *SnapshotIntegrationTestStatuses) MarshalJSON() ([]byte, error) {
@@ -46,6 +46,9 @@ var _ = Describe("integrationteststatus library unittests", func() {
 			Entry("When status is TestPass", intgteststat.IntegrationTestStatusTestPassed, "TestPassed"),
 			Entry("When status is Deleted", intgteststat.IntegrationTestStatusDeleted, "Deleted"),
 			Entry("When status is Invalid", intgteststat.IntegrationTestStatusTestInvalid, "TestInvalid"),
+			Entry("When status is Warning", intgteststat.IntegrationTestStatusTestWarning, "TestWarning"),
+			Entry("When status is Error", intgteststat.IntegrationTestStatusTestError, "TestError"),
+			Entry("When status is Suspended", intgteststat.IntegrationTestStatusSuspended, "Suspended"),
 		)
 
 		DescribeTable("Status to JSON and vice versa",
@@ -67,6 +70,9 @@ var _ = Describe("integrationteststatus library unittests", func() {
 			Entry("When status is TestPass", intgteststat.IntegrationTestStatusTestPassed, "TestPassed"),
 			Entry("When status is Deleted", intgteststat.IntegrationTestStatusDeleted, "Deleted"),
 			Entry("When status is Invalid", intgteststat.IntegrationTestStatusTestInvalid, "TestInvalid"),
+			Entry("When status is Warning", intgteststat.IntegrationTestStatusTestWarning, "TestWarning"),
+			Entry("When status is Error", intgteststat.IntegrationTestStatusTestError, "TestError"),
+			Entry("When status is Suspended", intgteststat.IntegrationTestStatusSuspended, "Suspended"),
 		)
 
 		DescribeTable("Check IsFinal logic",
@@ -80,9 +86,25 @@ var _ = Describe("integrationteststatus library unittests", func() {
 			Entry("When status is TestFail", intgteststat.IntegrationTestStatusTestFail, true),
 			Entry("When status is TestPass", intgteststat.IntegrationTestStatusTestPassed, true),
 			Entry("When status is Invalid", intgteststat.IntegrationTestStatusTestInvalid, true),
+			Entry("When status is Warning", intgteststat.IntegrationTestStatusTestWarning, true),
+			Entry("When status is Error", intgteststat.IntegrationTestStatusTestError, true),
+			Entry("When status is Suspended", intgteststat.IntegrationTestStatusSuspended, true),
 			Entry("When status is Other", intgteststat.IntegrationTestStatusPending, false),
 		)
 
+		DescribeTable("Check IsPassed logic",
+			func(st intgteststat.IntegrationTestStatus, isPassed bool) {
+				Expect(st.IsPassed()).To(Equal(isPassed))
+			},
+			Entry("When status is TestPass", intgteststat.IntegrationTestStatusTestPassed, true),
+			Entry("When status is Warning", intgteststat.IntegrationTestStatusTestWarning, true),
+			Entry("When status is TestFail", intgteststat.IntegrationTestStatusTestFail, false),
+			Entry("When status is Error", intgteststat.IntegrationTestStatusTestError, false),
+			Entry("When status is Invalid", intgteststat.IntegrationTestStatusTestInvalid, false),
+			Entry("When status is Suspended", intgteststat.IntegrationTestStatusSuspended, false),
+			Entry("When status is Other", intgteststat.IntegrationTestStatusInProgress, false),
+		)
+
 		It("Invalid status to type fails with error", func() {
 			_, err := intgteststat.IntegrationTestStatusString("Unknown")
 			Expect(err).NotTo(BeNil())
@@ -191,6 +213,9 @@ var _ = Describe("integrationteststatus library unittests", func() {
 			Entry("When status is TestPass", intgteststat.IntegrationTestStatusTestPassed, true),
 			Entry("When status is Deleted", intgteststat.IntegrationTestStatusDeleted, true),
 			Entry("When status is Invalid", intgteststat.IntegrationTestStatusTestInvalid, true),
+			Entry("When status is Warning", intgteststat.IntegrationTestStatusTestWarning, true),
+			Entry("When status is Error", intgteststat.IntegrationTestStatusTestError, true),
+			Entry("When status is Suspended", intgteststat.IntegrationTestStatusSuspended, true),
 		)
 
 		It("Change back to InProgress updates timestamps accordingly", func() {
@@ -265,6 +290,56 @@ var _ = Describe("integrationteststatus library unittests", func() {
 			Expect(err).NotTo(BeNil())
 		})
 
+		It("can mark the test as observe-only", func() {
+			Expect(sits.UpdateTestObserveOnly(testScenarioName, true)).NotTo(Succeed())
+
+			sits.UpdateTestStatusIfChanged(testScenarioName, intgteststat.IntegrationTestStatusInProgress, testDetails)
+			sits.ResetDirty()
+
+			Expect(sits.UpdateTestObserveOnly(testScenarioName, true)).To(Succeed())
+			Expect(sits.IsDirty()).To(BeTrue())
+			sits.ResetDirty()
+			Expect(sits.UpdateTestObserveOnly(testScenarioName, true)).To(Succeed())
+			Expect(sits.IsDirty()).To(BeFalse())
+
+			detail, ok := sits.GetScenarioStatus(testScenarioName)
+			Expect(ok).To(BeTrue())
+			Expect(detail.ObserveOnly).To(BeTrue())
+
+			// the flag is cleared when the test is re-run
+			sits.ResetStatus(testScenarioName)
+			detail, _ = sits.GetScenarioStatus(testScenarioName)
+			Expect(detail.ObserveOnly).To(BeFalse())
+		})
+
+		It("can update details with test results summary", func() {
+			summary := &intgteststat.TestResultsSummary{Successes: 10, Failures: 2, Warnings: 1}
+			Expect(sits.UpdateTestResultsSummary(testScenarioName, summary)).NotTo(Succeed())
+
+			sits.UpdateTestStatusIfChanged(testScenarioName, intgteststat.IntegrationTestStatusTestFail, testDetails)
+			sits.ResetDirty()
+
+			Expect(sits.UpdateTestResultsSummary(testScenarioName, summary)).To(Succeed())
+			Expect(sits.IsDirty()).To(BeTrue())
+			sits.ResetDirty()
+			Expect(sits.UpdateTestResultsSummary(testScenarioName, &intgteststat.TestResultsSummary{Successes: 10, Failures: 2, Warnings: 1})).To(Succeed())
+			Expect(sits.IsDirty()).To(BeFalse())
+
+			// summary survives the JSON roundtrip
+			data, err := json.Marshal(sits)
+			Expect(err).To(BeNil())
+			newSits, err := intgteststat.NewSnapshotIntegrationTestStatuses(string(data))
+			Expect(err).To(BeNil())
+			detail, ok := newSits.GetScenarioStatus(testScenarioName)
+			Expect(ok).To(BeTrue())
+			Expect(detail.TestResultsSummary).To(Equal(summary))
+
+			sits.ResetStatus(testScenarioName)
+			detail, ok = sits.GetScenarioStatus(testScenarioName)
+			Expect(ok).To(BeTrue())
+			Expect(detail.TestResultsSummary).To(BeNil())
+		})
+
 		It("Can export valid JSON without start and completion time (Pending)", func() {
 			sits.UpdateTestStatusIfChanged(testScenarioName, intgteststat.IntegrationTestStatusPending, testDetails)
 			detail, ok := sits.GetScenarioStatus(testScenarioName)
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// OCIReferencePrefix is the prefix of the JUnit results references pointing to an OCI artifact,
+	// e.g. "oci://quay.io/org/repo@sha256:...". The JUnit XML files are read from the layers of the artifact.
+	OCIReferencePrefix = "oci://"
+
+	// TestCaseStatusPassed is the status of a test case which passed.
+	TestCaseStatusPassed = "passed"
+
+	// TestCaseStatusFailure is the status of a test case which failed.
+	TestCaseStatusFailure = "failure"
+
+	// TestCaseStatusError is the status of a test case which experienced an error.
+	TestCaseStatusError = "error"
+
+	// TestCaseStatusSkipped is the status of a test case which was skipped.
+	TestCaseStatusSkipped = "skipped"
+)
+
+// ErrUnsupportedReference is returned when the JUnit results reference is neither inline JUnit XML nor an OCI artifact.
+// Files written to a workspace of the test pipeline aren't reachable from the controller, tasks need to push them as
+// an OCI artifact and reference it instead.
+var ErrUnsupportedReference = errors.New("unsupported JUnit results reference, only inline JUnit XML and oci:// references are supported")
+
+// TestCase is a single test case of a JUnit XML report.
+type TestCase struct {
+	Name      string  `xml:"name,attr"`
+	ClassName string  `xml:"classname,attr"`
+	File      string  `xml:"file,attr"`
+	Line      string  `xml:"line,attr"`
+	Time      string  `xml:"time,attr"`
+	Failure   *Result `xml:"failure"`
+	Error     *Result `xml:"error"`
+	Skipped   *Result `xml:"skipped"`
+}
+
+// Result holds the details of a failed, errored or skipped test case.
+type Result struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// testSuite matches both the <testsuites> and <testsuite> elements, which can be nested.
+type testSuite struct {
+	XMLName   xml.Name
+	Suites    []testSuite `xml:"testsuite"`
+	TestCases []TestCase  `xml:"testcase"`
+}
+
+// GetStatus returns the status of the test case.
+func (tc *TestCase) GetStatus() string {
+	switch {
+	case tc.Error != nil:
+		return TestCaseStatusError
+	case tc.Failure != nil:
+		return TestCaseStatusFailure
+	case tc.Skipped != nil:
+		return TestCaseStatusSkipped
+	default:
+		return TestCaseStatusPassed
+	}
+}
+
+// GetLine returns the line of the test case in its source file, 0 is returned when it is unknown.
+func (tc *TestCase) GetLine() int {
+	line, err := strconv.Atoi(tc.Line)
+	if err != nil || line < 0 {
+		return 0
+	}
+	return line
+}
+
+// GetMessage returns the message describing why the test case didn't pass, falling back to the text of the failure.
+func (tc *TestCase) GetMessage() string {
+	result := tc.Error
+	if result == nil {
+		result = tc.Failure
+	}
+	if result == nil {
+		return ""
+	}
+	if result.Message != "" {
+		return result.Message
+	}
+	return strings.TrimSpace(result.Text)
+}
+
+// Parse parses a JUnit XML report with either a <testsuites> or a <testsuite> root element
+// and returns all of its test cases.
+func Parse(data []byte) ([]TestCase, error) {
+	data, err := decompress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var root testSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse JUnit XML: %w", err)
+	}
+	if root.XMLName.Local != "testsuites" && root.XMLName.Local != "testsuite" {
+		return nil, fmt.Errorf("failed to parse JUnit XML: unexpected root element <%s>", root.XMLName.Local)
+	}
+
+	return collectTestCases(root), nil
+}
+
+// Load returns the test cases of the JUnit XML report referenced by a Tekton task result. The reference is either
+// the JUnit XML itself or an OCI artifact reference prefixed with OCIReferencePrefix.
+func Load(ctx context.Context, reference string) ([]TestCase, error) {
+	reference = strings.TrimSpace(reference)
+	switch {
+	case strings.HasPrefix(reference, "<"):
+		return Parse([]byte(reference))
+	case strings.HasPrefix(reference, OCIReferencePrefix):
+		blobs, err := fetchArtifactBlobs(ctx, strings.TrimPrefix(reference, OCIReferencePrefix))
+		if err != nil {
+			return nil, err
+		}
+		testCases := []TestCase{}
+		for _, blob := range blobs {
+			blobTestCases, err := Parse(blob)
+			if err != nil {
+				return nil, err
+			}
+			testCases = append(testCases, blobTestCases...)
+		}
+		return testCases, nil
+	default:
+		return nil, ErrUnsupportedReference
+	}
+}
+
+// collectTestCases returns the test cases of the given suite and all of its nested suites.
+func collectTestCases(suite testSuite) []TestCase {
+	testCases := append([]TestCase{}, suite.TestCases...)
+	for _, nested := range suite.Suites {
+		testCases = append(testCases, collectTestCases(nested)...)
+	}
+	return testCases
+}
+
+// decompress returns the gunzipped data if it is gzip compressed, the data is returned as is otherwise.
+func decompress(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress JUnit XML: %w", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxArtifactSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress JUnit XML: %w", err)
+	}
+	if len(decompressed) > maxArtifactSize {
+		return nil, fmt.Errorf("decompressed JUnit XML exceeds the maximum size of %d bytes", maxArtifactSize)
+	}
+	return decompressed, nil
+}
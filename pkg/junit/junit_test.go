@@ -0,0 +1,183 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/junit"
+)
+
+const sampleReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="unit" tests="3">
+    <testcase name="TestPass" classname="pkg.unit" time="0.01"/>
+    <testcase name="TestFail" classname="pkg.unit" file="pkg/unit_test.go" line="42">
+      <failure message="expected 1, got 2" type="assertion">unit_test.go:42: expected 1, got 2</failure>
+    </testcase>
+    <testcase name="TestSkip" classname="pkg.unit">
+      <skipped/>
+    </testcase>
+  </testsuite>
+  <testsuite name="e2e">
+    <testsuite name="nested">
+      <testcase name="TestError" classname="pkg.e2e">
+        <error>cluster unreachable</error>
+      </testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+var _ = Describe("JUnit XML parser", func() {
+
+	It("parses the test cases of nested test suites", func() {
+		testCases, err := junit.Parse([]byte(sampleReport))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testCases).To(HaveLen(4))
+
+		Expect(testCases[0].GetStatus()).To(Equal(junit.TestCaseStatusPassed))
+		Expect(testCases[1].GetStatus()).To(Equal(junit.TestCaseStatusFailure))
+		Expect(testCases[1].GetMessage()).To(Equal("expected 1, got 2"))
+		Expect(testCases[1].File).To(Equal("pkg/unit_test.go"))
+		Expect(testCases[1].GetLine()).To(Equal(42))
+		Expect(testCases[2].GetStatus()).To(Equal(junit.TestCaseStatusSkipped))
+		Expect(testCases[3].GetStatus()).To(Equal(junit.TestCaseStatusError))
+		Expect(testCases[3].GetMessage()).To(Equal("cluster unreachable"))
+		Expect(testCases[3].GetLine()).To(Equal(0))
+	})
+
+	It("parses a report with a testsuite root element", func() {
+		testCases, err := junit.Parse([]byte(`<testsuite name="single"><testcase name="TestPass"/></testsuite>`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testCases).To(HaveLen(1))
+		Expect(testCases[0].Name).To(Equal("TestPass"))
+	})
+
+	It("parses a gzip compressed report", func() {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write([]byte(sampleReport))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		testCases, err := junit.Parse(buf.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testCases).To(HaveLen(4))
+	})
+
+	It("returns an error for documents which aren't JUnit XML", func() {
+		_, err := junit.Parse([]byte(`<html><body/></html>`))
+		Expect(err).To(MatchError(ContainSubstring("unexpected root element <html>")))
+		_, err = junit.Parse([]byte(`{"result": "SUCCESS"}`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("loads an inline report", func() {
+		testCases, err := junit.Load(context.Background(), "\n"+sampleReport)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testCases).To(HaveLen(4))
+	})
+
+	It("refuses workspace paths", func() {
+		_, err := junit.Load(context.Background(), "/workspace/results/junit.xml")
+		Expect(errors.Is(err, junit.ErrUnsupportedReference)).To(BeTrue())
+	})
+
+	When("the report is stored in an OCI artifact", func() {
+		var (
+			server     *httptest.Server
+			reference  string
+			authorized []string
+		)
+		reportHash := sha256.Sum256([]byte(sampleReport))
+		reportDigest := "sha256:" + hex.EncodeToString(reportHash[:])
+		logsHash := sha256.Sum256([]byte("logs"))
+		logsDigest := "sha256:" + hex.EncodeToString(logsHash[:])
+
+		BeforeEach(func() {
+			authorized = []string{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/token" {
+					Expect(r.URL.Query().Get("scope")).To(Equal("repository:org/results:pull"))
+					Expect(json.NewEncoder(w).Encode(map[string]string{"token": "anonymous-token"})).To(Succeed())
+					return
+				}
+				if r.Header.Get("Authorization") != "Bearer anonymous-token" {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test",scope="repository:org/results:pull"`, r.Host))
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				authorized = append(authorized, r.URL.Path)
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/manifests/v1"):
+					Expect(r.Header.Get("Accept")).To(ContainSubstring("application/vnd.oci.image.manifest.v1+json"))
+					Expect(json.NewEncoder(w).Encode(map[string]any{
+						"layers": []map[string]any{
+							{
+								"mediaType":   "application/vnd.oci.image.layer.v1.tar",
+								"digest":      reportDigest,
+								"size":        len(sampleReport),
+								"annotations": map[string]string{"org.opencontainers.image.title": "junit.xml"},
+							},
+							{
+								"mediaType":   "text/plain",
+								"digest":      logsDigest,
+								"size":        4,
+								"annotations": map[string]string{"org.opencontainers.image.title": "build.log"},
+							},
+						},
+					})).To(Succeed())
+				case strings.HasSuffix(r.URL.Path, "/blobs/"+reportDigest):
+					_, err := w.Write([]byte(sampleReport))
+					Expect(err).ToNot(HaveOccurred())
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			reference = fmt.Sprintf("%s%s/org/results:v1", junit.OCIReferencePrefix, strings.TrimPrefix(server.URL, "http://"))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("fetches the JUnit XML layers with an anonymous token", func() {
+			testCases, err := junit.Load(context.Background(), reference)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testCases).To(HaveLen(4))
+			Expect(authorized).To(Equal([]string{"/v2/org/results/manifests/v1", "/v2/org/results/blobs/" + reportDigest}))
+		})
+
+		It("returns an error when the artifact doesn't exist", func() {
+			_, err := junit.Load(context.Background(), strings.Replace(reference, ":v1", ":v2", 1))
+			Expect(err).To(MatchError(ContainSubstring("unexpected status code 404")))
+		})
+	})
+})
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+const (
+	// maxArtifactSize is the maximum size in bytes of a single JUnit XML file read from an OCI artifact.
+	maxArtifactSize = 10 * 1024 * 1024
+
+	// titleAnnotation is the OCI layer annotation holding the name of the file stored in the layer.
+	titleAnnotation = "org.opencontainers.image.title"
+
+	// registryTimeout is the maximum duration of the registry requests made to pull an artifact.
+	registryTimeout = 30 * time.Second
+)
+
+// isJUnitXML returns true when the layer holds a JUnit XML file, judged by its file name or media type.
+func isJUnitXML(layer v1.Descriptor) bool {
+	title := layer.Annotations[titleAnnotation]
+	return strings.HasSuffix(title, ".xml") || strings.HasSuffix(title, ".xml.gz") || strings.Contains(string(layer.MediaType), "xml")
+}
+
+// fetchArtifactBlobs anonymously pulls the OCI artifact and returns the contents of its JUnit XML layers.
+func fetchArtifactBlobs(ctx context.Context, reference string) ([][]byte, error) {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the JUnit results OCI reference %s: %w", reference, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+
+	descriptor, err := remote.Get(ref, remote.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the manifest of %s: %w", reference, err)
+	}
+	m, err := v1.ParseManifest(bytes.NewReader(descriptor.Manifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest of %s: %w", reference, err)
+	}
+
+	blobs := [][]byte{}
+	for _, layer := range m.Layers {
+		if !isJUnitXML(layer) {
+			continue
+		}
+		if layer.Size > maxArtifactSize {
+			return nil, fmt.Errorf("layer %s of %s exceeds the maximum size of %d bytes", layer.Digest, reference, maxArtifactSize)
+		}
+		blob, err := fetchBlob(ctx, ref.Context().Digest(layer.Digest.String()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch layer %s of %s: %w", layer.Digest, reference, err)
+		}
+		blobs = append(blobs, blob)
+	}
+	if len(blobs) == 0 {
+		return nil, fmt.Errorf("no JUnit XML layers found in %s", reference)
+	}
+
+	return blobs, nil
+}
+
+// fetchBlob anonymously pulls the blob and returns its content once its digest is verified.
+func fetchBlob(ctx context.Context, ref name.Digest) ([]byte, error) {
+	layer, err := remote.Layer(ref, remote.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	content, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	return io.ReadAll(io.LimitReader(content, maxArtifactSize))
+}
@@ -58,6 +58,21 @@ func (g *GithubAppAvailabilityProbe) CheckAvailability(ctx context.Context) bool
 	return err == nil
 }
 
+// CheckCredentials checks that the credentials of the GitHub App can be read from the Pipelines as Code secret and
+// that its private key is valid, without communicating with GitHub.
+func (g *GithubAppAvailabilityProbe) CheckCredentials(ctx context.Context) error {
+	githubAppId, privateKey, err := g.getGithubAppCredentials(ctx, g.client)
+	if err != nil {
+		return err
+	}
+	_, err = ghinstallation.NewAppsTransport(http.DefaultTransport, githubAppId, privateKey)
+	if err != nil {
+		return fmt.Errorf("invalid private key of the GitHub App: %w", err)
+	}
+
+	return nil
+}
+
 func getGithubAppCredentials(ctx context.Context, client client.Client) (int64, []byte, error) {
 	pacSecret := corev1.Secret{}
 	globalPaCSecretKey := types.NamespacedName{Namespace: IntegrationServiceNamespaceName, Name: PipelinesAsCodeGitHubAppSecretName}
@@ -2,6 +2,10 @@ package metrics
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"github.com/google/go-github/v45/github"
 	"github.com/prometheus/client_golang/prometheus"
@@ -88,3 +92,52 @@ func TestGithubAppAvailability(t *testing.T) {
 		})
 	}
 }
+
+func TestGithubAppCredentials(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Fail to generate the private key: %v", err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	tests := []struct {
+		name                    string
+		getGithubAppCredentials func(ctx context.Context, client client.Client) (int64, []byte, error)
+		expectedError           string
+	}{
+		{name: "should succeed if the credentials are valid",
+			getGithubAppCredentials: func(ctx context.Context, client client.Client) (int64, []byte, error) {
+				return 1, privateKey, nil
+			},
+		},
+		{name: "should fail if the credentials can't be read",
+			getGithubAppCredentials: func(ctx context.Context, client client.Client) (int64, []byte, error) {
+				return 0, nil, errors.New("some error")
+			},
+			expectedError: "some error",
+		},
+		{name: "should fail if the private key is invalid",
+			getGithubAppCredentials: func(ctx context.Context, client client.Client) (int64, []byte, error) {
+				return 1, []byte("not a key"), nil
+			},
+			expectedError: "invalid private key of the GitHub App",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := &GithubAppAvailabilityProbe{
+				client:                  fake.NewClientBuilder().Build(),
+				getGithubAppCredentials: tt.getGithubAppCredentials,
+			}
+
+			err := probe.CheckCredentials(context.Background())
+			if tt.expectedError == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedError)) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
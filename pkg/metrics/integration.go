@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -90,6 +91,123 @@ var (
 			Buckets: []float64{0.05, 0.1, 0.5, 1, 2, 3, 4, 5, 10, 15, 30},
 		},
 	)
+
+	BuildToReleaseLeadTimeSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "integration_svc_build_to_release_lead_time_seconds",
+			Help:    "Lead time from the moment the build pipelineRun is completed till the release is created for an auto-released snapshot",
+			Buckets: []float64{30, 60, 150, 300, 450, 600, 900, 1200, 1800, 2700, 3600, 7200},
+		},
+	)
+
+	SnapshotCreatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integration_svc_snapshot_created_total",
+			Help: "Total number of snapshots created by the operator",
+		},
+		[]string{"namespace", "application"},
+	)
+
+	SnapshotPassedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integration_svc_snapshot_passed_total",
+			Help: "Total number of snapshots which passed all required integration tests",
+		},
+		[]string{"namespace", "application"},
+	)
+
+	SnapshotFailedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integration_svc_snapshot_failed_total",
+			Help: "Total number of snapshots which failed at least one required integration test",
+		},
+		[]string{"namespace", "application"},
+	)
+
+	// IntegrationTestScenarioResultTotal can be used to compute the pass ratio of each scenario
+	// by dividing the number of passed results by the total number of results
+	IntegrationTestScenarioResultTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integration_svc_integration_test_scenario_result_total",
+			Help: "Total number of finished integration tests per scenario and result",
+		},
+		[]string{"namespace", "application", "scenario", "result"},
+	)
+
+	IntegrationTestScenarioPassRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "integration_svc_integration_test_scenario_pass_rate",
+			Help: "Ratio of the latest finished integration tests per scenario which passed",
+		},
+		[]string{"namespace", "application", "scenario"},
+	)
+
+	IntegrationTestScenarioAverageDurationSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "integration_svc_integration_test_scenario_average_duration_seconds",
+			Help: "Average duration of the latest finished integration tests per scenario",
+		},
+		[]string{"namespace", "application", "scenario"},
+	)
+
+	BuildPipelineRunCompletedToSnapshotCreatedSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "integration_svc_build_pipelinerun_completed_to_snapshot_created_seconds",
+			Help:    "Time duration from the moment the build pipelineRun was completed till the snapshot is created",
+			Buckets: []float64{0.5, 1, 2, 3, 4, 5, 6, 7, 10, 15, 30, 60, 120, 240},
+		},
+		[]string{"namespace", "application"},
+	)
+
+	SnapshotCreatedToGatingDecisionSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "integration_svc_snapshot_created_to_gating_decision_seconds",
+			Help:    "Time duration from the moment the snapshot was created till it is marked as passed or failed",
+			Buckets: []float64{7, 15, 30, 60, 150, 300, 450, 600, 750, 900, 1050, 1800, 3600},
+		},
+		[]string{"namespace", "application", "result"},
+	)
+
+	IntegrationPipelineRunDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "integration_svc_integration_pipelinerun_duration_seconds",
+			Help:    "Time duration from the moment the integration pipelineRun was started till it is completed",
+			Buckets: []float64{7, 15, 30, 60, 150, 300, 450, 600, 750, 900, 1050, 1800, 3600},
+		},
+		[]string{"namespace", "application"},
+	)
+
+	PipelineRunCreationQueueLength = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "integration_svc_pipelinerun_creation_queue_length",
+			Help: "Number of pipelineRun creations waiting for the rate limiter",
+		},
+	)
+
+	PipelineRunCreationWaitSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "integration_svc_pipelinerun_creation_wait_seconds",
+			Help:    "Time duration the pipelineRun creations waited for the rate limiter",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+	)
+
+	AdapterOperationDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "integration_svc_adapter_operation_duration_seconds",
+			Help:    "Time duration of the adapter operations run by the controllers per operation and result",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"controller", "operation", "result"},
+	)
+
+	AdapterOperationErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integration_svc_adapter_operation_errors_total",
+			Help: "Total number of the errors returned by the adapter operations run by the controllers per operation and reason",
+		},
+		[]string{"controller", "operation", "reason"},
+	)
 )
 
 // IntegrationMetrics represents a collection of metrics to be registered on a
@@ -143,6 +261,82 @@ func RegisterReleaseLatency(startTime metav1.Time) {
 	ReleaseLatencySeconds.Observe(latency)
 }
 
+// RegisterBuildToReleaseLeadTime observes the time elapsed from the completion of the build pipelineRun
+// till the creation of the release.
+func RegisterBuildToReleaseLeadTime(buildCompletionTime metav1.Time) {
+	BuildToReleaseLeadTimeSeconds.Observe(time.Since(buildCompletionTime.Time).Seconds())
+}
+
+// RegisterSnapshotCreated increments the number of created snapshots for the given application.
+func RegisterSnapshotCreated(namespace, application string) {
+	SnapshotCreatedTotal.WithLabelValues(namespace, application).Inc()
+}
+
+// RegisterBuildPipelineRunCompletedToSnapshotCreated observes the time elapsed between the completion of
+// a build pipelineRun and the creation of the snapshot for it.
+func RegisterBuildPipelineRunCompletedToSnapshotCreated(namespace, application string, buildCompletionTime metav1.Time, snapshotCreationTime metav1.Time) {
+	BuildPipelineRunCompletedToSnapshotCreatedSeconds.WithLabelValues(namespace, application).Observe(snapshotCreationTime.Sub(buildCompletionTime.Time).Seconds())
+}
+
+// RegisterSnapshotGatingDecision increments the passed or failed snapshot counter and observes the time
+// elapsed between the creation of the snapshot and the decision made about its test results.
+func RegisterSnapshotGatingDecision(namespace, application string, passed bool, snapshotCreationTime metav1.Time, decisionTime *metav1.Time) {
+	result := "failed"
+	if passed {
+		result = "passed"
+		SnapshotPassedTotal.WithLabelValues(namespace, application).Inc()
+	} else {
+		SnapshotFailedTotal.WithLabelValues(namespace, application).Inc()
+	}
+	SnapshotCreatedToGatingDecisionSeconds.WithLabelValues(namespace, application, result).Observe(decisionTime.Sub(snapshotCreationTime.Time).Seconds())
+}
+
+// RegisterIntegrationTestScenarioResult increments the result counter of the given integration test scenario.
+func RegisterIntegrationTestScenarioResult(namespace, application, scenario, result string) {
+	IntegrationTestScenarioResultTotal.WithLabelValues(namespace, application, scenario, result).Inc()
+}
+
+// RegisterIntegrationTestScenarioTrend sets the rolling pass rate and average duration of the given integration
+// test scenario.
+func RegisterIntegrationTestScenarioTrend(namespace, application, scenario string, passRate float64, averageDuration time.Duration) {
+	IntegrationTestScenarioPassRate.WithLabelValues(namespace, application, scenario).Set(passRate)
+	IntegrationTestScenarioAverageDurationSeconds.WithLabelValues(namespace, application, scenario).Set(averageDuration.Seconds())
+}
+
+// RegisterIntegrationPipelineRunDuration observes the duration of a finished integration pipelineRun.
+func RegisterIntegrationPipelineRunDuration(namespace, application string, startTime, completionTime *metav1.Time) {
+	IntegrationPipelineRunDurationSeconds.WithLabelValues(namespace, application).Observe(completionTime.Sub(startTime.Time).Seconds())
+}
+
+// RegisterPipelineRunCreationQueued increments the number of pipelineRun creations waiting for the rate limiter.
+func RegisterPipelineRunCreationQueued() {
+	PipelineRunCreationQueueLength.Inc()
+}
+
+// RegisterPipelineRunCreationDequeued decrements the number of pipelineRun creations waiting for the rate limiter
+// and observes the time the creation waited.
+func RegisterPipelineRunCreationDequeued(wait time.Duration) {
+	PipelineRunCreationQueueLength.Dec()
+	PipelineRunCreationWaitSeconds.Observe(wait.Seconds())
+}
+
+// RegisterAdapterOperation observes the duration of an adapter operation run by the named controller and the result
+// it returned.
+func RegisterAdapterOperation(controllerName, operation, result string, duration time.Duration) {
+	AdapterOperationDurationSeconds.WithLabelValues(controllerName, operation, result).Observe(duration.Seconds())
+}
+
+// RegisterAdapterOperationError increments the number of errors returned by an adapter operation run by the named
+// controller. The reason is the reason of the API status of the error, e.g. Conflict, or Unknown for the errors which
+// aren't returned by the API server.
+func RegisterAdapterOperationError(controllerName, operation string, err error) {
+	reason := errors.ReasonForError(err)
+	if reason == metav1.StatusReasonUnknown {
+		reason = "Unknown"
+	}
+	AdapterOperationErrorsTotal.WithLabelValues(controllerName, operation, string(reason)).Inc()
+}
+
 func (m *IntegrationMetrics) InitMetrics(registerer prometheus.Registerer) error {
 	registerer.MustRegister(
 		SnapshotCreatedToPipelineRunStartedStaticEnvSeconds,
@@ -153,6 +347,20 @@ func (m *IntegrationMetrics) InitMetrics(registerer prometheus.Registerer) error
 		SnapshotDurationSeconds,
 		SnapshotTotal,
 		ReleaseLatencySeconds,
+		BuildToReleaseLeadTimeSeconds,
+		SnapshotCreatedTotal,
+		SnapshotPassedTotal,
+		SnapshotFailedTotal,
+		IntegrationTestScenarioResultTotal,
+		IntegrationTestScenarioPassRate,
+		IntegrationTestScenarioAverageDurationSeconds,
+		BuildPipelineRunCompletedToSnapshotCreatedSeconds,
+		SnapshotCreatedToGatingDecisionSeconds,
+		IntegrationPipelineRunDurationSeconds,
+		PipelineRunCreationQueueLength,
+		PipelineRunCreationWaitSeconds,
+		AdapterOperationDurationSeconds,
+		AdapterOperationErrorsTotal,
 	)
 	for _, probe := range m.probes {
 		if err := registerer.Register(probe.AvailabilityGauge()); err != nil {
@@ -25,7 +25,9 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
@@ -264,4 +266,158 @@ var _ = Describe("Metrics Integration", Ordered, func() {
 			Expect(testutil.CollectAndCount(ReleaseLatencySeconds)).To(Equal(1))
 		})
 	})
+	Context("When RegisterBuildToReleaseLeadTime is called", func() {
+		BeforeAll(func() {
+			BuildToReleaseLeadTimeSeconds = prometheus.NewHistogram(
+				prometheus.HistogramOpts{
+					Name:    "integration_svc_build_to_release_lead_time_seconds",
+					Help:    "Lead time from the moment the build pipelineRun is completed till the release is created for an auto-released snapshot",
+					Buckets: []float64{60, 600, 1800, 3600},
+				},
+			)
+		})
+
+		It("adds an observation to BuildToReleaseLeadTimeSeconds", func() {
+			buildCompletionTime := metav1.Time{Time: time.Now().Add(-300 * time.Second)}
+			RegisterBuildToReleaseLeadTime(buildCompletionTime)
+			Expect(testutil.CollectAndCount(BuildToReleaseLeadTimeSeconds)).To(Equal(1))
+		})
+	})
+
+	Context("When RegisterSnapshotGatingDecision is called", func() {
+		BeforeAll(func() {
+			SnapshotPassedTotal = prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "integration_svc_snapshot_passed_total",
+					Help: "Total number of snapshots which passed all required integration tests",
+				},
+				[]string{"namespace", "application"},
+			)
+			SnapshotFailedTotal = prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "integration_svc_snapshot_failed_total",
+					Help: "Total number of snapshots which failed at least one required integration test",
+				},
+				[]string{"namespace", "application"},
+			)
+			SnapshotCreatedToGatingDecisionSeconds = prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name:    "integration_svc_snapshot_created_to_gating_decision_seconds",
+					Help:    "Time duration from the moment the snapshot was created till it is marked as passed or failed",
+					Buckets: []float64{60, 600, 1800, 3600},
+				},
+				[]string{"namespace", "application", "result"},
+			)
+		})
+
+		SnapshotCreatedToGatingDecisionSecondsHeader := inputHeader{
+			Name: "integration_svc_snapshot_created_to_gating_decision_seconds",
+			Help: "Time duration from the moment the snapshot was created till it is marked as passed or failed",
+		}
+		inputSeconds := []float64{30, 500, 1500, 3000}
+		elapsedSeconds := 0.0
+
+		It("increments the passed and failed snapshot counters per application", func() {
+			creationTime := metav1.Time{Time: time.Now()}
+			for _, seconds := range inputSeconds {
+				decisionTime := metav1.NewTime(creationTime.Add(time.Second * time.Duration(seconds)))
+				elapsedSeconds += seconds
+				RegisterSnapshotGatingDecision("default", "application-sample", true, creationTime, &decisionTime)
+			}
+			RegisterSnapshotGatingDecision("default", "application-sample", false, creationTime, &creationTime)
+
+			Expect(testutil.ToFloat64(SnapshotPassedTotal.WithLabelValues("default", "application-sample"))).To(Equal(float64(len(inputSeconds))))
+			Expect(testutil.ToFloat64(SnapshotFailedTotal.WithLabelValues("default", "application-sample"))).To(Equal(1.0))
+		})
+
+		It("registers a new observation for 'SnapshotCreatedToGatingDecisionSeconds' with the elapsed time from the moment the Snapshot is created.", func() {
+			timeBuckets := []string{"60", "600", "1800", "3600"}
+			data := []int{1, 2, 3, 4}
+			labels := fmt.Sprintf(`application="%s", namespace="%s", result="%s",`, "application-sample", "default", "passed")
+			readerData := createHistogramReader(SnapshotCreatedToGatingDecisionSecondsHeader, timeBuckets, data, labels, elapsedSeconds, len(inputSeconds))
+			Expect(testutil.CollectAndCompare(SnapshotCreatedToGatingDecisionSeconds.WithLabelValues("default", "application-sample", "passed").(prometheus.Histogram),
+				strings.NewReader(readerData))).To(Succeed())
+		})
+	})
+
+	Context("When snapshot creation and integration test metrics are registered", func() {
+		BeforeAll(func() {
+			SnapshotCreatedTotal = prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "integration_svc_snapshot_created_total",
+					Help: "Total number of snapshots created by the operator",
+				},
+				[]string{"namespace", "application"},
+			)
+			IntegrationTestScenarioResultTotal = prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "integration_svc_integration_test_scenario_result_total",
+					Help: "Total number of finished integration tests per scenario and result",
+				},
+				[]string{"namespace", "application", "scenario", "result"},
+			)
+			BuildPipelineRunCompletedToSnapshotCreatedSeconds = prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name:    "integration_svc_build_pipelinerun_completed_to_snapshot_created_seconds",
+					Help:    "Time duration from the moment the build pipelineRun was completed till the snapshot is created",
+					Buckets: []float64{1, 5, 10, 30},
+				},
+				[]string{"namespace", "application"},
+			)
+			IntegrationPipelineRunDurationSeconds = prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name:    "integration_svc_integration_pipelinerun_duration_seconds",
+					Help:    "Time duration from the moment the integration pipelineRun was started till it is completed",
+					Buckets: []float64{60, 600, 1800, 3600},
+				},
+				[]string{"namespace", "application"},
+			)
+		})
+
+		It("increments 'SnapshotCreatedTotal' per application", func() {
+			RegisterSnapshotCreated("default", "application-sample")
+			RegisterSnapshotCreated("default", "application-sample")
+			RegisterSnapshotCreated("default", "other-application")
+			Expect(testutil.ToFloat64(SnapshotCreatedTotal.WithLabelValues("default", "application-sample"))).To(Equal(2.0))
+			Expect(testutil.ToFloat64(SnapshotCreatedTotal.WithLabelValues("default", "other-application"))).To(Equal(1.0))
+		})
+
+		It("increments 'IntegrationTestScenarioResultTotal' per scenario and result", func() {
+			RegisterIntegrationTestScenarioResult("default", "application-sample", "scenario-sample", "TestPassed")
+			RegisterIntegrationTestScenarioResult("default", "application-sample", "scenario-sample", "TestPassed")
+			RegisterIntegrationTestScenarioResult("default", "application-sample", "scenario-sample", "TestFail")
+			Expect(testutil.ToFloat64(IntegrationTestScenarioResultTotal.WithLabelValues("default", "application-sample", "scenario-sample", "TestPassed"))).To(Equal(2.0))
+			Expect(testutil.ToFloat64(IntegrationTestScenarioResultTotal.WithLabelValues("default", "application-sample", "scenario-sample", "TestFail"))).To(Equal(1.0))
+		})
+
+		It("sets the trend of the scenario", func() {
+			RegisterIntegrationTestScenarioTrend("default", "application-sample", "scenario-sample", 0.5, 2*time.Minute)
+			RegisterIntegrationTestScenarioTrend("default", "application-sample", "scenario-sample", 0.75, time.Minute)
+			Expect(testutil.ToFloat64(IntegrationTestScenarioPassRate.WithLabelValues("default", "application-sample", "scenario-sample"))).To(Equal(0.75))
+			Expect(testutil.ToFloat64(IntegrationTestScenarioAverageDurationSeconds.WithLabelValues("default", "application-sample", "scenario-sample"))).To(Equal(60.0))
+		})
+
+		It("observes the build pipelineRun completion to snapshot creation time", func() {
+			completionTime := metav1.Time{Time: time.Now()}
+			creationTime := metav1.NewTime(completionTime.Add(3 * time.Second))
+			RegisterBuildPipelineRunCompletedToSnapshotCreated("default", "application-sample", completionTime, creationTime)
+			Expect(testutil.CollectAndCount(BuildPipelineRunCompletedToSnapshotCreatedSeconds)).To(Equal(1))
+		})
+
+		It("observes the integration pipelineRun duration", func() {
+			startTime := metav1.Time{Time: time.Now()}
+			completionTime := metav1.NewTime(startTime.Add(120 * time.Second))
+			RegisterIntegrationPipelineRunDuration("default", "application-sample", &startTime, &completionTime)
+			Expect(testutil.CollectAndCount(IntegrationPipelineRunDurationSeconds)).To(Equal(1))
+		})
+
+		It("counts the errors of the adapter operations by reason", func() {
+			conflict := errors.NewConflict(schema.GroupResource{Resource: "snapshots"}, "snapshot-sample", fmt.Errorf("modified"))
+			RegisterAdapterOperationError("snapshot", "EnsureAllIntegrationTestPipelinesExist", conflict)
+			RegisterAdapterOperationError("snapshot", "EnsureAllIntegrationTestPipelinesExist", fmt.Errorf("wrapped: %w", conflict))
+			RegisterAdapterOperationError("snapshot", "EnsureAllIntegrationTestPipelinesExist", fmt.Errorf("failed to report"))
+			Expect(testutil.ToFloat64(AdapterOperationErrorsTotal.WithLabelValues("snapshot", "EnsureAllIntegrationTestPipelinesExist", "Conflict"))).To(Equal(2.0))
+			Expect(testutil.ToFloat64(AdapterOperationErrorsTotal.WithLabelValues("snapshot", "EnsureAllIntegrationTestPipelinesExist", "Unknown"))).To(Equal(1.0))
+		})
+	})
 })
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification the emitted events conform to.
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventTypePrefix is the prefix of the types of the emitted CloudEvents, followed by the type of the Event,
+	// e.g. "dev.konflux-ci.integration.gate.passed".
+	CloudEventTypePrefix = "dev.konflux-ci.integration."
+
+	// CloudEventsSinkEnvVar is the environment variable holding the address of the sink all of the CloudEvents are
+	// sent to regardless of their namespace, as injected by a Knative SinkBinding.
+	CloudEventsSinkEnvVar = "K_SINK"
+)
+
+// CloudEventsSink sends notification events as CloudEvents in the binary content mode of the HTTP protocol binding
+// to a sink, e.g. a Knative broker.
+type CloudEventsSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewCloudEventsSink creates and returns a CloudEventsSink sending the events to the given address.
+func NewCloudEventsSink(address string) *CloudEventsSink {
+	return &CloudEventsSink{
+		url:        address,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetName returns the name of the sink, only the host of the sink is included since the rest
+// of its address may hold credentials.
+func (s *CloudEventsSink) GetName() string {
+	parsed, err := url.Parse(s.url)
+	if err != nil {
+		return "CloudEvents"
+	}
+	return fmt.Sprintf("CloudEvents %s", parsed.Host)
+}
+
+// GetID returns the identifier of the sink derived from its address.
+func (s *CloudEventsSink) GetID() string {
+	return addressID("cloudevents", s.url)
+}
+
+// Send sends the event to the sink, the JSON encoded event is the data of the CloudEvent.
+func (s *CloudEventsSink) Send(ctx context.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Ce-Specversion", CloudEventsSpecVersion)
+	request.Header.Set("Ce-Id", string(uuid.NewUUID()))
+	request.Header.Set("Ce-Type", CloudEventTypePrefix+event.Type)
+	request.Header.Set("Ce-Source", fmt.Sprintf("/namespaces/%s/applications/%s", event.Namespace, event.Application))
+	request.Header.Set("Ce-Subject", event.Snapshot)
+	request.Header.Set("Ce-Time", event.Time.UTC().Format(time.RFC3339Nano))
+
+	resp, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %q from the CloudEvents sink", resp.Status)
+	}
+	return nil
+}
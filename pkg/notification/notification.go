@@ -0,0 +1,350 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/dryrun"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/status"
+	"github.com/konflux-ci/operator-toolkit/metadata"
+)
+
+const (
+	// SecretName is the name of the Secret configuring the notification sinks of its namespace.
+	// Notifications are disabled in namespaces without it.
+	SecretName = "integration-service-notifications"
+
+	// SecretSlackWebhookURLKey is the key of the Secret holding the address of the Slack incoming webhook.
+	SecretSlackWebhookURLKey = "slack-webhook-url"
+
+	// SecretSlackTemplateKey is the key of the Secret holding an optional Go template overriding the Slack message.
+	SecretSlackTemplateKey = "slack-template"
+
+	// SecretWebhookURLsKey is the key of the Secret holding the HTTPS addresses of the webhooks the events are posted to,
+	// one address per line.
+	SecretWebhookURLsKey = "webhook-urls"
+
+	// SecretWebhookHMACKey is the key of the Secret holding the key the webhook payloads are signed with.
+	SecretWebhookHMACKey = "webhook-hmac-key"
+
+	// SecretCloudEventsSinkURLKey is the key of the Secret holding the address of the sink the CloudEvents
+	// of the namespace are sent to.
+	SecretCloudEventsSinkURLKey = "cloudevents-sink-url"
+
+	// GateNotifiedAnnotation is the annotation added to the Snapshots whose gate result was notified,
+	// holding the notified verdict.
+	GateNotifiedAnnotation = "test.appstudio.openshift.io/gate-notified"
+
+	// NotifiedEventsAnnotation is the annotation added to the Snapshots and integration PipelineRuns holding
+	// the comma separated types of the lifecycle events which were notified.
+	NotifiedEventsAnnotation = "test.appstudio.openshift.io/notified-events"
+
+	// DeliveriesAnnotation is the annotation added to the notified objects holding the identifiers of the sinks which
+	// delivered the events that other sinks failed to deliver, keyed by event type, so that the sinks which already
+	// delivered an event don't deliver it again when it's retried.
+	DeliveriesAnnotation = "test.appstudio.openshift.io/notification-deliveries"
+
+	// EventSnapshotCreated is the type of the event sent when a Snapshot was created.
+	EventSnapshotCreated = "snapshot.created"
+
+	// EventSnapshotReleased is the type of the event sent when the Releases of a Snapshot were created.
+	EventSnapshotReleased = "snapshot.released"
+
+	// EventTestStarted is the type of the event sent when an integration PipelineRun testing a Snapshot started.
+	EventTestStarted = "test.started"
+
+	// EventTestFinished is the type of the event sent when an integration PipelineRun testing a Snapshot finished.
+	EventTestFinished = "test.finished"
+
+	// EventGatePassed is the type of the event sent when a Snapshot passed all of its required integration tests.
+	EventGatePassed = "gate.passed"
+
+	// EventGateFailed is the type of the event sent when a Snapshot failed some of its required integration tests.
+	EventGateFailed = "gate.failed"
+
+	// EventRevalidationFailed is the type of the event sent when a released Snapshot failed its re-run
+	// required integration tests.
+	EventRevalidationFailed = "snapshot.revalidation.failed"
+)
+
+// RetryInterval is how long the controllers wait before retrying to deliver the events which some sinks failed to
+// deliver, the failures of the sinks don't block the other operations of the controllers.
+const RetryInterval = time.Minute
+
+// ErrInvalidConfiguration is returned when the SecretName Secret doesn't configure the sinks correctly.
+var ErrInvalidConfiguration = errors.New("invalid notification configuration")
+
+// Event describes a change of a Snapshot the notification sinks are notified about.
+type Event struct {
+	Type        string           `json:"type"`
+	Time        time.Time        `json:"time"`
+	Namespace   string           `json:"namespace"`
+	Snapshot    string           `json:"snapshot"`
+	Application string           `json:"application"`
+	Component   string           `json:"component,omitempty"`
+	Verdict     string           `json:"verdict,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	Scenarios   []ScenarioResult `json:"scenarios,omitempty"`
+}
+
+// ScenarioResult is the result of a single IntegrationTestScenario of the notified Snapshot.
+type ScenarioResult struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	Details         string `json:"details,omitempty"`
+	PipelineRunName string `json:"pipelineRunName,omitempty"`
+	PipelineRunURL  string `json:"pipelineRunURL,omitempty"`
+}
+
+// Sink delivers notification events to an external service.
+type Sink interface {
+	// GetName returns the name of the sink used when reporting delivery failures.
+	GetName() string
+	// GetID returns the identifier of the sink recorded in the DeliveriesAnnotation. It is stable as long as the sink
+	// is configured the same way, and doesn't expose the address of the sink, which may hold credentials.
+	GetID() string
+	// Send delivers the event to the sink.
+	Send(ctx context.Context, event *Event) error
+}
+
+// NewSinksFromSecretData creates the sinks configured through the data of the SecretName Secret.
+// An empty list is returned when no sink is configured.
+func NewSinksFromSecretData(data map[string][]byte) ([]Sink, error) {
+	sinks := []Sink{}
+
+	if webhookURL := strings.TrimSpace(string(data[SecretSlackWebhookURLKey])); webhookURL != "" {
+		slack, err := NewSlackSink(webhookURL, string(data[SecretSlackTemplateKey]))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, slack)
+	}
+
+	webhookURLs := strings.Fields(string(data[SecretWebhookURLsKey]))
+	if len(webhookURLs) > 0 {
+		hmacKey := strings.TrimSpace(string(data[SecretWebhookHMACKey]))
+		if hmacKey == "" {
+			return nil, fmt.Errorf("the %s key is required to sign the webhook payloads", SecretWebhookHMACKey)
+		}
+		for _, webhookURL := range webhookURLs {
+			if !strings.HasPrefix(webhookURL, "https://") {
+				return nil, fmt.Errorf("the webhook address %s doesn't use HTTPS", webhookURL)
+			}
+			sinks = append(sinks, NewWebhookSink(webhookURL, []byte(hmacKey)))
+		}
+	}
+
+	if sinkURL := strings.TrimSpace(string(data[SecretCloudEventsSinkURLKey])); sinkURL != "" {
+		sinks = append(sinks, NewCloudEventsSink(sinkURL))
+	}
+
+	return sinks, nil
+}
+
+// LoadSinks returns the sinks configured for the given namespace through its SecretName Secret, along with
+// the CloudEvents sink configured for all namespaces through the CloudEventsSinkEnvVar environment variable.
+// Errors caused by a misconfigured Secret wrap ErrInvalidConfiguration.
+func LoadSinks(ctx context.Context, c client.Reader, namespace string) ([]Sink, error) {
+	sinks := []Sink{}
+	if sinkURL := strings.TrimSpace(os.Getenv(CloudEventsSinkEnvVar)); sinkURL != "" {
+		sinks = append(sinks, NewCloudEventsSink(sinkURL))
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: SecretName}, secret)
+	if k8serrors.IsNotFound(err) {
+		return sinks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceSinks, err := NewSinksFromSecretData(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w in the secret %s/%s: %w", ErrInvalidConfiguration, namespace, SecretName, err)
+	}
+	return append(sinks, namespaceSinks...), nil
+}
+
+// IsInvalidConfiguration returns true when the error was caused by a misconfigured SecretName Secret.
+func IsInvalidConfiguration(err error) bool {
+	return errors.Is(err, ErrInvalidConfiguration)
+}
+
+// NewSnapshotEvent creates and returns an event of the given type describing the Snapshot.
+func NewSnapshotEvent(eventType string, snapshot *applicationapiv1alpha1.Snapshot) *Event {
+	return &Event{
+		Type:        eventType,
+		Time:        time.Now().UTC(),
+		Namespace:   snapshot.Namespace,
+		Snapshot:    snapshot.Name,
+		Application: snapshot.Spec.Application,
+		Component:   snapshot.Labels[gitops.SnapshotComponentLabel],
+	}
+}
+
+// NewScenarioResults returns the results of the IntegrationTestScenarios of a Snapshot from its test statuses,
+// linking the integration PipelineRuns in the console.
+func NewScenarioResults(testStatuses *intgteststat.SnapshotIntegrationTestStatuses, namespace string, logger logr.Logger) []ScenarioResult {
+	results := []ScenarioResult{}
+	for _, detail := range testStatuses.GetStatuses() {
+		results = append(results, NewScenarioResult(detail, namespace, logger))
+	}
+	return results
+}
+
+// NewScenarioResult returns the result of an IntegrationTestScenario from its test status detail, linking
+// the integration PipelineRun in the console.
+func NewScenarioResult(detail *intgteststat.IntegrationTestStatusDetail, namespace string, logger logr.Logger) ScenarioResult {
+	result := ScenarioResult{
+		Name:            detail.ScenarioName,
+		Status:          detail.Status.String(),
+		Details:         detail.Details,
+		PipelineRunName: detail.TestPipelineRunName,
+	}
+	if detail.TestPipelineRunName != "" {
+		result.PipelineRunURL = status.FormatPipelineURL(detail.TestPipelineRunName, namespace, logger)
+	}
+	return result
+}
+
+// IsEventNotified returns true when the lifecycle event of the given type was recorded as notified
+// in the NotifiedEventsAnnotation of the object.
+func IsEventNotified(object client.Object, eventType string) bool {
+	return slices.Contains(strings.Split(object.GetAnnotations()[NotifiedEventsAnnotation], ","), eventType)
+}
+
+// SetEventNotified records the lifecycle event of the given type as notified in the NotifiedEventsAnnotation
+// of the object.
+func SetEventNotified(object client.Object, eventType string) error {
+	if IsEventNotified(object, eventType) {
+		return nil
+	}
+	notified := object.GetAnnotations()[NotifiedEventsAnnotation]
+	if notified != "" {
+		notified += ","
+	}
+	return metadata.SetAnnotation(object, NotifiedEventsAnnotation, notified+eventType)
+}
+
+// Send delivers the event to all of the given sinks, a failure of one sink doesn't prevent
+// the delivery to the others.
+func Send(ctx context.Context, sinks []Sink, event *Event) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := send(ctx, sink, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendOnce delivers the event to the given sinks which didn't deliver it yet according to the DeliveriesAnnotation of
+// the object, a failure of one sink doesn't prevent the delivery to the others. The sinks which delivered the event
+// are recorded in the annotation while some sinks failed to deliver it, and the record is removed once all of them
+// did. The annotation is only set on the given object, the caller persists it also when an error is returned.
+func SendOnce(ctx context.Context, object client.Object, sinks []Sink, event *Event) error {
+	deliveries := getDeliveries(object)
+	delivered := deliveries[event.Type]
+
+	var errs []error
+	for _, sink := range sinks {
+		if slices.Contains(delivered, sink.GetID()) {
+			continue
+		}
+		if err := send(ctx, sink, event); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delivered = append(delivered, sink.GetID())
+	}
+
+	if len(errs) == 0 || len(delivered) == 0 {
+		delete(deliveries, event.Type)
+	} else {
+		deliveries[event.Type] = delivered
+	}
+	setDeliveries(object, deliveries)
+
+	return errors.Join(errs...)
+}
+
+// send delivers the event to the given sink, the event is only logged in dry-run mode.
+func send(ctx context.Context, sink Sink, event *Event) error {
+	if dryrun.IsEnabled() {
+		ctrllog.FromContext(ctx).Info("Dry-run: skipping the notification", "event", event.Type, "sink", sink.GetName())
+		return nil
+	}
+
+	if err := sink.Send(ctx, event); err != nil {
+		return fmt.Errorf("failed to send the %s event to the %s sink: %w", event.Type, sink.GetName(), err)
+	}
+	return nil
+}
+
+// getDeliveries returns the identifiers of the sinks which delivered the events, keyed by event type, as recorded in
+// the DeliveriesAnnotation of the object. An unreadable record is ignored, so the events are delivered again.
+func getDeliveries(object client.Object) map[string][]string {
+	deliveries := map[string][]string{}
+	if value, ok := object.GetAnnotations()[DeliveriesAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &deliveries); err != nil {
+			return map[string][]string{}
+		}
+	}
+	return deliveries
+}
+
+// setDeliveries records the given deliveries in the DeliveriesAnnotation of the object, the annotation is removed
+// when there are none.
+func setDeliveries(object client.Object, deliveries map[string][]string) {
+	if len(deliveries) == 0 {
+		annotations := object.GetAnnotations()
+		delete(annotations, DeliveriesAnnotation)
+		object.SetAnnotations(annotations)
+		return
+	}
+
+	value, _ := json.Marshal(deliveries)
+	_ = metadata.SetAnnotation(object, DeliveriesAnnotation, string(value))
+}
+
+// addressID returns the identifier of a sink of the given kind, made of a short hash of its address, which doesn't
+// expose the address.
+func addressID(kind, address string) string {
+	sum := sha256.Sum256([]byte(address))
+	return kind + "-" + hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/notification"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+var _ = Describe("Notification sinks", func() {
+
+	var (
+		server   *httptest.Server
+		messages []string
+		status   int
+		event    *notification.Event
+	)
+
+	BeforeEach(func() {
+		messages = []string{}
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			message := map[string]string{}
+			Expect(json.NewDecoder(r.Body).Decode(&message)).To(Succeed())
+			messages = append(messages, message["text"])
+			w.WriteHeader(status)
+		}))
+
+		event = &notification.Event{
+			Type:        notification.EventGatePassed,
+			Namespace:   "default",
+			Snapshot:    "snapshot-sample",
+			Application: "application-sample",
+			Component:   "component-sample",
+			Verdict:     "Passed",
+			Message:     "all required integration tests passed",
+			Scenarios: []notification.ScenarioResult{
+				{
+					Name:            "scenario-a",
+					Status:          "TestPassed",
+					PipelineRunName: "pipelinerun-a",
+					PipelineRunURL:  "https://console.example.com/pipelinerun-a",
+				},
+				{
+					Name:   "scenario-b",
+					Status: "Pending",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("creates no sinks when none is configured", func() {
+		sinks, err := notification.NewSinksFromSecretData(map[string][]byte{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks).To(BeEmpty())
+	})
+
+	It("creates the Slack sink from the secret data", func() {
+		sinks, err := notification.NewSinksFromSecretData(map[string][]byte{
+			notification.SecretSlackWebhookURLKey: []byte(server.URL),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks).To(HaveLen(1))
+		Expect(sinks[0].GetName()).To(Equal("Slack"))
+	})
+
+	It("fails to create the Slack sink with an invalid template", func() {
+		_, err := notification.NewSinksFromSecretData(map[string][]byte{
+			notification.SecretSlackWebhookURLKey: []byte(server.URL),
+			notification.SecretSlackTemplateKey:   []byte("{{ .Snapshot"),
+		})
+		Expect(err).To(MatchError(ContainSubstring("failed to parse the Slack message template")))
+	})
+
+	It("posts the default message to the Slack webhook", func() {
+		sink, err := notification.NewSlackSink(server.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(notification.Send(context.Background(), []notification.Sink{sink}, event)).To(Succeed())
+		Expect(messages).To(HaveLen(1))
+		Expect(messages[0]).To(HavePrefix(":white_check_mark: Snapshot *snapshot-sample* of application *application-sample* (component *component-sample*) in namespace *default*: all required integration tests passed"))
+		Expect(messages[0]).To(ContainSubstring("• *scenario-a*: TestPassed (<https://console.example.com/pipelinerun-a|pipelinerun-a>)"))
+		Expect(messages[0]).To(HaveSuffix("• *scenario-b*: Pending"))
+	})
+
+	It("posts a custom message to the Slack webhook", func() {
+		sink, err := notification.NewSlackSink(server.URL, "{{ .Type }}: {{ .Snapshot }} {{ len .Scenarios }}")
+		Expect(err).NotTo(HaveOccurred())
+
+		event.Type = notification.EventGateFailed
+		Expect(sink.Send(context.Background(), event)).To(Succeed())
+		Expect(messages).To(Equal([]string{"gate.failed: snapshot-sample 2"}))
+	})
+
+	It("reports the sinks which failed to deliver the event", func() {
+		status = http.StatusForbidden
+		sink, err := notification.NewSlackSink(server.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = notification.Send(context.Background(), []notification.Sink{sink}, event)
+		Expect(err).To(MatchError(ContainSubstring("failed to send the gate.passed event to the Slack sink")))
+		Expect(err).To(MatchError(ContainSubstring("403 Forbidden")))
+	})
+
+	It("posts the failed revalidations to the Slack webhook", func() {
+		sink, err := notification.NewSlackSink(server.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		event.Type = notification.EventRevalidationFailed
+		event.Message = "The released Snapshot failed 1 of the 2 integration tests re-run by SnapshotRun scenario-a-x7k2p"
+		Expect(sink.Send(context.Background(), event)).To(Succeed())
+		Expect(messages).To(HaveLen(1))
+		Expect(messages[0]).To(HavePrefix(":x: Snapshot *snapshot-sample*"))
+		Expect(messages[0]).To(ContainSubstring("The released Snapshot failed 1 of the 2 integration tests"))
+	})
+
+	It("fails to create the webhook sinks without the HMAC key", func() {
+		_, err := notification.NewSinksFromSecretData(map[string][]byte{
+			notification.SecretWebhookURLsKey: []byte("https://hooks.example.com/integration"),
+		})
+		Expect(err).To(MatchError(ContainSubstring("the webhook-hmac-key key is required")))
+	})
+
+	It("fails to create the webhook sinks for addresses not using HTTPS", func() {
+		_, err := notification.NewSinksFromSecretData(map[string][]byte{
+			notification.SecretWebhookURLsKey: []byte("https://hooks.example.com/integration\nhttp://hooks.example.com/insecure"),
+			notification.SecretWebhookHMACKey: []byte("hmac-key"),
+		})
+		Expect(err).To(MatchError(ContainSubstring("the webhook address http://hooks.example.com/insecure doesn't use HTTPS")))
+	})
+
+	It("creates a webhook sink for each configured address", func() {
+		sinks, err := notification.NewSinksFromSecretData(map[string][]byte{
+			notification.SecretWebhookURLsKey: []byte("https://hooks.example.com/integration?token=secret\nhttps://dashboard.example.com/events\n"),
+			notification.SecretWebhookHMACKey: []byte("hmac-key"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks).To(HaveLen(2))
+		Expect(sinks[0].GetName()).To(Equal("webhook hooks.example.com"))
+		Expect(sinks[1].GetName()).To(Equal("webhook dashboard.example.com"))
+	})
+
+	It("ignores the lifecycle events other than the gate results in the Slack sink", func() {
+		sink, err := notification.NewSlackSink(server.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		event.Type = notification.EventSnapshotCreated
+		Expect(sink.Send(context.Background(), event)).To(Succeed())
+		Expect(messages).To(BeEmpty())
+	})
+
+	It("posts the signed event to the webhook", func() {
+		var body []byte
+		var headers http.Header
+		webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			headers = r.Header
+		}))
+		defer webhook.Close()
+
+		sink := notification.NewWebhookSink(webhook.URL, []byte("hmac-key"))
+		Expect(sink.Send(context.Background(), event)).To(Succeed())
+
+		Expect(headers.Get("Content-Type")).To(Equal("application/json"))
+		Expect(headers.Get(notification.WebhookEventHeader)).To(Equal(notification.EventGatePassed))
+		Expect(headers.Get(notification.WebhookSignatureHeader)).To(Equal("sha256=" + notification.Sign([]byte("hmac-key"), body)))
+
+		payload := map[string]any{}
+		Expect(json.Unmarshal(body, &payload)).To(Succeed())
+		Expect(payload).To(HaveKeyWithValue("type", "gate.passed"))
+		Expect(payload).To(HaveKeyWithValue("snapshot", "snapshot-sample"))
+		Expect(payload).To(HaveKeyWithValue("verdict", "Passed"))
+		Expect(payload["scenarios"]).To(HaveLen(2))
+	})
+
+	It("computes the HMAC-SHA256 signature of the payload", func() {
+		Expect(notification.Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog"))).
+			To(Equal("f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"))
+	})
+
+	It("records the notified lifecycle events in the snapshot annotation", func() {
+		snapshot := &applicationapiv1alpha1.Snapshot{}
+		Expect(notification.IsEventNotified(snapshot, notification.EventSnapshotCreated)).To(BeFalse())
+
+		Expect(notification.SetEventNotified(snapshot, notification.EventSnapshotCreated)).To(Succeed())
+		Expect(notification.SetEventNotified(snapshot, notification.EventSnapshotCreated)).To(Succeed())
+		Expect(notification.SetEventNotified(snapshot, notification.EventSnapshotReleased)).To(Succeed())
+		Expect(snapshot.Annotations).To(HaveKeyWithValue(notification.NotifiedEventsAnnotation, "snapshot.created,snapshot.released"))
+		Expect(notification.IsEventNotified(snapshot, notification.EventSnapshotReleased)).To(BeTrue())
+	})
+
+	It("retries the delivery only to the sinks which failed to deliver the event", func() {
+		failingStatus := http.StatusServiceUnavailable
+		failingRequests := 0
+		failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			failingRequests++
+			w.WriteHeader(failingStatus)
+		}))
+		defer failingServer.Close()
+
+		deliveringSink, err := notification.NewSlackSink(server.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+		failingSink, err := notification.NewSlackSink(failingServer.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deliveringSink.GetID()).NotTo(Equal(failingSink.GetID()))
+		Expect(deliveringSink.GetID()).NotTo(ContainSubstring(server.URL))
+		sinks := []notification.Sink{deliveringSink, failingSink}
+		snapshot := &applicationapiv1alpha1.Snapshot{}
+
+		err = notification.SendOnce(context.Background(), snapshot, sinks, event)
+		Expect(err).To(MatchError(ContainSubstring("503 Service Unavailable")))
+		Expect(messages).To(HaveLen(1))
+		Expect(failingRequests).To(Equal(1))
+		Expect(snapshot.Annotations).To(HaveKeyWithValue(notification.DeliveriesAnnotation,
+			`{"gate.passed":["`+deliveringSink.GetID()+`"]}`))
+
+		err = notification.SendOnce(context.Background(), snapshot, sinks, event)
+		Expect(err).To(HaveOccurred())
+		Expect(messages).To(HaveLen(1))
+		Expect(failingRequests).To(Equal(2))
+
+		failingStatus = http.StatusOK
+		Expect(notification.SendOnce(context.Background(), snapshot, sinks, event)).To(Succeed())
+		Expect(messages).To(HaveLen(1))
+		Expect(failingRequests).To(Equal(3))
+		Expect(snapshot.Annotations).NotTo(HaveKey(notification.DeliveriesAnnotation))
+
+		Expect(notification.SendOnce(context.Background(), snapshot, sinks, event)).To(Succeed())
+		Expect(messages).To(HaveLen(2))
+		Expect(failingRequests).To(Equal(4))
+	})
+
+	It("creates the CloudEvents sink from the secret data", func() {
+		sinks, err := notification.NewSinksFromSecretData(map[string][]byte{
+			notification.SecretCloudEventsSinkURLKey: []byte("http://broker-ingress.knative-eventing.svc.cluster.local/default/default"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sinks).To(HaveLen(1))
+		Expect(sinks[0].GetName()).To(Equal("CloudEvents broker-ingress.knative-eventing.svc.cluster.local"))
+	})
+
+	It("sends the event as a binary mode CloudEvent", func() {
+		var body []byte
+		var headers http.Header
+		broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			headers = r.Header
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer broker.Close()
+
+		event.Time = time.Date(2024, 5, 14, 10, 30, 0, 0, time.UTC)
+		sink := notification.NewCloudEventsSink(broker.URL)
+		Expect(sink.Send(context.Background(), event)).To(Succeed())
+
+		Expect(headers.Get("Content-Type")).To(Equal("application/json"))
+		Expect(headers.Get("Ce-Specversion")).To(Equal("1.0"))
+		Expect(headers.Get("Ce-Id")).NotTo(BeEmpty())
+		Expect(headers.Get("Ce-Type")).To(Equal("dev.konflux-ci.integration.gate.passed"))
+		Expect(headers.Get("Ce-Source")).To(Equal("/namespaces/default/applications/application-sample"))
+		Expect(headers.Get("Ce-Subject")).To(Equal("snapshot-sample"))
+		Expect(headers.Get("Ce-Time")).To(Equal("2024-05-14T10:30:00Z"))
+
+		data := notification.Event{}
+		Expect(json.Unmarshal(body, &data)).To(Succeed())
+		Expect(data.Type).To(Equal(notification.EventGatePassed))
+		Expect(data.Scenarios).To(HaveLen(2))
+	})
+
+	It("reports the CloudEvents which weren't accepted by the sink", func() {
+		broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer broker.Close()
+
+		err := notification.NewCloudEventsSink(broker.URL).Send(context.Background(), event)
+		Expect(err).To(MatchError(ContainSubstring("unexpected status \"400 Bad Request\" from the CloudEvents sink")))
+	})
+})
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultSlackTemplate is the Go template of the Slack message used when the Secret doesn't override it.
+// The template is executed with the notified Event.
+const DefaultSlackTemplate = `{{ if eq .Type "gate.passed" }}:white_check_mark:{{ else }}:x:{{ end }} Snapshot *{{ .Snapshot }}* of application *{{ .Application }}*{{ if .Component }} (component *{{ .Component }}*){{ end }} in namespace *{{ .Namespace }}*: {{ .Message }}
+{{ range .Scenarios }}• *{{ .Name }}*: {{ .Status }}{{ if .PipelineRunURL }} (<{{ .PipelineRunURL }}|{{ .PipelineRunName }}>){{ end }}
+{{ end }}`
+
+// SlackSink posts notification events as messages to a Slack incoming webhook.
+type SlackSink struct {
+	webhookURL string
+	template   *template.Template
+	httpClient *http.Client
+}
+
+// slackMessage is the payload of a Slack incoming webhook request.
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// NewSlackSink creates and returns a SlackSink posting to the given webhook. The messages are rendered
+// from the given Go template, DefaultSlackTemplate is used when it is empty.
+func NewSlackSink(webhookURL, messageTemplate string) (*SlackSink, error) {
+	if strings.TrimSpace(messageTemplate) == "" {
+		messageTemplate = DefaultSlackTemplate
+	}
+	t, err := template.New("slack").Parse(messageTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Slack message template: %w", err)
+	}
+
+	return &SlackSink{
+		webhookURL: webhookURL,
+		template:   t,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// GetName returns the name of the sink.
+func (s *SlackSink) GetName() string {
+	return "Slack"
+}
+
+// GetID returns the identifier of the sink derived from its webhook address.
+func (s *SlackSink) GetID() string {
+	return addressID("slack", s.webhookURL)
+}
+
+// Send renders the message for the event and posts it to the Slack webhook. Only the gate results and
+// the failed revalidations are posted, the other lifecycle events are ignored.
+func (s *SlackSink) Send(ctx context.Context, event *Event) error {
+	if event.Type != EventGatePassed && event.Type != EventGateFailed && event.Type != EventRevalidationFailed {
+		return nil
+	}
+
+	text := bytes.Buffer{}
+	if err := s.template.Execute(&text, event); err != nil {
+		return fmt.Errorf("failed to render the Slack message: %w", err)
+	}
+	payload, err := json.Marshal(&slackMessage{Text: strings.TrimSpace(text.String())})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %q from the Slack webhook", resp.Status)
+	}
+	return nil
+}
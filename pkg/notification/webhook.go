@@ -0,0 +1,106 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// WebhookEventHeader is the header of the webhook requests holding the type of the delivered event.
+	WebhookEventHeader = "X-Integration-Service-Event"
+
+	// WebhookSignatureHeader is the header of the webhook requests holding the hex encoded HMAC-SHA256 signature
+	// of the payload, prefixed with "sha256=".
+	WebhookSignatureHeader = "X-Integration-Service-Signature-256"
+)
+
+// WebhookSink posts notification events as signed JSON payloads to an HTTPS webhook.
+type WebhookSink struct {
+	url        string
+	hmacKey    []byte
+	httpClient *http.Client
+}
+
+// NewWebhookSink creates and returns a WebhookSink posting to the given address, signing the payloads
+// with the given key.
+func NewWebhookSink(address string, hmacKey []byte) *WebhookSink {
+	return &WebhookSink{
+		url:        address,
+		hmacKey:    hmacKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetName returns the name of the sink, only the host of the webhook is included since the rest
+// of its address may hold credentials.
+func (s *WebhookSink) GetName() string {
+	parsed, err := url.Parse(s.url)
+	if err != nil {
+		return "webhook"
+	}
+	return fmt.Sprintf("webhook %s", parsed.Host)
+}
+
+// GetID returns the identifier of the sink derived from its address.
+func (s *WebhookSink) GetID() string {
+	return addressID("webhook", s.url)
+}
+
+// Send posts the JSON encoded event to the webhook.
+func (s *WebhookSink) Send(ctx context.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, event.Type)
+	request.Header.Set(WebhookSignatureHeader, "sha256="+Sign(s.hmacKey, payload))
+
+	resp, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %q from the webhook", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the payload, receivers verify the WebhookSignatureHeader
+// by computing it with the shared key.
+func Sign(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
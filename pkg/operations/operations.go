@@ -0,0 +1,241 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/tracing"
+	"github.com/konflux-ci/operator-toolkit/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ResultContinue is the result of the operations letting the chain continue with the next operation.
+	ResultContinue = "continue"
+
+	// ResultRequeue is the result of the operations requesting the reconciliation to be requeued.
+	ResultRequeue = "requeue"
+
+	// ResultStop is the result of the operations stopping the processing of the reconciliation.
+	ResultStop = "stop"
+
+	// ResultError is the result of the operations which failed.
+	ResultError = "error"
+)
+
+// DefaultShutdownDrainTimeout is the default longest time the operations in flight when the manager shuts down are
+// given to finish, matching the default graceful shutdown timeout of the manager.
+const DefaultShutdownDrainTimeout = 30 * time.Second
+
+// shutdownDrainTimeout is the longest time the operations in flight when the manager shuts down are given to finish.
+var shutdownDrainTimeout = DefaultShutdownDrainTimeout
+
+// SetShutdownDrainTimeout sets the longest time the operations in flight when the manager shuts down are given to
+// finish, it should not exceed the graceful shutdown timeout of the manager.
+func SetShutdownDrainTimeout(timeout time.Duration) {
+	shutdownDrainTimeout = timeout
+}
+
+// WithShutdownDrain returns a context carrying the values of the given reconciliation context, which isn't cancelled
+// right away when the manager shuts down. The operations in flight, e.g. reporting the status of a Snapshot to its
+// git provider, can then finish instead of being interrupted halfway; the returned context is only cancelled once the
+// shutdown drain timeout has elapsed. The returned function must be called once the reconciliation returns.
+func WithShutdownDrain(ctx context.Context) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(shutdownDrainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-drainCtx.Done():
+		}
+	})
+
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Operation is a named operation of an adapter.
+type Operation struct {
+	// Name is the name under which the operation is traced and measured.
+	Name string
+
+	// Run executes the operation.
+	Run controller.Operation
+}
+
+// Chain is an ordered list of the adapter operations run on each reconciliation of a controller.
+// Each step of the chain is either a single operation or a group of independent operations run concurrently.
+type Chain struct {
+	controllerName string
+	steps          [][]Operation
+}
+
+// NewChain creates and returns a Chain of the given operations for the named controller.
+// Each of the operations is named after the adapter method backing it, e.g. "EnsureAllReleasesExist".
+func NewChain(controllerName string, operations ...controller.Operation) *Chain {
+	return (&Chain{controllerName: controllerName}).AppendAll(operations...)
+}
+
+// Append adds the given operation under the given name at the end of the chain and returns the chain.
+func (c *Chain) Append(name string, operation controller.Operation) *Chain {
+	c.steps = append(c.steps, []Operation{{Name: name, Run: operation}})
+
+	return c
+}
+
+// AppendAll adds the given operations at the end of the chain, to be run one after the other, and returns the chain.
+// Each of the operations is named after the adapter method backing it.
+func (c *Chain) AppendAll(operations ...controller.Operation) *Chain {
+	for _, operation := range operations {
+		c.Append(tracing.GetOperationName(operation), operation)
+	}
+
+	return c
+}
+
+// AppendConcurrent adds the given operations at the end of the chain as a single step, in which they are run
+// concurrently, and returns the chain. Each of the operations is named after the adapter method backing it.
+// The operations of the step must be independent of each other, i.e. none of them may modify the state of the
+// adapter read by the others, as the next step only starts once all of them returned.
+func (c *Chain) AppendConcurrent(operations ...controller.Operation) *Chain {
+	step := make([]Operation, 0, len(operations))
+	for _, operation := range operations {
+		step = append(step, Operation{Name: tracing.GetOperationName(operation), Run: operation})
+	}
+	if len(step) > 0 {
+		c.steps = append(c.steps, step)
+	}
+
+	return c
+}
+
+// Names returns the names of the operations of the chain in the order they are run. The operations run concurrently
+// are listed in the order they were appended.
+func (c *Chain) Names() []string {
+	names := []string{}
+	for _, step := range c.steps {
+		for _, operation := range step {
+			names = append(names, operation.Name)
+		}
+	}
+
+	return names
+}
+
+// Run runs the steps of the chain in order, recording a child span of the span found in the given context
+// and the duration and result of each of the operations. Like controller.ReconcileHandler, the chain stops at the
+// first step which fails or requests the reconciliation to be requeued or stopped. When the given context is cancelled,
+// e.g. since the manager is shutting down, the chain stops before its next step and requests the reconciliation to be
+// requeued, so that the remaining operations are run by the next reconciliation.
+func (c *Chain) Run(ctx context.Context) (ctrl.Result, error) {
+	for i, step := range c.steps {
+		if ctx.Err() != nil {
+			ctrl.LoggerFrom(ctx).Info("Interrupting the operations of the reconciliation", "controller", c.controllerName,
+				"nextOperation", step[0].Name, "remainingSteps", len(c.steps)-i)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		result, err := c.runStep(ctx, step)
+
+		switch {
+		case err != nil || result.RequeueRequest:
+			return ctrl.Result{RequeueAfter: result.RequeueDelay}, err
+		case result.CancelRequest:
+			return ctrl.Result{}, nil
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// runStep runs the operations of the given step, concurrently if there are several of them, and waits for all of
+// them to return. The results of the concurrent operations are combined: the errors of all the failed operations are
+// joined, and otherwise the shortest delay requested by the operations requeueing the reconciliation takes precedence
+// over stopping it, so that no operation is left waiting longer than it asked for.
+func (c *Chain) runStep(ctx context.Context, step []Operation) (controller.OperationResult, error) {
+	if len(step) == 1 {
+		return c.runOperation(ctx, step[0])
+	}
+
+	results := make([]controller.OperationResult, len(step))
+	errs := make([]error, len(step))
+	var wg sync.WaitGroup
+	for i, operation := range step {
+		wg.Add(1)
+		go func(i int, operation Operation) {
+			defer wg.Done()
+			results[i], errs[i] = c.runOperation(ctx, operation)
+		}(i, operation)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return controller.RequeueWithError(err)
+	}
+	combined := controller.OperationResult{}
+	for _, result := range results {
+		switch {
+		case result.RequeueRequest:
+			if !combined.RequeueRequest || result.RequeueDelay < combined.RequeueDelay {
+				combined.RequeueDelay = result.RequeueDelay
+			}
+			combined.RequeueRequest = true
+		case result.CancelRequest:
+			combined.CancelRequest = true
+		}
+	}
+	if combined.RequeueRequest {
+		combined.CancelRequest = false
+	}
+
+	return combined, nil
+}
+
+// runOperation runs the given operation, tracing it and recording its duration, its result and its error if it
+// failed in the metrics.
+func (c *Chain) runOperation(ctx context.Context, operation Operation) (controller.OperationResult, error) {
+	start := time.Now()
+	result, err := tracing.TraceOperation(ctx, operation.Name, operation.Run)()
+	metrics.RegisterAdapterOperation(c.controllerName, operation.Name, getResultName(result, err), time.Since(start))
+	if err != nil {
+		metrics.RegisterAdapterOperationError(c.controllerName, operation.Name, err)
+	}
+
+	return result, err
+}
+
+// getResultName returns the name of the result returned by an operation, as recorded in the metrics.
+func getResultName(result controller.OperationResult, err error) string {
+	switch {
+	case err != nil:
+		return ResultError
+	case result.RequeueRequest:
+		return ResultRequeue
+	case result.CancelRequest:
+		return ResultStop
+	default:
+		return ResultContinue
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/operations"
+	"github.com/konflux-ci/operator-toolkit/controller"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type testAdapter struct {
+	mutex sync.Mutex
+	calls []string
+}
+
+func (a *testAdapter) record(call string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.calls = append(a.calls, call)
+}
+
+func (a *testAdapter) EnsureFirstStepIsDone() (controller.OperationResult, error) {
+	a.record("first")
+	return controller.ContinueProcessing()
+}
+
+func (a *testAdapter) EnsureSecondStepIsDone() (controller.OperationResult, error) {
+	a.record("second")
+	return controller.ContinueProcessing()
+}
+
+// observedOperations returns the number of runs of the operation observed with the given result.
+func observedOperations(controllerName, operation, result string) uint64 {
+	metric := &dto.Metric{}
+	observer := metrics.AdapterOperationDurationSeconds.WithLabelValues(controllerName, operation, result)
+	Expect(observer.(prometheus.Metric).Write(metric)).To(Succeed())
+	return metric.GetHistogram().GetSampleCount()
+}
+
+var _ = Describe("Operation chain", func() {
+
+	var (
+		ctx     context.Context
+		adapter *testAdapter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		adapter = &testAdapter{}
+	})
+
+	It("names the operations after the adapter methods", func() {
+		chain := operations.NewChain("test", adapter.EnsureFirstStepIsDone, adapter.EnsureSecondStepIsDone).
+			Append("CustomStep", controller.ContinueProcessing)
+		Expect(chain.Names()).To(Equal([]string{"EnsureFirstStepIsDone", "EnsureSecondStepIsDone", "CustomStep"}))
+	})
+
+	It("runs all the operations in order and records their results", func() {
+		before := observedOperations("ordered", "EnsureSecondStepIsDone", operations.ResultContinue)
+
+		result, err := operations.NewChain("ordered", adapter.EnsureFirstStepIsDone, adapter.EnsureSecondStepIsDone).Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsZero()).To(BeTrue())
+		Expect(adapter.calls).To(Equal([]string{"first", "second"}))
+		Expect(observedOperations("ordered", "EnsureSecondStepIsDone", operations.ResultContinue)).To(Equal(before + 1))
+	})
+
+	It("stops at the first operation stopping the processing", func() {
+		result, err := operations.NewChain("stopped").
+			Append("Stop", controller.StopProcessing).
+			Append("First", adapter.EnsureFirstStepIsDone).
+			Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsZero()).To(BeTrue())
+		Expect(adapter.calls).To(BeEmpty())
+		Expect(observedOperations("stopped", "Stop", operations.ResultStop)).To(Equal(uint64(1)))
+	})
+
+	It("requeues the reconciliation after the delay requested by an operation", func() {
+		result, err := operations.NewChain("requeued").
+			Append("Requeue", func() (controller.OperationResult, error) {
+				return controller.RequeueAfter(time.Minute, nil)
+			}).
+			Append("First", adapter.EnsureFirstStepIsDone).
+			Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(adapter.calls).To(BeEmpty())
+		Expect(observedOperations("requeued", "Requeue", operations.ResultRequeue)).To(Equal(uint64(1)))
+	})
+
+	It("returns the error of a failed operation", func() {
+		_, err := operations.NewChain("failed", adapter.EnsureFirstStepIsDone).
+			Append("Fail", func() (controller.OperationResult, error) {
+				return controller.RequeueOnErrorOrContinue(fmt.Errorf("something failed"))
+			}).
+			Append("Second", adapter.EnsureSecondStepIsDone).
+			Run(ctx)
+		Expect(err).To(MatchError("something failed"))
+		Expect(adapter.calls).To(Equal([]string{"first"}))
+		Expect(observedOperations("failed", "Fail", operations.ResultError)).To(Equal(uint64(1)))
+		Expect(testutil.ToFloat64(metrics.AdapterOperationErrorsTotal.WithLabelValues("failed", "Fail", "Unknown"))).To(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.AdapterOperationErrorsTotal.WithLabelValues("failed", "First", "Unknown"))).To(BeZero())
+	})
+
+	It("runs the concurrent operations together before the next step", func() {
+		started := make(chan struct{})
+		waitForOther := func(name string) controller.Operation {
+			return func() (controller.OperationResult, error) {
+				adapter.record(name)
+				select {
+				case started <- struct{}{}:
+				case <-started:
+				case <-time.After(time.Second):
+					return controller.RequeueWithError(fmt.Errorf("%s was run alone", name))
+				}
+				return controller.ContinueProcessing()
+			}
+		}
+
+		chain := operations.NewChain("concurrent", adapter.EnsureFirstStepIsDone).
+			AppendConcurrent(waitForOther("left"), waitForOther("right")).
+			AppendAll(adapter.EnsureSecondStepIsDone)
+		Expect(chain.Names()).To(HaveLen(4))
+		Expect(chain.Names()[0]).To(Equal("EnsureFirstStepIsDone"))
+		Expect(chain.Names()[3]).To(Equal("EnsureSecondStepIsDone"))
+
+		result, err := chain.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsZero()).To(BeTrue())
+		Expect(adapter.calls).To(HaveLen(4))
+		Expect(adapter.calls[0]).To(Equal("first"))
+		Expect(adapter.calls[1:3]).To(ConsistOf("left", "right"))
+		Expect(adapter.calls[3]).To(Equal("second"))
+	})
+
+	It("joins the errors of the concurrent operations", func() {
+		_, err := operations.NewChain("concurrently-failed").
+			AppendConcurrent(
+				func() (controller.OperationResult, error) {
+					return controller.RequeueWithError(fmt.Errorf("left failed"))
+				},
+				adapter.EnsureFirstStepIsDone,
+				func() (controller.OperationResult, error) {
+					return controller.RequeueOnErrorOrContinue(fmt.Errorf("right failed"))
+				},
+			).
+			AppendAll(adapter.EnsureSecondStepIsDone).
+			Run(ctx)
+		Expect(err).To(MatchError(ContainSubstring("left failed")))
+		Expect(err).To(MatchError(ContainSubstring("right failed")))
+		Expect(adapter.calls).To(Equal([]string{"first"}))
+	})
+
+	It("requeues after the shortest delay requested by the concurrent operations", func() {
+		result, err := operations.NewChain("concurrently-requeued").
+			AppendConcurrent(
+				func() (controller.OperationResult, error) {
+					return controller.RequeueAfter(time.Hour, nil)
+				},
+				controller.StopProcessing,
+				func() (controller.OperationResult, error) {
+					return controller.RequeueAfter(time.Minute, nil)
+				},
+			).
+			AppendAll(adapter.EnsureFirstStepIsDone).
+			Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(adapter.calls).To(BeEmpty())
+	})
+
+	It("stops the chain when one of the concurrent operations stops the processing", func() {
+		result, err := operations.NewChain("concurrently-stopped").
+			AppendConcurrent(controller.StopProcessing, adapter.EnsureFirstStepIsDone).
+			AppendAll(adapter.EnsureSecondStepIsDone).
+			Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.IsZero()).To(BeTrue())
+		Expect(adapter.calls).To(Equal([]string{"first"}))
+	})
+
+	It("finishes the running operation and interrupts the chain when the context is cancelled", func() {
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		result, err := operations.NewChain("interrupted",
+			func() (controller.OperationResult, error) {
+				cancel()
+				adapter.record("running")
+				return controller.ContinueProcessing()
+			},
+			adapter.EnsureFirstStepIsDone,
+		).Run(cancelledCtx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeTrue())
+		Expect(adapter.calls).To(Equal([]string{"running"}))
+	})
+
+	Describe("WithShutdownDrain", func() {
+		type contextKey struct{}
+
+		AfterEach(func() {
+			operations.SetShutdownDrainTimeout(operations.DefaultShutdownDrainTimeout)
+		})
+
+		It("keeps the context alive for the drain timeout once the reconciliation context is cancelled", func() {
+			operations.SetShutdownDrainTimeout(200 * time.Millisecond)
+			reconcileCtx, cancelReconcile := context.WithCancel(context.WithValue(ctx, contextKey{}, "value"))
+			drainCtx, cancel := operations.WithShutdownDrain(reconcileCtx)
+			defer cancel()
+			Expect(drainCtx.Value(contextKey{})).To(Equal("value"))
+
+			cancelReconcile()
+			Consistently(drainCtx.Done(), 100*time.Millisecond).ShouldNot(BeClosed())
+			Eventually(drainCtx.Done(), time.Second).Should(BeClosed())
+		})
+
+		It("cancels the context once the reconciliation returns", func() {
+			drainCtx, cancel := operations.WithShutdownDrain(ctx)
+			Expect(drainCtx.Err()).NotTo(HaveOccurred())
+			cancel()
+			Expect(drainCtx.Err()).To(MatchError(context.Canceled))
+		})
+	})
+})
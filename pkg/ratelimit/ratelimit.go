@@ -0,0 +1,93 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxWait is the longest time a PipelineRun creation waits for the rate limiter before giving up, so the
+// reconciliations don't keep the workers of the controllers busy for long.
+const DefaultMaxWait = 10 * time.Second
+
+// ErrRateLimited is returned by Wait when the creation isn't allowed by the rate limiter within the maximum wait.
+var ErrRateLimited = errors.New("the PipelineRun creation is rate limited")
+
+// Limiter limits the rate of the PipelineRun creations through a token bucket, exporting the number of creations
+// waiting for a token and their wait time as metrics.
+type Limiter struct {
+	limiter *rate.Limiter
+	maxWait time.Duration
+}
+
+// pipelineRunCreationLimiter is the limiter shared by all controllers creating PipelineRuns, by default it
+// doesn't limit the creations.
+var pipelineRunCreationLimiter = NewLimiter(0, 0, DefaultMaxWait)
+
+// NewLimiter creates and returns a Limiter allowing qps creations per second with bursts of up to burst
+// creations. The creations aren't limited when qps isn't positive.
+func NewLimiter(qps float64, burst int, maxWait time.Duration) *Limiter {
+	limit := rate.Inf
+	if qps > 0 {
+		limit = rate.Limit(qps)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+
+	return &Limiter{
+		limiter: rate.NewLimiter(limit, burst),
+		maxWait: maxWait,
+	}
+}
+
+// SetPipelineRunCreationLimiter replaces the limiter used by WaitForPipelineRunCreation.
+func SetPipelineRunCreationLimiter(limiter *Limiter) {
+	pipelineRunCreationLimiter = limiter
+}
+
+// WaitForPipelineRunCreation waits until a PipelineRun may be created according to the limiter shared by all
+// controllers. ErrRateLimited is returned when the creation isn't allowed within the maximum wait.
+func WaitForPipelineRunCreation(ctx context.Context) error {
+	return pipelineRunCreationLimiter.Wait(ctx)
+}
+
+// Wait waits until a creation is allowed by the limiter. ErrRateLimited is returned without waiting when the
+// creation wouldn't be allowed within the maximum wait or the context is done first.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.limiter.Limit() == rate.Inf {
+		return nil
+	}
+
+	metrics.RegisterPipelineRunCreationQueued()
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, l.maxWait)
+	defer cancel()
+	err := l.limiter.Wait(waitCtx)
+	metrics.RegisterPipelineRunCreationDequeued(time.Since(start))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+
+	return nil
+}
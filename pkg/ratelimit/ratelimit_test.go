@@ -0,0 +1,80 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/metrics"
+	"github.com/konflux-ci/integration-service/pkg/ratelimit"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// observedWaits returns the number of waits observed by the wait time histogram.
+func observedWaits() uint64 {
+	metric := &dto.Metric{}
+	Expect(metrics.PipelineRunCreationWaitSeconds.Write(metric)).To(Succeed())
+	return metric.GetHistogram().GetSampleCount()
+}
+
+var _ = Describe("PipelineRun creation rate limiter", func() {
+
+	ctx := context.Background()
+
+	It("doesn't limit the creations without a rate", func() {
+		limiter := ratelimit.NewLimiter(0, 0, time.Millisecond)
+		for i := 0; i < 100; i++ {
+			Expect(limiter.Wait(ctx)).To(Succeed())
+		}
+	})
+
+	It("waits for a token of the bucket", func() {
+		waits := observedWaits()
+		limiter := ratelimit.NewLimiter(20, 1, time.Second)
+
+		start := time.Now()
+		Expect(limiter.Wait(ctx)).To(Succeed())
+		Expect(limiter.Wait(ctx)).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", 40*time.Millisecond))
+
+		Expect(testutil.ToFloat64(metrics.PipelineRunCreationQueueLength)).To(BeZero())
+		Expect(observedWaits()).To(Equal(waits + 2))
+	})
+
+	It("reports the creations which would wait longer than the maximum wait", func() {
+		limiter := ratelimit.NewLimiter(0.1, 1, 10*time.Millisecond)
+		Expect(limiter.Wait(ctx)).To(Succeed())
+
+		start := time.Now()
+		Expect(limiter.Wait(ctx)).To(MatchError(ratelimit.ErrRateLimited))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(testutil.ToFloat64(metrics.PipelineRunCreationQueueLength)).To(BeZero())
+	})
+
+	It("limits the creations through the shared limiter", func() {
+		ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(0.1, 1, 10*time.Millisecond))
+		defer ratelimit.SetPipelineRunCreationLimiter(ratelimit.NewLimiter(0, 0, ratelimit.DefaultMaxWait))
+
+		Expect(ratelimit.WaitForPipelineRunCreation(ctx)).To(Succeed())
+		Expect(ratelimit.WaitForPipelineRunCreation(ctx)).To(MatchError(ratelimit.ErrRateLimited))
+	})
+})
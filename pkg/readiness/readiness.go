@@ -0,0 +1,93 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// CacheSyncTimeout is the longest time the cache sync check waits for the informer caches to be synced.
+const CacheSyncTimeout = time.Second
+
+// CacheSyncer is the part of the manager cache reporting whether its informers have synced.
+type CacheSyncer interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// CredentialsChecker checks that the credentials needed to communicate with a git provider are available.
+type CredentialsChecker interface {
+	CheckCredentials(ctx context.Context) error
+}
+
+// NewCacheSyncChecker returns a readiness check failing until the informer caches of the manager have synced, so
+// the reconciliations of the replica aren't run against an incomplete view of the cluster.
+func NewCacheSyncChecker(cache CacheSyncer) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), CacheSyncTimeout)
+		defer cancel()
+		if !cache.WaitForCacheSync(ctx) {
+			return errors.New("the informer caches haven't synced yet")
+		}
+		return nil
+	}
+}
+
+// NewCertificateChecker returns a readiness check failing when the TLS certificate of a server can't be loaded or
+// isn't valid at the time of the check, e.g. since it expired without being rotated.
+func NewCertificateChecker(certFile, keyFile string) healthz.Checker {
+	return func(_ *http.Request) error {
+		return checkCertificate(certFile, keyFile, time.Now())
+	}
+}
+
+// NewCredentialsChecker returns a readiness check failing when the credentials of the git provider aren't available.
+func NewCredentialsChecker(checker CredentialsChecker) healthz.Checker {
+	return func(req *http.Request) error {
+		if err := checker.CheckCredentials(req.Context()); err != nil {
+			return fmt.Errorf("the git provider credentials aren't available: %w", err)
+		}
+		return nil
+	}
+}
+
+// checkCertificate loads the key pair from the given files and checks that its certificate is valid at the given time.
+func checkCertificate(certFile, keyFile string, now time.Time) error {
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the certificate %s: %w", certFile, err)
+	}
+	certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse the certificate %s: %w", certFile, err)
+	}
+	if now.Before(certificate.NotBefore) {
+		return fmt.Errorf("the certificate %s isn't valid before %s", certFile, certificate.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if now.After(certificate.NotAfter) {
+		return fmt.Errorf("the certificate %s expired at %s", certFile, certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
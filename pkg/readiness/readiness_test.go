@@ -0,0 +1,123 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/readiness"
+)
+
+type fakeCache struct {
+	synced bool
+}
+
+func (c *fakeCache) WaitForCacheSync(_ context.Context) bool {
+	return c.synced
+}
+
+type fakeCredentialsChecker struct {
+	err error
+}
+
+func (c *fakeCredentialsChecker) CheckCredentials(_ context.Context) error {
+	return c.err
+}
+
+// writeCertificate writes a self-signed certificate valid between the given times and its key to the directory,
+// returning the paths of the certificate and key files.
+func writeCertificate(dir string, notBefore, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "integration-service"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	Expect(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)).To(Succeed())
+	Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)).To(Succeed())
+	return certFile, keyFile
+}
+
+var _ = Describe("Readiness checks", func() {
+
+	request := httptest.NewRequest("GET", "/readyz", nil)
+
+	It("fails until the informer caches have synced", func() {
+		cache := &fakeCache{}
+		checker := readiness.NewCacheSyncChecker(cache)
+		Expect(checker(request)).To(MatchError("the informer caches haven't synced yet"))
+
+		cache.synced = true
+		Expect(checker(request)).To(Succeed())
+	})
+
+	It("succeeds when the certificate is valid", func() {
+		certFile, keyFile := writeCertificate(GinkgoT().TempDir(), time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		Expect(readiness.NewCertificateChecker(certFile, keyFile)(request)).To(Succeed())
+	})
+
+	It("fails when the certificate expired", func() {
+		certFile, keyFile := writeCertificate(GinkgoT().TempDir(), time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+		err := readiness.NewCertificateChecker(certFile, keyFile)(request)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("expired at"))
+	})
+
+	It("fails when the certificate isn't valid yet", func() {
+		certFile, keyFile := writeCertificate(GinkgoT().TempDir(), time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+		err := readiness.NewCertificateChecker(certFile, keyFile)(request)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("isn't valid before"))
+	})
+
+	It("fails when the certificate can't be loaded", func() {
+		dir := GinkgoT().TempDir()
+		err := readiness.NewCertificateChecker(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))(request)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to load the certificate"))
+	})
+
+	It("fails when the git provider credentials aren't available", func() {
+		Expect(readiness.NewCredentialsChecker(&fakeCredentialsChecker{})(request)).To(Succeed())
+
+		err := readiness.NewCredentialsChecker(&fakeCredentialsChecker{err: errors.New("secret not found")})(request)
+		Expect(err).To(MatchError("the git provider credentials aren't available: secret not found"))
+	})
+})
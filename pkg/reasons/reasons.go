@@ -0,0 +1,196 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reasons contains the reasons of the events emitted and of the status conditions set by the controllers,
+// so that they are shared across the controllers instead of being redefined by each of them.
+package reasons
+
+// EventReason is the reason of an event emitted by the controllers.
+type EventReason string
+
+// EventClass groups the event reasons, so that the emission of a whole class of events can be turned off.
+type EventClass string
+
+const (
+	// EventClassLifecycle is the class of the events tracking the progress of the resources, e.g. a started test.
+	EventClassLifecycle EventClass = "lifecycle"
+
+	// EventClassVerdict is the class of the events reporting the outcome of the integration tests of a Snapshot.
+	EventClassVerdict EventClass = "verdict"
+
+	// EventClassError is the class of the events reporting the resources which can't be processed, e.g. an invalid
+	// Snapshot.
+	EventClassError EventClass = "error"
+)
+
+// EventClasses are all the classes of the event reasons.
+var EventClasses = []EventClass{EventClassLifecycle, EventClassVerdict, EventClassError}
+
+// Events tracking the progress of the resources
+const (
+	// SnapshotCreatedEventReason is the reason of the event emitted when a Snapshot is created.
+	SnapshotCreatedEventReason EventReason = "SnapshotCreated"
+
+	// SnapshotTestStartedEventReason is the reason of the event emitted when an integration test is started for a Snapshot.
+	SnapshotTestStartedEventReason EventReason = "TestStarted"
+
+	// SnapshotRunStartedEventReason is the reason of the event emitted when the integration PipelineRuns of a
+	// SnapshotRun are created.
+	SnapshotRunStartedEventReason EventReason = "SnapshotRunStarted"
+
+	// SnapshotOverrideEventReason is the reason of the event emitted when the components of an override Snapshot
+	// are added to the global candidate list.
+	SnapshotOverrideEventReason EventReason = "Override"
+
+	// SnapshotSupersededEventReason is the reason of the event emitted when a Snapshot is superseded by a composite Snapshot.
+	SnapshotSupersededEventReason EventReason = "Superseded"
+
+	// SnapshotPendingReleaseWindowEventReason is the reason of the event emitted when a Snapshot passes outside of
+	// the release windows of its application and its auto-release is held until the next window opens.
+	SnapshotPendingReleaseWindowEventReason EventReason = "PendingReleaseWindow"
+
+	// SnapshotPromotedEventReason is the reason of the event emitted when a Snapshot is manually promoted to an Environment.
+	SnapshotPromotedEventReason EventReason = "Promoted"
+)
+
+// Events reporting the outcome of the integration tests of a Snapshot
+const (
+	// SnapshotPassedEventReason is the reason of the event emitted when a Snapshot passes all required integration tests.
+	SnapshotPassedEventReason EventReason = "Passed"
+
+	// SnapshotFailedEventReason is the reason of the event emitted when a Snapshot fails some required integration tests.
+	SnapshotFailedEventReason EventReason = "Failed"
+
+	// SnapshotTimedOutEventReason is the reason of the event emitted when a Snapshot is failed since its required
+	// integration tests didn't finish before its testing deadline.
+	SnapshotTimedOutEventReason EventReason = "TimedOut"
+
+	// SnapshotRevalidationFailedEventReason is the reason of the event emitted when a released Snapshot failed
+	// its re-run integration tests.
+	SnapshotRevalidationFailedEventReason EventReason = "SnapshotRevalidationFailed"
+)
+
+// Events reporting the resources which can't be processed
+const (
+	// SnapshotInvalidEventReason is the reason of the event emitted when a Snapshot is marked as invalid.
+	SnapshotInvalidEventReason EventReason = "Invalid"
+
+	// SnapshotOnboardingFailedEventReason is the reason of the event emitted when the new component of a Snapshot
+	// isn't added to the global candidate list since the Snapshot failed its integration tests.
+	SnapshotOnboardingFailedEventReason EventReason = "OnboardingFailed"
+
+	// SnapshotImageVerificationFailedEventReason is the reason of the event emitted when the signature or provenance
+	// attestation of the image built for the Snapshot can't be verified.
+	SnapshotImageVerificationFailedEventReason EventReason = "ImageVerificationFailed"
+
+	// PushedImageInvalidEventReason is the reason of the event emitted when no Snapshot can be created for the image
+	// pushed to the image repository of a Component, e.g. since it isn't referenced by digest.
+	PushedImageInvalidEventReason EventReason = "InvalidPushedImage"
+
+	// SnapshotRunInvalidEventReason is the reason of the event emitted when a SnapshotRun is marked as invalid.
+	SnapshotRunInvalidEventReason EventReason = "SnapshotRunInvalid"
+
+	// SnapshotPromotionFailedEventReason is the reason of the event emitted when the manual promotion of a Snapshot
+	// to an Environment is rejected, e.g. since the Snapshot didn't pass its integration tests.
+	SnapshotPromotionFailedEventReason EventReason = "PromotionFailed"
+
+	// ComponentNotFoundEventReason is the reason of the event recorded for build pipelineRuns whose Component doesn't exist anymore
+	ComponentNotFoundEventReason EventReason = "ComponentNotFound"
+
+	// BuildFailureReportFailedEventReason is the reason of the event recorded for failed build pipelineRuns whose
+	// failure couldn't be reported to the git provider.
+	BuildFailureReportFailedEventReason EventReason = "BuildFailureReportFailed"
+)
+
+// eventClasses maps the event reasons to their class.
+var eventClasses = map[EventReason]EventClass{
+	SnapshotCreatedEventReason:                 EventClassLifecycle,
+	SnapshotTestStartedEventReason:             EventClassLifecycle,
+	SnapshotRunStartedEventReason:              EventClassLifecycle,
+	SnapshotOverrideEventReason:                EventClassLifecycle,
+	SnapshotSupersededEventReason:              EventClassLifecycle,
+	SnapshotPendingReleaseWindowEventReason:    EventClassLifecycle,
+	SnapshotPromotedEventReason:                EventClassLifecycle,
+	SnapshotPassedEventReason:                  EventClassVerdict,
+	SnapshotFailedEventReason:                  EventClassVerdict,
+	SnapshotTimedOutEventReason:                EventClassVerdict,
+	SnapshotRevalidationFailedEventReason:      EventClassVerdict,
+	SnapshotInvalidEventReason:                 EventClassError,
+	SnapshotOnboardingFailedEventReason:        EventClassError,
+	SnapshotImageVerificationFailedEventReason: EventClassError,
+	PushedImageInvalidEventReason:              EventClassError,
+	SnapshotRunInvalidEventReason:              EventClassError,
+	SnapshotPromotionFailedEventReason:         EventClassError,
+	ComponentNotFoundEventReason:               EventClassError,
+	BuildFailureReportFailedEventReason:        EventClassError,
+}
+
+// Class returns the class of the event reason, or an empty class if the reason is unknown.
+func (r EventReason) Class() EventClass {
+	return eventClasses[r]
+}
+
+// Status condition reasons
+const (
+	// AppStudioTestSucceededConditionSatisfied is the reason that's set when the AppStudio tests succeed.
+	AppStudioTestSucceededConditionSatisfied = "Passed"
+
+	// AppStudioTestSucceededConditionFailed is the reason that's set when the AppStudio tests fail.
+	AppStudioTestSucceededConditionFailed = "Failed"
+
+	// AppStudioTestSucceededConditionTimedOut is the reason that's set when the AppStudio tests don't finish before
+	// the testing deadline of the Snapshot.
+	AppStudioTestSucceededConditionTimedOut = "TimedOut"
+
+	// AppStudioIntegrationStatusInvalid is the reason that's set when the AppStudio integration of the Snapshot, or
+	// the IntegrationTestScenario, gets into an invalid state.
+	AppStudioIntegrationStatusInvalid = "Invalid"
+
+	// AppStudioIntegrationStatusValid is the reason that's set when the IntegrationTestScenario gets into a valid state.
+	AppStudioIntegrationStatusValid = "Valid"
+
+	// AppStudioIntegrationStatusErrorOccured is the reason that's set when the AppStudio integration gets into an error state.
+	AppStudioIntegrationStatusErrorOccured = "ErrorOccured"
+
+	// AppStudioIntegrationStatusInProgress is the reason that's set when the AppStudio tests gets into an in progress state.
+	AppStudioIntegrationStatusInProgress = "InProgress"
+
+	// AppStudioIntegrationStatusFinished is the reason that's set when the AppStudio tests finish.
+	AppStudioIntegrationStatusFinished = "Finished"
+
+	// SnapshotAutoReleasedReason is the reason that's set when the Snapshot was auto-released.
+	SnapshotAutoReleasedReason = "AutoReleased"
+
+	// SnapshotAddedToGlobalCandidateListReason is the reason that's set when the components of the Snapshot were
+	// added to the global candidate list.
+	SnapshotAddedToGlobalCandidateListReason = "Added"
+
+	// SnapshotPassedPendingWindowReason is the reason that's set when the Snapshot passed outside of the release
+	// windows of its application and waits for the next window to open to be auto-released.
+	SnapshotPassedPendingWindowReason = "PassedPendingWindow"
+
+	// SnapshotRevalidationPassed is the reason that's set when the re-run integration tests of the released Snapshot pass.
+	SnapshotRevalidationPassed = "RevalidationPassed"
+
+	// SnapshotRevalidationFailed is the reason that's set when the re-run integration tests of the released Snapshot fail.
+	SnapshotRevalidationFailed = "RevalidationFailed"
+
+	// SnapshotImageVerificationPassed is the reason that's set when the built image is signed and attested.
+	SnapshotImageVerificationPassed = "VerificationPassed"
+
+	// SnapshotImageVerificationFailed is the reason that's set when the built image isn't signed or attested.
+	SnapshotImageVerificationFailed = "VerificationFailed"
+)
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reasons
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// AllEventClasses is the value of the list of event classes enabling all of them.
+const AllEventClasses = "all"
+
+// ParseEventClasses parses the given comma separated list of event classes, AllEventClasses enables all of them.
+func ParseEventClasses(value string) ([]EventClass, error) {
+	if strings.TrimSpace(value) == AllEventClasses {
+		return EventClasses, nil
+	}
+
+	classes := []EventClass{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		class := EventClass(item)
+		if !slices.Contains(EventClasses, class) {
+			return nil, fmt.Errorf("unknown event class %q, the classes are %v", item, EventClasses)
+		}
+		if !slices.Contains(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+
+	return classes, nil
+}
+
+// NewEventFilteringManager returns a manager whose event recorders only emit the events of the given classes.
+func NewEventFilteringManager(mgr manager.Manager, classes []EventClass) manager.Manager {
+	return &eventFilteringManager{Manager: mgr, classes: classes}
+}
+
+// NewEventFilteringRecorder returns a recorder only emitting the events of the given classes. The events whose
+// reason doesn't belong to any class are always emitted.
+func NewEventFilteringRecorder(recorder record.EventRecorder, classes []EventClass) record.EventRecorder {
+	return &eventFilteringRecorder{recorder: recorder, classes: classes}
+}
+
+type eventFilteringManager struct {
+	manager.Manager
+	classes []EventClass
+}
+
+// GetEventRecorderFor returns a recorder only emitting the events of the enabled classes.
+func (m *eventFilteringManager) GetEventRecorderFor(name string) record.EventRecorder {
+	return NewEventFilteringRecorder(m.Manager.GetEventRecorderFor(name), m.classes)
+}
+
+type eventFilteringRecorder struct {
+	recorder record.EventRecorder
+	classes  []EventClass
+}
+
+// isEnabled returns true if the events with the given reason are emitted.
+func (r *eventFilteringRecorder) isEnabled(reason string) bool {
+	class := EventReason(reason).Class()
+	return class == "" || slices.Contains(r.classes, class)
+}
+
+func (r *eventFilteringRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.isEnabled(reason) {
+		r.recorder.Event(object, eventtype, reason, message)
+	}
+}
+
+func (r *eventFilteringRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.isEnabled(reason) {
+		r.recorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	}
+}
+
+func (r *eventFilteringRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.isEnabled(reason) {
+		r.recorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reasons_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Event classes", func() {
+
+	DescribeTable("parses the list of event classes",
+		func(value string, expected []reasons.EventClass) {
+			classes, err := reasons.ParseEventClasses(value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(classes).To(Equal(expected))
+		},
+		Entry("all classes", reasons.AllEventClasses, reasons.EventClasses),
+		Entry("some classes", "verdict, error,verdict", []reasons.EventClass{reasons.EventClassVerdict, reasons.EventClassError}),
+		Entry("no class", "", []reasons.EventClass{}),
+	)
+
+	It("fails to parse an unknown event class", func() {
+		_, err := reasons.ParseEventClasses("verdict,unknown")
+		Expect(err).To(MatchError(ContainSubstring(`unknown event class "unknown"`)))
+	})
+
+	It("classifies the event reasons", func() {
+		Expect(reasons.SnapshotTestStartedEventReason.Class()).To(Equal(reasons.EventClassLifecycle))
+		Expect(reasons.SnapshotFailedEventReason.Class()).To(Equal(reasons.EventClassVerdict))
+		Expect(reasons.SnapshotInvalidEventReason.Class()).To(Equal(reasons.EventClassError))
+		Expect(reasons.EventReason("Unknown").Class()).To(BeEmpty())
+	})
+
+	It("emits only the events of the enabled classes", func() {
+		fakeRecorder := record.NewFakeRecorder(10)
+		recorder := reasons.NewEventFilteringRecorder(fakeRecorder, []reasons.EventClass{reasons.EventClassVerdict})
+		snapshot := &applicationapiv1alpha1.Snapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-sample", Namespace: "default"}}
+
+		recorder.Eventf(snapshot, corev1.EventTypeNormal, string(reasons.SnapshotTestStartedEventReason), "Started integration test for scenario %s", "example")
+		recorder.Event(snapshot, corev1.EventTypeWarning, string(reasons.SnapshotInvalidEventReason), "invalid")
+		recorder.Event(snapshot, corev1.EventTypeNormal, string(reasons.SnapshotPassedEventReason), "passed")
+		recorder.AnnotatedEventf(snapshot, nil, corev1.EventTypeWarning, string(reasons.SnapshotFailedEventReason), "failed")
+		recorder.Event(snapshot, corev1.EventTypeNormal, "Unknown", "unclassified")
+
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal Passed passed")))
+		Expect(fakeRecorder.Events).To(Receive(Equal("Warning Failed failed")))
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal Unknown unclassified")))
+		Expect(fakeRecorder.Events).NotTo(Receive())
+	})
+})
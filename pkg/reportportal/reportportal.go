@@ -0,0 +1,294 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reportportal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SecretName is the name of the Secret configuring the ReportPortal instance the results of the integration
+	// tests in its namespace are exported to. Exporting is disabled in namespaces without it.
+	SecretName = "integration-service-reportportal"
+
+	// SecretURLKey is the key of the Secret holding the address of the ReportPortal instance.
+	SecretURLKey = "url"
+
+	// SecretProjectKey is the key of the Secret holding the ReportPortal project the launches are created in.
+	SecretProjectKey = "project"
+
+	// SecretTokenKey is the key of the Secret holding the ReportPortal API token.
+	SecretTokenKey = "token"
+
+	// LaunchAnnotation is the annotation added to the integration PipelineRuns exported to ReportPortal,
+	// holding the UUID of the created launch. It's added once the launch was started, before its items are
+	// exported, so that the PipelineRun isn't exported into a second launch when the export is retried.
+	LaunchAnnotation = "test.appstudio.openshift.io/reportportal-launch"
+
+	// StatusPassed is the ReportPortal status of a passed test item.
+	StatusPassed = "PASSED"
+
+	// StatusFailed is the ReportPortal status of a failed test item.
+	StatusFailed = "FAILED"
+
+	// StatusSkipped is the ReportPortal status of a skipped test item.
+	StatusSkipped = "SKIPPED"
+
+	// StatusStopped is the ReportPortal status of a launch whose items couldn't all be exported.
+	StatusStopped = "STOPPED"
+
+	// ItemTypeSuite is the ReportPortal type of a test item grouping other items.
+	ItemTypeSuite = "SUITE"
+
+	// ItemTypeStep is the ReportPortal type of a single test item.
+	ItemTypeStep = "STEP"
+)
+
+// Launch is a single run of tests exported to ReportPortal.
+type Launch struct {
+	Name        string
+	Description string
+	StartTime   time.Time
+	EndTime     time.Time
+	Attributes  map[string]string
+	Items       []Item
+}
+
+// Item is a test item of a Launch, items of the ItemTypeSuite type group their children.
+type Item struct {
+	Name        string
+	Description string
+	Type        string
+	Status      string
+	StartTime   time.Time
+	EndTime     time.Time
+	Children    []Item
+}
+
+// Client exports launches to a project of a ReportPortal instance.
+type Client struct {
+	address    string
+	project    string
+	token      string
+	httpClient *http.Client
+}
+
+// attribute is the ReportPortal representation of a launch attribute.
+type attribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// startRequest is the body of the requests starting a launch or a test item.
+type startRequest struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	StartTime   int64       `json:"startTime"`
+	Type        string      `json:"type,omitempty"`
+	LaunchUUID  string      `json:"launchUuid,omitempty"`
+	Attributes  []attribute `json:"attributes,omitempty"`
+}
+
+// finishRequest is the body of the requests finishing a launch or a test item.
+type finishRequest struct {
+	EndTime    int64  `json:"endTime"`
+	Status     string `json:"status,omitempty"`
+	LaunchUUID string `json:"launchUuid,omitempty"`
+}
+
+// startResponse is the ReportPortal response to a start request.
+type startResponse struct {
+	ID string `json:"id"`
+}
+
+// NewClient creates and returns a Client for the given ReportPortal address, project and API token.
+func NewClient(address, project, token string) *Client {
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		project:    project,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewClientFromSecret creates and returns a Client configured through the given SecretName Secret.
+func NewClientFromSecret(secret *corev1.Secret) (*Client, error) {
+	values := map[string]string{}
+	for _, key := range []string{SecretURLKey, SecretProjectKey, SecretTokenKey} {
+		value := strings.TrimSpace(string(secret.Data[key]))
+		if value == "" {
+			return nil, fmt.Errorf("the ReportPortal secret %s/%s doesn't contain the %s key", secret.Namespace, secret.Name, key)
+		}
+		values[key] = value
+	}
+
+	return NewClient(values[SecretURLKey], values[SecretProjectKey], values[SecretTokenKey]), nil
+}
+
+// ExportLaunch starts the launch, reports all of its items and finishes it.
+// The UUID of the created launch is returned.
+func (c *Client) ExportLaunch(ctx context.Context, launch *Launch) (string, error) {
+	launchUUID, err := c.StartLaunch(ctx, launch)
+	if err != nil {
+		return "", err
+	}
+	if err := c.FinishLaunch(ctx, launchUUID, launch); err != nil {
+		return "", err
+	}
+	return launchUUID, nil
+}
+
+// StartLaunch starts the launch without any of its items and returns the UUID of the created launch.
+func (c *Client) StartLaunch(ctx context.Context, launch *Launch) (string, error) {
+	keys := make([]string, 0, len(launch.Attributes))
+	for key := range launch.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attributes := []attribute{}
+	for _, key := range keys {
+		attributes = append(attributes, attribute{Key: key, Value: launch.Attributes[key]})
+	}
+
+	started := &startResponse{}
+	err := c.do(ctx, http.MethodPost, "launch", &startRequest{
+		Name:        launch.Name,
+		Description: launch.Description,
+		StartTime:   launch.StartTime.UnixMilli(),
+		Attributes:  attributes,
+	}, started)
+	if err != nil {
+		return "", fmt.Errorf("failed to start the ReportPortal launch %s: %w", launch.Name, err)
+	}
+
+	return started.ID, nil
+}
+
+// FinishLaunch reports all the items of the launch started with the given UUID and finishes it. When an item
+// can't be reported, the launch is finished with the StatusStopped status instead, so that it isn't left in
+// progress, and the error is returned.
+func (c *Client) FinishLaunch(ctx context.Context, launchUUID string, launch *Launch) error {
+	for _, item := range launch.Items {
+		if err := c.exportItem(ctx, launchUUID, "", &item); err != nil {
+			if stopErr := c.StopLaunch(ctx, launchUUID, launch); stopErr != nil {
+				return fmt.Errorf("%w, and failed to stop the ReportPortal launch %s: %w", err, launch.Name, stopErr)
+			}
+			return err
+		}
+	}
+
+	if err := c.finishLaunch(ctx, launchUUID, launch, ""); err != nil {
+		return fmt.Errorf("failed to finish the ReportPortal launch %s: %w", launch.Name, err)
+	}
+	return nil
+}
+
+// StopLaunch finishes the launch started with the given UUID with the StatusStopped status, without reporting
+// any more of its items.
+func (c *Client) StopLaunch(ctx context.Context, launchUUID string, launch *Launch) error {
+	return c.finishLaunch(ctx, launchUUID, launch, StatusStopped)
+}
+
+// finishLaunch finishes the launch with the given UUID, with the given status unless it's empty, in which case
+// ReportPortal computes it from the items of the launch.
+func (c *Client) finishLaunch(ctx context.Context, launchUUID string, launch *Launch, status string) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("launch/%s/finish", url.PathEscape(launchUUID)), &finishRequest{
+		EndTime: launch.EndTime.UnixMilli(),
+		Status:  status,
+	}, nil)
+}
+
+// exportItem starts the test item under the given parent, reports its children and finishes it.
+func (c *Client) exportItem(ctx context.Context, launchUUID, parentID string, item *Item) error {
+	path := "item"
+	if parentID != "" {
+		path = fmt.Sprintf("item/%s", url.PathEscape(parentID))
+	}
+
+	started := &startResponse{}
+	err := c.do(ctx, http.MethodPost, path, &startRequest{
+		Name:        item.Name,
+		Description: item.Description,
+		StartTime:   item.StartTime.UnixMilli(),
+		Type:        item.Type,
+		LaunchUUID:  launchUUID,
+	}, started)
+	if err != nil {
+		return fmt.Errorf("failed to start the ReportPortal test item %s: %w", item.Name, err)
+	}
+
+	for _, child := range item.Children {
+		if err := c.exportItem(ctx, launchUUID, started.ID, &child); err != nil {
+			return err
+		}
+	}
+
+	err = c.do(ctx, http.MethodPut, fmt.Sprintf("item/%s", url.PathEscape(started.ID)), &finishRequest{
+		EndTime:    item.EndTime.UnixMilli(),
+		Status:     item.Status,
+		LaunchUUID: launchUUID,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to finish the ReportPortal test item %s: %w", item.Name, err)
+	}
+
+	return nil
+}
+
+// do sends the JSON encoded request body to the given path of the project API and decodes the response into
+// the given destination, if there is one.
+func (c *Client) do(ctx context.Context, method, path string, body, response any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	requestURL := fmt.Sprintf("%s/api/v1/%s/%s", c.address, url.PathEscape(c.project), path)
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %q from %s %s", resp.Status, method, requestURL)
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode the ReportPortal response: %w", err)
+	}
+	return nil
+}
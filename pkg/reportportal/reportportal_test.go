@@ -0,0 +1,161 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reportportal_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/pkg/reportportal"
+)
+
+var _ = Describe("ReportPortal client", func() {
+
+	var (
+		server   *httptest.Server
+		requests []string
+		bodies   []map[string]any
+		failOn   string
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		bodies = []map[string]any{}
+		failOn = ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer rp-token"))
+			request := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+			requests = append(requests, request)
+			body := map[string]any{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			bodies = append(bodies, body)
+			if request == failOn {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if r.Method == http.MethodPost {
+				Expect(json.NewEncoder(w).Encode(map[string]string{"id": fmt.Sprintf("uuid-%d", len(requests))})).To(Succeed())
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]string{"message": "finished"})).To(Succeed())
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("can be configured through a secret", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: reportportal.SecretName, Namespace: "default"},
+			Data: map[string][]byte{
+				reportportal.SecretURLKey:     []byte(server.URL),
+				reportportal.SecretProjectKey: []byte("integration"),
+			},
+		}
+		_, err := reportportal.NewClientFromSecret(secret)
+		Expect(err).To(MatchError(ContainSubstring("doesn't contain the token key")))
+
+		secret.Data[reportportal.SecretTokenKey] = []byte("rp-token\n")
+		client, err := reportportal.NewClientFromSecret(secret)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.ExportLaunch(context.Background(), &reportportal.Launch{Name: "scenario"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("exports the launch with its nested items", func() {
+		now := time.Now()
+		client := reportportal.NewClient(server.URL+"/", "integration", "rp-token")
+		launchUUID, err := client.ExportLaunch(context.Background(), &reportportal.Launch{
+			Name:       "scenario",
+			StartTime:  now,
+			EndTime:    now.Add(time.Minute),
+			Attributes: map[string]string{"snapshot": "snapshot-sample", "application": "application-sample"},
+			Items: []reportportal.Item{
+				{
+					Name:   "pipelinerun-sample",
+					Type:   reportportal.ItemTypeSuite,
+					Status: reportportal.StatusFailed,
+					Children: []reportportal.Item{
+						{Name: "task1", Type: reportportal.ItemTypeStep, Status: reportportal.StatusPassed},
+						{Name: "task2", Type: reportportal.ItemTypeStep, Status: reportportal.StatusFailed},
+					},
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(launchUUID).To(Equal("uuid-1"))
+		Expect(requests).To(Equal([]string{
+			"POST /api/v1/integration/launch",
+			"POST /api/v1/integration/item",
+			"POST /api/v1/integration/item/uuid-2",
+			"PUT /api/v1/integration/item/uuid-3",
+			"POST /api/v1/integration/item/uuid-2",
+			"PUT /api/v1/integration/item/uuid-5",
+			"PUT /api/v1/integration/item/uuid-2",
+			"PUT /api/v1/integration/launch/uuid-1/finish",
+		}))
+		Expect(bodies[0]["startTime"]).To(BeEquivalentTo(now.UnixMilli()))
+		Expect(bodies[0]["attributes"]).To(Equal([]any{
+			map[string]any{"key": "application", "value": "application-sample"},
+			map[string]any{"key": "snapshot", "value": "snapshot-sample"},
+		}))
+		Expect(bodies[1]["launchUuid"]).To(Equal("uuid-1"))
+		Expect(bodies[5]["status"]).To(Equal(reportportal.StatusFailed))
+		Expect(bodies[7]["endTime"]).To(BeEquivalentTo(now.Add(time.Minute).UnixMilli()))
+	})
+
+	It("returns an error when ReportPortal rejects a request", func() {
+		failOn = "POST /api/v1/integration/item"
+		client := reportportal.NewClient(server.URL, "integration", "rp-token")
+		_, err := client.ExportLaunch(context.Background(), &reportportal.Launch{
+			Name:  "scenario",
+			Items: []reportportal.Item{{Name: "pipelinerun-sample", Type: reportportal.ItemTypeSuite}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("failed to start the ReportPortal test item pipelinerun-sample")))
+	})
+
+	It("stops the started launch when its items can't be exported", func() {
+		failOn = "POST /api/v1/integration/item"
+		client := reportportal.NewClient(server.URL, "integration", "rp-token")
+		launch := &reportportal.Launch{
+			Name:  "scenario",
+			Items: []reportportal.Item{{Name: "pipelinerun-sample", Type: reportportal.ItemTypeSuite}},
+		}
+		launchUUID, err := client.StartLaunch(context.Background(), launch)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(launchUUID).To(Equal("uuid-1"))
+
+		err = client.FinishLaunch(context.Background(), launchUUID, launch)
+		Expect(err).To(MatchError(ContainSubstring("failed to start the ReportPortal test item pipelinerun-sample")))
+		Expect(requests).To(Equal([]string{
+			"POST /api/v1/integration/launch",
+			"POST /api/v1/integration/item",
+			"PUT /api/v1/integration/launch/uuid-1/finish",
+		}))
+		Expect(bodies[2]["status"]).To(Equal(reportportal.StatusStopped))
+	})
+})
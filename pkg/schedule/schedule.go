@@ -0,0 +1,223 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule parses the cron expressions scheduling the periodic runs of the integration tests and computes
+// the times of their next runs.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears bounds the search of the next run of a schedule, so the schedules which never match any time,
+// e.g. "0 0 30 2 *", don't loop forever.
+const maxSearchYears = 5
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// field describes the range and the names of the values of a field of a cron expression.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: monthNames}
+	// the day of week 7 is accepted as Sunday, like most cron implementations do
+	dowField = field{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Schedule is a parsed cron expression, its times are evaluated in its location, UTC unless parsed in another one.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	location *time.Location
+
+	// domRestricted and dowRestricted are set when the day of month or the day of week aren't "*", in which case
+	// a day matches the schedule when it matches any of them, following the cron semantics
+	domRestricted, dowRestricted bool
+}
+
+// Parse parses the standard five fields cron expression "minute hour day-of-month month day-of-week" or any of
+// the @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly macros. The fields accept "*", values,
+// ranges ("1-5"), steps ("*/15", "0-30/10"), lists ("1,15") and the three letters names of the months and days.
+func Parse(spec string) (*Schedule, error) {
+	return ParseInLocation(spec, time.UTC)
+}
+
+// ParseInLocation parses the cron expression like Parse, the times of the returned Schedule are evaluated in the
+// given location, e.g. the time zone a release window opens in.
+func ParseInLocation(spec string, location *time.Location) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in the cron expression %q, found %d", spec, len(fields))
+	}
+
+	schedule := &Schedule{
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+		location:      location,
+	}
+	var err error
+	if schedule.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	return schedule, nil
+}
+
+// parseField returns the bitset of the values of the field of a cron expression.
+func parseField(expression string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expression, ",") {
+		rangeExpression, stepExpression, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpression)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q of the %s field", stepExpression, f.name)
+			}
+		}
+
+		start, end := f.min, f.max
+		if rangeExpression != "*" {
+			startExpression, endExpression, isRange := strings.Cut(rangeExpression, "-")
+			var err error
+			if start, err = parseValue(startExpression, f); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(endExpression, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = f.max
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q of the %s field", rangeExpression, f.name)
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseValue parses a single value, or the name of a value, of the field of a cron expression.
+func parseValue(expression string, f field) (int, error) {
+	if value, ok := f.names[strings.ToLower(expression)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expression)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q of the %s field", expression, f.name)
+	}
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("the value %d of the %s field is out of the range %d-%d", value, f.name, f.min, f.max)
+	}
+
+	return value, nil
+}
+
+// Next returns the first time matching the schedule after the given time, or the zero time when no time matches
+// the schedule in the next years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns true if the day of the given time matches the day of month and the day of week of the schedule.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatches := s.dom&(1<<uint(t.Day())) != 0
+	dowMatches := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatches || dowMatches
+	}
+
+	return domMatches && dowMatches
+}
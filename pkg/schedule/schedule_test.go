@@ -0,0 +1,79 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/schedule"
+)
+
+var _ = Describe("Schedule", func() {
+
+	// Monday, 14th of October 2024
+	now := time.Date(2024, 10, 14, 10, 30, 45, 0, time.UTC)
+
+	DescribeTable("computes the next run of the schedule",
+		func(spec string, expected time.Time) {
+			s, err := schedule.Parse(spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Next(now)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", time.Date(2024, 10, 14, 10, 31, 0, 0, time.UTC)),
+		Entry("every 15 minutes", "*/15 * * * *", time.Date(2024, 10, 14, 10, 45, 0, 0, time.UTC)),
+		Entry("nightly", "0 2 * * *", time.Date(2024, 10, 15, 2, 0, 0, 0, time.UTC)),
+		Entry("the daily macro", "@daily", time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)),
+		Entry("the weekdays", "30 22 * * mon-fri", time.Date(2024, 10, 14, 22, 30, 0, 0, time.UTC)),
+		Entry("Sundays as day 7", "0 0 * * 7", time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC)),
+		Entry("a list of hours", "0 6,18 * * *", time.Date(2024, 10, 14, 18, 0, 0, 0, time.UTC)),
+		Entry("the next month", "0 0 1 * *", time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("the next year", "0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("either the day of month or the day of week", "0 0 20 * fri", time.Date(2024, 10, 18, 0, 0, 0, 0, time.UTC)),
+		Entry("a leap day", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)),
+	)
+
+	It("computes the next run of the schedule in its location", func() {
+		location, err := time.LoadLocation("Europe/Prague")
+		Expect(err).NotTo(HaveOccurred())
+		s, err := schedule.ParseInLocation("0 9 * * *", location)
+		Expect(err).NotTo(HaveOccurred())
+		// 9:00 in Prague is 7:00 UTC in the summer time
+		Expect(s.Next(now).UTC()).To(Equal(time.Date(2024, 10, 15, 7, 0, 0, 0, time.UTC)))
+		Expect(s.Next(now).Location()).To(Equal(location))
+	})
+
+	It("returns the zero time for the schedules which never match", func() {
+		s, err := schedule.Parse("0 0 30 2 *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Next(now).IsZero()).To(BeTrue())
+	})
+
+	DescribeTable("rejects the invalid cron expressions",
+		func(spec string, message string) {
+			_, err := schedule.Parse(spec)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("missing fields", "0 2 * *", "expected 5 fields"),
+		Entry("out of range values", "0 24 * * *", "the value 24 of the hour field is out of the range 0-23"),
+		Entry("invalid values", "0 2 * foo *", "invalid value \"foo\" of the month field"),
+		Entry("invalid steps", "*/0 * * * *", "invalid step \"0\" of the minute field"),
+		Entry("invalid ranges", "0 2 10-5 * *", "invalid range \"10-5\" of the day of month field"),
+	)
+})
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	sigstore "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/tuf"
+)
+
+const (
+	// PublicKeyFileEnvVar is the environment variable holding the path of the PEM encoded cosign public key the
+	// signatures and attestations of the built images are verified with. Image verification is disabled when it's
+	// not set.
+	PublicKeyFileEnvVar = "COSIGN_PUBLIC_KEY_FILE"
+
+	// RekorPublicKeyFileEnvVar is the environment variable holding the path of the PEM encoded public key of the
+	// Rekor transparency log. When it's set, the signatures and attestations are only trusted when they were
+	// recorded in the transparency log.
+	RekorPublicKeyFileEnvVar = "COSIGN_REKOR_PUBLIC_KEY_FILE"
+
+	// SLSAProvenancePredicateTypePrefix is the prefix of the in-toto predicate types of the SLSA provenance attestations.
+	SLSAProvenancePredicateTypePrefix = "https://slsa.dev/provenance/"
+
+	// verificationTimeout is the maximum duration of the registry requests made to verify an image.
+	verificationTimeout = 30 * time.Second
+)
+
+// ErrVerificationFailed is returned when the image isn't signed or hasn't a provenance attestation signed with
+// the configured key. The other errors returned by the Verifier are caused by the registry not being reachable.
+var ErrVerificationFailed = errors.New("image verification failed")
+
+// IsVerificationFailed returns true if the error was returned as the image isn't signed or attested with the key.
+func IsVerificationFailed(err error) bool {
+	return errors.Is(err, ErrVerificationFailed)
+}
+
+// Verifier verifies with cosign that the images are signed and have a SLSA provenance attestation, as pushed by
+// cosign or Tekton Chains next to the images, signed with the configured public key. The Verifier is safe for
+// concurrent use.
+type Verifier struct {
+	sigVerifier   sigstore.Verifier
+	rekorPubKeys  *cosign.TrustedTransparencyLogPubKeys
+	remoteOptions []remote.Option
+}
+
+// NewVerifierFromEnv creates and returns a Verifier configured through the COSIGN_PUBLIC_KEY_FILE and
+// COSIGN_REKOR_PUBLIC_KEY_FILE environment variables. The registries are accessed with the credentials of the
+// default keychain, which reads the docker config file or the file set in REGISTRY_AUTH_FILE. If the public key
+// isn't configured, nil is returned. It's meant to be called once when the operator starts.
+func NewVerifierFromEnv() (*Verifier, error) {
+	keyFile := os.Getenv(PublicKeyFileEnvVar)
+	if keyFile == "" {
+		return nil, nil
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cosign public key: %w", err)
+	}
+
+	verifier, err := NewVerifier(key, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, err
+	}
+	if rekorKeyFile := os.Getenv(RekorPublicKeyFileEnvVar); rekorKeyFile != "" {
+		rekorKey, err := os.ReadFile(rekorKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Rekor public key: %w", err)
+		}
+		return verifier.WithTransparencyLog(rekorKey)
+	}
+
+	return verifier, nil
+}
+
+// NewVerifier creates and returns a Verifier verifying the images with the given PEM encoded public key. The
+// registries are accessed with the given options, e.g. to set the credentials or the transport.
+func NewVerifier(publicKey []byte, options ...remote.Option) (*Verifier, error) {
+	key, err := cryptoutils.UnmarshalPEMToPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the cosign public key: %w", err)
+	}
+	sigVerifier, err := sigstore.LoadVerifier(key, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the cosign public key: %w", err)
+	}
+
+	return &Verifier{
+		sigVerifier:   sigVerifier,
+		remoteOptions: options,
+	}, nil
+}
+
+// WithTransparencyLog makes the Verifier trust only the signatures and attestations carrying the bundle of an
+// entry of the transparency log with the given PEM encoded public key, and returns it. The bundles are verified
+// offline, and the ones of the other transparency logs are rejected.
+func (v *Verifier) WithTransparencyLog(rekorPublicKey []byte) (*Verifier, error) {
+	rekorPubKeys := cosign.NewTrustedTransparencyLogPubKeys()
+	if err := rekorPubKeys.AddTransparencyLogPubKey(rekorPublicKey, tuf.Active); err != nil {
+		return nil, fmt.Errorf("failed to parse the Rekor public key: %w", err)
+	}
+	v.rekorPubKeys = &rekorPubKeys
+
+	return v, nil
+}
+
+// Verify checks that the image referenced by digest is signed and has a SLSA provenance attestation signed with
+// the public key of the Verifier. ErrVerificationFailed is wrapped by the returned error if it isn't the case.
+func (v *Verifier) Verify(ctx context.Context, image string) error {
+	reference, err := name.NewDigest(image)
+	if err != nil {
+		return fmt.Errorf("%w: %s isn't referenced by a valid digest: %w", ErrVerificationFailed, image, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, verificationTimeout)
+	defer cancel()
+
+	_, _, err = cosign.VerifyImageSignatures(ctx, reference, v.checkOpts(ctx, cosign.SimpleClaimVerifier))
+	if isNoMatchError(err) {
+		return fmt.Errorf("%w: %s has no signature made with the trusted key: %w", ErrVerificationFailed, image, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _, err = cosign.VerifyImageAttestations(ctx, reference, v.checkOpts(ctx, provenanceClaimVerifier))
+	if isNoMatchError(err) {
+		return fmt.Errorf("%w: %s has no provenance attestation signed with the trusted key: %w", ErrVerificationFailed, image, err)
+	}
+
+	return err
+}
+
+// checkOpts returns the cosign options verifying the signatures or attestations with the key of the Verifier and
+// the given claim verifier, through the transparency log if it's configured.
+func (v *Verifier) checkOpts(ctx context.Context, claimVerifier func(oci.Signature, v1.Hash, map[string]interface{}) error) *cosign.CheckOpts {
+	return &cosign.CheckOpts{
+		RegistryClientOpts: []ociremote.Option{
+			ociremote.WithRemoteOptions(append([]remote.Option{remote.WithContext(ctx)}, v.remoteOptions...)...),
+		},
+		SigVerifier:   v.sigVerifier,
+		ClaimVerifier: claimVerifier,
+		RekorPubKeys:  v.rekorPubKeys,
+		IgnoreTlog:    v.rekorPubKeys == nil,
+		Offline:       true,
+	}
+}
+
+// isNoMatchError returns true if cosign found no signature or attestation verified by the key of the Verifier.
+func isNoMatchError(err error) bool {
+	var noSignatures *cosign.ErrNoSignaturesFound
+	var noMatchingSignatures *cosign.ErrNoMatchingSignatures
+	var noMatchingAttestations *cosign.ErrNoMatchingAttestations
+
+	return errors.As(err, &noSignatures) || errors.As(err, &noMatchingSignatures) || errors.As(err, &noMatchingAttestations)
+}
+
+// provenanceClaimVerifier verifies that the attestation is a SLSA provenance of the image digest.
+func provenanceClaimVerifier(attestation oci.Signature, imageDigest v1.Hash, annotations map[string]interface{}) error {
+	if err := cosign.IntotoSubjectClaimVerifier(attestation, imageDigest, annotations); err != nil {
+		return err
+	}
+
+	payload, err := attestation.Payload()
+	if err != nil {
+		return err
+	}
+	envelope := struct {
+		Payload string `json:"payload"`
+	}{}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return err
+	}
+	content, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return err
+	}
+	statement := struct {
+		PredicateType string `json:"predicateType"`
+	}{}
+	if err := json.Unmarshal(content, &statement); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(statement.PredicateType, SLSAProvenancePredicateTypePrefix) {
+		return fmt.Errorf("the predicate type %s isn't a SLSA provenance", statement.PredicateType)
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signature_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/empty"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	sigstore "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/dsse"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/signature"
+)
+
+// failingRegistry serves an in-memory registry, or fails all the requests when failing is set.
+type failingRegistry struct {
+	registry http.Handler
+	failing  bool
+}
+
+func (f *failingRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	f.registry.ServeHTTP(w, r)
+}
+
+var _ = Describe("Verifier", func() {
+
+	const imageDigest = "sha256:841328df1b9f8c4087adbdcfec6cc99ac8308805dea83f6d415d6fb8d40227c1"
+
+	var (
+		key      *ecdsa.PrivateKey
+		handler  *failingRegistry
+		server   *httptest.Server
+		verifier *signature.Verifier
+		image    name.Digest
+	)
+
+	encodePublicKey := func(key *ecdsa.PrivateKey) []byte {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	signer := func(key *ecdsa.PrivateKey) sigstore.SignerVerifier {
+		signer, err := sigstore.LoadECDSASignerVerifier(key, crypto.SHA256)
+		Expect(err).NotTo(HaveOccurred())
+		return signer
+	}
+
+	sha256Hex := func(content []byte) string {
+		hash := sha256.Sum256(content)
+		return hex.EncodeToString(hash[:])
+	}
+
+	// newBundle returns the bundle of a transparency log entry with the given body, signed with the Rekor key and
+	// claiming to be recorded in the transparency log of the given public key
+	newBundle := func(rekorKey *ecdsa.PrivateKey, logPublicKey *ecdsa.PublicKey, body string) *bundle.RekorBundle {
+		logID, err := cosign.GetTransparencyLogID(logPublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		rekorPayload := bundle.RekorPayload{
+			Body:           base64.StdEncoding.EncodeToString([]byte(body)),
+			IntegratedTime: 1700000000,
+			LogIndex:       1,
+			LogID:          logID,
+		}
+		content, err := json.Marshal(rekorPayload)
+		Expect(err).NotTo(HaveOccurred())
+		canonicalized, err := jsoncanonicalizer.Transform(content)
+		Expect(err).NotTo(HaveOccurred())
+		set, err := signer(rekorKey).SignMessage(bytes.NewReader(canonicalized))
+		Expect(err).NotTo(HaveOccurred())
+		return &bundle.RekorBundle{SignedEntryTimestamp: set, Payload: rekorPayload}
+	}
+
+	writeSignatures := func(tag name.Tag, signatures ...oci.Signature) {
+		image, err := mutate.AppendSignatures(empty.Signatures(), false, signatures...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remote.Write(tag, image)).To(Succeed())
+	}
+
+	addSignature := func(key *ecdsa.PrivateKey, rekorKey *ecdsa.PrivateKey) {
+		content, err := (&payload.Cosign{Image: image}).MarshalJSON()
+		Expect(err).NotTo(HaveOccurred())
+		sig, err := signer(key).SignMessage(bytes.NewReader(content))
+		Expect(err).NotTo(HaveOccurred())
+		encodedSig := base64.StdEncoding.EncodeToString(sig)
+
+		options := []static.Option{}
+		if rekorKey != nil {
+			options = append(options, static.WithBundle(newBundle(rekorKey, &rekorKey.PublicKey, fmt.Sprintf(
+				`{"apiVersion":"0.0.1","kind":"hashedrekord","spec":{"data":{"hash":{"algorithm":"sha256","value":%q}},"signature":{"content":%q,"publicKey":{"content":%q}}}}`,
+				sha256Hex(content), encodedSig, base64.StdEncoding.EncodeToString(encodePublicKey(key))))))
+		}
+		signature, err := static.NewSignature(content, encodedSig, options...)
+		Expect(err).NotTo(HaveOccurred())
+		tag, err := ociremote.SignatureTag(image)
+		Expect(err).NotTo(HaveOccurred())
+		writeSignatures(tag, signature)
+	}
+
+	// addAttestation adds an attestation of the image with the given predicate type, recorded in the transparency
+	// log with the logKey public key when the Rekor key is given
+	addAttestation := func(key *ecdsa.PrivateKey, predicateType string, rekorKey *ecdsa.PrivateKey, logKey *ecdsa.PublicKey) {
+		statement := []byte(fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,"subject":[{"name":"sample/image","digest":{"sha256":%q}}],"predicate":{}}`,
+			predicateType, image.DigestStr()[len("sha256:"):]))
+		envelope, err := dsse.WrapSigner(signer(key), "application/vnd.in-toto+json").SignMessage(bytes.NewReader(statement))
+		Expect(err).NotTo(HaveOccurred())
+
+		options := []static.Option{}
+		if rekorKey != nil {
+			options = append(options, static.WithBundle(newBundle(rekorKey, logKey, fmt.Sprintf(
+				`{"apiVersion":"0.0.1","kind":"intoto","spec":{"content":{"hash":{"algorithm":"sha256","value":%q},"payloadHash":{"algorithm":"sha256","value":%q}},"publicKey":%q}}`,
+				sha256Hex(envelope), sha256Hex(statement), base64.StdEncoding.EncodeToString(encodePublicKey(key))))))
+		}
+		attestation, err := static.NewAttestation(envelope, options...)
+		Expect(err).NotTo(HaveOccurred())
+		tag, err := ociremote.AttestationTag(image)
+		Expect(err).NotTo(HaveOccurred())
+		writeSignatures(tag, attestation)
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		handler = &failingRegistry{registry: registry.New(registry.Logger(log.New(io.Discard, "", 0)))}
+		server = httptest.NewServer(handler)
+		image, err = name.NewDigest(strings.TrimPrefix(server.URL, "http://") + "/sample/image@" + imageDigest)
+		Expect(err).NotTo(HaveOccurred())
+
+		verifier, err = signature.NewVerifier(encodePublicKey(key))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("verifies the images signed and attested with the key", func() {
+		addSignature(key, nil)
+		addAttestation(key, "https://slsa.dev/provenance/v0.2", nil, nil)
+		Expect(verifier.Verify(context.Background(), image.String())).To(Succeed())
+	})
+
+	It("fails the verification of the images which aren't signed", func() {
+		addAttestation(key, "https://slsa.dev/provenance/v0.2", nil, nil)
+		err := verifier.Verify(context.Background(), image.String())
+		Expect(err).To(MatchError(signature.ErrVerificationFailed))
+		Expect(err.Error()).To(ContainSubstring("has no signature made with the trusted key"))
+		Expect(signature.IsVerificationFailed(err)).To(BeTrue())
+	})
+
+	It("fails the verification of the images signed with another key", func() {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		addSignature(otherKey, nil)
+		addAttestation(otherKey, "https://slsa.dev/provenance/v0.2", nil, nil)
+		Expect(verifier.Verify(context.Background(), image.String())).To(MatchError(signature.ErrVerificationFailed))
+	})
+
+	It("fails the verification of the images without provenance attestation", func() {
+		addSignature(key, nil)
+		addAttestation(key, "https://cyclonedx.org/bom", nil, nil)
+		err := verifier.Verify(context.Background(), image.String())
+		Expect(err).To(MatchError(signature.ErrVerificationFailed))
+		Expect(err.Error()).To(ContainSubstring("has no provenance attestation"))
+	})
+
+	Context("when the verifier requires the transparency log", func() {
+		var rekorKey *ecdsa.PrivateKey
+
+		BeforeEach(func() {
+			var err error
+			rekorKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			verifier, err = verifier.WithTransparencyLog(encodePublicKey(rekorKey))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("verifies the images whose signature and attestation were recorded in the transparency log", func() {
+			addSignature(key, rekorKey)
+			addAttestation(key, "https://slsa.dev/provenance/v0.2", rekorKey, &rekorKey.PublicKey)
+			Expect(verifier.Verify(context.Background(), image.String())).To(Succeed())
+		})
+
+		It("fails the verification of the images whose signature wasn't recorded in the transparency log", func() {
+			addSignature(key, nil)
+			addAttestation(key, "https://slsa.dev/provenance/v0.2", rekorKey, &rekorKey.PublicKey)
+			err := verifier.Verify(context.Background(), image.String())
+			Expect(err).To(MatchError(signature.ErrVerificationFailed))
+			Expect(err.Error()).To(ContainSubstring("has no signature made with the trusted key"))
+		})
+
+		It("fails the verification of the images whose attestation was recorded in another transparency log", func() {
+			otherRekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			addSignature(key, rekorKey)
+			addAttestation(key, "https://slsa.dev/provenance/v0.2", otherRekorKey, &otherRekorKey.PublicKey)
+			err = verifier.Verify(context.Background(), image.String())
+			Expect(err).To(MatchError(signature.ErrVerificationFailed))
+			Expect(err.Error()).To(ContainSubstring("has no provenance attestation"))
+			Expect(err.Error()).To(ContainSubstring("rekor log public key not found"))
+		})
+
+		It("fails the verification of the images whose attestation bundle claims the log ID of the trusted transparency log", func() {
+			otherRekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			addSignature(key, rekorKey)
+			addAttestation(key, "https://slsa.dev/provenance/v0.2", otherRekorKey, &rekorKey.PublicKey)
+			err = verifier.Verify(context.Background(), image.String())
+			Expect(err).To(MatchError(signature.ErrVerificationFailed))
+			Expect(err.Error()).To(ContainSubstring("unable to verify SET"))
+		})
+	})
+
+	It("fails the verification of the images not referenced by digest", func() {
+		Expect(verifier.Verify(context.Background(), "quay.io/sample/image:latest")).To(MatchError(signature.ErrVerificationFailed))
+	})
+
+	It("returns the errors of the registry without failing the verification", func() {
+		handler.failing = true
+		err := verifier.Verify(context.Background(), image.String())
+		Expect(err).To(HaveOccurred())
+		Expect(signature.IsVerificationFailed(err)).To(BeFalse())
+	})
+
+	It("is created from the environment only when the public key is configured", func() {
+		GinkgoT().Setenv(signature.PublicKeyFileEnvVar, "")
+		envVerifier, err := signature.NewVerifierFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envVerifier).To(BeNil())
+
+		keyFile := filepath.Join(GinkgoT().TempDir(), "cosign.pub")
+		Expect(os.WriteFile(keyFile, encodePublicKey(key), 0600)).To(Succeed())
+		GinkgoT().Setenv(signature.PublicKeyFileEnvVar, keyFile)
+		envVerifier, err = signature.NewVerifierFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envVerifier).NotTo(BeNil())
+
+		Expect(os.WriteFile(keyFile, []byte("invalid"), 0600)).To(Succeed())
+		_, err = signature.NewVerifierFromEnv()
+		Expect(err).To(HaveOccurred())
+	})
+})
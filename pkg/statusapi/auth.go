@@ -0,0 +1,126 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrUnauthenticated is returned by an Authorizer when the token doesn't identify a user.
+	ErrUnauthenticated = errors.New("the request is not authenticated")
+
+	// ErrForbidden is returned by an Authorizer when the user isn't allowed to access the requested resource.
+	ErrForbidden = errors.New("the request is not allowed")
+)
+
+// Authorizer decides whether the bearer token of a request grants access to the requested resource.
+type Authorizer interface {
+	// Authorize returns nil when the user identified by the token is allowed to perform the request described by
+	// the resource attributes, ErrUnauthenticated or ErrForbidden when it is not, or any other error when
+	// the decision couldn't be made.
+	Authorize(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error
+}
+
+// KubernetesAuthorizer authenticates the bearer tokens through TokenReviews and authorizes the requests through
+// SubjectAccessReviews, so the access to the status API and to the build webhook follows the RBAC of the cluster.
+type KubernetesAuthorizer struct {
+	client client.Client
+}
+
+// NewKubernetesAuthorizer creates and returns a KubernetesAuthorizer creating the reviews with the given client.
+func NewKubernetesAuthorizer(c client.Client) *KubernetesAuthorizer {
+	return &KubernetesAuthorizer{client: c}
+}
+
+// Authorize reviews the token and checks that the authenticated user is allowed to perform the request.
+func (a *KubernetesAuthorizer) Authorize(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error {
+	if token == "" {
+		return ErrUnauthenticated
+	}
+
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}
+	if err := a.client.Create(ctx, tokenReview); err != nil {
+		return fmt.Errorf("failed to review the token: %w", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		return ErrUnauthenticated
+	}
+
+	user := tokenReview.Status.User
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	accessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: attributes,
+			User:               user.Username,
+			UID:                user.UID,
+			Groups:             user.Groups,
+			Extra:              extra,
+		},
+	}
+	if err := a.client.Create(ctx, accessReview); err != nil {
+		return fmt.Errorf("failed to review the access of user %s: %w", user.Username, err)
+	}
+	if !accessReview.Status.Allowed {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeRequest checks that the bearer token of the request allows the access described by the resource
+// attributes. The error response is written when the request isn't allowed, so the request mustn't be served any
+// further when false is returned.
+func AuthorizeRequest(w http.ResponseWriter, r *http.Request, authorizer Authorizer, attributes *authorizationv1.ResourceAttributes, logger logr.Logger) bool {
+	token := ""
+	if value, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		token = strings.TrimSpace(value)
+	}
+
+	err := authorizer.Authorize(r.Context(), token, attributes)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrUnauthenticated):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		httpserver.WriteError(w, http.StatusUnauthorized, err.Error(), logger)
+	case errors.Is(err, ErrForbidden):
+		httpserver.WriteError(w, http.StatusForbidden, err.Error(), logger)
+	default:
+		logger.Error(err, "Failed to authorize the request")
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to authorize the request", logger)
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/pkg/statusapi"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Kubernetes authorizer", func() {
+
+	var (
+		accessReviews []*authorizationv1.SubjectAccessReview
+		allowed       bool
+		authorizer    *statusapi.KubernetesAuthorizer
+		attributes    *authorizationv1.ResourceAttributes
+	)
+
+	BeforeEach(func() {
+		accessReviews = nil
+		allowed = true
+		attributes = &authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "get",
+			Group:     "appstudio.redhat.com",
+			Resource:  "snapshots",
+			Name:      "snapshot-sample",
+		}
+
+		c := fake.NewClientBuilder().
+			WithScheme(newScheme()).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					switch review := obj.(type) {
+					case *authenticationv1.TokenReview:
+						if review.Spec.Token == "valid-token" {
+							review.Status.Authenticated = true
+							review.Status.User = authenticationv1.UserInfo{
+								Username: "user-sample",
+								Groups:   []string{"group-sample"},
+							}
+						}
+					case *authorizationv1.SubjectAccessReview:
+						accessReviews = append(accessReviews, review)
+						review.Status.Allowed = allowed
+					}
+					return nil
+				},
+			}).
+			Build()
+		authorizer = statusapi.NewKubernetesAuthorizer(c)
+	})
+
+	It("allows the users granted access by the cluster RBAC", func() {
+		Expect(authorizer.Authorize(context.Background(), "valid-token", attributes)).To(Succeed())
+		Expect(accessReviews).To(HaveLen(1))
+		Expect(accessReviews[0].Spec.User).To(Equal("user-sample"))
+		Expect(accessReviews[0].Spec.Groups).To(Equal([]string{"group-sample"}))
+		Expect(accessReviews[0].Spec.ResourceAttributes).To(Equal(attributes))
+	})
+
+	It("rejects the users who aren't granted access", func() {
+		allowed = false
+		Expect(authorizer.Authorize(context.Background(), "valid-token", attributes)).To(MatchError(statusapi.ErrForbidden))
+	})
+
+	It("rejects the tokens which don't authenticate a user", func() {
+		Expect(authorizer.Authorize(context.Background(), "invalid-token", attributes)).To(MatchError(statusapi.ErrUnauthenticated))
+		Expect(authorizer.Authorize(context.Background(), "", attributes)).To(MatchError(statusapi.ErrUnauthenticated))
+		Expect(accessReviews).To(BeEmpty())
+	})
+})
+
+// authorizerFunc adapts a function to the Authorizer interface.
+type authorizerFunc func(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error
+
+func (f authorizerFunc) Authorize(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error {
+	return f(ctx, token, attributes)
+}
+
+var _ = Describe("Request authorization", func() {
+
+	var attributes *authorizationv1.ResourceAttributes
+
+	authorize := func(authorization string, err error) (bool, *httptest.ResponseRecorder, string) {
+		receivedToken := ""
+		authorizer := authorizerFunc(func(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error {
+			receivedToken = token
+			return err
+		})
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		allowed := statusapi.AuthorizeRequest(recorder, request, authorizer, attributes, logr.Discard())
+		return allowed, recorder, receivedToken
+	}
+
+	BeforeEach(func() {
+		attributes = &authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Resource: "snapshots"}
+	})
+
+	It("authorizes the bearer token of the request", func() {
+		allowed, recorder, token := authorize("Bearer  user-token ", nil)
+		Expect(allowed).To(BeTrue())
+		Expect(token).To(Equal("user-token"))
+		Expect(recorder.Body.Len()).To(BeZero())
+	})
+
+	It("challenges the requests without a token and forbids the other users", func() {
+		allowed, recorder, token := authorize("Basic dXNlcjpwYXNz", statusapi.ErrUnauthenticated)
+		Expect(allowed).To(BeFalse())
+		Expect(token).To(BeEmpty())
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal("Bearer"))
+
+		allowed, recorder, _ = authorize("Bearer other-token", statusapi.ErrForbidden)
+		Expect(allowed).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("doesn't leak the errors of the authorizer", func() {
+		allowed, recorder, _ := authorize("Bearer user-token", errors.New("the token review failed"))
+		Expect(allowed).To(BeFalse())
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error": "failed to authorize the request"}`))
+	})
+})
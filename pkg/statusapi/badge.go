@@ -0,0 +1,185 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	clienterrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BadgePathPrefix is the path prefix of the status badge endpoints.
+	BadgePathPrefix = "/badges/v1/namespaces/"
+
+	// PublicBadgeAnnotation is the annotation of the Applications whose status badge is served without
+	// authentication, it has to be set to "true".
+	PublicBadgeAnnotation = "test.appstudio.openshift.io/public-badge"
+
+	// badgeLabel is the text of the left part of the badges.
+	badgeLabel = "integration"
+)
+
+// badgeTemplate is the SVG template of the status badges, in the flat style of shields.io.
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="20" role="img" aria-label="{{ .Label }}: {{ .Message }}">
+<title>{{ .Label }}: {{ .Message }}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{ .Width }}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="{{ .LabelWidth }}" height="20" fill="#555"/><rect x="{{ .LabelWidth }}" width="{{ .MessageWidth }}" height="20" fill="{{ .Color }}"/><rect width="{{ .Width }}" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{ .LabelX }}" y="14">{{ .Label }}</text>
+<text x="{{ .MessageX }}" y="14">{{ .Message }}</text>
+</g>
+</svg>
+`))
+
+// badge holds the values the badge template is executed with.
+type badge struct {
+	Label        string
+	Message      string
+	Color        string
+	Width        int
+	LabelWidth   int
+	MessageWidth int
+	LabelX       float64
+	MessageX     float64
+}
+
+// newBadge returns the badge with the given message and color, the widths of its parts are estimated
+// from the length of their texts.
+func newBadge(message, color string) *badge {
+	labelWidth := textWidth(badgeLabel)
+	messageWidth := textWidth(message)
+	return &badge{
+		Label:        badgeLabel,
+		Message:      message,
+		Color:        color,
+		Width:        labelWidth + messageWidth,
+		LabelWidth:   labelWidth,
+		MessageWidth: messageWidth,
+		LabelX:       float64(labelWidth) / 2,
+		MessageX:     float64(labelWidth) + float64(messageWidth)/2,
+	}
+}
+
+// textWidth estimates the width of the text rendered in the 11px Verdana font of the badges, including the padding.
+func textWidth(text string) int {
+	return len(text)*7 + 10
+}
+
+// newVerdictBadge returns the badge of the given Snapshot verdict, the unknown badge is returned when it is empty.
+func newVerdictBadge(verdict string) *badge {
+	switch verdict {
+	case VerdictPassed:
+		return newBadge("passing", "#4c1")
+	case VerdictFailed:
+		return newBadge("failing", "#e05d44")
+	case VerdictInProgress:
+		return newBadge("running", "#dfb317")
+	case VerdictInvalid:
+		return newBadge("invalid", "#9f9f9f")
+	default:
+		return newBadge("unknown", "#9f9f9f")
+	}
+}
+
+// handleBadge serves the SVG status badge of the latest Snapshot of an application, the supported path is
+// /badges/v1/namespaces/<namespace>/applications/<application>, optionally with the branch query parameter.
+// Badges are served without authentication so they can be embedded in READMEs, only for the Applications
+// which opted in through the PublicBadgeAnnotation.
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		httpserver.WriteError(w, http.StatusMethodNotAllowed, "only the GET method is supported", s.logger)
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, BadgePathPrefix), "/")
+	if len(segments) != 3 || segments[1] != "applications" || segments[0] == "" || segments[2] == "" {
+		httpserver.WriteError(w, http.StatusNotFound, "the requested path is not supported", s.logger)
+		return
+	}
+	namespace, applicationName := segments[0], strings.TrimSuffix(segments[2], ".svg")
+	branch := strings.TrimPrefix(r.URL.Query().Get("branch"), "refs/heads/")
+
+	application := &applicationapiv1alpha1.Application{}
+	err := s.reader.Get(r.Context(), client.ObjectKey{Namespace: namespace, Name: applicationName}, application)
+	if err != nil && !clienterrors.IsNotFound(err) {
+		s.logger.Error(err, "Failed to get the application", "application.Namespace", namespace, "application.Name", applicationName)
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to get the application", s.logger)
+		return
+	}
+	// the badges of the applications which didn't opt in are reported as not found, so their existence isn't revealed
+	if err != nil || application.GetAnnotations()[PublicBadgeAnnotation] != "true" {
+		httpserver.WriteError(w, http.StatusNotFound, fmt.Sprintf("no badge found for application %s", applicationName), s.logger)
+		return
+	}
+
+	snapshot, err := GetLatestBranchSnapshot(r.Context(), s.reader, application, branch)
+	if err != nil {
+		s.logger.Error(err, "Failed to list the snapshots of the application", "application.Namespace", namespace, "application.Name", applicationName)
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to list the snapshots", s.logger)
+		return
+	}
+	verdict := ""
+	if snapshot != nil {
+		verdict = GetSnapshotVerdict(snapshot)
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	// the badges are embedded through caching image proxies, which would otherwise keep serving a stale state
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(http.StatusOK)
+	if err := badgeTemplate.Execute(w, newVerdictBadge(verdict)); err != nil {
+		s.logger.Error(err, "Failed to write the status badge")
+	}
+}
+
+// GetLatestBranchSnapshot returns the most recently created Snapshot of the application which was created for
+// a push event to the branch, or to any branch when it is empty. Override Snapshots aren't gated by integration
+// tests, so they are not taken into account. Nil is returned when there is no such Snapshot.
+func GetLatestBranchSnapshot(ctx context.Context, reader client.Reader, application *applicationapiv1alpha1.Application, branch string) (*applicationapiv1alpha1.Snapshot, error) {
+	snapshots := &applicationapiv1alpha1.SnapshotList{}
+	err := reader.List(ctx, snapshots, client.InNamespace(application.Namespace),
+		client.MatchingLabels{gitops.ApplicationNameLabel: application.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *applicationapiv1alpha1.Snapshot
+	for i, snapshot := range snapshots.Items {
+		if gitops.IsOverrideSnapshot(&snapshot) || !gitops.IsSnapshotCreatedByPACPushEvent(&snapshot) {
+			continue
+		}
+		if branch != "" && strings.TrimPrefix(snapshot.GetAnnotations()[gitops.PipelineAsCodeTargetBranchAnnotation], "refs/heads/") != branch {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			latest = &snapshots.Items[i]
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/statusapi"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Status badges", func() {
+
+	var server *httptest.Server
+
+	getBadge := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	newBranchSnapshot := func(name, branch, eventType string, created time.Time, passed bool) *applicationapiv1alpha1.Snapshot {
+		snapshot := newSnapshot(name, "abc123", created)
+		snapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = eventType
+		snapshot.Annotations[gitops.PipelineAsCodeTargetBranchAnnotation] = branch
+		status := metav1.ConditionFalse
+		if passed {
+			status = metav1.ConditionTrue
+		}
+		snapshot.Status.Conditions = []metav1.Condition{
+			{
+				Type:               gitops.AppStudioTestSucceededCondition,
+				Status:             status,
+				Reason:             reasons.AppStudioTestSucceededConditionSatisfied,
+				LastTransitionTime: metav1.Now(),
+			},
+		}
+		return snapshot
+	}
+
+	BeforeEach(func() {
+		public := &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "application-sample",
+				Namespace:   "default",
+				Annotations: map[string]string{statusapi.PublicBadgeAnnotation: "true"},
+			},
+		}
+		private := &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "application-private",
+				Namespace: "default",
+			},
+		}
+		empty := &applicationapiv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "application-empty",
+				Namespace:   "default",
+				Annotations: map[string]string{statusapi.PublicBadgeAnnotation: "true"},
+			},
+		}
+
+		reader := fake.NewClientBuilder().
+			WithScheme(newScheme()).
+			WithObjects(
+				public, private, empty,
+				newBranchSnapshot("snapshot-main", "main", "push", time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC), true),
+				newBranchSnapshot("snapshot-release", "refs/heads/release-1.0", "push", time.Date(2024, 5, 14, 11, 0, 0, 0, time.UTC), false),
+				newBranchSnapshot("snapshot-pull-request", "main", "pull_request", time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC), false),
+			).
+			Build()
+		server = httptest.NewServer(statusapi.NewServer(reader, &fakeAuthorizer{}, logr.Discard()).Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves the badge of the latest push snapshot of the branch", func() {
+		resp, body := getBadge("/badges/v1/namespaces/default/applications/application-sample?branch=main")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("image/svg+xml"))
+		Expect(resp.Header.Get("Cache-Control")).To(ContainSubstring("no-cache"))
+		Expect(body).To(HavePrefix("<svg"))
+		Expect(body).To(ContainSubstring("<title>integration: passing</title>"))
+		Expect(body).To(ContainSubstring(`fill="#4c1"`))
+	})
+
+	It("serves the badge of the latest push snapshot of any branch", func() {
+		_, body := getBadge("/badges/v1/namespaces/default/applications/application-sample.svg")
+		Expect(body).To(ContainSubstring("<title>integration: failing</title>"))
+
+		_, body = getBadge("/badges/v1/namespaces/default/applications/application-sample?branch=release-1.0")
+		Expect(body).To(ContainSubstring("<title>integration: failing</title>"))
+	})
+
+	It("serves the unknown badge when no snapshot was created for the branch", func() {
+		resp, body := getBadge("/badges/v1/namespaces/default/applications/application-sample?branch=feature")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(ContainSubstring("<title>integration: unknown</title>"))
+
+		_, body = getBadge("/badges/v1/namespaces/default/applications/application-empty")
+		Expect(body).To(ContainSubstring("<title>integration: unknown</title>"))
+	})
+
+	It("doesn't serve the badges of the applications which didn't opt in", func() {
+		resp, _ := getBadge("/badges/v1/namespaces/default/applications/application-private")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		resp, _ = getBadge("/badges/v1/namespaces/default/applications/application-missing")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
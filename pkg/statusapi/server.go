@@ -0,0 +1,277 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/httpserver"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	clienterrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+const (
+	// APIPathPrefix is the path prefix of all of the status API endpoints.
+	APIPathPrefix = "/api/v1/namespaces/"
+
+	// VerdictPassed is the verdict of a Snapshot which passed all of its required integration tests.
+	VerdictPassed = "Passed"
+
+	// VerdictFailed is the verdict of a Snapshot which failed some of its required integration tests.
+	VerdictFailed = "Failed"
+
+	// VerdictInvalid is the verdict of a Snapshot which was marked as invalid and won't be tested.
+	VerdictInvalid = "Invalid"
+
+	// VerdictInProgress is the verdict of a Snapshot whose required integration tests haven't finished yet.
+	VerdictInProgress = "InProgress"
+)
+
+// SnapshotStatus is the gating status of a Snapshot returned by the status API.
+type SnapshotStatus struct {
+	// Name of the Snapshot
+	Name string `json:"name"`
+	// Namespace of the Snapshot
+	Namespace string `json:"namespace"`
+	// Application the Snapshot was created for
+	Application string `json:"application"`
+	// Component is the name of the updated component, it is empty for Snapshots not created by a component build
+	Component string `json:"component,omitempty"`
+	// SHA is the commit the Snapshot was created for, it is empty for Snapshots not created by Pipelines as Code
+	SHA string `json:"sha,omitempty"`
+	// CreationTime is the time the Snapshot was created
+	CreationTime time.Time `json:"creationTime"`
+	// Verdict is the gating status of the Snapshot
+	Verdict string `json:"verdict"`
+	// Message is a human readable explanation of the verdict
+	Message string `json:"message,omitempty"`
+	// Scenarios are the results of the integration test scenarios of the Snapshot
+	Scenarios []*intgteststat.IntegrationTestStatusDetail `json:"scenarios"`
+	// GatingDecisions are the gating decisions recorded for the Snapshot
+	GatingDecisions []gitops.GatingDecision `json:"gatingDecisions,omitempty"`
+	// Diff describes the component images which changed relative to the previous Snapshot of the application
+	Diff *gitops.SnapshotDiff `json:"diff,omitempty"`
+	// SBOMs are the references of the SBOMs of the component images, keyed by component name
+	SBOMs map[string]string `json:"sboms,omitempty"`
+}
+
+// Server serves the status API, which returns the gating status and the integration test results of Snapshots
+// to the users allowed to get them, without requiring access to the rest of the integration resources.
+// It also serves the public status badges of the Applications. Its Handler is served by an httpserver.Server.
+type Server struct {
+	reader     client.Reader
+	authorizer Authorizer
+	logger     logr.Logger
+}
+
+// NewServer creates and returns a Server. The Snapshots are read through the given reader and the requests are
+// authorized by the given authorizer.
+func NewServer(reader client.Reader, authorizer Authorizer, logger logr.Logger) *Server {
+	return &Server{
+		reader:     reader,
+		authorizer: authorizer,
+		logger:     logger,
+	}
+}
+
+// Handler returns the handler of the status API and status badge requests.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(APIPathPrefix, s.handleAPI)
+	mux.HandleFunc(BadgePathPrefix, s.handleBadge)
+	return mux
+}
+
+// handleAPI routes the status API requests, the supported paths are
+// /api/v1/namespaces/<namespace>/snapshots/<name> and
+// /api/v1/namespaces/<namespace>/applications/<application>/commits/<sha>.
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		httpserver.WriteError(w, http.StatusMethodNotAllowed, "only the GET method is supported", s.logger)
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, APIPathPrefix), "/")
+	switch {
+	case len(segments) == 3 && segments[1] == "snapshots" && segments[0] != "" && segments[2] != "":
+		s.handleSnapshot(w, r, segments[0], segments[2])
+	case len(segments) == 5 && segments[1] == "applications" && segments[3] == "commits" &&
+		segments[0] != "" && segments[2] != "" && segments[4] != "":
+		s.handleCommit(w, r, segments[0], segments[2], segments[4])
+	default:
+		httpserver.WriteError(w, http.StatusNotFound, "the requested path is not supported", s.logger)
+	}
+}
+
+// handleSnapshot returns the status of the named Snapshot.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	if !s.authorize(w, r, namespace, name) {
+		return
+	}
+
+	snapshot := &applicationapiv1alpha1.Snapshot{}
+	err := s.reader.Get(r.Context(), client.ObjectKey{Namespace: namespace, Name: name}, snapshot)
+	if err != nil {
+		if clienterrors.IsNotFound(err) {
+			httpserver.WriteError(w, http.StatusNotFound, fmt.Sprintf("snapshot %s not found", name), s.logger)
+			return
+		}
+		s.logger.Error(err, "Failed to get the snapshot", "snapshot.Namespace", namespace, "snapshot.Name", name)
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to get the snapshot", s.logger)
+		return
+	}
+
+	s.writeSnapshotStatus(w, snapshot)
+}
+
+// handleCommit returns the status of the latest Snapshot created for the commit of the application.
+func (s *Server) handleCommit(w http.ResponseWriter, r *http.Request, namespace, application, sha string) {
+	if !s.authorize(w, r, namespace, "") {
+		return
+	}
+
+	snapshot, err := GetLatestSnapshot(r.Context(), s.reader, namespace, client.MatchingLabels{
+		gitops.ApplicationNameLabel:   application,
+		gitops.PipelineAsCodeSHALabel: sha,
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to list the snapshots of the commit", "namespace", namespace, "application", application, "sha", sha)
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to list the snapshots", s.logger)
+		return
+	}
+	if snapshot == nil {
+		httpserver.WriteError(w, http.StatusNotFound, fmt.Sprintf("no snapshot found for commit %s of application %s", sha, application), s.logger)
+		return
+	}
+
+	s.writeSnapshotStatus(w, snapshot)
+}
+
+// authorize checks that the bearer token of the request allows getting the Snapshot, or any Snapshot of the
+// namespace when the name is empty. The error response is written when the request isn't allowed.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, namespace, name string) bool {
+	return AuthorizeRequest(w, r, s.authorizer, &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "get",
+		Group:     applicationapiv1alpha1.GroupVersion.Group,
+		Version:   applicationapiv1alpha1.GroupVersion.Version,
+		Resource:  "snapshots",
+		Name:      name,
+	}, s.logger)
+}
+
+// writeSnapshotStatus writes the status of the Snapshot as the JSON response.
+func (s *Server) writeSnapshotStatus(w http.ResponseWriter, snapshot *applicationapiv1alpha1.Snapshot) {
+	status, err := NewSnapshotStatus(snapshot)
+	if err != nil {
+		s.logger.Error(err, "Failed to get the status of the snapshot", "snapshot.Namespace", snapshot.Namespace, "snapshot.Name", snapshot.Name)
+		httpserver.WriteError(w, http.StatusInternalServerError, "failed to get the status of the snapshot", s.logger)
+		return
+	}
+
+	httpserver.WriteJSON(w, http.StatusOK, status, s.logger)
+}
+
+// NewSnapshotStatus returns the gating status of the Snapshot.
+func NewSnapshotStatus(snapshot *applicationapiv1alpha1.Snapshot) (*SnapshotStatus, error) {
+	testStatuses, err := gitops.NewSnapshotIntegrationTestStatusesFromSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	gatingDecisions, err := gitops.GetGatingDecisionsFromSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := gitops.GetSnapshotDiff(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	sboms, err := gitops.GetSnapshotSBOMs(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SnapshotStatus{
+		Name:            snapshot.Name,
+		Namespace:       snapshot.Namespace,
+		Application:     snapshot.Spec.Application,
+		Component:       snapshot.GetLabels()[gitops.SnapshotComponentLabel],
+		SHA:             snapshot.GetLabels()[gitops.PipelineAsCodeSHALabel],
+		CreationTime:    snapshot.CreationTimestamp.UTC(),
+		Verdict:         GetSnapshotVerdict(snapshot),
+		Scenarios:       testStatuses.GetStatuses(),
+		GatingDecisions: gatingDecisions,
+		Diff:            diff,
+		SBOMs:           sboms,
+	}
+	if condition := meta.FindStatusCondition(snapshot.Status.Conditions, gitops.AppStudioTestSucceededCondition); condition != nil {
+		status.Message = condition.Message
+	}
+	if status.Verdict == VerdictInvalid {
+		if condition := meta.FindStatusCondition(snapshot.Status.Conditions, gitops.AppStudioIntegrationStatusCondition); condition != nil {
+			status.Message = condition.Message
+		}
+	}
+
+	return status, nil
+}
+
+// GetSnapshotVerdict returns the gating verdict of the Snapshot.
+func GetSnapshotVerdict(snapshot *applicationapiv1alpha1.Snapshot) string {
+	switch {
+	case gitops.IsSnapshotMarkedAsInvalid(snapshot):
+		return VerdictInvalid
+	case gitops.IsSnapshotMarkedAsPassed(snapshot):
+		return VerdictPassed
+	case gitops.IsSnapshotMarkedAsFailed(snapshot):
+		return VerdictFailed
+	default:
+		return VerdictInProgress
+	}
+}
+
+// GetLatestSnapshot returns the most recently created Snapshot of the namespace matching the given labels,
+// or nil when there is none.
+func GetLatestSnapshot(ctx context.Context, reader client.Reader, namespace string, labels client.MatchingLabels) (*applicationapiv1alpha1.Snapshot, error) {
+	snapshots := &applicationapiv1alpha1.SnapshotList{}
+	if err := reader.List(ctx, snapshots, client.InNamespace(namespace), labels); err != nil {
+		return nil, err
+	}
+
+	var latest *applicationapiv1alpha1.Snapshot
+	for i, snapshot := range snapshots.Items {
+		if latest == nil || latest.CreationTimestamp.Before(&snapshot.CreationTimestamp) {
+			latest = &snapshots.Items[i]
+		}
+	}
+
+	return latest, nil
+}
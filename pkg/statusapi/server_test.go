@@ -0,0 +1,208 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/konflux-ci/integration-service/gitops"
+	"github.com/konflux-ci/integration-service/pkg/reasons"
+	"github.com/konflux-ci/integration-service/pkg/statusapi"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeAuthorizer allows the requests bearing the allowed token and records the authorized resource attributes.
+type fakeAuthorizer struct {
+	allowedToken string
+	attributes   []*authorizationv1.ResourceAttributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, token string, attributes *authorizationv1.ResourceAttributes) error {
+	a.attributes = append(a.attributes, attributes)
+	if token == "" {
+		return statusapi.ErrUnauthenticated
+	}
+	if token != a.allowedToken {
+		return statusapi.ErrForbidden
+	}
+	return nil
+}
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(applicationapiv1alpha1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+func newSnapshot(name, sha string, created time.Time) *applicationapiv1alpha1.Snapshot {
+	return &applicationapiv1alpha1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				gitops.ApplicationNameLabel:   "application-sample",
+				gitops.SnapshotComponentLabel: "component-sample",
+				gitops.PipelineAsCodeSHALabel: sha,
+			},
+			Annotations: map[string]string{
+				gitops.SnapshotTestsStatusAnnotation: `[{"scenario":"scenario-a","status":"TestPassed","lastUpdateTime":"2024-05-14T10:30:00Z","details":"test pass","testPipelineRunName":"pipelinerun-a"}]`,
+			},
+		},
+		Spec: applicationapiv1alpha1.SnapshotSpec{
+			Application: "application-sample",
+		},
+	}
+}
+
+var _ = Describe("Status API", func() {
+
+	var (
+		authorizer *fakeAuthorizer
+		server     *httptest.Server
+	)
+
+	get := func(path, token string) (*http.Response, map[string]any) {
+		request, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		body := map[string]any{}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return resp, body
+	}
+
+	BeforeEach(func() {
+		passed := newSnapshot("snapshot-passed", "abc123", time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC))
+		passed.Status.Conditions = []metav1.Condition{
+			{
+				Type:               gitops.AppStudioTestSucceededCondition,
+				Status:             metav1.ConditionTrue,
+				Reason:             reasons.AppStudioTestSucceededConditionSatisfied,
+				Message:            "All Integration Pipeline tests passed",
+				LastTransitionTime: metav1.Now(),
+			},
+		}
+		passed.Annotations[gitops.SnapshotDiffAnnotation] = `{"previousSnapshot":"snapshot-older","updated":[{"name":"component-sample","containerImage":"quay.io/sample@sha256:bbb","previousContainerImage":"quay.io/sample@sha256:aaa"}]}`
+		passed.Annotations[gitops.SnapshotSBOMsAnnotation] = `{"component-sample":"quay.io/sample@sha256:ccc"}`
+		older := newSnapshot("snapshot-older", "abc123", time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC))
+		other := newSnapshot("snapshot-other", "def456", time.Date(2024, 5, 14, 11, 0, 0, 0, time.UTC))
+
+		reader := fake.NewClientBuilder().
+			WithScheme(newScheme()).
+			WithObjects(passed, older, other).
+			Build()
+		authorizer = &fakeAuthorizer{allowedToken: "allowed-token"}
+		server = httptest.NewServer(statusapi.NewServer(reader, authorizer, logr.Discard()).Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the status of the snapshot by name", func() {
+		resp, body := get("/api/v1/namespaces/default/snapshots/snapshot-passed", "allowed-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(HaveKeyWithValue("name", "snapshot-passed"))
+		Expect(body).To(HaveKeyWithValue("application", "application-sample"))
+		Expect(body).To(HaveKeyWithValue("component", "component-sample"))
+		Expect(body).To(HaveKeyWithValue("sha", "abc123"))
+		Expect(body).To(HaveKeyWithValue("verdict", statusapi.VerdictPassed))
+		Expect(body).To(HaveKeyWithValue("message", "All Integration Pipeline tests passed"))
+		Expect(body["scenarios"]).To(ConsistOf(
+			And(HaveKeyWithValue("scenario", "scenario-a"), HaveKeyWithValue("status", "TestPassed")),
+		))
+		Expect(body["diff"]).To(And(
+			HaveKeyWithValue("previousSnapshot", "snapshot-older"),
+			HaveKeyWithValue("updated", ConsistOf(HaveKeyWithValue("name", "component-sample"))),
+		))
+		Expect(body["sboms"]).To(HaveKeyWithValue("component-sample", "quay.io/sample@sha256:ccc"))
+
+		Expect(authorizer.attributes).To(ConsistOf(&authorizationv1.ResourceAttributes{
+			Namespace: "default",
+			Verb:      "get",
+			Group:     "appstudio.redhat.com",
+			Version:   "v1alpha1",
+			Resource:  "snapshots",
+			Name:      "snapshot-passed",
+		}))
+	})
+
+	It("returns the status of the latest snapshot of the commit", func() {
+		resp, body := get("/api/v1/namespaces/default/applications/application-sample/commits/abc123", "allowed-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(HaveKeyWithValue("name", "snapshot-passed"))
+		Expect(authorizer.attributes).To(HaveLen(1))
+		Expect(authorizer.attributes[0].Name).To(BeEmpty())
+	})
+
+	It("reports the snapshots still being tested as in progress", func() {
+		resp, body := get("/api/v1/namespaces/default/applications/application-sample/commits/def456", "allowed-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(HaveKeyWithValue("name", "snapshot-other"))
+		Expect(body).To(HaveKeyWithValue("verdict", statusapi.VerdictInProgress))
+	})
+
+	It("returns not found for unknown snapshots and commits", func() {
+		resp, body := get("/api/v1/namespaces/default/snapshots/snapshot-missing", "allowed-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(body).To(HaveKeyWithValue("error", "snapshot snapshot-missing not found"))
+
+		resp, _ = get("/api/v1/namespaces/default/applications/application-sample/commits/fff000", "allowed-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		resp, _ = get("/api/v1/namespaces/default/components/component-sample", "allowed-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("rejects the requests which aren't authenticated or allowed", func() {
+		resp, _ := get("/api/v1/namespaces/default/snapshots/snapshot-passed", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(resp.Header.Get("WWW-Authenticate")).To(Equal("Bearer"))
+
+		resp, body := get("/api/v1/namespaces/default/snapshots/snapshot-passed", "other-token")
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(body).NotTo(HaveKey("name"))
+	})
+
+	It("rejects the methods other than GET", func() {
+		resp, err := http.Post(server.URL+"/api/v1/namespaces/default/snapshots/snapshot-passed", "application/json", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
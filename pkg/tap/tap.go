@@ -0,0 +1,152 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tap
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DirectiveSkip marks a test point which was skipped.
+	DirectiveSkip = "SKIP"
+
+	// DirectiveTodo marks a test point which is expected to fail.
+	DirectiveTodo = "TODO"
+)
+
+// ErrNoTestPoints is returned when the output contains neither a plan nor any test points.
+var ErrNoTestPoints = errors.New("output contains no TAP plan or test points")
+
+var (
+	planRegex      = regexp.MustCompile(`^1\.\.(\d+)(?:\s*#.*)?$`)
+	testPointRegex = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+	directiveRegex = regexp.MustCompile(`(?i)^(skip|todo)\S*\s*(.*)$`)
+)
+
+// TestPoint is a single "ok" or "not ok" line of the TAP output.
+type TestPoint struct {
+	Number      int
+	Description string
+	OK          bool
+	Directive   string
+	Reason      string
+}
+
+// Report holds the test points of a TAP output.
+type Report struct {
+	// Planned is the number of planned test points, -1 when the output has no plan.
+	Planned       int
+	TestPoints    []TestPoint
+	BailedOut     bool
+	BailOutReason string
+}
+
+// IsComplete returns true when the run wasn't bailed out and, if a plan was given,
+// the number of test points matches the plan.
+func (r *Report) IsComplete() bool {
+	if r.BailedOut {
+		return false
+	}
+	return r.Planned < 0 || r.Planned == len(r.TestPoints)
+}
+
+// GetFailedTestPoints returns the test points which failed and weren't marked with a TODO directive.
+func (r *Report) GetFailedTestPoints() []TestPoint {
+	var failed []TestPoint
+	for _, tp := range r.TestPoints {
+		if !tp.OK && tp.Directive != DirectiveTodo {
+			failed = append(failed, tp)
+		}
+	}
+	return failed
+}
+
+// Count returns the number of passed, failed, skipped and TODO test points. Test points marked with a TODO directive
+// are counted as TODO regardless of their outcome and skipped test points are counted as skipped.
+func (r *Report) Count() (passed, failed, skipped, todo int) {
+	for _, tp := range r.TestPoints {
+		switch {
+		case tp.Directive == DirectiveSkip:
+			skipped++
+		case tp.Directive == DirectiveTodo:
+			todo++
+		case tp.OK:
+			passed++
+		default:
+			failed++
+		}
+	}
+	return passed, failed, skipped, todo
+}
+
+// Parse parses the Test Anything Protocol output, lines which aren't part of the protocol,
+// like diagnostics, YAML blocks or the version line, are ignored.
+func Parse(output string) (*Report, error) {
+	report := &Report{Planned: -1}
+	hasPlan := false
+
+	for _, rawLine := range strings.Split(output, "\n") {
+		// indented lines belong to YAML diagnostic blocks or subtests
+		if strings.HasPrefix(rawLine, " ") || strings.HasPrefix(rawLine, "\t") {
+			continue
+		}
+		line := strings.TrimSpace(rawLine)
+
+		if strings.HasPrefix(line, "Bail out!") {
+			report.BailedOut = true
+			report.BailOutReason = strings.TrimSpace(strings.TrimPrefix(line, "Bail out!"))
+			break
+		}
+
+		if matches := planRegex.FindStringSubmatch(line); matches != nil && !hasPlan {
+			planned, err := strconv.Atoi(matches[1])
+			if err != nil {
+				return nil, err
+			}
+			report.Planned = planned
+			hasPlan = true
+			continue
+		}
+
+		matches := testPointRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		tp := TestPoint{
+			Description: matches[3],
+			OK:          matches[1] == "ok",
+		}
+		if matches[2] != "" {
+			tp.Number, _ = strconv.Atoi(matches[2])
+		} else {
+			tp.Number = len(report.TestPoints) + 1
+		}
+		if directive := directiveRegex.FindStringSubmatch(matches[4]); directive != nil {
+			tp.Directive = strings.ToUpper(directive[1])
+			tp.Reason = directive[2]
+		}
+		report.TestPoints = append(report.TestPoints, tp)
+	}
+
+	if !hasPlan && len(report.TestPoints) == 0 && !report.BailedOut {
+		return nil, ErrNoTestPoints
+	}
+	return report, nil
+}
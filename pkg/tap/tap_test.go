@@ -0,0 +1,81 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tap_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/integration-service/pkg/tap"
+)
+
+var _ = Describe("TAP parser", func() {
+
+	It("parses the test points and directives", func() {
+		report, err := tap.Parse(`TAP version 13
+1..5
+ok 1 - image is signed
+not ok 2 - image has no critical CVEs
+  ---
+  message: 3 critical CVEs found
+  ...
+ok 3 - sbom is attached # SKIP no sbom support
+not ok 4 - rpms are signed # TODO not enforced yet
+# diagnostic line
+ok test without number
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Planned).To(Equal(5))
+		Expect(report.TestPoints).To(HaveLen(5))
+		Expect(report.IsComplete()).To(BeTrue())
+
+		Expect(report.TestPoints[1]).To(Equal(tap.TestPoint{Number: 2, Description: "image has no critical CVEs"}))
+		Expect(report.TestPoints[2].Directive).To(Equal(tap.DirectiveSkip))
+		Expect(report.TestPoints[2].Reason).To(Equal("no sbom support"))
+		Expect(report.TestPoints[3].Directive).To(Equal(tap.DirectiveTodo))
+		Expect(report.TestPoints[4].Number).To(Equal(5))
+		Expect(report.TestPoints[4].Description).To(Equal("test without number"))
+
+		passed, failed, skipped, todo := report.Count()
+		Expect([]int{passed, failed, skipped, todo}).To(Equal([]int{2, 1, 1, 1}))
+		Expect(report.GetFailedTestPoints()).To(HaveLen(1))
+	})
+
+	It("reports incomplete runs", func() {
+		report, err := tap.Parse("1..3\nok 1\nok 2\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.IsComplete()).To(BeFalse())
+
+		report, err = tap.Parse("1..3\nok 1\nBail out! database unavailable\nok 2\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.IsComplete()).To(BeFalse())
+		Expect(report.BailedOut).To(BeTrue())
+		Expect(report.BailOutReason).To(Equal("database unavailable"))
+		Expect(report.TestPoints).To(HaveLen(1))
+	})
+
+	It("accepts a trailing plan", func() {
+		report, err := tap.Parse("ok 1\nok 2\n1..2\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.IsComplete()).To(BeTrue())
+	})
+
+	It("returns an error for output which isn't TAP", func() {
+		_, err := tap.Parse(`{"result": "SUCCESS"}`)
+		Expect(err).To(MatchError(tap.ErrNoTestPoints))
+	})
+})
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tektonresults
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// APIAddressEnvVar is the environment variable holding the address of the Tekton Results API,
+	// e.g. "https://tekton-results-api-service.tekton-pipelines.svc.cluster.local:8080".
+	// Fetching records of pruned objects is disabled when it's not set.
+	APIAddressEnvVar = "TEKTON_RESULTS_API_ADDR"
+
+	// APITokenFileEnvVar is the environment variable holding the path of the bearer token used to authenticate
+	// against the Tekton Results API. The service account token of the pod is used when it's not set.
+	APITokenFileEnvVar = "TEKTON_RESULTS_API_TOKEN_FILE"
+
+	// APICAFileEnvVar is the environment variable holding the path of the CA bundle used to verify the
+	// certificate of the Tekton Results API. The system CA bundle is used when it's not set.
+	APICAFileEnvVar = "TEKTON_RESULTS_API_CA_FILE"
+
+	// PipelineRunRecordType is the type of the Tekton Results records holding v1 PipelineRuns.
+	PipelineRunRecordType = "tekton.dev/v1.PipelineRun"
+
+	// TaskRunRecordType is the type of the Tekton Results records holding v1 TaskRuns.
+	TaskRunRecordType = "tekton.dev/v1.TaskRun"
+
+	// defaultTokenFile is the path of the service account token mounted into the pod.
+	defaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// recordsPath is the path of the endpoint listing records across all results of a namespace.
+	recordsPath = "/apis/results.tekton.dev/v1alpha2/parents/%s/results/-/records"
+)
+
+// ErrRecordNotFound is returned when the Tekton Results API doesn't contain a record of the requested object.
+var ErrRecordNotFound = errors.New("record not found in Tekton Results")
+
+// Client fetches the stored records of PipelineRuns and TaskRuns which were pruned from the cluster.
+type Client struct {
+	address    string
+	tokenFile  string
+	httpClient *http.Client
+}
+
+// record is the Tekton Results API representation of a single stored object.
+type record struct {
+	Name string `json:"name"`
+	Data struct {
+		Type  string `json:"type"`
+		Value []byte `json:"value"`
+	} `json:"data"`
+}
+
+// listRecordsResponse is the Tekton Results API response to a list records request.
+type listRecordsResponse struct {
+	Records []record `json:"records"`
+}
+
+// NewClientFromEnv creates and returns a Client configured through the TEKTON_RESULTS_API_* environment variables.
+// If the address of the Tekton Results API isn't configured, nil is returned.
+func NewClientFromEnv() (*Client, error) {
+	address := os.Getenv(APIAddressEnvVar)
+	if address == "" {
+		return nil, nil
+	}
+
+	tokenFile := os.Getenv(APITokenFileEnvVar)
+	if tokenFile == "" {
+		tokenFile = defaultTokenFile
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile := os.Getenv(APICAFileEnvVar); caFile != "" {
+		caBundle, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Tekton Results API CA bundle: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("failed to parse the Tekton Results API CA bundle %s", caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		tokenFile:  tokenFile,
+		httpClient: &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+// GetPipelineRun returns the stored PipelineRun with the given namespace and name.
+// If no record for the PipelineRun exists, ErrRecordNotFound is returned.
+func (c *Client) GetPipelineRun(ctx context.Context, namespace, name string) (*tektonv1.PipelineRun, error) {
+	pipelineRun := &tektonv1.PipelineRun{}
+	if err := c.getRecord(ctx, namespace, name, PipelineRunRecordType, pipelineRun); err != nil {
+		return nil, err
+	}
+
+	return pipelineRun, nil
+}
+
+// GetTaskRun returns the stored TaskRun with the given namespace and name.
+// If no record for the TaskRun exists, ErrRecordNotFound is returned.
+func (c *Client) GetTaskRun(ctx context.Context, namespace, name string) (*tektonv1.TaskRun, error) {
+	taskRun := &tektonv1.TaskRun{}
+	if err := c.getRecord(ctx, namespace, name, TaskRunRecordType, taskRun); err != nil {
+		return nil, err
+	}
+
+	return taskRun, nil
+}
+
+// ListPipelineRuns returns the stored PipelineRuns of the given namespace which have all of the given labels.
+func (c *Client) ListPipelineRuns(ctx context.Context, namespace string, labels map[string]string) ([]tektonv1.PipelineRun, error) {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	filter := fmt.Sprintf(`data_type == %q`, PipelineRunRecordType)
+	for _, key := range keys {
+		filter += fmt.Sprintf(` && data.metadata.labels[%q] == %q`, key, labels[key])
+	}
+
+	records, err := c.listRecords(ctx, namespace, filter)
+	if err != nil {
+		return nil, err
+	}
+	pipelineRuns := []tektonv1.PipelineRun{}
+	for _, r := range records {
+		if r.Data.Type != PipelineRunRecordType {
+			continue
+		}
+		pipelineRun := tektonv1.PipelineRun{}
+		if err := json.Unmarshal(r.Data.Value, &pipelineRun); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the Tekton Results record %s: %w", r.Name, err)
+		}
+		pipelineRuns = append(pipelineRuns, pipelineRun)
+	}
+
+	return pipelineRuns, nil
+}
+
+// getRecord finds the record of the given type for the object with the given namespace and name and
+// unmarshals the stored object into the given destination.
+func (c *Client) getRecord(ctx context.Context, namespace, name, recordType string, object any) error {
+	records, err := c.listRecords(ctx, namespace, fmt.Sprintf(`data_type == %q && data.metadata.name == %q`, recordType, name))
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if r.Data.Type != recordType {
+			continue
+		}
+		if err := json.Unmarshal(r.Data.Value, object); err != nil {
+			return fmt.Errorf("failed to unmarshal the Tekton Results record %s: %w", r.Name, err)
+		}
+		return nil
+	}
+
+	return ErrRecordNotFound
+}
+
+// listRecords returns the records of the given namespace matching the given filter, none when the namespace
+// has no records at all.
+func (c *Client) listRecords(ctx context.Context, namespace, filter string) ([]record, error) {
+	query := url.Values{}
+	query.Set("filter", filter)
+	requestURL := c.address + fmt.Sprintf(recordsPath, url.PathEscape(namespace)) + "?" + query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Tekton Results API request: %w", err)
+	}
+	token, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Tekton Results API token: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	request.Header.Set("Accept", "application/json")
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records from the Tekton Results API: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q while listing records from the Tekton Results API", response.Status)
+	}
+
+	records := &listRecordsResponse{}
+	if err := json.NewDecoder(response.Body).Decode(records); err != nil {
+		return nil, fmt.Errorf("failed to decode the Tekton Results API response: %w", err)
+	}
+	return records.Records, nil
+}
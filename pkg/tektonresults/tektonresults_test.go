@@ -0,0 +1,149 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tektonresults_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/pkg/tektonresults"
+)
+
+var _ = Describe("Tekton Results client", func() {
+
+	var (
+		server               *httptest.Server
+		requests             []*http.Request
+		recordResponse       map[string]any
+		pipelineRunsResponse map[string]any
+	)
+
+	BeforeEach(func() {
+		requests = []*http.Request{}
+		taskRun, err := json.Marshal(&tektonv1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "pruned-taskrun", Namespace: "default"},
+			Status: tektonv1.TaskRunStatus{
+				TaskRunStatusFields: tektonv1.TaskRunStatusFields{
+					Results: []tektonv1.TaskRunResult{
+						{Name: "TEST_OUTPUT", Value: *tektonv1.NewStructuredValues(`{"result": "SUCCESS"}`)},
+					},
+				},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		pipelineRun, err := json.Marshal(&tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "pruned-pipelinerun", Namespace: "default"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		pipelineRunsResponse = map[string]any{
+			"records": []map[string]any{
+				{
+					"name": "default/results/uid/records/uid",
+					"data": map[string]any{"type": tektonresults.PipelineRunRecordType, "value": pipelineRun},
+				},
+			},
+		}
+		recordResponse = map[string]any{
+			"records": []map[string]any{
+				{
+					"name": "default/results/uid/records/uid",
+					"data": map[string]any{"type": tektonresults.TaskRunRecordType, "value": taskRun},
+				},
+			},
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			if r.URL.Query().Get("filter") == `data_type == "tekton.dev/v1.TaskRun" && data.metadata.name == "pruned-taskrun"` {
+				Expect(json.NewEncoder(w).Encode(recordResponse)).To(Succeed())
+				return
+			}
+			if r.URL.Query().Get("filter") == `data_type == "tekton.dev/v1.PipelineRun" && data.metadata.labels["appstudio.openshift.io/snapshot"] == "snapshot-sample" && data.metadata.labels["test.appstudio.openshift.io/scenario"] == "scenario-sample"` {
+				Expect(json.NewEncoder(w).Encode(pipelineRunsResponse)).To(Succeed())
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]any{"records": []any{}})).To(Succeed())
+		}))
+
+		tokenFile := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("test-token\n"), 0600)).To(Succeed())
+		GinkgoT().Setenv(tektonresults.APIAddressEnvVar, server.URL)
+		GinkgoT().Setenv(tektonresults.APITokenFileEnvVar, tokenFile)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("is disabled when the API address isn't configured", func() {
+		GinkgoT().Setenv(tektonresults.APIAddressEnvVar, "")
+		client, err := tektonresults.NewClientFromEnv()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client).To(BeNil())
+	})
+
+	It("fetches the stored TaskRun", func() {
+		client, err := tektonresults.NewClientFromEnv()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client).ToNot(BeNil())
+
+		taskRun, err := client.GetTaskRun(context.Background(), "default", "pruned-taskrun")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taskRun.Name).To(Equal("pruned-taskrun"))
+		Expect(taskRun.Status.Results).To(HaveLen(1))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/apis/results.tekton.dev/v1alpha2/parents/default/results/-/records"))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer test-token"))
+	})
+
+	It("lists the stored PipelineRuns with the given labels", func() {
+		client, err := tektonresults.NewClientFromEnv()
+		Expect(err).ToNot(HaveOccurred())
+
+		pipelineRuns, err := client.ListPipelineRuns(context.Background(), "default", map[string]string{
+			"test.appstudio.openshift.io/scenario": "scenario-sample",
+			"appstudio.openshift.io/snapshot":      "snapshot-sample",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pipelineRuns).To(HaveLen(1))
+		Expect(pipelineRuns[0].Name).To(Equal("pruned-pipelinerun"))
+
+		pipelineRuns, err = client.ListPipelineRuns(context.Background(), "default", map[string]string{
+			"appstudio.openshift.io/snapshot": "other-snapshot",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pipelineRuns).To(BeEmpty())
+	})
+
+	It("returns ErrRecordNotFound when there is no record", func() {
+		client, err := tektonresults.NewClientFromEnv()
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetPipelineRun(context.Background(), "default", "missing-pipelinerun")
+		Expect(err).To(MatchError(tektonresults.ErrRecordNotFound))
+	})
+})
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/konflux-ci/operator-toolkit/controller"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ServiceName is the name under which the spans of the integration service are reported.
+	ServiceName = "integration-service"
+
+	// TracerName is the name of the tracer used to instrument the integration service.
+	TracerName = "github.com/konflux-ci/integration-service"
+
+	// OTLPEndpointEnvVar is the standard OpenTelemetry environment variable which enables the OTLP exporter when set.
+	OTLPEndpointEnvVar = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+	// OTLPTracesEndpointEnvVar is the standard OpenTelemetry environment variable for the traces specific OTLP endpoint.
+	OTLPTracesEndpointEnvVar = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// ShutdownFunc flushes the remaining spans and stops the tracer provider.
+type ShutdownFunc func(ctx context.Context) error
+
+// InitTracerProvider configures the global tracer provider to export spans via OTLP over gRPC.
+// The exporter is configured through the standard OTEL_EXPORTER_OTLP_* environment variables. When no endpoint
+// is configured, tracing is left disabled and the global no-op tracer provider is kept.
+func InitTracerProvider(ctx context.Context) (ShutdownFunc, error) {
+	if os.Getenv(OTLPEndpointEnvVar) == "" && os.Getenv(OTLPTracesEndpointEnvVar) == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the tracing resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tracerProvider.Shutdown, nil
+}
+
+// Tracer returns the tracer used to instrument the integration service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// StartReconcileSpan starts a span for a single reconciliation of the named controller. The namespace and name
+// of each of the given objects are recorded as span attributes prefixed with the map key, e.g. "snapshot.name".
+// The returned context should be passed to the adapter so that operation spans are recorded as its children.
+func StartReconcileSpan(ctx context.Context, controllerName string, objects map[string]client.Object) (context.Context, trace.Span) {
+	attributes := make([]attribute.KeyValue, 0, 2*len(objects))
+	for prefix, object := range objects {
+		if object == nil || reflect.ValueOf(object).IsNil() {
+			continue
+		}
+		attributes = append(attributes,
+			attribute.String(prefix+".namespace", object.GetNamespace()),
+			attribute.String(prefix+".name", object.GetName()),
+		)
+	}
+
+	return Tracer().Start(ctx, controllerName+".Reconcile", trace.WithAttributes(attributes...))
+}
+
+// TraceOperations wraps each of the given adapter operations so that its execution is recorded as a child span
+// of the span found in the given context. The span of each operation is named after the operation method.
+func TraceOperations(ctx context.Context, operations []controller.Operation) []controller.Operation {
+	tracedOperations := make([]controller.Operation, 0, len(operations))
+	for _, operation := range operations {
+		tracedOperations = append(tracedOperations, TraceOperation(ctx, GetOperationName(operation), operation))
+	}
+
+	return tracedOperations
+}
+
+// TraceOperation wraps the given adapter operation so that its execution is recorded as a child span, with the
+// given name, of the span found in the given context.
+func TraceOperation(ctx context.Context, operationName string, operation controller.Operation) controller.Operation {
+	return func() (controller.OperationResult, error) {
+		_, span := Tracer().Start(ctx, operationName)
+		defer span.End()
+
+		result, err := operation()
+		span.SetAttributes(
+			attribute.Bool("operation.requeue", result.RequeueRequest),
+			attribute.Bool("operation.cancel", result.CancelRequest),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return result, err
+	}
+}
+
+// GetOperationName returns the name of the method backing the given operation, e.g. "EnsureAllReleasesExist".
+func GetOperationName(operation controller.Operation) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(operation).Pointer())
+	if fn == nil {
+		return "UnknownOperation"
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konflux-ci/integration-service/pkg/tracing"
+	"github.com/konflux-ci/operator-toolkit/controller"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type testAdapter struct{}
+
+func (a *testAdapter) EnsureSomethingIsDone() (controller.OperationResult, error) {
+	return controller.ContinueProcessing()
+}
+
+func (a *testAdapter) EnsureSomethingFails() (controller.OperationResult, error) {
+	return controller.RequeueWithError(fmt.Errorf("something failed"))
+}
+
+var _ = Describe("Tracing", Ordered, func() {
+	var (
+		spanRecorder *tracetest.SpanRecorder
+		snapshot     *applicationapiv1alpha1.Snapshot
+		application  *applicationapiv1alpha1.Application
+	)
+
+	BeforeAll(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+
+		snapshot = &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snapshot-sample",
+				Namespace: "default",
+			},
+		}
+	})
+
+	It("leaves tracing disabled when no OTLP endpoint is configured", func() {
+		shutdown, err := tracing.InitTracerProvider(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shutdown(context.Background())).To(Succeed())
+	})
+
+	It("records a span for the reconciliation and each of the operations", func() {
+		adapter := &testAdapter{}
+		ctx, span := tracing.StartReconcileSpan(context.Background(), "snapshot",
+			map[string]client.Object{"snapshot": snapshot, "application": application})
+		_, err := controller.ReconcileHandler(tracing.TraceOperations(ctx, []controller.Operation{
+			adapter.EnsureSomethingIsDone,
+			adapter.EnsureSomethingFails,
+		}))
+		span.End()
+		Expect(err).To(HaveOccurred())
+
+		spans := spanRecorder.Ended()
+		Expect(spans).To(HaveLen(3))
+
+		Expect(spans[0].Name()).To(Equal("EnsureSomethingIsDone"))
+		Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[2].SpanContext().SpanID()))
+
+		Expect(spans[1].Name()).To(Equal("EnsureSomethingFails"))
+		Expect(spans[1].Status().Code).To(Equal(codes.Error))
+		Expect(spans[1].Attributes()).To(ContainElement(attribute.Bool("operation.requeue", true)))
+
+		Expect(spans[2].Name()).To(Equal("snapshot.Reconcile"))
+		Expect(spans[2].Attributes()).To(ConsistOf(
+			attribute.String("snapshot.namespace", "default"),
+			attribute.String("snapshot.name", "snapshot-sample"),
+		))
+	})
+})
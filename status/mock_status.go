@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	v1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -54,6 +55,20 @@ func (mr *MockStatusInterfaceMockRecorder) GetReporter(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReporter", reflect.TypeOf((*MockStatusInterface)(nil).GetReporter), arg0)
 }
 
+// ReportBuildPipelineRunFailure mocks base method.
+func (m *MockStatusInterface) ReportBuildPipelineRunFailure(arg0 context.Context, arg1 ReporterInterface, arg2 *v1alpha1.Snapshot, arg3 *v1.PipelineRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportBuildPipelineRunFailure", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportBuildPipelineRunFailure indicates an expected call of ReportBuildPipelineRunFailure.
+func (mr *MockStatusInterfaceMockRecorder) ReportBuildPipelineRunFailure(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportBuildPipelineRunFailure", reflect.TypeOf((*MockStatusInterface)(nil).ReportBuildPipelineRunFailure), arg0, arg1, arg2, arg3)
+}
+
 // ReportSnapshotStatus mocks base method.
 func (m *MockStatusInterface) ReportSnapshotStatus(arg0 context.Context, arg1 ReporterInterface, arg2 *v1alpha1.Snapshot) error {
 	m.ctrl.T.Helper()
@@ -20,6 +20,9 @@ package status
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -54,6 +57,25 @@ type TestReport struct {
 	CompletionTime *time.Time
 	// pipelineRun Name
 	TestPipelineRunName string
+	// failed test cases reported in structured test results
+	FailedTestCases []intgteststat.TestCaseDetail
+	// ObserveOnly is set when the test doesn't gate the snapshot
+	ObserveOnly bool
+}
+
+// Digest returns the digest of the content of the test report reported to the git provider,
+// the same digest means that reporting the test report again wouldn't change anything
+func (r TestReport) Digest() string {
+	content, _ := json.Marshal(struct {
+		FullName            string
+		Status              string
+		Summary             string
+		Text                string
+		TestPipelineRunName string
+		FailedTestCases     []intgteststat.TestCaseDetail
+	}{r.FullName, r.Status.String(), r.Summary, r.Text, r.TestPipelineRunName, r.FailedTestCases})
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:16])
 }
 
 type ReporterInterface interface {
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+)
+
+// DryRunReporter wraps the reporter detected for a Snapshot when the operator runs in dry-run mode, logging the
+// test reports instead of sending them to the git provider.
+type DryRunReporter struct {
+	reporter ReporterInterface
+	logger   logr.Logger
+}
+
+// NewDryRunReporter returns a DryRunReporter wrapping the given reporter.
+func NewDryRunReporter(reporter ReporterInterface, logger logr.Logger) *DryRunReporter {
+	return &DryRunReporter{reporter: reporter, logger: logger}
+}
+
+// check if interface has been correctly implemented
+var _ ReporterInterface = (*DryRunReporter)(nil)
+
+// Detect returns whether the wrapped reporter can be used with the snapshot.
+func (r *DryRunReporter) Detect(snapshot *applicationapiv1alpha1.Snapshot) bool {
+	return r.reporter.Detect(snapshot)
+}
+
+// Initialize doesn't authenticate to the git provider, the reports are only logged.
+func (r *DryRunReporter) Initialize(_ context.Context, snapshot *applicationapiv1alpha1.Snapshot) error {
+	r.logger.Info("Dry-run: skipping the initialization of the reporter",
+		"reporter", r.GetReporterName(), "snapshot.Namespace", snapshot.Namespace, "snapshot.Name", snapshot.Name)
+	return nil
+}
+
+// GetReporterName returns the name of the wrapped reporter.
+func (r *DryRunReporter) GetReporterName() string {
+	return r.reporter.GetReporterName()
+}
+
+// ReportStatus logs the test report which would be sent to the git provider.
+func (r *DryRunReporter) ReportStatus(_ context.Context, report TestReport) error {
+	r.logger.Info("Dry-run: skipping the report of the integration test status",
+		"reporter", r.GetReporterName(), "snapshot.Name", report.SnapshotName, "scenario", report.ScenarioName,
+		"status", report.Status.String(), "summary", report.Summary)
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/tonglil/buflogr"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	"github.com/konflux-ci/integration-service/status"
+)
+
+var _ = Describe("DryRunReporter", func() {
+
+	It("logs the reports instead of sending them to the git provider", func() {
+		var buf bytes.Buffer
+		mockReporter := status.NewMockReporterInterface(gomock.NewController(GinkgoT()))
+		mockReporter.EXPECT().GetReporterName().Return("mocked-reporter").AnyTimes()
+		mockReporter.EXPECT().Detect(gomock.Any()).Return(true)
+		// neither Initialize nor ReportStatus are called on the wrapped reporter
+
+		snapshot := &applicationapiv1alpha1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "snapshot-sample", Namespace: "default"},
+		}
+		reporter := status.NewDryRunReporter(mockReporter, buflogr.NewWithBuffer(&buf))
+		Expect(reporter.Detect(snapshot)).To(BeTrue())
+		Expect(reporter.GetReporterName()).To(Equal("mocked-reporter"))
+		Expect(reporter.Initialize(context.Background(), snapshot)).To(Succeed())
+		Expect(reporter.ReportStatus(context.Background(), status.TestReport{
+			SnapshotName: snapshot.Name,
+			ScenarioName: "scenario-sample",
+			Status:       integrationteststatus.IntegrationTestStatusTestPassed,
+			Summary:      "Integration test for snapshot snapshot-sample and scenario scenario-sample has passed",
+		})).To(Succeed())
+
+		Expect(buf.String()).To(ContainSubstring("Dry-run: skipping the report of the integration test status"))
+		Expect(buf.String()).To(ContainSubstring("scenario scenario-sample status TestPassed"))
+	})
+})
@@ -168,6 +168,10 @@ func (cru *CheckRunStatusUpdater) createCheckRunAdapterForSnapshot(report TestRe
 	if err != nil {
 		return nil, fmt.Errorf("unknown status %s for integrationTestScenario %s and snapshot %s/%s", report.Status, report.ScenarioName, snapshot.Namespace, snapshot.Name)
 	}
+	if report.ObserveOnly && conclusion != "" {
+		// the observe-only tests don't gate the snapshot, so they don't block the pull request either
+		conclusion = gitops.IntegrationTestStatusNeutralGithub
+	}
 
 	title, err := generateCheckRunTitle(report.Status)
 	if err != nil {
@@ -206,9 +210,43 @@ func (cru *CheckRunStatusUpdater) createCheckRunAdapterForSnapshot(report TestRe
 		cra.CompletionTime = *complete
 	}
 
+	cra.Annotations = generateCheckRunAnnotations(report.FailedTestCases)
+
 	return cra, nil
 }
 
+// generateCheckRunAnnotations creates check run annotations for the failed test cases which reported their source file,
+// GitHub requires a path for each annotation so the test cases without one are skipped
+func generateCheckRunAnnotations(testCases []intgteststat.TestCaseDetail) []*ghapi.CheckRunAnnotation {
+	var annotations []*ghapi.CheckRunAnnotation
+	for _, testCase := range testCases {
+		if testCase.File == "" {
+			continue
+		}
+		line := testCase.Line
+		if line < 1 {
+			line = 1
+		}
+		title := testCase.Name
+		if testCase.ClassName != "" {
+			title = fmt.Sprintf("%s.%s", testCase.ClassName, testCase.Name)
+		}
+		message := testCase.Message
+		if message == "" {
+			message = fmt.Sprintf("Test case %s", testCase.Status)
+		}
+		annotations = append(annotations, &ghapi.CheckRunAnnotation{
+			Path:            ghapi.String(testCase.File),
+			StartLine:       ghapi.Int(line),
+			EndLine:         ghapi.Int(line),
+			AnnotationLevel: ghapi.String("failure"),
+			Title:           ghapi.String(title),
+			Message:         ghapi.String(message),
+		})
+	}
+	return annotations
+}
+
 // UpdateStatus updates CheckRun status of PR
 func (cru *CheckRunStatusUpdater) UpdateStatus(ctx context.Context, report TestReport) error {
 	if cru.creds == nil {
@@ -335,6 +373,11 @@ func (csu *CommitStatusUpdater) createCommitStatusAdapterForSnapshot(report Test
 	if err != nil {
 		return nil, fmt.Errorf("unknown status %s for integrationTestScenario %s and snapshot %s/%s", report.Status, report.ScenarioName, snapshot.Namespace, snapshot.Name)
 	}
+	if report.ObserveOnly && report.Status.IsFinal() {
+		// the commit statuses have no neutral state, the observe-only tests are reported as successful not to block
+		// the pull request
+		state = gitops.IntegrationTestStatusSuccessGithub
+	}
 
 	if report.TestPipelineRunName == "" {
 		csu.logger.Info("TestPipelineRunName is not set for CommitStatus")
@@ -486,14 +529,19 @@ func generateCheckRunTitle(state intgteststat.IntegrationTestStatus) (string, er
 		title = "In Progress"
 	case intgteststat.IntegrationTestStatusEnvironmentProvisionError_Deprecated,
 		intgteststat.IntegrationTestStatusDeploymentError_Deprecated,
-		intgteststat.IntegrationTestStatusTestInvalid:
+		intgteststat.IntegrationTestStatusTestInvalid,
+		intgteststat.IntegrationTestStatusTestError:
 		title = "Errored"
 	case intgteststat.IntegrationTestStatusDeleted:
 		title = "Deleted"
 	case intgteststat.IntegrationTestStatusTestPassed:
 		title = "Succeeded"
+	case intgteststat.IntegrationTestStatusTestWarning:
+		title = "Succeeded with warnings"
 	case intgteststat.IntegrationTestStatusTestFail:
 		title = "Failed"
+	case intgteststat.IntegrationTestStatusSuspended:
+		title = "Suspended"
 	default:
 		return title, fmt.Errorf("unknown status")
 	}
@@ -510,10 +558,14 @@ func generateCheckRunConclusion(state intgteststat.IntegrationTestStatus) (strin
 	switch state {
 	case intgteststat.IntegrationTestStatusTestFail, intgteststat.IntegrationTestStatusEnvironmentProvisionError_Deprecated,
 		intgteststat.IntegrationTestStatusDeploymentError_Deprecated, intgteststat.IntegrationTestStatusDeleted,
-		intgteststat.IntegrationTestStatusTestInvalid:
+		intgteststat.IntegrationTestStatusTestInvalid, intgteststat.IntegrationTestStatusTestError:
 		conclusion = gitops.IntegrationTestStatusFailureGithub
 	case intgteststat.IntegrationTestStatusTestPassed:
 		conclusion = gitops.IntegrationTestStatusSuccessGithub
+	case intgteststat.IntegrationTestStatusTestWarning:
+		conclusion = gitops.IntegrationTestStatusNeutralGithub
+	case intgteststat.IntegrationTestStatusSuspended:
+		conclusion = gitops.IntegrationTestStatusSkippedGithub
 	case intgteststat.IntegrationTestStatusPending, intgteststat.IntegrationTestStatusInProgress:
 		conclusion = ""
 	default:
@@ -533,9 +585,11 @@ func generateGithubCommitState(state intgteststat.IntegrationTestStatus) (string
 	case intgteststat.IntegrationTestStatusTestFail:
 		commitState = gitops.IntegrationTestStatusFailureGithub
 	case intgteststat.IntegrationTestStatusEnvironmentProvisionError_Deprecated, intgteststat.IntegrationTestStatusDeploymentError_Deprecated,
-		intgteststat.IntegrationTestStatusDeleted, intgteststat.IntegrationTestStatusTestInvalid:
+		intgteststat.IntegrationTestStatusDeleted, intgteststat.IntegrationTestStatusTestInvalid,
+		intgteststat.IntegrationTestStatusTestError:
 		commitState = gitops.IntegrationTestStatusErrorGithub
-	case intgteststat.IntegrationTestStatusTestPassed:
+	case intgteststat.IntegrationTestStatusTestPassed, intgteststat.IntegrationTestStatusTestWarning,
+		intgteststat.IntegrationTestStatusSuspended:
 		commitState = gitops.IntegrationTestStatusSuccessGithub
 	case intgteststat.IntegrationTestStatusPending, intgteststat.IntegrationTestStatusInProgress:
 		commitState = gitops.IntegrationTestStatusPendingGithub
@@ -324,6 +324,9 @@ var _ = Describe("GitHubReporter", func() {
 			Entry("In progress", integrationteststatus.IntegrationTestStatusInProgress, "In Progress", ""),
 			Entry("Pending", integrationteststatus.IntegrationTestStatusPending, "Pending", ""),
 			Entry("Invalid", integrationteststatus.IntegrationTestStatusTestInvalid, "Errored", gitops.IntegrationTestStatusFailureGithub),
+			Entry("Warning", integrationteststatus.IntegrationTestStatusTestWarning, "Succeeded with warnings", gitops.IntegrationTestStatusNeutralGithub),
+			Entry("Error", integrationteststatus.IntegrationTestStatusTestError, "Errored", gitops.IntegrationTestStatusFailureGithub),
+			Entry("Suspended", integrationteststatus.IntegrationTestStatusSuspended, "Suspended", gitops.IntegrationTestStatusSkippedGithub),
 		)
 
 		It("check if all integration tests statuses are supported", func() {
@@ -337,6 +340,27 @@ var _ = Describe("GitHubReporter", func() {
 			}
 		})
 
+		It("reports the observe-only tests as neutral", func() {
+			Expect(reporter.ReportStatus(
+				context.TODO(),
+				status.TestReport{
+					ScenarioName: "scenario1",
+					Status:       integrationteststatus.IntegrationTestStatusTestFail,
+					ObserveOnly:  true,
+				})).To(Succeed())
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Title).To(Equal("Failed"))
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Conclusion).To(Equal(gitops.IntegrationTestStatusNeutralGithub))
+
+			Expect(reporter.ReportStatus(
+				context.TODO(),
+				status.TestReport{
+					ScenarioName: "scenario1",
+					Status:       integrationteststatus.IntegrationTestStatusInProgress,
+					ObserveOnly:  true,
+				})).To(Succeed())
+			Expect(mockGitHubClient.CreateCheckRunResult.cra.Conclusion).To(Equal(""))
+		})
+
 		It("reports all details of snapshot tests status via CheckRuns", func() {
 			now := time.Now()
 
@@ -364,6 +388,36 @@ var _ = Describe("GitHubReporter", func() {
 			Expect(mockGitHubClient.CreateCheckRunResult.cra.CompletionTime.IsZero()).To(BeFalse())
 		})
 
+		It("reports the failed test cases as CheckRun annotations", func() {
+			now := time.Now()
+
+			Expect(reporter.ReportStatus(
+				context.TODO(),
+				status.TestReport{
+					FullName:       "test-name",
+					ScenarioName:   "scenario1",
+					SnapshotName:   "snapshot-sample",
+					ComponentName:  "component-sample",
+					Status:         integrationteststatus.IntegrationTestStatusTestFail,
+					Summary:        "Integration test for snapshot snapshot-sample and scenario scenario1 has failed",
+					StartTime:      &now,
+					CompletionTime: &now,
+					FailedTestCases: []integrationteststatus.TestCaseDetail{
+						{Name: "TestFail", ClassName: "e2e", File: "test/e2e_test.go", Line: 12, Status: "failure", Message: "timed out"},
+						{Name: "TestWithoutFile", Status: "error"},
+					},
+				})).To(Succeed())
+			Expect(mockGitHubClient.CreateCheckRunResult.cra).NotTo(BeNil())
+			annotations := mockGitHubClient.CreateCheckRunResult.cra.Annotations
+			Expect(annotations).To(HaveLen(1))
+			Expect(annotations[0].GetPath()).To(Equal("test/e2e_test.go"))
+			Expect(annotations[0].GetStartLine()).To(Equal(12))
+			Expect(annotations[0].GetEndLine()).To(Equal(12))
+			Expect(annotations[0].GetAnnotationLevel()).To(Equal("failure"))
+			Expect(annotations[0].GetTitle()).To(Equal("e2e.TestFail"))
+			Expect(annotations[0].GetMessage()).To(Equal("timed out"))
+		})
+
 		It("reports all details of snapshot tests status via CheckRuns for a Snapshot without a component", func() {
 			now := time.Now()
 
@@ -542,8 +596,22 @@ var _ = Describe("GitHubReporter", func() {
 			Entry("In progress", integrationteststatus.IntegrationTestStatusInProgress, gitops.IntegrationTestStatusPendingGithub),
 			Entry("Pending", integrationteststatus.IntegrationTestStatusPending, gitops.IntegrationTestStatusPendingGithub),
 			Entry("Invalid", integrationteststatus.IntegrationTestStatusTestInvalid, gitops.IntegrationTestStatusErrorGithub),
+			Entry("Warning", integrationteststatus.IntegrationTestStatusTestWarning, gitops.IntegrationTestStatusSuccessGithub),
+			Entry("Error", integrationteststatus.IntegrationTestStatusTestError, gitops.IntegrationTestStatusErrorGithub),
+			Entry("Suspended", integrationteststatus.IntegrationTestStatusSuspended, gitops.IntegrationTestStatusSuccessGithub),
 		)
 
+		It("reports the finished observe-only tests as successful", func() {
+			Expect(reporter.ReportStatus(
+				context.TODO(),
+				status.TestReport{
+					ScenarioName: "scenario1",
+					Status:       integrationteststatus.IntegrationTestStatusTestFail,
+					ObserveOnly:  true,
+				})).To(Succeed())
+			Expect(mockGitHubClient.CreateCommitStatusResult.state).To(Equal(gitops.IntegrationTestStatusSuccessGithub))
+		})
+
 		It("check if all integration tests statuses are supported", func() {
 			for _, teststatus := range integrationteststatus.IntegrationTestStatusValues() {
 				Expect(reporter.ReportStatus(
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -137,6 +138,11 @@ func (r *GitLabReporter) setCommitStatus(report TestReport) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate gitlab state: %w", err)
 	}
+	if report.ObserveOnly && report.Status.IsFinal() {
+		// the commit statuses have no neutral state, the observe-only tests are reported as successful not to block
+		// the merge request
+		glState = gitlab.Success
+	}
 
 	opt := gitlab.SetCommitStatusOptions{
 		State:       gitlab.BuildStateValue(glState),
@@ -177,35 +183,59 @@ func (r *GitLabReporter) setCommitStatus(report TestReport) error {
 	return nil
 }
 
-// updateStatusInComment will create/update a comment in the MR which creates snapshot
+// updateStatusInComment will create/update the note in the MR which creates snapshot, keeping a single note
+// per snapshot with a section for each integration test scenario
 func (r *GitLabReporter) updateStatusInComment(report TestReport) error {
 	comment, err := FormatComment(report.Summary, report.Text)
 	if err != nil {
 		return fmt.Errorf("failed to generate comment for merge-request %d: %w", r.mergeRequest, err)
 	}
 
-	allNotes, _, err := r.client.Notes.ListMergeRequestNotes(r.targetProjectID, r.mergeRequest, nil)
+	allNotes, err := r.getAllMergeRequestNotes()
 	if err != nil {
 		return fmt.Errorf("error while getting all comments for merge-request %d: %w", r.mergeRequest, err)
 	}
-	existingCommentId := r.GetExistingNoteID(allNotes, report.ScenarioName, report.SnapshotName)
-	if existingCommentId == nil {
-		noteOptions := gitlab.CreateMergeRequestNoteOptions{Body: &comment}
+	existingNote := r.GetExistingNote(allNotes, report.ScenarioName, r.snapshot.Name)
+	if existingNote == nil {
+		body := FormatSnapshotNote("", r.snapshot.Name, report.ScenarioName, comment)
+		noteOptions := gitlab.CreateMergeRequestNoteOptions{Body: &body}
 		_, _, err := r.client.Notes.CreateMergeRequestNote(r.targetProjectID, r.mergeRequest, &noteOptions)
 		if err != nil {
 			return fmt.Errorf("error while creating comment for merge-request %d: %w", r.mergeRequest, err)
 		}
 	} else {
-		noteOptions := gitlab.UpdateMergeRequestNoteOptions{Body: &comment}
-		_, _, err := r.client.Notes.UpdateMergeRequestNote(r.targetProjectID, r.mergeRequest, *existingCommentId, &noteOptions)
+		body := FormatSnapshotNote(existingNote.Body, r.snapshot.Name, report.ScenarioName, comment)
+		if body == existingNote.Body {
+			r.logger.Info("the merge-request note is already up to date", "scenarioName", report.ScenarioName, "noteID", existingNote.ID)
+			return nil
+		}
+		noteOptions := gitlab.UpdateMergeRequestNoteOptions{Body: &body}
+		_, _, err := r.client.Notes.UpdateMergeRequestNote(r.targetProjectID, r.mergeRequest, existingNote.ID, &noteOptions)
 		if err != nil {
-			return fmt.Errorf("error while creating comment for merge-request %d: %w", r.mergeRequest, err)
+			return fmt.Errorf("error while updating comment for merge-request %d: %w", r.mergeRequest, err)
 		}
 	}
 
 	return nil
 }
 
+// getAllMergeRequestNotes returns the notes of all pages of the merge request
+func (r *GitLabReporter) getAllMergeRequestNotes() ([]*gitlab.Note, error) {
+	allNotes := []*gitlab.Note{}
+	opts := &gitlab.ListMergeRequestNotesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		notes, resp, err := r.client.Notes.ListMergeRequestNotes(r.targetProjectID, r.mergeRequest, opts)
+		if err != nil {
+			return nil, err
+		}
+		allNotes = append(allNotes, notes...)
+		if resp == nil || resp.NextPage == 0 {
+			return allNotes, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
 // GetExistingCommitStatus returns existing GitLab commit status that matches .
 func (r *GitLabReporter) GetExistingCommitStatus(commitStatuses []*gitlab.CommitStatus, statusName string) *gitlab.CommitStatus {
 	for _, commitStatus := range commitStatuses {
@@ -219,18 +249,97 @@ func (r *GitLabReporter) GetExistingCommitStatus(commitStatuses []*gitlab.Commit
 	return nil
 }
 
-// GetExistingNoteID returns existing GitLab note for the scenario of ref.
-func (r *GitLabReporter) GetExistingNoteID(notes []*gitlab.Note, scenarioName, snapshotName string) *int {
+// GetExistingNote returns the existing GitLab note of the snapshot, falling back to the note
+// created for the scenario of the snapshot before all scenarios were reported in a single note.
+func (r *GitLabReporter) GetExistingNote(notes []*gitlab.Note, scenarioName, snapshotName string) *gitlab.Note {
+	marker := snapshotNoteMarker(snapshotName)
+	for _, note := range notes {
+		if strings.Contains(note.Body, marker) {
+			r.logger.Info("found note of the snapshot", "snapshotName", snapshotName, "noteID", note.ID)
+			return note
+		}
+	}
 	for _, note := range notes {
 		if strings.Contains(note.Body, snapshotName) && strings.Contains(note.Body, scenarioName) {
-			r.logger.Info("found note ID with a matching scenarioName", "scenarioName", scenarioName, "noteID", &note.ID)
-			return &note.ID
+			r.logger.Info("found note ID with a matching scenarioName", "scenarioName", scenarioName, "noteID", note.ID)
+			return note
 		}
 	}
-	r.logger.Info("found no note with a matching scenarioName", "scenarioName", scenarioName)
+	r.logger.Info("found no note with a matching snapshotName", "snapshotName", snapshotName)
 	return nil
 }
 
+// GetExistingNoteID returns the ID of the existing GitLab note for the snapshot and its scenario.
+func (r *GitLabReporter) GetExistingNoteID(notes []*gitlab.Note, scenarioName, snapshotName string) *int {
+	note := r.GetExistingNote(notes, scenarioName, snapshotName)
+	if note == nil {
+		return nil
+	}
+	return &note.ID
+}
+
+// snapshotNoteMarker returns the hidden marker identifying the note of the snapshot
+func snapshotNoteMarker(snapshotName string) string {
+	return fmt.Sprintf("<!-- integration-service snapshot: %s -->", snapshotName)
+}
+
+// scenarioSectionMarkers returns the hidden markers delimiting the section of the scenario in the note of the snapshot
+func scenarioSectionMarkers(scenarioName string) (string, string) {
+	return fmt.Sprintf("<!-- integration-service scenario: %s -->", scenarioName),
+		fmt.Sprintf("<!-- /integration-service scenario: %s -->", scenarioName)
+}
+
+// FormatSnapshotNote returns the body of the note of the snapshot with the section of the scenario set to the comment,
+// keeping the sections of the other scenarios found in the existing body ordered by the scenario name
+func FormatSnapshotNote(existingBody, snapshotName, scenarioName, comment string) string {
+	sections := map[string]string{}
+	if strings.Contains(existingBody, snapshotNoteMarker(snapshotName)) {
+		sections = parseScenarioSections(existingBody)
+	}
+	sections[scenarioName] = comment
+
+	scenarioNames := make([]string, 0, len(sections))
+	for name := range sections {
+		scenarioNames = append(scenarioNames, name)
+	}
+	sort.Strings(scenarioNames)
+
+	var body strings.Builder
+	body.WriteString(snapshotNoteMarker(snapshotName))
+	for _, name := range scenarioNames {
+		start, end := scenarioSectionMarkers(name)
+		fmt.Fprintf(&body, "\n%s\n%s\n%s\n", start, sections[name], end)
+	}
+	return body.String()
+}
+
+// parseScenarioSections returns the comments of the scenario sections found in the body of the note of the snapshot
+func parseScenarioSections(body string) map[string]string {
+	sections := map[string]string{}
+	startPrefix, _ := scenarioSectionMarkers("")
+	startPrefix = strings.TrimSuffix(startPrefix, " -->")
+	for {
+		index := strings.Index(body, startPrefix)
+		if index < 0 {
+			return sections
+		}
+		body = body[index+len(startPrefix):]
+		nameEnd := strings.Index(body, " -->")
+		if nameEnd < 0 {
+			return sections
+		}
+		name := body[:nameEnd]
+		body = body[nameEnd+len(" -->"):]
+		_, end := scenarioSectionMarkers(name)
+		commentEnd := strings.Index(body, end)
+		if commentEnd < 0 {
+			return sections
+		}
+		sections[name] = strings.Trim(body[:commentEnd], "\n")
+		body = body[commentEnd+len(end):]
+	}
+}
+
 // ReportStatus reports test result to gitlab
 func (r *GitLabReporter) ReportStatus(ctx context.Context, report TestReport) error {
 	if r.client == nil {
@@ -263,14 +372,17 @@ func GenerateGitlabCommitState(state intgteststat.IntegrationTestStatus) (gitlab
 		glState = gitlab.Running
 	case intgteststat.IntegrationTestStatusEnvironmentProvisionError_Deprecated,
 		intgteststat.IntegrationTestStatusDeploymentError_Deprecated,
-		intgteststat.IntegrationTestStatusTestInvalid:
+		intgteststat.IntegrationTestStatusTestInvalid,
+		intgteststat.IntegrationTestStatusTestError:
 		glState = gitlab.Failed
 	case intgteststat.IntegrationTestStatusDeleted:
 		glState = gitlab.Canceled
-	case intgteststat.IntegrationTestStatusTestPassed:
+	case intgteststat.IntegrationTestStatusTestPassed, intgteststat.IntegrationTestStatusTestWarning:
 		glState = gitlab.Success
 	case intgteststat.IntegrationTestStatusTestFail:
 		glState = gitlab.Failed
+	case intgteststat.IntegrationTestStatusSuspended:
+		glState = gitlab.Skipped
 	default:
 		return glState, fmt.Errorf("unknown status %s", state)
 	}
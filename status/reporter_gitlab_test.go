@@ -24,6 +24,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
@@ -307,10 +308,66 @@ var _ = Describe("GitLabReporter", func() {
 			Expect(*existingNoteID).To(Equal(note.ID))
 		})
 
+		It("updates the existing note of the snapshot in place instead of creating a new one", func() {
+			existingComment, err := status.FormatComment("scenario2 passed", "scenario2 details")
+			Expect(err).ToNot(HaveOccurred())
+			existingBody := status.FormatSnapshotNote("", hasSnapshot.Name, "scenario2", existingComment)
+
+			updatedBody := ""
+			path := fmt.Sprintf("/projects/%s/merge_requests/%s/notes", targetProjectID, mergeRequest)
+			mux.HandleFunc(path, func(rw http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodGet))
+				jsonNotes, _ := json.Marshal([]gitlab.Note{{ID: 1, Body: "unrelated note"}, {ID: 789, Body: existingBody}})
+				fmt.Fprint(rw, string(jsonNotes))
+			})
+			mux.HandleFunc(path+"/789", func(rw http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPut))
+				bit, _ := io.ReadAll(r.Body)
+				options := gitlab.UpdateMergeRequestNoteOptions{}
+				Expect(json.Unmarshal(bit, &options)).To(Succeed())
+				updatedBody = *options.Body
+				fmt.Fprintf(rw, "{}")
+			})
+			muxCommitStatusPost(mux, sourceProjectID, digest, "")
+
+			Expect(reporter.ReportStatus(
+				context.TODO(),
+				status.TestReport{
+					FullName:     "fullname/scenario1",
+					ScenarioName: "scenario1",
+					SnapshotName: hasSnapshot.Name,
+					Status:       integrationteststatus.IntegrationTestStatusTestFail,
+					Summary:      "scenario1 failed",
+					Text:         "scenario1 details",
+				})).To(Succeed())
+			Expect(updatedBody).To(ContainSubstring("scenario1 failed"))
+			Expect(updatedBody).To(ContainSubstring("scenario2 passed"))
+			Expect(strings.Index(updatedBody, "scenario1 failed")).To(BeNumerically("<", strings.Index(updatedBody, "scenario2 passed")))
+		})
+
 	})
 
 	Describe("Test helper functions", func() {
 
+		It("keeps a single section per scenario in the note of the snapshot", func() {
+			body := status.FormatSnapshotNote("", "snapshot-sample", "scenario2", "### scenario2 is running")
+			Expect(body).To(HavePrefix("<!-- integration-service snapshot: snapshot-sample -->"))
+			Expect(body).To(ContainSubstring("### scenario2 is running"))
+
+			body = status.FormatSnapshotNote(body, "snapshot-sample", "scenario1", "### scenario1 passed")
+			body = status.FormatSnapshotNote(body, "snapshot-sample", "scenario2", "### scenario2 failed")
+			Expect(body).To(Equal("<!-- integration-service snapshot: snapshot-sample -->\n" +
+				"<!-- integration-service scenario: scenario1 -->\n### scenario1 passed\n<!-- /integration-service scenario: scenario1 -->\n\n" +
+				"<!-- integration-service scenario: scenario2 -->\n### scenario2 failed\n<!-- /integration-service scenario: scenario2 -->\n"))
+			Expect(status.FormatSnapshotNote(body, "snapshot-sample", "scenario2", "### scenario2 failed")).To(Equal(body))
+		})
+
+		It("replaces the notes not created for all the scenarios of the snapshot", func() {
+			body := status.FormatSnapshotNote("### snapshot-sample scenario1 passed", "snapshot-sample", "scenario1", "### scenario1 failed")
+			Expect(body).NotTo(ContainSubstring("passed"))
+			Expect(body).To(ContainSubstring("### scenario1 failed"))
+		})
+
 		DescribeTable(
 			"reports correct gitlab statuses from test statuses",
 			func(teststatus integrationteststatus.IntegrationTestStatus, glState gitlab.BuildStateValue) {
@@ -327,6 +384,9 @@ var _ = Describe("GitLabReporter", func() {
 			Entry("In progress", integrationteststatus.IntegrationTestStatusInProgress, gitlab.Running),
 			Entry("Pending", integrationteststatus.IntegrationTestStatusPending, gitlab.Pending),
 			Entry("Invalid", integrationteststatus.IntegrationTestStatusTestInvalid, gitlab.Failed),
+			Entry("Warning", integrationteststatus.IntegrationTestStatusTestWarning, gitlab.Success),
+			Entry("Error", integrationteststatus.IntegrationTestStatusTestError, gitlab.Failed),
+			Entry("Suspended", integrationteststatus.IntegrationTestStatusSuspended, gitlab.Skipped),
 		)
 
 		It("check if all integration tests statuses are supported", func() {
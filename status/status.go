@@ -29,18 +29,24 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/konflux-ci/integration-service/gitops"
 	"github.com/konflux-ci/integration-service/helpers"
+	"github.com/konflux-ci/integration-service/pkg/dryrun"
 	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
 	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ScenarioReportStatus keep report status of git provider for the particular scenario
 type ScenarioReportStatus struct {
 	LastUpdateTime *time.Time `json:"lastUpdateTime"`
+	// LastReportedStatus is the test status last reported to the git provider
+	LastReportedStatus *intgteststat.IntegrationTestStatus `json:"lastReportedStatus,omitempty"`
+	// LastReportedDigest is the digest of the test report last reported to the git provider
+	LastReportedDigest string `json:"lastReportedDigest,omitempty"`
 }
 
 // SnapshotReportStatus keep report status of git provider for the snapshot
@@ -72,6 +78,29 @@ func (srs *SnapshotReportStatus) IsNewer(scenarioName string, t time.Time) bool
 	return true
 }
 
+// SetLastReported records the given test report as the last one reported to the git provider for the given scenario
+func (srs *SnapshotReportStatus) SetLastReported(scenarioName string, report TestReport) {
+	srs.dirty = true
+	scenario, ok := srs.Scenarios[scenarioName]
+	if !ok {
+		scenario = &ScenarioReportStatus{}
+		srs.Scenarios[scenarioName] = scenario
+	}
+	reportStatus := report.Status
+	scenario.LastReportedStatus = &reportStatus
+	scenario.LastReportedDigest = report.Digest()
+}
+
+// IsReported returns true if the given test report is the same as the one last reported to the git provider for the given scenario
+func (srs *SnapshotReportStatus) IsReported(scenarioName string, report TestReport) bool {
+	if scenario, ok := srs.Scenarios[scenarioName]; ok {
+		return scenario.LastReportedDigest != "" && scenario.LastReportedDigest == report.Digest()
+	}
+
+	// no record, it was never reported
+	return false
+}
+
 // ToAnnotationString exports data in format for annotation
 func (srs *SnapshotReportStatus) ToAnnotationString() (string, error) {
 	byteVar, err := json.Marshal(srs)
@@ -179,9 +208,13 @@ func MigrateSnapshotToReportStatus(s *applicationapiv1alpha1.Snapshot, testStatu
 	annotations[gitops.SnapshotStatusReportAnnotation], _ = srs.ToAnnotationString()
 }
 
+// BuildPipelineRunReportName is the name under which the failures of build pipelineRuns are reported to the git provider
+const BuildPipelineRunReportName = "build"
+
 type StatusInterface interface {
 	GetReporter(*applicationapiv1alpha1.Snapshot) ReporterInterface
 	ReportSnapshotStatus(context.Context, ReporterInterface, *applicationapiv1alpha1.Snapshot) error
+	ReportBuildPipelineRunFailure(context.Context, ReporterInterface, *applicationapiv1alpha1.Snapshot, *tektonv1.PipelineRun) error
 }
 
 type Status struct {
@@ -201,6 +234,16 @@ func NewStatus(logger logr.Logger, client client.Client) *Status {
 
 // GetReporter returns reporter to process snapshot using the right git provider, nil means no suitable reporter found
 func (s *Status) GetReporter(snapshot *applicationapiv1alpha1.Snapshot) ReporterInterface {
+	reporter := s.detectReporter(snapshot)
+	if reporter != nil && dryrun.IsEnabled() {
+		return NewDryRunReporter(reporter, s.logger)
+	}
+
+	return reporter
+}
+
+// detectReporter returns the reporter of the git provider of the snapshot, or nil if there's none.
+func (s *Status) detectReporter(snapshot *applicationapiv1alpha1.Snapshot) ReporterInterface {
 	githubReporter := NewGitHubReporter(s.logger, s.client)
 	if githubReporter.Detect(snapshot) {
 		return githubReporter
@@ -261,6 +304,12 @@ func (s *Status) ReportSnapshotStatus(ctx context.Context, reporter ReporterInte
 			}
 			return fmt.Errorf("failed to generate test report: %w", reportErr)
 		}
+		if srs.IsReported(integrationTestStatusDetail.ScenarioName, *testReport) {
+			// the same report was already sent to the git provider, e.g. before a failed write of the metadata
+			s.logger.Info("Integration Test status was already reported, skipping", "scenario.Name", integrationTestStatusDetail.ScenarioName)
+			srs.SetLastUpdateTime(integrationTestStatusDetail.ScenarioName, integrationTestStatusDetail.LastUpdateTime)
+			continue
+		}
 		if reportStatusErr := reporter.ReportStatus(ctx, *testReport); reportStatusErr != nil {
 			if writeErr := WriteSnapshotReportStatus(ctx, s.client, snapshot, srs); writeErr != nil { // try to write what was already written
 				return fmt.Errorf("failed to report status AND write snapshot report status metadata: %w", errors.Join(reportStatusErr, writeErr))
@@ -268,6 +317,7 @@ func (s *Status) ReportSnapshotStatus(ctx context.Context, reporter ReporterInte
 			return fmt.Errorf("failed to update status: %w", reportStatusErr)
 		}
 		srs.SetLastUpdateTime(integrationTestStatusDetail.ScenarioName, integrationTestStatusDetail.LastUpdateTime)
+		srs.SetLastReported(integrationTestStatusDetail.ScenarioName, *testReport)
 	}
 	if err := WriteSnapshotReportStatus(ctx, s.client, snapshot, srs); err != nil {
 		return fmt.Errorf("failed to write snapshot report status metadata: %w", err)
@@ -278,6 +328,56 @@ func (s *Status) ReportSnapshotStatus(ctx context.Context, reporter ReporterInte
 	return nil
 }
 
+// ReportBuildPipelineRunFailure reports the failure of the build pipelineRun into the Pull Request or commit which triggered it,
+// so that it's clear that no Snapshot will be created and that no integration tests will run for it. The given snapshot isn't
+// expected to exist, it only carries the git provider metadata copied from the build pipelineRun.
+func (s *Status) ReportBuildPipelineRunFailure(ctx context.Context, reporter ReporterInterface, snapshot *applicationapiv1alpha1.Snapshot, pipelineRun *tektonv1.PipelineRun) error {
+	if err := reporter.Initialize(ctx, snapshot); err != nil {
+		s.logger.Error(err, "Failed to initialize reporter", "reporter", reporter.GetReporterName())
+		return fmt.Errorf("failed to initialize reporter: %w", err)
+	}
+	s.logger.Info("Reporter initialized", "reporter", reporter.GetReporterName())
+
+	if err := reporter.ReportStatus(ctx, GenerateBuildPipelineRunFailureReport(snapshot, pipelineRun)); err != nil {
+		return fmt.Errorf("failed to report build pipelineRun failure: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateBuildPipelineRunFailureReport generates TestReport describing the failure of the build pipelineRun
+func GenerateBuildPipelineRunFailureReport(snapshot *applicationapiv1alpha1.Snapshot, pipelineRun *tektonv1.PipelineRun) TestReport {
+	componentName := snapshot.Labels[gitops.SnapshotComponentLabel]
+
+	fullName := fmt.Sprintf("%s / %s", getConsoleName(), BuildPipelineRunReportName)
+	if componentName != "" {
+		fullName = fmt.Sprintf("%s / %s", fullName, componentName)
+	}
+
+	text := fmt.Sprintf("Build pipelineRun %s has failed, no Snapshot will be created and no integration tests will run for it.", pipelineRun.Name)
+	if condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded); condition != nil && condition.Message != "" {
+		text = fmt.Sprintf("%s\n\n%s", text, condition.Message)
+	}
+
+	report := TestReport{
+		Text:                text,
+		FullName:            fullName,
+		ScenarioName:        BuildPipelineRunReportName,
+		SnapshotName:        snapshot.Name,
+		ComponentName:       componentName,
+		Status:              intgteststat.IntegrationTestStatusTestFail,
+		Summary:             fmt.Sprintf("Build pipelineRun %s of component %s has failed", pipelineRun.Name, componentName),
+		TestPipelineRunName: pipelineRun.Name,
+	}
+	if pipelineRun.Status.StartTime != nil {
+		report.StartTime = &pipelineRun.Status.StartTime.Time
+	}
+	if pipelineRun.Status.CompletionTime != nil {
+		report.CompletionTime = &pipelineRun.Status.CompletionTime.Time
+	}
+	return report
+}
+
 // generateTestReport generates TestReport to be used by all reporters
 func (s *Status) generateTestReport(ctx context.Context, detail intgteststat.IntegrationTestStatusDetail, snapshot *applicationapiv1alpha1.Snapshot) (*TestReport, error) {
 	text, err := s.generateText(ctx, detail, snapshot.Namespace)
@@ -289,6 +389,9 @@ func (s *Status) generateTestReport(ctx context.Context, detail intgteststat.Int
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate summary message: %w", err)
 	}
+	if detail.ObserveOnly {
+		summary += " (observe-only, it doesn't gate the snapshot)"
+	}
 
 	consoleName := getConsoleName()
 
@@ -308,19 +411,28 @@ func (s *Status) generateTestReport(ctx context.Context, detail intgteststat.Int
 		StartTime:           detail.StartTime,
 		CompletionTime:      detail.CompletionTime,
 		TestPipelineRunName: detail.TestPipelineRunName,
+		ObserveOnly:         detail.ObserveOnly,
+	}
+	if detail.TestResultsSummary != nil {
+		report.FailedTestCases = detail.TestResultsSummary.FailedTestCases
 	}
 	return &report, nil
 }
 
 // generateText generates a text with details for the given state
 func (s *Status) generateText(ctx context.Context, integrationTestStatusDetail intgteststat.IntegrationTestStatusDetail, namespace string) (string, error) {
-	if integrationTestStatusDetail.Status == intgteststat.IntegrationTestStatusTestPassed || integrationTestStatusDetail.Status == intgteststat.IntegrationTestStatusTestFail {
+	if integrationTestStatusDetail.Status.IsPassed() || integrationTestStatusDetail.Status == intgteststat.IntegrationTestStatusTestFail ||
+		integrationTestStatusDetail.Status == intgteststat.IntegrationTestStatusTestError {
 		pipelineRunName := integrationTestStatusDetail.TestPipelineRunName
 		pipelineRun := &tektonv1.PipelineRun{}
 		err := s.client.Get(ctx, types.NamespacedName{
 			Namespace: namespace,
 			Name:      pipelineRunName,
 		}, pipelineRun)
+		if apierrors.IsNotFound(err) {
+			// the pipelineRun may have been pruned already, try to fetch it from Tekton Results
+			pipelineRun, err = helpers.GetPrunedPipelineRun(ctx, namespace, pipelineRunName, err)
+		}
 
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -369,6 +481,12 @@ func GenerateSummary(state intgteststat.IntegrationTestStatus, snapshotName, sce
 		statusDesc = "has failed"
 	case intgteststat.IntegrationTestStatusTestInvalid:
 		statusDesc = "is invalid"
+	case intgteststat.IntegrationTestStatusTestWarning:
+		statusDesc = "has passed with warnings"
+	case intgteststat.IntegrationTestStatusTestError:
+		statusDesc = "experienced an error"
+	case intgteststat.IntegrationTestStatusSuspended:
+		statusDesc = "was suspended before the pipelineRun could finish"
 	default:
 		return summary, fmt.Errorf("unknown status")
 	}
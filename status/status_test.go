@@ -29,6 +29,7 @@ import (
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"go.uber.org/mock/gomock"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/konflux-ci/integration-service/pkg/integrationteststatus"
@@ -383,6 +384,56 @@ var _ = Describe("Status Adapter", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("doesn't report the status again when the same report was already reported", func() {
+		os.Setenv("CONSOLE_NAME", "Konflux Staging")
+		hasSnapshot.Annotations["test.appstudio.openshift.io/status"] = "[{\"scenario\":\"scenario1\",\"status\":\"InProgress\",\"testPipelineRunName\":\"test-pipelinerun\",\"startTime\":\"2023-07-26T16:57:49+02:00\",\"lastUpdateTime\":\"2023-08-26T17:57:50+02:00\",\"details\":\"Test in progress\"}]"
+		reportedTestReport := status.TestReport{
+			FullName:            "Konflux Staging / scenario1 / component-sample",
+			Text:                "Test in progress",
+			Summary:             "Integration test for snapshot snapshot-sample and scenario scenario1 is in progress",
+			Status:              integrationteststatus.IntegrationTestStatusInProgress,
+			TestPipelineRunName: "test-pipelinerun",
+		}
+		srs, err := status.NewSnapshotReportStatus("{\"scenarios\":{\"scenario1\":{\"lastUpdateTime\":\"2023-08-26T17:57:49+02:00\"}}}")
+		Expect(err).NotTo(HaveOccurred())
+		srs.SetLastReported("scenario1", reportedTestReport)
+		annotation, err := srs.ToAnnotationString()
+		Expect(err).NotTo(HaveOccurred())
+		hasSnapshot.Annotations["test.appstudio.openshift.io/git-reporter-status"] = annotation
+
+		mockReporter.EXPECT().Initialize(gomock.Any(), gomock.Any()).Times(1)
+		mockReporter.EXPECT().ReportStatus(gomock.Any(), gomock.Any()).Times(0) // the same report was already reported
+
+		st := status.NewStatus(logr.Discard(), mockK8sClient)
+		err = st.ReportSnapshotStatus(context.Background(), mockReporter, hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+
+		// the last update time is still recorded so that the status isn't processed again
+		srs, err = status.NewSnapshotReportStatusFromSnapshot(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(srs.IsNewer("scenario1", time.Date(2023, 8, 26, 15, 57, 50, 0, time.UTC))).To(BeFalse())
+	})
+
+	It("records the reported status of the test scenario", func() {
+		hasSnapshot.Annotations["test.appstudio.openshift.io/status"] = "[{\"scenario\":\"scenario1\",\"status\":\"TestFail\",\"testPipelineRunName\":\"test-pipelinerun\",\"startTime\":\"2023-07-26T16:57:49+02:00\",\"completionTime\":\"2023-07-26T17:57:49+02:00\",\"lastUpdateTime\":\"2023-08-26T17:57:50+02:00\",\"details\":\"failed\"}]"
+		var reported status.TestReport
+		mockReporter.EXPECT().Initialize(gomock.Any(), gomock.Any()).Times(1)
+		mockReporter.EXPECT().ReportStatus(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, report status.TestReport) error {
+			reported = report
+			return nil
+		}).Times(1)
+
+		st := status.NewStatus(logr.Discard(), mockK8sClient)
+		err := st.ReportSnapshotStatus(context.Background(), mockReporter, hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+
+		srs, err := status.NewSnapshotReportStatusFromSnapshot(hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(srs.Scenarios).To(HaveKey("scenario1"))
+		Expect(*srs.Scenarios["scenario1"].LastReportedStatus).To(Equal(integrationteststatus.IntegrationTestStatusTestFail))
+		Expect(srs.IsReported("scenario1", reported)).To(BeTrue())
+	})
+
 	It("report expected textual data for InProgress test scenario", func() {
 		os.Setenv("CONSOLE_NAME", "Konflux Staging")
 		hasSnapshot.Annotations["test.appstudio.openshift.io/status"] = "[{\"scenario\":\"scenario1\",\"status\":\"InProgress\",\"testPipelineRunName\":\"test-pipelinerun\",\"startTime\":\"2023-07-26T16:57:49+02:00\",\"lastUpdateTime\":\"2023-08-26T17:57:50+02:00\",\"details\":\"Test in progress\"}]"
@@ -468,8 +519,75 @@ var _ = Describe("Status Adapter", func() {
 		Entry("Pending", integrationteststatus.IntegrationTestStatusPending, "is pending"),
 		Entry("In progress", integrationteststatus.IntegrationTestStatusInProgress, "is in progress"),
 		Entry("Invalid", integrationteststatus.IntegrationTestStatusTestInvalid, "is invalid"),
+		Entry("Warning", integrationteststatus.IntegrationTestStatusTestWarning, "has passed with warnings"),
+		Entry("Error", integrationteststatus.IntegrationTestStatusTestError, "experienced an error"),
+		Entry("Suspended", integrationteststatus.IntegrationTestStatusSuspended, "was suspended before the pipelineRun could finish"),
 	)
 
+	It("reports the observe-only tests as such", func() {
+		hasSnapshot.Annotations["test.appstudio.openshift.io/status"] = "[{\"scenario\":\"scenario1\",\"status\":\"TestFail\",\"testPipelineRunName\":\"test-pipelinerun\",\"observeOnly\":true,\"startTime\":\"2023-07-26T16:57:49+02:00\",\"completionTime\":\"2023-07-26T17:57:49+02:00\",\"lastUpdateTime\":\"2023-08-26T17:57:55+02:00\",\"details\":\"failed\"}]"
+
+		mockReporter.EXPECT().Initialize(gomock.Any(), gomock.Any()).Times(1)
+		mockReporter.EXPECT().ReportStatus(gomock.Any(), HasSummary(
+			"Integration test for snapshot snapshot-sample and scenario scenario1 has failed (observe-only, it doesn't gate the snapshot)")).Times(1)
+
+		st := status.NewStatus(logr.Discard(), mockK8sClient)
+		err := st.ReportSnapshotStatus(context.Background(), mockReporter, hasSnapshot)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reports the failure of the build pipelineRun", func() {
+		ts, err := time.Parse(time.RFC3339, "2023-07-26T16:57:49+02:00")
+		Expect(err).NotTo(HaveOccurred())
+		tc, err := time.Parse(time.RFC3339, "2023-07-26T17:57:49+02:00")
+		Expect(err).NotTo(HaveOccurred())
+		buildPipelineRun := &tektonv1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "build-pipelinerun",
+				Namespace: "default",
+			},
+			Status: tektonv1.PipelineRunStatus{
+				PipelineRunStatusFields: tektonv1.PipelineRunStatusFields{
+					StartTime:      &metav1.Time{Time: ts},
+					CompletionTime: &metav1.Time{Time: tc},
+				},
+			},
+		}
+		buildPipelineRun.Status.SetCondition(&apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  "False",
+			Message: "Tasks Completed: 1 (Failed: 1, Cancelled 0), Skipped: 2",
+		})
+
+		expectedTestReport := status.TestReport{
+			FullName:            "Red Hat Konflux / build / component-sample",
+			ScenarioName:        status.BuildPipelineRunReportName,
+			SnapshotName:        "snapshot-sample",
+			ComponentName:       "component-sample",
+			Text:                "Build pipelineRun build-pipelinerun has failed, no Snapshot will be created and no integration tests will run for it.\n\nTasks Completed: 1 (Failed: 1, Cancelled 0), Skipped: 2",
+			Summary:             "Build pipelineRun build-pipelinerun of component component-sample has failed",
+			Status:              integrationteststatus.IntegrationTestStatusTestFail,
+			StartTime:           &ts,
+			CompletionTime:      &tc,
+			TestPipelineRunName: "build-pipelinerun",
+		}
+		mockReporter.EXPECT().Initialize(gomock.Any(), gomock.Any()).Times(1)
+		mockReporter.EXPECT().ReportStatus(gomock.Any(), gomock.Eq(expectedTestReport)).Times(1)
+
+		st := status.NewStatus(logr.Discard(), mockK8sClient)
+		err = st.ReportBuildPipelineRunFailure(context.Background(), mockReporter, hasSnapshot, buildPipelineRun)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("doesn't report the failure of the build pipelineRun when the reporter can't be initialized", func() {
+		mockReporter.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(fmt.Errorf("failed")).Times(1)
+		mockReporter.EXPECT().ReportStatus(gomock.Any(), gomock.Any()).Times(0)
+
+		st := status.NewStatus(logr.Discard(), mockK8sClient)
+		err := st.ReportBuildPipelineRunFailure(context.Background(), mockReporter, hasSnapshot, pipelineRun)
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("check if GenerateSummary supports all integration test statuses", func() {
 		for _, teststatus := range integrationteststatus.IntegrationTestStatusValues() {
 			_, err := status.GenerateSummary(teststatus, "yolo", "yolo")
@@ -574,6 +692,25 @@ var _ = Describe("Status Adapter", func() {
 			Expect(newSRS.Scenarios).To(HaveLen(1))
 		})
 
+		It("Detect already reported test report", func() {
+			report := status.TestReport{
+				FullName: "Red Hat Konflux / test-scenario",
+				Status:   integrationteststatus.IntegrationTestStatusInProgress,
+				Summary:  "Integration test is in progress",
+			}
+			Expect(hasSRS.IsReported(scenarioName, report)).To(BeFalse())
+
+			hasSRS.SetLastReported(scenarioName, report)
+			Expect(hasSRS.IsDirty()).To(BeTrue())
+			Expect(hasSRS.IsReported(scenarioName, report)).To(BeTrue())
+			// the times of the report don't matter
+			report.StartTime = &now
+			Expect(hasSRS.IsReported(scenarioName, report)).To(BeTrue())
+
+			report.Status = integrationteststatus.IntegrationTestStatusTestPassed
+			Expect(hasSRS.IsReported(scenarioName, report)).To(BeFalse())
+		})
+
 		It("Can read annotation from snapshot", func() {
 			hasSnapshot.Annotations["test.appstudio.openshift.io/git-reporter-status"] = "{\"scenarios\":{\"test-scenario\":{\"lastUpdateTime\":\"2023-08-26T17:57:49+02:00\"}}}"
 			newSRS, err := status.NewSnapshotReportStatusFromSnapshot(hasSnapshot)
@@ -27,17 +27,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// AnnotateBuildPipelineRun sets annotation for a build pipelineRun in defined context and returns that pipeline
+// AnnotateBuildPipelineRun sets annotation for a build pipelineRun in defined context and returns that pipeline.
+// The annotation is server-side applied, so the fields of the pipelineRun owned by the build controllers are left alone.
 func AnnotateBuildPipelineRun(ctx context.Context, pipelineRun *tektonv1.PipelineRun, key, value string, cl client.Client) error {
-	patch := client.MergeFrom(pipelineRun.DeepCopy())
-
-	_ = metadata.SetAnnotation(&pipelineRun.ObjectMeta, key, value)
-
-	err := cl.Patch(ctx, pipelineRun, patch)
-	if err != nil {
-		return err
-	}
-	return nil
+	return h.ApplyMetadata(ctx, cl, pipelineRun, nil, map[string]string{key: value})
 }
 
 // AnnotateBuildPipelineRunWithCreateSnapshotAnnotation sets annotation test.appstudio.openshift.io/create-snapshot-status to build pipelineRun with
@@ -22,14 +22,18 @@ import (
 	"strings"
 
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/konflux-ci/integration-service/api/v1beta2"
 	"github.com/konflux-ci/operator-toolkit/metadata"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -46,6 +50,29 @@ const (
 	// PipelineTypeTest is the type for PipelineRuns created to run an integration Pipeline
 	PipelineTypeTest = "test"
 
+	// MaxRunningPipelineRunsEnvVar is the environment variable holding the default maximum number of integration
+	// PipelineRuns running at once in a namespace
+	MaxRunningPipelineRunsEnvVar = "MAX_RUNNING_INTEGRATION_PIPELINERUNS"
+
+	// StuckPipelineRunTimeoutEnvVar is the environment variable holding how long an integration PipelineRun may make
+	// no status progress before it's considered stuck, "0" disables the detection of the stuck PipelineRuns
+	StuckPipelineRunTimeoutEnvVar = "STUCK_INTEGRATION_PIPELINERUN_TIMEOUT"
+
+	// DefaultStuckPipelineRunTimeout is how long an integration PipelineRun may make no status progress before it's
+	// considered stuck when the StuckPipelineRunTimeoutEnvVar isn't set
+	DefaultStuckPipelineRunTimeout = time.Hour
+
+	// DefaultIntegrationPipelineServiceAccount is the ServiceAccount used by the integration PipelineRuns of the
+	// IntegrationTestScenarios which don't specify one
+	DefaultIntegrationPipelineServiceAccount = "appstudio-pipeline"
+
+	// SnapshotConfigMapKey is the key of the Snapshot JSON in the ConfigMaps delivering it to the integration
+	// PipelineRuns, which is also the name of its file in the workspace the ConfigMaps are bound to
+	SnapshotConfigMapKey = "snapshot.json"
+
+	// SnapshotFileParamName is the name of the param holding the name of the Snapshot file in the workspace
+	SnapshotFileParamName = "SNAPSHOT_FILE"
+
 	// Name of tekton resolver for git
 	TektonResolverGit = "git"
 
@@ -78,10 +105,78 @@ var (
 	// ComponentNameLabel is the label of specific the name of the component associated with PipelineRun
 	ComponentNameLabel = fmt.Sprintf("%s/%s", ResourceLabelSuffix, "component")
 
+	// EventTypeLabel is the label used to specify the type of the git event, e.g. push or pull_request, which triggered
+	// the build of the Snapshot tested by the PipelineRun
+	EventTypeLabel = fmt.Sprintf("pac.%s/%s", TestLabelPrefix, "event-type")
+
 	// OptionalLabel is the label used to specify if an IntegrationTestScenario is allowed to fail
 	OptionalLabel = fmt.Sprintf("%s/%s", TestLabelPrefix, "optional")
+
+	// ArchitectureLabel is the label used to specify the architecture of the component images tested by the PipelineRun
+	ArchitectureLabel = fmt.Sprintf("%s/%s", TestLabelPrefix, "architecture")
+
+	// MatrixScenarioLabel is the label used to specify the IntegrationTestScenario whose matrix cell is tested by the PipelineRun
+	MatrixScenarioLabel = fmt.Sprintf("%s/%s", TestLabelPrefix, "matrix-scenario")
+
+	// MaxAllowedFailuresAnnotation is the annotation used to carry the failure tolerance threshold of the
+	// IntegrationTestScenario over to the PipelineRun
+	MaxAllowedFailuresAnnotation = fmt.Sprintf("%s/%s", TestLabelPrefix, "max-allowed-failures")
+
+	// MaxRunningPipelineRunsAnnotation is the Namespace annotation holding the maximum number of integration
+	// PipelineRuns running at once in the namespace, it takes precedence over the MaxRunningPipelineRunsEnvVar
+	MaxRunningPipelineRunsAnnotation = fmt.Sprintf("%s/%s", TestLabelPrefix, "max-running-pipelineruns")
 )
 
+// GetMaxRunningIntegrationPipelineRuns returns the maximum number of integration PipelineRuns which may be running
+// at once in the namespace, as set through the MaxRunningPipelineRunsAnnotation of the namespace or the
+// MaxRunningPipelineRunsEnvVar. 0 is returned when the number of running integration PipelineRuns isn't limited.
+func GetMaxRunningIntegrationPipelineRuns(namespace *corev1.Namespace) (int, error) {
+	value := os.Getenv(MaxRunningPipelineRunsEnvVar)
+	if namespace != nil && metadata.HasAnnotation(namespace, MaxRunningPipelineRunsAnnotation) {
+		value = namespace.GetAnnotations()[MaxRunningPipelineRunsAnnotation]
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	maxRunning, err := strconv.Atoi(value)
+	if err != nil || maxRunning < 0 {
+		return 0, fmt.Errorf("invalid maximum number of running integration PipelineRuns %q, a non-negative number is expected", value)
+	}
+	return maxRunning, nil
+}
+
+// GetStuckPipelineRunTimeout returns how long an integration PipelineRun may make no status progress before it's
+// considered stuck, as set through the StuckPipelineRunTimeoutEnvVar, or DefaultStuckPipelineRunTimeout when it isn't
+// set. 0 is returned when the stuck PipelineRuns aren't detected.
+func GetStuckPipelineRunTimeout() (time.Duration, error) {
+	value := os.Getenv(StuckPipelineRunTimeoutEnvVar)
+	if value == "" {
+		return DefaultStuckPipelineRunTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return DefaultStuckPipelineRunTimeout, fmt.Errorf("invalid timeout of the stuck integration PipelineRuns %q, a non-negative duration is expected", value)
+	}
+	return timeout, nil
+}
+
+// GetLastProgressTime returns the last time the status of the PipelineRun made progress, which is the latest of its
+// creation, its start and the last transition of its Succeeded condition. Tekton updates the condition whenever
+// the tasks of the PipelineRun progress.
+func GetLastProgressTime(pipelineRun *tektonv1.PipelineRun) time.Time {
+	lastProgress := pipelineRun.CreationTimestamp.Time
+	if pipelineRun.Status.StartTime != nil && pipelineRun.Status.StartTime.After(lastProgress) {
+		lastProgress = pipelineRun.Status.StartTime.Time
+	}
+	if condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded); condition != nil && condition.LastTransitionTime.Inner.After(lastProgress) {
+		lastProgress = condition.LastTransitionTime.Inner.Time
+	}
+
+	return lastProgress
+}
+
 // IntegrationPipelineRun is a PipelineRun alias, so we can add new methods to it in this file.
 type IntegrationPipelineRun struct {
 	tektonv1.PipelineRun
@@ -194,20 +289,69 @@ func (r *IntegrationPipelineRun) WithSnapshot(snapshot *applicationapiv1alpha1.S
 		StringVal: string(snapshotString),
 	})
 
+	return r.withSnapshotLabels(snapshot)
+}
+
+// WithSnapshotInWorkspace binds the ConfigMap holding the Snapshot to the given workspace of the Integration PipelineRun
+// and passes the name of the file of the Snapshot in the workspace as the SNAPSHOT_FILE param instead of the SNAPSHOT param.
+func (r *IntegrationPipelineRun) WithSnapshotInWorkspace(snapshot *applicationapiv1alpha1.Snapshot, workspace, configMapName string) *IntegrationPipelineRun {
+	r.Spec.Workspaces = append(r.Spec.Workspaces, tektonv1.WorkspaceBinding{
+		Name: workspace,
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+		},
+	})
+
+	r.WithExtraParam(SnapshotFileParamName, tektonv1.ParamValue{
+		Type:      tektonv1.ParamTypeString,
+		StringVal: SnapshotConfigMapKey,
+	})
+
+	return r.withSnapshotLabels(snapshot)
+}
+
+// NewSnapshotConfigMap returns the ConfigMap delivering the given Snapshot to the integration PipelineRuns of the
+// IntegrationTestScenarios which specify a Snapshot workspace. The name of the ConfigMap is autogenerated using the name
+// of the Snapshot as prefix.
+func NewSnapshotConfigMap(snapshot *applicationapiv1alpha1.Snapshot) (*corev1.ConfigMap, error) {
+	snapshotString, err := json.Marshal(snapshot.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot %s into JSON: %w", snapshot.Name, err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: snapshot.Name + "-",
+			Namespace:    snapshot.Namespace,
+			Labels: map[string]string{
+				SnapshotNameLabel: snapshot.Name,
+			},
+		},
+		Data: map[string]string{
+			SnapshotConfigMapKey: string(snapshotString),
+		},
+	}, nil
+}
+
+// withSnapshotLabels adds the name of the Snapshot and, when the Snapshot carries them, the name of its component and
+// the type of the event which triggered its build as labels to the Integration PipelineRun.
+func (r *IntegrationPipelineRun) withSnapshotLabels(snapshot *applicationapiv1alpha1.Snapshot) *IntegrationPipelineRun {
 	if r.ObjectMeta.Labels == nil {
 		r.ObjectMeta.Labels = map[string]string{}
 	}
 	r.ObjectMeta.Labels[SnapshotNameLabel] = snapshot.Name
 
-	componentLabel, found := snapshot.GetLabels()[ComponentNameLabel]
-	if found {
-		r.ObjectMeta.Labels[ComponentNameLabel] = componentLabel
+	for _, label := range []string{ComponentNameLabel, EventTypeLabel} {
+		if value, found := snapshot.GetLabels()[label]; found {
+			r.ObjectMeta.Labels[label] = value
+		}
 	}
 
 	return r
 }
 
-// WithIntegrationLabels adds the type, optional flag and IntegrationTestScenario name as labels to the Integration PipelineRun.
+// WithIntegrationLabels adds the type, optional flag ("true" or "false"), tested architecture, matrix scenario and IntegrationTestScenario name
+// as labels to the Integration PipelineRun.
 func (r *IntegrationPipelineRun) WithIntegrationLabels(integrationTestScenario *v1beta2.IntegrationTestScenario) *IntegrationPipelineRun {
 	if r.ObjectMeta.Labels == nil {
 		r.ObjectMeta.Labels = map[string]string{}
@@ -215,15 +359,22 @@ func (r *IntegrationPipelineRun) WithIntegrationLabels(integrationTestScenario *
 	r.ObjectMeta.Labels[PipelinesTypeLabel] = PipelineTypeTest
 	r.ObjectMeta.Labels[ScenarioNameLabel] = integrationTestScenario.Name
 
-	if metadata.HasLabel(integrationTestScenario, OptionalLabel) {
-		r.ObjectMeta.Labels[OptionalLabel] = integrationTestScenario.Labels[OptionalLabel]
+	// the optional flag is always set so that the PipelineRuns of the required scenarios can be selected too
+	r.ObjectMeta.Labels[OptionalLabel] = strconv.FormatBool(metadata.HasLabelWithValue(integrationTestScenario, OptionalLabel, "true"))
+
+	if metadata.HasLabel(integrationTestScenario, MatrixScenarioLabel) {
+		r.ObjectMeta.Labels[MatrixScenarioLabel] = integrationTestScenario.Labels[MatrixScenarioLabel]
+	}
+
+	if integrationTestScenario.Spec.Architecture != "" {
+		r.ObjectMeta.Labels[ArchitectureLabel] = integrationTestScenario.Spec.Architecture
 	}
 
 	return r
 }
 
 // WithIntegrationAnnotations copies the App Studio annotations from the
-// IntegrationTestScenario to the PipelineRun
+// IntegrationTestScenario to the PipelineRun, together with its failure tolerance threshold
 func (r *IntegrationPipelineRun) WithIntegrationAnnotations(its *v1beta2.IntegrationTestScenario) *IntegrationPipelineRun {
 	for k, v := range its.GetAnnotations() {
 		if strings.Contains(k, "appstudio.openshift.io/") {
@@ -234,6 +385,146 @@ func (r *IntegrationPipelineRun) WithIntegrationAnnotations(its *v1beta2.Integra
 		}
 	}
 
+	if its.Spec.MaxAllowedFailures != nil {
+		if err := metadata.SetAnnotation(r, MaxAllowedFailuresAnnotation, strconv.Itoa(*its.Spec.MaxAllowedFailures)); err != nil {
+			// this will only happen if we pass IntegrationPipelineRun as nil
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// WithDefaultMaxAllowedFailures sets the failure tolerance threshold of the Integration PipelineRun to the given
+// default, e.g. the one of the IntegrationPolicy of the application, when its IntegrationTestScenario doesn't set one.
+func (r *IntegrationPipelineRun) WithDefaultMaxAllowedFailures(maxAllowedFailures *int) *IntegrationPipelineRun {
+	if maxAllowedFailures == nil || metadata.HasAnnotation(r, MaxAllowedFailuresAnnotation) {
+		return r
+	}
+
+	if err := metadata.SetAnnotation(r, MaxAllowedFailuresAnnotation, strconv.Itoa(*maxAllowedFailures)); err != nil {
+		// this will only happen if we pass IntegrationPipelineRun as nil
+		panic(err)
+	}
+
+	return r
+}
+
+// WithServiceAccount sets the ServiceAccount the Integration PipelineRun runs as to the one specified by the
+// IntegrationTestScenario, or to the default appstudio-pipeline ServiceAccount if the scenario doesn't specify any.
+func (r *IntegrationPipelineRun) WithServiceAccount(integrationTestScenario *v1beta2.IntegrationTestScenario) *IntegrationPipelineRun {
+	r.Spec.TaskRunTemplate.ServiceAccountName = DefaultIntegrationPipelineServiceAccount
+	if integrationTestScenario.Spec.ServiceAccountName != "" {
+		r.Spec.TaskRunTemplate.ServiceAccountName = integrationTestScenario.Spec.ServiceAccountName
+	}
+
+	return r
+}
+
+// WithWorkspaces binds the workspaces declared by the IntegrationTestScenario to the Integration PipelineRun. The
+// workspaces which don't specify any volume source are bound to an emptyDir volume.
+func (r *IntegrationPipelineRun) WithWorkspaces(workspaces []v1beta2.PipelineWorkspace) *IntegrationPipelineRun {
+	for _, workspace := range workspaces {
+		binding := tektonv1.WorkspaceBinding{
+			Name:    workspace.Name,
+			SubPath: workspace.SubPath,
+		}
+
+		switch {
+		case workspace.VolumeClaimTemplate != nil:
+			accessModes := workspace.VolumeClaimTemplate.AccessModes
+			if len(accessModes) == 0 {
+				accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+			}
+			binding.VolumeClaimTemplate = &corev1.PersistentVolumeClaim{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: accessModes,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: workspace.VolumeClaimTemplate.Storage,
+						},
+					},
+				},
+			}
+			if workspace.VolumeClaimTemplate.StorageClassName != "" {
+				storageClassName := workspace.VolumeClaimTemplate.StorageClassName
+				binding.VolumeClaimTemplate.Spec.StorageClassName = &storageClassName
+			}
+		case workspace.PersistentVolumeClaim != "":
+			binding.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: workspace.PersistentVolumeClaim}
+		case workspace.ConfigMap != "":
+			binding.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: workspace.ConfigMap},
+			}
+		case workspace.Secret != "":
+			binding.Secret = &corev1.SecretVolumeSource{SecretName: workspace.Secret}
+		default:
+			binding.EmptyDir = &corev1.EmptyDirVolumeSource{}
+		}
+
+		r.Spec.Workspaces = append(r.Spec.Workspaces, binding)
+	}
+
+	return r
+}
+
+// WithPodTemplate sets the template of the pods running the tasks of the Integration PipelineRun to the pod template of
+// the IntegrationTestScenario, so that the tasks can be scheduled onto dedicated nodes.
+func (r *IntegrationPipelineRun) WithPodTemplate(podTemplate *v1beta2.PipelinePodTemplate) *IntegrationPipelineRun {
+	if podTemplate == nil {
+		return r
+	}
+
+	r.Spec.TaskRunTemplate.PodTemplate = &pod.PodTemplate{
+		NodeSelector:    podTemplate.NodeSelector,
+		Tolerations:     podTemplate.Tolerations,
+		SecurityContext: podTemplate.SecurityContext,
+	}
+
+	return r
+}
+
+// WithSecrets binds the Secrets listed by the IntegrationTestScenario to the Integration PipelineRun, either as
+// workspaces or as environment variables of the pods running its tasks. It has to be called after WithPodTemplate
+// for the environment variables to be kept.
+func (r *IntegrationPipelineRun) WithSecrets(secrets []v1beta2.PipelineSecret) *IntegrationPipelineRun {
+	for _, secret := range secrets {
+		if secret.Workspace != "" {
+			r.Spec.Workspaces = append(r.Spec.Workspaces, tektonv1.WorkspaceBinding{
+				Name:   secret.Workspace,
+				Secret: &corev1.SecretVolumeSource{SecretName: secret.Name},
+			})
+		}
+
+		for _, env := range secret.Env {
+			if r.Spec.TaskRunTemplate.PodTemplate == nil {
+				r.Spec.TaskRunTemplate.PodTemplate = &pod.PodTemplate{}
+			}
+			r.Spec.TaskRunTemplate.PodTemplate.Env = append(r.Spec.TaskRunTemplate.PodTemplate.Env, corev1.EnvVar{
+				Name: env.Name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+						Key:                  env.Key,
+					},
+				},
+			})
+		}
+	}
+
+	return r
+}
+
+// WithTaskRunSpecs overrides the compute resources of the tasks of the Integration PipelineRun with the ones specified
+// by the IntegrationTestScenario.
+func (r *IntegrationPipelineRun) WithTaskRunSpecs(taskRunSpecs []v1beta2.PipelineTaskRunSpec) *IntegrationPipelineRun {
+	for _, taskRunSpec := range taskRunSpecs {
+		r.Spec.TaskRunSpecs = append(r.Spec.TaskRunSpecs, tektonv1.PipelineTaskRunSpec{
+			PipelineTaskName: taskRunSpec.PipelineTaskName,
+			ComputeResources: taskRunSpec.ComputeResources,
+		})
+	}
+
 	return r
 }
 
@@ -30,7 +30,11 @@ import (
 	tekton "github.com/konflux-ci/integration-service/tekton"
 	applicationapiv1alpha1 "github.com/redhat-appstudio/application-api/api/v1alpha1"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 type ExtraParams struct {
@@ -357,6 +361,28 @@ var _ = Describe("Integration pipeline", func() {
 				To(Equal(hasSnapshot.Name))
 			Expect(newIntegrationPipelineRun.Labels["appstudio.openshift.io/component"]).
 				To(Equal(hasComp.Name))
+			Expect(newIntegrationPipelineRun.Labels).NotTo(HaveKey(tekton.EventTypeLabel))
+		})
+
+		It("copies the event type of the Snapshot to the IntegrationPipelineRun labels", func() {
+			pullRequestSnapshot := hasSnapshot.DeepCopy()
+			pullRequestSnapshot.Labels[gitops.PipelineAsCodeEventTypeLabel] = gitops.PipelineAsCodePullRequestType
+
+			newIntegrationPipelineRun.WithSnapshot(pullRequestSnapshot)
+			Expect(tekton.EventTypeLabel).To(Equal(gitops.PipelineAsCodeEventTypeLabel))
+			Expect(newIntegrationPipelineRun.Labels).To(HaveKeyWithValue(tekton.EventTypeLabel, gitops.PipelineAsCodePullRequestType))
+		})
+
+		It("always sets the optional flag of the scenario on the IntegrationPipelineRun labels", func() {
+			optionalScenario := integrationTestScenarioGit.DeepCopy()
+			optionalScenario.Labels[tekton.OptionalLabel] = "true"
+			newIntegrationPipelineRun.WithIntegrationLabels(optionalScenario)
+			Expect(newIntegrationPipelineRun.Labels).To(HaveKeyWithValue(tekton.OptionalLabel, "true"))
+
+			unlabeledScenario := integrationTestScenarioGit.DeepCopy()
+			delete(unlabeledScenario.Labels, tekton.OptionalLabel)
+			newIntegrationPipelineRun.WithIntegrationLabels(unlabeledScenario)
+			Expect(newIntegrationPipelineRun.Labels).To(HaveKeyWithValue(tekton.OptionalLabel, "false"))
 		})
 
 		It("can append labels coming from Application to IntegrationPipelineRun and making sure that label values matches application", func() {
@@ -442,5 +468,221 @@ var _ = Describe("Integration pipeline", func() {
 				"test.appstudio.openshift.io/future": "future",
 			}))
 		})
+
+		It("copies the failure tolerance threshold of the scenario as an annotation", func() {
+			maxAllowedFailures := 3
+			its := v1beta2.IntegrationTestScenario{}
+			its.Spec.MaxAllowedFailures = &maxAllowedFailures
+
+			ipr := tekton.IntegrationPipelineRun{}
+
+			ipr.WithIntegrationAnnotations(&its)
+
+			Expect(ipr.Annotations).To(HaveKeyWithValue(tekton.MaxAllowedFailuresAnnotation, "3"))
+			threshold, ok := tekton.GetMaxAllowedFailures(ipr.AsPipelineRun())
+			Expect(ok).To(BeTrue())
+			Expect(threshold).To(Equal(3))
+		})
+
+		It("sets the default failure tolerance threshold only when the scenario doesn't define one", func() {
+			defaultMaxAllowedFailures := 1
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithDefaultMaxAllowedFailures(nil)
+			Expect(ipr.Annotations).NotTo(HaveKey(tekton.MaxAllowedFailuresAnnotation))
+			ipr.WithDefaultMaxAllowedFailures(&defaultMaxAllowedFailures)
+			Expect(ipr.Annotations).To(HaveKeyWithValue(tekton.MaxAllowedFailuresAnnotation, "1"))
+
+			maxAllowedFailures := 3
+			its := v1beta2.IntegrationTestScenario{}
+			its.Spec.MaxAllowedFailures = &maxAllowedFailures
+			ipr = tekton.IntegrationPipelineRun{}
+			ipr.WithIntegrationAnnotations(&its).WithDefaultMaxAllowedFailures(&defaultMaxAllowedFailures)
+			Expect(ipr.Annotations).To(HaveKeyWithValue(tekton.MaxAllowedFailuresAnnotation, "3"))
+		})
+
+		It("labels the pipelineRuns of the scenarios targeting an architecture", func() {
+			its := v1beta2.IntegrationTestScenario{}
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithIntegrationLabels(&its)
+			Expect(ipr.Labels).NotTo(HaveKey(tekton.ArchitectureLabel))
+
+			its.Spec.Architecture = "arm64"
+			ipr.WithIntegrationLabels(&its)
+			Expect(ipr.Labels).To(HaveKeyWithValue(tekton.ArchitectureLabel, "arm64"))
+		})
+
+		It("runs the pipelineRuns as the ServiceAccount of the scenario or the default one", func() {
+			its := v1beta2.IntegrationTestScenario{}
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithServiceAccount(&its)
+			Expect(ipr.Spec.TaskRunTemplate.ServiceAccountName).To(Equal(tekton.DefaultIntegrationPipelineServiceAccount))
+
+			its.Spec.ServiceAccountName = "integration-tests"
+			ipr.WithServiceAccount(&its)
+			Expect(ipr.Spec.TaskRunTemplate.ServiceAccountName).To(Equal("integration-tests"))
+		})
+
+		It("binds the workspaces of the scenario to the pipelineRuns", func() {
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithWorkspaces([]v1beta2.PipelineWorkspace{
+				{Name: "shared", SubPath: "tests", VolumeClaimTemplate: &v1beta2.WorkspaceVolumeClaimTemplate{
+					StorageClassName: "fast", Storage: resource.MustParse("1Gi"),
+				}},
+				{Name: "data", PersistentVolumeClaim: "data-pvc"},
+				{Name: "config", ConfigMap: "test-config"},
+				{Name: "credentials", Secret: "test-credentials"},
+				{Name: "scratch"},
+			})
+			Expect(ipr.Spec.Workspaces).To(HaveLen(5))
+
+			shared := ipr.Spec.Workspaces[0]
+			Expect(shared.Name).To(Equal("shared"))
+			Expect(shared.SubPath).To(Equal("tests"))
+			Expect(shared.VolumeClaimTemplate.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+			Expect(*shared.VolumeClaimTemplate.Spec.StorageClassName).To(Equal("fast"))
+			Expect(shared.VolumeClaimTemplate.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+			Expect(ipr.Spec.Workspaces[1].PersistentVolumeClaim.ClaimName).To(Equal("data-pvc"))
+			Expect(ipr.Spec.Workspaces[2].ConfigMap.Name).To(Equal("test-config"))
+			Expect(ipr.Spec.Workspaces[3].Secret.SecretName).To(Equal("test-credentials"))
+			Expect(ipr.Spec.Workspaces[4].EmptyDir).NotTo(BeNil())
+		})
+
+		It("sets the pod template and the task compute resources of the scenario on the pipelineRuns", func() {
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithPodTemplate(nil).WithTaskRunSpecs(nil)
+			Expect(ipr.Spec.TaskRunTemplate.PodTemplate).To(BeNil())
+			Expect(ipr.Spec.TaskRunSpecs).To(BeEmpty())
+
+			runAsNonRoot := true
+			ipr.WithPodTemplate(&v1beta2.PipelinePodTemplate{
+				NodeSelector:    map[string]string{"node-role.kubernetes.io/e2e": ""},
+				Tolerations:     []corev1.Toleration{{Key: "e2e", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+				SecurityContext: &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+			}).WithTaskRunSpecs([]v1beta2.PipelineTaskRunSpec{{
+				PipelineTaskName: "e2e-tests",
+				ComputeResources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+				},
+			}})
+
+			podTemplate := ipr.Spec.TaskRunTemplate.PodTemplate
+			Expect(podTemplate.NodeSelector).To(HaveKey("node-role.kubernetes.io/e2e"))
+			Expect(podTemplate.Tolerations).To(HaveLen(1))
+			Expect(*podTemplate.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(ipr.Spec.TaskRunSpecs).To(HaveLen(1))
+			Expect(ipr.Spec.TaskRunSpecs[0].PipelineTaskName).To(Equal("e2e-tests"))
+			Expect(ipr.Spec.TaskRunSpecs[0].ComputeResources.Requests.Memory().String()).To(Equal("8Gi"))
+		})
+
+		It("binds the secrets of the scenario to the pipelineRuns as workspaces and environment variables", func() {
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithPodTemplate(&v1beta2.PipelinePodTemplate{NodeSelector: map[string]string{"e2e": "true"}}).
+				WithSecrets([]v1beta2.PipelineSecret{
+					{Name: "api-keys", Workspace: "api-keys"},
+					{Name: "cloud-credentials", Env: []v1beta2.SecretEnvVar{{Name: "CLOUD_TOKEN", Key: "token"}}},
+				})
+
+			Expect(ipr.Spec.Workspaces).To(HaveLen(1))
+			Expect(ipr.Spec.Workspaces[0].Name).To(Equal("api-keys"))
+			Expect(ipr.Spec.Workspaces[0].Secret.SecretName).To(Equal("api-keys"))
+
+			podTemplate := ipr.Spec.TaskRunTemplate.PodTemplate
+			Expect(podTemplate.NodeSelector).To(HaveKey("e2e"))
+			Expect(podTemplate.Env).To(HaveLen(1))
+			Expect(podTemplate.Env[0].Name).To(Equal("CLOUD_TOKEN"))
+			Expect(podTemplate.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("cloud-credentials"))
+			Expect(podTemplate.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal("token"))
+		})
+
+		It("delivers the snapshot in a workspace instead of the SNAPSHOT param", func() {
+			snapshot := &applicationapiv1alpha1.Snapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "snapshot-sample",
+					Namespace: "default",
+				},
+				Spec: applicationapiv1alpha1.SnapshotSpec{
+					Application: "application-sample",
+					Components: []applicationapiv1alpha1.SnapshotComponent{
+						{Name: "component-sample", ContainerImage: "quay.io/redhat-appstudio/sample-image:latest"},
+					},
+				},
+			}
+
+			configMap, err := tekton.NewSnapshotConfigMap(snapshot)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configMap.GenerateName).To(Equal("snapshot-sample-"))
+			Expect(configMap.Namespace).To(Equal("default"))
+			Expect(configMap.Labels).To(HaveKeyWithValue(tekton.SnapshotNameLabel, "snapshot-sample"))
+			Expect(configMap.Data[tekton.SnapshotConfigMapKey]).To(ContainSubstring("quay.io/redhat-appstudio/sample-image:latest"))
+
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithSnapshotInWorkspace(snapshot, "snapshot", "snapshot-sample-abcde")
+			Expect(ipr.Labels).To(HaveKeyWithValue(tekton.SnapshotNameLabel, "snapshot-sample"))
+			Expect(ipr.Spec.Workspaces).To(HaveLen(1))
+			Expect(ipr.Spec.Workspaces[0].Name).To(Equal("snapshot"))
+			Expect(ipr.Spec.Workspaces[0].ConfigMap.Name).To(Equal("snapshot-sample-abcde"))
+			Expect(ipr.Spec.Params).To(ConsistOf(HaveField("Name", tekton.SnapshotFileParamName)))
+			Expect(ipr.Spec.Params[0].Value.StringVal).To(Equal(tekton.SnapshotConfigMapKey))
+		})
+
+		It("labels the pipelineRuns of the matrix cells with their scenario", func() {
+			its := v1beta2.IntegrationTestScenario{}
+			ipr := tekton.IntegrationPipelineRun{}
+			ipr.WithIntegrationLabels(&its)
+			Expect(ipr.Labels).NotTo(HaveKey(tekton.MatrixScenarioLabel))
+
+			its.Labels = map[string]string{tekton.MatrixScenarioLabel: "e2e"}
+			ipr.WithIntegrationLabels(&its)
+			Expect(ipr.Labels).To(HaveKeyWithValue(tekton.MatrixScenarioLabel, "e2e"))
+		})
+
+		It("limits the running integration pipelineRuns of namespaces through the annotation or the environment", func() {
+			tenantNamespace := &corev1.Namespace{}
+			Expect(tekton.GetMaxRunningIntegrationPipelineRuns(tenantNamespace)).To(Equal(0))
+
+			os.Setenv(tekton.MaxRunningPipelineRunsEnvVar, "5")
+			defer os.Unsetenv(tekton.MaxRunningPipelineRunsEnvVar)
+			Expect(tekton.GetMaxRunningIntegrationPipelineRuns(tenantNamespace)).To(Equal(5))
+
+			tenantNamespace.Annotations = map[string]string{tekton.MaxRunningPipelineRunsAnnotation: "2"}
+			Expect(tekton.GetMaxRunningIntegrationPipelineRuns(tenantNamespace)).To(Equal(2))
+
+			tenantNamespace.Annotations[tekton.MaxRunningPipelineRunsAnnotation] = "-1"
+			_, err := tekton.GetMaxRunningIntegrationPipelineRuns(tenantNamespace)
+			Expect(err).To(MatchError(ContainSubstring("invalid maximum number of running integration PipelineRuns \"-1\"")))
+		})
+
+		It("gets the timeout of the stuck integration pipelineRuns from the environment", func() {
+			Expect(tekton.GetStuckPipelineRunTimeout()).To(Equal(tekton.DefaultStuckPipelineRunTimeout))
+
+			GinkgoT().Setenv(tekton.StuckPipelineRunTimeoutEnvVar, "15m")
+			Expect(tekton.GetStuckPipelineRunTimeout()).To(Equal(15 * time.Minute))
+
+			GinkgoT().Setenv(tekton.StuckPipelineRunTimeoutEnvVar, "0")
+			Expect(tekton.GetStuckPipelineRunTimeout()).To(Equal(time.Duration(0)))
+
+			GinkgoT().Setenv(tekton.StuckPipelineRunTimeoutEnvVar, "-5m")
+			timeout, err := tekton.GetStuckPipelineRunTimeout()
+			Expect(err).To(MatchError(ContainSubstring("invalid timeout of the stuck integration PipelineRuns \"-5m\"")))
+			Expect(timeout).To(Equal(tekton.DefaultStuckPipelineRunTimeout))
+		})
+
+		It("gets the last time the status of the pipelineRun made progress", func() {
+			created := time.Now().Add(-time.Hour).Truncate(time.Second)
+			pipelineRun := &tektonv1.PipelineRun{}
+			pipelineRun.CreationTimestamp = metav1.NewTime(created)
+			Expect(tekton.GetLastProgressTime(pipelineRun)).To(Equal(created))
+
+			pipelineRun.Status.StartTime = &metav1.Time{Time: created.Add(time.Minute)}
+			Expect(tekton.GetLastProgressTime(pipelineRun)).To(Equal(created.Add(time.Minute)))
+
+			pipelineRun.Status.Conditions = duckv1.Conditions{{
+				Type:               apis.ConditionSucceeded,
+				Status:             corev1.ConditionUnknown,
+				Reason:             "Running",
+				LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(created.Add(10 * time.Minute))},
+			}}
+			Expect(tekton.GetLastProgressTime(pipelineRun)).To(Equal(created.Add(10 * time.Minute)))
+		})
 	})
 })
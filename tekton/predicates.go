@@ -47,6 +47,8 @@ func IntegrationPipelineRunPredicate() predicate.Predicate {
 
 // BuildPipelineRunSignedAndSucceededPredicate returns a predicate which filters out all objects except
 // Build PipelineRuns which have finished, been signed and haven't had a Snapshot created for them.
+// Every update of such a PipelineRun passes the filter, including resyncs and no-op updates, so the
+// PipelineRuns which finished while the controller wasn't running still get a Snapshot.
 func BuildPipelineRunSignedAndSucceededPredicate() predicate.Predicate {
 	return predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
@@ -67,7 +69,8 @@ func BuildPipelineRunSignedAndSucceededPredicate() predicate.Predicate {
 }
 
 // BuildPipelineRunFailedPredicate returns a predicate which filters out all objects except Build
-// PipelineRuns which have finished and have failed.
+// PipelineRuns which have finished and have failed. Only the updates which finished or signed the
+// PipelineRun pass the filter.
 func BuildPipelineRunFailedPredicate() predicate.Predicate {
 	return predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
@@ -82,7 +85,9 @@ func BuildPipelineRunFailedPredicate() predicate.Predicate {
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			return IsBuildPipelineRun(e.ObjectNew) &&
 				isChainsDoneWithPipelineRun(e.ObjectNew) &&
-				!helpers.HasPipelineRunSucceeded(e.ObjectNew)
+				!helpers.HasPipelineRunSucceeded(e.ObjectNew) &&
+				(hasPipelineRunSucceededConditionChanged(e.ObjectOld, e.ObjectNew) ||
+					hasPipelineRunChainsSignedAnnotationChanged(e.ObjectOld, e.ObjectNew))
 		},
 	}
 }
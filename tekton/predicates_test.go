@@ -146,6 +146,43 @@ var _ = Describe("Predicates", func() {
 			newPipelineRun.Annotations["appstudio.openshift.io/snapshot"] = "snapshot"
 			Expect(instance.Update(contextEvent)).To(BeFalse())
 		})
+
+		It("should return true when a resync event is received for a succeeded and signed PipelineRun without a Snapshot", func() {
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: "True",
+			})
+			pipelineRun.Annotations["chains.tekton.dev/signed"] = "true"
+			newPipelineRun = pipelineRun.DeepCopy()
+			newPipelineRun.Annotations["example.com/unrelated"] = "value"
+
+			contextEvent := event.UpdateEvent{
+				ObjectOld: pipelineRun,
+				ObjectNew: newPipelineRun,
+			}
+			Expect(instance.Update(contextEvent)).To(BeTrue())
+
+			contextEvent.ObjectOld = newPipelineRun
+			Expect(instance.Update(contextEvent)).To(BeTrue())
+
+			newPipelineRun.Annotations["appstudio.openshift.io/snapshot"] = "snapshot"
+			Expect(instance.Update(contextEvent)).To(BeFalse())
+		})
+
+		It("should return true when an updated event is received for a succeeded PipelineRun which just got signed", func() {
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: "True",
+			})
+			newPipelineRun = pipelineRun.DeepCopy()
+			newPipelineRun.Annotations["chains.tekton.dev/signed"] = "true"
+
+			contextEvent := event.UpdateEvent{
+				ObjectOld: pipelineRun,
+				ObjectNew: newPipelineRun,
+			}
+			Expect(instance.Update(contextEvent)).To(BeTrue())
+		})
 	})
 
 	Context("when testing IntegrationPipelineRunPredicate", func() {
@@ -355,6 +392,21 @@ var _ = Describe("Predicates", func() {
 			Expect(instance.Update(contextEvent)).To(BeTrue())
 		})
 
+		It("should return false for an update event of a failed and signed build PLR which didn't change its status", func() {
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: "False",
+			})
+			pipelineRun.Annotations["chains.tekton.dev/signed"] = "true"
+			newPipelineRun = pipelineRun.DeepCopy()
+			newPipelineRun.Annotations["example.com/unrelated"] = "value"
+			contextEvent := event.UpdateEvent{
+				ObjectOld: pipelineRun,
+				ObjectNew: newPipelineRun,
+			}
+			Expect(instance.Update(contextEvent)).To(BeFalse())
+		})
+
 		It("should return false for an update event in which the build PLR succeeded", func() {
 			newPipelineRun.Status.SetCondition(&apis.Condition{
 				Type:   apis.ConditionSucceeded,
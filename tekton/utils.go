@@ -17,7 +17,11 @@ limitations under the License.
 package tekton
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	h "github.com/konflux-ci/integration-service/helpers"
 	"github.com/konflux-ci/operator-toolkit/metadata"
@@ -42,6 +46,19 @@ const (
 	// PipelineRunApplicationLabel is the label denoting the application.
 	PipelineRunApplicationLabel = "appstudio.openshift.io/application"
 
+	// PipelineRunComponentParamName is the name of the param holding the name of the component built by the build
+	// PipelineRuns which don't carry the PipelineRunComponentLabel, e.g. the ones created by plain Tekton triggers
+	PipelineRunComponentParamName = "COMPONENT"
+
+	// PipelineRunApplicationParamName is the name of the param holding the name of the application built by the
+	// build PipelineRuns which don't carry the PipelineRunApplicationLabel
+	PipelineRunApplicationParamName = "APPLICATION"
+
+	// BuildPipelineRunLabelEnvVar is the environment variable holding the label, in the key=value form, which
+	// identifies the build PipelineRuns which don't carry the PipelineRunTypeLabel, e.g. the ones created by plain
+	// Tekton triggers instead of Pipelines as Code
+	BuildPipelineRunLabelEnvVar = "BUILD_PIPELINERUN_LABEL"
+
 	// PipelineRunChainsSignedAnnotation is the label added by Tekton Chains to signed PipelineRuns
 	PipelineRunChainsSignedAnnotation = "chains.tekton.dev/signed"
 
@@ -51,6 +68,13 @@ const (
 	// PipelineRunImageDigestParamName name of image digest in PipelineRun result param
 	PipelineRunImageDigestParamName = "IMAGE_DIGEST"
 
+	// PipelineRunImageArchitectureDigestsParamName name of the PipelineRun result holding the json map of the
+	// architectures to the digests of their images, set by the builds producing a manifest list
+	PipelineRunImageArchitectureDigestsParamName = "IMAGE_ARCHITECTURE_DIGESTS"
+
+	// PipelineRunSBOMBlobURLParamName name of the PipelineRun result holding the reference of the SBOM blob of the built image
+	PipelineRunSBOMBlobURLParamName = "SBOM_BLOB_URL"
+
 	// PipelineRunChainsGitUrlParamName name of param chains repo url
 	PipelineRunChainsGitUrlParamName = "CHAINS-GIT_URL"
 
@@ -59,17 +83,63 @@ const (
 )
 
 // IsBuildPipelineRun returns a boolean indicating whether the object passed is a PipelineRun from
-// the Build service or not.
+// the Build service or not. The PipelineRuns which don't carry the PipelineRunTypeLabel are build PipelineRuns
+// when they carry the label set through the BuildPipelineRunLabelEnvVar.
 func IsBuildPipelineRun(object client.Object) bool {
 	if pipelineRun, ok := object.(*tektonv1.PipelineRun); ok {
-		return metadata.HasLabelWithValue(pipelineRun,
-			PipelineRunTypeLabel,
-			PipelineRunBuildType)
+		if metadata.HasLabel(pipelineRun, PipelineRunTypeLabel) {
+			return metadata.HasLabelWithValue(pipelineRun,
+				PipelineRunTypeLabel,
+				PipelineRunBuildType)
+		}
+
+		key, value, found := getBuildPipelineRunLabel()
+		return found && metadata.HasLabelWithValue(pipelineRun, key, value)
 	}
 
 	return false
 }
 
+// getBuildPipelineRunLabel returns the key and the value of the label set through the BuildPipelineRunLabelEnvVar.
+// False is returned when the environment variable isn't set or doesn't hold a label in the key=value form.
+func getBuildPipelineRunLabel() (string, string, bool) {
+	key, value, found := strings.Cut(os.Getenv(BuildPipelineRunLabelEnvVar), "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", "", false
+	}
+
+	return key, strings.TrimSpace(value), true
+}
+
+// GetComponentName returns the name of the component built by the given build PipelineRun, taken from its
+// PipelineRunComponentLabel or, when the PipelineRun doesn't carry it, from its PipelineRunComponentParamName param.
+func GetComponentName(pipelineRun *tektonv1.PipelineRun) (string, bool) {
+	return getLabelOrParam(pipelineRun, PipelineRunComponentLabel, PipelineRunComponentParamName)
+}
+
+// GetApplicationName returns the name of the application built by the given build PipelineRun, taken from its
+// PipelineRunApplicationLabel or, when the PipelineRun doesn't carry it, from its PipelineRunApplicationParamName param.
+func GetApplicationName(pipelineRun *tektonv1.PipelineRun) (string, bool) {
+	return getLabelOrParam(pipelineRun, PipelineRunApplicationLabel, PipelineRunApplicationParamName)
+}
+
+// getLabelOrParam returns the value of the given label of the PipelineRun, or the value of the given param of the
+// PipelineRun when the label isn't set.
+func getLabelOrParam(pipelineRun *tektonv1.PipelineRun, label, paramName string) (string, bool) {
+	if value, found := pipelineRun.GetLabels()[label]; found {
+		return value, true
+	}
+
+	for _, param := range pipelineRun.Spec.Params {
+		if param.Name == paramName && param.Value.StringVal != "" {
+			return param.Value.StringVal, true
+		}
+	}
+
+	return "", false
+}
+
 // IsIntegrationPipelineRun returns a boolean indicating whether the object passed is an Integration
 // Component PipelineRun
 func IsIntegrationPipelineRun(object client.Object) bool {
@@ -120,6 +190,38 @@ func hasPipelineRunStateChangedToDeleting(objectOld, objectNew client.Object) bo
 	return false
 }
 
+// hasPipelineRunSucceededConditionChanged returns a boolean indicating whether the status or the reason of the
+// Succeeded condition of the PipelineRun changed. If the objects passed to this function are not PipelineRuns,
+// the function will return false.
+func hasPipelineRunSucceededConditionChanged(objectOld, objectNew client.Object) bool {
+	if oldPipelineRun, ok := objectOld.(*tektonv1.PipelineRun); ok {
+		if newPipelineRun, ok := objectNew.(*tektonv1.PipelineRun); ok {
+			oldCondition := oldPipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+			newCondition := newPipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+			if oldCondition == nil || newCondition == nil {
+				return oldCondition != newCondition
+			}
+			return oldCondition.Status != newCondition.Status || oldCondition.Reason != newCondition.Reason
+		}
+	}
+
+	return false
+}
+
+// hasPipelineRunChainsSignedAnnotationChanged returns a boolean indicating whether Tekton Chains just
+// annotated the PipelineRun with the outcome of its signing. If the objects passed to this function are not
+// PipelineRuns, the function will return false.
+func hasPipelineRunChainsSignedAnnotationChanged(objectOld, objectNew client.Object) bool {
+	if oldPipelineRun, ok := objectOld.(*tektonv1.PipelineRun); ok {
+		if newPipelineRun, ok := objectNew.(*tektonv1.PipelineRun); ok {
+			return oldPipelineRun.GetAnnotations()[PipelineRunChainsSignedAnnotation] !=
+				newPipelineRun.GetAnnotations()[PipelineRunChainsSignedAnnotation]
+		}
+	}
+
+	return false
+}
+
 // isChainsDoneWithPipelineRun returns a boolean indicating whether Tekton Chains is done processing
 // the PipelineRun. true is returned regardless if Chains was able to successfully sign/attest the
 // artifacts produced by the PipelineRun. If the object passed to this function is not a
@@ -174,6 +276,42 @@ func GetOutputImageDigest(object client.Object) (string, error) {
 	return "", h.MissingInfoInPipelineRunError(pipelineRun.Name, PipelineRunImageDigestParamName)
 }
 
+// GetOutputImageArchitectureDigests returns the map of the architectures to the digests of their images from the
+// IMAGE_ARCHITECTURE_DIGESTS result of a given PipelineRun. Nil is returned when the result isn't set, which is the
+// case for the builds which don't produce a manifest list.
+func GetOutputImageArchitectureDigests(object client.Object) (map[string]string, error) {
+	pipelineRun, ok := object.(*tektonv1.PipelineRun)
+	if !ok {
+		return nil, nil
+	}
+	for _, pipelineResult := range pipelineRun.Status.Results {
+		if pipelineResult.Name != PipelineRunImageArchitectureDigestsParamName {
+			continue
+		}
+		digests := map[string]string{}
+		if err := json.Unmarshal([]byte(pipelineResult.Value.StringVal), &digests); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the %s result of pipelineRun %s: %w",
+				PipelineRunImageArchitectureDigestsParamName, pipelineRun.Name, err)
+		}
+		return digests, nil
+	}
+	return nil, nil
+}
+
+// GetOutputSBOMBlobURL returns a string containing the SBOM_BLOB_URL result value from a given PipelineRun, it is empty
+// when the build doesn't produce a SBOM.
+func GetOutputSBOMBlobURL(object client.Object) string {
+	pipelineRun, ok := object.(*tektonv1.PipelineRun)
+	if ok {
+		for _, pipelineResult := range pipelineRun.Status.Results {
+			if pipelineResult.Name == PipelineRunSBOMBlobURLParamName {
+				return pipelineResult.Value.StringVal
+			}
+		}
+	}
+	return ""
+}
+
 // GetComponentSourceGitUrl returns a string containing the CHAINS-GIT_URL result value from a given PipelineRun.
 func GetComponentSourceGitUrl(object client.Object) (string, error) {
 	pipelineRun, ok := object.(*tektonv1.PipelineRun)
@@ -199,3 +337,17 @@ func GetComponentSourceGitCommit(object client.Object) (string, error) {
 	}
 	return "", h.MissingInfoInPipelineRunError(pipelineRun.Name, PipelineRunChainsGitCommitParamName)
 }
+
+// GetMaxAllowedFailures returns the failure tolerance threshold of the IntegrationTestScenario which was copied
+// to the given integration PipelineRun, the boolean is false when no valid threshold is set.
+func GetMaxAllowedFailures(pipelineRun *tektonv1.PipelineRun) (int, bool) {
+	value, found := pipelineRun.GetAnnotations()[MaxAllowedFailuresAnnotation]
+	if !found {
+		return 0, false
+	}
+	maxAllowedFailures, err := strconv.Atoi(value)
+	if err != nil || maxAllowedFailures < 0 {
+		return 0, false
+	}
+	return maxAllowedFailures, true
+}
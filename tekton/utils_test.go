@@ -113,4 +113,81 @@ var _ = Describe("Utils", func() {
 		_, err := tekton.GetComponentSourceGitCommit(pipelineRun)
 		Expect(err).ToNot(BeNil())
 	})
+
+	It("can get the image digests of the architectures", func() {
+		digests, err := tekton.GetOutputImageArchitectureDigests(pipelineRun)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digests).To(BeNil())
+
+		pipelineRun.Status.PipelineRunStatusFields.Results = append(pipelineRun.Status.PipelineRunStatusFields.Results, tektonv1.PipelineRunResult{
+			Name:  tekton.PipelineRunImageArchitectureDigestsParamName,
+			Value: *tektonv1.NewStructuredValues(`{"amd64":"sha256:aaa","arm64":"sha256:bbb"}`),
+		})
+		digests, err = tekton.GetOutputImageArchitectureDigests(pipelineRun)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digests).To(Equal(map[string]string{"amd64": "sha256:aaa", "arm64": "sha256:bbb"}))
+
+		pipelineRun.Status.PipelineRunStatusFields.Results[len(pipelineRun.Status.PipelineRunStatusFields.Results)-1].Value = *tektonv1.NewStructuredValues("{invalid")
+		_, err = tekton.GetOutputImageArchitectureDigests(pipelineRun)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("can get the SBOM blob url", func() {
+		Expect(tekton.GetOutputSBOMBlobURL(pipelineRun)).To(BeEmpty())
+		pipelineRun.Status.PipelineRunStatusFields.Results = append(pipelineRun.Status.PipelineRunStatusFields.Results, tektonv1.PipelineRunResult{
+			Name:  tekton.PipelineRunSBOMBlobURLParamName,
+			Value: *tektonv1.NewStructuredValues("quay.io/sample/image@sha256:aaa"),
+		})
+		Expect(tekton.GetOutputSBOMBlobURL(pipelineRun)).To(Equal("quay.io/sample/image@sha256:aaa"))
+	})
+
+	It("ignores an invalid failure tolerance threshold", func() {
+		pipelineRun.Annotations = map[string]string{tekton.MaxAllowedFailuresAnnotation: "-1"}
+		_, ok := tekton.GetMaxAllowedFailures(pipelineRun)
+		Expect(ok).To(BeFalse())
+		pipelineRun.Annotations[tekton.MaxAllowedFailuresAnnotation] = "many"
+		_, ok = tekton.GetMaxAllowedFailures(pipelineRun)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("recognizes the build PipelineRuns without the type label through the configured label", func() {
+		pipelineRun.Labels = map[string]string{"triggers.tekton.dev/eventlistener": "build-listener"}
+		Expect(tekton.IsBuildPipelineRun(pipelineRun)).To(BeFalse())
+
+		GinkgoT().Setenv(tekton.BuildPipelineRunLabelEnvVar, "triggers.tekton.dev/eventlistener=build-listener")
+		Expect(tekton.IsBuildPipelineRun(pipelineRun)).To(BeTrue())
+
+		// the type label takes precedence over the configured label
+		pipelineRun.Labels[tekton.PipelineRunTypeLabel] = tekton.PipelineRunTestType
+		Expect(tekton.IsBuildPipelineRun(pipelineRun)).To(BeFalse())
+
+		GinkgoT().Setenv(tekton.BuildPipelineRunLabelEnvVar, "build-listener")
+		delete(pipelineRun.Labels, tekton.PipelineRunTypeLabel)
+		Expect(tekton.IsBuildPipelineRun(pipelineRun)).To(BeFalse())
+	})
+
+	It("gets the component and application names from the labels or the params of the PipelineRun", func() {
+		_, found := tekton.GetComponentName(pipelineRun)
+		Expect(found).To(BeFalse())
+
+		pipelineRun.Spec.Params = append(pipelineRun.Spec.Params,
+			tektonv1.Param{Name: tekton.PipelineRunComponentParamName, Value: *tektonv1.NewStructuredValues("param-component")},
+			tektonv1.Param{Name: tekton.PipelineRunApplicationParamName, Value: *tektonv1.NewStructuredValues("param-application")},
+		)
+		component, found := tekton.GetComponentName(pipelineRun)
+		Expect(found).To(BeTrue())
+		Expect(component).To(Equal("param-component"))
+		application, found := tekton.GetApplicationName(pipelineRun)
+		Expect(found).To(BeTrue())
+		Expect(application).To(Equal("param-application"))
+
+		pipelineRun.Labels = map[string]string{
+			tekton.PipelineRunComponentLabel:   "label-component",
+			tekton.PipelineRunApplicationLabel: "label-application",
+		}
+		component, _ = tekton.GetComponentName(pipelineRun)
+		Expect(component).To(Equal("label-component"))
+		application, _ = tekton.GetApplicationName(pipelineRun)
+		Expect(application).To(Equal("label-application"))
+	})
 })